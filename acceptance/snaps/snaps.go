@@ -43,6 +43,7 @@ var (
 	tempPathRegex      = regexp.MustCompile(`\$\{TEMP\}([^: \\"]+)[: ]?`)                                                    // starts with "${TEMP}" and ends with something not in path, perhaps breaks on Windows due to the colon
 	randomBitsRegex    = regexp.MustCompile(`([a-f0-9]+)$`)                                                                  // in general, we add random bits to paths as suffixes
 	unixTimestamp      = regexp.MustCompile(`("| )(?:\d{10})(\\"|"|$)`)                                                      // Recent Unix timestamp in second resolution
+	reportDigestRegex  = regexp.MustCompile(`(report-digest"?: "?)sha256:[a-f0-9]{64}`)                                      // digest of the report, changes with the image digests
 )
 
 type errCapture struct {
@@ -120,6 +121,9 @@ func MatchSnapshot(ctx context.Context, qualifier, text string, vars map[string]
 	// more timestamps, Unix here
 	text = unixTimestamp.ReplaceAllString(text, "$1$${TIMESTAMP}$2")
 
+	// the digest of the report
+	text = reportDigestRegex.ReplaceAllString(text, "${1}$${REPORT_DIGEST}")
+
 	// handle temp directories, replace local temp path with "${TEMP}"
 	text = strings.ReplaceAll(text, os.TempDir(), "${TEMP}")
 
@@ -0,0 +1,124 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Define the `ec cache clear` command
+package cache
+
+import (
+	"fmt"
+
+	hd "github.com/MakeNowJust/heredoc"
+	"github.com/spf13/cobra"
+
+	"github.com/enterprise-contract/ec-cli/internal/downloader"
+	"github.com/enterprise-contract/ec-cli/internal/evaluator"
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+	"github.com/enterprise-contract/ec-cli/internal/utils/oci"
+)
+
+func cacheClearCmd() *cobra.Command {
+	var (
+		cacheDir     string
+		sourcesOnly  bool
+		compiledOnly bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "clear",
+		Short: "Remove the cached sources, rule metadata and registry responses",
+
+		Long: hd.Doc(`
+			Remove the cached sources, rule metadata and registry responses.
+
+			The "ec validate" commands cache the downloaded policy, data and configuration
+			sources within the ec/sources directory of $XDG_CACHE_HOME, and the rule metadata
+			read from the policy sources within the ec/compiled directory. The policies
+			themselves are not cached, they are compiled on every run. Cached sources are
+			removed once they are older than the --cache-ttl of the validate commands, the
+			rule metadata is keyed by the content of the sources and is never removed unless
+			the cache is cleared. The responses of the image registries are cached within
+			the ec/http directory, those are removed only when neither --sources-only nor
+			--compiled-only is provided.
+
+			The source cache directory given via --cache-dir of the validate commands can be
+			cleared by providing the same directory via --cache-dir.
+		`),
+
+		Example: hd.Doc(`
+			Remove all cached sources, rule metadata and registry responses:
+
+			  ec cache clear
+
+			Remove only the cached rule metadata:
+
+			  ec cache clear --compiled-only
+
+			Remove the sources cached within a specific directory:
+
+			  ec cache clear --sources-only --cache-dir /tmp/ec-cache
+		`),
+
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dirs := []string{}
+
+			if !compiledOnly {
+				dir := cacheDir
+				if dir == "" {
+					var err error
+					if dir, err = downloader.DefaultCacheDir(); err != nil {
+						return fmt.Errorf("unable to determine the source cache directory: %w", err)
+					}
+				}
+				dirs = append(dirs, dir)
+			}
+
+			if !sourcesOnly {
+				dir, err := evaluator.DefaultPolicyCacheDir()
+				if err != nil {
+					return fmt.Errorf("unable to determine the rule metadata cache directory: %w", err)
+				}
+				dirs = append(dirs, dir)
+			}
+
+			if !sourcesOnly && !compiledOnly {
+				dir, err := oci.DefaultHTTPCacheDir()
+				if err != nil {
+					return fmt.Errorf("unable to determine the HTTP cache directory: %w", err)
+				}
+				dirs = append(dirs, dir)
+			}
+
+			fs := utils.FS(cmd.Context())
+			for _, dir := range dirs {
+				if err := fs.RemoveAll(dir); err != nil {
+					return fmt.Errorf("unable to clear the cache in %s: %w", dir, err)
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "Cleared %s\n", dir)
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&cacheDir, "cache-dir", "", hd.Doc(`
+		Directory of the source cache, defaults to the ec/sources directory of $XDG_CACHE_HOME`))
+	cmd.Flags().BoolVar(&sourcesOnly, "sources-only", false, "Remove only the cached sources")
+	cmd.Flags().BoolVar(&compiledOnly, "compiled-only", false, "Remove only the cached rule metadata")
+	cmd.MarkFlagsMutuallyExclusive("sources-only", "compiled-only")
+
+	return cmd
+}
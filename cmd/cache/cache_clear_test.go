@@ -0,0 +1,121 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package cache
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/cmd/root"
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+func TestCacheClear(t *testing.T) {
+	cases := []struct {
+		name    string
+		args    []string
+		removed []string
+		kept    []string
+		err     string
+	}{
+		{
+			name:    "all",
+			removed: []string{"ec/sources/abc/main.rego", "ec/compiled/abc.json", "ec/http/blobs/sha256-abc"},
+			kept:    []string{"other/file"},
+		},
+		{
+			name:    "sources only",
+			args:    []string{"--sources-only"},
+			removed: []string{"ec/sources/abc/main.rego"},
+			kept:    []string{"ec/compiled/abc.json", "ec/http/blobs/sha256-abc"},
+		},
+		{
+			name:    "compiled only",
+			args:    []string{"--compiled-only"},
+			removed: []string{"ec/compiled/abc.json"},
+			kept:    []string{"ec/sources/abc/main.rego", "ec/http/blobs/sha256-abc"},
+		},
+		{
+			name:    "cache directory",
+			args:    []string{"--cache-dir", "/tmp/ec-cache"},
+			removed: []string{"/tmp/ec-cache/abc/main.rego", "ec/compiled/abc.json"},
+			kept:    []string{"ec/sources/abc/main.rego"},
+		},
+		{
+			name: "sources and compiled only",
+			args: []string{"--sources-only", "--compiled-only"},
+			kept: []string{"ec/sources/abc/main.rego", "ec/compiled/abc.json"},
+			err:  "if any flags in the group [sources-only compiled-only] are set none of the others can be; [compiled-only sources-only] were all set",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			home := t.TempDir()
+			t.Setenv("XDG_CACHE_HOME", home)
+			t.Setenv("HOME", home)
+
+			path := func(p string) string {
+				if filepath.IsAbs(p) {
+					return p
+				}
+				return filepath.Join(home, p)
+			}
+
+			fs := afero.NewMemMapFs()
+			for _, f := range append(append([]string{}, c.removed...), c.kept...) {
+				require.NoError(t, afero.WriteFile(fs, path(f), []byte("content"), 0644))
+			}
+
+			cmd := root.NewRootCmd()
+			cacheCmd := NewCacheCmd()
+			cacheCmd.AddCommand(cacheClearCmd())
+			cmd.AddCommand(cacheCmd)
+			cmd.SetContext(utils.WithFS(context.Background(), fs))
+			out := bytes.Buffer{}
+			cmd.SetOut(&out)
+			cmd.SetArgs(append([]string{"cache", "clear"}, c.args...))
+
+			err := cmd.Execute()
+			if c.err != "" {
+				assert.EqualError(t, err, c.err)
+			} else {
+				require.NoError(t, err)
+			}
+
+			for _, f := range c.removed {
+				exists, err := afero.Exists(fs, path(f))
+				require.NoError(t, err)
+				assert.False(t, exists, f)
+			}
+
+			for _, f := range c.kept {
+				exists, err := afero.Exists(fs, path(f))
+				require.NoError(t, err)
+				assert.True(t, exists, f)
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package explain
+
+import (
+	"encoding/json"
+	"fmt"
+
+	hd "github.com/MakeNowJust/heredoc"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+
+	"github.com/enterprise-contract/ec-cli/internal/explain"
+	"github.com/enterprise-contract/ec-cli/internal/report"
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+var ExplainCmd *cobra.Command
+
+func init() {
+	ExplainCmd = NewExplainCmd()
+}
+
+func NewExplainCmd() *cobra.Command {
+	var (
+		reportPath string
+		inputPath  string
+		component  string
+		output     string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "explain --report <report> --component <name>",
+		Short: "Explain the failures of a single component",
+
+		Long: hd.Doc(`
+			Explain the failures of a single component
+
+			Reads the report produced by "ec validate image" in JSON or YAML format and explains
+			each of the failed rules of the given component: the message, the package of the rule
+			and the policy sources providing it, the values of the policy input related to the
+			failure and any exclusions in the policy configuration matching the rule that did not
+			apply.
+
+			The values of the policy input are shown when the policy input, written by
+			"ec validate image" using the policy-input output format, is provided. The values are
+			found by the term of the failure, which is set by most rules.
+		`),
+
+		Example: hd.Doc(`
+			Explain the failures of the "my-component" component:
+
+			  ec validate image --image registry/name:tag --policy my-policy.yaml --info \
+			    --output json=report.json --output policy-input=input.jsonl
+			  ec explain --report report.json --input input.jsonl --component my-component
+		`),
+
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if output != "text" && output != "json" {
+				return fmt.Errorf("invalid value for --output '%s'. accepted values: text, json", output)
+			}
+
+			fs := utils.FS(cmd.Context())
+
+			data, err := afero.ReadFile(fs, reportPath)
+			if err != nil {
+				return fmt.Errorf("unable to read the report: %w", err)
+			}
+
+			r, err := report.ReadReport(data)
+			if err != nil {
+				return err
+			}
+
+			var inputs map[string]any
+			if inputPath != "" {
+				data, err := afero.ReadFile(fs, inputPath)
+				if err != nil {
+					return fmt.Errorf("unable to read the policy input: %w", err)
+				}
+
+				if inputs, err = explain.ReadInputs(data); err != nil {
+					return err
+				}
+			}
+
+			e, err := explain.Explain(r, component, inputs)
+			if err != nil {
+				return err
+			}
+
+			if output == "json" {
+				return json.NewEncoder(cmd.OutOrStdout()).Encode(e)
+			}
+
+			return e.WriteText(cmd.OutOrStdout())
+		},
+	}
+
+	cmd.Flags().StringVarP(&reportPath, "report", "r", "", "Path to the report in JSON or YAML format")
+	cmd.Flags().StringVarP(&inputPath, "input", "i", "", "Path to the policy input written using the policy-input output format")
+	cmd.Flags().StringVarP(&component, "component", "c", "", "Name, or image reference, of the component to explain")
+	cmd.Flags().StringVarP(&output, "output", "o", "text", "Output format, one of: text, json")
+
+	if err := cmd.MarkFlagRequired("report"); err != nil {
+		panic(err)
+	}
+
+	if err := cmd.MarkFlagRequired("component"); err != nil {
+		panic(err)
+	}
+
+	return cmd
+}
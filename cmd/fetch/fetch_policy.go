@@ -18,11 +18,14 @@
 package fetch
 
 import (
+	"fmt"
+
 	hd "github.com/MakeNowJust/heredoc"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/afero"
 	"github.com/spf13/cobra"
 
+	"github.com/enterprise-contract/ec-cli/internal/kubernetes"
 	"github.com/enterprise-contract/ec-cli/internal/policy/source"
 	"github.com/enterprise-contract/ec-cli/internal/utils"
 )
@@ -33,10 +36,11 @@ func fetchPolicyCmd() *cobra.Command {
 		dataSourceUrls []string
 		destDir        string
 		useWorkDir     bool
+		selector       string
 	)
 
 	cmd := &cobra.Command{
-		Use:   "policy --source <source-url> --data-source <source-url>",
+		Use:   "policy --source <source-url> --data-source <source-url> | --selector <label-selector>",
 		Short: "Fetch policy rules from a git repository or other source",
 
 		Long: hd.Doc(`
@@ -55,6 +59,13 @@ func fetchPolicyCmd() *cobra.Command {
 			Note that this command is not typically required to verify the Enterprise
 			Contract. It has been made available for troubleshooting and debugging
 			purposes.
+
+			With --selector the EnterpriseContractPolicy resources matching the label
+			selector are listed from the Kubernetes cluster, in all namespaces the user
+			is allowed to list them in, or in the namespace given via --namespace. The
+			reference of each matching policy, in the namespace/name format, is printed,
+			one per line, and the policy and data sources of the policies are fetched
+			like the sources given via --source and --data-source.
 		`),
 
 		Example: hd.Doc(`
@@ -87,6 +98,10 @@ func fetchPolicyCmd() *cobra.Command {
 
 			  ec fetch policy --source quay.io/enterprise-contract/ec-release-policy:latest
 
+			Listing the policies of a team, and fetching their sources, from the cluster:
+
+			  ec fetch policy --selector team=payments
+
 			Notes:
 
 			- The --dest flag will be ignored if --work-dir is set
@@ -105,7 +120,7 @@ func fetchPolicyCmd() *cobra.Command {
 				destDir = workDir
 			}
 
-			sources := make([]*source.PolicyUrl, 0, len(sourceUrls)+len(dataSourceUrls))
+			sources := make([]source.PolicySource, 0, len(sourceUrls)+len(dataSourceUrls))
 
 			for _, url := range sourceUrls {
 				sources = append(sources, &source.PolicyUrl{Url: url, Kind: source.PolicyKind})
@@ -115,6 +130,25 @@ func fetchPolicyCmd() *cobra.Command {
 				sources = append(sources, &source.PolicyUrl{Url: url, Kind: source.DataKind})
 			}
 
+			if selector != "" {
+				policies, err := kubernetes.ListEnterpriseContractPolicies(cmd.Context(), selector)
+				if err != nil {
+					return err
+				}
+
+				for _, p := range policies {
+					fmt.Fprintf(cmd.OutOrStdout(), "%s/%s\n", p.Namespace, p.Name)
+
+					for _, src := range p.Spec.Sources {
+						policySources, err := source.FetchPolicySources(src)
+						if err != nil {
+							return err
+						}
+						sources = append(sources, policySources...)
+					}
+				}
+			}
+
 			for _, s := range sources {
 				_, err := s.GetPolicy(cmd.Context(), destDir, true)
 				if err != nil {
@@ -130,10 +164,9 @@ func fetchPolicyCmd() *cobra.Command {
 	cmd.Flags().StringArrayVar(&dataSourceUrls, "data-source", []string{}, "data source url. multiple values are allowed")
 	cmd.Flags().StringVarP(&destDir, "dest", "d", ".", "use the specified download destination directory. ignored if --work-dir is set")
 	cmd.Flags().BoolVarP(&useWorkDir, "work-dir", "w", false, "use a temporary work dir as the download destination directory")
+	cmd.Flags().StringVarP(&selector, "selector", "l", "", "fetch the sources of the EnterpriseContractPolicy resources in the cluster matching the label selector, e.g. team=payments")
 
-	if err := cmd.MarkFlagRequired("source"); err != nil {
-		panic(err)
-	}
+	cmd.MarkFlagsOneRequired("source", "selector")
 
 	return cmd
 }
@@ -0,0 +1,109 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package report
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	hd "github.com/MakeNowJust/heredoc"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+
+	"github.com/enterprise-contract/ec-cli/internal/report"
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+func NewDiffCmd() *cobra.Command {
+	var (
+		output string
+		strict bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "diff <old report> <new report>",
+		Short: "Compare two validation reports",
+
+		Long: hd.Doc(`
+			Compare two validation reports
+
+			Reads two reports produced by "ec validate image", or the other validate commands, in
+			JSON or YAML format and lists the violations introduced in the new report, the
+			violations of the old report resolved in the new report, and the warnings found in
+			both reports.
+
+			The results are matched by the name of the component, or the image reference when the
+			component has no name, and by the code, term and message of the result.
+
+			With --strict the command returns a non-zero status when the new report introduces
+			violations, e.g. to fail a pull request pipeline only on regressions.
+		`),
+
+		Example: hd.Doc(`
+			Compare the report of the main branch with the report of a pull request:
+
+			  ec report diff main.json pull-request.json
+
+			Compare the reports in JSON format, failing if violations are introduced:
+
+			  ec report diff main.json pull-request.json --output json --strict
+		`),
+
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if output != "text" && output != "json" {
+				return fmt.Errorf("invalid value for --output '%s'. accepted values: text, json", output)
+			}
+
+			reports := make([]*report.Report, 0, len(args))
+			for _, path := range args {
+				data, err := afero.ReadFile(utils.FS(cmd.Context()), path)
+				if err != nil {
+					return fmt.Errorf("unable to read the report: %w", err)
+				}
+
+				r, err := report.ReadReport(data)
+				if err != nil {
+					return err
+				}
+				reports = append(reports, r)
+			}
+
+			diff := report.Compare(reports[0], reports[1])
+
+			if output == "json" {
+				if err := json.NewEncoder(cmd.OutOrStdout()).Encode(diff); err != nil {
+					return err
+				}
+			} else if err := diff.WriteText(cmd.OutOrStdout()); err != nil {
+				return err
+			}
+
+			if strict && diff.Regressions() {
+				return errors.New("violations introduced")
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&output, "output", "o", "text", "Output format, one of: text, json")
+	cmd.Flags().BoolVarP(&strict, "strict", "s", false, "Return non-zero status when the new report introduces violations")
+
+	return cmd
+}
@@ -0,0 +1,90 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package report
+
+import (
+	"fmt"
+
+	hd "github.com/MakeNowJust/heredoc"
+	sigstoresig "github.com/sigstore/sigstore/pkg/signature"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+
+	"github.com/enterprise-contract/ec-cli/internal/report"
+	"github.com/enterprise-contract/ec-cli/internal/signature"
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+func NewPushCmd() *cobra.Command {
+	var key string
+
+	cmd := &cobra.Command{
+		Use:   "push <report>",
+		Short: "Attach a validation report to the validated images",
+
+		Long: hd.Doc(`
+			Attach a validation report to the validated images
+
+			Reads a report produced by "ec validate image" in JSON or YAML format and pushes it,
+			in JSON format, to the registry as an OCI artifact referring to the image of each
+			component in the report. The artifacts have the artifact type
+			application/vnd.enterprisecontract.report.v1+json and can be discovered using the
+			referrers API of the registry, for example with "oras discover".
+
+			With --key the report is signed and the base64 encoded signature is added to the
+			artifact as the dev.enterprisecontract.report.signature annotation. The password of an
+			encrypted key is read from the COSIGN_PASSWORD environment variable.
+		`),
+
+		Example: hd.Doc(`
+			Validate an image and attach the report to it:
+
+			  ec validate image --image registry/name:tag --policy my-policy --output json=report.json
+			  ec report push report.json
+
+			Attach a signed report:
+
+			  ec report push report.json --key cosign.key
+		`),
+
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			data, err := afero.ReadFile(utils.FS(cmd.Context()), args[0])
+			if err != nil {
+				return fmt.Errorf("unable to read the report: %w", err)
+			}
+
+			var signer sigstoresig.Signer
+			if key != "" {
+				if signer, err = signature.LoadSigner(cmd.Context(), key); err != nil {
+					return err
+				}
+			}
+
+			pushed, err := report.Push(cmd.Context(), data, signer)
+			for _, ref := range pushed {
+				fmt.Fprintln(cmd.OutOrStdout(), ref.String())
+			}
+
+			return err
+		},
+	}
+
+	cmd.Flags().StringVarP(&key, "key", "k", "", "Reference of the private key to sign the report with, a path or a KMS URI")
+
+	return cmd
+}
@@ -21,11 +21,15 @@ import (
 
 	log "github.com/sirupsen/logrus"
 
+	"github.com/enterprise-contract/ec-cli/cmd/cache"
+	"github.com/enterprise-contract/ec-cli/cmd/explain"
 	"github.com/enterprise-contract/ec-cli/cmd/fetch"
 	"github.com/enterprise-contract/ec-cli/cmd/initialize"
 	"github.com/enterprise-contract/ec-cli/cmd/inspect"
 	"github.com/enterprise-contract/ec-cli/cmd/opa"
+	"github.com/enterprise-contract/ec-cli/cmd/report"
 	"github.com/enterprise-contract/ec-cli/cmd/root"
+	"github.com/enterprise-contract/ec-cli/cmd/serve"
 	"github.com/enterprise-contract/ec-cli/cmd/sigstore"
 	"github.com/enterprise-contract/ec-cli/cmd/test"
 	"github.com/enterprise-contract/ec-cli/cmd/track"
@@ -51,9 +55,13 @@ func Execute() {
 }
 
 func init() {
+	RootCmd.AddCommand(cache.CacheCmd)
+	RootCmd.AddCommand(explain.ExplainCmd)
 	RootCmd.AddCommand(fetch.FetchCmd)
 	RootCmd.AddCommand(initialize.InitCmd)
 	RootCmd.AddCommand(inspect.InspectCmd)
+	RootCmd.AddCommand(report.ReportCmd)
+	RootCmd.AddCommand(serve.ServeCmd)
 	RootCmd.AddCommand(track.TrackCmd)
 	RootCmd.AddCommand(validate.ValidateCmd)
 	RootCmd.AddCommand(version.VersionCmd)
@@ -30,8 +30,11 @@ import (
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 
+	"github.com/enterprise-contract/ec-cli/internal/downloader"
 	"github.com/enterprise-contract/ec-cli/internal/kubernetes"
 	"github.com/enterprise-contract/ec-cli/internal/logging"
+	"github.com/enterprise-contract/ec-cli/internal/tracing"
+	"github.com/enterprise-contract/ec-cli/internal/utils/oci"
 )
 
 var (
@@ -39,11 +42,18 @@ var (
 	verbose       bool = false
 	debug         bool = false
 	trace         bool = false
+	offline       bool = false
+	showProgress  bool = false
 	globalTimeout      = 5 * time.Minute
 	logfile       string
+	logFormat     = logging.FormatText
+	otlpEndpoint  string
 	OnExit        func() = func() {}
 )
 
+// tracingFlushTimeout is the time given to export the remaining spans on exit
+const tracingFlushTimeout = 5 * time.Second
+
 type customDeadlineExceededError struct{}
 
 func (customDeadlineExceededError) Error() string {
@@ -66,13 +76,40 @@ func NewRootCmd() *cobra.Command {
 		SilenceUsage: true,
 
 		PersistentPreRun: func(cmd *cobra.Command, _ []string) {
-			logging.InitLogging(verbose, quiet, debug, trace, logfile)
+			if err := logging.InitLogging(verbose, quiet, debug, trace, logfile, logFormat); err != nil {
+				log.Fatal(err)
+			}
 
 			// set a custom message for context.DeadlineExceeded error
 			context.DeadlineExceeded = customDeadlineExceededError{}
 
 			// Create a new context now that flags have been parsed so a custom timeout can be used.
 			ctx, cancel := context.WithTimeout(cmd.Context(), globalTimeout)
+			if offline {
+				ctx = downloader.WithOffline(ctx, true)
+			}
+			if showProgress {
+				ctx = downloader.WithEvents(ctx, downloader.NewProgressPrinter(os.Stderr))
+			}
+			ctx, err := oci.WithRegistryAuth(ctx)
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			ctx, err = oci.WithRegistryMirrors(ctx)
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			// export the spans of the command if requested
+			shutdownTracing := func(context.Context) error { return nil }
+			if otlpEndpoint != "" {
+				var err error
+				if shutdownTracing, err = tracing.Setup(ctx, otlpEndpoint); err != nil {
+					log.Fatal("could not set up tracing: ", err)
+				}
+			}
+			ctx, span := tracing.Start(ctx, cmd.CommandPath())
 			cmd.SetContext(ctx)
 
 			// if trace is enabled setup CPU profiling
@@ -110,6 +147,13 @@ func NewRootCmd() *cobra.Command {
 					}
 				}
 
+				span.End()
+				flushCtx, cancelFlush := context.WithTimeout(context.Background(), tracingFlushTimeout)
+				defer cancelFlush()
+				if err := shutdownTracing(flushCtx); err != nil {
+					log.Warnf("could not export the traces: %v", err)
+				}
+
 				// perform resource cleanup
 				if f, ok := log.StandardLogger().Out.(io.Closer); ok {
 					f.Close()
@@ -132,6 +176,11 @@ func setFlags(rootCmd *cobra.Command) {
 	rootCmd.PersistentFlags().BoolVar(&debug, "debug", debug, "same as verbose but also show function names and line numbers")
 	rootCmd.PersistentFlags().BoolVar(&trace, "trace", trace, "enable trace logging")
 	rootCmd.PersistentFlags().DurationVar(&globalTimeout, "timeout", globalTimeout, "max overall execution duration")
+	rootCmd.PersistentFlags().BoolVar(&offline, "offline", offline, "do not download policy, data and configuration sources over the network, use only the cached and local sources")
+	rootCmd.PersistentFlags().BoolVar(&showProgress, "show-download-progress", showProgress, "print the progress of downloading policy, data and configuration sources to stderr")
+	rootCmd.PersistentFlags().StringVar(&otlpEndpoint, "otlp-endpoint", otlpEndpoint, "export OpenTelemetry traces of the policy resolution, source downloads, signature verification and policy evaluation over OTLP/gRPC to the collector at the URL, e.g. http://localhost:4317")
 	rootCmd.PersistentFlags().StringVar(&logfile, "logfile", "", "file to write the logging output. If not specified logging output will be written to stderr")
-	kubernetes.AddKubeconfigFlag(rootCmd)
+	rootCmd.PersistentFlags().StringVar(&logFormat, "log-format", logFormat, fmt.Sprintf("format of the logging output, one of: %s, %s", logging.FormatText, logging.FormatJSON))
+	kubernetes.AddKubeconfigFlags(rootCmd)
+	oci.AddRegistryFlags(rootCmd)
 }
@@ -0,0 +1,182 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package serve
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	hd "github.com/MakeNowJust/heredoc"
+	app "github.com/konflux-ci/application-api/api/v1alpha1"
+	"github.com/spf13/cobra"
+
+	"github.com/enterprise-contract/ec-cli/internal/applicationsnapshot"
+	"github.com/enterprise-contract/ec-cli/internal/evaluator"
+	"github.com/enterprise-contract/ec-cli/internal/policy"
+	"github.com/enterprise-contract/ec-cli/internal/service"
+	"github.com/enterprise-contract/ec-cli/internal/tracing"
+)
+
+func newAPICmd(validate imageValidationFunc) *cobra.Command {
+	data := struct {
+		address        string
+		tlsCertFile    string
+		tlsKeyFile     string
+		policy         string
+		publicKey      string
+		rekorURL       string
+		ignoreRekor    bool
+		effectiveTime  string
+		policyCacheTTL time.Duration
+		evaluator      string
+	}{
+		address:        ":8080",
+		effectiveTime:  policy.Now,
+		policyCacheTTL: 5 * time.Minute,
+		evaluator:      evaluator.EngineConftest,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "api",
+		Short: "Run the validation REST API server",
+
+		Long: hd.Doc(`
+			Run the validation REST API server
+
+			Serves the POST /validate endpoint for validating an image against the Enterprise
+			Contract without running the ec command for each validation. The request body is a
+			JSON object holding the reference of the image and, optionally, the reference of the
+			policy configuration in any of the forms accepted by --policy:
+
+			  {"image": "registry/name:tag", "policy": "github.com/org/repo//policy"}
+
+			When the policy is not provided in the request the policy given by --policy is used.
+			The response is the JSON object of the component as found in the "components" of the
+			JSON report of "ec validate image", with the "success" attribute holding the outcome.
+
+			The policies are downloaded and compiled on the first use and cached for the duration
+			given by --policy-cache-ttl, after which they are reloaded on the next use. Changes to
+			the policies, to their policy sources, and the current time, used as the effective time
+			by default, are picked up on reload.
+
+			With --evaluator wasm the policy packages are compiled to an OPA WASM module for each
+			validated document, the compiled policies are reused until the policies are reloaded.
+
+			The /healthz and /readyz endpoints respond with "ok" for use in the liveness and readiness
+			probes, /readyz fails once the server starts to shut down. The /metrics endpoint exposes
+			the Prometheus metrics of the validations, of the downloads of the policy sources, of the
+			signature verifications and of the policy evaluations.
+		`),
+
+		Example: hd.Doc(`
+			Serve the API using a default policy:
+
+			  ec serve api --policy my-namespace/my-policy
+
+			Validate an image using the API:
+
+			  curl -X POST http://localhost:8080/validate -d '{"image": "registry/name:tag"}'
+		`),
+
+		Args: cobra.NoArgs,
+
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if err := evaluator.ValidateEngine(data.evaluator); err != nil {
+				return err
+			}
+			return checkTLSFlags(data.tlsCertFile, data.tlsKeyFile)
+		},
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := evaluator.WithEngine(cmd.Context(), data.evaluator)
+
+			cache := newPolicyCache(data.policyCacheTTL, policy.Options{
+				EffectiveTime: data.effectiveTime,
+				IgnoreRekor:   data.ignoreRekor,
+				PublicKey:     data.publicKey,
+				RekorURL:      data.rekorURL,
+			})
+
+			mux := http.NewServeMux()
+			mux.Handle("/validate", tracing.Handler("api.validate", service.Handler{
+				Cache:         cache,
+				DefaultPolicy: data.policy,
+				Validate:      validateWith(validate),
+			}))
+
+			return run(ctx, data.address, data.tlsCertFile, data.tlsKeyFile, mux, cache.Close)
+		},
+	}
+
+	cmd.Flags().StringVar(&data.address, "address", data.address, "Address, in the host:port form, to listen on")
+
+	cmd.Flags().StringVar(&data.tlsCertFile, "tls-cert-file", data.tlsCertFile,
+		"Path to the PEM encoded TLS certificate, including any intermediate certificates")
+
+	cmd.Flags().StringVar(&data.tlsKeyFile, "tls-key-file", data.tlsKeyFile, "Path to the PEM encoded TLS private key")
+
+	cmd.Flags().StringVarP(&data.policy, "policy", "p", data.policy, hd.Doc(`
+		Policy configuration used when the request does not provide one, as:
+		  * Kubernetes reference ([<namespace>/]<name> or <name>@<namespace>)
+		  * file (policy.yaml or file:policy.yaml)
+		  * Kubernetes ConfigMap key (configmap:<name>[@<namespace>][#<key>] or
+		    configmap://<namespace>/<name>[/<key>], the key defaults to policy.yaml)
+		  * OCI artifact (oci::quay.io/org/policy:tag)
+		  * git reference (github.com/user/repo//default?ref=main), or
+		  * inline JSON ('{sources: {...}, configuration: {...}}')`))
+
+	cmd.Flags().StringVarP(&data.publicKey, "public-key", "k", data.publicKey,
+		"path to the public key. Overrides publicKey from EnterpriseContractPolicy")
+
+	cmd.Flags().StringVarP(&data.rekorURL, "rekor-url", "r", data.rekorURL,
+		"Rekor URL. Overrides rekorURL from EnterpriseContractPolicy")
+
+	cmd.Flags().BoolVar(&data.ignoreRekor, "ignore-rekor", data.ignoreRekor,
+		"Skip Rekor transparency log checks during validation.")
+
+	cmd.Flags().StringVar(&data.effectiveTime, "effective-time", data.effectiveTime, hd.Doc(`
+		Run policy checks with the provided time. Useful for testing rules with
+		effective dates in the future. The value can be "now" (default) - for
+		current time, or a RFC3339 formatted value, e.g. 2022-11-18T00:00:00Z.`))
+
+	cmd.Flags().DurationVar(&data.policyCacheTTL, "policy-cache-ttl", data.policyCacheTTL,
+		"Duration the compiled policies are cached for before they are reloaded")
+
+	cmd.Flags().StringVar(&data.evaluator, "evaluator", data.evaluator, hd.Doc(`
+		Engine evaluating the policies, one of "conftest" or "wasm". With "wasm" the policy rules
+		are compiled to OPA WASM modules and executed by the WASM runtime, which requires ec to
+		be built with cgo enabled.`))
+
+	return cmd
+}
+
+// validateWith adapts the image validation function for the service
+func validateWith(validate imageValidationFunc) service.ValidateFunc {
+	return func(ctx context.Context, comp app.SnapshotComponent, compiled *service.Compiled) (applicationsnapshot.Component, error) {
+		return observe(func() (applicationsnapshot.Component, error) {
+			spec := &app.SnapshotSpec{Components: []app.SnapshotComponent{comp}}
+			out, err := validate(ctx, comp, spec, compiled.Policy, compiled.Evaluators, false)
+			if err != nil {
+				return applicationsnapshot.Component{}, fmt.Errorf("unable to validate %s: %w", comp.ContainerImage, err)
+			}
+
+			return component(comp, out), nil
+		})
+	}
+}
@@ -0,0 +1,388 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package serve
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	hd "github.com/MakeNowJust/heredoc"
+	app "github.com/konflux-ci/application-api/api/v1alpha1"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/enterprise-contract/ec-cli/internal/admission"
+	"github.com/enterprise-contract/ec-cli/internal/applicationsnapshot"
+	"github.com/enterprise-contract/ec-cli/internal/downloader"
+	"github.com/enterprise-contract/ec-cli/internal/evaluator"
+	"github.com/enterprise-contract/ec-cli/internal/image"
+	"github.com/enterprise-contract/ec-cli/internal/metrics"
+	"github.com/enterprise-contract/ec-cli/internal/output"
+	"github.com/enterprise-contract/ec-cli/internal/policy"
+	"github.com/enterprise-contract/ec-cli/internal/policy/source"
+	"github.com/enterprise-contract/ec-cli/internal/service"
+	"github.com/enterprise-contract/ec-cli/internal/tracing"
+	validate_utils "github.com/enterprise-contract/ec-cli/internal/validate"
+)
+
+type imageValidationFunc func(context.Context, app.SnapshotComponent, *app.SnapshotSpec, policy.Policy, []evaluator.Evaluator, bool) (*output.Output, error)
+
+var newConftestEvaluator = evaluator.NewConftestEvaluator
+
+// shutdownTimeout is the time given to the in-flight requests to complete
+// once the server is asked to stop
+const shutdownTimeout = 30 * time.Second
+
+var ServeCmd *cobra.Command
+
+func init() {
+	ServeCmd = NewServeCmd(image.ValidateImage)
+}
+
+func NewServeCmd(validate imageValidationFunc) *cobra.Command {
+	data := struct {
+		address        string
+		tlsCertFile    string
+		tlsKeyFile     string
+		policy         string
+		publicKey      string
+		rekorURL       string
+		ignoreRekor    bool
+		effectiveTime  string
+		policyCacheTTL time.Duration
+	}{
+		address:        ":8443",
+		effectiveTime:  policy.Now,
+		policyCacheTTL: 5 * time.Minute,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Run a Kubernetes validating admission webhook server",
+
+		Long: hd.Doc(`
+			Run a Kubernetes validating admission webhook server
+
+			Serves the /validate endpoint to be referenced by a ValidatingWebhookConfiguration. The
+			images of the Pods, Deployments, ReplicaSets, StatefulSets, DaemonSets, Jobs and CronJobs
+			being created or updated are validated against the EnterpriseContractPolicy given by
+			--policy. The request is admitted only if all of the images conform, otherwise it is
+			denied and the violations are listed in the status message. Requests for any other kind
+			of resource are admitted.
+
+			The policy is downloaded and compiled on the first request and cached for the duration
+			given by --policy-cache-ttl, after which it is reloaded on the next request. Changes to
+			the referenced EnterpriseContractPolicy, to its policy sources, and the current time,
+			used as the effective time by default, take effect on reload without restarting the
+			server.
+
+			The /healthz and /readyz endpoints respond with "ok" for use in the liveness and readiness
+			probes, /readyz fails once the server starts to shut down. The /metrics endpoint exposes
+			the Prometheus metrics of the validations, of the downloads of the policy sources, of the
+			signature verifications and of the policy evaluations.
+
+			The Kubernetes API server requires webhooks to be served over HTTPS, provide the
+			certificate and key using --tls-cert-file and --tls-key-file. Without them the server
+			uses plain HTTP, for example when TLS is terminated by a proxy in front of it.
+		`),
+
+		Example: hd.Doc(`
+			Serve the webhook using the EnterpriseContractPolicy in the cluster:
+
+			  ec serve --policy my-namespace/my-policy --tls-cert-file tls.crt --tls-key-file tls.key
+
+			Serve the webhook over plain HTTP on a different port:
+
+			  ec serve --policy policy.yaml --public-key key.pub --address :8080
+		`),
+
+		Args: cobra.NoArgs,
+
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return checkTLSFlags(data.tlsCertFile, data.tlsKeyFile)
+		},
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cache := newPolicyCache(data.policyCacheTTL, policy.Options{
+				EffectiveTime: data.effectiveTime,
+				IgnoreRekor:   data.ignoreRekor,
+				PublicKey:     data.publicKey,
+				RekorURL:      data.rekorURL,
+			})
+
+			mux := http.NewServeMux()
+			mux.Handle("/validate", tracing.Handler("admission.validate", admission.Handler{
+				Validate: validateImagesWith(validate, cache, data.policy),
+			}))
+
+			return run(cmd.Context(), data.address, data.tlsCertFile, data.tlsKeyFile, mux, cache.Close)
+		},
+	}
+
+	cmd.Flags().StringVar(&data.address, "address", data.address, "Address, in the host:port form, to listen on")
+
+	cmd.Flags().StringVar(&data.tlsCertFile, "tls-cert-file", data.tlsCertFile,
+		"Path to the PEM encoded TLS certificate, including any intermediate certificates")
+
+	cmd.Flags().StringVar(&data.tlsKeyFile, "tls-key-file", data.tlsKeyFile, "Path to the PEM encoded TLS private key")
+
+	cmd.Flags().StringVarP(&data.policy, "policy", "p", data.policy, hd.Doc(`
+		Policy configuration as:
+		  * Kubernetes reference ([<namespace>/]<name> or <name>@<namespace>)
+		  * file (policy.yaml or file:policy.yaml)
+		  * Kubernetes ConfigMap key (configmap:<name>[@<namespace>][#<key>] or
+		    configmap://<namespace>/<name>[/<key>], the key defaults to policy.yaml)
+		  * OCI artifact (oci::quay.io/org/policy:tag)
+		  * git reference (github.com/user/repo//default?ref=main), or
+		  * inline JSON ('{sources: {...}, configuration: {...}}')`))
+
+	cmd.Flags().StringVarP(&data.publicKey, "public-key", "k", data.publicKey,
+		"path to the public key. Overrides publicKey from EnterpriseContractPolicy")
+
+	cmd.Flags().StringVarP(&data.rekorURL, "rekor-url", "r", data.rekorURL,
+		"Rekor URL. Overrides rekorURL from EnterpriseContractPolicy")
+
+	cmd.Flags().BoolVar(&data.ignoreRekor, "ignore-rekor", data.ignoreRekor,
+		"Skip Rekor transparency log checks during validation.")
+
+	cmd.Flags().StringVar(&data.effectiveTime, "effective-time", data.effectiveTime, hd.Doc(`
+		Run policy checks with the provided time. Useful for testing rules with
+		effective dates in the future. The value can be "now" (default) - for
+		current time, or a RFC3339 formatted value, e.g. 2022-11-18T00:00:00Z.`))
+
+	cmd.Flags().DurationVar(&data.policyCacheTTL, "policy-cache-ttl", data.policyCacheTTL,
+		"Duration the compiled policy is cached for before it is reloaded")
+
+	if err := cmd.MarkFlagRequired("policy"); err != nil {
+		panic(err)
+	}
+
+	cmd.AddCommand(newAPICmd(validate))
+
+	return cmd
+}
+
+// checkTLSFlags returns an error if only one of the TLS certificate and key
+// files is provided
+func checkTLSFlags(tlsCertFile, tlsKeyFile string) error {
+	if (tlsCertFile == "") != (tlsKeyFile == "") {
+		return errors.New("both --tls-cert-file and --tls-key-file need to be provided")
+	}
+	return nil
+}
+
+// run serves the handler on the address until the process is signaled to
+// stop, using TLS if the certificate and key files are provided. The /healthz
+// and /readyz probe endpoints are added to the handler, the latter failing
+// once the server starts to shut down, along with the /metrics endpoint. The cleanup function, if given, is
+// invoked after all of the in-flight requests have completed.
+func run(ctx context.Context, address, tlsCertFile, tlsKeyFile string, mux *http.ServeMux, cleanup func()) error {
+	var shuttingDown atomic.Bool
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprintln(w, "ok")
+	})
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, _ *http.Request) {
+		if shuttingDown.Load() {
+			http.Error(w, "shutting down", http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprintln(w, "ok")
+	})
+	mux.Handle("/metrics", metrics.Handler())
+
+	// The server runs until it is signaled to stop, the global timeout of
+	// the root command does not apply to it
+	ctx, stop := signal.NotifyContext(context.WithoutCancel(ctx), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	ctx = downloader.AddEvents(ctx, metrics.DownloadEvents{})
+
+	server := &http.Server{
+		Addr:              address,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+		BaseContext:       func(net.Listener) context.Context { return ctx },
+	}
+
+	errs := make(chan error, 1)
+	go func() {
+		log.Infof("Serving on %s", address)
+		if tlsCertFile != "" {
+			errs <- server.ListenAndServeTLS(tlsCertFile, tlsKeyFile)
+		} else {
+			errs <- server.ListenAndServe()
+		}
+	}()
+
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+	}
+
+	log.Info("Shutting down the server")
+	shuttingDown.Store(true)
+	shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), shutdownTimeout)
+	defer cancel()
+
+	err := server.Shutdown(shutdownCtx)
+	if cleanup != nil {
+		cleanup()
+	}
+
+	return err
+}
+
+// loadPolicy loads the policy configuration with the given reference
+func loadPolicy(ctx context.Context, policyRef string, opts policy.Options) (policy.Policy, error) {
+	policyConfiguration, err := validate_utils.GetPolicyConfig(ctx, policyRef)
+	if err != nil {
+		return nil, err
+	}
+
+	opts.PolicyRef = policyConfiguration
+
+	return policy.NewPolicy(ctx, opts)
+}
+
+// newPolicyCache returns the cache of the policies loaded with the given
+// options. Each load downloads the policy sources anew, so that the changes to
+// the sources are picked up on reload, and the downloads don't outlive the
+// evaluators using them, which are destroyed once the policy is reloaded.
+func newPolicyCache(ttl time.Duration, opts policy.Options) *service.PolicyCache {
+	return service.NewPolicyCache(ttl, func(ctx context.Context, policyRef string) (*service.Compiled, error) {
+		ctx = source.WithDownloads(ctx, source.NewDownloads())
+
+		p, err := loadPolicy(ctx, policyRef, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		evaluators, err := compile(ctx, p)
+		if err != nil {
+			return nil, err
+		}
+
+		return &service.Compiled{Policy: p, Evaluators: evaluators}, nil
+	})
+}
+
+// compile creates an evaluator for each of the source groups of the policy
+func compile(ctx context.Context, p policy.Policy) ([]evaluator.Evaluator, error) {
+	evaluators := []evaluator.Evaluator{}
+	for _, sourceGroup := range p.Spec().Sources {
+		policySources, err := source.FetchPolicySources(sourceGroup)
+		if err != nil {
+			destroy(evaluators)
+			return nil, err
+		}
+
+		e, err := newConftestEvaluator(ctx, policySources, p, sourceGroup)
+		if err != nil {
+			destroy(evaluators)
+			return nil, err
+		}
+
+		evaluators = append(evaluators, e)
+	}
+
+	return evaluators, nil
+}
+
+func destroy(evaluators []evaluator.Evaluator) {
+	for _, e := range evaluators {
+		e.Destroy()
+	}
+}
+
+// validateImagesWith returns the function validating the images of the
+// admission requests against the policy with the given reference, taken from
+// the cache
+func validateImagesWith(validate imageValidationFunc, cache *service.PolicyCache, policyRef string) admission.ValidateFunc {
+	return func(ctx context.Context, images []string) ([]applicationsnapshot.Component, error) {
+		compiled, release, err := cache.Get(ctx, policyRef)
+		if err != nil {
+			return nil, err
+		}
+		defer release()
+
+		return validateAll(ctx, validate, compiled, images)
+	}
+}
+
+// validateAll validates each of the images against the compiled policy,
+// returning a component per image holding the outcome
+func validateAll(ctx context.Context, validate imageValidationFunc, compiled *service.Compiled, images []string) ([]applicationsnapshot.Component, error) {
+	spec := &app.SnapshotSpec{}
+	for _, i := range images {
+		spec.Components = append(spec.Components, app.SnapshotComponent{Name: "Unnamed", ContainerImage: i})
+	}
+
+	components := make([]applicationsnapshot.Component, 0, len(images))
+	for _, comp := range spec.Components {
+		c, err := observe(func() (applicationsnapshot.Component, error) {
+			out, err := validate(ctx, comp, spec, compiled.Policy, compiled.Evaluators, false)
+			if err != nil {
+				return applicationsnapshot.Component{}, fmt.Errorf("unable to validate %s: %w", comp.ContainerImage, err)
+			}
+
+			return component(comp, out), nil
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		components = append(components, c)
+	}
+
+	return components, nil
+}
+
+// observe records the metrics of the validation performed by the function
+func observe(validate func() (applicationsnapshot.Component, error)) (applicationsnapshot.Component, error) {
+	start := time.Now()
+	c, err := validate()
+
+	result := metrics.ResultSuccess
+	if err != nil {
+		result = metrics.ResultError
+	} else if !c.Success {
+		result = metrics.ResultFailure
+	}
+	metrics.ObserveValidation(result, time.Since(start))
+
+	return c, err
+}
+
+// component returns the component with the outcome of the validation
+func component(comp app.SnapshotComponent, out *output.Output) applicationsnapshot.Component {
+	violations := out.Violations()
+
+	return applicationsnapshot.Component{
+		SnapshotComponent: comp,
+		Violations:        violations,
+		Warnings:          out.Warnings(),
+		Success:           len(violations) == 0,
+	}
+}
@@ -0,0 +1,254 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package serve
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"strings"
+	"testing"
+	"time"
+
+	app "github.com/konflux-ci/application-api/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/enterprise-contract/ec-cli/internal/admission"
+	"github.com/enterprise-contract/ec-cli/internal/applicationsnapshot"
+	"github.com/enterprise-contract/ec-cli/internal/evaluator"
+	"github.com/enterprise-contract/ec-cli/internal/output"
+	"github.com/enterprise-contract/ec-cli/internal/policy"
+	"github.com/enterprise-contract/ec-cli/internal/service"
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+func TestValidateAll(t *testing.T) {
+	ctx := context.Background()
+	p, err := policy.NewOfflinePolicy(ctx, policy.Now)
+	require.NoError(t, err)
+
+	validate := func(_ context.Context, comp app.SnapshotComponent, spec *app.SnapshotSpec, _ policy.Policy, _ []evaluator.Evaluator, _ bool) (*output.Output, error) {
+		assert.Len(t, spec.Components, 2)
+
+		out := &output.Output{ImageURL: comp.ContainerImage}
+		if comp.ContainerImage == "registry.io/bad:tag" {
+			out.SetPolicyCheck([]evaluator.Outcome{{
+				Failures: []evaluator.Result{{Message: "Not signed", Metadata: map[string]any{"code": "builtin.image.signature_check"}}},
+			}})
+		}
+		return out, nil
+	}
+
+	compiled := &service.Compiled{Policy: p}
+	components, err := validateAll(ctx, validate, compiled, []string{"registry.io/good:tag", "registry.io/bad:tag"})
+	require.NoError(t, err)
+	require.Len(t, components, 2)
+
+	assert.Equal(t, "registry.io/good:tag", components[0].ContainerImage)
+	assert.True(t, components[0].Success)
+	assert.Empty(t, components[0].Violations)
+
+	assert.Equal(t, "registry.io/bad:tag", components[1].ContainerImage)
+	assert.False(t, components[1].Success)
+	require.Len(t, components[1].Violations, 1)
+	assert.Equal(t, "Not signed", components[1].Violations[0].Message)
+
+	failing := func(context.Context, app.SnapshotComponent, *app.SnapshotSpec, policy.Policy, []evaluator.Evaluator, bool) (*output.Output, error) {
+		return nil, errors.New("boom")
+	}
+
+	_, err = validateAll(ctx, failing, compiled, []string{"registry.io/good:tag"})
+	assert.EqualError(t, err, "unable to validate registry.io/good:tag: boom")
+}
+
+func TestServeCmdTLSFlags(t *testing.T) {
+	cmd := NewServeCmd(nil)
+	cmd.SetArgs([]string{"--policy", "policy.yaml", "--tls-cert-file", "tls.crt"})
+	cmd.SilenceUsage = true
+	cmd.SilenceErrors = true
+
+	err := cmd.ExecuteContext(context.Background())
+	assert.EqualError(t, err, "both --tls-cert-file and --tls-key-file need to be provided")
+}
+
+func TestAPICmdEvaluatorFlag(t *testing.T) {
+	cmd := newAPICmd(nil)
+	cmd.SetArgs([]string{"--policy", "policy.yaml", "--evaluator", "opa"})
+	cmd.SilenceUsage = true
+	cmd.SilenceErrors = true
+
+	err := cmd.ExecuteContext(context.Background())
+	assert.EqualError(t, err, `unsupported evaluator "opa", expecting one of: conftest, wasm`)
+}
+
+func TestValidateWith(t *testing.T) {
+	ctx := context.Background()
+	p, err := policy.NewOfflinePolicy(ctx, policy.Now)
+	require.NoError(t, err)
+
+	validate := func(_ context.Context, comp app.SnapshotComponent, spec *app.SnapshotSpec, got policy.Policy, _ []evaluator.Evaluator, _ bool) (*output.Output, error) {
+		assert.Equal(t, []app.SnapshotComponent{comp}, spec.Components)
+		assert.Same(t, p, got)
+
+		out := &output.Output{ImageURL: comp.ContainerImage}
+		out.SetPolicyCheck([]evaluator.Outcome{{
+			Warnings: []evaluator.Result{{Message: "Deprecated"}},
+		}})
+		return out, nil
+	}
+
+	comp := app.SnapshotComponent{Name: "Unnamed", ContainerImage: "registry.io/good:tag"}
+	got, err := validateWith(validate)(ctx, comp, &service.Compiled{Policy: p})
+	require.NoError(t, err)
+	assert.Equal(t, comp, got.SnapshotComponent)
+	assert.True(t, got.Success)
+	assert.Equal(t, []evaluator.Result{{Message: "Deprecated"}}, got.Warnings)
+}
+
+// writePolicy writes the policy archive with a rule denying with the given
+// message
+func writePolicy(t *testing.T, archive, message string) {
+	rule := []byte(fmt.Sprintf(`package main
+
+import rego.v1
+
+# METADATA
+# custom:
+#   short_name: message
+deny contains {"code": "main.message", "msg": %q}
+`, message))
+
+	var buf bytes.Buffer
+	ar := tar.NewWriter(&buf)
+	require.NoError(t, ar.WriteHeader(&tar.Header{Name: "main.rego", Mode: 0644, Size: int64(len(rule))}))
+	_, err := ar.Write(rule)
+	require.NoError(t, err)
+	require.NoError(t, ar.Close())
+
+	require.NoError(t, os.WriteFile(archive, buf.Bytes(), 0600))
+}
+
+// evaluatePolicy is the image validation evaluating the policy against an
+// empty input
+func evaluatePolicy(t *testing.T) imageValidationFunc {
+	input := t.TempDir()
+	require.NoError(t, os.WriteFile(path.Join(input, "input.json"), []byte("{}"), 0600))
+
+	return func(ctx context.Context, comp app.SnapshotComponent, _ *app.SnapshotSpec, _ policy.Policy, evaluators []evaluator.Evaluator, _ bool) (*output.Output, error) {
+		out := &output.Output{ImageURL: comp.ContainerImage}
+		for _, e := range evaluators {
+			outcomes, _, err := e.Evaluate(ctx, evaluator.EvaluationTarget{Inputs: []string{input}})
+			if err != nil {
+				return nil, err
+			}
+			out.SetPolicyCheck(outcomes)
+		}
+		return out, nil
+	}
+}
+
+// testPolicyCache returns the cache of the policy with the source in the given
+// archive, expiring the policy right after it is loaded
+func testPolicyCache(t *testing.T, archive string) (*service.PolicyCache, string) {
+	cache := newPolicyCache(time.Nanosecond, policy.Options{
+		EffectiveTime: policy.Now,
+		IgnoreRekor:   true,
+		PublicKey:     utils.TestPublicKey,
+	})
+	t.Cleanup(cache.Close)
+
+	return cache, fmt.Sprintf(`{"sources": [{"policy": [%q]}]}`, archive)
+}
+
+func TestWebhookSequentialRequests(t *testing.T) {
+	archive := path.Join(t.TempDir(), "policy.tar")
+	writePolicy(t, archive, "first")
+	cache, policyRef := testPolicyCache(t, archive)
+
+	handler := admission.Handler{Validate: validateImagesWith(evaluatePolicy(t), cache, policyRef)}
+
+	review := func() *admissionv1.AdmissionResponse {
+		body, err := json.Marshal(admissionv1.AdmissionReview{
+			TypeMeta: metav1.TypeMeta{APIVersion: "admission.k8s.io/v1", Kind: "AdmissionReview"},
+			Request: &admissionv1.AdmissionRequest{
+				UID:       "uid-1",
+				Kind:      metav1.GroupVersionKind{Version: "v1", Kind: "Pod"},
+				Operation: admissionv1.Create,
+				Object:    runtime.RawExtension{Raw: []byte(`{"spec": {"containers": [{"name": "app", "image": "registry.io/image:tag"}]}}`)},
+			},
+		})
+		require.NoError(t, err)
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/validate", bytes.NewReader(body)))
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		var got admissionv1.AdmissionReview
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+		require.NotNil(t, got.Response)
+		return got.Response
+	}
+
+	// the policy is reloaded, and its source downloaded anew, on each request
+	resp := review()
+	assert.False(t, resp.Allowed)
+	assert.Contains(t, resp.Result.Message, "[main.message] first")
+
+	writePolicy(t, archive, "second")
+	resp = review()
+	assert.False(t, resp.Allowed)
+	assert.Contains(t, resp.Result.Message, "[main.message] second")
+}
+
+func TestAPIPolicyReload(t *testing.T) {
+	archive := path.Join(t.TempDir(), "policy.tar")
+	writePolicy(t, archive, "first")
+	cache, policyRef := testPolicyCache(t, archive)
+
+	handler := service.Handler{Cache: cache, DefaultPolicy: policyRef, Validate: validateWith(evaluatePolicy(t))}
+
+	validate := func() applicationsnapshot.Component {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/validate", strings.NewReader(`{"image": "registry.io/image:tag"}`)))
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+		var got applicationsnapshot.Component
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+		require.Len(t, got.Violations, 1)
+		return got
+	}
+
+	assert.Equal(t, "first", validate().Violations[0].Message)
+
+	// the changes to the policy source are picked up once the cached policy
+	// expires
+	writePolicy(t, archive, "second")
+	assert.Equal(t, "second", validate().Violations[0].Message)
+}
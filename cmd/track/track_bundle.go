@@ -59,10 +59,19 @@ func trackBundleCmd(track trackBundleFn, pullImage pullImageFn, pushImage pushIm
 			command will query the registry to determine its value. Either a tag
 			or a digest is required.
 
+			Tekton Tasks resolved from git, e.g. by the git resolver or by Pipelines
+			as Code, are tracked using references in the
+			git+<repository URL>//<path to the Task>@<revision> form. The revision
+			may be a commit id, a branch, a tag, or an abbreviated commit id, which
+			are resolved to the full commit id that is recorded. With --freshen the
+			revision may be omitted, and the latest commit changing the Task is
+			recorded.
+
 			The output is meant to assist enforcement of policies that ensure the
 			most recent Tekton Bundle is used. As such, each entry contains an
 			"effective_on" date which is set to 30 days from today. This indicates
-			the Tekton Bundle usage should be updated within that period.
+			the Tekton Bundle usage should be updated within that period. The same
+			applies to the Tekton Tasks resolved from git.
 
 			If --prune is set, on by default, non-acceptable entries are removed.
 			Any entry with an effective_on date in the future, and the entry with
@@ -75,6 +84,10 @@ func trackBundleCmd(track trackBundleFn, pullImage pullImageFn, pushImage pushIm
 
 			  ec track bundle --bundle <IMAGE1> --bundle <IMAGE2>
 
+			Track a Task resolved from git:
+
+			  ec track bundle --git git+https://github.com/org/repository//task/0.1/task.yaml@main
+
 			Save tracking information into a new tracking file:
 
 			  ec track bundle --bundle <IMAGE1> --output <path/to/new/file>
@@ -167,7 +180,7 @@ func trackBundleCmd(track trackBundleFn, pullImage pullImageFn, pushImage pushIm
 		"bundle image reference to track - may be used multiple times")
 
 	cmd.Flags().StringSliceVarP(&params.gits, "git", "g", params.gits,
-		"git references to track - may be used multiple times")
+		"git references of Tekton Tasks to track, in the git+<repository>//<path>@<revision> form - may be used multiple times")
 
 	cmd.Flags().BoolVarP(&params.prune, "prune", "p", params.prune,
 		"remove entries that are no longer acceptable, i.e. a newer entry already effective exists")
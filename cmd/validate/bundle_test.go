@@ -0,0 +1,173 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package validate
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	tektonoci "github.com/tektoncd/pipeline/pkg/remote/oci"
+
+	"github.com/enterprise-contract/ec-cli/internal/evaluator"
+	"github.com/enterprise-contract/ec-cli/internal/output"
+	"github.com/enterprise-contract/ec-cli/internal/policy"
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+	"github.com/enterprise-contract/ec-cli/internal/utils/oci"
+	"github.com/enterprise-contract/ec-cli/internal/utils/oci/fake"
+)
+
+func Test_ValidateBundleCommand(t *testing.T) {
+	task := func(name string) mutate.Addendum {
+		return mutate.Addendum{
+			Layer: static.NewLayer([]byte(fmt.Sprintf(`{"kind": "Task", "metadata": {"name": %q}}`, name)), types.OCILayer),
+			Annotations: map[string]string{
+				tektonoci.KindAnnotation:       "task",
+				tektonoci.TitleAnnotation:      name,
+				tektonoci.APIVersionAnnotation: "v1",
+			},
+		}
+	}
+
+	bundle, err := mutate.Append(empty.Image, task("build"), task("test"))
+	require.NoError(t, err)
+	digest, err := bundle.Digest()
+	require.NoError(t, err)
+
+	ref := name.MustParseReference("registry.io/tasks/bundle:v1")
+	bundleRef := fmt.Sprintf("registry.io/tasks/bundle@%s", digest)
+	bundleDigest, err := name.NewDigest(bundleRef)
+	require.NoError(t, err)
+
+	validate := func(ctx context.Context, fpath string, _ policy.Policy, _ bool) (*output.Output, error) {
+		b, err := afero.ReadFile(utils.FS(ctx), fpath)
+		if err != nil {
+			return nil, err
+		}
+
+		in := struct {
+			Bundle struct {
+				Ref  string `json:"ref"`
+				Name string `json:"name"`
+			} `json:"bundle"`
+		}{}
+		if err := json.Unmarshal(b, &in); err != nil {
+			return nil, err
+		}
+
+		if in.Bundle.Ref != bundleRef {
+			return nil, fmt.Errorf("unexpected bundle reference: %s", in.Bundle.Ref)
+		}
+
+		outcome := evaluator.Outcome{}
+		if in.Bundle.Name == "test" {
+			outcome.Failures = []evaluator.Result{{Message: "tests are not allowed"}}
+		}
+
+		return &output.Output{PolicyCheck: []evaluator.Outcome{outcome}}, nil
+	}
+
+	cases := []struct {
+		name      string
+		signature error
+		expected  map[string][]string
+	}{
+		{
+			name: "signed",
+			expected: map[string][]string{
+				bundleRef + "/task/build": nil,
+				bundleRef + "/task/test":  {"tests are not allowed"},
+			},
+		},
+		{
+			name:      "unsigned",
+			signature: errors.New("no signatures found"),
+			expected: map[string][]string{
+				bundleRef + "/task/build": {"Image signature check failed: no signatures found"},
+				bundleRef + "/task/test":  {"tests are not allowed", "Image signature check failed: no signatures found"},
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			client := fake.FakeClient{}
+			client.On("Image", ref).Return(bundle, nil)
+			client.On("VerifyImageSignatures", bundleDigest, mock.Anything).Return(nil, true, c.signature)
+
+			ctx := utils.WithFS(context.Background(), afero.NewMemMapFs())
+			ctx = oci.WithClient(ctx, &client)
+
+			cmd := setUpCobra(validateBundleCmd(validate))
+			cmd.SetContext(ctx)
+			cmd.SetArgs([]string{
+				"validate",
+				"bundle",
+				"--bundle",
+				ref.String(),
+				"--policy",
+				fmt.Sprintf(`{"publicKey": %s}`, utils.TestPublicKeyJSON),
+				"--ignore-rekor",
+			})
+
+			var out bytes.Buffer
+			cmd.SetOut(&out)
+
+			err := cmd.Execute()
+			assert.EqualError(t, err, "success criteria not met")
+
+			report := struct {
+				Success   bool `json:"success"`
+				FilePaths []struct {
+					FilePath   string `json:"filepath"`
+					Success    bool   `json:"success"`
+					Violations []struct {
+						Message string `json:"msg"`
+					} `json:"violations"`
+				} `json:"filepaths"`
+			}{}
+			require.NoError(t, json.Unmarshal(out.Bytes(), &report))
+			assert.False(t, report.Success)
+
+			actual := map[string][]string{}
+			for _, f := range report.FilePaths {
+				var messages []string
+				for _, v := range f.Violations {
+					messages = append(messages, v.Message)
+				}
+				assert.Equal(t, len(messages) == 0, f.Success)
+				actual[f.FilePath] = messages
+			}
+
+			assert.Equal(t, c.expected, actual)
+		})
+	}
+}
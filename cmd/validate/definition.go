@@ -28,11 +28,13 @@ import (
 	"github.com/enterprise-contract/ec-cli/internal/evaluator"
 	"github.com/enterprise-contract/ec-cli/internal/format"
 	"github.com/enterprise-contract/ec-cli/internal/output"
+	"github.com/enterprise-contract/ec-cli/internal/policy"
 	"github.com/enterprise-contract/ec-cli/internal/policy/source"
 	"github.com/enterprise-contract/ec-cli/internal/utils"
+	validate_utils "github.com/enterprise-contract/ec-cli/internal/validate"
 )
 
-type definitionValidationFn func(context.Context, string, []source.PolicySource, []string) (*output.Output, error)
+type definitionValidationFn func(context.Context, string, []source.PolicySource, []string, policy.Policy) (*output.Output, error)
 
 func validateDefinitionCmd(validate definitionValidationFn) *cobra.Command {
 	data := struct {
@@ -42,6 +44,7 @@ func validateDefinitionCmd(validate definitionValidationFn) *cobra.Command {
 		output     []string
 		namespaces []string
 		strict     bool
+		policies   []string
 	}{
 		filePaths:  []string{},
 		policyURLs: []string{"oci::quay.io/enterprise-contract/ec-pipeline-policy:latest"},
@@ -57,7 +60,9 @@ func validateDefinitionCmd(validate definitionValidationFn) *cobra.Command {
 			Validate definition file conformance with the Enterprise Contract
 
 			Validate Kubernetes definition files conforms to the rego policies
-			defined in the given policy repository.
+			defined in the given policy repository, or in the sources of the given
+			EnterpriseContractPolicy, the same policy configuration used to validate
+			images.
 		`),
 
 		Example: hd.Doc(`
@@ -79,13 +84,37 @@ func validateDefinitionCmd(validate definitionValidationFn) *cobra.Command {
 				--policy git::https://github.com/enterprise-contract/ec-policies//policy/lib \
 				--policy git::https://github.com/enterprise-contract/ec-policies//policy/pipeline \
 				--data git::https://github.com/enterprise-contract/ec-policies//example/data
+
+			Use the sources of an EnterpriseContractPolicy:
+
+			  ec validate definition --file </path/to/pipeline/file> \
+				--policy-config github.com/user/repo//default?ref=main
+
+			  ec validate definition --file </path/to/pipeline/file> --policy-config my-namespace/my-policy
 		`),
 
 		Deprecated: "please use \"ec validate input\" instead.",
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := withSourceOptions(cmd); err != nil {
+				return err
+			}
 			var allErrors error
 			report := definition.NewReport()
 			showSuccesses, _ := cmd.Flags().GetBool("show-successes")
+			ctx, err := withEvaluationOptions(cmd)
+			if err != nil {
+				return err
+			}
+			var pol policy.Policy
+			if len(data.policies) > 0 {
+				policyConfiguration, err := validate_utils.GetPolicyConfigs(ctx, data.policies)
+				if err != nil {
+					return err
+				}
+				if pol, err = policy.NewInputPolicy(ctx, policyConfiguration, policy.Now); err != nil {
+					return err
+				}
+			}
 			for i := range data.filePaths {
 				fpath := data.filePaths[i]
 				var sources []source.PolicySource
@@ -95,8 +124,7 @@ func validateDefinitionCmd(validate definitionValidationFn) *cobra.Command {
 				for _, url := range data.dataURLs {
 					sources = append(sources, &source.PolicyUrl{Url: url, Kind: source.DataKind})
 				}
-				ctx := cmd.Context()
-				if out, err := validate(ctx, fpath, sources, data.namespaces); err != nil {
+				if out, err := validate(ctx, fpath, sources, data.namespaces, pol); err != nil {
 					allErrors = multierror.Append(allErrors, err)
 				} else {
 					if !showSuccesses {
@@ -132,6 +160,15 @@ func validateDefinitionCmd(validate definitionValidationFn) *cobra.Command {
 	cmd.Flags().StringSliceVar(&data.dataURLs, "data", data.dataURLs,
 		"url for policy data, go-getter style. May be used multiple times")
 
+	cmd.Flags().StringArrayVar(&data.policies, "policy-config", data.policies, hd.Doc(`
+		EnterpriseContractPolicy providing the policy and data sources, used instead of --policy
+		and --data, as:
+		* file (policy.yaml)
+		* git reference (github.com/user/repo//default?ref=main)
+		* inline JSON ('{sources: {...}, configuration: {...}}'), or
+		* Kubernetes reference ([<namespace>/]<name>)
+		Can be repeated to merge several policies, each overriding the ones before it.`))
+
 	cmd.Flags().StringSliceVarP(&data.output, "output", "o", data.output, hd.Doc(`
 		write output to a file in a specific format, e.g. yaml=/tmp/output.yaml. Use empty string
 		path for stdout, e.g. yaml. May be used multiple times. Possible formats are json and yaml
@@ -145,5 +182,8 @@ func validateDefinitionCmd(validate definitionValidationFn) *cobra.Command {
 		panic(err)
 	}
 
+	cmd.MarkFlagsMutuallyExclusive("policy-config", "policy")
+	cmd.MarkFlagsMutuallyExclusive("policy-config", "data")
+
 	return cmd
 }
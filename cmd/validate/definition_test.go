@@ -32,12 +32,13 @@ import (
 
 	"github.com/enterprise-contract/ec-cli/internal/evaluator"
 	output2 "github.com/enterprise-contract/ec-cli/internal/output"
+	"github.com/enterprise-contract/ec-cli/internal/policy"
 	"github.com/enterprise-contract/ec-cli/internal/policy/source"
 	"github.com/enterprise-contract/ec-cli/internal/utils"
 )
 
 func TestValidateDefinitionFileCommandOutput(t *testing.T) {
-	validate := func(_ context.Context, fpath string, _ []source.PolicySource, _ []string) (*output2.Output, error) {
+	validate := func(_ context.Context, fpath string, _ []source.PolicySource, _ []string, _ policy.Policy) (*output2.Output, error) {
 		return &output2.Output{PolicyCheck: []evaluator.Outcome{{FileName: fpath}}}, nil
 	}
 
@@ -85,7 +86,7 @@ func TestValidateDefinitionFilePolicySources(t *testing.T) {
 		&source.PolicyUrl{Url: "bacon-data-source", Kind: source.DataKind},
 		&source.PolicyUrl{Url: "eggs-data-source", Kind: source.DataKind},
 	}
-	validate := func(_ context.Context, fpath string, sources []source.PolicySource, _ []string) (*output2.Output, error) {
+	validate := func(_ context.Context, fpath string, sources []source.PolicySource, _ []string, _ policy.Policy) (*output2.Output, error) {
 		assert.Equal(t, expected, sources)
 		return &output2.Output{}, nil
 	}
@@ -115,6 +116,63 @@ func TestValidateDefinitionFilePolicySources(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestValidateDefinitionFilePolicyConfig(t *testing.T) {
+	validate := func(_ context.Context, _ string, _ []source.PolicySource, _ []string, p policy.Policy) (*output2.Output, error) {
+		if assert.NotNil(t, p) {
+			sources := p.Spec().Sources
+			if assert.Len(t, sources, 1) {
+				assert.Equal(t, []string{"spam-policy-source"}, sources[0].Policy)
+				assert.Equal(t, []string{"bacon-data-source"}, sources[0].Data)
+			}
+		}
+		return &output2.Output{}, nil
+	}
+
+	validateDefinitionCmd := validateDefinitionCmd(validate)
+	cmd := setUpCobra(validateDefinitionCmd)
+
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+
+	cmd.SetArgs([]string{
+		"validate",
+		"definition",
+		"--file",
+		"/path/file1.yaml",
+		"--policy-config",
+		`{"sources": [{"policy": ["spam-policy-source"], "data": ["bacon-data-source"]}]}`,
+	})
+
+	err := cmd.Execute()
+	assert.NoError(t, err)
+}
+
+func TestValidateDefinitionFilePolicyConfigExclusive(t *testing.T) {
+	validate := func(context.Context, string, []source.PolicySource, []string, policy.Policy) (*output2.Output, error) {
+		return &output2.Output{}, nil
+	}
+
+	validateDefinitionCmd := validateDefinitionCmd(validate)
+	cmd := setUpCobra(validateDefinitionCmd)
+
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+
+	cmd.SetArgs([]string{
+		"validate",
+		"definition",
+		"--file",
+		"/path/file1.yaml",
+		"--policy-config",
+		`{"sources": [{"policy": ["spam-policy-source"]}]}`,
+		"--policy",
+		"ham-policy-source",
+	})
+
+	err := cmd.Execute()
+	assert.ErrorContains(t, err, "if any flags in the group [policy-config policy] are set none of the others can be")
+}
+
 func TestDefinitionFileOutputFormats(t *testing.T) {
 	testJSONText := `{"definitions":[{"filename":"/path/file1.yaml","violations":[],"warnings":[],"successes":[]}],"success":true,"ec-version":"development"}`
 
@@ -162,7 +220,7 @@ func TestDefinitionFileOutputFormats(t *testing.T) {
 	for _, c := range cases {
 		t.Run(c.name, func(t *testing.T) {
 			fs := afero.NewMemMapFs()
-			validate := func(_ context.Context, fpath string, sources []source.PolicySource, _ []string) (*output2.Output, error) {
+			validate := func(_ context.Context, fpath string, sources []source.PolicySource, _ []string, _ policy.Policy) (*output2.Output, error) {
 				return &output2.Output{PolicyCheck: []evaluator.Outcome{{FileName: fpath}}}, nil
 			}
 
@@ -195,7 +253,7 @@ func TestDefinitionFileOutputFormats(t *testing.T) {
 }
 
 func TestValidateDefinitionFileCommandErrors(t *testing.T) {
-	validate := func(_ context.Context, fpath string, _ []source.PolicySource, _ []string) (*output2.Output, error) {
+	validate := func(_ context.Context, fpath string, _ []source.PolicySource, _ []string, _ policy.Policy) (*output2.Output, error) {
 		return nil, errors.New(fpath)
 	}
 
@@ -221,7 +279,7 @@ func TestValidateDefinitionFileCommandErrors(t *testing.T) {
 }
 
 func TestStrictOutput(t *testing.T) {
-	validate := func(_ context.Context, fpath string, _ []source.PolicySource, _ []string) (*output2.Output, error) {
+	validate := func(_ context.Context, fpath string, _ []source.PolicySource, _ []string, _ policy.Policy) (*output2.Output, error) {
 		failureResult := evaluator.Outcome{
 			FileName: fpath,
 			Failures: []evaluator.Result{
@@ -21,25 +21,33 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"sort"
+	"io"
+	"runtime/debug"
 	"strings"
+	"time"
 
 	hd "github.com/MakeNowJust/heredoc"
 	"github.com/hashicorp/go-multierror"
 	app "github.com/konflux-ci/application-api/api/v1alpha1"
 	"github.com/sigstore/cosign/v2/pkg/cosign"
+	sigstoresig "github.com/sigstore/sigstore/pkg/signature"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 
 	"github.com/enterprise-contract/ec-cli/internal/applicationsnapshot"
+	"github.com/enterprise-contract/ec-cli/internal/downloader"
 	"github.com/enterprise-contract/ec-cli/internal/evaluator"
 	"github.com/enterprise-contract/ec-cli/internal/format"
+	"github.com/enterprise-contract/ec-cli/internal/image"
+	"github.com/enterprise-contract/ec-cli/internal/logging"
 	"github.com/enterprise-contract/ec-cli/internal/output"
 	"github.com/enterprise-contract/ec-cli/internal/policy"
 	"github.com/enterprise-contract/ec-cli/internal/policy/source"
+	"github.com/enterprise-contract/ec-cli/internal/signature"
 	"github.com/enterprise-contract/ec-cli/internal/utils"
 	validate_utils "github.com/enterprise-contract/ec-cli/internal/validate"
+	"github.com/enterprise-contract/ec-cli/internal/vulnerability"
 )
 
 type imageValidationFunc func(context.Context, app.SnapshotComponent, *app.SnapshotSpec, policy.Policy, []evaluator.Evaluator, bool) (*output.Output, error)
@@ -48,30 +56,57 @@ var newConftestEvaluator = evaluator.NewConftestEvaluator
 
 func validateImageCmd(validate imageValidationFunc) *cobra.Command {
 	data := struct {
-		certificateIdentity         string
-		certificateIdentityRegExp   string
-		certificateOIDCIssuer       string
-		certificateOIDCIssuerRegExp string
-		effectiveTime               string
-		extraRuleData               []string
-		filePath                    string // Deprecated: images replaced this
-		imageRef                    string
-		info                        bool
-		input                       string // Deprecated: images replaced this
-		ignoreRekor                 bool
-		output                      []string
-		outputFile                  string
-		policy                      policy.Policy
-		policyConfiguration         string
-		publicKey                   string
-		rekorURL                    string
-		snapshot                    string
-		spec                        *app.SnapshotSpec
-		strict                      bool
-		images                      string
-		noColor                     bool
-		forceColor                  bool
-		workers                     int
+		certificateIdentity                 string
+		certificateIdentityRegExp           string
+		certificateOIDCIssuer               string
+		certificateOIDCIssuerRegExp         string
+		certificateGithubWorkflowRepository string
+		certificateGithubWorkflowRef        string
+		caIntermediates                     string
+		caRoots                             string
+		effectiveTime                       string
+		extraRuleData                       []string
+		filePath                            string // Deprecated: images replaced this
+		imageRef                            string
+		info                                bool
+		input                               string // Deprecated: images replaced this
+		ignoreRekor                         bool
+		ignoreSCT                           bool
+		skipCertificateChecks               bool
+		output                              []string
+		outputFile                          string
+		policy                              policy.Policy
+		policyConfiguration                 string
+		platforms                           []string
+		policies                            []string
+		publicKey                           string
+		rekorInclusionProof                 bool
+		rekorOffline                        bool
+		requireDigest                       bool
+		rekorURL                            string
+		tufMirror                           string
+		tufRoot                             string
+		snapshot                            string
+		spec                                *app.SnapshotSpec
+		strict                              bool
+		images                              string
+		noColor                             bool
+		color                               string
+		workers                             int
+		allowedBaseImageRegistries          []string
+		maxAttestationAge                   string
+		maxAttestationAgeDuration           time.Duration
+		failOnReview                        bool
+		failOn                              string
+		groupResults                        bool
+		streamOutput                        string
+		vsaSigningKey                       string
+		saveSources                         string
+		requireAttestations                 []string
+		requiredAttestations                image.RequiredAttestations
+		gateOutput                          string
+		evaluateUnattested                  bool
+		vulnerabilityScanURL                string
 	}{
 		strict:  true,
 		workers: 5,
@@ -116,6 +151,22 @@ func validateImageCmd(validate imageValidationFunc) *cobra.Command {
 
 			  ec validate image --images my-app.yaml
 
+			Validate only the linux/amd64 and linux/arm64 images of a multi-platform image
+			index, reporting on each of them:
+
+			  ec validate image --image registry/name:tag --platform linux/amd64 --platform linux/arm64
+
+			Validate only images referenced by digest, rejecting references by tag:
+
+			  ec validate image --images images.txt --require-digest --policy my-policy
+
+			Validate multiple images listed one per line in a file, or given on the standard
+			input, with one report for all images:
+
+			  ec validate image --images images.txt
+
+			  cat images.txt | ec validate image --images -
+
 			Validate attestation of images from an inline ApplicationSnapshot Spec:
 
 			  ec validate image --images '{"components":[{"containerImage":"<image url>"}]}'
@@ -128,6 +179,25 @@ func validateImageCmd(validate imageValidationFunc) *cobra.Command {
 
 			  ec validate image --image registry/name:tag --rekor-url https://rekor.example.org
 
+			Verify the inclusion proofs of the Rekor entries of the signatures:
+
+			  ec validate image --image registry/name:tag --rekor-url https://rekor.example.org --rekor-inclusion-proof
+
+			Verify the signatures only against their Rekor bundles, without contacting Rekor:
+
+			  ec validate image --image registry/name:tag --rekor-offline
+
+			Use the trust root of a private Sigstore deployment:
+
+			  ec validate image --image registry/name:tag --tuf-mirror https://tuf.example.org \
+			    --tuf-root root.json --rekor-url https://rekor.example.org
+
+			Verify keyless signatures with certificates issued by a private certificate authority:
+
+			  ec validate image --image registry/name:tag --ca-roots roots.pem \
+			    --ca-intermediates intermediates.pem --certificate-identity-regexp '.*' \
+			    --certificate-oidc-issuer-regexp '.*'
+
 			Return a non-zero status code on validation failure:
 
 			  ec validate image --image registry/name:tag
@@ -169,10 +239,32 @@ func validateImageCmd(validate imageValidationFunc) *cobra.Command {
 
 			  ec validate image --image registry/name:tag --output yaml --output appstudio=<path>
 
+			Print a compact table with the number of violations, warnings and successes and
+			the verdict of each component
+
+			  ec validate image --image registry/name:tag --output summary-table
+
+			Print nothing, only report the outcome of the validation via the exit code, e.g.
+			in shell pipelines
+
+			  ec validate image --image registry/name:tag --quiet
+
 			Write the data used in the policy evaluation to a file in YAML format
 
 			  ec validate image --image registry/name:tag --output data=<path>
 
+			Write a readable report in Markdown format, e.g. to attach to a pull request, and
+			as a HTML page, e.g. to publish as a build artifact
+
+			  ec validate image --image registry/name:tag --output markdown=report.md --output html=report.html
+
+			Render the report to a file through a Go template, executed with the report as
+			found in the JSON format, optionally transformed first by the output rule of a
+			Rego file
+
+			  ec validate image --image registry/name:tag \
+			    --output 'template=<path>?template=slack.tmpl&rego=transform.rego'
+
 			Validate a single image with keyless workflow.
 
 			  ec validate image --image registry/name:tag --policy my-policy \
@@ -186,23 +278,81 @@ func validateImageCmd(validate imageValidationFunc) *cobra.Command {
 			    --certificate-identity-regexp '^https://github\.com' \
 			    --certificate-oidc-issuer-regexp 'githubusercontent' \
 			    --rekor-url 'https://rekor.sigstore.dev'
+
+			Require the image to be signed by a GitHub Actions workflow of a repository
+			running on the main branch.
+
+			  ec validate image --image registry/name:tag --policy my-policy \
+			    --certificate-identity-regexp '^https://github\.com/org/repo/' \
+			    --certificate-oidc-issuer 'https://token.actions.githubusercontent.com' \
+			    --certificate-github-workflow-repository 'org/repo' \
+			    --certificate-github-workflow-ref 'refs/heads/main'
 		`),
 
 		PreRunE: func(cmd *cobra.Command, args []string) (allErrors error) {
+			if err := withSourceOptions(cmd); err != nil {
+				return err
+			}
 			ctx := cmd.Context()
+			defer func() {
+				if allErrors != nil {
+					allErrors = writeGateStatus(cmd, data.gateOutput, nil, data.strict, allErrors)
+				}
+			}()
 			if s, err := applicationsnapshot.DetermineInputSpec(ctx, applicationsnapshot.Input{
-				File:     data.filePath,
-				JSON:     data.input,
-				Image:    data.imageRef,
-				Snapshot: data.snapshot,
-				Images:   data.images,
+				File:          data.filePath,
+				JSON:          data.input,
+				Image:         data.imageRef,
+				Snapshot:      data.snapshot,
+				Images:        data.images,
+				Stdin:         cmd.InOrStdin(),
+				Platforms:     data.platforms,
+				RequireDigest: data.requireDigest,
 			}); err != nil {
 				allErrors = multierror.Append(allErrors, err)
 			} else {
 				data.spec = s
 			}
 
-			policyConfiguration, err := validate_utils.GetPolicyConfig(ctx, data.policyConfiguration)
+			if _, _, err := utils.ColorModeFlags(data.color); err != nil {
+				allErrors = multierror.Append(allErrors, err)
+			}
+
+			if data.workers < 1 {
+				allErrors = multierror.Append(allErrors, fmt.Errorf("invalid number of workers %d, at least one worker is needed", data.workers))
+			}
+
+			if data.maxAttestationAge != "" {
+				if d, err := image.ParseMaxAttestationAge(data.maxAttestationAge); err != nil {
+					allErrors = multierror.Append(allErrors, err)
+				} else {
+					data.maxAttestationAgeDuration = d
+				}
+			}
+
+			if data.vulnerabilityScanURL != "" {
+				if err := vulnerability.ValidateScannerURL(data.vulnerabilityScanURL); err != nil {
+					allErrors = multierror.Append(allErrors, err)
+				}
+			}
+
+			if data.failOn != "" {
+				if severity, err := evaluator.ParseSeverity(data.failOn); err != nil {
+					allErrors = multierror.Append(allErrors, err)
+				} else {
+					data.failOn = severity
+				}
+			}
+
+			if len(data.requireAttestations) > 0 {
+				if r, err := image.ParseRequiredAttestations(data.requireAttestations); err != nil {
+					allErrors = multierror.Append(allErrors, err)
+				} else {
+					data.requiredAttestations = r
+				}
+			}
+
+			policyConfiguration, err := validate_utils.GetPolicyConfigs(ctx, data.policies)
 			if err != nil {
 				allErrors = multierror.Append(allErrors, err)
 				return
@@ -218,9 +368,25 @@ func validateImageCmd(validate imageValidationFunc) *cobra.Command {
 					SubjectRegExp: data.certificateIdentityRegExp,
 				},
 				IgnoreRekor: data.ignoreRekor,
-				PolicyRef:   data.policyConfiguration,
-				PublicKey:   data.publicKey,
-				RekorURL:    data.rekorURL,
+				Keyless: policy.KeylessConstraints{
+					GithubWorkflowRepository: data.certificateGithubWorkflowRepository,
+					GithubWorkflowRef:        data.certificateGithubWorkflowRef,
+					IgnoreSCT:                data.ignoreSCT,
+				},
+				PKI: policy.PKI{
+					Roots:         data.caRoots,
+					Intermediates: data.caIntermediates,
+				},
+				PolicyRef:             data.policyConfiguration,
+				PublicKey:             data.publicKey,
+				RekorInclusionProof:   data.rekorInclusionProof,
+				RekorOffline:          data.rekorOffline,
+				RekorURL:              data.rekorURL,
+				SkipCertificateChecks: data.skipCertificateChecks,
+				TUF: policy.TUFRoot{
+					Mirror: data.tufMirror,
+					Root:   data.tufRoot,
+				},
 			}); err != nil {
 				allErrors = multierror.Append(allErrors, err)
 			} else {
@@ -280,7 +446,12 @@ func validateImageCmd(validate imageValidationFunc) *cobra.Command {
 			return
 		},
 
-		RunE: func(cmd *cobra.Command, args []string) error {
+		RunE: func(cmd *cobra.Command, args []string) (runErr error) {
+			var report *applicationsnapshot.Report
+			defer func() {
+				runErr = writeGateStatus(cmd, data.gateOutput, report, data.strict, runErr)
+			}()
+
 			type result struct {
 				err         error
 				component   applicationsnapshot.Component
@@ -291,6 +462,14 @@ func validateImageCmd(validate imageValidationFunc) *cobra.Command {
 			appComponents := data.spec.Components
 			evaluators := []evaluator.Evaluator{}
 
+			var vsaSigner sigstoresig.Signer
+			if data.vsaSigningKey != "" {
+				var err error
+				if vsaSigner, err = signature.LoadSigner(cmd.Context(), data.vsaSigningKey); err != nil {
+					return err
+				}
+			}
+
 			// Return an evaluator for each of these
 			for _, sourceGroup := range data.policy.Spec().Sources {
 				// Todo: Make each fetch run concurrently
@@ -317,14 +496,39 @@ func validateImageCmd(validate imageValidationFunc) *cobra.Command {
 
 			showSuccesses, _ := cmd.Flags().GetBool("show-successes")
 
+			ctx, err := withEvaluationOptions(cmd)
+			if err != nil {
+				return err
+			}
+			if len(data.allowedBaseImageRegistries) > 0 {
+				ctx = image.WithAllowedBaseImageRegistries(ctx, data.allowedBaseImageRegistries)
+			}
+			if data.maxAttestationAgeDuration > 0 {
+				ctx = image.WithMaxAttestationAge(ctx, data.maxAttestationAgeDuration)
+			}
+			if len(data.requiredAttestations) > 0 {
+				ctx = image.WithRequiredAttestations(ctx, data.requiredAttestations)
+			}
+			if data.evaluateUnattested {
+				ctx = image.WithEvaluateUnattested(ctx)
+			}
+			if data.vulnerabilityScanURL != "" {
+				ctx = vulnerability.WithScannerURL(ctx, data.vulnerabilityScanURL)
+			}
+
 			// worker is responsible for processing one component at a time from the jobs channel,
 			// and for emitting a corresponding result for the component on the results channel.
 			worker := func(id int, jobs <-chan app.SnapshotComponent, results chan<- result) {
 				log.Debugf("Starting worker %d", id)
 				for comp := range jobs {
 					log.Debugf("Worker %d got a component %q", id, comp.ContainerImage)
-					ctx := cmd.Context()
-					out, err := validate(ctx, comp, data.spec, data.policy, evaluators, data.info)
+					start := time.Now()
+					out, err := validateComponent(ctx, validate, comp, data.spec, data.policy, evaluators, data.info)
+					log.WithFields(log.Fields{
+						logging.ComponentField: comp.Name,
+						logging.ImageField:     comp.ContainerImage,
+						logging.DurationField:  time.Since(start).Seconds(),
+					}).Debug("Validated component")
 					res := result{
 						err: err,
 						component: applicationsnapshot.Component{
@@ -337,6 +541,7 @@ func validateImageCmd(validate imageValidationFunc) *cobra.Command {
 					if err == nil {
 						res.component.Violations = out.Violations()
 						res.component.Warnings = out.Warnings()
+						res.component.Reviews = out.Reviews()
 
 						successes := out.Successes()
 						res.component.SuccessCount = len(successes)
@@ -347,11 +552,16 @@ func validateImageCmd(validate imageValidationFunc) *cobra.Command {
 						res.component.Signatures = out.Signatures
 						res.component.Attestations = out.Attestations
 						res.component.ContainerImage = out.ImageURL
+						res.component.Platform = out.Platform
+						res.component.ResolvedFrom = out.ResolvedFrom
 						res.data = out.Data
 						res.component.Attestations = out.Attestations
 						res.policyInput = out.PolicyInput
 					}
-					res.component.Success = err == nil && len(res.component.Violations) == 0
+					res.component.Success = err == nil && len(evaluator.FailingResults(res.component.Violations, data.failOn)) == 0
+					if data.failOnReview && len(res.component.Reviews) > 0 {
+						res.component.Success = false
+					}
 
 					results <- res
 				}
@@ -360,14 +570,15 @@ func validateImageCmd(validate imageValidationFunc) *cobra.Command {
 
 			numComponents := len(appComponents)
 
-			// Set numWorkers to the value from our flag. The default is 5.
-			numWorkers := data.workers
+			// Set numWorkers to the value from our flag, the default is 5, there's
+			// no need for more workers than there are components
+			numWorkers := min(data.workers, numComponents)
 
 			jobs := make(chan app.SnapshotComponent, numComponents)
 			results := make(chan result, numComponents)
 			// Initialize each worker. They will wait patiently until a job is sent to the jobs
 			// channel, or the jobs channel is closed.
-			for i := 0; i <= numWorkers; i++ {
+			for i := 0; i < numWorkers; i++ {
 				go worker(i, jobs, results)
 			}
 			// Initialize all the jobs. Each worker will pick a job from the channel when the worker
@@ -377,6 +588,19 @@ func validateImageCmd(validate imageValidationFunc) *cobra.Command {
 			}
 			close(jobs)
 
+			var stream *applicationsnapshot.StreamWriter
+			if data.streamOutput != "" {
+				w, closer, err := streamWriter(cmd, data.streamOutput)
+				if err != nil {
+					return err
+				}
+				defer closer()
+				stream = applicationsnapshot.NewStreamWriter(w)
+			}
+			// With only the streamed output requested the report is not written,
+			// so the results not needed to determine the outcome are not retained
+			retainAll := stream == nil || len(data.output) > 0 || len(data.outputFile) > 0
+
 			var components []applicationsnapshot.Component
 			var manyData [][]evaluator.Data
 			var manyPolicyInput [][]byte
@@ -386,37 +610,78 @@ func validateImageCmd(validate imageValidationFunc) *cobra.Command {
 				if r.err != nil {
 					e := fmt.Errorf("error validating image %s of component %s: %w", r.component.ContainerImage, r.component.Name, r.err)
 					allErrors = multierror.Append(allErrors, e)
-				} else {
-					components = append(components, r.component)
+					continue
+				}
+
+				if stream != nil {
+					if err := stream.WriteComponent(r.component); err != nil {
+						allErrors = multierror.Append(allErrors, fmt.Errorf("unable to write the streamed output: %w", err))
+					}
+				}
+
+				if retainAll {
 					manyData = append(manyData, r.data)
 					manyPolicyInput = append(manyPolicyInput, r.policyInput)
+				} else {
+					r.component.Successes = nil
+					r.component.Signatures = nil
+					r.component.Attestations = nil
 				}
+				components = append(components, r.component)
 			}
 			close(results)
+
+			if data.saveSources != "" {
+				if err := source.SaveSources(ctx, data.saveSources); err != nil {
+					allErrors = multierror.Append(allErrors, err)
+				}
+			}
+
 			if allErrors != nil {
 				return allErrors
 			}
 
-			// Ensure some consistency in output.
-			sort.Slice(components, func(i, j int) bool {
-				return components[i].ContainerImage > components[j].ContainerImage
-			})
-
 			if len(data.outputFile) > 0 {
 				data.output = append(data.output, fmt.Sprintf("%s=%s", applicationsnapshot.JSON, data.outputFile))
 			}
 
-			report, err := applicationsnapshot.NewReport(data.snapshot, components, data.policy, manyData, manyPolicyInput, showSuccesses)
+			r, err := applicationsnapshot.NewReport(data.snapshot, components, data.policy, manyData, manyPolicyInput, showSuccesses)
 			if err != nil {
 				return err
 			}
-			p := format.NewTargetParser(applicationsnapshot.JSON, format.Options{ShowSuccesses: showSuccesses}, cmd.OutOrStdout(), utils.FS(cmd.Context()))
-			utils.SetColorEnabled(data.noColor, data.forceColor)
-			if err := report.WriteAll(data.output, p); err != nil {
-				return err
+			r.SourceMirrors = downloader.MirrorsUsed(cmd.Context())
+			report = &r
+			if stream != nil {
+				if err := stream.WriteReport(r); err != nil {
+					return fmt.Errorf("unable to write the streamed output: %w", err)
+				}
+			}
+			// With --quiet only the exit code is of interest, unless the
+			// report output was explicitly requested
+			quiet, _ := cmd.Flags().GetBool("quiet")
+			if retainAll && (!quiet || len(data.output) > 0) {
+				p := format.NewTargetParser(applicationsnapshot.JSON, format.Options{ShowSuccesses: showSuccesses, GroupResults: data.groupResults}, cmd.OutOrStdout(), utils.FS(cmd.Context()))
+				noColor, forceColor, _ := utils.ColorModeFlags(data.color)
+				utils.SetColorEnabled(data.noColor || noColor, forceColor)
+				if err := report.WriteAll(data.output, p); err != nil {
+					return err
+				}
+			}
+
+			if vsaSigner != nil && report.Success {
+				pushed, err := applicationsnapshot.AttachSLSAVSAs(cmd.Context(), r, vsaPolicyURI(data.policies), vsaSigner)
+				for _, ref := range pushed {
+					log.Infof("Attached the VSA %s", ref)
+				}
+				if err != nil {
+					return err
+				}
 			}
 
 			if data.strict && !report.Success {
+				if quiet {
+					cmd.SilenceErrors = true
+				}
 				return errors.New("success criteria not met")
 			}
 
@@ -424,17 +689,24 @@ func validateImageCmd(validate imageValidationFunc) *cobra.Command {
 		},
 	}
 
-	cmd.Flags().StringVarP(&data.policyConfiguration, "policy", "p", data.policyConfiguration, hd.Doc(`
+	cmd.Flags().StringArrayVarP(&data.policies, "policy", "p", data.policies, hd.Doc(`
 		Policy configuration as:
-		  * Kubernetes reference ([<namespace>/]<name>)
-		  * file (policy.yaml)
+		  * Kubernetes reference ([<namespace>/]<name> or <name>@<namespace>)
+		  * file (policy.yaml or file:policy.yaml)
+		  * Kubernetes ConfigMap key (configmap:<name>[@<namespace>][#<key>] or
+		    configmap://<namespace>/<name>[/<key>], the key defaults to policy.yaml)
+		  * OCI artifact (oci::quay.io/org/policy:tag)
 		  * git reference (github.com/user/repo//default?ref=main), or
-		  * inline JSON ('{sources: {...}, configuration: {...}}')")`))
+		  * inline JSON ('{sources: {...}, configuration: {...}}')")
+		Can be repeated to merge several policies, each overriding the ones before it.`))
 
 	cmd.Flags().StringVarP(&data.imageRef, "image", "i", data.imageRef, "OCI image reference")
 
 	cmd.Flags().StringVarP(&data.publicKey, "public-key", "k", data.publicKey,
-		"path to the public key. Overrides publicKey from EnterpriseContractPolicy")
+		hd.Doc(`
+			path to the public key, or the reference to a key in a KMS, e.g. awskms://,
+			gcpkms://, azurekms:// or hashivault://. Overrides publicKey and publicKeys from
+			EnterpriseContractPolicy`))
 
 	cmd.Flags().StringVarP(&data.rekorURL, "rekor-url", "r", data.rekorURL,
 		"Rekor URL. Overrides rekorURL from EnterpriseContractPolicy")
@@ -442,6 +714,27 @@ func validateImageCmd(validate imageValidationFunc) *cobra.Command {
 	cmd.Flags().BoolVar(&data.ignoreRekor, "ignore-rekor", data.ignoreRekor,
 		"Skip Rekor transparency log checks during validation.")
 
+	cmd.Flags().BoolVar(&data.rekorOffline, "rekor-offline", data.rekorOffline, hd.Doc(`
+		Verify the Rekor transparency log entries only against the Rekor bundles embedded in the
+		signatures, without contacting Rekor. Signatures without a Rekor bundle are rejected.`))
+
+	cmd.Flags().BoolVar(&data.rekorInclusionProof, "rekor-inclusion-proof", data.rekorInclusionProof, hd.Doc(`
+		Verify the inclusion proofs of the Rekor transparency log entries recorded in the Rekor
+		bundles of the signatures, fetching them from Rekor. Requires the Rekor URL.`))
+
+	cmd.Flags().StringVar(&data.tufMirror, "tuf-mirror", data.tufMirror, hd.Doc(`
+		URL of the TUF repository of a private Sigstore deployment to take the trusted Fulcio
+		certificates and the Rekor and CT log keys from. Overrides tuf.mirror from the policy`))
+
+	cmd.Flags().StringVar(&data.tufRoot, "tuf-root", data.tufRoot, hd.Doc(`
+		path to, or URL of, the initial trusted root.json of the TUF repository given by
+		--tuf-mirror. Overrides tuf.root from the policy`))
+
+	cmd.Flags().BoolVar(&data.skipCertificateChecks, "skip-certificate-checks", data.skipCertificateChecks, hd.Doc(`
+		Skip the verification of the certificate chain and of the embedded SCT of keyless
+		signatures. Only allowed in offline mode, i.e. with --ignore-rekor, with a trust bundle
+		loaded via the SIGSTORE_ROOT_FILE environment variable.`))
+
 	cmd.Flags().StringVar(&data.certificateIdentity, "certificate-identity", data.certificateIdentity,
 		"URL of the certificate identity for keyless verification")
 
@@ -454,6 +747,28 @@ func validateImageCmd(validate imageValidationFunc) *cobra.Command {
 	cmd.Flags().StringVar(&data.certificateOIDCIssuerRegExp, "certificate-oidc-issuer-regexp", data.certificateOIDCIssuerRegExp,
 		"Regular expresssion for the URL of the certificate OIDC issuer for keyless verification")
 
+	cmd.Flags().StringVar(&data.certificateGithubWorkflowRepository, "certificate-github-workflow-repository", data.certificateGithubWorkflowRepository, hd.Doc(`
+		GitHub repository, e.g. org/repo, of the workflow recorded in the certificate for keyless
+		verification. Overrides keyless.githubWorkflowRepository from the policy`))
+
+	cmd.Flags().StringVar(&data.certificateGithubWorkflowRef, "certificate-github-workflow-ref", data.certificateGithubWorkflowRef, hd.Doc(`
+		Git ref, e.g. refs/heads/main, of the workflow recorded in the certificate for keyless
+		verification. Overrides keyless.githubWorkflowRef from the policy`))
+
+	cmd.Flags().BoolVar(&data.ignoreSCT, "ignore-sct", data.ignoreSCT, hd.Doc(`
+		Do not require a signed certificate timestamp (SCT) in the certificates of keyless
+		signatures, e.g. when signing with a private Fulcio instance without a certificate
+		transparency log. The certificate chain is still verified.`))
+
+	cmd.Flags().StringVar(&data.caRoots, "ca-roots", data.caRoots, hd.Doc(`
+		path to, or URL of, a bundle of PEM encoded root certificates of a private certificate
+		authority issuing the certificates of keyless signatures instead of Fulcio. Overrides
+		pki.roots from the policy`))
+
+	cmd.Flags().StringVar(&data.caIntermediates, "ca-intermediates", data.caIntermediates, hd.Doc(`
+		path to, or URL of, a bundle of PEM encoded intermediate certificates of the private
+		certificate authority given by --ca-roots. Overrides pki.intermediates from the policy`))
+
 	// Deprecated: images replaced this
 	cmd.Flags().StringVarP(&data.filePath, "file-path", "f", data.filePath,
 		"DEPRECATED - use --images: path to ApplicationSnapshot Spec JSON file")
@@ -462,8 +777,22 @@ func validateImageCmd(validate imageValidationFunc) *cobra.Command {
 	cmd.Flags().StringVarP(&data.input, "json-input", "j", data.input,
 		"DEPRECATED - use --images: JSON representation of an ApplicationSnapshot Spec")
 
-	cmd.Flags().StringVar(&data.images, "images", data.images,
-		"path to ApplicationSnapshot Spec JSON file or JSON representation of an ApplicationSnapshot Spec")
+	cmd.Flags().StringVar(&data.images, "images", data.images, hd.Doc(`
+		path to ApplicationSnapshot Spec JSON file or JSON representation of an ApplicationSnapshot Spec.
+		The file can also hold a JSON list of image references, or image references one per line.
+		Use "-" to read from the standard input`))
+
+	cmd.Flags().StringSliceVar(&data.platforms, "platform", data.platforms, hd.Doc(`
+		platform, in the os[/arch[/variant]] form, e.g. linux/arm64/v8, of the images to
+		validate from image indexes. The images of an image index are validated, and reported
+		on, individually. By default all of them are validated, with this flag only those of
+		the given platforms. May be used multiple times.`))
+
+	cmd.Flags().BoolVar(&data.requireDigest, "require-digest", data.requireDigest, hd.Doc(`
+		require the image references to be pinned to a digest, e.g. registry/name@sha256:...
+		or registry/name:tag@sha256:..., image references by tag only are rejected. By default
+		the tags are resolved to digests before validation and the tag is reported next to the
+		digest the image was validated by.`))
 
 	cmd.Flags().StringSliceVar(&data.output, "output", data.output, hd.Doc(`
 		write output to a file in a specific format. Use empty string path for stdout.
@@ -502,11 +831,86 @@ func validateImageCmd(validate imageValidationFunc) *cobra.Command {
 	cmd.Flags().BoolVar(&data.noColor, "no-color", data.info, hd.Doc(`
 		Disable color when using text output even when the current terminal supports it`))
 
-	cmd.Flags().BoolVar(&data.forceColor, "color", data.info, hd.Doc(`
-		Enable color when using text output even when the current terminal does not support it`))
+	cmd.Flags().StringVar(&data.color, "color", utils.ColorAuto, hd.Doc(`
+		When to use color, and links to the rule documentation, in the text output: "always",
+		"never" or "auto" to use them only when writing to a terminal. Without a value
+		"always" is used`))
+	cmd.Flags().Lookup("color").NoOptDefVal = utils.ColorAlways
 
 	cmd.Flags().IntVar(&data.workers, "workers", data.workers, hd.Doc(`
-		Number of workers to use for validation. Defaults to 5.`))
+		Number of components to validate concurrently. The policy sources are downloaded once
+		for all workers, and each worker evaluates the policies with a policy engine compiled for
+		it on its first evaluation. Defaults to 5.`))
+
+	cmd.Flags().StringSliceVar(&data.allowedBaseImageRegistries, "allowed-base-image-registry", data.allowedBaseImageRegistries, hd.Doc(`
+		Registry, or repository prefix, base images are allowed to come from. Base images are
+		taken from the materials of the SLSA Provenance. When set, a violation is reported for
+		any base image not matching one of the values. May be used multiple times.`))
+
+	cmd.Flags().StringVar(&data.saveSources, "save-sources", data.saveSources, hd.Doc(`
+		Path of a tar archive to write with all policy, data and configuration sources downloaded
+		during validation, including a manifest.json listing the source URLs and digests.`))
+
+	cmd.Flags().BoolVar(&data.evaluateUnattested, "evaluate-without-attestations", data.evaluateUnattested, hd.Doc(`
+		Evaluate the policy also for images without attestations, e.g. for policies with rules
+		inspecting only the image labels and annotations. The missing attestations are still
+		reported as violations.`))
+
+	cmd.Flags().StringVar(&data.vulnerabilityScanURL, "vulnerability-scan-url", data.vulnerabilityScanURL, hd.Doc(`
+		URL of a scanner API returning the Clair, Trivy or Grype vulnerability report of an image
+		in JSON format, with {digest} replaced by the digest of the image, e.g.
+		"https://clair.example.com/matcher/api/v1/vulnerability_report/{digest}". The report is
+		included in the policy input next to the reports from the vulnerability attestations. The
+		bearer token from the EC_VULNERABILITY_SCAN_TOKEN environment variable, if set, is used
+		to authenticate.`))
+
+	cmd.Flags().StringVar(&data.gateOutput, "gate-output", data.gateOutput, hd.Doc(`
+		Path of a file to write the gate status to, a JSON object with the "status", either "pass"
+		or "fail", the "reason" and the "violationCount". The status is "fail" exactly when the
+		command exits with a non-zero status.`))
+
+	cmd.Flags().StringVar(&data.maxAttestationAge, "max-attestation-age", data.maxAttestationAge, hd.Doc(`
+		Maximum age of the provenance, given as a number of days, e.g. "30d", or as a duration,
+		e.g. "36h". The age is determined from the build finish time recorded in the provenance.
+		When set, a violation is reported for images with older, or without, provenance.`))
+
+	cmd.Flags().StringSliceVar(&data.requireAttestations, "require-attestations", data.requireAttestations, hd.Doc(`
+		Require attestations of the given predicate type signed by at least the given number of
+		distinct trusted signers, in the form of predicateType>=N, e.g.
+		"https://slsa.dev/provenance/v0.2>=2". Signers are distinguished by the certificate identity
+		and issuer, or by the public key verifying the attestations, so multiple signers require
+		the keyless workflow or multiple public keys listed in the policy. When set, a violation is
+		reported for images not meeting the threshold. May be used multiple times.`))
+
+	cmd.Flags().BoolVar(&data.failOnReview, "fail-on-review", data.failOnReview, hd.Doc(`
+		Consider components with results from review rules, i.e. warn_review rules, as not
+		successful. By default such results are reported as requiring review without affecting
+		the success of the validation.`))
+
+	cmd.Flags().BoolVar(&data.groupResults, "group-results", data.groupResults, hd.Doc(`
+		Report identical results of multiple components once, listing the components with each
+		result, in the json, yaml and text output formats. Can also be set per output with the
+		group-results option, for example: --output text?group-results=true`))
+
+	cmd.Flags().StringVar(&data.streamOutput, "stream-output", data.streamOutput, hd.Doc(`
+		Path of a file to write newline delimited JSON to, one record per component written as
+		soon as the component is validated followed by a record with the outcome of the
+		validation. Use "-" to write to the standard output. When no --output is given, the
+		report is not written and only the results needed to determine the outcome are kept in
+		memory.`))
+
+	cmd.Flags().StringVar(&data.vsaSigningKey, "vsa-signing-key", data.vsaSigningKey, hd.Doc(`
+		Reference of the private key, a path or a KMS URI, to sign a SLSA Verification Summary
+		Attestation (VSA) with. When given and the validation is successful, a VSA is generated
+		for the image of each component, signed, and attached to the image as an OCI artifact
+		discoverable using the referrers API. The password of an encrypted key is read from the
+		COSIGN_PASSWORD environment variable.`))
+
+	cmd.Flags().StringVar(&data.failOn, "fail-on", data.failOn, hd.Doc(`
+		Consider only the violations at least as severe as the given severity, one of: critical,
+		high, medium, low, when determining the success of the validation. Less severe violations
+		are still reported. Violations of rules without a severity are always considered. By
+		default all violations are considered.`))
 
 	if len(data.input) > 0 || len(data.filePath) > 0 || len(data.images) > 0 {
 		if err := cmd.MarkFlagRequired("image"); err != nil {
@@ -516,3 +920,69 @@ func validateImageCmd(validate imageValidationFunc) *cobra.Command {
 
 	return cmd
 }
+
+// validateComponent invokes validate for a single component recovering from
+// any panic. A panic is recorded as a violation of the component so the
+// validation of the remaining components can complete.
+func validateComponent(ctx context.Context, validate imageValidationFunc, comp app.SnapshotComponent, spec *app.SnapshotSpec, p policy.Policy, evaluators []evaluator.Evaluator, detailed bool) (out *output.Output, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Debugf("Recovered from panic while validating image %s: %v\n%s", comp.ContainerImage, r, debug.Stack())
+			out = &output.Output{ImageURL: comp.ContainerImage, Detailed: detailed, Policy: p}
+			out.SetPolicyCheck([]evaluator.Outcome{
+				{
+					Failures: []evaluator.Result{{
+						Message: fmt.Sprintf("Unexpected error validating the image: %v", r),
+						Metadata: map[string]interface{}{
+							"code": "builtin.image.validation_panic",
+						},
+					}},
+				},
+			})
+			err = nil
+		}
+	}()
+
+	return validate(ctx, comp, spec, p, evaluators, detailed)
+}
+
+// vsaPolicyURI returns the policy reference to include in the VSA, or an
+// empty string if the policy was given inline or merged from multiple
+// policies
+func vsaPolicyURI(policies []string) string {
+	if len(policies) != 1 || strings.ContainsAny(policies[0], "{\n") {
+		return ""
+	}
+
+	return policies[0]
+}
+
+// writeGateStatus writes the gate status to the file at the given path, if
+// provided, returning the error the command exits with
+func writeGateStatus(cmd *cobra.Command, path string, report *applicationsnapshot.Report, strict bool, err error) error {
+	if path == "" {
+		return err
+	}
+
+	status := applicationsnapshot.NewGateStatus(report, strict, err)
+	if writeErr := applicationsnapshot.WriteGateStatus(utils.FS(cmd.Context()), path, status); writeErr != nil {
+		return multierror.Append(err, fmt.Errorf("unable to write the gate status: %w", writeErr))
+	}
+
+	return err
+}
+
+// streamWriter returns the writer for the streamed output at the given path,
+// the standard output for "-", and a function closing it
+func streamWriter(cmd *cobra.Command, path string) (io.Writer, func(), error) {
+	if path == "-" {
+		return cmd.OutOrStdout(), func() {}, nil
+	}
+
+	f, err := utils.FS(cmd.Context()).Create(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to create the streamed output file: %w", err)
+	}
+
+	return f, func() { f.Close() }, nil
+}
@@ -24,11 +24,14 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strconv"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	hd "github.com/MakeNowJust/heredoc"
+	ecc "github.com/enterprise-contract/enterprise-contract-controller/api/v1alpha1"
 	"github.com/gkampitakis/go-snaps/snaps"
 	app "github.com/konflux-ci/application-api/api/v1alpha1"
 	"github.com/sigstore/cosign/v2/pkg/cosign"
@@ -36,6 +39,7 @@ import (
 	"github.com/sirupsen/logrus/hooks/test"
 	"github.com/spf13/afero"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/enterprise-contract/ec-cli/internal/applicationsnapshot"
 	"github.com/enterprise-contract/ec-cli/internal/evaluator"
@@ -58,6 +62,20 @@ var rootArgs = []string{
 	"image",
 }
 
+// withoutReportDigest removes the digest from the report in JSON format, the
+// digest changes with any change to the report so it is checked separately
+func withoutReportDigest(t *testing.T, report string) string {
+	r := map[string]any{}
+	require.NoError(t, json.Unmarshal([]byte(report), &r))
+	assert.Regexp(t, `^sha256:[a-f0-9]{64}$`, r["report-digest"])
+	delete(r, "report-digest")
+
+	stripped, err := json.Marshal(r)
+	require.NoError(t, err)
+
+	return string(stripped)
+}
+
 func Test_determineInputSpec(t *testing.T) {
 	cases := []struct {
 		name      string
@@ -289,54 +307,830 @@ func Test_ValidateImageCommand(t *testing.T) {
 		}, nil
 	}
 
-	validateImageCmd := validateImageCmd(validate)
-	cmd := setUpCobra(validateImageCmd)
+	validateImageCmd := validateImageCmd(validate)
+	cmd := setUpCobra(validateImageCmd)
+
+	client := fake.FakeClient{}
+	commonMockClient(&client)
+	ctx := utils.WithFS(context.Background(), afero.NewMemMapFs())
+	ctx = oci.WithClient(ctx, &client)
+	cmd.SetContext(ctx)
+
+	effectiveTimeTest := time.Now().UTC().Format(time.RFC3339Nano)
+
+	cmd.SetArgs(append(rootArgs, []string{
+		"--image",
+		"registry/image:tag",
+		"--policy",
+		fmt.Sprintf(`{"publicKey": %s}`, utils.TestPublicKeyJSON),
+		"--effective-time",
+		effectiveTimeTest,
+	}...))
+
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+
+	utils.SetTestRekorPublicKey(t)
+
+	err := cmd.Execute()
+	assert.NoError(t, err)
+	assert.JSONEq(t, fmt.Sprintf(`{
+		"success": true,
+		"ec-version": "development",
+		"effective-time": %q,
+		"key": %s,
+		"components": [
+		  {
+			"name": "Unnamed",
+			"containerImage": "registry/image:tag",
+			"source": {},
+			"success": true
+		  }
+		],
+		"policy": {
+			"publicKey": %s
+		}
+	  }`, effectiveTimeTest, utils.TestPublicKeyJSON, utils.TestPublicKeyJSON), withoutReportDigest(t, out.String()))
+}
+
+func Test_ValidateImageCommandImages(t *testing.T) {
+	validate := func(_ context.Context, component app.SnapshotComponent, _ *app.SnapshotSpec, _ policy.Policy, _ []evaluator.Evaluator, _ bool) (*output.Output, error) {
+		return &output.Output{
+			ImageSignatureCheck: output.VerificationStatus{
+				Passed: true,
+			},
+			ImageAccessibleCheck: output.VerificationStatus{
+				Passed: true,
+			},
+			AttestationSignatureCheck: output.VerificationStatus{
+				Passed: true,
+			},
+			AttestationSyntaxCheck: output.VerificationStatus{
+				Passed: true,
+			},
+			PolicyCheck: []evaluator.Outcome{
+				{
+					FileName:  "test.json",
+					Namespace: "test.main",
+					Successes: []evaluator.Result{
+						{
+							Message: "Pass",
+							Metadata: map[string]interface{}{
+								"code": "policy.nice",
+							},
+						},
+					},
+				},
+			},
+			ImageURL: component.ContainerImage,
+			ExitCode: 0,
+		}, nil
+	}
+
+	validateImageCmd := validateImageCmd(validate)
+	cmd := setUpCobra(validateImageCmd)
+
+	client := fake.FakeClient{}
+	commonMockClient(&client)
+	ctx := utils.WithFS(context.Background(), afero.NewMemMapFs())
+	ctx = oci.WithClient(ctx, &client)
+	cmd.SetContext(ctx)
+
+	effectiveTimeTest := time.Now().UTC().Format(time.RFC3339Nano)
+
+	images := `{
+		"components": [
+			{
+				"name": "bacon",
+				"containerImage": "registry.localhost/bacon:v2.0",
+				"source": {
+					"git": {
+						"url": "https://git.localhost/bacon.git",
+						"revision": "8abf15bef376e0e21f1f9e9c3d74483d5018f3d5"
+					}
+				}
+			},
+			{
+				"name": "spam",
+				"containerImage": "registry.localhost/spam:v1.0",
+				"source": {
+					"git": {
+						"url": "https://git.localhost/spam.git",
+						"revision": "ded982e702e07bb7b6effafdc353db3fe172c83f"
+					}
+				}
+			}
+		]
+	}`
+
+	cmd.SetArgs(append(rootArgs, []string{
+		"--images",
+		images,
+		"--policy",
+		fmt.Sprintf(`{"publicKey": %s}`, utils.TestPublicKeyJSON),
+		"--effective-time",
+		effectiveTimeTest,
+	}...))
+
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+
+	utils.SetTestRekorPublicKey(t)
+
+	err := cmd.Execute()
+	assert.NoError(t, err)
+	assert.JSONEq(t, fmt.Sprintf(`{
+		"success": true,
+		"ec-version": "development",
+		"effective-time": %q,
+		"key": %s,
+		"components": [
+			{
+				"name": "spam",
+				"containerImage": "registry.localhost/spam:v1.0",
+				"source": {
+					"git": {
+						"url": "https://git.localhost/spam.git",
+						"revision": "ded982e702e07bb7b6effafdc353db3fe172c83f"
+					}
+				},
+				"success": true
+			},
+			{
+				"name": "bacon",
+				"containerImage": "registry.localhost/bacon:v2.0",
+				"source": {
+					"git": {
+						"url": "https://git.localhost/bacon.git",
+						"revision": "8abf15bef376e0e21f1f9e9c3d74483d5018f3d5"
+					}
+				},
+				"success": true
+			}
+		],
+		"policy": {
+			"publicKey": %s
+		}
+	  }`, effectiveTimeTest, utils.TestPublicKeyJSON, utils.TestPublicKeyJSON), withoutReportDigest(t, out.String()))
+}
+
+func Test_ValidateImageCommandReviews(t *testing.T) {
+	validate := func(_ context.Context, component app.SnapshotComponent, _ *app.SnapshotSpec, _ policy.Policy, _ []evaluator.Evaluator, _ bool) (*output.Output, error) {
+		return &output.Output{
+			ImageSignatureCheck: output.VerificationStatus{
+				Passed: true,
+			},
+			ImageAccessibleCheck: output.VerificationStatus{
+				Passed: true,
+			},
+			AttestationSignatureCheck: output.VerificationStatus{
+				Passed: true,
+			},
+			AttestationSyntaxCheck: output.VerificationStatus{
+				Passed: true,
+			},
+			PolicyCheck: []evaluator.Outcome{
+				{
+					Reviews: []evaluator.Result{
+						{
+							Message:  "No approval record found",
+							Metadata: map[string]any{"code": "review.approval"},
+						},
+					},
+				},
+			},
+			ImageURL: component.ContainerImage,
+		}, nil
+	}
+
+	cases := []struct {
+		name    string
+		args    []string
+		success bool
+		err     string
+	}{
+		{
+			name:    "review required",
+			success: true,
+		},
+		{
+			name:    "fail on review",
+			args:    []string{"--fail-on-review"},
+			success: false,
+			err:     "success criteria not met",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cmd := setUpCobra(validateImageCmd(validate))
+
+			client := fake.FakeClient{}
+			commonMockClient(&client)
+			ctx := utils.WithFS(context.Background(), afero.NewMemMapFs())
+			ctx = oci.WithClient(ctx, &client)
+			cmd.SetContext(ctx)
+
+			cmd.SetArgs(append(append(rootArgs, []string{
+				"--image",
+				"registry/image:tag",
+				"--policy",
+				fmt.Sprintf(`{"publicKey": %s}`, utils.TestPublicKeyJSON),
+			}...), c.args...))
+
+			var out bytes.Buffer
+			cmd.SetOut(&out)
+
+			utils.SetTestRekorPublicKey(t)
+
+			err := cmd.Execute()
+			if c.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, c.err)
+			}
+
+			report := struct {
+				Success        bool `json:"success"`
+				ReviewRequired bool `json:"review-required"`
+				Components     []struct {
+					Success bool               `json:"success"`
+					Reviews []evaluator.Result `json:"reviews"`
+				} `json:"components"`
+			}{}
+			require.NoError(t, json.Unmarshal(out.Bytes(), &report))
+
+			assert.Equal(t, c.success, report.Success)
+			assert.True(t, report.ReviewRequired)
+			require.Len(t, report.Components, 1)
+			assert.Equal(t, c.success, report.Components[0].Success)
+			assert.Equal(t, []evaluator.Result{
+				{Message: "No approval record found", Metadata: map[string]any{"code": "review.approval"}},
+			}, report.Components[0].Reviews)
+		})
+	}
+}
+
+func Test_ValidateImageCommandFailOn(t *testing.T) {
+	validate := func(_ context.Context, component app.SnapshotComponent, _ *app.SnapshotSpec, _ policy.Policy, _ []evaluator.Evaluator, _ bool) (*output.Output, error) {
+		return &output.Output{
+			ImageSignatureCheck: output.VerificationStatus{
+				Passed: true,
+			},
+			ImageAccessibleCheck: output.VerificationStatus{
+				Passed: true,
+			},
+			AttestationSignatureCheck: output.VerificationStatus{
+				Passed: true,
+			},
+			AttestationSyntaxCheck: output.VerificationStatus{
+				Passed: true,
+			},
+			PolicyCheck: []evaluator.Outcome{
+				{
+					Failures: []evaluator.Result{
+						{
+							Message:  "Outdated base image",
+							Metadata: map[string]any{"code": "base_image.current", "severity": "medium"},
+						},
+					},
+				},
+			},
+			ImageURL: component.ContainerImage,
+		}, nil
+	}
+
+	cases := []struct {
+		name    string
+		args    []string
+		success bool
+		err     string
+	}{
+		{
+			name:    "all violations",
+			success: false,
+			err:     "success criteria not met",
+		},
+		{
+			name:    "at least as severe",
+			args:    []string{"--fail-on", "medium"},
+			success: false,
+			err:     "success criteria not met",
+		},
+		{
+			name:    "less severe",
+			args:    []string{"--fail-on", "High"},
+			success: true,
+		},
+		{
+			name: "invalid severity",
+			args: []string{"--fail-on", "urgent"},
+			err:  "1 error occurred:\n\t* invalid severity \"urgent\", expecting one of: critical, high, medium, low\n\n",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cmd := setUpCobra(validateImageCmd(validate))
+
+			client := fake.FakeClient{}
+			commonMockClient(&client)
+			ctx := utils.WithFS(context.Background(), afero.NewMemMapFs())
+			ctx = oci.WithClient(ctx, &client)
+			cmd.SetContext(ctx)
+
+			cmd.SetArgs(append(append(rootArgs, []string{
+				"--image",
+				"registry/image:tag",
+				"--policy",
+				fmt.Sprintf(`{"publicKey": %s}`, utils.TestPublicKeyJSON),
+			}...), c.args...))
+
+			var out bytes.Buffer
+			cmd.SetOut(&out)
+
+			utils.SetTestRekorPublicKey(t)
+
+			err := cmd.Execute()
+			if c.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, c.err)
+			}
+
+			if out.Len() == 0 {
+				return
+			}
+
+			report := struct {
+				Success    bool `json:"success"`
+				Components []struct {
+					Success    bool               `json:"success"`
+					Violations []evaluator.Result `json:"violations"`
+				} `json:"components"`
+			}{}
+			require.NoError(t, json.Unmarshal(out.Bytes(), &report))
+
+			assert.Equal(t, c.success, report.Success)
+			require.Len(t, report.Components, 1)
+			assert.Equal(t, c.success, report.Components[0].Success)
+			assert.Len(t, report.Components[0].Violations, 1)
+		})
+	}
+}
+
+func Test_ValidateImageCommandGateOutput(t *testing.T) {
+	validate := func(violations ...evaluator.Result) imageValidationFunc {
+		return func(_ context.Context, component app.SnapshotComponent, _ *app.SnapshotSpec, _ policy.Policy, _ []evaluator.Evaluator, _ bool) (*output.Output, error) {
+			return &output.Output{
+				ImageSignatureCheck: output.VerificationStatus{
+					Passed: true,
+				},
+				ImageAccessibleCheck: output.VerificationStatus{
+					Passed: true,
+				},
+				AttestationSignatureCheck: output.VerificationStatus{
+					Passed: true,
+				},
+				AttestationSyntaxCheck: output.VerificationStatus{
+					Passed: true,
+				},
+				PolicyCheck: []evaluator.Outcome{
+					{Failures: violations},
+				},
+				ImageURL: component.ContainerImage,
+			}, nil
+		}
+	}
+
+	violation := evaluator.Result{Message: "Failure!", Metadata: map[string]any{"code": "policy.bad"}}
+
+	cases := []struct {
+		name     string
+		validate imageValidationFunc
+		args     []string
+		expected string
+	}{
+		{
+			name:     "pass",
+			validate: validate(),
+			expected: `{"status": "pass", "reason": "1 of 1 component(s) passed", "violationCount": 0}`,
+		},
+		{
+			name:     "fail",
+			validate: validate(violation, violation),
+			expected: `{"status": "fail", "reason": "1 of 1 component(s) failed with 2 violation(s)", "violationCount": 2}`,
+		},
+		{
+			name:     "not strict",
+			validate: validate(violation),
+			args:     []string{"--strict=false"},
+			expected: `{"status": "pass", "reason": "1 of 1 component(s) failed with 1 violation(s), ignored as the validation is not strict", "violationCount": 1}`,
+		},
+		{
+			name:     "error",
+			validate: validate(),
+			args:     []string{"--max-attestation-age", "tomorrow"},
+			expected: `{"status": "fail", "reason": "1 error occurred:\n\t* invalid attestation age \"tomorrow\": time: invalid duration \"tomorrow\"\n\n", "violationCount": 0}`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cmd := setUpCobra(validateImageCmd(c.validate))
+
+			client := fake.FakeClient{}
+			commonMockClient(&client)
+			fs := afero.NewMemMapFs()
+			ctx := utils.WithFS(context.Background(), fs)
+			ctx = oci.WithClient(ctx, &client)
+			cmd.SetContext(ctx)
+
+			cmd.SetArgs(append(append(rootArgs, []string{
+				"--image",
+				"registry/image:tag",
+				"--policy",
+				fmt.Sprintf(`{"publicKey": %s}`, utils.TestPublicKeyJSON),
+				"--gate-output",
+				"/status.json",
+			}...), c.args...))
+
+			var out bytes.Buffer
+			cmd.SetOut(&out)
+
+			utils.SetTestRekorPublicKey(t)
+
+			err := cmd.Execute()
+
+			status, readErr := afero.ReadFile(fs, "/status.json")
+			require.NoError(t, readErr)
+			assert.JSONEq(t, c.expected, string(status))
+
+			gate := applicationsnapshot.GateStatus{}
+			require.NoError(t, json.Unmarshal(status, &gate))
+			// the status must match the exit code
+			assert.Equal(t, err != nil, gate.Status == applicationsnapshot.GateFail)
+		})
+	}
+}
+
+func Test_ValidateImageCommandImagesStdin(t *testing.T) {
+	validate := func(_ context.Context, component app.SnapshotComponent, _ *app.SnapshotSpec, _ policy.Policy, _ []evaluator.Evaluator, _ bool) (*output.Output, error) {
+		return &output.Output{
+			ImageSignatureCheck: output.VerificationStatus{
+				Passed: true,
+			},
+			ImageAccessibleCheck: output.VerificationStatus{
+				Passed: true,
+			},
+			AttestationSignatureCheck: output.VerificationStatus{
+				Passed: true,
+			},
+			AttestationSyntaxCheck: output.VerificationStatus{
+				Passed: true,
+			},
+			ImageURL: component.ContainerImage,
+		}, nil
+	}
+
+	cmd := setUpCobra(validateImageCmd(validate))
+
+	client := fake.FakeClient{}
+	commonMockClient(&client)
+	ctx := utils.WithFS(context.Background(), afero.NewMemMapFs())
+	ctx = oci.WithClient(ctx, &client)
+	cmd.SetContext(ctx)
+
+	cmd.SetArgs(append(rootArgs, []string{
+		"--images",
+		"-",
+		"--policy",
+		fmt.Sprintf(`{"publicKey": %s}`, utils.TestPublicKeyJSON),
+	}...))
+
+	cmd.SetIn(strings.NewReader("registry.localhost/spam:v1.0\nregistry.localhost/bacon:v1.0\n"))
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+
+	utils.SetTestRekorPublicKey(t)
+
+	require.NoError(t, cmd.Execute())
+
+	var report applicationsnapshot.Report
+	require.NoError(t, json.Unmarshal(out.Bytes(), &report))
+	require.Len(t, report.Components, 2)
+	assert.Equal(t, "registry.localhost/spam:v1.0", report.Components[0].ContainerImage)
+	assert.Equal(t, "registry.localhost/bacon:v1.0", report.Components[1].ContainerImage)
+	assert.True(t, report.Success)
+}
+
+func Test_ValidateImageCommandStreamOutput(t *testing.T) {
+	validate := func(_ context.Context, component app.SnapshotComponent, _ *app.SnapshotSpec, _ policy.Policy, _ []evaluator.Evaluator, _ bool) (*output.Output, error) {
+		return &output.Output{
+			ImageSignatureCheck: output.VerificationStatus{
+				Passed: true,
+			},
+			ImageAccessibleCheck: output.VerificationStatus{
+				Passed: true,
+			},
+			AttestationSignatureCheck: output.VerificationStatus{
+				Passed: true,
+			},
+			AttestationSyntaxCheck: output.VerificationStatus{
+				Passed: true,
+			},
+			PolicyCheck: []evaluator.Outcome{
+				{Successes: []evaluator.Result{{Message: "Pass", Metadata: map[string]any{"code": "policy.good"}}}},
+			},
+			ImageURL: component.ContainerImage,
+		}, nil
+	}
+
+	cases := []struct {
+		name       string
+		args       []string
+		streamPath string
+		report     bool
+	}{
+		{
+			name:       "only streamed output",
+			args:       []string{"--stream-output", "/stream.ndjson"},
+			streamPath: "/stream.ndjson",
+		},
+		{
+			name:       "streamed output and report",
+			args:       []string{"--stream-output", "/stream.ndjson", "--output", "json"},
+			streamPath: "/stream.ndjson",
+			report:     true,
+		},
+		{
+			name: "streamed to stdout",
+			args: []string{"--stream-output", "-"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cmd := setUpCobra(validateImageCmd(validate))
+
+			client := fake.FakeClient{}
+			commonMockClient(&client)
+			fs := afero.NewMemMapFs()
+			ctx := utils.WithFS(context.Background(), fs)
+			ctx = oci.WithClient(ctx, &client)
+			cmd.SetContext(ctx)
+
+			cmd.SetArgs(append(append(rootArgs, []string{
+				"--images",
+				`{"components": [{"name": "spam", "containerImage": "registry.localhost/spam:v1.0"}, {"name": "bacon", "containerImage": "registry.localhost/bacon:v1.0"}]}`,
+				"--policy",
+				fmt.Sprintf(`{"publicKey": %s}`, utils.TestPublicKeyJSON),
+			}...), c.args...))
+
+			var out bytes.Buffer
+			cmd.SetOut(&out)
+
+			utils.SetTestRekorPublicKey(t)
+
+			require.NoError(t, cmd.Execute())
+
+			stream := out.Bytes()
+			if c.streamPath != "" {
+				var err error
+				stream, err = afero.ReadFile(fs, c.streamPath)
+				require.NoError(t, err)
+			}
+
+			lines := strings.Split(strings.TrimSuffix(string(stream), "\n"), "\n")
+			require.Len(t, lines, 3)
+			names := []string{}
+			for _, line := range lines[:2] {
+				record := map[string]any{}
+				require.NoError(t, json.Unmarshal([]byte(line), &record))
+				assert.Equal(t, "component", record["type"])
+				names = append(names, record["name"].(string))
+			}
+			assert.ElementsMatch(t, []string{"spam", "bacon"}, names)
+
+			report := map[string]any{}
+			require.NoError(t, json.Unmarshal([]byte(lines[2]), &report))
+			assert.Equal(t, "report", report["type"])
+			assert.Equal(t, true, report["success"])
+			assert.Equal(t, float64(2), report["components"])
+
+			if c.report {
+				assert.Contains(t, out.String(), `"components":[`)
+			} else if c.streamPath != "" {
+				assert.Empty(t, out.String())
+			}
+		})
+	}
+}
+
+func Test_ValidateImageCommandQuiet(t *testing.T) {
+	validate := func(_ context.Context, component app.SnapshotComponent, _ *app.SnapshotSpec, _ policy.Policy, _ []evaluator.Evaluator, _ bool) (*output.Output, error) {
+		outcome := evaluator.Outcome{
+			Successes: []evaluator.Result{{Message: "Pass", Metadata: map[string]any{"code": "policy.good"}}},
+		}
+		if component.Name == "bacon" {
+			outcome.Failures = []evaluator.Result{{Message: "Fail", Metadata: map[string]any{"code": "policy.bad"}}}
+		}
+
+		return &output.Output{
+			ImageSignatureCheck: output.VerificationStatus{
+				Passed: true,
+			},
+			ImageAccessibleCheck: output.VerificationStatus{
+				Passed: true,
+			},
+			AttestationSignatureCheck: output.VerificationStatus{
+				Passed: true,
+			},
+			AttestationSyntaxCheck: output.VerificationStatus{
+				Passed: true,
+			},
+			PolicyCheck: []evaluator.Outcome{outcome},
+			ImageURL:    component.ContainerImage,
+		}, nil
+	}
+
+	cases := []struct {
+		name       string
+		components string
+		args       []string
+		err        string
+		out        string
+	}{
+		{
+			name:       "success",
+			components: `{"components": [{"name": "spam", "containerImage": "registry.localhost/spam:v1.0"}]}`,
+		},
+		{
+			name:       "failure",
+			components: `{"components": [{"name": "bacon", "containerImage": "registry.localhost/bacon:v1.0"}]}`,
+			err:        "success criteria not met",
+		},
+		{
+			name:       "explicit output",
+			components: `{"components": [{"name": "spam", "containerImage": "registry.localhost/spam:v1.0"}, {"name": "bacon", "containerImage": "registry.localhost/bacon:v1.0"}]}`,
+			args:       []string{"--output", "summary-table"},
+			err:        "success criteria not met",
+			out: `COMPONENT  VIOLATIONS  WARNINGS  SUCCESSES  VERDICT
+spam       0           0         1          PASS
+bacon      1           0         1          FAIL
+`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cmd := setUpCobra(validateImageCmd(validate))
+			t.Cleanup(func() {
+				// the flag is bound to a package variable of the root command
+				require.NoError(t, cmd.PersistentFlags().Set("quiet", "false"))
+			})
+
+			client := fake.FakeClient{}
+			commonMockClient(&client)
+			ctx := utils.WithFS(context.Background(), afero.NewMemMapFs())
+			ctx = oci.WithClient(ctx, &client)
+			cmd.SetContext(ctx)
+
+			cmd.SetArgs(append(append(rootArgs, []string{
+				"--quiet",
+				"--images",
+				c.components,
+				"--policy",
+				fmt.Sprintf(`{"publicKey": %s}`, utils.TestPublicKeyJSON),
+			}...), c.args...))
+
+			var out, errOut bytes.Buffer
+			cmd.SetOut(&out)
+			cmd.SetErr(&errOut)
+
+			utils.SetTestRekorPublicKey(t)
+
+			err := cmd.Execute()
+			if c.err != "" {
+				assert.EqualError(t, err, c.err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, c.out, out.String())
+			assert.Empty(t, errOut.String())
+		})
+	}
+}
+
+func Test_ValidateImageCommandWorkers(t *testing.T) {
+	var mu sync.Mutex
+	running, maxRunning := 0, 0
+	// each validation reports its start and then waits to be released
+	started := make(chan struct{})
+	release := make(chan struct{})
+	validate := func(_ context.Context, component app.SnapshotComponent, _ *app.SnapshotSpec, _ policy.Policy, _ []evaluator.Evaluator, _ bool) (*output.Output, error) {
+		mu.Lock()
+		running++
+		maxRunning = max(maxRunning, running)
+		mu.Unlock()
+
+		started <- struct{}{}
+		<-release
+
+		mu.Lock()
+		running--
+		mu.Unlock()
+
+		return &output.Output{
+			ImageSignatureCheck:       output.VerificationStatus{Passed: true},
+			ImageAccessibleCheck:      output.VerificationStatus{Passed: true},
+			AttestationSignatureCheck: output.VerificationStatus{Passed: true},
+			AttestationSyntaxCheck:    output.VerificationStatus{Passed: true},
+			ImageURL:                  component.ContainerImage,
+		}, nil
+	}
+
+	images := []string{}
+	for i := 0; i < 6; i++ {
+		images = append(images, fmt.Sprintf("registry.localhost/image-%d:v1.0", i))
+	}
+	imagesJSON, err := json.Marshal(images)
+	require.NoError(t, err)
+
+	cases := []struct {
+		workers int
+		err     string
+	}{
+		{workers: 1},
+		{workers: 2},
+		{workers: 0, err: "1 error occurred:\n\t* invalid number of workers 0, at least one worker is needed\n\n"},
+	}
+
+	for _, c := range cases {
+		t.Run(strconv.Itoa(c.workers), func(t *testing.T) {
+			running, maxRunning = 0, 0
+			cmd := setUpCobra(validateImageCmd(validate))
+
+			client := fake.FakeClient{}
+			commonMockClient(&client)
+			ctx := utils.WithFS(context.Background(), afero.NewMemMapFs())
+			ctx = oci.WithClient(ctx, &client)
+			cmd.SetContext(ctx)
+
+			cmd.SetArgs(append(rootArgs, []string{
+				"--images",
+				string(imagesJSON),
+				"--policy",
+				fmt.Sprintf(`{"publicKey": %s}`, utils.TestPublicKeyJSON),
+				"--workers",
+				strconv.Itoa(c.workers),
+			}...))
+
+			var out bytes.Buffer
+			cmd.SetOut(&out)
 
-	client := fake.FakeClient{}
-	commonMockClient(&client)
-	ctx := utils.WithFS(context.Background(), afero.NewMemMapFs())
-	ctx = oci.WithClient(ctx, &client)
-	cmd.SetContext(ctx)
+			utils.SetTestRekorPublicKey(t)
 
-	effectiveTimeTest := time.Now().UTC().Format(time.RFC3339Nano)
+			if c.err != "" {
+				assert.EqualError(t, cmd.Execute(), c.err)
+				return
+			}
 
-	cmd.SetArgs(append(rootArgs, []string{
-		"--image",
-		"registry/image:tag",
-		"--policy",
-		fmt.Sprintf(`{"publicKey": %s}`, utils.TestPublicKeyJSON),
-		"--effective-time",
-		effectiveTimeTest,
-	}...))
+			done := make(chan error)
+			go func() {
+				done <- cmd.Execute()
+			}()
 
-	var out bytes.Buffer
-	cmd.SetOut(&out)
+			// as many validations as there are workers run at the same time,
+			// and the next one starts only once one of them completes
+			for i := 0; i < c.workers; i++ {
+				<-started
+			}
+			for i := c.workers; i < len(images); i++ {
+				release <- struct{}{}
+				<-started
+			}
+			for i := 0; i < c.workers; i++ {
+				release <- struct{}{}
+			}
 
-	utils.SetTestRekorPublicKey(t)
+			require.NoError(t, <-done)
+			assert.Equal(t, c.workers, maxRunning)
 
-	err := cmd.Execute()
-	assert.NoError(t, err)
-	assert.JSONEq(t, fmt.Sprintf(`{
-		"success": true,
-		"ec-version": "development",
-		"effective-time": %q,
-		"key": %s,
-		"components": [
-		  {
-			"name": "Unnamed",
-			"containerImage": "registry/image:tag",
-			"source": {},
-			"success": true
-		  }
-		],
-		"policy": {
-			"publicKey": %s
-		}
-	  }`, effectiveTimeTest, utils.TestPublicKeyJSON, utils.TestPublicKeyJSON), out.String())
+			var report applicationsnapshot.Report
+			require.NoError(t, json.Unmarshal(out.Bytes(), &report))
+			assert.Len(t, report.Components, len(images))
+		})
+	}
 }
 
-func Test_ValidateImageCommandImages(t *testing.T) {
+func Test_ValidateImageCommandPanicRecovery(t *testing.T) {
 	validate := func(_ context.Context, component app.SnapshotComponent, _ *app.SnapshotSpec, _ policy.Policy, _ []evaluator.Evaluator, _ bool) (*output.Output, error) {
+		if component.Name == "bacon" {
+			var m map[string]string
+			m["boom"] = "boom" // assignment to entry in nil map
+		}
+
 		return &output.Output{
 			ImageSignatureCheck: output.VerificationStatus{
 				Passed: true,
@@ -350,22 +1144,7 @@ func Test_ValidateImageCommandImages(t *testing.T) {
 			AttestationSyntaxCheck: output.VerificationStatus{
 				Passed: true,
 			},
-			PolicyCheck: []evaluator.Outcome{
-				{
-					FileName:  "test.json",
-					Namespace: "test.main",
-					Successes: []evaluator.Result{
-						{
-							Message: "Pass",
-							Metadata: map[string]interface{}{
-								"code": "policy.nice",
-							},
-						},
-					},
-				},
-			},
 			ImageURL: component.ContainerImage,
-			ExitCode: 0,
 		}, nil
 	}
 
@@ -384,23 +1163,11 @@ func Test_ValidateImageCommandImages(t *testing.T) {
 		"components": [
 			{
 				"name": "bacon",
-				"containerImage": "registry.localhost/bacon:v2.0",
-				"source": {
-					"git": {
-						"url": "https://git.localhost/bacon.git",
-						"revision": "8abf15bef376e0e21f1f9e9c3d74483d5018f3d5"
-					}
-				}
+				"containerImage": "registry.localhost/bacon:v2.0"
 			},
 			{
 				"name": "spam",
-				"containerImage": "registry.localhost/spam:v1.0",
-				"source": {
-					"git": {
-						"url": "https://git.localhost/spam.git",
-						"revision": "ded982e702e07bb7b6effafdc353db3fe172c83f"
-					}
-				}
+				"containerImage": "registry.localhost/spam:v1.0"
 			}
 		]
 	}`
@@ -412,6 +1179,7 @@ func Test_ValidateImageCommandImages(t *testing.T) {
 		fmt.Sprintf(`{"publicKey": %s}`, utils.TestPublicKeyJSON),
 		"--effective-time",
 		effectiveTimeTest,
+		"--strict=false",
 	}...))
 
 	var out bytes.Buffer
@@ -422,7 +1190,7 @@ func Test_ValidateImageCommandImages(t *testing.T) {
 	err := cmd.Execute()
 	assert.NoError(t, err)
 	assert.JSONEq(t, fmt.Sprintf(`{
-		"success": true,
+		"success": false,
 		"ec-version": "development",
 		"effective-time": %q,
 		"key": %s,
@@ -430,30 +1198,28 @@ func Test_ValidateImageCommandImages(t *testing.T) {
 			{
 				"name": "spam",
 				"containerImage": "registry.localhost/spam:v1.0",
-				"source": {
-					"git": {
-						"url": "https://git.localhost/spam.git",
-						"revision": "ded982e702e07bb7b6effafdc353db3fe172c83f"
-					}
-				},
+				"source": {},
 				"success": true
 			},
 			{
 				"name": "bacon",
 				"containerImage": "registry.localhost/bacon:v2.0",
-				"source": {
-					"git": {
-						"url": "https://git.localhost/bacon.git",
-						"revision": "8abf15bef376e0e21f1f9e9c3d74483d5018f3d5"
+				"source": {},
+				"success": false,
+				"violations": [
+					{
+						"msg": "Unexpected error validating the image: assignment to entry in nil map",
+						"metadata": {
+							"code": "builtin.image.validation_panic"
+						}
 					}
-				},
-				"success": true
+				]
 			}
 		],
 		"policy": {
 			"publicKey": %s
 		}
-	  }`, effectiveTimeTest, utils.TestPublicKeyJSON, utils.TestPublicKeyJSON), out.String())
+	  }`, effectiveTimeTest, utils.TestPublicKeyJSON, utils.TestPublicKeyJSON), withoutReportDigest(t, out.String()))
 }
 
 func Test_ValidateImageCommandKeyless(t *testing.T) {
@@ -502,6 +1268,169 @@ func Test_ValidateImageCommandKeyless(t *testing.T) {
 	assert.True(t, called)
 }
 
+func Test_ValidateImageCommandKeylessConstraints(t *testing.T) {
+	called := false
+	validateImageCmd := validateImageCmd(func(_ context.Context, _ app.SnapshotComponent, _ *app.SnapshotSpec, p policy.Policy, _ []evaluator.Evaluator, _ bool) (*output.Output, error) {
+		assert.Equal(t, policy.KeylessConstraints{
+			GithubWorkflowRepository: "org/repo",
+			GithubWorkflowRef:        "refs/heads/main",
+			IgnoreSCT:                true,
+		}, p.KeylessConstraints())
+
+		opts, err := p.CheckOpts()
+		require.NoError(t, err)
+		assert.Equal(t, "org/repo", opts.CertGithubWorkflowRepository)
+		assert.Equal(t, "refs/heads/main", opts.CertGithubWorkflowRef)
+		assert.True(t, opts.IgnoreSCT)
+
+		called = true
+
+		return &output.Output{}, nil
+	})
+	cmd := setUpCobra(validateImageCmd)
+
+	client := fake.FakeClient{}
+	commonMockClient(&client)
+	ctx := utils.WithFS(context.Background(), afero.NewMemMapFs())
+	ctx = oci.WithClient(ctx, &client)
+	cmd.SetContext(ctx)
+
+	cmd.SetArgs(append(rootArgs, []string{
+		"--image",
+		"registry/image:tag",
+		"--policy",
+		`{"keyless": {"githubWorkflowRepository": "other/repo", "githubWorkflowRef": "refs/heads/main"}}`,
+		"--certificate-identity",
+		"my-certificate-identity",
+		"--certificate-oidc-issuer",
+		"my-certificate-oidc-issuer",
+		"--certificate-github-workflow-repository",
+		"org/repo",
+		"--ignore-sct",
+	}...))
+
+	utils.SetTestRekorPublicKey(t)
+	utils.SetTestFulcioRoots(t)
+	utils.SetTestCTLogPublicKey(t)
+
+	err := cmd.Execute()
+	assert.NoError(t, err)
+	assert.True(t, called)
+}
+
+func Test_ValidateImageCommandRekorInclusionProof(t *testing.T) {
+	called := false
+	validateImageCmd := validateImageCmd(func(_ context.Context, _ app.SnapshotComponent, _ *app.SnapshotSpec, p policy.Policy, _ []evaluator.Evaluator, _ bool) (*output.Output, error) {
+		assert.True(t, p.RekorInclusionProof())
+
+		opts, err := p.CheckOpts()
+		require.NoError(t, err)
+		assert.NotNil(t, opts.RekorClient)
+		assert.False(t, opts.Offline)
+
+		called = true
+
+		return &output.Output{}, nil
+	})
+	cmd := setUpCobra(validateImageCmd)
+
+	client := fake.FakeClient{}
+	commonMockClient(&client)
+	ctx := utils.WithFS(context.Background(), afero.NewMemMapFs())
+	ctx = oci.WithClient(ctx, &client)
+	cmd.SetContext(ctx)
+
+	cmd.SetArgs(append(rootArgs, []string{
+		"--image",
+		"registry/image:tag",
+		"--policy",
+		"",
+		"--public-key",
+		utils.TestPublicKey,
+		"--rekor-url",
+		utils.TestRekorURL,
+		"--rekor-inclusion-proof",
+	}...))
+
+	utils.SetTestRekorPublicKey(t)
+
+	err := cmd.Execute()
+	assert.NoError(t, err)
+	assert.True(t, called)
+}
+
+func Test_ValidateImageCommandRekorOfflineInclusionProof(t *testing.T) {
+	validateImageCmd := validateImageCmd(nil)
+	cmd := setUpCobra(validateImageCmd)
+
+	client := fake.FakeClient{}
+	commonMockClient(&client)
+	ctx := utils.WithFS(context.Background(), afero.NewMemMapFs())
+	ctx = oci.WithClient(ctx, &client)
+	cmd.SetContext(ctx)
+
+	cmd.SetArgs(append(rootArgs, []string{
+		"--image",
+		"registry/image:tag",
+		"--policy",
+		"",
+		"--public-key",
+		utils.TestPublicKey,
+		"--rekor-url",
+		utils.TestRekorURL,
+		"--rekor-offline",
+		"--rekor-inclusion-proof",
+	}...))
+
+	utils.SetTestRekorPublicKey(t)
+
+	err := cmd.Execute()
+	assert.ErrorContains(t, err, "the inclusion proofs of the Rekor entries can't be verified in offline mode")
+}
+
+func Test_ValidateImageCommandMultiplePolicies(t *testing.T) {
+	called := false
+	validateImageCmd := validateImageCmd(func(_ context.Context, _ app.SnapshotComponent, _ *app.SnapshotSpec, p policy.Policy, _ []evaluator.Evaluator, _ bool) (*output.Output, error) {
+		assert.Equal(t, []ecc.Source{
+			{
+				Name:   "release",
+				Policy: []string{"oci::quay.io/org/policy:v2"},
+				Config: &ecc.SourceConfig{Exclude: []string{"cve", "test"}},
+			},
+			{
+				Name:   "team",
+				Policy: []string{"github.com/team/policy"},
+			},
+		}, p.Spec().Sources)
+
+		called = true
+
+		return &output.Output{}, nil
+	})
+	cmd := setUpCobra(validateImageCmd)
+
+	client := fake.FakeClient{}
+	commonMockClient(&client)
+	ctx := utils.WithFS(context.Background(), afero.NewMemMapFs())
+	ctx = oci.WithClient(ctx, &client)
+	cmd.SetContext(ctx)
+
+	cmd.SetArgs(append(rootArgs, []string{
+		"--image",
+		"registry/image:tag",
+		"--policy",
+		fmt.Sprintf(`{"publicKey": %s, "sources": [{"name": "release", "policy": ["oci::quay.io/org/policy:v1"], "config": {"exclude": ["cve"]}}]}`, utils.TestPublicKeyJSON),
+		"--policy",
+		`{"sources": [{"name": "release", "policy": ["oci::quay.io/org/policy:v2"], "config": {"exclude": ["test"]}}, {"name": "team", "policy": ["github.com/team/policy"]}]}`,
+	}...))
+
+	utils.SetTestRekorPublicKey(t)
+
+	err := cmd.Execute()
+	assert.NoError(t, err)
+	assert.True(t, called)
+}
+
 func Test_ValidateImageCommandYAMLPolicyFile(t *testing.T) {
 	validate := func(_ context.Context, component app.SnapshotComponent, _ *app.SnapshotSpec, _ policy.Policy, _ []evaluator.Evaluator, _ bool) (*output.Output, error) {
 		return &output.Output{
@@ -536,16 +1465,6 @@ func Test_ValidateImageCommandYAMLPolicyFile(t *testing.T) {
 		}, nil
 	}
 
-	validateImageCmd := validateImageCmd(validate)
-	cmd := setUpCobra(validateImageCmd)
-
-	client := fake.FakeClient{}
-	commonMockClient(&client)
-	fs := afero.NewMemMapFs()
-	ctx := utils.WithFS(context.Background(), fs)
-	ctx = oci.WithClient(ctx, &client)
-	cmd.SetContext(ctx)
-
 	cases := []struct {
 		name   string
 		config string
@@ -591,6 +1510,16 @@ spec:
 
 	for _, c := range cases {
 		t.Run(c.name, func(t *testing.T) {
+			validateImageCmd := validateImageCmd(validate)
+			cmd := setUpCobra(validateImageCmd)
+
+			client := fake.FakeClient{}
+			commonMockClient(&client)
+			fs := afero.NewMemMapFs()
+			ctx := utils.WithFS(context.Background(), fs)
+			ctx = oci.WithClient(ctx, &client)
+			cmd.SetContext(ctx)
+
 			err := afero.WriteFile(fs, "/policy.yaml", []byte(c.config), 0644)
 			if err != nil {
 				panic(err)
@@ -1052,6 +1981,31 @@ func Test_ValidateErrorCommand(t *testing.T) {
 	* unable to parse Snapshot specification from {"invalid": "json""}: error converting YAML to JSON: yaml: found unexpected end of stream
 	* unable to parse EnterpriseContractPolicySpec: error converting YAML to JSON: yaml: found unexpected end of stream
 
+`,
+		},
+		{
+			name: "unsupported rego version",
+			args: []string{
+				"--image",
+				"registry/image:tag",
+				"--policy",
+				fmt.Sprintf(`{"publicKey": %s}`, utils.TestPublicKeyJSON),
+				"--experimental-rego-v1=v2",
+			},
+			expected: `unsupported rego version "v2", expecting one of: v0, v1, auto`,
+		},
+		{
+			name: "invalid color mode",
+			args: []string{
+				"--image",
+				"registry/image:tag",
+				"--policy",
+				fmt.Sprintf(`{"publicKey": %s}`, utils.TestPublicKeyJSON),
+				"--color=sometimes",
+			},
+			expected: `1 error occurred:
+	* invalid color mode "sometimes", expected one of: always, never, auto
+
 `,
 		},
 	}
@@ -1154,7 +2108,7 @@ func Test_FailureImageAccessibility(t *testing.T) {
 		"policy": {
 			"publicKey": %s
 		}
-	  }`, effectiveTimeTest, utils.TestPublicKeyJSON, utils.TestPublicKeyJSON), out.String())
+	  }`, effectiveTimeTest, utils.TestPublicKeyJSON, utils.TestPublicKeyJSON), withoutReportDigest(t, out.String()))
 }
 
 func Test_FailureOutput(t *testing.T) {
@@ -1223,7 +2177,7 @@ func Test_FailureOutput(t *testing.T) {
 		"policy": {
 			"publicKey": %s
 		}
-	  }`, effectiveTimeTest, utils.TestPublicKeyJSON, utils.TestPublicKeyJSON), out.String())
+	  }`, effectiveTimeTest, utils.TestPublicKeyJSON, utils.TestPublicKeyJSON), withoutReportDigest(t, out.String()))
 }
 
 func Test_WarningOutput(t *testing.T) {
@@ -1297,7 +2251,7 @@ func Test_WarningOutput(t *testing.T) {
 		"policy": {
 			"publicKey": %s
 		}
-	  }`, effectiveTimeTest, utils.TestPublicKeyJSON, utils.TestPublicKeyJSON), out.String())
+	  }`, effectiveTimeTest, utils.TestPublicKeyJSON, utils.TestPublicKeyJSON), withoutReportDigest(t, out.String()))
 }
 
 func Test_FailureImageAccessibilityNonStrict(t *testing.T) {
@@ -1365,7 +2319,7 @@ func Test_FailureImageAccessibilityNonStrict(t *testing.T) {
 		"policy": {
 			"publicKey": %s
 		}
-	  }`, effectiveTimeTest, utils.TestPublicKeyJSON, utils.TestPublicKeyJSON), out.String())
+	  }`, effectiveTimeTest, utils.TestPublicKeyJSON, utils.TestPublicKeyJSON), withoutReportDigest(t, out.String()))
 }
 
 func TestValidateImageCommand_RunE(t *testing.T) {
@@ -1445,5 +2399,13 @@ func TestValidateImageCommand_RunE(t *testing.T) {
 		"policy": {
 			"publicKey": %s
 		}
-	  }`, effectiveTimeTest, utils.TestPublicKeyJSON, utils.TestPublicKeyJSON), out.String())
+	  }`, effectiveTimeTest, utils.TestPublicKeyJSON, utils.TestPublicKeyJSON), withoutReportDigest(t, out.String()))
+}
+
+func TestVSAPolicyURI(t *testing.T) {
+	assert.Equal(t, "github.com/org/policy", vsaPolicyURI([]string{"github.com/org/policy"}))
+	assert.Equal(t, "", vsaPolicyURI([]string{`{"sources": []}`}))
+	assert.Equal(t, "", vsaPolicyURI([]string{"sources:\n- policy: []"}))
+	assert.Equal(t, "", vsaPolicyURI([]string{"one", "two"}))
+	assert.Equal(t, "", vsaPolicyURI(nil))
 }
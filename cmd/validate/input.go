@@ -20,6 +20,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"sort"
 	"strings"
 	"sync"
@@ -29,11 +30,14 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/enterprise-contract/ec-cli/internal/applicationsnapshot"
+	"github.com/enterprise-contract/ec-cli/internal/downloader"
 	"github.com/enterprise-contract/ec-cli/internal/evaluator"
 	"github.com/enterprise-contract/ec-cli/internal/format"
 	"github.com/enterprise-contract/ec-cli/internal/input"
 	"github.com/enterprise-contract/ec-cli/internal/output"
+	"github.com/enterprise-contract/ec-cli/internal/pipeline_run"
 	"github.com/enterprise-contract/ec-cli/internal/policy"
+	"github.com/enterprise-contract/ec-cli/internal/policy/source"
 	"github.com/enterprise-contract/ec-cli/internal/utils"
 	validate_utils "github.com/enterprise-contract/ec-cli/internal/validate"
 )
@@ -43,12 +47,17 @@ type InputValidationFunc func(context.Context, string, policy.Policy, bool) (*ou
 func validateInputCmd(validate InputValidationFunc) *cobra.Command {
 	data := struct {
 		effectiveTime       string
+		failOn              string
+		failOnReview        bool
 		filePaths           []string
 		info                bool
 		namespaces          []string
 		output              []string
+		pipelineRuns        []string
 		policy              policy.Policy
 		policyConfiguration string
+		policies            []string
+		saveSources         string
 		strict              bool
 	}{
 		strict: true,
@@ -60,7 +69,13 @@ func validateInputCmd(validate InputValidationFunc) *cobra.Command {
 			Validate conformance of arbitrary JSON or yaml file input with the Enterprise Contract
 
 			For each file, validation is performed to determine if the file conforms to rego policies
-			defined in the the EnterpriseContractPolicy.
+			defined in the the EnterpriseContractPolicy. The content of the file is available to the
+			rules as the input document.
+
+			The policy and data sources are resolved the same way as when validating images, each
+			source group of the EnterpriseContractPolicy can refer to git repositories, OCI bundles,
+			HTTP and local sources, and the rules are selected by the include and exclude lists of its
+			configuration, taking their effective_on dates into account.
 			`),
 		Example: hd.Doc(`
 			Use an EnterpriseContractPolicy spec from a local YAML file to validate a single file
@@ -74,6 +89,13 @@ func validateInputCmd(validate InputValidationFunc) *cobra.Command {
 			The file flag can take a comma separated series of files.
 			ec validate input --file="/path/to/file.json,/path/to/file2.json" --policy my-policy.yaml
 
+			Validate a document given on the standard input
+			kubectl get deployment my-deployment -o json | ec validate input --file - --policy my-policy.yaml
+
+			Validate a Tekton PipelineRun from a file, or from the cluster given its namespace and name
+			ec validate input --pipeline-run /path/to/pipeline-run.yaml --policy my-policy.yaml
+			ec validate input --pipeline-run my-namespace/my-pipeline-run --policy my-policy.yaml
+
 			Use a git url for the policy configuration. In the first example there should be a '.ec/policy.yaml'
 			or a 'policy.yaml' inside a directory called 'default' in the top level of the git repo. In the second
 			example there should be a '.ec/policy.yaml' or a 'policy.yaml' file in the top level
@@ -86,9 +108,30 @@ func validateInputCmd(validate InputValidationFunc) *cobra.Command {
 
 `),
 		PreRunE: func(cmd *cobra.Command, args []string) (allErrors error) {
+			if err := withSourceOptions(cmd); err != nil {
+				return err
+			}
 			ctx := cmd.Context()
 
-			policyConfiguration, err := validate_utils.GetPolicyConfig(ctx, data.policyConfiguration)
+			stdin := 0
+			for _, f := range data.filePaths {
+				if f == "-" {
+					stdin++
+				}
+			}
+			if stdin > 1 {
+				allErrors = multierror.Append(allErrors, errors.New("the standard input, -, can be given as --file only once"))
+			}
+
+			if data.failOn != "" {
+				if severity, err := evaluator.ParseSeverity(data.failOn); err != nil {
+					allErrors = multierror.Append(allErrors, err)
+				} else {
+					data.failOn = severity
+				}
+			}
+
+			policyConfiguration, err := validate_utils.GetPolicyConfigs(ctx, data.policies)
 			if err != nil {
 				allErrors = multierror.Append(allErrors, err)
 				return
@@ -110,23 +153,58 @@ func validateInputCmd(validate InputValidationFunc) *cobra.Command {
 				policyInput []byte
 			}
 
-			ch := make(chan result, len(data.filePaths))
+			ch := make(chan result, len(data.filePaths)+len(data.pipelineRuns))
 
 			var lock sync.WaitGroup
 
 			showSuccesses, _ := cmd.Flags().GetBool("show-successes")
 
+			ctx, err := withEvaluationOptions(cmd)
+			if err != nil {
+				return err
+			}
+
+			// name is reported in the output, path is the file that is validated
+			type target struct {
+				name string
+				path string
+			}
+
+			targets := make([]target, 0, len(data.filePaths)+len(data.pipelineRuns))
 			for _, f := range data.filePaths {
+				if f == "-" {
+					b, err := io.ReadAll(cmd.InOrStdin())
+					if err != nil {
+						return fmt.Errorf("unable to read the input from the standard input: %w", err)
+					}
+					path, err := utils.WriteTempFile(ctx, string(b), "input-file-")
+					if err != nil {
+						return err
+					}
+					targets = append(targets, target{name: f, path: path})
+					continue
+				}
+				targets = append(targets, target{name: f, path: f})
+			}
+
+			for _, ref := range data.pipelineRuns {
+				path, err := pipeline_run.WriteInputFile(ctx, ref)
+				if err != nil {
+					return fmt.Errorf("unable to load pipeline run %s: %w", ref, err)
+				}
+				targets = append(targets, target{name: ref, path: path})
+			}
+
+			for _, t := range targets {
 				lock.Add(1)
-				go func(fpath string) {
+				go func(t target) {
 					defer lock.Done()
 
-					ctx := cmd.Context()
-					out, err := validate(ctx, fpath, data.policy, data.info)
+					out, err := validate(ctx, t.path, data.policy, data.info)
 					res := result{
 						err: err,
 						input: input.Input{
-							FilePath: fpath,
+							FilePath: t.name,
 							Success:  err == nil,
 						},
 					}
@@ -134,6 +212,7 @@ func validateInputCmd(validate InputValidationFunc) *cobra.Command {
 					if err == nil {
 						res.input.Violations = out.Violations()
 						res.input.Warnings = out.Warnings()
+						res.input.Reviews = out.Reviews()
 
 						successes := out.Successes()
 						res.input.SuccessCount = len(successes)
@@ -142,14 +221,23 @@ func validateInputCmd(validate InputValidationFunc) *cobra.Command {
 						}
 						res.data = out.Data
 					}
-					res.input.Success = err == nil && len(res.input.Violations) == 0
+					res.input.Success = err == nil && len(evaluator.FailingResults(res.input.Violations, data.failOn)) == 0
+					if data.failOnReview && len(res.input.Reviews) > 0 {
+						res.input.Success = false
+					}
 					ch <- res
-				}(f)
+				}(t)
 			}
 
 			lock.Wait()
 			close(ch)
 
+			if data.saveSources != "" {
+				if err := source.SaveSources(ctx, data.saveSources); err != nil {
+					return err
+				}
+			}
+
 			var inputs []input.Input
 			var manyData [][]evaluator.Data
 			var manyPolicyInput [][]byte
@@ -178,6 +266,7 @@ func validateInputCmd(validate InputValidationFunc) *cobra.Command {
 			if err != nil {
 				return err
 			}
+			report.SourceMirrors = downloader.MirrorsUsed(cmd.Context())
 
 			p := format.NewTargetParser(input.JSON, format.Options{ShowSuccesses: showSuccesses}, cmd.OutOrStdout(), utils.FS(cmd.Context()))
 			if err := report.WriteAll(data.output, p); err != nil {
@@ -192,13 +281,24 @@ func validateInputCmd(validate InputValidationFunc) *cobra.Command {
 		},
 	}
 
-	cmd.Flags().StringSliceVarP(&data.filePaths, "file", "f", data.filePaths, "path to input YAML/JSON file (required)")
+	cmd.Flags().StringSliceVarP(&data.filePaths, "file", "f", data.filePaths, hd.Doc(`
+		path to input YAML/JSON file, or to a directory of such files, or the YAML/JSON document
+		itself. Use "-" to read the document from the standard input. May be used multiple times.`))
 
-	cmd.Flags().StringVarP(&data.policyConfiguration, "policy", "p", data.policyConfiguration, hd.Doc(`
+	cmd.Flags().StringSliceVar(&data.pipelineRuns, "pipeline-run", data.pipelineRuns, hd.Doc(`
+		Tekton PipelineRun to validate, either a path to a YAML/JSON file or a reference to a
+		PipelineRun in the cluster in the [<namespace>/]<name> format. May be used multiple times.`))
+
+	cmd.Flags().StringArrayVarP(&data.policies, "policy", "p", data.policies, hd.Doc(`
 		Policy configuration as:
-		* file (policy.yaml)
-		* git reference (github.com/user/repo//default?ref=main), or
-		* inline JSON ('{sources: {...}, configuration: {...}}')")`))
+		  * Kubernetes reference ([<namespace>/]<name> or <name>@<namespace>)
+		  * file (policy.yaml or file:policy.yaml)
+		  * Kubernetes ConfigMap key (configmap:<name>[@<namespace>][#<key>] or
+		    configmap://<namespace>/<name>[/<key>], the key defaults to policy.yaml)
+		  * OCI artifact (oci::quay.io/org/policy:tag)
+		  * git reference (github.com/user/repo//default?ref=main), or
+		  * inline JSON ('{sources: {...}, configuration: {...}}')")
+		Can be repeated to merge several policies, each overriding the ones before it.`))
 
 	validOutputFormats := applicationsnapshot.OutputFormats
 	cmd.Flags().StringSliceVarP(&data.output, "output", "o", data.output, hd.Doc(`
@@ -222,9 +322,22 @@ func validateInputCmd(validate InputValidationFunc) *cobra.Command {
 		violations, include the title and the description of the failed policy
 		rule.`))
 
-	if err := cmd.MarkFlagRequired("file"); err != nil {
-		panic(err)
-	}
+	cmd.Flags().StringVar(&data.saveSources, "save-sources", data.saveSources, hd.Doc(`
+		Path of a tar archive to write with all policy, data and configuration sources downloaded
+		during validation, including a manifest.json listing the source URLs and digests.`))
+
+	cmd.Flags().BoolVar(&data.failOnReview, "fail-on-review", data.failOnReview, hd.Doc(`
+		Consider files with results from review rules, i.e. warn_review rules, as not successful.
+		By default such results are reported as requiring review without affecting the success of
+		the validation.`))
+
+	cmd.Flags().StringVar(&data.failOn, "fail-on", data.failOn, hd.Doc(`
+		Consider only the violations at least as severe as the given severity, one of: critical,
+		high, medium, low, when determining the success of the validation. Less severe violations
+		are still reported. Violations of rules without a severity are always considered. By
+		default all violations are considered.`))
+
+	cmd.MarkFlagsOneRequired("file", "pipeline-run")
 
 	if err := cmd.MarkFlagRequired("policy"); err != nil {
 		panic(err)
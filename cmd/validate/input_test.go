@@ -17,3 +17,142 @@
 //go:build unit
 
 package validate
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/evaluator"
+	"github.com/enterprise-contract/ec-cli/internal/output"
+	"github.com/enterprise-contract/ec-cli/internal/policy"
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+func Test_ValidateInputCommandPipelineRun(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/pr.yaml", []byte(`{"metadata": {"name": "build-1"}}`), 0644))
+
+	var pipelineRunName string
+	validate := func(ctx context.Context, fpath string, _ policy.Policy, _ bool) (*output.Output, error) {
+		b, err := afero.ReadFile(utils.FS(ctx), fpath)
+		if err != nil {
+			return nil, err
+		}
+
+		in := struct {
+			PipelineRun struct {
+				Name string `json:"name"`
+			} `json:"pipeline_run"`
+		}{}
+		if err := json.Unmarshal(b, &in); err != nil {
+			return nil, err
+		}
+		pipelineRunName = in.PipelineRun.Name
+
+		return &output.Output{PolicyCheck: []evaluator.Outcome{}}, nil
+	}
+
+	cmd := setUpCobra(validateInputCmd(validate))
+	cmd.SetContext(utils.WithFS(context.Background(), fs))
+	cmd.SetArgs([]string{
+		"validate",
+		"input",
+		"--pipeline-run",
+		"/pr.yaml",
+		"--policy",
+		`{"sources": [{"policy": ["/policy"]}]}`,
+	})
+
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+
+	err := cmd.Execute()
+	require.NoError(t, err)
+
+	assert.Equal(t, "build-1", pipelineRunName)
+
+	report := struct {
+		FilePaths []struct {
+			FilePath string `json:"filepath"`
+			Success  bool   `json:"success"`
+		} `json:"filepaths"`
+	}{}
+	require.NoError(t, json.Unmarshal(out.Bytes(), &report))
+	require.Len(t, report.FilePaths, 1)
+	assert.Equal(t, "/pr.yaml", report.FilePaths[0].FilePath)
+	assert.True(t, report.FilePaths[0].Success)
+}
+
+func Test_ValidateInputCommandStdin(t *testing.T) {
+	var content string
+	validate := func(ctx context.Context, fpath string, _ policy.Policy, _ bool) (*output.Output, error) {
+		b, err := afero.ReadFile(utils.FS(ctx), fpath)
+		if err != nil {
+			return nil, err
+		}
+		content = string(b)
+
+		return &output.Output{PolicyCheck: []evaluator.Outcome{}}, nil
+	}
+
+	cmd := setUpCobra(validateInputCmd(validate))
+	cmd.SetContext(utils.WithFS(context.Background(), afero.NewMemMapFs()))
+	cmd.SetIn(strings.NewReader(`{"kind": "Deployment"}`))
+	cmd.SetArgs([]string{
+		"validate",
+		"input",
+		"--file",
+		"-",
+		"--policy",
+		`{"sources": [{"policy": ["/policy"]}]}`,
+	})
+
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+
+	err := cmd.Execute()
+	require.NoError(t, err)
+
+	assert.Equal(t, `{"kind": "Deployment"}`, content)
+
+	report := struct {
+		FilePaths []struct {
+			FilePath string `json:"filepath"`
+		} `json:"filepaths"`
+	}{}
+	require.NoError(t, json.Unmarshal(out.Bytes(), &report))
+	require.Len(t, report.FilePaths, 1)
+	assert.Equal(t, "-", report.FilePaths[0].FilePath)
+}
+
+func Test_ValidateInputCommandStdinOnce(t *testing.T) {
+	validate := func(context.Context, string, policy.Policy, bool) (*output.Output, error) {
+		return &output.Output{PolicyCheck: []evaluator.Outcome{}}, nil
+	}
+
+	cmd := setUpCobra(validateInputCmd(validate))
+	cmd.SetContext(utils.WithFS(context.Background(), afero.NewMemMapFs()))
+	cmd.SetArgs([]string{
+		"validate",
+		"input",
+		"--file",
+		"-",
+		"--file",
+		"-",
+		"--policy",
+		`{"sources": [{"policy": ["/policy"]}]}`,
+	})
+
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+
+	err := cmd.Execute()
+	assert.ErrorContains(t, err, "the standard input, -, can be given as --file only once")
+}
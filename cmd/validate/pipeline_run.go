@@ -0,0 +1,219 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package validate
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	hd "github.com/MakeNowJust/heredoc"
+	"github.com/hashicorp/go-multierror"
+	"github.com/spf13/cobra"
+
+	"github.com/enterprise-contract/ec-cli/internal/applicationsnapshot"
+	"github.com/enterprise-contract/ec-cli/internal/downloader"
+	"github.com/enterprise-contract/ec-cli/internal/evaluator"
+	"github.com/enterprise-contract/ec-cli/internal/format"
+	"github.com/enterprise-contract/ec-cli/internal/input"
+	"github.com/enterprise-contract/ec-cli/internal/pipeline_run"
+	"github.com/enterprise-contract/ec-cli/internal/policy"
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+	validate_utils "github.com/enterprise-contract/ec-cli/internal/validate"
+)
+
+func validatePipelineRunCmd(validate InputValidationFunc) *cobra.Command {
+	data := struct {
+		effectiveTime       string
+		info                bool
+		output              []string
+		pipelineRuns        []string
+		policy              policy.Policy
+		policyConfiguration string
+		policies            []string
+		strict              bool
+	}{
+		strict: true,
+	}
+	cmd := &cobra.Command{
+		Use:   "pipeline-run",
+		Short: "Validate conformance of completed Tekton PipelineRuns with the Enterprise Contract",
+		Long: hd.Doc(`
+			Validate conformance of completed Tekton PipelineRuns with the Enterprise Contract
+
+			For each PipelineRun, the PipelineRun and the TaskRuns it created are converted into
+			the policy input and validated to determine if they conform to rego policies defined
+			in the EnterpriseContractPolicy. Pipeline level rules can then check, for instance,
+			that the required tasks ran, that the tasks came from trusted task bundles and that
+			the tasks were given the expected parameters.
+
+			The policy input is available to the rules as input.pipeline_run, holding the name,
+			namespace, labels, annotations, status, parameters and results of the PipelineRun, and
+			its tasks, each with the reference to the task, e.g. the bundle it was resolved from,
+			and the status, parameters and results of its TaskRun.
+
+			The PipelineRun is read from a YAML/JSON file or, if no such file exists, fetched from
+			the cluster together with its TaskRuns. The file holds either the PipelineRun or a
+			List of the PipelineRun and its TaskRuns, as output by "kubectl get -o yaml".
+			`),
+		Example: hd.Doc(`
+			Validate a PipelineRun in the cluster, given its namespace and name
+
+			  ec validate pipeline-run --pipeline-run my-namespace/my-pipeline-run --policy my-policy.yaml
+
+			Validate a PipelineRun and its TaskRuns stored in a file
+
+			  kubectl get pipelinerun/build-1 taskrun -l tekton.dev/pipelineRun=build-1 -o yaml > build-1.yaml
+			  ec validate pipeline-run --pipeline-run build-1.yaml --policy my-policy.yaml
+			`),
+		PreRunE: func(cmd *cobra.Command, args []string) (allErrors error) {
+			if err := withSourceOptions(cmd); err != nil {
+				return err
+			}
+			ctx := cmd.Context()
+
+			policyConfiguration, err := validate_utils.GetPolicyConfigs(ctx, data.policies)
+			if err != nil {
+				allErrors = multierror.Append(allErrors, err)
+				return
+			}
+			data.policyConfiguration = policyConfiguration
+
+			if p, err := policy.NewInputPolicy(ctx, data.policyConfiguration, data.effectiveTime); err != nil {
+				allErrors = multierror.Append(allErrors, err)
+			} else {
+				data.policy = p
+			}
+
+			return
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			showSuccesses, _ := cmd.Flags().GetBool("show-successes")
+
+			ctx, err := withEvaluationOptions(cmd)
+			if err != nil {
+				return err
+			}
+
+			var inputs []input.Input
+			var manyData [][]evaluator.Data
+			var manyPolicyInput [][]byte
+			var allErrors error = nil
+
+			for _, ref := range data.pipelineRuns {
+				path, err := pipeline_run.WriteInputFile(ctx, ref)
+				if err != nil {
+					allErrors = multierror.Append(allErrors, fmt.Errorf("unable to load pipeline run %s: %w", ref, err))
+					continue
+				}
+
+				out, err := validate(ctx, path, data.policy, data.info)
+				if err != nil {
+					allErrors = multierror.Append(allErrors, fmt.Errorf("error validating pipeline run %s: %w", ref, err))
+					continue
+				}
+
+				in := input.Input{
+					FilePath:   ref,
+					Violations: out.Violations(),
+					Warnings:   out.Warnings(),
+					Reviews:    out.Reviews(),
+				}
+
+				successes := out.Successes()
+				in.SuccessCount = len(successes)
+				if showSuccesses {
+					in.Successes = successes
+				}
+				in.Success = len(in.Violations) == 0
+
+				inputs = append(inputs, in)
+				manyData = append(manyData, out.Data)
+				manyPolicyInput = append(manyPolicyInput, nil)
+			}
+			if allErrors != nil {
+				return allErrors
+			}
+
+			// Ensure some consistency in output.
+			sort.Slice(inputs, func(i, j int) bool {
+				return inputs[i].FilePath < inputs[j].FilePath
+			})
+
+			report, err := input.NewReport(inputs, data.policy, manyData, manyPolicyInput)
+			if err != nil {
+				return err
+			}
+			report.SourceMirrors = downloader.MirrorsUsed(cmd.Context())
+
+			p := format.NewTargetParser(input.JSON, format.Options{ShowSuccesses: showSuccesses}, cmd.OutOrStdout(), utils.FS(cmd.Context()))
+			if err := report.WriteAll(data.output, p); err != nil {
+				return err
+			}
+
+			if data.strict && !report.Success {
+				return errors.New("success criteria not met")
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&data.pipelineRuns, "pipeline-run", data.pipelineRuns, hd.Doc(`
+		Tekton PipelineRun to validate, either a path to a YAML/JSON file or a reference to a
+		PipelineRun in the cluster in the [<namespace>/]<name> format. May be used multiple times.`))
+
+	cmd.Flags().StringArrayVarP(&data.policies, "policy", "p", data.policies, hd.Doc(`
+		Policy configuration as:
+		* file (policy.yaml)
+		* git reference (github.com/user/repo//default?ref=main), or
+		* inline JSON ('{sources: {...}, configuration: {...}}')")
+		Can be repeated to merge several policies, each overriding the ones before it.`))
+
+	validOutputFormats := applicationsnapshot.OutputFormats
+	cmd.Flags().StringSliceVarP(&data.output, "output", "o", data.output, hd.Doc(`
+		Write output to a file in a specific format, e.g. yaml=/tmp/output.yaml. Use empty string
+		path for stdout, e.g. yaml. May be used multiple times. Possible formats are:
+		`+strings.Join(validOutputFormats, ", ")+`. In following format and file path
+		additional options can be provided in key=value form following the question
+		mark (?) sign, for example: --output text=output.txt?show-successes=false
+	`))
+
+	cmd.Flags().BoolVarP(&data.strict, "strict", "s", data.strict,
+		"Return non-zero status on non-successful validation")
+
+	cmd.Flags().StringVar(&data.effectiveTime, "effective-time", policy.Now, hd.Doc(`
+		Run policy checks with the provided time. Useful for testing rules with
+		effective dates in the future. The value can be "now" (default) - for
+		current time, or a RFC3339 formatted value, e.g. 2022-11-18T00:00:00Z.`))
+
+	cmd.Flags().BoolVar(&data.info, "info", data.info, hd.Doc(`
+		Include additional information on the failures. For instance for policy
+		violations, include the title and the description of the failed policy
+		rule.`))
+
+	if err := cmd.MarkFlagRequired("pipeline-run"); err != nil {
+		panic(err)
+	}
+
+	if err := cmd.MarkFlagRequired("policy"); err != nil {
+		panic(err)
+	}
+
+	return cmd
+}
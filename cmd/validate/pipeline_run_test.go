@@ -0,0 +1,164 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package validate
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/evaluator"
+	"github.com/enterprise-contract/ec-cli/internal/output"
+	"github.com/enterprise-contract/ec-cli/internal/policy"
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+func Test_ValidatePipelineRunCommand(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/trusted.yaml", []byte(`
+kind: List
+items:
+  - kind: PipelineRun
+    metadata: {name: trusted}
+    status:
+      childReferences: [{kind: TaskRun, name: trusted-build, pipelineTaskName: build}]
+  - kind: TaskRun
+    metadata: {name: trusted-build}
+    spec:
+      taskRef:
+        resolver: bundles
+        params: [{name: bundle, value: registry.io/trusted/buildah:0.1}, {name: name, value: buildah}]
+`), 0644))
+	require.NoError(t, afero.WriteFile(fs, "/untrusted.yaml", []byte(`
+kind: List
+items:
+  - kind: PipelineRun
+    metadata: {name: untrusted}
+    status:
+      childReferences: [{kind: TaskRun, name: untrusted-build, pipelineTaskName: build}]
+  - kind: TaskRun
+    metadata: {name: untrusted-build}
+    spec:
+      taskRef:
+        resolver: bundles
+        params: [{name: bundle, value: registry.io/untrusted/buildah:0.1}, {name: name, value: buildah}]
+`), 0644))
+
+	// fails tasks not from the trusted bundle
+	validate := func(ctx context.Context, fpath string, _ policy.Policy, _ bool) (*output.Output, error) {
+		b, err := afero.ReadFile(utils.FS(ctx), fpath)
+		if err != nil {
+			return nil, err
+		}
+
+		in := struct {
+			PipelineRun struct {
+				Tasks []struct {
+					Name string `json:"name"`
+					Ref  struct {
+						Bundle string `json:"bundle"`
+					} `json:"ref"`
+				} `json:"tasks"`
+			} `json:"pipeline_run"`
+		}{}
+		if err := json.Unmarshal(b, &in); err != nil {
+			return nil, err
+		}
+
+		outcome := evaluator.Outcome{}
+		for _, t := range in.PipelineRun.Tasks {
+			if t.Ref.Bundle != "registry.io/trusted/buildah:0.1" {
+				outcome.Failures = append(outcome.Failures, evaluator.Result{Message: fmt.Sprintf("task %s is from an untrusted bundle %s", t.Name, t.Ref.Bundle)})
+			}
+		}
+
+		return &output.Output{PolicyCheck: []evaluator.Outcome{outcome}}, nil
+	}
+
+	cmd := setUpCobra(validatePipelineRunCmd(validate))
+	cmd.SetContext(utils.WithFS(context.Background(), fs))
+	cmd.SetArgs([]string{
+		"validate",
+		"pipeline-run",
+		"--pipeline-run",
+		"/untrusted.yaml",
+		"--pipeline-run",
+		"/trusted.yaml",
+		"--policy",
+		`{"sources": [{"policy": ["/policy"]}]}`,
+	})
+
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+
+	err := cmd.Execute()
+	assert.EqualError(t, err, "success criteria not met")
+
+	report := struct {
+		FilePaths []struct {
+			FilePath   string `json:"filepath"`
+			Success    bool   `json:"success"`
+			Violations []struct {
+				Message string `json:"msg"`
+			} `json:"violations"`
+		} `json:"filepaths"`
+	}{}
+	require.NoError(t, json.Unmarshal(out.Bytes(), &report))
+	require.Len(t, report.FilePaths, 2)
+
+	assert.Equal(t, "/trusted.yaml", report.FilePaths[0].FilePath)
+	assert.True(t, report.FilePaths[0].Success)
+
+	assert.Equal(t, "/untrusted.yaml", report.FilePaths[1].FilePath)
+	assert.False(t, report.FilePaths[1].Success)
+	require.Len(t, report.FilePaths[1].Violations, 1)
+	assert.Equal(t, "task build is from an untrusted bundle registry.io/untrusted/buildah:0.1", report.FilePaths[1].Violations[0].Message)
+}
+
+func Test_ValidatePipelineRunCommandLoadError(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/empty.yaml", []byte(`{"kind": "List", "items": []}`), 0644))
+
+	validate := func(context.Context, string, policy.Policy, bool) (*output.Output, error) {
+		return &output.Output{}, nil
+	}
+
+	cmd := setUpCobra(validatePipelineRunCmd(validate))
+	cmd.SetContext(utils.WithFS(context.Background(), fs))
+	cmd.SetArgs([]string{
+		"validate",
+		"pipeline-run",
+		"--pipeline-run",
+		"/empty.yaml",
+		"--policy",
+		`{"sources": [{"policy": ["/policy"]}]}`,
+	})
+
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+
+	err := cmd.Execute()
+	assert.ErrorContains(t, err, "unable to load pipeline run /empty.yaml: no PipelineRun found in /empty.yaml")
+}
@@ -42,6 +42,17 @@ func ValidatePolicyCmd(validate policyValidationFunc) *cobra.Command {
 		Short: "Validate the provided EnterpriseContractPolicy spec",
 		Long: hd.Doc(`
 			Validate the provided EnterpriseContractPolicy spec against the EnterpriseContractPolicy spec schema used in this version of the ec CLI
+
+			In addition to the schema, the spec is checked for the problems the schema
+			can't express, reporting all of them:
+
+			* the policy has no sources, or a source has no policy
+			* a rule is both included and excluded
+			* the effectiveOn or effectiveUntil of the volatile config is not a RFC3339
+			  timestamp, or effectiveOn is not before effectiveUntil
+
+			No image is needed to validate the policy. Apart from the missing sources,
+			the same problems prevent the policy from being used by the other commands.
 		`),
 		Example: hd.Doc(`
 			Validate a local policy configuration file:
@@ -51,6 +62,9 @@ func ValidatePolicyCmd(validate policyValidationFunc) *cobra.Command {
 			ec validate policy --policy-configuration github.com/org/repo/policy.yaml
 `),
 		PreRunE: func(cmd *cobra.Command, args []string) (allErrors error) {
+			if err := withSourceOptions(cmd); err != nil {
+				return err
+			}
 			ctx := cmd.Context()
 
 			policyConfiguration, err := validate_utils.GetPolicyConfig(ctx, data.policyConfiguration)
@@ -67,7 +81,7 @@ func ValidatePolicyCmd(validate policyValidationFunc) *cobra.Command {
 			ctx := cmd.Context()
 			err := validate(ctx, data.policyConfiguration)
 			if err != nil {
-				return fmt.Errorf("policy configuration does not conform to the EnterpriseContractPolicy spec")
+				return fmt.Errorf("policy configuration does not conform to the EnterpriseContractPolicy spec: %w", err)
 			}
 			fmt.Fprintln(cmd.OutOrStdout(), "Policy configuration conforms to the EnterpriseContractPolicy spec")
 			return nil
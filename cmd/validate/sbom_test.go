@@ -0,0 +1,138 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package validate
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/evaluator"
+	"github.com/enterprise-contract/ec-cli/internal/output"
+	"github.com/enterprise-contract/ec-cli/internal/policy"
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+func Test_ValidateSBOMCommand(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/cyclonedx.json", []byte(`{
+		"bomFormat": "CycloneDX",
+		"specVersion": "1.5",
+		"components": [{"name": "bash", "licenses": [{"license": {"id": "GPL-3.0-or-later"}}]}]
+	}`), 0644))
+	require.NoError(t, afero.WriteFile(fs, "/spdx.json", []byte(`{
+		"spdxVersion": "SPDX-2.3",
+		"packages": [{"SPDXID": "SPDXRef-requests", "name": "requests", "licenseConcluded": "Apache-2.0"}]
+	}`), 0644))
+
+	// fails components with GPL licenses
+	validate := func(ctx context.Context, fpath string, _ policy.Policy, _ bool) (*output.Output, error) {
+		b, err := afero.ReadFile(utils.FS(ctx), fpath)
+		if err != nil {
+			return nil, err
+		}
+
+		in := struct {
+			SBOM struct {
+				Components []struct {
+					Name     string   `json:"name"`
+					Licenses []string `json:"licenses"`
+				} `json:"components"`
+			} `json:"sbom"`
+		}{}
+		if err := json.Unmarshal(b, &in); err != nil {
+			return nil, err
+		}
+
+		outcome := evaluator.Outcome{}
+		for _, c := range in.SBOM.Components {
+			for _, l := range c.Licenses {
+				if l == "GPL-3.0-or-later" {
+					outcome.Failures = append(outcome.Failures, evaluator.Result{Message: fmt.Sprintf("%s has a disallowed license: %s", c.Name, l)})
+				}
+			}
+		}
+
+		return &output.Output{PolicyCheck: []evaluator.Outcome{outcome}}, nil
+	}
+
+	cmd := setUpCobra(validateSBOMCmd(validate))
+	cmd.SetContext(utils.WithFS(context.Background(), fs))
+	cmd.SetArgs([]string{
+		"validate",
+		"sbom",
+		"--file",
+		"/cyclonedx.json",
+		"--file",
+		"/spdx.json",
+		"--policy",
+		`{"sources": [{"policy": ["/policy"]}]}`,
+	})
+
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+
+	err := cmd.Execute()
+	assert.EqualError(t, err, "success criteria not met")
+
+	report := struct {
+		FilePaths []struct {
+			FilePath   string `json:"filepath"`
+			Success    bool   `json:"success"`
+			Violations []struct {
+				Message string `json:"msg"`
+			} `json:"violations"`
+		} `json:"filepaths"`
+	}{}
+	require.NoError(t, json.Unmarshal(out.Bytes(), &report))
+	require.Len(t, report.FilePaths, 2)
+
+	assert.Equal(t, "/cyclonedx.json", report.FilePaths[0].FilePath)
+	assert.False(t, report.FilePaths[0].Success)
+	require.Len(t, report.FilePaths[0].Violations, 1)
+	assert.Equal(t, "bash has a disallowed license: GPL-3.0-or-later", report.FilePaths[0].Violations[0].Message)
+
+	assert.Equal(t, "/spdx.json", report.FilePaths[1].FilePath)
+	assert.True(t, report.FilePaths[1].Success)
+}
+
+func Test_ValidateSBOMCommandUnknownFormat(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/sbom.json", []byte(`{"kind": "Task"}`), 0644))
+
+	cmd := setUpCobra(validateSBOMCmd(nil))
+	cmd.SetContext(utils.WithFS(context.Background(), fs))
+	cmd.SetArgs([]string{
+		"validate",
+		"sbom",
+		"--file",
+		"/sbom.json",
+		"--policy",
+		`{"sources": [{"policy": ["/policy"]}]}`,
+	})
+
+	err := cmd.Execute()
+	assert.EqualError(t, err, "1 error occurred:\n\t* unable to load SBOM /sbom.json: unknown SBOM format, expecting a CycloneDX or a SPDX SBOM in JSON format\n\n")
+}
@@ -0,0 +1,249 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package validate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	hd "github.com/MakeNowJust/heredoc"
+	"github.com/hashicorp/go-multierror"
+	"github.com/sigstore/cosign/v2/pkg/cosign"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+
+	"github.com/enterprise-contract/ec-cli/internal/format"
+	"github.com/enterprise-contract/ec-cli/internal/policy"
+	"github.com/enterprise-contract/ec-cli/internal/tekton_bundle"
+	"github.com/enterprise-contract/ec-cli/internal/tracker"
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+	validate_utils "github.com/enterprise-contract/ec-cli/internal/validate"
+)
+
+type taskBundleTrustFunc func(context.Context, string, tracker.Tracker, policy.Policy, tekton_bundle.TrustOptions) (*tekton_bundle.TrustResult, error)
+
+func validateTaskBundleCmd(verify taskBundleTrustFunc) *cobra.Command {
+	data := struct {
+		bundles                     []string
+		certificateIdentity         string
+		certificateIdentityRegExp   string
+		certificateOIDCIssuer       string
+		certificateOIDCIssuerRegExp string
+		effectiveTime               string
+		expiringWithin              time.Duration
+		ignoreRekor                 bool
+		ignoreSignature             bool
+		output                      []string
+		policy                      policy.Policy
+		policies                    []string
+		publicKey                   string
+		rekorURL                    string
+		strict                      bool
+		trustedTasks                string
+	}{
+		strict: true,
+	}
+	cmd := &cobra.Command{
+		Use:   "task-bundle",
+		Short: "Verify that Tekton task bundles are trusted",
+		Long: hd.Doc(`
+			Verify that Tekton task bundles are trusted
+
+			For each Tekton task bundle, the bundle is looked up in the trusted tasks, as recorded by
+			"ec track bundle", and the signature of the bundle is verified. The bundle is trusted when
+			its digest is recorded for its repository, and tag if any, and the record has not expired
+			at the effective time. Bundles whose record expires, as a newer bundle replaces them, are
+			reported as expiring, bundles whose record has expired as expired and bundles not recorded
+			as untrusted.
+
+			The verification is successful when all the bundles are trusted, or expiring, and signed.
+			The report lists the trust, the expiration and the outcome of the signature verification
+			of each bundle.
+			`),
+		Example: hd.Doc(`
+			Verify a task bundle against the trusted tasks in a local file
+
+			  ec validate task-bundle --bundle registry.io/tasks/buildah:0.1 \
+			    --trusted-tasks trusted_tasks.yaml --public-key key.pub
+
+			Verify task bundles against the trusted tasks in an image registry, reporting the bundles
+			expiring within the next week as expiring
+
+			  ec validate task-bundle --bundle registry.io/tasks/buildah:0.1 --bundle registry.io/tasks/git-clone:0.1 \
+			    --trusted-tasks oci:registry.io/tasks/trusted-tasks:latest --expiring-within 168h \
+			    --policy my-policy.yaml
+			`),
+		PreRunE: func(cmd *cobra.Command, args []string) (allErrors error) {
+			if err := withSourceOptions(cmd); err != nil {
+				return err
+			}
+			ctx := cmd.Context()
+
+			policyConfiguration, err := validate_utils.GetPolicyConfigs(ctx, data.policies)
+			if err != nil {
+				allErrors = multierror.Append(allErrors, err)
+				return
+			}
+
+			var p policy.Policy
+			if data.ignoreSignature {
+				// no signing materials are needed when the signatures are not verified
+				p, err = policy.NewInputPolicy(ctx, policyConfiguration, data.effectiveTime)
+			} else {
+				p, err = policy.NewPolicy(ctx, policy.Options{
+					EffectiveTime: data.effectiveTime,
+					Identity: cosign.Identity{
+						Issuer:        data.certificateOIDCIssuer,
+						IssuerRegExp:  data.certificateOIDCIssuerRegExp,
+						Subject:       data.certificateIdentity,
+						SubjectRegExp: data.certificateIdentityRegExp,
+					},
+					IgnoreRekor: data.ignoreRekor,
+					PolicyRef:   policyConfiguration,
+					PublicKey:   data.publicKey,
+					RekorURL:    data.rekorURL,
+				})
+			}
+			if err != nil {
+				allErrors = multierror.Append(allErrors, err)
+			} else {
+				data.policy = p
+			}
+
+			return
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			var content []byte
+			var err error
+			if strings.HasPrefix(data.trustedTasks, "oci:") {
+				content, err = tracker.PullImage(ctx, strings.TrimPrefix(data.trustedTasks, "oci:"))
+			} else {
+				content, err = afero.ReadFile(utils.FS(ctx), data.trustedTasks)
+			}
+			if err != nil {
+				return fmt.Errorf("unable to read the trusted tasks from %s: %w", data.trustedTasks, err)
+			}
+
+			trusted, err := tracker.Parse(content)
+			if err != nil {
+				return err
+			}
+
+			opts := tekton_bundle.TrustOptions{
+				ExpiringWithin:  data.expiringWithin,
+				IgnoreSignature: data.ignoreSignature,
+			}
+
+			results := make([]tekton_bundle.TrustResult, 0, len(data.bundles))
+			var allErrors error
+			for _, ref := range data.bundles {
+				result, err := verify(ctx, ref, trusted, data.policy, opts)
+				if err != nil {
+					allErrors = multierror.Append(allErrors, err)
+					continue
+				}
+				results = append(results, *result)
+			}
+			if allErrors != nil {
+				return allErrors
+			}
+
+			report := tekton_bundle.NewTrustReport(results, data.policy)
+
+			p := format.NewTargetParser(tekton_bundle.JSON, format.Options{}, cmd.OutOrStdout(), utils.FS(ctx))
+			if err := report.WriteAll(data.output, p); err != nil {
+				return err
+			}
+
+			if data.strict && !report.Success {
+				return errors.New("success criteria not met")
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringSliceVarP(&data.bundles, "bundle", "b", data.bundles,
+		"Tekton task bundle image reference. May be used multiple times.")
+
+	cmd.Flags().StringVar(&data.trustedTasks, "trusted-tasks", data.trustedTasks, hd.Doc(`
+		Trusted tasks, as recorded by "ec track bundle", either a path to the file or an image in
+		the oci:<reference> format.`))
+
+	cmd.Flags().StringArrayVarP(&data.policies, "policy", "p", data.policies, hd.Doc(`
+		Policy configuration providing the signing materials as:
+		  * Kubernetes reference ([<namespace>/]<name> or <name>@<namespace>)
+		  * file (policy.yaml or file:policy.yaml)
+		  * OCI artifact (oci::quay.io/org/policy:tag)
+		  * git reference (github.com/user/repo//default?ref=main), or
+		  * inline JSON ('{sources: {...}, configuration: {...}}')")
+		Can be repeated to merge several policies, each overriding the ones before it.`))
+
+	cmd.Flags().DurationVar(&data.expiringWithin, "expiring-within", data.expiringWithin, hd.Doc(`
+		Report only the bundles expiring within the given duration from the effective time as
+		expiring, e.g. 168h. By default all bundles with an expiration are reported as expiring.`))
+
+	cmd.Flags().BoolVar(&data.ignoreSignature, "ignore-signature", data.ignoreSignature,
+		"Skip the verification of the signatures of the bundles.")
+
+	cmd.Flags().StringVarP(&data.publicKey, "public-key", "k", data.publicKey,
+		"path to the public key. Overrides publicKey from EnterpriseContractPolicy")
+
+	cmd.Flags().StringVarP(&data.rekorURL, "rekor-url", "r", data.rekorURL,
+		"Rekor URL. Overrides rekorURL from EnterpriseContractPolicy")
+
+	cmd.Flags().BoolVar(&data.ignoreRekor, "ignore-rekor", data.ignoreRekor,
+		"Skip Rekor transparency log checks during validation.")
+
+	cmd.Flags().StringVar(&data.certificateIdentity, "certificate-identity", data.certificateIdentity,
+		"URL of the certificate identity for keyless verification")
+
+	cmd.Flags().StringVar(&data.certificateIdentityRegExp, "certificate-identity-regexp", data.certificateIdentityRegExp,
+		"Regular expression for the URL of the certificate identity for keyless verification")
+
+	cmd.Flags().StringVar(&data.certificateOIDCIssuer, "certificate-oidc-issuer", data.certificateOIDCIssuer,
+		"URL of the certificate OIDC issuer for keyless verification")
+
+	cmd.Flags().StringVar(&data.certificateOIDCIssuerRegExp, "certificate-oidc-issuer-regexp", data.certificateOIDCIssuerRegExp,
+		"Regular expresssion for the URL of the certificate OIDC issuer for keyless verification")
+
+	cmd.Flags().StringSliceVarP(&data.output, "output", "o", data.output, hd.Doc(`
+		Write output to a file in a specific format, e.g. yaml=/tmp/output.yaml. Use empty string
+		path for stdout, e.g. yaml. May be used multiple times. Possible formats are: json, yaml`))
+
+	cmd.Flags().BoolVarP(&data.strict, "strict", "s", data.strict,
+		"Return non-zero status when any of the bundles is not trusted or not signed")
+
+	cmd.Flags().StringVar(&data.effectiveTime, "effective-time", policy.Now, hd.Doc(`
+		Verify the trust of the bundles at the provided time. The value can be "now" (default) - for
+		current time, or a RFC3339 formatted value, e.g. 2022-11-18T00:00:00Z.`))
+
+	if err := cmd.MarkFlagRequired("bundle"); err != nil {
+		panic(err)
+	}
+
+	if err := cmd.MarkFlagRequired("trusted-tasks"); err != nil {
+		panic(err)
+	}
+
+	return cmd
+}
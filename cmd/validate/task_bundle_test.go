@@ -0,0 +1,148 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package validate
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/policy"
+	"github.com/enterprise-contract/ec-cli/internal/tekton_bundle"
+	"github.com/enterprise-contract/ec-cli/internal/tracker"
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+const trustedTasks = `trusted_tasks:
+  oci://registry.io/tasks/buildah:0.1:
+    - ref: sha256:4e388ab32b10dc8dbc7e28144f552830adc74787c1e2c0824032078a79f227fb
+      effective_on: "2024-06-01T00:00:00Z"
+`
+
+func Test_ValidateTaskBundleCommand(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/trusted_tasks.yaml", []byte(trustedTasks), 0644))
+
+	var gotOpts tekton_bundle.TrustOptions
+	verify := func(_ context.Context, ref string, trusted tracker.Tracker, p policy.Policy, opts tekton_bundle.TrustOptions) (*tekton_bundle.TrustResult, error) {
+		gotOpts = opts
+		assert.Len(t, trusted.TrustedTasks, 1)
+		assert.Equal(t, time.Date(2024, 5, 20, 0, 0, 0, 0, time.UTC), p.EffectiveTime())
+
+		if ref == "registry.io/tasks/buildah:0.1" {
+			return &tekton_bundle.TrustResult{Ref: ref, Trust: tracker.Trusted, Success: true}, nil
+		}
+		return &tekton_bundle.TrustResult{Ref: ref, Trust: tracker.Untrusted}, nil
+	}
+
+	cases := []struct {
+		name    string
+		bundles []string
+		success bool
+	}{
+		{name: "trusted", bundles: []string{"registry.io/tasks/buildah:0.1"}, success: true},
+		{name: "untrusted", bundles: []string{"registry.io/tasks/buildah:0.1", "registry.io/tasks/other:0.1"}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cmd := setUpCobra(validateTaskBundleCmd(verify))
+			cmd.SetContext(utils.WithFS(context.Background(), fs))
+
+			args := []string{
+				"validate",
+				"task-bundle",
+				"--trusted-tasks",
+				"/trusted_tasks.yaml",
+				"--ignore-signature",
+				"--expiring-within",
+				"168h",
+				"--effective-time",
+				"2024-05-20T00:00:00Z",
+			}
+			for _, b := range c.bundles {
+				args = append(args, "--bundle", b)
+			}
+			cmd.SetArgs(args)
+
+			var out bytes.Buffer
+			cmd.SetOut(&out)
+
+			err := cmd.Execute()
+			if c.success {
+				require.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, "success criteria not met")
+			}
+
+			assert.Equal(t, tekton_bundle.TrustOptions{ExpiringWithin: 168 * time.Hour, IgnoreSignature: true}, gotOpts)
+
+			report := tekton_bundle.TrustReport{}
+			require.NoError(t, json.Unmarshal(out.Bytes(), &report))
+			assert.Equal(t, c.success, report.Success)
+			assert.Len(t, report.Bundles, len(c.bundles))
+		})
+	}
+}
+
+func Test_ValidateTaskBundleCommandTrustedTasksErrors(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/empty.yaml", []byte{}, 0644))
+
+	verify := func(context.Context, string, tracker.Tracker, policy.Policy, tekton_bundle.TrustOptions) (*tekton_bundle.TrustResult, error) {
+		return &tekton_bundle.TrustResult{}, nil
+	}
+
+	cases := []struct {
+		name         string
+		trustedTasks string
+		err          string
+	}{
+		{name: "missing", trustedTasks: "/missing.yaml", err: "unable to read the trusted tasks from /missing.yaml"},
+		{name: "empty", trustedTasks: "/empty.yaml", err: "no trusted tasks found, the trusted tasks are empty"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cmd := setUpCobra(validateTaskBundleCmd(verify))
+			cmd.SetContext(utils.WithFS(context.Background(), fs))
+			cmd.SetArgs([]string{
+				"validate",
+				"task-bundle",
+				"--bundle",
+				"registry.io/tasks/buildah:0.1",
+				"--trusted-tasks",
+				c.trustedTasks,
+				"--ignore-signature",
+			})
+
+			var out bytes.Buffer
+			cmd.SetOut(&out)
+
+			err := cmd.Execute()
+			assert.ErrorContains(t, err, c.err)
+		})
+	}
+}
@@ -17,13 +17,26 @@
 package validate
 
 import (
+	"context"
+	"fmt"
+	"os"
+
+	hd "github.com/MakeNowJust/heredoc"
+	"github.com/sigstore/cosign/v2/pkg/cosign"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
 	"github.com/spf13/cobra"
 
 	"github.com/enterprise-contract/ec-cli/internal/definition"
+	"github.com/enterprise-contract/ec-cli/internal/downloader"
+	"github.com/enterprise-contract/ec-cli/internal/evaluator"
 	"github.com/enterprise-contract/ec-cli/internal/image"
 	"github.com/enterprise-contract/ec-cli/internal/input"
 	"github.com/enterprise-contract/ec-cli/internal/policy"
+	"github.com/enterprise-contract/ec-cli/internal/policy/source"
 	_ "github.com/enterprise-contract/ec-cli/internal/rego"
+	"github.com/enterprise-contract/ec-cli/internal/tekton_bundle"
+	"github.com/enterprise-contract/ec-cli/internal/utils"
 )
 
 var ValidateCmd *cobra.Command
@@ -36,6 +49,10 @@ func init() {
 	ValidateCmd.AddCommand(validateImageCmd(image.ValidateImage))
 	ValidateCmd.AddCommand(validateDefinitionCmd(definition.ValidateDefinition))
 	ValidateCmd.AddCommand(validateInputCmd(input.ValidateInput))
+	ValidateCmd.AddCommand(validateBundleCmd(input.ValidateInput))
+	ValidateCmd.AddCommand(validateSBOMCmd(input.ValidateInput))
+	ValidateCmd.AddCommand(validatePipelineRunCmd(input.ValidateInput))
+	ValidateCmd.AddCommand(validateTaskBundleCmd(tekton_bundle.VerifyTrust))
 	ValidateCmd.AddCommand(ValidatePolicyCmd(policy.ValidatePolicy))
 }
 
@@ -45,5 +62,346 @@ func NewValidateCmd() *cobra.Command {
 		Short: "Validate conformance with the Enterprise Contract",
 	}
 	validateCmd.PersistentFlags().Bool("show-successes", false, "")
+	validateCmd.PersistentFlags().String("experimental-rego-v1", evaluator.RegoV0, hd.Doc(`
+		EXPERIMENTAL. Syntax of the rego policy modules, one of "v0", "v1" or "auto". With "auto"
+		the syntax is determined per policy source from the rego_version and file_rego_versions
+		attributes of the OPA bundle .manifest file. When given without a value "v1" is used.`))
+	validateCmd.PersistentFlags().Lookup("experimental-rego-v1").NoOptDefVal = evaluator.RegoV1
+	validateCmd.PersistentFlags().String("evaluator", evaluator.EngineConftest, hd.Doc(`
+		Engine evaluating the policies, one of "conftest" or "wasm". With "wasm" the policy rules
+		are compiled to OPA WASM modules and executed by the WASM runtime, which requires ec to
+		be built with cgo enabled.`))
+	validateCmd.PersistentFlags().Int64("max-download-bytes", 0, hd.Doc(`
+		Maximum total number of bytes downloaded from all policy, data and configuration sources
+		during the run. Once exceeded further downloads fail. Zero, the default, means no limit.`))
+	validateCmd.PersistentFlags().Int64("max-source-bytes", 0, hd.Doc(`
+		Maximum number of bytes each policy, data and configuration source can take up on disk.
+		The download of a source exceeding it is aborted. Zero, the default, means no limit.`))
+	validateCmd.PersistentFlags().Int64("disk-quota", 0, hd.Doc(`
+		Maximum total number of bytes all policy, data and configuration sources can take up on
+		disk, including the sources read from the cache. The download exceeding it is aborted and
+		further downloads fail. Zero, the default, means no limit.`))
+	validateCmd.PersistentFlags().Int64("max-archive-bytes", downloader.DefaultMaxArchiveBytes, hd.Doc(`
+		Maximum total number of bytes extracted from each .tar.gz, .tgz or .zip archive downloaded
+		as a policy, data or configuration source. Archives exceeding it fail to extract.`))
+	validateCmd.PersistentFlags().Bool("require-all-sources", false, hd.Doc(`
+		Fail if any of the policy or data sources fails to download, naming each of the failed
+		sources. By default the validation proceeds without the failed sources, as long as at
+		least one of the policy sources was downloaded.`))
+	validateCmd.PersistentFlags().StringSlice("rule-effective-time", nil, hd.Doc(`
+		Effective time to use for the given rules instead of the --effective-time, in the form of
+		name=time, where name is a rule code or a package name and time is in RFC3339 format, e.g.
+		"attestation_type.known_attestation_type=2024-01-01T00:00:00Z". Takes precedence over the
+		effective_time annotation of the rule. Can be repeated.`))
+	validateCmd.PersistentFlags().Bool("local-policy-in-place", false, hd.Doc(`
+		Evaluate policy and data sources that are local directories in place, instead of copying
+		them into the working directory. Useful for large local policy repositories.`))
+	validateCmd.PersistentFlags().Bool("no-cache", false, hd.Doc(`
+		Do not use the local cache of policy, data and configuration sources. By default the
+		downloaded sources pinned to a git commit or an OCI digest, see --cache-mutable-sources,
+		are cached within the ec/sources directory of $XDG_CACHE_HOME and used instead of
+		downloading the same source again, and the rule metadata read from the policy sources is
+		cached within the ec/compiled directory and used instead of parsing the Rego files of
+		sources with the same content again. The policies are compiled for every run regardless.
+		Use "ec cache clear" to remove the cached sources and rule metadata.`))
+	validateCmd.PersistentFlags().String("cache-dir", "", hd.Doc(`
+		Directory of the source cache, defaults to the ec/sources directory of $XDG_CACHE_HOME. A
+		cache directory populated by an earlier run can be provided to run with --offline, the
+		sources not pinned to a commit or a digest are cached with --cache-mutable-sources.`))
+	validateCmd.PersistentFlags().Duration("cache-ttl", downloader.DefaultCacheTTL, hd.Doc(`
+		Duration for which cached sources are used before downloading them again. Cached sources
+		older than this are removed from the cache.`))
+	validateCmd.PersistentFlags().Bool("cache-mutable-sources", false, hd.Doc(`
+		Also cache the sources that are not pinned to a git commit or an OCI digest, e.g. git
+		branches and tags or OCI tags. The cached copies of such sources are used for the
+		--cache-ttl even if the source changed in the meantime.`))
+	validateCmd.PersistentFlags().Int("download-retries", 0, hd.Doc(`
+		Number of times a failed download of a policy, data or configuration source is retried,
+		waiting with an exponential backoff between the retries. Errors that are not transient,
+		like authentication failures or sources that don't exist, are not retried.`))
+	validateCmd.PersistentFlags().Duration("download-timeout", 0, hd.Doc(`
+		Maximum duration of each download attempt of a policy, data or configuration source, e.g.
+		"30s" or "2m". Attempts that time out are retried as set by --download-retries. Zero, the
+		default, means no timeout.`))
+	validateCmd.PersistentFlags().Float64("download-rate-limit", 0, hd.Doc(`
+		Maximum number of downloads of policy, data and configuration sources started per second
+		from each host, including retries, e.g. "0.5" for one download every two seconds. Zero, the
+		default, means no limit.`))
+	validateCmd.PersistentFlags().Int("download-max-concurrent", 0, hd.Doc(`
+		Maximum number of concurrent downloads of policy, data and configuration sources from each
+		host. Zero, the default, means no limit.`))
+	validateCmd.PersistentFlags().StringArray("policy-source-mirror", nil, hd.Doc(`
+		Mirror of the policy, data and configuration sources, in the form of <prefix>=<mirror>,
+		e.g. "quay.io/org=registry.internal/org". Sources with URLs starting with the prefix that
+		fail to download are downloaded from the mirror instead, with the prefix replaced by the
+		mirror. The mirrors are tried in the given order. The output records the mirror used for
+		each such source. Can be repeated.`))
+	validateCmd.PersistentFlags().StringArray("source-backend", nil, hd.Doc(`
+		Backend used to download the policy, data and configuration sources of the given scheme,
+		in the form of <scheme>=<backend>, e.g. "git=go-gather". The scheme is the getter or the
+		protocol of the source URL, one of file, git, http, oci, s3, gcs or hg. The backend is
+		either conftest, the default, or go-gather, which supports the file, git, http and oci
+		schemes. Can be repeated.`))
+	validateCmd.PersistentFlags().StringArray("git-token", nil, hd.Doc(`
+		Token used to access private git repositories holding policy, data or configuration sources
+		over HTTPS, given either as <token>, used for all hosts, or as <host>=<token>, used only for
+		the given host. Can be repeated. Defaults to the value of the EC_GIT_TOKEN environment
+		variable. Not used for sources with credentials in their URL.`))
+	validateCmd.PersistentFlags().StringArray("credential-helper", nil, hd.Doc(`
+		Credential helper providing the credentials used to download the policy, data and
+		configuration sources of the given scheme, in the form of <scheme>=<helper>, e.g.
+		s3=/usr/local/bin/vault-aws-helper. Supported schemes are git, s3 and gcs. The helper is
+		invoked following the protocol of the Docker credential helpers for each download. Can be
+		repeated. Not used for sources with credentials in their URL.`))
+	validateCmd.PersistentFlags().String("proxy", "", hd.Doc(`
+		URL of the proxy, e.g. "http://proxy.example.com:3128", used to download policy, data and
+		configuration sources, taking precedence over the HTTP_PROXY and HTTPS_PROXY environment
+		variables. Hosts listed in the NO_PROXY environment variable are accessed directly. S3 and
+		GCS sources, and git sources not accessed over HTTP(S), use the proxy of the environment.`))
+	validateCmd.PersistentFlags().String("tls-ca-bundle", "", hd.Doc(`
+		Path to a PEM file with additional CA certificates to trust when downloading policy, data
+		and configuration sources, in addition to the system CA certificates. Not used for S3 and GCS
+		sources.`))
+	validateCmd.PersistentFlags().Bool("insecure-policy-source", false, hd.Doc(`
+		Allow downloading policy, data and configuration sources that don't use network transport
+		security, e.g. plain HTTP. A warning is logged for each such source. Meant for internal
+		mirrors within trusted networks, content downloaded this way can be tampered with in transit.`))
+	validateCmd.PersistentFlags().String("policy-source-public-key", "", hd.Doc(`
+		Public key used to verify the signatures of OCI policy, data and configuration sources
+		before they're downloaded. Accepts the same values as --public-key. Sources not signed with
+		the key fail to download.`))
+	validateCmd.PersistentFlags().String("policy-source-certificate-identity", "", hd.Doc(`
+		URL of the certificate identity for the keyless verification of the signatures of OCI
+		policy, data and configuration sources`))
+	validateCmd.PersistentFlags().String("policy-source-certificate-identity-regexp", "", hd.Doc(`
+		Regular expression for the URL of the certificate identity for the keyless verification
+		of the signatures of OCI policy, data and configuration sources`))
+	validateCmd.PersistentFlags().String("policy-source-certificate-oidc-issuer", "", hd.Doc(`
+		URL of the certificate OIDC issuer for the keyless verification of the signatures of OCI
+		policy, data and configuration sources`))
+	validateCmd.PersistentFlags().String("policy-source-certificate-oidc-issuer-regexp", "", hd.Doc(`
+		Regular expression for the URL of the certificate OIDC issuer for the keyless verification
+		of the signatures of OCI policy, data and configuration sources`))
+	validateCmd.PersistentFlags().String("policy-bundle-public-key", "", hd.Doc(`
+		Path to the PEM encoded public key used to verify the signatures of the policy and data
+		sources, which are then required to be OPA bundles signed as described in the OPA bundle
+		specification, e.g. built with "opa build --signing-key". Sources that are not signed
+		bundles, or have been modified since signed, fail to download.`))
+	validateCmd.PersistentFlags().String("policy-bundle-signing-alg", source.DefaultBundleSigningAlg, hd.Doc(`
+		Algorithm the policy and data bundles are signed with, e.g. RS256, PS256 or ES256`))
 	return validateCmd
 }
+
+// withSourceOptions sets the command's context to one configured, as given
+// by the persistent flags of the validate command, for downloading the policy,
+// data and configuration sources: the download budget, the disk quota, the
+// archive size limit, whether all sources are required, retries and timeouts,
+// per host limits, mirrors, the download backends, git credentials, credential
+// helpers, the proxy and the trusted CA certificates, insecure sources, local
+// sources used in place, the signature verification of OCI sources and of OPA
+// bundles and the caches of sources and of rule metadata.
+func withSourceOptions(cmd *cobra.Command) error {
+	if limit, _ := cmd.Flags().GetInt64("max-download-bytes"); limit > 0 {
+		cmd.SetContext(downloader.WithDownloadBudget(cmd.Context(), limit))
+	}
+
+	maxSource, _ := cmd.Flags().GetInt64("max-source-bytes")
+	quota, _ := cmd.Flags().GetInt64("disk-quota")
+	if maxSource > 0 || quota > 0 {
+		cmd.SetContext(downloader.WithDiskQuota(cmd.Context(), downloader.DiskQuota{MaxSourceBytes: maxSource, MaxTotalBytes: quota}))
+	}
+
+	if limit, _ := cmd.Flags().GetInt64("max-archive-bytes"); limit > 0 {
+		cmd.SetContext(downloader.WithArchiveLimits(cmd.Context(), downloader.ArchiveLimits{MaxBytes: limit}))
+	}
+
+	if require, _ := cmd.Flags().GetBool("require-all-sources"); require {
+		cmd.SetContext(evaluator.WithRequireAllSources(cmd.Context(), true))
+	}
+
+	if retries, _ := cmd.Flags().GetInt("download-retries"); retries > 0 {
+		cmd.SetContext(downloader.WithDownloadRetry(cmd.Context(), retries+1, downloader.DefaultRetryDelay))
+	}
+
+	if timeout, _ := cmd.Flags().GetDuration("download-timeout"); timeout > 0 {
+		cmd.SetContext(downloader.WithDownloadTimeout(cmd.Context(), timeout))
+	}
+
+	rateLimit, _ := cmd.Flags().GetFloat64("download-rate-limit")
+	maxConcurrent, _ := cmd.Flags().GetInt("download-max-concurrent")
+	if rateLimit > 0 || maxConcurrent > 0 {
+		cmd.SetContext(downloader.WithHostLimits(cmd.Context(), downloader.HostLimits{
+			Rate:          rateLimit,
+			Burst:         1,
+			MaxConcurrent: maxConcurrent,
+		}))
+	}
+
+	if values, _ := cmd.Flags().GetStringArray("policy-source-mirror"); len(values) > 0 {
+		mirrors := make([]downloader.Mirror, 0, len(values))
+		for _, v := range values {
+			m, err := downloader.ParseMirror(v)
+			if err != nil {
+				return err
+			}
+			mirrors = append(mirrors, m)
+		}
+		cmd.SetContext(downloader.WithMirrors(cmd.Context(), mirrors))
+	}
+
+	if values, _ := cmd.Flags().GetStringArray("source-backend"); len(values) > 0 {
+		routes := downloader.Routes{}
+		for _, v := range values {
+			if err := routes.ParseRoute(v); err != nil {
+				return err
+			}
+		}
+		cmd.SetContext(downloader.WithRoutes(cmd.Context(), routes))
+	}
+
+	values, _ := cmd.Flags().GetStringArray("git-token")
+	if token := os.Getenv(downloader.GitTokenEnv); len(values) == 0 && token != "" {
+		values = []string{token}
+	}
+	if len(values) > 0 {
+		tokens := downloader.GitTokens{}
+		for _, v := range values {
+			tokens.ParseGitToken(v)
+		}
+		cmd.SetContext(downloader.WithGitTokens(cmd.Context(), tokens))
+	}
+
+	if values, _ := cmd.Flags().GetStringArray("credential-helper"); len(values) > 0 {
+		helpers := downloader.CredentialHelpers{}
+		for _, v := range values {
+			if err := helpers.ParseCredentialHelper(v); err != nil {
+				return err
+			}
+		}
+		cmd.SetContext(downloader.WithCredentialHelpers(cmd.Context(), helpers))
+	}
+
+	proxy, _ := cmd.Flags().GetString("proxy")
+	bundle, _ := cmd.Flags().GetString("tls-ca-bundle")
+	if proxy != "" || bundle != "" {
+		t, err := downloader.NewTransport(proxy, bundle)
+		if err != nil {
+			return err
+		}
+		cmd.SetContext(downloader.WithTransport(cmd.Context(), t))
+	}
+
+	if insecure, _ := cmd.Flags().GetBool("insecure-policy-source"); insecure {
+		cmd.SetContext(downloader.WithInsecureSources(cmd.Context(), true))
+	}
+
+	if inPlace, _ := cmd.Flags().GetBool("local-policy-in-place"); inPlace {
+		cmd.SetContext(downloader.WithLocalInPlace(cmd.Context(), true))
+	}
+
+	if opts, err := sourceCheckOpts(cmd); err != nil {
+		return err
+	} else if opts != nil {
+		cmd.SetContext(downloader.WithSignatureVerification(cmd.Context(), opts))
+	}
+
+	if publicKey, _ := cmd.Flags().GetString("policy-bundle-public-key"); publicKey != "" {
+		key, err := afero.ReadFile(utils.FS(cmd.Context()), publicKey)
+		if err != nil {
+			return fmt.Errorf("reading the public key of the policy bundles: %w", err)
+		}
+		alg, _ := cmd.Flags().GetString("policy-bundle-signing-alg")
+		cmd.SetContext(source.WithBundleVerification(cmd.Context(), source.NewBundleVerificationConfig(key, alg)))
+	}
+
+	if noCache, err := cmd.Flags().GetBool("no-cache"); err == nil && !noCache {
+		if dir, err := evaluator.DefaultPolicyCacheDir(); err != nil {
+			log.Debugf("Not caching the rule metadata, unable to determine the cache directory: %v", err)
+		} else {
+			cmd.SetContext(evaluator.WithPolicyCache(cmd.Context(), dir))
+		}
+
+		ttl, _ := cmd.Flags().GetDuration("cache-ttl")
+		dir, _ := cmd.Flags().GetString("cache-dir")
+		if dir == "" {
+			if dir, err = downloader.DefaultCacheDir(); err != nil {
+				log.Debugf("Not caching sources, unable to determine the cache directory: %v", err)
+				return nil
+			}
+		}
+
+		// in offline mode the sources can't be downloaded again once removed
+		if offline, _ := cmd.Flags().GetBool("offline"); !offline {
+			if err := downloader.PruneCache(utils.FS(cmd.Context()), dir, ttl); err != nil {
+				log.Warnf("Unable to prune the source cache at %s: %v", dir, err)
+			}
+		}
+
+		mutable, _ := cmd.Flags().GetBool("cache-mutable-sources")
+		cmd.SetContext(downloader.WithCache(cmd.Context(), dir, ttl, mutable))
+	}
+
+	return nil
+}
+
+// sourceCheckOpts returns the options for verifying the signatures of OCI
+// sources, as given by the --policy-source-public-key flag or the
+// --policy-source-certificate-* flags for the keyless workflow, or nil if
+// none of them is given
+func sourceCheckOpts(cmd *cobra.Command) (*cosign.CheckOpts, error) {
+	publicKey, _ := cmd.Flags().GetString("policy-source-public-key")
+	identity := cosign.Identity{}
+	identity.Subject, _ = cmd.Flags().GetString("policy-source-certificate-identity")
+	identity.SubjectRegExp, _ = cmd.Flags().GetString("policy-source-certificate-identity-regexp")
+	identity.Issuer, _ = cmd.Flags().GetString("policy-source-certificate-oidc-issuer")
+	identity.IssuerRegExp, _ = cmd.Flags().GetString("policy-source-certificate-oidc-issuer-regexp")
+
+	if publicKey == "" && identity == (cosign.Identity{}) {
+		return nil, nil
+	}
+
+	p, err := policy.NewPolicy(cmd.Context(), policy.Options{
+		EffectiveTime: policy.Now,
+		Identity:      identity,
+		PublicKey:     publicKey,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("configuring the signature verification of policy sources: %w", err)
+	}
+
+	return p.CheckOpts()
+}
+
+// withEvaluationOptions returns the command's context configured with the
+// rego syntax version provided via the --experimental-rego-v1 flag, the rule
+// effective times provided via the --rule-effective-time flag and the engine
+// provided via the --evaluator flag
+func withEvaluationOptions(cmd *cobra.Command) (context.Context, error) {
+	ctx := cmd.Context()
+
+	if engine, _ := cmd.Flags().GetString("evaluator"); engine != "" {
+		if err := evaluator.ValidateEngine(engine); err != nil {
+			return ctx, err
+		}
+		ctx = evaluator.WithEngine(ctx, engine)
+	}
+
+	if overrides, _ := cmd.Flags().GetStringSlice("rule-effective-time"); len(overrides) > 0 {
+		times, err := evaluator.ParseRuleEffectiveTimes(overrides)
+		if err != nil {
+			return ctx, err
+		}
+		ctx = evaluator.WithRuleEffectiveTimes(ctx, times)
+	}
+
+	version, _ := cmd.Flags().GetString("experimental-rego-v1")
+	if version == "" {
+		return ctx, nil
+	}
+
+	if err := evaluator.ValidateRegoVersion(version); err != nil {
+		return ctx, err
+	}
+
+	return evaluator.WithRegoVersion(ctx, version), nil
+}
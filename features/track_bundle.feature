@@ -115,7 +115,7 @@ Feature: track bundles
         - effective_on: 2006-01-02T15:04:05Z
           ref: f0cacc1a
     """
-    When ec command is run with "track tekton-task --input ${TMPDIR}/bundles.yaml --git git+https://forge.io/organization/repository.git//task/0.1/task.yaml@f0cacc1af00d"
+    When ec command is run with "track tekton-task --input ${TMPDIR}/bundles.yaml --git git+https://forge.io/organization/repository.git//task/0.1/task.yaml@f0cacc1af00df0cacc1af00df0cacc1af00df0ca"
     Then the exit status should be 0
     Then the output should match the snapshot
 
@@ -126,9 +126,9 @@ Feature: track bundles
     trusted_tasks:
       git+https://forge.io/organization/repository.git//task/0.1/task.yaml:
         - effective_on: 2006-01-02T15:04:05Z
-          ref: f0cacc1a
+          ref: f0cacc1af0cacc1af0cacc1af0cacc1af0cacc1a
     """
-    When ec command is run with "track tekton-task --prune --input ${TMPDIR}/bundles.yaml --git git+https://forge.io/organization/repository.git//task/0.1/task.yaml@f0cacc1a"
+    When ec command is run with "track tekton-task --prune --input ${TMPDIR}/bundles.yaml --git git+https://forge.io/organization/repository.git//task/0.1/task.yaml@f0cacc1af0cacc1af0cacc1af0cacc1af0cacc1a"
     Then the exit status should be 0
     Then the output should match the snapshot
 
@@ -139,6 +139,13 @@ Feature: track bundles
     Then the exit status should be 1
     Then the output should match the snapshot
 
+  Scenario: Track git references, resolving a branch
+    Given a git repository named "tasks" with
+      | task.yaml | examples/task.yaml |
+    When ec command is run with "track tekton-task --git git+https://${GITHOST}/git/tasks.git//task.yaml@master"
+    Then the exit status should be 0
+    Then the output should match the snapshot
+
   Scenario: Track git references, with freshen
     Given a track bundle file named "${TMPDIR}/bundles.yaml" containing
     """
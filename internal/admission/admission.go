@@ -0,0 +1,223 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package admission implements a Kubernetes validating admission webhook
+// admitting the workloads only when all of their images conform to the
+// Enterprise Contract.
+package admission
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	admissionv1 "k8s.io/api/admission/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/enterprise-contract/ec-cli/internal/applicationsnapshot"
+	"github.com/enterprise-contract/ec-cli/internal/evaluator"
+)
+
+// maxRequestBytes limits the size of the AdmissionReview accepted, the API
+// server limits the objects to a few megabytes
+const maxRequestBytes = 10 * 1024 * 1024
+
+// ValidateFunc validates the given images, returning a component with the
+// outcome of the validation of each image
+type ValidateFunc func(ctx context.Context, images []string) ([]applicationsnapshot.Component, error)
+
+// Handler handles the AdmissionReview requests sent by the Kubernetes API
+// server to a ValidatingAdmissionWebhook. The images of the workloads being
+// created or updated are validated and the request is denied, with the
+// violations in the status message, unless all of the images conform.
+type Handler struct {
+	Validate ValidateFunc
+}
+
+func (h Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "only POST is supported", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var review admissionv1.AdmissionReview
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBytes)).Decode(&review); err != nil {
+		http.Error(w, fmt.Sprintf("unable to decode the AdmissionReview: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	if review.Request == nil {
+		http.Error(w, "the AdmissionReview holds no request", http.StatusBadRequest)
+		return
+	}
+
+	review.Response = h.review(r.Context(), review.Request)
+	review.Response.UID = review.Request.UID
+	review.Request = nil
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(review); err != nil {
+		log.Errorf("unable to write the AdmissionReview response: %v", err)
+	}
+}
+
+func (h Handler) review(ctx context.Context, req *admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse {
+	if req.Operation != admissionv1.Create && req.Operation != admissionv1.Update {
+		return allowed()
+	}
+
+	images, err := Images(req)
+	if err != nil {
+		return denied(http.StatusBadRequest, err.Error())
+	}
+
+	if len(images) == 0 {
+		return allowed()
+	}
+
+	log.Debugf("Validating the images of %s %s/%s: %v", req.Kind.Kind, req.Namespace, req.Name, images)
+	components, err := h.Validate(ctx, images)
+	if err != nil {
+		return denied(http.StatusInternalServerError, fmt.Sprintf("unable to validate the images: %v", err))
+	}
+
+	var violations []string
+	for _, c := range components {
+		if c.Success {
+			continue
+		}
+
+		for _, v := range c.Violations {
+			violations = append(violations, fmt.Sprintf("%s: [%s] %s", c.ContainerImage, evaluator.ExtractStringFromMetadata(v, "code"), v.Message))
+		}
+
+		if len(c.Violations) == 0 {
+			violations = append(violations, fmt.Sprintf("%s: failed validation", c.ContainerImage))
+		}
+	}
+
+	if len(violations) > 0 {
+		return denied(http.StatusForbidden, "Enterprise Contract violations: "+strings.Join(violations, "; "))
+	}
+
+	return allowed()
+}
+
+func allowed() *admissionv1.AdmissionResponse {
+	return &admissionv1.AdmissionResponse{Allowed: true}
+}
+
+func denied(code int32, message string) *admissionv1.AdmissionResponse {
+	return &admissionv1.AdmissionResponse{
+		Allowed: false,
+		Result: &metav1.Status{
+			Status:  metav1.StatusFailure,
+			Code:    code,
+			Message: message,
+		},
+	}
+}
+
+// Images returns the images of the Pod, or the Pod template of the workload,
+// in the request, in the order of the containers and without duplicates.
+// Pods, Deployments, ReplicaSets, StatefulSets, DaemonSets, Jobs and
+// CronJobs are supported, no images are returned for any other kind.
+func Images(req *admissionv1.AdmissionRequest) ([]string, error) {
+	var spec *corev1.PodSpec
+
+	decode := func(obj any) error {
+		if err := json.Unmarshal(req.Object.Raw, obj); err != nil {
+			return fmt.Errorf("unable to decode the %s: %w", req.Kind.Kind, err)
+		}
+		return nil
+	}
+
+	switch req.Kind.Kind {
+	case "Pod":
+		var o corev1.Pod
+		if err := decode(&o); err != nil {
+			return nil, err
+		}
+		spec = &o.Spec
+	case "Deployment":
+		var o appsv1.Deployment
+		if err := decode(&o); err != nil {
+			return nil, err
+		}
+		spec = &o.Spec.Template.Spec
+	case "ReplicaSet":
+		var o appsv1.ReplicaSet
+		if err := decode(&o); err != nil {
+			return nil, err
+		}
+		spec = &o.Spec.Template.Spec
+	case "StatefulSet":
+		var o appsv1.StatefulSet
+		if err := decode(&o); err != nil {
+			return nil, err
+		}
+		spec = &o.Spec.Template.Spec
+	case "DaemonSet":
+		var o appsv1.DaemonSet
+		if err := decode(&o); err != nil {
+			return nil, err
+		}
+		spec = &o.Spec.Template.Spec
+	case "Job":
+		var o batchv1.Job
+		if err := decode(&o); err != nil {
+			return nil, err
+		}
+		spec = &o.Spec.Template.Spec
+	case "CronJob":
+		var o batchv1.CronJob
+		if err := decode(&o); err != nil {
+			return nil, err
+		}
+		spec = &o.Spec.JobTemplate.Spec.Template.Spec
+	default:
+		log.Debugf("Admitting the unsupported kind %q", req.Kind.Kind)
+		return nil, nil
+	}
+
+	images := []string{}
+	seen := map[string]bool{}
+	add := func(image string) {
+		if image == "" || seen[image] {
+			return
+		}
+		seen[image] = true
+		images = append(images, image)
+	}
+
+	for _, c := range spec.InitContainers {
+		add(c.Image)
+	}
+	for _, c := range spec.Containers {
+		add(c.Image)
+	}
+	for _, c := range spec.EphemeralContainers {
+		add(c.Image)
+	}
+
+	return images, nil
+}
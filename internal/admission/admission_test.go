@@ -0,0 +1,214 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package admission
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	app "github.com/konflux-ci/application-api/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/enterprise-contract/ec-cli/internal/applicationsnapshot"
+	"github.com/enterprise-contract/ec-cli/internal/evaluator"
+)
+
+const podSpec = `{
+	"initContainers": [{"name": "init", "image": "registry.io/init:tag"}],
+	"containers": [
+		{"name": "app", "image": "registry.io/app:tag"},
+		{"name": "sidecar", "image": "registry.io/sidecar:tag"},
+		{"name": "again", "image": "registry.io/app:tag"}
+	]
+}`
+
+func TestImages(t *testing.T) {
+	template := `{"template": {"spec": ` + podSpec + `}}`
+
+	cases := []struct {
+		kind   string
+		object string
+		want   []string
+	}{
+		{kind: "Pod", object: `{"spec": ` + podSpec + `}`},
+		{kind: "Deployment", object: `{"spec": ` + template + `}`},
+		{kind: "ReplicaSet", object: `{"spec": ` + template + `}`},
+		{kind: "StatefulSet", object: `{"spec": ` + template + `}`},
+		{kind: "DaemonSet", object: `{"spec": ` + template + `}`},
+		{kind: "Job", object: `{"spec": ` + template + `}`},
+		{kind: "CronJob", object: `{"spec": {"jobTemplate": {"spec": ` + template + `}}}`},
+		{kind: "ConfigMap", object: `{"data": {"key": "value"}}`, want: []string{}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.kind, func(t *testing.T) {
+			images, err := Images(&admissionv1.AdmissionRequest{
+				Kind:   metav1.GroupVersionKind{Kind: c.kind},
+				Object: runtime.RawExtension{Raw: []byte(c.object)},
+			})
+			require.NoError(t, err)
+
+			want := c.want
+			if want == nil {
+				want = []string{"registry.io/init:tag", "registry.io/app:tag", "registry.io/sidecar:tag"}
+			}
+			assert.ElementsMatch(t, want, images)
+			if len(want) > 0 {
+				assert.Equal(t, want, images)
+			}
+		})
+	}
+
+	_, err := Images(&admissionv1.AdmissionRequest{
+		Kind:   metav1.GroupVersionKind{Kind: "Pod"},
+		Object: runtime.RawExtension{Raw: []byte(`{"spec": []}`)},
+	})
+	assert.ErrorContains(t, err, "unable to decode the Pod")
+}
+
+func TestHandler(t *testing.T) {
+	violation := evaluator.Result{Message: "Not signed", Metadata: map[string]any{"code": "builtin.image.signature_check"}}
+
+	validate := func(_ context.Context, images []string) ([]applicationsnapshot.Component, error) {
+		components := make([]applicationsnapshot.Component, 0, len(images))
+		for _, i := range images {
+			c := applicationsnapshot.Component{SnapshotComponent: app.SnapshotComponent{ContainerImage: i}, Success: true}
+			if i == "registry.io/bad:tag" {
+				c.Success = false
+				c.Violations = []evaluator.Result{violation}
+			}
+			components = append(components, c)
+		}
+		return components, nil
+	}
+
+	failing := func(context.Context, []string) ([]applicationsnapshot.Component, error) {
+		return nil, errors.New("boom")
+	}
+
+	pod := func(image string) string {
+		return `{"spec": {"containers": [{"name": "app", "image": "` + image + `"}]}}`
+	}
+
+	cases := []struct {
+		name      string
+		operation admissionv1.Operation
+		object    string
+		validate  ValidateFunc
+		allowed   bool
+		code      int32
+		message   string
+	}{
+		{
+			name:      "conforming",
+			operation: admissionv1.Create,
+			object:    pod("registry.io/good:tag"),
+			validate:  validate,
+			allowed:   true,
+		},
+		{
+			name:      "violations",
+			operation: admissionv1.Update,
+			object:    pod("registry.io/bad:tag"),
+			validate:  validate,
+			code:      http.StatusForbidden,
+			message:   "Enterprise Contract violations: registry.io/bad:tag: [builtin.image.signature_check] Not signed",
+		},
+		{
+			name:      "delete",
+			operation: admissionv1.Delete,
+			object:    pod("registry.io/bad:tag"),
+			validate:  validate,
+			allowed:   true,
+		},
+		{
+			name:      "validation error",
+			operation: admissionv1.Create,
+			object:    pod("registry.io/good:tag"),
+			validate:  failing,
+			code:      http.StatusInternalServerError,
+			message:   "unable to validate the images: boom",
+		},
+		{
+			name:      "invalid object",
+			operation: admissionv1.Create,
+			object:    `{"spec": []}`,
+			validate:  validate,
+			code:      http.StatusBadRequest,
+			message:   "unable to decode the Pod",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			review := admissionv1.AdmissionReview{
+				TypeMeta: metav1.TypeMeta{APIVersion: "admission.k8s.io/v1", Kind: "AdmissionReview"},
+				Request: &admissionv1.AdmissionRequest{
+					UID:       "uid-1",
+					Kind:      metav1.GroupVersionKind{Version: "v1", Kind: "Pod"},
+					Operation: c.operation,
+					Object:    runtime.RawExtension{Raw: []byte(c.object)},
+				},
+			}
+			body, err := json.Marshal(review)
+			require.NoError(t, err)
+
+			rec := httptest.NewRecorder()
+			Handler{Validate: c.validate}.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/validate", bytes.NewReader(body)))
+			require.Equal(t, http.StatusOK, rec.Code)
+
+			var got admissionv1.AdmissionReview
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+			assert.Equal(t, "AdmissionReview", got.Kind)
+			assert.Nil(t, got.Request)
+			require.NotNil(t, got.Response)
+			assert.Equal(t, review.Request.UID, got.Response.UID)
+			assert.Equal(t, c.allowed, got.Response.Allowed)
+			if !c.allowed {
+				assert.Equal(t, c.code, got.Response.Result.Code)
+				assert.Contains(t, got.Response.Result.Message, c.message)
+			}
+		})
+	}
+}
+
+func TestHandlerBadRequests(t *testing.T) {
+	h := Handler{}
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/validate", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/validate", bytes.NewReader([]byte("nope"))))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/validate", bytes.NewReader([]byte(`{"kind": "AdmissionReview"}`))))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
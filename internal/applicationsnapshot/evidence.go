@@ -0,0 +1,387 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package applicationsnapshot
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/package-url/packageurl-go"
+
+	"github.com/enterprise-contract/ec-cli/internal/attestation"
+	"github.com/enterprise-contract/ec-cli/internal/policy/source"
+)
+
+const predicateCycloneDX = "https://cyclonedx.org/bom"
+
+// evidence is what the verdict of the validation is based on: the images of
+// the components with the attestations consulted, and the policy and data
+// sources. It is written as a CycloneDX or a SPDX document.
+type evidence struct {
+	created time.Time
+	version string
+	images  []evidenceImage
+	sources []source.SourceInfo
+}
+
+type evidenceImage struct {
+	ref          string
+	name         string
+	digest       string
+	purl         string
+	success      bool
+	attestations []evidenceAttestation
+}
+
+type evidenceAttestation struct {
+	predicateType string
+	sha256        string
+	statement     []byte
+}
+
+// isSBOM returns true for attestations holding a SBOM
+func (a evidenceAttestation) isSBOM() bool {
+	return a.predicateType == attestation.PredicateSpdxDocument || a.predicateType == predicateCycloneDX
+}
+
+func (r *Report) evidence() evidence {
+	e := evidence{
+		created: r.created,
+		version: r.EcVersion,
+		sources: r.ResolvedSources,
+	}
+	if e.created.IsZero() {
+		e.created = r.EffectiveTime
+	}
+
+	for _, c := range r.Components {
+		img := evidenceImage{ref: c.ContainerImage, name: c.ContainerImage, success: c.Success}
+		if ref, err := name.ParseReference(c.ContainerImage); err == nil {
+			img.name = ref.Context().Name()
+			if d, ok := ref.(name.Digest); ok {
+				img.digest = d.DigestStr()
+				img.purl = ociPurl(d)
+			}
+		}
+
+		for _, a := range c.Attestations {
+			sum := sha256.Sum256(a.Statement())
+			img.attestations = append(img.attestations, evidenceAttestation{
+				predicateType: a.PredicateType(),
+				sha256:        hex.EncodeToString(sum[:]),
+				statement:     a.Statement(),
+			})
+		}
+
+		e.images = append(e.images, img)
+	}
+
+	return e
+}
+
+// ociPurl returns the package URL of the image, see
+// https://github.com/package-url/purl-spec/blob/master/PURL-TYPES.rst#oci
+func ociPurl(ref name.Digest) string {
+	repository := ref.Context().RepositoryStr()
+	image := repository[strings.LastIndex(repository, "/")+1:]
+
+	return packageurl.NewPackageURL(packageurl.TypeOCI, "", image, ref.DigestStr(), packageurl.Qualifiers{
+		{Key: "repository_url", Value: ref.Context().Name()},
+	}, "").ToString()
+}
+
+// The subset of the CycloneDX 1.5 format, see
+// https://cyclonedx.org/docs/1.5/json/, used for the evidence
+type cyclonedxBOM struct {
+	BOMFormat   string               `json:"bomFormat"`
+	SpecVersion string               `json:"specVersion"`
+	Version     int                  `json:"version"`
+	Metadata    cyclonedxMetadata    `json:"metadata"`
+	Components  []cyclonedxComponent `json:"components"`
+}
+
+type cyclonedxMetadata struct {
+	Timestamp time.Time      `json:"timestamp"`
+	Tools     cyclonedxTools `json:"tools"`
+}
+
+type cyclonedxTools struct {
+	Components []cyclonedxComponent `json:"components"`
+}
+
+type cyclonedxComponent struct {
+	Type               string                 `json:"type"`
+	BOMRef             string                 `json:"bom-ref,omitempty"`
+	Name               string                 `json:"name"`
+	Version            string                 `json:"version,omitempty"`
+	Purl               string                 `json:"purl,omitempty"`
+	Hashes             []cyclonedxHash        `json:"hashes,omitempty"`
+	ExternalReferences []cyclonedxExternalRef `json:"externalReferences,omitempty"`
+	Properties         []cyclonedxProperty    `json:"properties,omitempty"`
+	Data               []cyclonedxData        `json:"data,omitempty"`
+	Components         []cyclonedxComponent   `json:"components,omitempty"`
+}
+
+type cyclonedxHash struct {
+	Alg     string `json:"alg"`
+	Content string `json:"content"`
+}
+
+type cyclonedxExternalRef struct {
+	Type string `json:"type"`
+	URL  string `json:"url"`
+}
+
+type cyclonedxProperty struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type cyclonedxData struct {
+	Type     string               `json:"type"`
+	Name     string               `json:"name"`
+	Contents cyclonedxDataContent `json:"contents"`
+}
+
+type cyclonedxDataContent struct {
+	Attachment cyclonedxAttachment `json:"attachment"`
+}
+
+type cyclonedxAttachment struct {
+	ContentType string `json:"contentType"`
+	Encoding    string `json:"encoding"`
+	Content     string `json:"content"`
+}
+
+// toCycloneDX returns the evidence as a CycloneDX document. The images are
+// listed as container components, holding the attestations as data
+// components, and the policy and data sources are listed as data components.
+// The statements of the attestations holding SBOMs are included.
+func (r *Report) toCycloneDX() ([]byte, error) {
+	e := r.evidence()
+
+	bom := cyclonedxBOM{
+		BOMFormat:   "CycloneDX",
+		SpecVersion: "1.5",
+		Version:     1,
+		Metadata: cyclonedxMetadata{
+			Timestamp: e.created,
+			Tools: cyclonedxTools{
+				Components: []cyclonedxComponent{{Type: "application", Name: "ec", Version: e.version}},
+			},
+		},
+		Components: []cyclonedxComponent{},
+	}
+
+	for _, img := range e.images {
+		c := cyclonedxComponent{
+			Type:       "container",
+			BOMRef:     img.ref,
+			Name:       img.name,
+			Version:    img.digest,
+			Purl:       img.purl,
+			Properties: []cyclonedxProperty{{Name: "ec:success", Value: fmt.Sprint(img.success)}},
+		}
+
+		for i, a := range img.attestations {
+			att := cyclonedxComponent{
+				Type:       "data",
+				BOMRef:     fmt.Sprintf("%s#attestation-%d", img.ref, i),
+				Name:       a.predicateType,
+				Hashes:     []cyclonedxHash{{Alg: "SHA-256", Content: a.sha256}},
+				Properties: []cyclonedxProperty{{Name: "ec:predicateType", Value: a.predicateType}},
+			}
+			if a.isSBOM() {
+				att.Data = []cyclonedxData{{
+					Type: "other",
+					Name: "statement",
+					Contents: cyclonedxDataContent{Attachment: cyclonedxAttachment{
+						ContentType: "application/json",
+						Encoding:    "base64",
+						Content:     base64.StdEncoding.EncodeToString(a.statement),
+					}},
+				}}
+			}
+			c.Components = append(c.Components, att)
+		}
+
+		bom.Components = append(bom.Components, c)
+	}
+
+	for _, s := range e.sources {
+		c := cyclonedxComponent{
+			Type:               "data",
+			BOMRef:             s.Url,
+			Name:               s.Url,
+			ExternalReferences: []cyclonedxExternalRef{{Type: "distribution", URL: s.Url}},
+		}
+		if s.ContentDigest != "" {
+			c.Hashes = append(c.Hashes, cyclonedxHash{Alg: "SHA-256", Content: strings.TrimPrefix(s.ContentDigest, "sha256:")})
+		}
+		if s.Commit != "" {
+			c.Properties = append(c.Properties, cyclonedxProperty{Name: "ec:commit", Value: s.Commit})
+		}
+		if s.Digest != "" {
+			c.Properties = append(c.Properties, cyclonedxProperty{Name: "ec:digest", Value: s.Digest})
+		}
+		bom.Components = append(bom.Components, c)
+	}
+
+	return json.Marshal(bom)
+}
+
+// The subset of the SPDX 2.3 format, see
+// https://spdx.github.io/spdx-spec/v2.3/, used for the evidence
+type spdxDocument struct {
+	SPDXVersion       string             `json:"spdxVersion"`
+	DataLicense       string             `json:"dataLicense"`
+	SPDXID            string             `json:"SPDXID"`
+	Name              string             `json:"name"`
+	DocumentNamespace string             `json:"documentNamespace"`
+	CreationInfo      spdxCreationInfo   `json:"creationInfo"`
+	Packages          []spdxPackage      `json:"packages"`
+	Relationships     []spdxRelationship `json:"relationships"`
+}
+
+type spdxCreationInfo struct {
+	Created  time.Time `json:"created"`
+	Creators []string  `json:"creators"`
+}
+
+type spdxPackage struct {
+	Name             string            `json:"name"`
+	SPDXID           string            `json:"SPDXID"`
+	VersionInfo      string            `json:"versionInfo,omitempty"`
+	DownloadLocation string            `json:"downloadLocation"`
+	FilesAnalyzed    bool              `json:"filesAnalyzed"`
+	PrimaryPurpose   string            `json:"primaryPackagePurpose,omitempty"`
+	Checksums        []spdxChecksum    `json:"checksums,omitempty"`
+	ExternalRefs     []spdxExternalRef `json:"externalRefs,omitempty"`
+	Comment          string            `json:"comment,omitempty"`
+}
+
+type spdxChecksum struct {
+	Algorithm     string `json:"algorithm"`
+	ChecksumValue string `json:"checksumValue"`
+}
+
+type spdxExternalRef struct {
+	ReferenceCategory string `json:"referenceCategory"`
+	ReferenceType     string `json:"referenceType"`
+	ReferenceLocator  string `json:"referenceLocator"`
+}
+
+type spdxRelationship struct {
+	SPDXElementID      string `json:"spdxElementId"`
+	RelationshipType   string `json:"relationshipType"`
+	RelatedSPDXElement string `json:"relatedSpdxElement"`
+	Comment            string `json:"comment,omitempty"`
+}
+
+// toSPDX returns the evidence as a SPDX document. The images, the
+// attestations and the policy and data sources are listed as packages, the
+// document describes the images, and the attestations and sources are related
+// to the images they were consulted for. The document namespace is derived
+// from the content of the document, so the same evidence results in the same
+// namespace.
+func (r *Report) toSPDX() ([]byte, error) {
+	e := r.evidence()
+
+	doc := spdxDocument{
+		SPDXVersion: "SPDX-2.3",
+		DataLicense: "CC0-1.0",
+		SPDXID:      "SPDXRef-DOCUMENT",
+		Name:        "ec-validation-evidence",
+		CreationInfo: spdxCreationInfo{
+			Created:  e.created,
+			Creators: []string{"Tool: ec-" + e.version},
+		},
+		Packages:      []spdxPackage{},
+		Relationships: []spdxRelationship{},
+	}
+
+	var sourceIDs []string
+	for i, s := range e.sources {
+		id := fmt.Sprintf("SPDXRef-Source-%d", i)
+		p := spdxPackage{
+			Name:             s.Url,
+			SPDXID:           id,
+			VersionInfo:      s.Commit,
+			DownloadLocation: s.Url,
+			PrimaryPurpose:   "SOURCE",
+		}
+		if p.VersionInfo == "" {
+			p.VersionInfo = s.Digest
+		}
+		if s.ContentDigest != "" {
+			p.Checksums = []spdxChecksum{{Algorithm: "SHA256", ChecksumValue: strings.TrimPrefix(s.ContentDigest, "sha256:")}}
+		}
+		doc.Packages = append(doc.Packages, p)
+		sourceIDs = append(sourceIDs, id)
+	}
+
+	for i, img := range e.images {
+		id := fmt.Sprintf("SPDXRef-Image-%d", i)
+		p := spdxPackage{
+			Name:             img.name,
+			SPDXID:           id,
+			VersionInfo:      img.digest,
+			DownloadLocation: "NOASSERTION",
+			PrimaryPurpose:   "CONTAINER",
+			Comment:          fmt.Sprintf("ec:success=%t", img.success),
+		}
+		if img.digest != "" {
+			p.Checksums = []spdxChecksum{{Algorithm: "SHA256", ChecksumValue: strings.TrimPrefix(img.digest, "sha256:")}}
+		}
+		if img.purl != "" {
+			p.ExternalRefs = []spdxExternalRef{{ReferenceCategory: "PACKAGE-MANAGER", ReferenceType: "purl", ReferenceLocator: img.purl}}
+		}
+		doc.Packages = append(doc.Packages, p)
+		doc.Relationships = append(doc.Relationships, spdxRelationship{SPDXElementID: doc.SPDXID, RelationshipType: "DESCRIBES", RelatedSPDXElement: id})
+
+		for j, a := range img.attestations {
+			attID := fmt.Sprintf("SPDXRef-Image-%d-Attestation-%d", i, j)
+			doc.Packages = append(doc.Packages, spdxPackage{
+				Name:             a.predicateType,
+				SPDXID:           attID,
+				DownloadLocation: "NOASSERTION",
+				PrimaryPurpose:   "OTHER",
+				Checksums:        []spdxChecksum{{Algorithm: "SHA256", ChecksumValue: a.sha256}},
+			})
+			doc.Relationships = append(doc.Relationships, spdxRelationship{SPDXElementID: id, RelationshipType: "OTHER", RelatedSPDXElement: attID, Comment: "attestation consulted during validation"})
+		}
+
+		for _, sourceID := range sourceIDs {
+			doc.Relationships = append(doc.Relationships, spdxRelationship{SPDXElementID: id, RelationshipType: "OTHER", RelatedSPDXElement: sourceID, Comment: "policy source consulted during validation"})
+		}
+	}
+
+	content, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(content)
+	doc.DocumentNamespace = "https://enterprisecontract.dev/spdx/" + hex.EncodeToString(sum[:])
+
+	return json.Marshal(doc)
+}
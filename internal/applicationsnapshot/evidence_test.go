@@ -0,0 +1,187 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package applicationsnapshot
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/in-toto/in-toto-golang/in_toto"
+	app "github.com/konflux-ci/application-api/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/attestation"
+	"github.com/enterprise-contract/ec-cli/internal/policy/source"
+)
+
+const evidenceDigest = "sha256:4e388ab32b10dc8dbc7e28144f552830adc74787c1e2c0824032078a79f227fb"
+
+func evidenceTestReport() Report {
+	sbom := provenance{
+		statement: in_toto.Statement{StatementHeader: in_toto.StatementHeader{PredicateType: attestation.PredicateSpdxDocument}},
+		data:      []byte(`{"predicateType": "https://spdx.dev/Document"}`),
+	}
+	slsa := provenance{
+		statement: in_toto.Statement{StatementHeader: in_toto.StatementHeader{PredicateType: "https://slsa.dev/provenance/v0.2"}},
+		data:      []byte(`{"predicateType": "https://slsa.dev/provenance/v0.2"}`),
+	}
+
+	return Report{
+		created:   time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		EcVersion: "v0.5",
+		Components: []Component{
+			{
+				SnapshotComponent: app.SnapshotComponent{Name: "spam", ContainerImage: "registry.io/org/spam@" + evidenceDigest},
+				Success:           true,
+				Attestations:      []attestation.Attestation{slsa, sbom},
+			},
+		},
+		ResolvedSources: []source.SourceInfo{
+			{Url: "git::github.com/org/policy//policy", Commit: "abc123", ContentDigest: "sha256:aaaa"},
+		},
+	}
+}
+
+func TestToCycloneDX(t *testing.T) {
+	r := evidenceTestReport()
+
+	data, err := r.toFormat(CycloneDX)
+	require.NoError(t, err)
+
+	sbom := base64.StdEncoding.EncodeToString([]byte(`{"predicateType": "https://spdx.dev/Document"}`))
+	assert.JSONEq(t, `{
+		"bomFormat": "CycloneDX",
+		"specVersion": "1.5",
+		"version": 1,
+		"metadata": {
+			"timestamp": "2024-01-01T00:00:00Z",
+			"tools": {"components": [{"type": "application", "name": "ec", "version": "v0.5"}]}
+		},
+		"components": [
+			{
+				"type": "container",
+				"bom-ref": "registry.io/org/spam@`+evidenceDigest+`",
+				"name": "registry.io/org/spam",
+				"version": "`+evidenceDigest+`",
+				"purl": "pkg:oci/spam@sha256%3A4e388ab32b10dc8dbc7e28144f552830adc74787c1e2c0824032078a79f227fb?repository_url=registry.io%2Forg%2Fspam",
+				"properties": [{"name": "ec:success", "value": "true"}],
+				"components": [
+					{
+						"type": "data",
+						"bom-ref": "registry.io/org/spam@`+evidenceDigest+`#attestation-0",
+						"name": "https://slsa.dev/provenance/v0.2",
+						"hashes": [{"alg": "SHA-256", "content": "20dc63ea23dbbecb87460c33c9975a1472976c8563e140aefe76ac0512a9ed2e"}],
+						"properties": [{"name": "ec:predicateType", "value": "https://slsa.dev/provenance/v0.2"}]
+					},
+					{
+						"type": "data",
+						"bom-ref": "registry.io/org/spam@`+evidenceDigest+`#attestation-1",
+						"name": "https://spdx.dev/Document",
+						"hashes": [{"alg": "SHA-256", "content": "782da1b7c6365748aebcfdf324ac4a059a954e2272225df56c11a2948b3a07b0"}],
+						"properties": [{"name": "ec:predicateType", "value": "https://spdx.dev/Document"}],
+						"data": [{"type": "other", "name": "statement", "contents": {"attachment": {"contentType": "application/json", "encoding": "base64", "content": "`+sbom+`"}}}]
+					}
+				]
+			},
+			{
+				"type": "data",
+				"bom-ref": "git::github.com/org/policy//policy",
+				"name": "git::github.com/org/policy//policy",
+				"hashes": [{"alg": "SHA-256", "content": "aaaa"}],
+				"externalReferences": [{"type": "distribution", "url": "git::github.com/org/policy//policy"}],
+				"properties": [{"name": "ec:commit", "value": "abc123"}]
+			}
+		]
+	}`, string(data))
+}
+
+func TestToSPDX(t *testing.T) {
+	r := evidenceTestReport()
+
+	data, err := r.toFormat(SPDX)
+	require.NoError(t, err)
+
+	var doc map[string]any
+	require.NoError(t, json.Unmarshal(data, &doc))
+	assert.Regexp(t, `^https://enterprisecontract.dev/spdx/[0-9a-f]{64}$`, doc["documentNamespace"])
+	delete(doc, "documentNamespace")
+
+	withoutNamespace, err := json.Marshal(doc)
+	require.NoError(t, err)
+
+	assert.JSONEq(t, `{
+		"spdxVersion": "SPDX-2.3",
+		"dataLicense": "CC0-1.0",
+		"SPDXID": "SPDXRef-DOCUMENT",
+		"name": "ec-validation-evidence",
+		"creationInfo": {"created": "2024-01-01T00:00:00Z", "creators": ["Tool: ec-v0.5"]},
+		"packages": [
+			{
+				"name": "git::github.com/org/policy//policy",
+				"SPDXID": "SPDXRef-Source-0",
+				"versionInfo": "abc123",
+				"downloadLocation": "git::github.com/org/policy//policy",
+				"filesAnalyzed": false,
+				"primaryPackagePurpose": "SOURCE",
+				"checksums": [{"algorithm": "SHA256", "checksumValue": "aaaa"}]
+			},
+			{
+				"name": "registry.io/org/spam",
+				"SPDXID": "SPDXRef-Image-0",
+				"versionInfo": "`+evidenceDigest+`",
+				"downloadLocation": "NOASSERTION",
+				"filesAnalyzed": false,
+				"primaryPackagePurpose": "CONTAINER",
+				"checksums": [{"algorithm": "SHA256", "checksumValue": "4e388ab32b10dc8dbc7e28144f552830adc74787c1e2c0824032078a79f227fb"}],
+				"externalRefs": [{"referenceCategory": "PACKAGE-MANAGER", "referenceType": "purl", "referenceLocator": "pkg:oci/spam@sha256%3A4e388ab32b10dc8dbc7e28144f552830adc74787c1e2c0824032078a79f227fb?repository_url=registry.io%2Forg%2Fspam"}],
+				"comment": "ec:success=true"
+			},
+			{
+				"name": "https://slsa.dev/provenance/v0.2",
+				"SPDXID": "SPDXRef-Image-0-Attestation-0",
+				"downloadLocation": "NOASSERTION",
+				"filesAnalyzed": false,
+				"primaryPackagePurpose": "OTHER",
+				"checksums": [{"algorithm": "SHA256", "checksumValue": "20dc63ea23dbbecb87460c33c9975a1472976c8563e140aefe76ac0512a9ed2e"}]
+			},
+			{
+				"name": "https://spdx.dev/Document",
+				"SPDXID": "SPDXRef-Image-0-Attestation-1",
+				"downloadLocation": "NOASSERTION",
+				"filesAnalyzed": false,
+				"primaryPackagePurpose": "OTHER",
+				"checksums": [{"algorithm": "SHA256", "checksumValue": "782da1b7c6365748aebcfdf324ac4a059a954e2272225df56c11a2948b3a07b0"}]
+			}
+		],
+		"relationships": [
+			{"spdxElementId": "SPDXRef-DOCUMENT", "relationshipType": "DESCRIBES", "relatedSpdxElement": "SPDXRef-Image-0"},
+			{"spdxElementId": "SPDXRef-Image-0", "relationshipType": "OTHER", "relatedSpdxElement": "SPDXRef-Image-0-Attestation-0", "comment": "attestation consulted during validation"},
+			{"spdxElementId": "SPDXRef-Image-0", "relationshipType": "OTHER", "relatedSpdxElement": "SPDXRef-Image-0-Attestation-1", "comment": "attestation consulted during validation"},
+			{"spdxElementId": "SPDXRef-Image-0", "relationshipType": "OTHER", "relatedSpdxElement": "SPDXRef-Source-0", "comment": "policy source consulted during validation"}
+		]
+	}`, string(withoutNamespace))
+
+	// the same evidence results in the same document
+	again, err := r.toFormat(SPDX)
+	require.NoError(t, err)
+	assert.Equal(t, string(data), string(again))
+}
@@ -0,0 +1,91 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package applicationsnapshot
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/spf13/afero"
+)
+
+const (
+	GatePass = "pass"
+	GateFail = "fail"
+)
+
+// GateStatus is a minimal summary of the outcome of the validation, meant to
+// be consumed by simple pipeline steps gating on the outcome. The status is
+// fail exactly when the command exits with a non-zero status.
+type GateStatus struct {
+	Status         string `json:"status"`
+	Reason         string `json:"reason"`
+	ViolationCount int    `json:"violationCount"`
+}
+
+// NewGateStatus returns the gate status given the report, if one was
+// produced, the error the command is exiting with, if any, and whether the
+// validation is strict, i.e. the command fails when the report is not
+// successful.
+func NewGateStatus(report *Report, strict bool, err error) GateStatus {
+	violations := 0
+	failed := 0
+	for _, c := range components(report) {
+		violations += len(c.Violations)
+		if !c.Success {
+			failed++
+		}
+	}
+
+	reason := fmt.Sprintf("%d of %d component(s) failed with %d violation(s)", failed, len(components(report)), violations)
+
+	switch {
+	case report != nil && !report.Success && strict:
+		return GateStatus{Status: GateFail, Reason: reason, ViolationCount: violations}
+	case err != nil:
+		return GateStatus{Status: GateFail, Reason: err.Error(), ViolationCount: violations}
+	case report == nil:
+		return GateStatus{Status: GateFail, Reason: "no report was produced", ViolationCount: violations}
+	case !report.Success:
+		return GateStatus{Status: GatePass, Reason: reason + ", ignored as the validation is not strict", ViolationCount: violations}
+	}
+
+	return GateStatus{
+		Status:         GatePass,
+		Reason:         fmt.Sprintf("%d of %d component(s) passed", len(report.Components), len(report.Components)),
+		ViolationCount: violations,
+	}
+}
+
+func components(report *Report) []Component {
+	if report == nil {
+		return nil
+	}
+
+	return report.Components
+}
+
+// WriteGateStatus writes the gate status in JSON format to the file at the
+// given path
+func WriteGateStatus(fs afero.Fs, path string, status GateStatus) error {
+	data, err := json.Marshal(status)
+	if err != nil {
+		return err
+	}
+
+	return afero.WriteFile(fs, path, append(data, '\n'), 0644)
+}
@@ -0,0 +1,100 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package applicationsnapshot
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/evaluator"
+)
+
+func TestNewGateStatus(t *testing.T) {
+	failing := &Report{
+		Success: false,
+		Components: []Component{
+			{Success: true},
+			{Success: false, Violations: []evaluator.Result{{Message: "one"}, {Message: "two"}}},
+		},
+	}
+	passing := &Report{Success: true, Components: []Component{{Success: true}}}
+
+	cases := []struct {
+		name     string
+		report   *Report
+		strict   bool
+		err      error
+		expected GateStatus
+	}{
+		{
+			name:     "pass",
+			report:   passing,
+			strict:   true,
+			expected: GateStatus{Status: GatePass, Reason: "1 of 1 component(s) passed"},
+		},
+		{
+			name:     "fail",
+			report:   failing,
+			strict:   true,
+			err:      errors.New("success criteria not met"),
+			expected: GateStatus{Status: GateFail, Reason: "1 of 2 component(s) failed with 2 violation(s)", ViolationCount: 2},
+		},
+		{
+			name:     "not strict",
+			report:   failing,
+			expected: GateStatus{Status: GatePass, Reason: "1 of 2 component(s) failed with 2 violation(s), ignored as the validation is not strict", ViolationCount: 2},
+		},
+		{
+			name:     "error without report",
+			strict:   true,
+			err:      errors.New("boom"),
+			expected: GateStatus{Status: GateFail, Reason: "boom"},
+		},
+		{
+			name:     "error with passing report",
+			report:   passing,
+			strict:   true,
+			err:      errors.New("unable to write"),
+			expected: GateStatus{Status: GateFail, Reason: "unable to write"},
+		},
+		{
+			name:     "no report",
+			expected: GateStatus{Status: GateFail, Reason: "no report was produced"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, c.expected, NewGateStatus(c.report, c.strict, c.err))
+		})
+	}
+}
+
+func TestWriteGateStatus(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, WriteGateStatus(fs, "/status.json", GateStatus{Status: GatePass, Reason: "ok"}))
+
+	data, err := afero.ReadFile(fs, "/status.json")
+	require.NoError(t, err)
+	assert.Equal(t, "{\"status\":\"pass\",\"reason\":\"ok\",\"violationCount\":0}\n", string(data))
+}
@@ -0,0 +1,173 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package applicationsnapshot
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/enterprise-contract/ec-cli/internal/evaluator"
+)
+
+// The types of the results of the ResultGroup
+const (
+	violationType = "violation"
+	warningType   = "warning"
+	reviewType    = "review"
+	successType   = "success"
+)
+
+// ResultGroup is a result found identically, i.e. with the same code, term
+// and message, in one or more of the components
+type ResultGroup struct {
+	Type string `json:"type"`
+	evaluator.Result
+	// Components holds the names, or the image references when the component
+	// has no name, of the components with the result
+	Components []string `json:"components"`
+}
+
+type groupKey struct {
+	kind    string
+	code    string
+	term    string
+	message string
+}
+
+// groupResults groups the identical results of all components, the groups of
+// violations first, followed by the warnings, the results of review rules
+// and, with show-successes, the successes. Within each type the groups are in
+// the order the results were first found.
+func (r *Report) groupResults() []ResultGroup {
+	groups := []ResultGroup{}
+	indexes := map[groupKey]int{}
+
+	add := func(kind string, results func(Component) []evaluator.Result) {
+		for _, c := range r.Components {
+			name := c.Name
+			if name == "" {
+				name = c.ContainerImage
+			}
+
+			for _, res := range results(c) {
+				key := groupKey{
+					kind:    kind,
+					code:    evaluator.ExtractStringFromMetadata(res, "code"),
+					term:    evaluator.ExtractStringFromMetadata(res, "term"),
+					message: res.Message,
+				}
+
+				if i, ok := indexes[key]; ok {
+					if last := groups[i].Components; last[len(last)-1] != name {
+						groups[i].Components = append(groups[i].Components, name)
+					}
+					continue
+				}
+
+				indexes[key] = len(groups)
+				groups = append(groups, ResultGroup{Type: kind, Result: res, Components: []string{name}})
+			}
+		}
+	}
+
+	add(violationType, func(c Component) []evaluator.Result { return c.Violations })
+	add(warningType, func(c Component) []evaluator.Result { return c.Warnings })
+	add(reviewType, func(c Component) []evaluator.Result { return c.Reviews })
+	if r.ShowSuccesses {
+		add(successType, func(c Component) []evaluator.Result { return c.Successes })
+	}
+
+	return groups
+}
+
+// grouped returns a copy of the report with the results of the components
+// replaced by the groups of identical results, see groupResults. The report
+// is returned as is when grouping of results is not enabled.
+func (r *Report) grouped() *Report {
+	if !r.GroupResults {
+		return r
+	}
+
+	g := *r
+	g.Groups = r.groupResults()
+	g.Components = make([]Component, 0, len(r.Components))
+	for _, c := range r.Components {
+		c.Violations = nil
+		c.Warnings = nil
+		c.Reviews = nil
+		c.Successes = nil
+		g.Components = append(g.Components, c)
+	}
+
+	return &g
+}
+
+// PackageResults holds the results of a component produced by the rules of
+// a single policy package
+type PackageResults struct {
+	Package string
+	Results []evaluator.Result
+}
+
+// ResultsByPackage returns the results of the given type, i.e. "Violation",
+// "Warning", "Review" or "Success", grouped by the package of the rule that
+// produced them. The package is taken from the code of the result, the groups
+// are ordered by the package name and within a group the results keep their
+// order.
+func (c Component) ResultsByPackage(kind string) []PackageResults {
+	var results []evaluator.Result
+	switch strings.ToLower(kind) {
+	case violationType:
+		results = c.Violations
+	case warningType:
+		results = c.Warnings
+	case reviewType:
+		results = c.Reviews
+	case successType:
+		results = c.Successes
+	}
+
+	groups := []PackageResults{}
+	indexes := map[string]int{}
+	for _, res := range results {
+		pkg := packageOf(evaluator.ExtractStringFromMetadata(res, "code"))
+		i, ok := indexes[pkg]
+		if !ok {
+			i = len(groups)
+			indexes[pkg] = i
+			groups = append(groups, PackageResults{Package: pkg})
+		}
+		groups[i].Results = append(groups[i].Results, res)
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool {
+		return groups[i].Package < groups[j].Package
+	})
+
+	return groups
+}
+
+// packageOf returns the package of the rule with the given code, e.g.
+// "tasks" for "tasks.required_tasks_found", or an empty string if the code
+// holds no package
+func packageOf(code string) string {
+	if i := strings.LastIndex(code, "."); i >= 0 {
+		return code[:i]
+	}
+
+	return ""
+}
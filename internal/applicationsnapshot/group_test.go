@@ -0,0 +1,116 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package applicationsnapshot
+
+import (
+	"testing"
+
+	"github.com/gkampitakis/go-snaps/snaps"
+	app "github.com/konflux-ci/application-api/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/evaluator"
+)
+
+func groupTestReport() Report {
+	unsigned := evaluator.Result{Message: "Not signed", Metadata: map[string]any{"code": "attestation.signed"}}
+	cve := func(id string) evaluator.Result {
+		return evaluator.Result{Message: "CVE found", Metadata: map[string]any{"code": "cve.found", "term": id}}
+	}
+	oldTask := evaluator.Result{Message: "Old task", Metadata: map[string]any{"code": "tasks.current"}}
+	pass := evaluator.Result{Message: "Pass", Metadata: map[string]any{"code": "attestation.signed"}}
+
+	return Report{
+		GroupResults: true,
+		Components: []Component{
+			{
+				SnapshotComponent: app.SnapshotComponent{Name: "frontend", ContainerImage: "registry.io/frontend:tag"},
+				Violations:        []evaluator.Result{unsigned, cve("CVE-1")},
+				Warnings:          []evaluator.Result{oldTask},
+			},
+			{
+				SnapshotComponent: app.SnapshotComponent{ContainerImage: "registry.io/backend:tag"},
+				Violations:        []evaluator.Result{unsigned, cve("CVE-2"), cve("CVE-1")},
+				Warnings:          []evaluator.Result{oldTask},
+			},
+			{
+				SnapshotComponent: app.SnapshotComponent{Name: "database", ContainerImage: "registry.io/database:tag"},
+				Successes:         []evaluator.Result{pass},
+				SuccessCount:      1,
+				Success:           true,
+			},
+		},
+	}
+}
+
+func TestGroupResults(t *testing.T) {
+	r := groupTestReport()
+
+	assert.Equal(t, []ResultGroup{
+		{Type: "violation", Result: r.Components[0].Violations[0], Components: []string{"frontend", "registry.io/backend:tag"}},
+		{Type: "violation", Result: r.Components[0].Violations[1], Components: []string{"frontend", "registry.io/backend:tag"}},
+		{Type: "violation", Result: r.Components[1].Violations[1], Components: []string{"registry.io/backend:tag"}},
+		{Type: "warning", Result: r.Components[0].Warnings[0], Components: []string{"frontend", "registry.io/backend:tag"}},
+	}, r.groupResults())
+
+	r.ShowSuccesses = true
+	groups := r.groupResults()
+	require.Len(t, groups, 5)
+	assert.Equal(t, ResultGroup{Type: "success", Result: r.Components[2].Successes[0], Components: []string{"database"}}, groups[4])
+}
+
+func TestGroupedJSON(t *testing.T) {
+	r := groupTestReport()
+
+	data, err := r.toFormat(JSON)
+	require.NoError(t, err)
+
+	assert.JSONEq(t, `{
+		"success": false,
+		"components": [
+			{"name": "frontend", "containerImage": "registry.io/frontend:tag", "source": {}, "success": false},
+			{"name": "", "containerImage": "registry.io/backend:tag", "source": {}, "success": false},
+			{"name": "database", "containerImage": "registry.io/database:tag", "source": {}, "success": true}
+		],
+		"key": "",
+		"policy": {},
+		"ec-version": "",
+		"effective-time": "0001-01-01T00:00:00Z",
+		"groups": [
+			{"type": "violation", "msg": "Not signed", "metadata": {"code": "attestation.signed"}, "components": ["frontend", "registry.io/backend:tag"]},
+			{"type": "violation", "msg": "CVE found", "metadata": {"code": "cve.found", "term": "CVE-1"}, "components": ["frontend", "registry.io/backend:tag"]},
+			{"type": "violation", "msg": "CVE found", "metadata": {"code": "cve.found", "term": "CVE-2"}, "components": ["registry.io/backend:tag"]},
+			{"type": "warning", "msg": "Old task", "metadata": {"code": "tasks.current"}, "components": ["frontend", "registry.io/backend:tag"]}
+		]
+	}`, string(data))
+
+	// the report itself is not changed
+	assert.Len(t, r.Components[0].Violations, 2)
+	assert.Nil(t, r.Groups)
+}
+
+func TestGroupedTextReport(t *testing.T) {
+	r := groupTestReport()
+
+	output, err := generateTextReport(&r)
+	require.NoError(t, err)
+
+	snaps.MatchSnapshot(t, string(output))
+}
@@ -20,8 +20,11 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
+	"strings"
 
 	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
 	"github.com/hashicorp/go-multierror"
 	app "github.com/konflux-ci/application-api/api/v1alpha1"
 	log "github.com/sirupsen/logrus"
@@ -42,6 +45,14 @@ type Input struct {
 	Image    string
 	Snapshot string
 	Images   string
+	// Stdin is read when Images is "-"
+	Stdin io.Reader
+	// Platforms, e.g. linux/amd64, select the images of image indexes to
+	// validate, all the images are validated when not set
+	Platforms []string
+	// RequireDigest rejects the image references not pinned to a digest,
+	// otherwise the tags are resolved to digests
+	RequireDigest bool
 }
 
 type snapshot struct {
@@ -88,19 +99,30 @@ func DetermineInputSpec(ctx context.Context, input Input) (*app.SnapshotSpec, er
 	if input.Images != "" {
 		var content []byte
 		var err error
-		fs := utils.FS(ctx)
-		content, err = afero.ReadFile(fs, input.Images)
-		if err != nil {
-			log.Debugf("could not read images from file: %v", err)
-			// could not read as file so expecting string
-			content = []byte(input.Images)
+		// only a file, or the standard input, can hold a plain list of image
+		// references, as otherwise a mistyped file name would be validated
+		// as an image reference
+		allowPlainList := true
+		if input.Images == "-" && input.Stdin != nil {
+			if content, err = io.ReadAll(input.Stdin); err != nil {
+				return nil, fmt.Errorf("unable to read images from the standard input: %w", err)
+			}
+		} else {
+			fs := utils.FS(ctx)
+			content, err = afero.ReadFile(fs, input.Images)
+			if err != nil {
+				log.Debugf("could not read images from file: %v", err)
+				// could not read as file so expecting string
+				content = []byte(input.Images)
+				allowPlainList = false
+			}
 		}
 
-		file, err := readSnapshotSource(content)
+		images, err := readImagesSource(content, allowPlainList)
 		if err != nil {
 			return nil, err
 		}
-		snapshot.merge(file)
+		snapshot.merge(images)
 		provided = true
 	}
 
@@ -164,7 +186,25 @@ func DetermineInputSpec(ctx context.Context, input Input) (*app.SnapshotSpec, er
 		log.Debug("No application snapshot available")
 		return nil, errors.New("neither Snapshot nor image reference provided to validate")
 	}
-	expandImageIndex(ctx, &snapshot.SnapshotSpec)
+
+	if input.RequireDigest {
+		if err := requireDigest(snapshot.Components); err != nil {
+			return nil, err
+		}
+	}
+
+	platforms := make([]v1.Platform, 0, len(input.Platforms))
+	for _, p := range input.Platforms {
+		platform, err := v1.ParsePlatform(p)
+		if err != nil || platform.OS == "" {
+			return nil, fmt.Errorf("invalid platform %q, expecting os[/arch[/variant]][:osversion], e.g. linux/amd64", p)
+		}
+		platforms = append(platforms, *platform)
+	}
+
+	if err := expandImageIndex(ctx, &snapshot.SnapshotSpec, platforms); err != nil {
+		return nil, err
+	}
 
 	return &snapshot.SnapshotSpec, nil
 }
@@ -181,7 +221,80 @@ func readSnapshotSource(input []byte) (app.SnapshotSpec, error) {
 	return file, nil
 }
 
-func expandImageIndex(ctx context.Context, snap *app.SnapshotSpec) {
+// readImagesSource reads the images to validate given as a Snapshot
+// specification, as a JSON or YAML list of image references or, when
+// allowPlainList is set, as image references one per line. Empty lines and
+// lines starting with "#" are ignored in the latter.
+func readImagesSource(input []byte, allowPlainList bool) (app.SnapshotSpec, error) {
+	spec, specErr := readSnapshotSource(input)
+	if specErr == nil {
+		return spec, nil
+	}
+
+	var refs []string
+	if err := yaml.Unmarshal(input, &refs); err == nil {
+		return imagesSnapshot(refs)
+	}
+
+	if !allowPlainList {
+		return app.SnapshotSpec{}, specErr
+	}
+
+	refs = []string{}
+	for _, line := range strings.Split(string(input), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		refs = append(refs, line)
+	}
+
+	snap, err := imagesSnapshot(refs)
+	if err != nil {
+		return app.SnapshotSpec{}, multierror.Append(specErr, err)
+	}
+
+	return snap, nil
+}
+
+// imagesSnapshot returns the Snapshot specification with a component for
+// each of the image references
+func imagesSnapshot(refs []string) (app.SnapshotSpec, error) {
+	var snap app.SnapshotSpec
+	for _, ref := range refs {
+		if _, err := name.ParseReference(ref); err != nil {
+			return app.SnapshotSpec{}, fmt.Errorf("unable to parse the image reference %q: %w", ref, err)
+		}
+		snap.Components = append(snap.Components, app.SnapshotComponent{
+			Name:           unnamed,
+			ContainerImage: ref,
+		})
+	}
+
+	return snap, nil
+}
+
+// requireDigest returns an error listing the image references of the
+// components not pinned to a digest
+func requireDigest(components []app.SnapshotComponent) error {
+	var errs error
+	for _, c := range components {
+		if _, err := name.NewDigest(c.ContainerImage); err != nil {
+			errs = multierror.Append(errs, fmt.Errorf("the image reference %q is not pinned to a digest, expecting e.g. %s@sha256:...", c.ContainerImage, strings.Split(c.ContainerImage, "@")[0]))
+		}
+	}
+
+	return errs
+}
+
+// expandImageIndex replaces the components referencing an image index with a
+// component for each of the images in the index, so that each platform is
+// validated and reported on. When platforms are given only the images of the
+// matching platforms are kept, and an error is returned when an index holds
+// none of them. The tags are resolved to digests up front, the references of
+// the components become repository:tag@digest so that each component is
+// validated by digest while the tag it was resolved from is retained.
+func expandImageIndex(ctx context.Context, snap *app.SnapshotSpec, platforms []v1.Platform) error {
 	client := oci.NewClient(ctx)
 	// For an image index, remove the original component and replace it with an expanded component with all its image manifests
 	var components []app.SnapshotComponent
@@ -203,7 +316,17 @@ func expandImageIndex(ctx context.Context, snap *app.SnapshotSpec) {
 			continue
 		}
 
+		// the tag, if any, is kept in front of the digest to be reported
+		repository := ref.Context().Name()
+		if tag, ok := ref.(name.Tag); ok {
+			repository = fmt.Sprintf("%s:%s", repository, tag.TagStr())
+		}
+
 		if !desc.MediaType.IsIndex() {
+			if _, ok := ref.(name.Tag); ok && desc.Digest.Hex != "" {
+				components[len(components)-1].ContainerImage = fmt.Sprintf("%s@%s", repository, desc.Digest)
+				log.Debugf("Resolved the image %s to %s", component.ContainerImage, components[len(components)-1].ContainerImage)
+			}
 			continue
 		}
 
@@ -221,7 +344,14 @@ func expandImageIndex(ctx context.Context, snap *app.SnapshotSpec) {
 
 		// The image is an image index and accessible so remove the image index itself and add index manifests
 		components = components[:len(components)-1]
+		selected := 0
 		for i, manifest := range indexManifest.Manifests {
+			if !matchesPlatforms(manifest.Platform, platforms) {
+				log.Debugf("Skipping the image %s of the image index %s, not matching the platforms %s", manifest.Digest, component.ContainerImage, platformsString(platforms))
+				continue
+			}
+			selected++
+
 			var arch string
 			if manifest.Platform != nil && manifest.Platform.Architecture != "" {
 				arch = manifest.Platform.Architecture
@@ -230,9 +360,13 @@ func expandImageIndex(ctx context.Context, snap *app.SnapshotSpec) {
 			}
 			archComponent := component
 			archComponent.Name = fmt.Sprintf("%s-%s-%s", component.Name, manifest.Digest, arch)
-			archComponent.ContainerImage = fmt.Sprintf("%s@%s", ref.Context().Name(), manifest.Digest)
+			archComponent.ContainerImage = fmt.Sprintf("%s@%s", repository, manifest.Digest)
 			components = append(components, archComponent)
 		}
+
+		if len(platforms) > 0 && selected == 0 {
+			return fmt.Errorf("none of the images of the image index %s matches the platforms %s", component.ContainerImage, platformsString(platforms))
+		}
 	}
 
 	snap.Components = components
@@ -241,4 +375,35 @@ func expandImageIndex(ctx context.Context, snap *app.SnapshotSpec) {
 		log.Warnf("Encountered error while checking for Image Index: %v", allErrors)
 	}
 	log.Debugf("Snap component after expanding the image index is %v", snap.Components)
+
+	return nil
+}
+
+// matchesPlatforms returns true if no platforms are given or if the platform
+// satisfies any of them
+func matchesPlatforms(platform *v1.Platform, platforms []v1.Platform) bool {
+	if len(platforms) == 0 {
+		return true
+	}
+
+	if platform == nil {
+		return false
+	}
+
+	for _, p := range platforms {
+		if platform.Satisfies(p) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func platformsString(platforms []v1.Platform) string {
+	s := make([]string, 0, len(platforms))
+	for _, p := range platforms {
+		s = append(s, p.String())
+	}
+
+	return strings.Join(s, ", ")
 }
@@ -36,6 +36,7 @@ import (
 	"github.com/spf13/afero"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 
 	"github.com/enterprise-contract/ec-cli/internal/kubernetes"
 	"github.com/enterprise-contract/ec-cli/internal/policy"
@@ -187,6 +188,94 @@ func Test_DetermineInputSpec(t *testing.T) {
 	}
 }
 
+func TestDetermineInputSpecImagesList(t *testing.T) {
+	components := []app.SnapshotComponent{
+		{Name: "Unnamed", ContainerImage: "registry.io/repository/image:one"},
+		{Name: "Unnamed", ContainerImage: "registry.io/repository/image:two"},
+	}
+
+	cases := []struct {
+		name    string
+		images  string
+		file    string
+		stdin   string
+		want    *app.SnapshotSpec
+		wantErr string
+	}{
+		{
+			name:   "plain list file",
+			images: "/images.txt",
+			file:   "# the images\nregistry.io/repository/image:one\n\n  registry.io/repository/image:two\n",
+			want:   &app.SnapshotSpec{Components: components},
+		},
+		{
+			name:   "JSON list file",
+			images: "/images.json",
+			file:   `["registry.io/repository/image:one", "registry.io/repository/image:two"]`,
+			want:   &app.SnapshotSpec{Components: components},
+		},
+		{
+			name:   "YAML list file",
+			images: "/images.yaml",
+			file:   "- registry.io/repository/image:one\n- registry.io/repository/image:two\n",
+			want:   &app.SnapshotSpec{Components: components},
+		},
+		{
+			name:   "inline JSON list",
+			images: `["registry.io/repository/image:one", "registry.io/repository/image:two"]`,
+			want:   &app.SnapshotSpec{Components: components},
+		},
+		{
+			name:   "standard input",
+			images: "-",
+			stdin:  "registry.io/repository/image:one\nregistry.io/repository/image:two\n",
+			want:   &app.SnapshotSpec{Components: components},
+		},
+		{
+			name:   "duplicates",
+			images: "-",
+			stdin:  "registry.io/repository/image:one\nregistry.io/repository/image:two\nregistry.io/repository/image:one\n",
+			want:   &app.SnapshotSpec{Components: components},
+		},
+		{
+			name:    "invalid reference",
+			images:  "/images.txt",
+			file:    "registry.io/repository/image:one\nnot a reference\n",
+			wantErr: `unable to parse the image reference "not a reference"`,
+		},
+		{
+			name:    "inline plain reference",
+			images:  "images.txt",
+			wantErr: "unable to parse Snapshot specification from images.txt",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			ctx := utils.WithFS(context.Background(), fs)
+
+			client := fake.FakeClient{}
+			client.On("Head", mock.Anything).Return(&v1.Descriptor{MediaType: types.OCIManifestSchema1}, nil)
+			ctx = oci.WithClient(ctx, &client)
+
+			if c.file != "" {
+				if err := afero.WriteFile(fs, c.images, []byte(c.file), 0400); err != nil {
+					panic(err)
+				}
+			}
+
+			got, err := DetermineInputSpec(ctx, Input{Images: c.images, Stdin: strings.NewReader(c.stdin)})
+			if c.wantErr != "" {
+				assert.ErrorContains(t, err, c.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, c.want, got)
+		})
+	}
+}
+
 func TestReadSnapshotFile(t *testing.T) {
 	t.Run("Successful file read and unmarshal", func(t *testing.T) {
 		snapshotSpec := app.SnapshotSpec{
@@ -275,7 +364,7 @@ func TestExpandImageIndex(t *testing.T) {
 		},
 	}
 
-	expandImageIndex(ctx, snap)
+	assert.NoError(t, expandImageIndex(ctx, snap, nil))
 	assert.True(t, len(snap.Components) == 3, "Image Index itself should be removed and be replaced by individual image manifests")
 
 	amd64Image, arm64Image, noarchImage := false, false, false
@@ -295,6 +384,152 @@ func TestExpandImageIndex(t *testing.T) {
 	assert.True(t, noarchImage, "A noarch image should be present in the component")
 }
 
+func TestExpandImageIndexPlatforms(t *testing.T) {
+	client := fake.FakeClient{}
+	expectedRef := name.MustParseReference("registry.io/repository/image:tag")
+	client.On("Head", expectedRef).Return(&v1.Descriptor{MediaType: types.OCIImageIndex}, nil)
+
+	index := gcrfake.FakeImageIndex{}
+	index.IndexManifestReturns(&v1.IndexManifest{
+		Manifests: []v1.Descriptor{
+			{
+				MediaType: types.OCIManifestSchema1,
+				Platform:  &v1.Platform{OS: "linux", Architecture: "amd64"},
+				Digest:    v1.Hash{Algorithm: "sha256", Hex: "digest1"},
+			},
+			{
+				MediaType: types.OCIManifestSchema1,
+				Platform:  &v1.Platform{OS: "linux", Architecture: "arm64", Variant: "v8"},
+				Digest:    v1.Hash{Algorithm: "sha256", Hex: "digest2"},
+			},
+			{
+				MediaType: types.OCIManifestSchema1,
+				Platform:  &v1.Platform{OS: "linux", Architecture: "s390x"},
+				Digest:    v1.Hash{Algorithm: "sha256", Hex: "digest3"},
+			},
+			{
+				MediaType: types.OCIManifestSchema1,
+				Digest:    v1.Hash{Algorithm: "sha256", Hex: "digest4"},
+			},
+		},
+	}, nil)
+
+	client.On("Index", expectedRef).Return(&index, nil)
+
+	ctx := oci.WithClient(context.Background(), &client)
+
+	cases := []struct {
+		name      string
+		platforms []string
+		images    []string
+		err       string
+	}{
+		{
+			name: "all",
+			images: []string{
+				"registry.io/repository/image:tag@sha256:digest1",
+				"registry.io/repository/image:tag@sha256:digest2",
+				"registry.io/repository/image:tag@sha256:digest3",
+				"registry.io/repository/image:tag@sha256:digest4",
+			},
+		},
+		{
+			name:      "selected",
+			platforms: []string{"linux/amd64", "linux/arm64"},
+			images: []string{
+				"registry.io/repository/image:tag@sha256:digest1",
+				"registry.io/repository/image:tag@sha256:digest2",
+			},
+		},
+		{
+			name:      "variant",
+			platforms: []string{"linux/arm64/v8"},
+			images:    []string{"registry.io/repository/image:tag@sha256:digest2"},
+		},
+		{
+			name:      "none matching",
+			platforms: []string{"linux/ppc64le", "windows/amd64"},
+			err:       "none of the images of the image index registry.io/repository/image:tag matches the platforms linux/ppc64le, windows/amd64",
+		},
+		{
+			name:      "invalid platform",
+			platforms: []string{"linux/arm64/v8/extra"},
+			err:       `invalid platform "linux/arm64/v8/extra", expecting os[/arch[/variant]][:osversion], e.g. linux/amd64`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			snap, err := DetermineInputSpec(ctx, Input{Image: "registry.io/repository/image:tag", Platforms: c.platforms})
+			if c.err != "" {
+				assert.EqualError(t, err, c.err)
+				return
+			}
+			assert.NoError(t, err)
+
+			images := make([]string, 0, len(snap.Components))
+			for _, c := range snap.Components {
+				images = append(images, c.ContainerImage)
+			}
+			assert.Equal(t, c.images, images)
+		})
+	}
+}
+
+func TestResolveTags(t *testing.T) {
+	digest := v1.Hash{Algorithm: "sha256", Hex: "4e388ab32b10dc8dbc7e28144f552830adc74787c1e2c0824032078a79f227fb"}
+
+	client := fake.FakeClient{}
+	client.On("Head", mock.Anything).Return(&v1.Descriptor{MediaType: types.OCIManifestSchema1, Digest: digest}, nil)
+
+	ctx := oci.WithClient(context.Background(), &client)
+
+	cases := []struct {
+		name          string
+		images        string
+		requireDigest bool
+		expected      []string
+		err           string
+	}{
+		{
+			name:     "tag resolved",
+			images:   `["registry.io/repository/image:tag", "registry.io/repository/image@` + digest.String() + `"]`,
+			expected: []string{"registry.io/repository/image:tag@" + digest.String(), "registry.io/repository/image@" + digest.String()},
+		},
+		{
+			name:          "digests required",
+			images:        `["registry.io/repository/image@` + digest.String() + `", "registry.io/repository/image:tag@` + digest.String() + `"]`,
+			requireDigest: true,
+			expected:      []string{"registry.io/repository/image@" + digest.String(), "registry.io/repository/image:tag@" + digest.String()},
+		},
+		{
+			name:          "tag rejected",
+			images:        `["registry.io/repository/image@` + digest.String() + `", "registry.io/repository/image:tag", "registry.io/repository/other"]`,
+			requireDigest: true,
+			err: `2 errors occurred:
+	* the image reference "registry.io/repository/image:tag" is not pinned to a digest, expecting e.g. registry.io/repository/image:tag@sha256:...
+	* the image reference "registry.io/repository/other" is not pinned to a digest, expecting e.g. registry.io/repository/other@sha256:...`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			snap, err := DetermineInputSpec(ctx, Input{Images: c.images, RequireDigest: c.requireDigest})
+			if c.err != "" {
+				assert.EqualError(t, err, c.err+"\n\n")
+				return
+			}
+			require.NoError(t, err)
+
+			images := make([]string, 0, len(snap.Components))
+			for _, c := range snap.Components {
+				images = append(images, c.ContainerImage)
+			}
+			assert.Equal(t, c.expected, images)
+		})
+	}
+}
+
 func TestExpandImageImage_Errors(t *testing.T) {
 	imagePullspec := "registry.io/repository/image:tag"
 	expectedRef, _ := name.ParseReference(imagePullspec)
@@ -360,7 +595,7 @@ func TestExpandImageImage_Errors(t *testing.T) {
 					},
 				},
 			}
-			expandImageIndex(ctx, snapshot)
+			expandImageIndex(ctx, snapshot, nil)
 
 			found := false
 			for _, entry := range hook.AllEntries() {
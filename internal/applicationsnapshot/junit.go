@@ -133,6 +133,18 @@ func (r *Report) toJUnit() junit.Testsuites {
 			return c
 		})
 
+		// the results of review rules neither pass nor fail, the outcome is to
+		// be reviewed manually
+		mapResults(&suite, component.Reviews, func(r evaluator.Result) junit.Testcase {
+			c := asTestCase(r)
+			c.Skipped = &junit.Result{
+				Message: "Review required: " + r.Message,
+				Data:    r.Message,
+			}
+
+			return c
+		})
+
 		report.AddSuite(suite)
 	}
 
@@ -137,6 +137,14 @@ func TestToJunit(t *testing.T) {
 								},
 							},
 						},
+						Reviews: []evaluator.Result{
+							{
+								Message: "review",
+								Metadata: map[string]interface{}{
+									"code": "review",
+								},
+							},
+						},
 						Successes: []evaluator.Result{
 							{
 								Message: "success",
@@ -152,16 +160,16 @@ func TestToJunit(t *testing.T) {
 				Success: true,
 			},
 			expected: junit.Testsuites{
-				Tests:    3,
+				Tests:    4,
 				Failures: 1,
-				Skipped:  1,
+				Skipped:  2,
 				Suites: []junit.Testsuite{
 					{
 						Name:      "Name (registry.io/repository/image:tag)",
 						Timestamp: "0001-01-01T00:00:00Z",
-						Tests:     3,
+						Tests:     4,
 						Failures:  1,
-						Skipped:   1,
+						Skipped:   2,
 						Properties: &[]junit.Property{
 							{
 								Name:  "image",
@@ -225,6 +233,14 @@ func TestToJunit(t *testing.T) {
 									Data:    "warning",
 								},
 							},
+							{
+								Name:      "review: review",
+								Classname: "review: review",
+								Skipped: &junit.Result{
+									Message: "Review required: review",
+									Data:    "review",
+								},
+							},
 						},
 					},
 				},
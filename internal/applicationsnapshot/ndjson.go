@@ -0,0 +1,91 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package applicationsnapshot
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+
+	ecc "github.com/enterprise-contract/enterprise-contract-controller/api/v1alpha1"
+)
+
+// The types of the records written by the StreamWriter
+const (
+	componentRecord = "component"
+	reportRecord    = "report"
+)
+
+type componentStreamRecord struct {
+	Type string `json:"type"`
+	Component
+}
+
+type reportStreamRecord struct {
+	Type           string                           `json:"type"`
+	Success        bool                             `json:"success"`
+	Snapshot       string                           `json:"snapshot,omitempty"`
+	Key            string                           `json:"key"`
+	Policy         ecc.EnterpriseContractPolicySpec `json:"policy"`
+	EcVersion      string                           `json:"ec-version"`
+	EffectiveTime  time.Time                        `json:"effective-time"`
+	ReviewRequired bool                             `json:"review-required,omitempty"`
+	Components     int                              `json:"components"`
+}
+
+// StreamWriter writes the results of the validation as newline delimited
+// JSON, one record per component written as soon as the component is
+// validated, followed by a record with the outcome of the whole validation.
+// Each record holds the type of the record, either "component" or "report".
+type StreamWriter struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+// NewStreamWriter returns a StreamWriter writing to the given writer
+func NewStreamWriter(w io.Writer) *StreamWriter {
+	return &StreamWriter{enc: json.NewEncoder(w)}
+}
+
+// WriteComponent writes the record of a validated component, it is safe to
+// call from multiple goroutines
+func (s *StreamWriter) WriteComponent(c Component) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.enc.Encode(componentStreamRecord{Type: componentRecord, Component: c})
+}
+
+// WriteReport writes the final record with the outcome of the validation,
+// the components are not repeated only their number is written
+func (s *StreamWriter) WriteReport(r Report) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.enc.Encode(reportStreamRecord{
+		Type:           reportRecord,
+		Success:        r.Success,
+		Snapshot:       r.Snapshot,
+		Key:            r.Key,
+		Policy:         r.Policy,
+		EcVersion:      r.EcVersion,
+		EffectiveTime:  r.EffectiveTime,
+		ReviewRequired: r.ReviewRequired,
+		Components:     len(r.Components),
+	})
+}
@@ -0,0 +1,60 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package applicationsnapshot
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	app "github.com/konflux-ci/application-api/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/evaluator"
+)
+
+func TestStreamWriter(t *testing.T) {
+	var buf bytes.Buffer
+	s := NewStreamWriter(&buf)
+
+	spam := Component{
+		SnapshotComponent: app.SnapshotComponent{Name: "spam", ContainerImage: "registry.io/spam:tag"},
+		Violations:        []evaluator.Result{{Message: "Bad", Metadata: map[string]any{"code": "bad.thing"}}},
+	}
+	bacon := Component{
+		SnapshotComponent: app.SnapshotComponent{Name: "bacon", ContainerImage: "registry.io/bacon:tag"},
+		Success:           true,
+	}
+
+	require.NoError(t, s.WriteComponent(spam))
+	require.NoError(t, s.WriteComponent(bacon))
+	require.NoError(t, s.WriteReport(Report{
+		Components:    []Component{spam, bacon},
+		EffectiveTime: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		EcVersion:     "v1",
+	}))
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	require.Len(t, lines, 3)
+	assert.JSONEq(t, `{"type": "component", "name": "spam", "containerImage": "registry.io/spam:tag", "source": {}, "success": false, "violations": [{"msg": "Bad", "metadata": {"code": "bad.thing"}}]}`, lines[0])
+	assert.JSONEq(t, `{"type": "component", "name": "bacon", "containerImage": "registry.io/bacon:tag", "source": {}, "success": true}`, lines[1])
+	assert.JSONEq(t, `{"type": "report", "success": false, "key": "", "policy": {}, "ec-version": "v1", "effective-time": "2024-01-01T00:00:00Z", "components": 2}`, lines[2])
+}
@@ -18,10 +18,14 @@ package applicationsnapshot
 
 import (
 	"bytes"
+	"crypto/sha256"
 	"embed"
+	"encoding/hex"
 	"encoding/json"
 	"encoding/xml"
 	"fmt"
+	"sort"
+	"text/tabwriter"
 	"time"
 
 	ecc "github.com/enterprise-contract/enterprise-contract-controller/api/v1alpha1"
@@ -33,6 +37,7 @@ import (
 	"github.com/enterprise-contract/ec-cli/internal/evaluator"
 	"github.com/enterprise-contract/ec-cli/internal/format"
 	"github.com/enterprise-contract/ec-cli/internal/policy"
+	"github.com/enterprise-contract/ec-cli/internal/policy/source"
 	"github.com/enterprise-contract/ec-cli/internal/signature"
 	"github.com/enterprise-contract/ec-cli/internal/utils"
 	"github.com/enterprise-contract/ec-cli/internal/version"
@@ -40,8 +45,11 @@ import (
 
 type Component struct {
 	app.SnapshotComponent
+	Platform     string                      `json:"platform,omitempty"`
+	ResolvedFrom string                      `json:"resolvedFrom,omitempty"`
 	Violations   []evaluator.Result          `json:"violations,omitempty"`
 	Warnings     []evaluator.Result          `json:"warnings,omitempty"`
+	Reviews      []evaluator.Result          `json:"reviews,omitempty"`
 	Successes    []evaluator.Result          `json:"successes,omitempty"`
 	Success      bool                        `json:"success"`
 	SuccessCount int                         `json:"-"`
@@ -61,6 +69,36 @@ type Report struct {
 	EffectiveTime time.Time                        `json:"effective-time"`
 	PolicyInput   [][]byte                         `json:"-"`
 	ShowSuccesses bool                             `json:"-"`
+
+	// ReviewRequired is set when any of the components has results from
+	// review rules, i.e. the outcome needs to be reviewed manually
+	ReviewRequired bool `json:"review-required,omitempty"`
+
+	// SourceMirrors holds the URLs of the policy and data sources that were
+	// downloaded from a mirror, and the URL of the mirror used for each
+	SourceMirrors map[string]string `json:"source-mirrors,omitempty"`
+
+	// ResolvedSources holds the commit, image digest and content digest of
+	// each of the policy and data sources downloaded for the validation
+	ResolvedSources []source.SourceInfo `json:"resolved-sources,omitempty"`
+
+	// PolicyLineage holds the references of the policies the policy extends,
+	// the policy extended directly first
+	PolicyLineage []string `json:"policy-lineage,omitempty"`
+
+	// GroupResults enables the grouped view of the results, where identical
+	// results of multiple components are reported once, see Groups
+	GroupResults bool `json:"-"`
+
+	// Groups holds the results of all components, grouped, when GroupResults
+	// is set
+	Groups []ResultGroup `json:"groups,omitempty"`
+
+	// ReportDigest holds the sha256 digest of the canonical JSON of the
+	// report, i.e. the report without the grouping of results, the effective
+	// time, which defaults to the time of the validation, and the digest
+	// itself, to compare the reports of two validations
+	ReportDigest string `json:"report-digest,omitempty"`
 }
 
 type summary struct {
@@ -75,9 +113,11 @@ type componentSummary struct {
 	Success         bool                `json:"success"`
 	Violations      map[string][]string `json:"violations"`
 	Warnings        map[string][]string `json:"warnings"`
+	Reviews         map[string][]string `json:"reviews,omitempty"`
 	Successes       map[string][]string `json:"successes"`
 	TotalViolations int                 `json:"total_violations"`
 	TotalWarnings   int                 `json:"total_warnings"`
+	TotalReviews    int                 `json:"total_reviews,omitempty"`
 	TotalSuccesses  int                 `json:"total_successes"`
 }
 
@@ -103,11 +143,18 @@ const (
 	AppStudio       = "appstudio"
 	Summary         = "summary"
 	SummaryMarkdown = "summary-markdown"
+	SummaryTable    = "summary-table"
+	Markdown        = "markdown"
+	HTML            = "html"
 	JUnit           = "junit"
+	SARIF           = "sarif"
+	Template        = "template"
 	Data            = "data"
 	Attestation     = "attestation"
 	PolicyInput     = "policy-input"
 	VSA             = "vsa"
+	CycloneDX       = "cyclonedx"
+	SPDX            = "spdx"
 	// Deprecated old version of appstudio. Remove some day.
 	HACBS = "hacbs"
 )
@@ -119,23 +166,33 @@ var OutputFormats = []string{
 	AppStudio,
 	Summary,
 	SummaryMarkdown,
+	SummaryTable,
+	Markdown,
+	HTML,
 	JUnit,
+	SARIF,
+	Template,
 	Data,
 	Attestation,
 	PolicyInput,
 	VSA,
+	CycloneDX,
+	SPDX,
 }
 
 // WriteReport returns a new instance of Report representing the state of
 // components from the snapshot.
 func NewReport(snapshot string, components []Component, policy policy.Policy, data any, policyInput [][]byte, showSuccesses bool) (Report, error) {
 	success := true
+	reviewRequired := false
 
 	// Set the report success, remains true if all components are successful
 	for _, component := range components {
 		if !component.Success {
 			success = false
-			break
+		}
+		if len(component.Reviews) > 0 {
+			reviewRequired = true
 		}
 	}
 
@@ -148,18 +205,23 @@ func NewReport(snapshot string, components []Component, policy policy.Policy, da
 
 	info, _ := version.ComputeInfo()
 
+	canonicalOrder(components)
+
 	return Report{
-		Snapshot:      snapshot,
-		Success:       success,
-		Components:    components,
-		created:       time.Now().UTC(),
-		Key:           string(key),
-		Policy:        policy.Spec(),
-		EcVersion:     info.Version,
-		Data:          data,
-		PolicyInput:   policyInput,
-		EffectiveTime: policy.EffectiveTime().UTC(),
-		ShowSuccesses: showSuccesses,
+		Snapshot:        snapshot,
+		Success:         success,
+		Components:      components,
+		created:         time.Now().UTC(),
+		Key:             string(key),
+		Policy:          policy.Spec(),
+		EcVersion:       info.Version,
+		Data:            data,
+		PolicyInput:     policyInput,
+		EffectiveTime:   policy.EffectiveTime().UTC(),
+		ShowSuccesses:   showSuccesses,
+		ReviewRequired:  reviewRequired,
+		ResolvedSources: source.DownloadedSources(policy.Spec()),
+		PolicyLineage:   policy.Lineage(),
 	}, nil
 }
 
@@ -168,6 +230,12 @@ func (r Report) WriteAll(targets []string, p format.TargetParser) (allErrors err
 	if len(targets) == 0 {
 		targets = append(targets, JSON)
 	}
+	digest, err := r.digest()
+	if err != nil {
+		return err
+	}
+	r.ReportDigest = digest
+
 	for _, targetName := range targets {
 		target, err := p.Parse(targetName)
 		if err != nil {
@@ -176,7 +244,12 @@ func (r Report) WriteAll(targets []string, p format.TargetParser) (allErrors err
 		}
 		r.applyOptions(target.Options)
 
-		data, err := r.toFormat(target.Format)
+		var data []byte
+		if target.Format == Template {
+			data, err = r.renderTemplate(target)
+		} else {
+			data, err = r.toFormat(target.Format)
+		}
 		if err != nil {
 			allErrors = multierror.Append(allErrors, err)
 			continue
@@ -193,13 +266,66 @@ func (r Report) WriteAll(targets []string, p format.TargetParser) (allErrors err
 	return
 }
 
+// canonicalOrder sorts the components, by the image reference in descending
+// order and by name, and the results of each component, by code, term and
+// message, so that the validation of the same inputs always produces the same
+// report regardless of the order the components were validated and the
+// results were produced in.
+func canonicalOrder(components []Component) {
+	sort.SliceStable(components, func(i, j int) bool {
+		if components[i].ContainerImage != components[j].ContainerImage {
+			return components[i].ContainerImage > components[j].ContainerImage
+		}
+		return components[i].Name < components[j].Name
+	})
+
+	for _, c := range components {
+		sortResults(c.Violations)
+		sortResults(c.Warnings)
+		sortResults(c.Reviews)
+		sortResults(c.Successes)
+	}
+}
+
+func sortResults(results []evaluator.Result) {
+	sort.SliceStable(results, func(i, j int) bool {
+		a, b := results[i], results[j]
+		if ca, cb := evaluator.ExtractStringFromMetadata(a, "code"), evaluator.ExtractStringFromMetadata(b, "code"); ca != cb {
+			return ca < cb
+		}
+		if ta, tb := evaluator.ExtractStringFromMetadata(a, "term"), evaluator.ExtractStringFromMetadata(b, "term"); ta != tb {
+			return ta < tb
+		}
+		return a.Message < b.Message
+	})
+}
+
+// digest returns the sha256 digest of the canonical JSON of the report, see
+// ReportDigest. The keys of the metadata maps are sorted when encoding to
+// JSON, so with the components and the results in canonical order identical
+// reports have identical digests.
+func (r Report) digest() (string, error) {
+	r.ReportDigest = ""
+	r.EffectiveTime = time.Time{}
+	r.GroupResults = false
+	r.Groups = nil
+
+	data, err := json.Marshal(r)
+	if err != nil {
+		return "", fmt.Errorf("unable to compute the digest of the report: %w", err)
+	}
+	sum := sha256.Sum256(data)
+
+	return "sha256:" + hex.EncodeToString(sum[:]), nil
+}
+
 // toFormat converts the report into the given format.
 func (r *Report) toFormat(format string) (data []byte, err error) {
 	switch format {
 	case JSON:
-		data, err = json.Marshal(r)
+		data, err = json.Marshal(r.grouped())
 	case YAML:
-		data, err = yaml.Marshal(r)
+		data, err = yaml.Marshal(r.grouped())
 	case Text:
 		data, err = generateTextReport(r)
 	case AppStudio, HACBS:
@@ -208,8 +334,16 @@ func (r *Report) toFormat(format string) (data []byte, err error) {
 		data, err = json.Marshal(r.toSummary())
 	case SummaryMarkdown:
 		data, err = generateMarkdownSummary(r)
+	case SummaryTable:
+		data, err = generateSummaryTable(r)
+	case Markdown:
+		data, err = generateMarkdownReport(r)
+	case HTML:
+		data, err = generateHTMLReport(r)
 	case JUnit:
 		data, err = xml.Marshal(r.toJUnit())
+	case SARIF:
+		data, err = r.toSARIF()
 	case Data:
 		data, err = yaml.Marshal(r.Data)
 	case Attestation:
@@ -218,6 +352,10 @@ func (r *Report) toFormat(format string) (data []byte, err error) {
 		data = bytes.Join(r.PolicyInput, []byte("\n"))
 	case VSA:
 		data, err = r.toVSA()
+	case CycloneDX:
+		data, err = r.toCycloneDX()
+	case SPDX:
+		data, err = r.toSPDX()
 	default:
 		return nil, fmt.Errorf("%q is not a valid report format", format)
 	}
@@ -244,6 +382,7 @@ func (r *Report) toSummary() summary {
 		c := componentSummary{
 			TotalViolations: len(cmp.Violations),
 			TotalWarnings:   len(cmp.Warnings),
+			TotalReviews:    len(cmp.Reviews),
 
 			// Because cmp.Successes does not get populated unless the --show-successes
 			// flag was set, cmp.SuccessCount is used here instead of len(cmp.Successes)
@@ -255,6 +394,9 @@ func (r *Report) toSummary() summary {
 			Warnings:   condensedMsg(cmp.Warnings),
 			Successes:  condensedMsg(cmp.Successes),
 		}
+		if len(cmp.Reviews) > 0 {
+			c.Reviews = condensedMsg(cmp.Reviews)
+		}
 		pr.Components = append(pr.Components, c)
 	}
 	pr.Key = r.Key
@@ -263,6 +405,7 @@ func (r *Report) toSummary() summary {
 
 func (r *Report) applyOptions(opts format.Options) {
 	r.ShowSuccesses = opts.ShowSuccesses
+	r.GroupResults = opts.GroupResults
 }
 
 // condensedMsg reduces repetitive error messages.
@@ -317,23 +460,70 @@ func generateMarkdownSummary(r *Report) ([]byte, error) {
 	return markdownBuffer.Bytes(), nil
 }
 
+// generateSummaryTable renders a compact table with the number of
+// violations, warnings and successes and the verdict of each component, e.g.
+// for a quick check in the terminal.
+func generateSummaryTable(r *Report) ([]byte, error) {
+	var buffy bytes.Buffer
+	w := tabwriter.NewWriter(&buffy, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "COMPONENT\tVIOLATIONS\tWARNINGS\tSUCCESSES\tVERDICT")
+	for _, c := range r.toSummary().Components {
+		verdict := "FAIL"
+		if c.Success {
+			verdict = "PASS"
+		}
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%s\n", c.Name, c.TotalViolations, c.TotalWarnings, c.TotalSuccesses, verdict)
+	}
+	if err := w.Flush(); err != nil {
+		return nil, err
+	}
+
+	return buffy.Bytes(), nil
+}
+
 //go:embed templates/*.tmpl
 var efs embed.FS
 
-func generateTextReport(r *Report) ([]byte, error) {
-	// Prepare some template input
-	input := struct {
-		Report     *Report
-		TestReport TestReport
-	}{
-		// This includes everything in the yaml/json output
-		Report: r,
-		// This has useful stuff we want to output, so let's reuse it
-		// even though this is not what it was originally designed for
+// reportTemplateInput is the input of the templates of the text, Markdown
+// and HTML reports
+type reportTemplateInput struct {
+	// This includes everything in the yaml/json output
+	Report *Report
+	// This has useful stuff we want to output, so let's reuse it
+	// even though this is not what it was originally designed for
+	TestReport TestReport
+	// Groups holds the results grouped across the components, when grouping
+	// of results is enabled
+	Groups []ResultGroup
+}
+
+func (r *Report) templateInput() reportTemplateInput {
+	input := reportTemplateInput{
+		Report:     r,
 		TestReport: r.toAppstudioReport(),
 	}
+	if r.GroupResults {
+		input.Groups = r.groupResults()
+	}
+
+	return input
+}
+
+func generateTextReport(r *Report) ([]byte, error) {
+	return utils.RenderFromTemplatesWithMain(r.templateInput(), "text_report.tmpl", efs)
+}
+
+// generateMarkdownReport renders a readable report in Markdown format with a
+// summary table and a section for each component, e.g. to attach to a pull
+// request
+func generateMarkdownReport(r *Report) ([]byte, error) {
+	return utils.RenderFromTemplatesWithMain(r.templateInput(), "markdown_report.tmpl", efs)
+}
 
-	return utils.RenderFromTemplatesWithMain(input, "text_report.tmpl", efs)
+// generateHTMLReport renders a readable report as a HTML page with a summary
+// table and a section for each component, e.g. to publish as a build artifact
+func generateHTMLReport(r *Report) ([]byte, error) {
+	return utils.RenderFromTemplatesWithMain(r.templateInput(), "html_report.tmpl", efs)
 }
 
 func writeMarkdownField(buffer *bytes.Buffer, name string, value any, icon string) {
@@ -20,6 +20,7 @@ package applicationsnapshot
 
 import (
 	"bufio"
+	"bytes"
 	"context"
 	_ "embed"
 	"encoding/json"
@@ -73,19 +74,19 @@ func Test_ReportJson(t *testing.T) {
 		  "successes": [{"msg": "success1"}],
           "success": false
         },
-        {
-          "name": "bacon",
-          "containerImage": "quay.io/caf/bacon@sha256:234…",
-		  "source": {},
-          "violations": [{"msg": "violation2"}],
-          "success": false
-        },
         {
 			"name": "eggs",
 			"containerImage": "quay.io/caf/eggs@sha256:345…",
 			"source": {},
 			"successes": [{"msg": "success3"}],
 			"success": true
+        },
+        {
+          "name": "bacon",
+          "containerImage": "quay.io/caf/bacon@sha256:234…",
+		  "source": {},
+          "violations": [{"msg": "violation2"}],
+          "success": false
         }
       ],
 	  "policy": {
@@ -100,6 +101,106 @@ func Test_ReportJson(t *testing.T) {
 	assert.False(t, report.Success)
 }
 
+func Test_ReportCanonicalOrder(t *testing.T) {
+	results := func(codes ...string) []evaluator.Result {
+		r := make([]evaluator.Result, 0, len(codes))
+		for _, c := range codes {
+			r = append(r, evaluator.Result{Message: "msg", Metadata: map[string]any{"code": c}})
+		}
+		return r
+	}
+
+	components := func(reverse bool) []Component {
+		c := []Component{
+			{
+				SnapshotComponent: app.SnapshotComponent{Name: "a", ContainerImage: "registry.io/a:tag"},
+				Violations:        results("b.rule", "a.rule"),
+			},
+			{
+				SnapshotComponent: app.SnapshotComponent{Name: "b", ContainerImage: "registry.io/b:tag"},
+				Warnings:          results("a.rule", "c.rule", "b.rule"),
+			},
+		}
+		if reverse {
+			c[0], c[1] = c[1], c[0]
+			c[0].Warnings = results("c.rule", "b.rule", "a.rule")
+			c[1].Violations = results("a.rule", "b.rule")
+		}
+		return c
+	}
+
+	ctx := context.Background()
+	testPolicy := createTestPolicy(t, ctx)
+
+	write := func(reverse bool) []byte {
+		r, err := NewReport("snappy", components(reverse), testPolicy, nil, nil, false)
+		require.NoError(t, err)
+
+		var out bytes.Buffer
+		p := format.NewTargetParser(JSON, format.Options{}, &out, afero.NewMemMapFs())
+		require.NoError(t, r.WriteAll([]string{JSON}, p))
+
+		return out.Bytes()
+	}
+
+	first := write(false)
+	assert.Equal(t, first, write(true))
+
+	var report map[string]any
+	require.NoError(t, json.Unmarshal(first, &report))
+	assert.Regexp(t, `^sha256:[a-f0-9]{64}$`, report["report-digest"])
+
+	r, err := NewReport("snappy", components(true), testPolicy, nil, nil, false)
+	require.NoError(t, err)
+	assert.Equal(t, "b", r.Components[0].Name)
+	assert.Equal(t, results("a.rule", "b.rule", "c.rule"), r.Components[0].Warnings)
+	assert.Equal(t, "a", r.Components[1].Name)
+	assert.Equal(t, results("a.rule", "b.rule"), r.Components[1].Violations)
+}
+
+func Test_ReportDigest(t *testing.T) {
+	r := Report{
+		Success:       true,
+		Components:    []Component{{SnapshotComponent: app.SnapshotComponent{Name: "spam"}, Success: true}},
+		EffectiveTime: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	digest, err := r.digest()
+	require.NoError(t, err)
+	assert.Regexp(t, `^sha256:[a-f0-9]{64}$`, digest)
+
+	// the effective time, the grouping and the digest itself are not included
+	other := r
+	other.EffectiveTime = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	other.GroupResults = true
+	other.ReportDigest = "sha256:0000"
+	otherDigest, err := other.digest()
+	require.NoError(t, err)
+	assert.Equal(t, digest, otherDigest)
+
+	other.Success = false
+	otherDigest, err = other.digest()
+	require.NoError(t, err)
+	assert.NotEqual(t, digest, otherDigest)
+}
+
+func Test_ReportReviewRequired(t *testing.T) {
+	ctx := context.Background()
+	testPolicy := createTestPolicy(t, ctx)
+
+	report, err := NewReport("snappy", []Component{{Success: true}}, testPolicy, nil, nil, true)
+	require.NoError(t, err)
+	assert.False(t, report.ReviewRequired)
+
+	report, err = NewReport("snappy", []Component{
+		{Success: true},
+		{Success: true, Reviews: []evaluator.Result{{Message: "review1"}}},
+	}, testPolicy, nil, nil, true)
+	require.NoError(t, err)
+	assert.True(t, report.ReviewRequired)
+	assert.True(t, report.Success)
+}
+
 func Test_ReportYaml(t *testing.T) {
 	var snapshot *app.SnapshotSpec
 	err := json.Unmarshal([]byte(testSnapshot), &snapshot)
@@ -131,18 +232,18 @@ components:
     successes:
       - msg: success1
     success: false
-  - name: bacon
-    containerImage: quay.io/caf/bacon@sha256:234…
-    source: {}
-    violations:
-      - msg: violation2
-    success: false
   - name: eggs
     containerImage: quay.io/caf/eggs@sha256:345…
     source: {}
     successes:
       - msg: success3
     success: true
+  - name: bacon
+    containerImage: quay.io/caf/bacon@sha256:234…
+    source: {}
+    violations:
+      - msg: violation2
+    success: false
 policy:
   publicKey: %s
 `, testEffectiveTime, utils.TestPublicKeyJSON, utils.TestPublicKeyJSON)
@@ -267,6 +368,41 @@ func Test_GenerateMarkdownSummary(t *testing.T) {
 	}
 }
 
+func Test_GenerateSummaryTable(t *testing.T) {
+	components := []Component{
+		{
+			SnapshotComponent: app.SnapshotComponent{Name: "spam"},
+			Violations: []evaluator.Result{
+				{Message: "failure1"},
+				{Message: "failure2"},
+			},
+			Warnings: []evaluator.Result{
+				{Message: "Warning1"},
+			},
+			Success: false,
+		},
+		{
+			SnapshotComponent: app.SnapshotComponent{Name: "a-component-with-a-long-name"},
+			Successes: []evaluator.Result{
+				{Message: "Success1"},
+				{Message: "Success2"},
+			},
+			SuccessCount: 2,
+			Success:      true,
+		},
+	}
+
+	report, err := NewReport("snappy", components, createTestPolicy(t, context.Background()), nil, nil, true)
+	require.NoError(t, err)
+
+	table, err := generateSummaryTable(&report)
+	require.NoError(t, err)
+	assert.Equal(t, `COMPONENT                     VIOLATIONS  WARNINGS  SUCCESSES  VERDICT
+a-component-with-a-long-name  0           0         2          PASS
+spam                          2           1         0          FAIL
+`, string(table))
+}
+
 func Test_ReportSummary(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -883,11 +1019,34 @@ func Test_TextReport(t *testing.T) {
 		},
 	}
 
+	packaged := []evaluator.Result{
+		{
+			Metadata: map[string]interface{}{
+				"code":              "tasks.required",
+				"documentation_url": "https://example.com/docs/tasks.html#required",
+			},
+			Message: "Required task missing",
+		},
+		{
+			Metadata: map[string]interface{}{
+				"code": "attestation.signed",
+			},
+			Message: "Attestation not signed",
+		},
+		{
+			Metadata: map[string]interface{}{
+				"code": "tasks.trusted",
+			},
+			Message: "Untrusted task",
+		},
+	}
+
 	cases := []struct {
 		name   string
 		report Report
+		color  bool
 	}{
-		{"nothing", Report{}},
+		{"nothing", Report{}, false},
 		{"bunch", Report{
 			ShowSuccesses: true,
 			Components: []Component{
@@ -920,11 +1079,101 @@ func Test_TextReport(t *testing.T) {
 					SuccessCount: 2,
 				},
 			},
-		}},
+		}, false},
+		{"review", Report{
+			Success:        true,
+			ReviewRequired: true,
+			Components: []Component{
+				{
+					SnapshotComponent: app.SnapshotComponent{
+						ContainerImage: "registry.io/repository/component-1:tag",
+					},
+					Reviews: []evaluator.Result{
+						{
+							Metadata: map[string]interface{}{
+								"code":  "review-1",
+								"title": "Review 1 title",
+							},
+							Message: "Review 1 message",
+						},
+					},
+					Success:      true,
+					SuccessCount: 1,
+				},
+			},
+		}, false},
+		{"platforms", Report{
+			Success: true,
+			Components: []Component{
+				{
+					SnapshotComponent: app.SnapshotComponent{
+						Name:           "image-sha256:digest1-amd64",
+						ContainerImage: "registry.io/repository/image@sha256:digest1",
+					},
+					Platform:     "linux/amd64",
+					Success:      true,
+					SuccessCount: 1,
+				},
+				{
+					SnapshotComponent: app.SnapshotComponent{
+						Name:           "image-sha256:digest2-arm64",
+						ContainerImage: "registry.io/repository/image@sha256:digest2",
+					},
+					Platform:     "linux/arm64/v8",
+					Success:      true,
+					SuccessCount: 1,
+				},
+			},
+		}, false},
+		{"resolved tags", Report{
+			Success: true,
+			Components: []Component{
+				{
+					SnapshotComponent: app.SnapshotComponent{
+						Name:           "Unnamed",
+						ContainerImage: "registry.io/repository/image@sha256:digest1",
+					},
+					ResolvedFrom: "registry.io/repository/image:tag",
+					Success:      true,
+					SuccessCount: 1,
+				},
+				{
+					SnapshotComponent: app.SnapshotComponent{
+						Name:           "Unnamed",
+						ContainerImage: "registry.io/repository/other@sha256:digest2",
+					},
+					Success:      true,
+					SuccessCount: 1,
+				},
+			},
+		}, false},
+		{"packages", Report{
+			Components: []Component{
+				{
+					SnapshotComponent: app.SnapshotComponent{
+						ContainerImage: "registry.io/repository/component-1:tag",
+					},
+					Violations: packaged,
+				},
+			},
+		}, false},
+		{"packages with color", Report{
+			Components: []Component{
+				{
+					SnapshotComponent: app.SnapshotComponent{
+						ContainerImage: "registry.io/repository/component-1:tag",
+					},
+					Violations: packaged,
+				},
+			},
+		}, true},
 	}
 
 	for _, c := range cases {
 		t.Run(c.name, func(t *testing.T) {
+			utils.ColorEnabled = c.color
+			t.Cleanup(func() { utils.ColorEnabled = false })
+
 			r := c.report
 			output, err := generateTextReport(&r)
 			require.NoError(t, err)
@@ -934,6 +1183,75 @@ func Test_TextReport(t *testing.T) {
 	}
 }
 
+func TestGenerateMarkdownAndHTMLReport(t *testing.T) {
+	r := Report{
+		Success:        false,
+		ReviewRequired: true,
+		ShowSuccesses:  true,
+		EffectiveTime:  time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Components: []Component{
+			{
+				SnapshotComponent: app.SnapshotComponent{
+					Name:           "frontend",
+					ContainerImage: "registry.io/repository/frontend:tag",
+				},
+				Violations: []evaluator.Result{
+					{
+						Message: "Missing <signature>",
+						Metadata: map[string]interface{}{
+							"code":              "attestation.signed",
+							"title":             "Signed",
+							"description":       "The image is signed.",
+							"solution":          "Sign the image.",
+							"severity":          "high",
+							"documentation_url": "https://enterprisecontract.dev/docs/ec-policies/release_policy.html#attestation__signed",
+						},
+					},
+				},
+				Warnings: []evaluator.Result{
+					{
+						Message: "Old task",
+						Metadata: map[string]interface{}{
+							"code":         "tasks.current",
+							"term":         "buildah",
+							"effective_on": "2024-06-01T00:00:00Z",
+						},
+					},
+				},
+				Reviews: []evaluator.Result{
+					{
+						Message:  "Review needed",
+						Metadata: map[string]interface{}{"code": "review.manual"},
+					},
+				},
+			},
+			{
+				SnapshotComponent: app.SnapshotComponent{
+					Name:           "backend",
+					ContainerImage: "registry.io/repository/backend:tag",
+				},
+				Success:      true,
+				SuccessCount: 1,
+				Successes: []evaluator.Result{
+					{
+						Message:  "Pass",
+						Metadata: map[string]interface{}{"code": "attestation.signed", "solution": "Not shown"},
+					},
+				},
+			},
+		},
+	}
+
+	for _, f := range []string{Markdown, HTML} {
+		t.Run(f, func(t *testing.T) {
+			output, err := r.toFormat(f)
+			require.NoError(t, err)
+
+			snaps.MatchSnapshot(t, string(output))
+		})
+	}
+}
+
 func matchesJSONLFile(t *testing.T, fs afero.Fs, expected [][]byte, filename string) {
 	f, err := fs.Open(filename)
 	require.NoError(t, err)
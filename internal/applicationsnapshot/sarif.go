@@ -0,0 +1,231 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package applicationsnapshot
+
+import (
+	"encoding/json"
+	"sort"
+
+	"github.com/enterprise-contract/ec-cli/internal/evaluator"
+)
+
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+)
+
+// The subset of the SARIF 2.1.0 format, see
+// https://docs.oasis-open.org/sarif/sarif/v2.1.0/sarif-v2.1.0.html, used for
+// the report
+type sarifLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Version        string      `json:"version,omitempty"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string          `json:"id"`
+	ShortDescription *sarifMessage   `json:"shortDescription,omitempty"`
+	FullDescription  *sarifMessage   `json:"fullDescription,omitempty"`
+	Help             *sarifMessage   `json:"help,omitempty"`
+	Properties       *sarifRuleProps `json:"properties,omitempty"`
+}
+
+type sarifRuleProps struct {
+	Tags []string `json:"tags,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID     string          `json:"ruleId,omitempty"`
+	RuleIndex  *int            `json:"ruleIndex,omitempty"`
+	Kind       string          `json:"kind,omitempty"`
+	Level      string          `json:"level"`
+	Message    sarifMessage    `json:"message"`
+	Locations  []sarifLocation `json:"locations"`
+	Properties map[string]any  `json:"properties,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation  `json:"physicalLocation"`
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations,omitempty"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifLogicalLocation struct {
+	Name string `json:"name"`
+	Kind string `json:"kind"`
+}
+
+// The levels of the SARIF results of each kind of result
+const (
+	sarifError   = "error"
+	sarifWarning = "warning"
+	sarifNote    = "note"
+	sarifNone    = "none"
+)
+
+// toSARIF returns a version of the report in SARIF 2.1.0 format. The violations
+// are reported as errors, the warnings as warnings, the results of review
+// rules as notes, and with show-successes the successes as passing results.
+// Each result is located at the image of the component, with the rule code as
+// the rule ID and the title, description and solution of the rule as the rule
+// metadata.
+func (r *Report) toSARIF() ([]byte, error) {
+	rules := map[string]sarifRule{}
+	results := []sarifResult{}
+
+	for _, c := range r.Components {
+		location := sarifLocation{
+			PhysicalLocation: sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifactLocation{URI: c.ContainerImage},
+			},
+		}
+		if c.Name != "" {
+			location.LogicalLocations = []sarifLogicalLocation{{Name: c.Name, Kind: "module"}}
+		}
+
+		add := func(level string, kind string, outcomes []evaluator.Result) {
+			for _, o := range outcomes {
+				code, _ := o.Metadata["code"].(string)
+				if code != "" {
+					if _, ok := rules[code]; !ok {
+						rules[code] = sarifRuleFrom(code, o)
+					}
+				}
+
+				result := sarifResult{
+					RuleID:    code,
+					Kind:      kind,
+					Level:     level,
+					Message:   sarifMessage{Text: o.Message},
+					Locations: []sarifLocation{location},
+				}
+
+				if term, ok := o.Metadata["term"]; ok {
+					result.Properties = map[string]any{"term": term}
+				}
+				if effectiveOn, ok := o.Metadata["effective_on"]; ok {
+					if result.Properties == nil {
+						result.Properties = map[string]any{}
+					}
+					result.Properties["effective_on"] = effectiveOn
+				}
+
+				results = append(results, result)
+			}
+		}
+
+		add(sarifError, "", c.Violations)
+		add(sarifWarning, "", c.Warnings)
+		add(sarifNote, "review", c.Reviews)
+		if r.ShowSuccesses {
+			add(sarifNone, "pass", c.Successes)
+		}
+	}
+
+	ids := make([]string, 0, len(rules))
+	for id := range rules {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	indexes := make(map[string]int, len(ids))
+	driver := sarifDriver{
+		Name:           "ec",
+		InformationURI: "https://enterprisecontract.dev",
+		Version:        r.EcVersion,
+		Rules:          make([]sarifRule, 0, len(ids)),
+	}
+	for i, id := range ids {
+		indexes[id] = i
+		driver.Rules = append(driver.Rules, rules[id])
+	}
+
+	for i := range results {
+		if idx, ok := indexes[results[i].RuleID]; ok {
+			results[i].RuleIndex = &idx
+		}
+	}
+
+	return json.Marshal(sarifLog{
+		Version: sarifVersion,
+		Schema:  sarifSchema,
+		Runs: []sarifRun{{
+			Tool:    sarifTool{Driver: driver},
+			Results: results,
+		}},
+	})
+}
+
+// sarifRuleFrom describes the rule using the metadata of its result
+func sarifRuleFrom(code string, result evaluator.Result) sarifRule {
+	rule := sarifRule{ID: code}
+
+	if title, ok := result.Metadata["title"].(string); ok && title != "" {
+		rule.ShortDescription = &sarifMessage{Text: title}
+	}
+	if description, ok := result.Metadata["description"].(string); ok && description != "" {
+		rule.FullDescription = &sarifMessage{Text: description}
+	}
+	if solution, ok := result.Metadata["solution"].(string); ok && solution != "" {
+		rule.Help = &sarifMessage{Text: solution}
+	}
+
+	switch collections := result.Metadata["collections"].(type) {
+	case []string:
+		rule.Properties = &sarifRuleProps{Tags: collections}
+	case []any:
+		tags := make([]string, 0, len(collections))
+		for _, c := range collections {
+			if s, ok := c.(string); ok {
+				tags = append(tags, s)
+			}
+		}
+		if len(tags) > 0 {
+			rule.Properties = &sarifRuleProps{Tags: tags}
+		}
+	}
+
+	return rule
+}
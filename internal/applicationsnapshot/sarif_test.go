@@ -0,0 +1,133 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package applicationsnapshot
+
+import (
+	"testing"
+
+	app "github.com/konflux-ci/application-api/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/evaluator"
+)
+
+func TestToSARIF(t *testing.T) {
+	r := Report{
+		EcVersion: "v0.5.0",
+		Components: []Component{
+			{
+				SnapshotComponent: app.SnapshotComponent{Name: "frontend", ContainerImage: "registry.io/repo/frontend@sha256:abc"},
+				Violations: []evaluator.Result{{
+					Message: "Not signed",
+					Metadata: map[string]any{
+						"code":        "attestation.signed",
+						"title":       "Signed",
+						"description": "The attestation is signed.",
+						"solution":    "Sign the attestation.",
+						"collections": []any{"minimal"},
+					},
+				}},
+				Warnings: []evaluator.Result{{
+					Message:  "Old task",
+					Metadata: map[string]any{"code": "tasks.current", "term": "buildah", "effective_on": "2024-01-01T00:00:00Z"},
+				}},
+				Reviews: []evaluator.Result{{Message: "Review needed"}},
+				Successes: []evaluator.Result{{
+					Message:  "Pass",
+					Metadata: map[string]any{"code": "attestation.signed"},
+				}},
+			},
+		},
+	}
+
+	data, err := r.toFormat(SARIF)
+	require.NoError(t, err)
+
+	assert.JSONEq(t, `{
+		"version": "2.1.0",
+		"$schema": "https://json.schemastore.org/sarif-2.1.0.json",
+		"runs": [{
+			"tool": {"driver": {
+				"name": "ec",
+				"informationUri": "https://enterprisecontract.dev",
+				"version": "v0.5.0",
+				"rules": [
+					{
+						"id": "attestation.signed",
+						"shortDescription": {"text": "Signed"},
+						"fullDescription": {"text": "The attestation is signed."},
+						"help": {"text": "Sign the attestation."},
+						"properties": {"tags": ["minimal"]}
+					},
+					{"id": "tasks.current"}
+				]
+			}},
+			"results": [
+				{
+					"ruleId": "attestation.signed",
+					"ruleIndex": 0,
+					"level": "error",
+					"message": {"text": "Not signed"},
+					"locations": [{
+						"physicalLocation": {"artifactLocation": {"uri": "registry.io/repo/frontend@sha256:abc"}},
+						"logicalLocations": [{"name": "frontend", "kind": "module"}]
+					}]
+				},
+				{
+					"ruleId": "tasks.current",
+					"ruleIndex": 1,
+					"level": "warning",
+					"message": {"text": "Old task"},
+					"locations": [{
+						"physicalLocation": {"artifactLocation": {"uri": "registry.io/repo/frontend@sha256:abc"}},
+						"logicalLocations": [{"name": "frontend", "kind": "module"}]
+					}],
+					"properties": {"term": "buildah", "effective_on": "2024-01-01T00:00:00Z"}
+				},
+				{
+					"kind": "review",
+					"level": "note",
+					"message": {"text": "Review needed"},
+					"locations": [{
+						"physicalLocation": {"artifactLocation": {"uri": "registry.io/repo/frontend@sha256:abc"}},
+						"logicalLocations": [{"name": "frontend", "kind": "module"}]
+					}]
+				}
+			]
+		}]
+	}`, string(data))
+
+	r.ShowSuccesses = true
+	data, err = r.toFormat(SARIF)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `{"ruleId":"attestation.signed","ruleIndex":0,"kind":"pass","level":"none","message":{"text":"Pass"}`)
+}
+
+func TestToSARIFEmpty(t *testing.T) {
+	r := Report{}
+
+	data, err := r.toFormat(SARIF)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"version": "2.1.0",
+		"$schema": "https://json.schemastore.org/sarif-2.1.0.json",
+		"runs": [{"tool": {"driver": {"name": "ec", "informationUri": "https://enterprisecontract.dev", "rules": []}}, "results": []}]
+	}`, string(data))
+}
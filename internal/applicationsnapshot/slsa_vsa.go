@@ -0,0 +1,200 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package applicationsnapshot
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/in-toto/in-toto-golang/in_toto"
+	sigstoresig "github.com/sigstore/sigstore/pkg/signature"
+	"github.com/sigstore/sigstore/pkg/signature/dsse"
+
+	"github.com/enterprise-contract/ec-cli/internal/utils/oci"
+)
+
+const (
+	// PredicateSLSAVSA is the predicate type of the SLSA Verification Summary
+	// Attestation
+	PredicateSLSAVSA = "https://slsa.dev/verification_summary/v1"
+
+	// VerifierID identifies ec as the verifier in the SLSA VSA
+	VerifierID = "https://enterprisecontract.dev/ec-cli"
+
+	// DSSEArtifactType is the artifact type of the signed SLSA VSAs attached
+	// to the images, the DSSE envelope holding the in-toto statement
+	DSSEArtifactType = "application/vnd.dsse.envelope.v1+json"
+
+	// PredicateTypeAnnotation holds the predicate type of the in-toto
+	// statement in the DSSE envelope attached to the image
+	PredicateTypeAnnotation = "in-toto.io/predicate-type"
+
+	verificationPassed = "PASSED"
+	verificationFailed = "FAILED"
+)
+
+// ResourceDescriptor describes the policy and the attestations used as input
+// in the SLSA VSA
+type ResourceDescriptor struct {
+	URI    string            `json:"uri,omitempty"`
+	Digest map[string]string `json:"digest"`
+}
+
+// Verifier identifies the verifier in the SLSA VSA
+type Verifier struct {
+	ID      string            `json:"id"`
+	Version map[string]string `json:"version,omitempty"`
+}
+
+// SLSAVerificationSummary is the predicate of the SLSA VSA, see
+// https://slsa.dev/spec/v1.0/verification_summary
+type SLSAVerificationSummary struct {
+	Verifier           Verifier             `json:"verifier"`
+	TimeVerified       time.Time            `json:"timeVerified"`
+	ResourceURI        string               `json:"resourceUri"`
+	Policy             ResourceDescriptor   `json:"policy"`
+	InputAttestations  []ResourceDescriptor `json:"inputAttestations,omitempty"`
+	VerificationResult string               `json:"verificationResult"`
+	VerifiedLevels     []string             `json:"verifiedLevels"`
+	SlsaVersion        string               `json:"slsaVersion"`
+}
+
+// SLSAVSAStatement is the in-toto statement holding the SLSA VSA of a single
+// component
+type SLSAVSAStatement struct {
+	in_toto.StatementHeader
+	Predicate SLSAVerificationSummary `json:"predicate"`
+}
+
+// NewSLSAVSA returns the SLSA VSA of the component of the report with the
+// given image digest as its subject. The policy is described by the given
+// URI, if any, and the digest of the policy used in the validation.
+func NewSLSAVSA(r Report, c Component, digest v1.Hash, policyURI string) (SLSAVSAStatement, error) {
+	ref, err := name.ParseReference(c.ContainerImage)
+	if err != nil {
+		return SLSAVSAStatement{}, fmt.Errorf("unable to parse the image reference %q: %w", c.ContainerImage, err)
+	}
+
+	policy, err := json.Marshal(r.Policy)
+	if err != nil {
+		return SLSAVSAStatement{}, err
+	}
+
+	inputs := make([]ResourceDescriptor, 0, len(c.Attestations))
+	for _, a := range c.Attestations {
+		inputs = append(inputs, ResourceDescriptor{Digest: sha256Digest(a.Statement())})
+	}
+
+	result := verificationPassed
+	if !c.Success {
+		result = verificationFailed
+	}
+
+	verified := r.created
+	if verified.IsZero() {
+		verified = time.Now().UTC()
+	}
+
+	return SLSAVSAStatement{
+		StatementHeader: in_toto.StatementHeader{
+			Type:          StatmentVSA,
+			PredicateType: PredicateSLSAVSA,
+			Subject: []in_toto.Subject{
+				{Name: ref.Context().Name(), Digest: map[string]string{digest.Algorithm: digest.Hex}},
+			},
+		},
+		Predicate: SLSAVerificationSummary{
+			Verifier: Verifier{
+				ID:      VerifierID,
+				Version: map[string]string{"ec-cli": r.EcVersion},
+			},
+			TimeVerified:       verified,
+			ResourceURI:        c.ContainerImage,
+			Policy:             ResourceDescriptor{URI: policyURI, Digest: sha256Digest(policy)},
+			InputAttestations:  inputs,
+			VerificationResult: result,
+			VerifiedLevels:     []string{},
+			SlsaVersion:        "1.0",
+		},
+	}, nil
+}
+
+// AttachSLSAVSAs generates the SLSA VSA of each component of the report, see
+// NewSLSAVSA, signs it using the signer and pushes the resulting DSSE
+// envelope to the registry as an artifact referring to the image of the
+// component. The references of the pushed artifacts are returned in the order
+// of the components.
+func AttachSLSAVSAs(ctx context.Context, r Report, policyURI string, signer sigstoresig.Signer) ([]name.Digest, error) {
+	client := oci.NewClient(ctx)
+	envelopeSigner := dsse.WrapSigner(signer, in_toto.PayloadType)
+
+	pushed := make([]name.Digest, 0, len(r.Components))
+	for _, c := range r.Components {
+		ref, err := name.ParseReference(c.ContainerImage)
+		if err != nil {
+			return pushed, fmt.Errorf("unable to parse the image reference %q: %w", c.ContainerImage, err)
+		}
+
+		desc, err := client.Head(ref)
+		if err != nil {
+			return pushed, fmt.Errorf("unable to fetch the descriptor of the image %q: %w", c.ContainerImage, err)
+		}
+
+		vsa, err := NewSLSAVSA(r, c, desc.Digest, policyURI)
+		if err != nil {
+			return pushed, err
+		}
+
+		statement, err := json.Marshal(vsa)
+		if err != nil {
+			return pushed, err
+		}
+
+		envelope, err := envelopeSigner.SignMessage(bytes.NewReader(statement))
+		if err != nil {
+			return pushed, fmt.Errorf("unable to sign the VSA of the image %q: %w", c.ContainerImage, err)
+		}
+
+		artifact, err := oci.ReferrerArtifact(envelope, DSSEArtifactType, DSSEArtifactType, *desc, map[string]string{
+			PredicateTypeAnnotation: PredicateSLSAVSA,
+		})
+		if err != nil {
+			return pushed, err
+		}
+
+		target, err := oci.PushReferrer(client, ref, artifact)
+		if err != nil {
+			return pushed, fmt.Errorf("unable to push the VSA of the image %q: %w", c.ContainerImage, err)
+		}
+		pushed = append(pushed, target)
+	}
+
+	return pushed, nil
+}
+
+func sha256Digest(data []byte) map[string]string {
+	sum := sha256.Sum256(data)
+
+	return map[string]string{"sha256": hex.EncodeToString(sum[:])}
+}
@@ -0,0 +1,165 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package applicationsnapshot
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/in-toto/in-toto-golang/in_toto"
+	app "github.com/konflux-ci/application-api/api/v1alpha1"
+	"github.com/secure-systems-lab/go-securesystemslib/dsse"
+	"github.com/sigstore/sigstore/pkg/signature"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/attestation"
+	"github.com/enterprise-contract/ec-cli/internal/utils/oci"
+	"github.com/enterprise-contract/ec-cli/internal/utils/oci/fake"
+)
+
+var vsaSubject = v1.Descriptor{
+	MediaType: types.OCIManifestSchema1,
+	Size:      1234,
+	Digest:    v1.Hash{Algorithm: "sha256", Hex: "4e388ab32b10dc8dbc7e28144f552830adc74787c1e2c0824032078a79f227fb"},
+}
+
+func vsaTestReport() Report {
+	return Report{
+		created:   time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		EcVersion: "v0.5",
+		Success:   true,
+		Components: []Component{
+			{
+				SnapshotComponent: app.SnapshotComponent{Name: "spam", ContainerImage: "registry.io/spam:tag"},
+				Success:           true,
+				Attestations: []attestation.Attestation{
+					provenance{data: []byte(`{"predicateType": "https://slsa.dev/provenance/v0.2"}`)},
+				},
+			},
+		},
+	}
+}
+
+func TestNewSLSAVSA(t *testing.T) {
+	r := vsaTestReport()
+
+	vsa, err := NewSLSAVSA(r, r.Components[0], vsaSubject.Digest, "github.com/org/policy")
+	require.NoError(t, err)
+
+	data, err := json.Marshal(vsa)
+	require.NoError(t, err)
+
+	assert.JSONEq(t, `{
+		"_type": "https://in-toto.io/Statement/v1",
+		"predicateType": "https://slsa.dev/verification_summary/v1",
+		"subject": [{"name": "registry.io/spam", "digest": {"sha256": "4e388ab32b10dc8dbc7e28144f552830adc74787c1e2c0824032078a79f227fb"}}],
+		"predicate": {
+			"verifier": {"id": "https://enterprisecontract.dev/ec-cli", "version": {"ec-cli": "v0.5"}},
+			"timeVerified": "2024-01-01T00:00:00Z",
+			"resourceUri": "registry.io/spam:tag",
+			"policy": {"uri": "github.com/org/policy", "digest": {"sha256": "44136fa355b3678a1146ad16f7e8649e94fb4fc21fe77e8310c060f61caaff8a"}},
+			"inputAttestations": [{"digest": {"sha256": "20dc63ea23dbbecb87460c33c9975a1472976c8563e140aefe76ac0512a9ed2e"}}],
+			"verificationResult": "PASSED",
+			"verifiedLevels": [],
+			"slsaVersion": "1.0"
+		}
+	}`, string(data))
+
+	r.Components[0].Success = false
+	vsa, err = NewSLSAVSA(r, r.Components[0], vsaSubject.Digest, "")
+	require.NoError(t, err)
+	assert.Equal(t, "FAILED", vsa.Predicate.VerificationResult)
+	assert.Empty(t, vsa.Predicate.Policy.URI)
+}
+
+func TestAttachSLSAVSAs(t *testing.T) {
+	ref := name.MustParseReference("registry.io/spam:tag")
+
+	client := fake.FakeClient{}
+	client.On("Head", ref).Return(&vsaSubject, nil)
+	client.On("Write", mock.Anything, mock.Anything).Return(nil)
+	ctx := oci.WithClient(context.Background(), &client)
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	signer, err := signature.LoadECDSASignerVerifier(key, crypto.SHA256)
+	require.NoError(t, err)
+
+	pushed, err := AttachSLSAVSAs(ctx, vsaTestReport(), "", signer)
+	require.NoError(t, err)
+	require.Len(t, pushed, 1)
+
+	img := client.Calls[1].Arguments.Get(1).(v1.Image)
+	manifest, err := img.Manifest()
+	require.NoError(t, err)
+	assert.Equal(t, types.MediaType(DSSEArtifactType), manifest.Config.MediaType)
+	assert.Equal(t, vsaSubject.Digest, manifest.Subject.Digest)
+	assert.Equal(t, PredicateSLSAVSA, manifest.Annotations[PredicateTypeAnnotation])
+
+	layers, err := img.Layers()
+	require.NoError(t, err)
+	rc, err := layers[0].Uncompressed()
+	require.NoError(t, err)
+	defer rc.Close()
+	content, err := io.ReadAll(rc)
+	require.NoError(t, err)
+
+	var envelope dsse.Envelope
+	require.NoError(t, json.Unmarshal(content, &envelope))
+	assert.Equal(t, in_toto.PayloadType, envelope.PayloadType)
+	require.Len(t, envelope.Signatures, 1)
+
+	payload, err := base64.StdEncoding.DecodeString(envelope.Payload)
+	require.NoError(t, err)
+	var statement SLSAVSAStatement
+	require.NoError(t, json.Unmarshal(payload, &statement))
+	assert.Equal(t, PredicateSLSAVSA, statement.PredicateType)
+	assert.Equal(t, "PASSED", statement.Predicate.VerificationResult)
+}
+
+func TestAttachSLSAVSAsErrors(t *testing.T) {
+	ref := name.MustParseReference("registry.io/spam:tag")
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	signer, err := signature.LoadECDSASignerVerifier(key, crypto.SHA256)
+	require.NoError(t, err)
+
+	client := fake.FakeClient{}
+	client.On("Head", ref).Return(&vsaSubject, nil)
+	client.On("Write", mock.Anything, mock.Anything).Return(errors.New("denied"))
+	ctx := oci.WithClient(context.Background(), &client)
+
+	_, err = AttachSLSAVSAs(ctx, vsaTestReport(), "", signer)
+	assert.EqualError(t, err, `unable to push the VSA of the image "registry.io/spam:tag": denied`)
+}
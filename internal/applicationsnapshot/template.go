@@ -0,0 +1,109 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package applicationsnapshot
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+
+	"github.com/enterprise-contract/ec-cli/internal/format"
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+// regoOutputRule is the rule of the Rego transformation holding the
+// transformed report
+const regoOutputRule = "output"
+
+// renderTemplate renders the report through the Go template given via the
+// template option of the target. The template is executed with the report as
+// found in the JSON format, e.g. {{ .success }}, or, if the rego option is
+// given, with the value of the output rule of the Rego transformation
+// evaluated with the report as input. With just the rego option the value of
+// the output rule is written in JSON format.
+func (r *Report) renderTemplate(target *format.Target) ([]byte, error) {
+	if target.Options.Template == "" && target.Options.Rego == "" {
+		return nil, errors.New("the template format requires the template or the rego option, e.g. template=report.html?template=report.tmpl")
+	}
+
+	j, err := json.Marshal(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var input any
+	if err := json.Unmarshal(j, &input); err != nil {
+		return nil, err
+	}
+
+	if target.Options.Rego != "" {
+		if input, err = transform(target, input); err != nil {
+			return nil, err
+		}
+	}
+
+	if target.Options.Template == "" {
+		return json.Marshal(input)
+	}
+
+	text, err := target.ReadFile(target.Options.Template)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the template: %w", err)
+	}
+
+	data, err := utils.RenderTemplate(target.Options.Template, string(text), input)
+	if err != nil {
+		return nil, fmt.Errorf("unable to render the template %s: %w", target.Options.Template, err)
+	}
+
+	return data, nil
+}
+
+// transform evaluates the output rule of the Rego file given via the rego
+// option of the target with the input
+func transform(target *format.Target, input any) (any, error) {
+	file := target.Options.Rego
+	src, err := target.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the rego transformation: %w", err)
+	}
+
+	module, err := ast.ParseModule(file, string(src))
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse the rego transformation: %w", err)
+	}
+
+	query := fmt.Sprintf("%s.%s", module.Package.Path, regoOutputRule)
+	rs, err := rego.New(
+		rego.Query(query),
+		rego.ParsedModule(module),
+		rego.Input(input),
+	).Eval(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("unable to evaluate the rego transformation %s: %w", file, err)
+	}
+
+	if len(rs) == 0 || len(rs[0].Expressions) == 0 {
+		return nil, fmt.Errorf("the rego transformation %s does not define %s", file, query)
+	}
+
+	return rs[0].Expressions[0].Value, nil
+}
@@ -0,0 +1,85 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package applicationsnapshot
+
+import (
+	"bytes"
+	"testing"
+
+	app "github.com/konflux-ci/application-api/api/v1alpha1"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/evaluator"
+	"github.com/enterprise-contract/ec-cli/internal/format"
+)
+
+func TestRenderTemplate(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "slack.tmpl", []byte(
+		`{{ range .components }}{{ .name }}: {{ if .success }}passed{{ else }}failed{{ end }}{{ range .violations }} - {{ .msg }}{{ end }}{{ nl }}{{ end }}`), 0600))
+	require.NoError(t, afero.WriteFile(fs, "transform.rego", []byte(`package slack
+
+import rego.v1
+
+output := {"failed": [c.name | some c in input.components; not c.success]}
+`), 0600))
+	require.NoError(t, afero.WriteFile(fs, "failed.tmpl", []byte(`Failed: {{ range .failed }}{{ . }} {{ end }}`), 0600))
+	require.NoError(t, afero.WriteFile(fs, "undefined.rego", []byte("package nothing\n\nother := 1\n"), 0600))
+
+	r := Report{
+		Components: []Component{
+			{SnapshotComponent: app.SnapshotComponent{Name: "frontend"}, Success: true},
+			{
+				SnapshotComponent: app.SnapshotComponent{Name: "backend"},
+				Violations:        []evaluator.Result{{Message: "Not signed"}},
+			},
+		},
+	}
+
+	cases := []struct {
+		name     string
+		target   string
+		expected string
+		err      string
+	}{
+		{name: "template", target: "template?template=slack.tmpl", expected: "frontend: passed\nbackend: failed - Not signed\n"},
+		{name: "template and rego", target: "template?template=failed.tmpl&rego=transform.rego", expected: "Failed: backend \n"},
+		{name: "rego", target: "template?rego=transform.rego", expected: `{"failed":["backend"]}` + "\n"},
+		{name: "no options", target: "template", err: "the template format requires the template or the rego option"},
+		{name: "missing template", target: "template?template=missing.tmpl", err: "unable to read the template: open missing.tmpl: file does not exist"},
+		{name: "undefined output", target: "template?rego=undefined.rego", err: "the rego transformation undefined.rego does not define data.nothing.output"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			out := bytes.Buffer{}
+			p := format.NewTargetParser(JSON, format.Options{}, &out, fs)
+
+			err := r.WriteAll([]string{c.target}, p)
+			if c.err != "" {
+				assert.ErrorContains(t, err, c.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, c.expected, out.String())
+		})
+	}
+}
@@ -0,0 +1,105 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package attestation
+
+import (
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	log "github.com/sirupsen/logrus"
+)
+
+// URI prefixes used in provenance materials to denote container images
+var imageMaterialPrefixes = []string{"oci://", "docker://"}
+
+// BaseImage describes a container image listed in the materials of a
+// provenance predicate, i.e. an image that was used to build the image
+// being validated.
+type BaseImage struct {
+	Ref        string `json:"ref"`
+	Registry   string `json:"registry"`
+	Repository string `json:"repository"`
+	Digest     string `json:"digest,omitempty"`
+}
+
+// BaseImages extracts the base image references from the materials of the
+// given SLSA Provenance attestation, of either v0.2 or v1.0. Materials not
+// referencing container images, e.g. git repositories, are ignored. An empty
+// slice is returned for attestations of other predicate types.
+func BaseImages(att Attestation) []BaseImage {
+	provenance := NormalizedProvenance(att)
+	if provenance == nil {
+		return nil
+	}
+
+	var images []BaseImage
+	for _, m := range provenance.Materials {
+		uri, ok := trimImagePrefix(m.URI)
+		if !ok {
+			continue
+		}
+
+		ref, err := name.ParseReference(uri)
+		if err != nil {
+			log.Debugf("Ignoring material with unparsable image reference %q: %v", m.URI, err)
+			continue
+		}
+
+		image := BaseImage{
+			Ref:        uri,
+			Registry:   ref.Context().RegistryStr(),
+			Repository: ref.Context().Name(),
+		}
+
+		if d, ok := ref.(name.Digest); ok {
+			image.Digest = d.DigestStr()
+		} else if sha, ok := m.Digest["sha256"]; ok {
+			image.Digest = "sha256:" + sha
+		}
+
+		images = append(images, image)
+	}
+
+	return images
+}
+
+func trimImagePrefix(uri string) (string, bool) {
+	for _, prefix := range imageMaterialPrefixes {
+		if strings.HasPrefix(uri, prefix) {
+			return strings.TrimPrefix(uri, prefix), true
+		}
+	}
+
+	return "", false
+}
+
+// FromAllowedRegistry returns true if the base image is hosted on one of the
+// allowed registries. An allowed entry can be a registry host, e.g.
+// "registry.io", or a repository prefix, e.g. "registry.io/org".
+func (b BaseImage) FromAllowedRegistry(allowed []string) bool {
+	for _, a := range allowed {
+		a = strings.TrimSuffix(a, "/")
+		if a == "" {
+			continue
+		}
+		if b.Registry == a || b.Repository == a || strings.HasPrefix(b.Repository, a+"/") {
+			return true
+		}
+	}
+
+	return false
+}
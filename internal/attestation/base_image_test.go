@@ -0,0 +1,125 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package attestation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBaseImages(t *testing.T) {
+	cases := []struct {
+		name          string
+		predicateType string
+		statement     string
+		expected      []BaseImage
+	}{
+		{
+			name:          "not provenance",
+			predicateType: PredicateSpdxDocument,
+			statement:     `{"predicate": {"materials": [{"uri": "oci://registry.io/repo"}]}}`,
+		},
+		{
+			name:          "no materials",
+			predicateType: PredicateSLSAProvenance,
+			statement:     `{"predicate": {}}`,
+		},
+		{
+			name:          "mixed materials",
+			predicateType: PredicateSLSAProvenance,
+			statement: `{"predicate": {"materials": [
+				{"uri": "git+https://github.com/org/repo.git", "digest": {"sha1": "abc"}},
+				{"uri": "oci://registry.io/base/ubi", "digest": {"sha256": "4e388ab32b10dc8dbc7e28144f552830adc74787c1e2c0824032078a79f227fb"}},
+				{"uri": "docker://other.io/org/builder@sha256:4e388ab32b10dc8dbc7e28144f552830adc74787c1e2c0824032078a79f227fb"}
+			]}}`,
+			expected: []BaseImage{
+				{
+					Ref:        "registry.io/base/ubi",
+					Registry:   "registry.io",
+					Repository: "registry.io/base/ubi",
+					Digest:     "sha256:4e388ab32b10dc8dbc7e28144f552830adc74787c1e2c0824032078a79f227fb",
+				},
+				{
+					Ref:        "other.io/org/builder@sha256:4e388ab32b10dc8dbc7e28144f552830adc74787c1e2c0824032078a79f227fb",
+					Registry:   "other.io",
+					Repository: "other.io/org/builder",
+					Digest:     "sha256:4e388ab32b10dc8dbc7e28144f552830adc74787c1e2c0824032078a79f227fb",
+				},
+			},
+		},
+		{
+			name:          "v1.0 resolved dependencies",
+			predicateType: PredicateSLSAProvenanceV1,
+			statement: `{"predicate": {"buildDefinition": {"resolvedDependencies": [
+				{"uri": "git+https://github.com/org/repo.git", "digest": {"sha1": "abc"}},
+				{"uri": "oci://registry.io/base/ubi", "digest": {"sha256": "4e388ab32b10dc8dbc7e28144f552830adc74787c1e2c0824032078a79f227fb"}}
+			]}}}`,
+			expected: []BaseImage{
+				{
+					Ref:        "registry.io/base/ubi",
+					Registry:   "registry.io",
+					Repository: "registry.io/base/ubi",
+					Digest:     "sha256:4e388ab32b10dc8dbc7e28144f552830adc74787c1e2c0824032078a79f227fb",
+				},
+			},
+		},
+		{
+			name:          "malformed statement",
+			predicateType: PredicateSLSAProvenance,
+			statement:     `{"predicate": "nope"}`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			att := provenance{data: []byte(c.statement)}
+			att.statement.PredicateType = c.predicateType
+
+			assert.Equal(t, c.expected, BaseImages(att))
+		})
+	}
+}
+
+func TestFromAllowedRegistry(t *testing.T) {
+	image := BaseImage{
+		Ref:        "registry.io/org/repo:tag",
+		Registry:   "registry.io",
+		Repository: "registry.io/org/repo",
+	}
+
+	cases := []struct {
+		name     string
+		allowed  []string
+		expected bool
+	}{
+		{name: "nothing allowed", expected: false},
+		{name: "registry", allowed: []string{"registry.io"}, expected: true},
+		{name: "repository prefix", allowed: []string{"registry.io/org/"}, expected: true},
+		{name: "repository", allowed: []string{"registry.io/org/repo"}, expected: true},
+		{name: "partial repository", allowed: []string{"registry.io/or"}, expected: false},
+		{name: "other registry", allowed: []string{"evil.io", ""}, expected: false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, c.expected, image.FromAllowedRegistry(c.allowed))
+		})
+	}
+}
@@ -0,0 +1,115 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package attestation
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/in-toto/in-toto-golang/in_toto"
+	log "github.com/sirupsen/logrus"
+)
+
+// Provenance is the normalized view of the SLSA Provenance v0.2 and v1.0
+// predicates, allowing the same rules to apply regardless of the version of
+// the predicate. The materials of v0.2 correspond to the resolved
+// dependencies of v1.0, and the invocation parameters of v0.2 to the external
+// parameters of v1.0. Times are given in RFC3339 format, in UTC.
+type Provenance struct {
+	BuildType    string     `json:"build_type"`
+	BuilderID    string     `json:"builder_id"`
+	InvocationID string     `json:"invocation_id,omitempty"`
+	Parameters   any        `json:"parameters,omitempty"`
+	Materials    []Material `json:"materials,omitempty"`
+	StartedOn    string     `json:"started_on,omitempty"`
+	FinishedOn   string     `json:"finished_on,omitempty"`
+}
+
+// Material is an artifact used to build the image, e.g. a git repository or a
+// base image
+type Material struct {
+	URI    string            `json:"uri"`
+	Digest map[string]string `json:"digest,omitempty"`
+}
+
+// IsProvenance returns true if the predicate type is of a supported SLSA
+// Provenance version
+func IsProvenance(predicateType string) bool {
+	return predicateType == PredicateSLSAProvenance || predicateType == PredicateSLSAProvenanceV1
+}
+
+// NormalizedProvenance returns the normalized view of the SLSA Provenance
+// attestation, or nil for attestations of other predicate types or that
+// can't be parsed.
+func NormalizedProvenance(att Attestation) *Provenance {
+	switch att.PredicateType() {
+	case PredicateSLSAProvenance:
+		var statement in_toto.ProvenanceStatementSLSA02
+		if err := json.Unmarshal(att.Statement(), &statement); err != nil {
+			log.Debugf("Unable to parse the SLSA Provenance v0.2: %v", err)
+			return nil
+		}
+
+		p := statement.Predicate
+		provenance := Provenance{
+			BuildType:  p.BuildType,
+			BuilderID:  p.Builder.ID,
+			Parameters: p.Invocation.Parameters,
+		}
+		for _, m := range p.Materials {
+			provenance.Materials = append(provenance.Materials, Material{URI: m.URI, Digest: m.Digest})
+		}
+		if p.Metadata != nil {
+			provenance.InvocationID = p.Metadata.BuildInvocationID
+			provenance.StartedOn = utcTime(p.Metadata.BuildStartedOn)
+			provenance.FinishedOn = utcTime(p.Metadata.BuildFinishedOn)
+		}
+
+		return &provenance
+	case PredicateSLSAProvenanceV1:
+		var statement in_toto.ProvenanceStatementSLSA1
+		if err := json.Unmarshal(att.Statement(), &statement); err != nil {
+			log.Debugf("Unable to parse the SLSA Provenance v1.0: %v", err)
+			return nil
+		}
+
+		p := statement.Predicate
+		provenance := Provenance{
+			BuildType:    p.BuildDefinition.BuildType,
+			BuilderID:    p.RunDetails.Builder.ID,
+			InvocationID: p.RunDetails.BuildMetadata.InvocationID,
+			Parameters:   p.BuildDefinition.ExternalParameters,
+			StartedOn:    utcTime(p.RunDetails.BuildMetadata.StartedOn),
+			FinishedOn:   utcTime(p.RunDetails.BuildMetadata.FinishedOn),
+		}
+		for _, d := range p.BuildDefinition.ResolvedDependencies {
+			provenance.Materials = append(provenance.Materials, Material{URI: d.URI, Digest: d.Digest})
+		}
+
+		return &provenance
+	}
+
+	return nil
+}
+
+func utcTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+
+	return t.UTC().Format(time.RFC3339Nano)
+}
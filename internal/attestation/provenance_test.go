@@ -0,0 +1,108 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package attestation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizedProvenance(t *testing.T) {
+	expected := &Provenance{
+		BuildType:    "https://tekton.dev/attestations/chains/pipelinerun@v2",
+		BuilderID:    "https://tekton.dev/chains/v2",
+		InvocationID: "b0a4d4d1",
+		Parameters:   map[string]any{"git-url": "https://github.com/org/repo"},
+		Materials: []Material{
+			{URI: "git+https://github.com/org/repo.git", Digest: map[string]string{"sha1": "abc"}},
+		},
+		StartedOn:  "2024-01-02T03:04:05Z",
+		FinishedOn: "2024-01-02T03:14:05Z",
+	}
+
+	cases := []struct {
+		name          string
+		predicateType string
+		statement     string
+		expected      *Provenance
+	}{
+		{
+			name:          "v0.2",
+			predicateType: PredicateSLSAProvenance,
+			statement: `{"predicate": {
+				"builder": {"id": "https://tekton.dev/chains/v2"},
+				"buildType": "https://tekton.dev/attestations/chains/pipelinerun@v2",
+				"invocation": {"parameters": {"git-url": "https://github.com/org/repo"}},
+				"metadata": {
+					"buildInvocationID": "b0a4d4d1",
+					"buildStartedOn": "2024-01-02T04:04:05+01:00",
+					"buildFinishedOn": "2024-01-02T03:14:05Z"
+				},
+				"materials": [{"uri": "git+https://github.com/org/repo.git", "digest": {"sha1": "abc"}}]
+			}}`,
+			expected: expected,
+		},
+		{
+			name:          "v1.0",
+			predicateType: PredicateSLSAProvenanceV1,
+			statement: `{"predicate": {
+				"buildDefinition": {
+					"buildType": "https://tekton.dev/attestations/chains/pipelinerun@v2",
+					"externalParameters": {"git-url": "https://github.com/org/repo"},
+					"resolvedDependencies": [{"uri": "git+https://github.com/org/repo.git", "digest": {"sha1": "abc"}}]
+				},
+				"runDetails": {
+					"builder": {"id": "https://tekton.dev/chains/v2"},
+					"metadata": {
+						"invocationID": "b0a4d4d1",
+						"startedOn": "2024-01-02T03:04:05Z",
+						"finishedOn": "2024-01-02T03:14:05Z"
+					}
+				}
+			}}`,
+			expected: expected,
+		},
+		{
+			name:          "minimal v1.0",
+			predicateType: PredicateSLSAProvenanceV1,
+			statement:     `{"predicate": {"buildDefinition": {"buildType": "https://my.build.type"}}}`,
+			expected:      &Provenance{BuildType: "https://my.build.type"},
+		},
+		{
+			name:          "not provenance",
+			predicateType: PredicateSpdxDocument,
+			statement:     `{"predicate": {"buildType": "https://my.build.type"}}`,
+		},
+		{
+			name:          "malformed statement",
+			predicateType: PredicateSLSAProvenanceV1,
+			statement:     `{"predicate": "nope"}`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			att := provenance{data: []byte(c.statement)}
+			att.statement.PredicateType = c.predicateType
+
+			assert.Equal(t, c.expected, NormalizedProvenance(att))
+		})
+	}
+}
@@ -0,0 +1,103 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package attestation
+
+import (
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	ct "github.com/sigstore/cosign/v2/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSLSAProvenanceV1FromSignature(t *testing.T) {
+	cases := []struct {
+		name      string
+		statement string
+		err       string
+	}{
+		{
+			name: "in-toto v0.1 statement",
+			statement: `{
+				"_type": "https://in-toto.io/Statement/v0.1",
+				"predicateType": "https://slsa.dev/provenance/v1",
+				"predicate": {"buildDefinition": {"buildType": "https://my.build.type"}}
+			}`,
+		},
+		{
+			name: "in-toto v1 statement",
+			statement: `{
+				"_type": "https://in-toto.io/Statement/v1",
+				"predicateType": "https://slsa.dev/provenance/v1",
+				"predicate": {"buildDefinition": {"buildType": "https://my.build.type"}}
+			}`,
+		},
+		{
+			name: "unsupported statement type",
+			statement: `{
+				"_type": "https://in-toto.io/Statement/v2",
+				"predicateType": "https://slsa.dev/provenance/v1"
+			}`,
+			err: "unsupported attestation type: https://in-toto.io/Statement/v2",
+		},
+		{
+			name: "unexpected predicate type",
+			statement: `{
+				"_type": "https://in-toto.io/Statement/v1",
+				"predicateType": "https://slsa.dev/provenance/v0.2"
+			}`,
+			err: "unsupported attestation predicate type: https://slsa.dev/provenance/v0.2",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			sig := mockSignature{&mock.Mock{}}
+			sig.On("MediaType").Return(types.MediaType(ct.DssePayloadType), nil)
+			sig.On("Uncompressed").Return(buffy(
+				fmt.Sprintf(`{"payload": "%s", "signatures": [{"keyid": "key-id-1", "sig": "sig-1"}]}`, encode(c.statement)),
+			), nil)
+			sig.On("Base64Signature").Return("", nil)
+			sig.On("Cert").Return(&x509.Certificate{}, nil)
+			sig.On("Chain").Return([]*x509.Certificate{}, nil)
+
+			att, err := SLSAProvenanceV1FromSignature(sig)
+			if c.err != "" {
+				assert.EqualError(t, err, c.err)
+				assert.Nil(t, att)
+				return
+			}
+			require.NoError(t, err)
+
+			assert.Equal(t, PredicateSLSAProvenanceV1, att.PredicateType())
+			assert.JSONEq(t, c.statement, string(att.Statement()))
+			require.Len(t, att.Signatures(), 1)
+			assert.Equal(t, "key-id-1", att.Signatures()[0].KeyID)
+
+			j, err := json.Marshal(att)
+			require.NoError(t, err)
+			assert.Contains(t, string(j), `"predicateBuildType":"https://my.build.type"`)
+		})
+	}
+}
@@ -27,6 +27,7 @@ import (
 	"github.com/enterprise-contract/ec-cli/internal/evaluation_target/definition"
 	"github.com/enterprise-contract/ec-cli/internal/evaluator"
 	"github.com/enterprise-contract/ec-cli/internal/output"
+	"github.com/enterprise-contract/ec-cli/internal/policy"
 	"github.com/enterprise-contract/ec-cli/internal/policy/source"
 	"github.com/enterprise-contract/ec-cli/internal/utils"
 )
@@ -35,24 +36,29 @@ var definitionFile = definition.NewDefinition
 
 // ValidatePipeline calls NewPipelineEvaluator to obtain an PipelineEvaluator. It then executes the associated TestRunner
 // which tests the associated pipeline file(s) against the associated policies, and displays the output.
-func ValidateDefinition(ctx context.Context, fpath string, sources []source.PolicySource, namespace []string) (*output.Output, error) {
+// When a policy is given its source groups are used instead of the given sources.
+func ValidateDefinition(ctx context.Context, fpath string, sources []source.PolicySource, namespace []string, pol policy.Policy) (*output.Output, error) {
 	defFiles, err := detectInput(ctx, fpath)
 	if err != nil {
 		return nil, err
 	}
-	p, err := definitionFile(ctx, defFiles, sources, namespace)
+	p, err := definitionFile(ctx, defFiles, sources, namespace, pol)
 	if err != nil {
 		log.Debug("Failed to create definition file!")
 		return nil, err
 	}
 
-	results, _, err := p.Evaluator.Evaluate(ctx, evaluator.EvaluationTarget{Inputs: defFiles})
-	if err != nil {
-		log.Debug("Problem running conftest policy check!")
-		return nil, err
+	allResults := make([]evaluator.Outcome, 0, len(p.Evaluators))
+	for _, e := range p.Evaluators {
+		results, _, err := e.Evaluate(ctx, evaluator.EvaluationTarget{Inputs: defFiles})
+		if err != nil {
+			log.Debug("Problem running conftest policy check!")
+			return nil, err
+		}
+		allResults = append(allResults, results...)
 	}
 	log.Debug("Conftest policy check complete")
-	return &output.Output{PolicyCheck: results}, nil
+	return &output.Output{PolicyCheck: allResults}, nil
 }
 
 // detect if a file or directory was passed. if a directory, gather all files in it
@@ -31,13 +31,15 @@ import (
 	"github.com/enterprise-contract/ec-cli/internal/evaluation_target/definition"
 	"github.com/enterprise-contract/ec-cli/internal/evaluator"
 	"github.com/enterprise-contract/ec-cli/internal/output"
+	"github.com/enterprise-contract/ec-cli/internal/policy"
 	"github.com/enterprise-contract/ec-cli/internal/policy/source"
 	"github.com/enterprise-contract/ec-cli/internal/utils"
 )
 
 type (
-	mockEvaluator    struct{}
-	badMockEvaluator struct{}
+	mockEvaluator      struct{}
+	badMockEvaluator   struct{}
+	namedMockEvaluator string
 )
 
 func (e mockEvaluator) Evaluate(ctx context.Context, target evaluator.EvaluationTarget) ([]evaluator.Outcome, evaluator.Data, error) {
@@ -62,15 +64,34 @@ func (e badMockEvaluator) CapabilitiesPath() string {
 	return ""
 }
 
-func mockNewPipelineDefinitionFile(ctx context.Context, fpath []string, sources []source.PolicySource, namespace []string) (*definition.Definition, error) {
+func (e namedMockEvaluator) Evaluate(ctx context.Context, target evaluator.EvaluationTarget) ([]evaluator.Outcome, evaluator.Data, error) {
+	return []evaluator.Outcome{{Namespace: string(e)}}, nil, nil
+}
+
+func (e namedMockEvaluator) Destroy() {
+}
+
+func (e namedMockEvaluator) CapabilitiesPath() string {
+	return ""
+}
+
+// sourceGroupsMockNewPipelineDefinitionFile has an evaluator for each of the
+// source groups of a policy
+func sourceGroupsMockNewPipelineDefinitionFile(ctx context.Context, fpath []string, sources []source.PolicySource, namespace []string, _ policy.Policy) (*definition.Definition, error) {
 	return &definition.Definition{
-		Evaluator: mockEvaluator{},
+		Evaluators: []evaluator.Evaluator{namedMockEvaluator("release"), namedMockEvaluator("pipeline")},
 	}, nil
 }
 
-func badMockNewPipelineDefinitionFile(ctx context.Context, fpath []string, sources []source.PolicySource, namespace []string) (*definition.Definition, error) {
+func mockNewPipelineDefinitionFile(ctx context.Context, fpath []string, sources []source.PolicySource, namespace []string, _ policy.Policy) (*definition.Definition, error) {
 	return &definition.Definition{
-		Evaluator: badMockEvaluator{},
+		Evaluators: []evaluator.Evaluator{mockEvaluator{}},
+	}, nil
+}
+
+func badMockNewPipelineDefinitionFile(ctx context.Context, fpath []string, sources []source.PolicySource, namespace []string, _ policy.Policy) (*definition.Definition, error) {
+	return &definition.Definition{
+		Evaluators: []evaluator.Evaluator{badMockEvaluator{}},
 	}, nil
 }
 
@@ -85,7 +106,7 @@ func Test_ValidatePipeline(t *testing.T) {
 		fpath   string
 		err     error
 		output  *output.Output
-		defFunc func(ctx context.Context, fpath []string, sources []source.PolicySource, namespace []string) (*definition.Definition, error)
+		defFunc func(ctx context.Context, fpath []string, sources []source.PolicySource, namespace []string, p policy.Policy) (*definition.Definition, error)
 	}{
 		{
 			name:    "validation succeeds",
@@ -94,6 +115,13 @@ func Test_ValidatePipeline(t *testing.T) {
 			output:  &output.Output{PolicyCheck: []evaluator.Outcome{}},
 			defFunc: mockNewPipelineDefinitionFile,
 		},
+		{
+			name:    "results of all source groups",
+			fpath:   validFile,
+			err:     nil,
+			output:  &output.Output{PolicyCheck: []evaluator.Outcome{{Namespace: "release"}, {Namespace: "pipeline"}}},
+			defFunc: sourceGroupsMockNewPipelineDefinitionFile,
+		},
 		{
 			name:    "validation fails on empty directory",
 			fpath:   emptyDir,
@@ -150,7 +178,7 @@ func Test_ValidatePipeline(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			definitionFile = tt.defFunc
-			output, err := ValidateDefinition(ctx, tt.fpath, []source.PolicySource{}, []string{})
+			output, err := ValidateDefinition(ctx, tt.fpath, []source.PolicySource{}, []string{}, nil)
 			assert.Equal(t, tt.err, err)
 			assert.Equal(t, tt.output, output)
 		})
@@ -0,0 +1,362 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package downloader
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/enterprise-contract/go-gather/metadata"
+	getter "github.com/hashicorp/go-getter"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+const archiveLimitsKey key = 17
+
+// DefaultMaxArchiveBytes is the default maximum total size of the files
+// extracted from an archive
+const DefaultMaxArchiveBytes = 512 * 1024 * 1024
+
+// DefaultMaxArchiveFiles is the default maximum number of files extracted from
+// an archive
+const DefaultMaxArchiveFiles = 10000
+
+// ErrArchiveTooLarge is returned when the content of an archive exceeds the
+// limits
+var ErrArchiveTooLarge = errors.New("archive too large")
+
+// ArchiveLimits limit the content extracted from archives
+type ArchiveLimits struct {
+	// MaxBytes is the maximum total size of the extracted files
+	MaxBytes int64
+	// MaxFiles is the maximum number of extracted files
+	MaxFiles int
+}
+
+// WithArchiveLimits returns a context under which archives with content
+// exceeding the given limits fail to extract, see DefaultMaxArchiveBytes and
+// DefaultMaxArchiveFiles for the limits used by default. A limit of zero
+// means the default limit.
+func WithArchiveLimits(ctx context.Context, limits ArchiveLimits) context.Context {
+	return context.WithValue(ctx, archiveLimitsKey, limits)
+}
+
+func archiveLimits(ctx context.Context) ArchiveLimits {
+	limits, _ := ctx.Value(archiveLimitsKey).(ArchiveLimits)
+	if limits.MaxBytes <= 0 {
+		limits.MaxBytes = DefaultMaxArchiveBytes
+	}
+	if limits.MaxFiles <= 0 {
+		limits.MaxFiles = DefaultMaxArchiveFiles
+	}
+
+	return limits
+}
+
+const (
+	tarGz     = "tar.gz"
+	zipFormat = "zip"
+)
+
+// splitArchive recognizes HTTP sources of archives, i.e. with URLs ending in
+// .tar.gz, .tgz or .zip, without the archive query parameter, and returns the
+// URL of the archive file, the archive format and the subdirectory, if any,
+// e.g. `https://example.com/policy.tar.gz//policy`. The URL returned has the
+// archive query parameter set to false so the archive is downloaded as is and
+// extracted by extractArchive instead.
+func splitArchive(sourceUrl string) (string, string, string, bool) {
+	if sourceScheme(sourceUrl) != "http" {
+		return "", "", "", false
+	}
+
+	prefix := getterPrefix.FindString(sourceUrl)
+	src, subdir := getter.SourceDirSubdir(sourceUrl[len(prefix):])
+
+	u, err := url.Parse(src)
+	if err != nil {
+		return "", "", "", false
+	}
+
+	q := u.Query()
+	if q.Has("archive") {
+		return "", "", "", false
+	}
+
+	var format string
+	switch p := strings.ToLower(u.Path); {
+	case strings.HasSuffix(p, ".tar.gz"), strings.HasSuffix(p, ".tgz"):
+		format = tarGz
+	case strings.HasSuffix(p, ".zip"):
+		format = zipFormat
+	default:
+		return "", "", "", false
+	}
+
+	q.Set("archive", "false")
+	u.RawQuery = q.Encode()
+
+	return prefix + u.String(), format, subdir, true
+}
+
+// downloadArchive downloads the archive into a temporary directory and
+// extracts it, or the subdirectory within it, into the destination directory
+func downloadArchive(ctx context.Context, destDir, archiveUrl, format, subdir string, showMsg bool) (metadata.Metadata, error) {
+	if subdir != "" {
+		subdir = path.Clean(strings.TrimSuffix(subdir, "/"))
+		if !fs.ValidPath(subdir) || subdir == "." {
+			return nil, fmt.Errorf("invalid subdirectory %q of the source %s", subdir, Redact(archiveUrl))
+		}
+	}
+
+	afs := utils.FS(ctx)
+	parent := filepath.Dir(destDir)
+	if err := afs.MkdirAll(parent, 0755); err != nil {
+		return nil, err
+	}
+
+	tmp, err := afero.TempDir(afs, parent, "archive-")
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = afs.RemoveAll(tmp)
+	}()
+
+	downloadDir := filepath.Join(tmp, "download")
+	m, err := download(ctx, downloadDir, archiveUrl, showMsg)
+	if err != nil {
+		return m, err
+	}
+
+	// go-getter names the downloaded file after the last path segment
+	u, err := url.Parse(strings.TrimPrefix(archiveUrl, getterPrefix.FindString(archiveUrl)))
+	if err != nil {
+		return m, err
+	}
+	archiveFile := filepath.Join(downloadDir, path.Base(u.Path))
+
+	if subdir == "" {
+		return m, extractArchive(ctx, archiveFile, format, destDir)
+	}
+
+	extractDir := filepath.Join(tmp, "extracted")
+	if err := extractArchive(ctx, archiveFile, format, extractDir); err != nil {
+		return m, err
+	}
+
+	if _, err := afs.Stat(filepath.Join(extractDir, filepath.FromSlash(subdir))); err != nil {
+		return m, fmt.Errorf("the subdirectory %s was not found in the source %s", subdir, Redact(archiveUrl))
+	}
+
+	log.Debugf("Copying %s from %s to %s", subdir, Redact(archiveUrl), destDir)
+	return m, copyTree(afero.NewIOFS(afero.NewBasePathFs(afs, extractDir)), subdir, afs, destDir)
+}
+
+// archiveExtractor writes the files of an archive into the destination
+// directory, making sure no file is written outside of it and the limits are
+// not exceeded
+type archiveExtractor struct {
+	fs      afero.Fs
+	destDir string
+	limits  ArchiveLimits
+	bytes   int64
+	files   int
+}
+
+// target returns the path within the destination directory for the name of
+// the archive entry, names that refer to a parent directory are rejected.
+// Absolute names are taken as relative to the destination directory, as tar
+// does, OPA bundles built by opa build have names like /data.json.
+func (x *archiveExtractor) target(name string) (string, error) {
+	clean := path.Clean(strings.TrimLeft(strings.TrimPrefix(filepath.ToSlash(name), "./"), "/"))
+	if clean == "." {
+		return x.destDir, nil
+	}
+
+	if !fs.ValidPath(clean) || strings.Contains(clean, `\`) {
+		return "", fmt.Errorf("the archive entry %q is outside of the destination directory", name)
+	}
+
+	return filepath.Join(x.destDir, filepath.FromSlash(clean)), nil
+}
+
+func (x *archiveExtractor) dir(name string) error {
+	target, err := x.target(name)
+	if err != nil {
+		return err
+	}
+
+	return x.fs.MkdirAll(target, 0755)
+}
+
+func (x *archiveExtractor) file(name string, mode fs.FileMode, r io.Reader) error {
+	target, err := x.target(name)
+	if err != nil {
+		return err
+	}
+
+	x.files++
+	if x.files > x.limits.MaxFiles {
+		return fmt.Errorf("%w: more than %d files", ErrArchiveTooLarge, x.limits.MaxFiles)
+	}
+
+	if err := x.fs.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+
+	f, err := x.fs.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode.Perm()|0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	// the sizes recorded in the archive can't be trusted, so the content is
+	// read up to the remaining limit
+	remaining := x.limits.MaxBytes - x.bytes
+	n, err := io.Copy(f, io.LimitReader(r, remaining+1))
+	x.bytes += n
+	if err != nil {
+		return err
+	}
+
+	if n > remaining {
+		return fmt.Errorf("%w: more than %d bytes", ErrArchiveTooLarge, x.limits.MaxBytes)
+	}
+
+	return nil
+}
+
+// extractArchive extracts the archive file of the given format into the
+// destination directory. Only directories and regular files are extracted,
+// links are skipped.
+func extractArchive(ctx context.Context, archiveFile, format, destDir string) error {
+	afs := utils.FS(ctx)
+	x := archiveExtractor{fs: afs, destDir: destDir, limits: archiveLimits(ctx)}
+
+	if err := afs.MkdirAll(destDir, 0755); err != nil {
+		return err
+	}
+
+	f, err := afs.Open(archiveFile)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	log.Debugf("Extracting %s to %s", archiveFile, destDir)
+
+	switch format {
+	case tarGz:
+		err = extractTarGz(&x, f)
+	case zipFormat:
+		err = extractZip(&x, f)
+	default:
+		err = fmt.Errorf("unsupported archive format %q", format)
+	}
+
+	if err != nil {
+		return fmt.Errorf("extracting %s: %w", filepath.Base(archiveFile), err)
+	}
+
+	return nil
+}
+
+func extractTarGz(x *archiveExtractor, r io.Reader) error {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		h, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		switch h.Typeflag {
+		case tar.TypeDir:
+			err = x.dir(h.Name)
+		case tar.TypeReg:
+			err = x.file(h.Name, h.FileInfo().Mode(), tr)
+		default:
+			log.Debugf("Skipping the archive entry %q of type %c", h.Name, h.Typeflag)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func extractZip(x *archiveExtractor, f afero.File) error {
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	zr, err := zip.NewReader(f, info.Size())
+	if err != nil {
+		return err
+	}
+
+	for _, e := range zr.File {
+		mode := e.Mode()
+		switch {
+		case mode.IsDir():
+			err = x.dir(e.Name)
+		case mode.IsRegular():
+			err = extractZipFile(x, e)
+		default:
+			log.Debugf("Skipping the archive entry %q of mode %s", e.Name, mode)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func extractZipFile(x *archiveExtractor, e *zip.File) error {
+	r, err := e.Open()
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	return x.file(e.Name, e.Mode(), r)
+}
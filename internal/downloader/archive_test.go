@@ -0,0 +1,286 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package downloader
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+type archiveEntry struct {
+	name    string
+	content string
+	link    string
+}
+
+func tarGzArchive(t *testing.T, entries ...archiveEntry) []byte {
+	buf := bytes.Buffer{}
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+
+	for _, e := range entries {
+		h := tar.Header{Name: e.name, Mode: 0644, Size: int64(len(e.content)), Typeflag: tar.TypeReg}
+		if e.link != "" {
+			h = tar.Header{Name: e.name, Linkname: e.link, Typeflag: tar.TypeSymlink}
+		}
+		require.NoError(t, tw.WriteHeader(&h))
+		_, err := tw.Write([]byte(e.content))
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, tw.Close())
+	require.NoError(t, gz.Close())
+
+	return buf.Bytes()
+}
+
+func zipArchive(t *testing.T, entries ...archiveEntry) []byte {
+	buf := bytes.Buffer{}
+	zw := zip.NewWriter(&buf)
+
+	for _, e := range entries {
+		w, err := zw.Create(e.name)
+		require.NoError(t, err)
+		_, err = w.Write([]byte(e.content))
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, zw.Close())
+
+	return buf.Bytes()
+}
+
+// archiveDownloader writes the archive into the destination directory the
+// way go-getter does with the archive=false query parameter
+func archiveDownloader(fs afero.Fs, name string, archive []byte) *mockDownloader {
+	d := mockDownloader{}
+	d.On("Download", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		dest := args.String(1)
+		if err := afero.WriteFile(fs, path.Join(dest, name), archive, 0644); err != nil {
+			panic(err)
+		}
+	}).Return(nil)
+
+	return &d
+}
+
+func TestSplitArchive(t *testing.T) {
+	cases := []struct {
+		source  string
+		url     string
+		format  string
+		subdir  string
+		archive bool
+	}{
+		{source: "https://example.com/policy.tar.gz", url: "https://example.com/policy.tar.gz?archive=false", format: "tar.gz", archive: true},
+		{source: "https://example.com/policy.tgz?token=x", url: "https://example.com/policy.tgz?archive=false&token=x", format: "tar.gz", archive: true},
+		{source: "https://example.com/policy.zip//release", url: "https://example.com/policy.zip?archive=false", format: "zip", subdir: "release", archive: true},
+		{source: "https://example.com/policy.tar.gz?archive=tar.gz"},
+		{source: "https://example.com/policy.rego"},
+		{source: "git::https://github.com/org/repo.zip"},
+		{source: "oci::registry.io/org/policy.tar.gz"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.source, func(t *testing.T) {
+			url, format, subdir, ok := splitArchive(c.source)
+			assert.Equal(t, c.archive, ok)
+			assert.Equal(t, c.url, url)
+			assert.Equal(t, c.format, format)
+			assert.Equal(t, c.subdir, subdir)
+		})
+	}
+}
+
+func TestDownloadArchive(t *testing.T) {
+	t.Setenv("USEGOGATHER", "")
+
+	entries := []archiveEntry{
+		{name: "bundle/policy/main.rego", content: "package main"},
+		{name: "./bundle/data/data.json", content: "{}"},
+		{name: "bundle/link", link: "/etc/passwd"},
+	}
+
+	cases := []struct {
+		name    string
+		source  string
+		file    string
+		archive []byte
+		files   map[string]string
+	}{
+		{
+			name:    "tar.gz",
+			source:  "https://example.com/bundle.tar.gz",
+			file:    "bundle.tar.gz",
+			archive: tarGzArchive(t, entries...),
+			files: map[string]string{
+				"/dest/bundle/policy/main.rego": "package main",
+				"/dest/bundle/data/data.json":   "{}",
+			},
+		},
+		{
+			name:    "zip",
+			source:  "https://example.com/bundle.zip",
+			file:    "bundle.zip",
+			archive: zipArchive(t, entries[:2]...),
+			files: map[string]string{
+				"/dest/bundle/policy/main.rego": "package main",
+				"/dest/bundle/data/data.json":   "{}",
+			},
+		},
+		{
+			name:   "OPA bundle",
+			source: "https://example.com/bundle.tar.gz",
+			file:   "bundle.tar.gz",
+			// opa build names the entries with a leading slash
+			archive: tarGzArchive(t,
+				archiveEntry{name: "/data.json", content: "{}"},
+				archiveEntry{name: "/policy/main.rego", content: "package main"},
+				archiveEntry{name: "/etc/evil.rego", content: "package evil"},
+			),
+			files: map[string]string{
+				"/dest/data.json":        "{}",
+				"/dest/policy/main.rego": "package main",
+				"/dest/etc/evil.rego":    "package evil",
+			},
+		},
+		{
+			name:    "subdirectory",
+			source:  "https://example.com/bundle.tar.gz//bundle/policy",
+			file:    "bundle.tar.gz",
+			archive: tarGzArchive(t, entries...),
+			files: map[string]string{
+				"/dest/main.rego": "package main",
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			ctx := utils.WithFS(context.Background(), fs)
+			ctx = WithDownloadImpl(ctx, archiveDownloader(fs, c.file, c.archive))
+
+			_, err := Download(ctx, "/dest", c.source, false)
+			require.NoError(t, err)
+
+			files := map[string]string{}
+			require.NoError(t, afero.Walk(fs, "/dest", func(p string, info os.FileInfo, err error) error {
+				if err == nil && !info.IsDir() {
+					content, err := afero.ReadFile(fs, p)
+					files[p] = string(content)
+					return err
+				}
+				return err
+			}))
+			assert.Equal(t, c.files, files)
+
+			// the temporary directory is removed
+			leftovers, err := afero.Glob(fs, "/archive-*")
+			require.NoError(t, err)
+			assert.Empty(t, leftovers)
+		})
+	}
+}
+
+func TestDownloadArchiveUnsafe(t *testing.T) {
+	t.Setenv("USEGOGATHER", "")
+
+	cases := []struct {
+		name    string
+		archive func(*testing.T) []byte
+		limits  ArchiveLimits
+		err     string
+	}{
+		{
+			name: "parent directory",
+			archive: func(t *testing.T) []byte {
+				return tarGzArchive(t, archiveEntry{name: "../../evil.rego", content: "package evil"})
+			},
+			err: `extracting bundle.tar.gz: the archive entry "../../evil.rego" is outside of the destination directory`,
+		},
+		{
+			name: "nested parent directory",
+			archive: func(t *testing.T) []byte {
+				return tarGzArchive(t, archiveEntry{name: "policy/../../evil.rego", content: "package evil"})
+			},
+			err: `extracting bundle.tar.gz: the archive entry "policy/../../evil.rego" is outside of the destination directory`,
+		},
+		{
+			name: "absolute parent directory",
+			archive: func(t *testing.T) []byte {
+				return tarGzArchive(t, archiveEntry{name: "/../etc/evil.rego", content: "package evil"})
+			},
+			err: `extracting bundle.tar.gz: the archive entry "/../etc/evil.rego" is outside of the destination directory`,
+		},
+		{
+			name: "too many bytes",
+			archive: func(t *testing.T) []byte {
+				return tarGzArchive(t, archiveEntry{name: "a", content: "123"}, archiveEntry{name: "b", content: "456"})
+			},
+			limits: ArchiveLimits{MaxBytes: 5},
+			err:    "extracting bundle.tar.gz: archive too large: more than 5 bytes",
+		},
+		{
+			name: "too many files",
+			archive: func(t *testing.T) []byte {
+				return tarGzArchive(t, archiveEntry{name: "a"}, archiveEntry{name: "b"}, archiveEntry{name: "c"})
+			},
+			limits: ArchiveLimits{MaxFiles: 2},
+			err:    "extracting bundle.tar.gz: archive too large: more than 2 files",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			ctx := utils.WithFS(context.Background(), fs)
+			ctx = WithDownloadImpl(ctx, archiveDownloader(fs, "bundle.tar.gz", c.archive(t)))
+			ctx = WithArchiveLimits(ctx, c.limits)
+
+			_, err := Download(ctx, "/work/dest", "https://example.com/bundle.tar.gz", false)
+			assert.EqualError(t, err, c.err)
+
+			exists, err := afero.Exists(fs, "/work/evil.rego")
+			require.NoError(t, err)
+			assert.False(t, exists)
+			exists, err = afero.Exists(fs, "/etc/evil.rego")
+			require.NoError(t, err)
+			assert.False(t, exists)
+		})
+	}
+}
+
+func TestArchiveLimits(t *testing.T) {
+	assert.Equal(t, ArchiveLimits{MaxBytes: DefaultMaxArchiveBytes, MaxFiles: DefaultMaxArchiveFiles}, archiveLimits(context.Background()))
+	assert.Equal(t, ArchiveLimits{MaxBytes: 10, MaxFiles: DefaultMaxArchiveFiles}, archiveLimits(WithArchiveLimits(context.Background(), ArchiveLimits{MaxBytes: 10})))
+}
@@ -0,0 +1,102 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package downloader
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync/atomic"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+)
+
+const downloadBudgetKey key = 1
+
+// ErrDownloadBudgetExceeded is returned when the total number of bytes
+// downloaded exceeds the budget
+var ErrDownloadBudgetExceeded = errors.New("download budget exceeded")
+
+// budget accounts for the number of bytes downloaded, it is shared by all
+// downloads performed with the same context
+type budget struct {
+	limit int64
+	used  atomic.Int64
+}
+
+// WithDownloadBudget returns a context limiting the total number of bytes
+// downloaded using it, or any context derived from it, to the given limit.
+// Once the limit is exceeded all further downloads fail with
+// ErrDownloadBudgetExceeded. Downloads already in progress are not
+// interrupted, so the limit can be exceeded by the downloads running
+// concurrently.
+func WithDownloadBudget(ctx context.Context, limit int64) context.Context {
+	return context.WithValue(ctx, downloadBudgetKey, &budget{limit: limit})
+}
+
+func downloadBudget(ctx context.Context) *budget {
+	if b, ok := ctx.Value(downloadBudgetKey).(*budget); ok {
+		return b
+	}
+
+	return nil
+}
+
+// check returns an error if the budget has been exceeded
+func (b *budget) check() error {
+	if used := b.used.Load(); used > b.limit {
+		return fmt.Errorf("%w: downloaded %d bytes, the maximum is %d bytes", ErrDownloadBudgetExceeded, used, b.limit)
+	}
+
+	return nil
+}
+
+// add accounts for the given number of bytes and returns an error if the
+// budget has been exceeded
+func (b *budget) add(n int64) error {
+	b.used.Add(n)
+
+	return b.check()
+}
+
+// dirSize returns the total size of all files within the given directory, a
+// missing directory has the size of 0
+func dirSize(fs afero.Fs, dir string) (int64, error) {
+	var size int64
+	err := afero.Walk(fs, dir, func(_ string, info os.FileInfo, err error) error {
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+
+		if info.Mode().IsRegular() {
+			size += info.Size()
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		log.Debugf("Unable to determine the size of %s: %v", dir, err)
+	}
+
+	return size, err
+}
@@ -0,0 +1,124 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package downloader
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"path"
+	"sync"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+func writingDownloader(fs afero.Fs, size int) *mockDownloader {
+	d := mockDownloader{}
+	d.On("Download", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		dest := args.String(1)
+		if err := afero.WriteFile(fs, path.Join(dest, "content"), bytes.Repeat([]byte{'x'}, size), 0644); err != nil {
+			panic(err)
+		}
+	}).Return(nil)
+
+	return &d
+}
+
+func TestDownloadBudget(t *testing.T) {
+	t.Setenv("USEGOGATHER", "")
+
+	fs := afero.NewMemMapFs()
+	d := writingDownloader(fs, 40)
+
+	ctx := utils.WithFS(context.Background(), fs)
+	ctx = WithDownloadImpl(ctx, d)
+	ctx = WithDownloadBudget(ctx, 100)
+
+	for i := 0; i < 2; i++ {
+		_, err := Download(ctx, fmt.Sprintf("/dir/%d", i), "https://example.com/org/repo.git", false)
+		require.NoError(t, err)
+	}
+
+	// the third download exceeds the budget
+	_, err := Download(ctx, "/dir/2", "https://example.com/org/repo.git", false)
+	assert.ErrorIs(t, err, ErrDownloadBudgetExceeded)
+	assert.EqualError(t, err, "downloading https://example.com/org/repo.git: download budget exceeded: downloaded 120 bytes, the maximum is 100 bytes")
+
+	// no further downloads are attempted
+	_, err = Download(ctx, "/dir/3", "https://example.com/org/repo.git", false)
+	assert.ErrorIs(t, err, ErrDownloadBudgetExceeded)
+	d.AssertNumberOfCalls(t, "Download", 3)
+}
+
+func TestDownloadBudgetExistingContent(t *testing.T) {
+	t.Setenv("USEGOGATHER", "")
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/dir/existing", bytes.Repeat([]byte{'x'}, 200), 0644))
+
+	ctx := utils.WithFS(context.Background(), fs)
+	ctx = WithDownloadImpl(ctx, writingDownloader(fs, 40))
+	ctx = WithDownloadBudget(ctx, 100)
+
+	// only the downloaded content is accounted for
+	_, err := Download(ctx, "/dir", "https://example.com/org/repo.git", false)
+	assert.NoError(t, err)
+}
+
+func TestDownloadBudgetConcurrent(t *testing.T) {
+	t.Setenv("USEGOGATHER", "")
+
+	fs := afero.NewMemMapFs()
+	d := writingDownloader(fs, 10)
+
+	ctx := utils.WithFS(context.Background(), fs)
+	ctx = WithDownloadImpl(ctx, d)
+	ctx = WithDownloadBudget(ctx, 55)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 20)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, err := Download(ctx, fmt.Sprintf("/dir/%d", i), "https://example.com/org/repo.git", false)
+			errs <- err
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+
+	succeeded := 0
+	for err := range errs {
+		if err == nil {
+			succeeded++
+		} else {
+			assert.ErrorIs(t, err, ErrDownloadBudgetExceeded)
+		}
+	}
+
+	assert.Equal(t, 5, succeeded)
+	assert.Equal(t, int64(len(d.Calls)*10), downloadBudget(ctx).used.Load())
+}
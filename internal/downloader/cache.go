@@ -0,0 +1,238 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package downloader
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/open-policy-agent/conftest/downloader"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+)
+
+const downloadCacheKey key = 5
+
+// DefaultCacheTTL is the default duration for which cached sources are used
+// before downloading them again
+const DefaultCacheTTL = time.Hour
+
+// cacheMarker is the file recording when a source was cached, its presence
+// denotes a complete cache entry
+const cacheMarker = ".ec-cached"
+
+// cache is a directory holding the downloaded sources, each in a directory
+// named by the digest of the source URL
+type cache struct {
+	dir     string
+	ttl     time.Duration
+	mutable bool
+}
+
+// WithCache returns a context under which downloaded sources are stored in
+// the given directory and, for the given duration, used instead of
+// downloading the same source again. Sources are keyed by the source URL, so
+// only sources pinned to an immutable reference, a git commit or an OCI
+// digest, are cached unless mutable is true. Mutable references, e.g. git
+// branches and tags or OCI tags, can change upstream and their cached copies
+// would be stale. Local files are never cached. In offline mode any cached
+// copy is used, see WithOffline.
+func WithCache(ctx context.Context, dir string, ttl time.Duration, mutable bool) context.Context {
+	return context.WithValue(ctx, downloadCacheKey, &cache{dir: dir, ttl: ttl, mutable: mutable})
+}
+
+func downloadCache(ctx context.Context) *cache {
+	if c, ok := ctx.Value(downloadCacheKey).(*cache); ok {
+		return c
+	}
+
+	return nil
+}
+
+// DefaultCacheDir returns the directory where sources are cached by default,
+// that is ec/sources within $XDG_CACHE_HOME, or the platform specific user
+// cache directory
+func DefaultCacheDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "ec", "sources"), nil
+}
+
+// isRemote returns true for sources fetched over the network, only those are
+// cached, local files are always read directly
+func isRemote(sourceUrl string) bool {
+	detected, err := downloader.Detect(sourceUrl, "")
+	if err != nil {
+		return false
+	}
+
+	return !strings.HasPrefix(detected, "file::") && !strings.HasPrefix(detected, "file://")
+}
+
+// commitRef matches the ref query parameter set to a full git commit id, of
+// SHA-1 or SHA-256
+var commitRef = regexp.MustCompile(`[?&]ref=([0-9a-fA-F]{40}|[0-9a-fA-F]{64})(&|$)`)
+
+// isPinned returns true for sources pinned to an immutable reference: git
+// sources referencing a commit and OCI sources referencing a digest
+func isPinned(sourceUrl string) bool {
+	if ref, ok := ociReference(sourceUrl); ok {
+		return strings.Contains(ref, "@sha256:")
+	}
+
+	return commitRef.MatchString(sourceUrl)
+}
+
+// cacheable returns true if the source is cached
+func (c *cache) cacheable(sourceUrl string) bool {
+	return c.mutable || isPinned(sourceUrl)
+}
+
+func (c *cache) entry(sourceUrl string) string {
+	return filepath.Join(c.dir, fmt.Sprintf("%x", sha256.Sum256([]byte(sourceUrl))))
+}
+
+// complete returns true if the cache entry was completely written
+func (c *cache) complete(fs afero.Fs, entry string) bool {
+	_, err := fs.Stat(filepath.Join(entry, cacheMarker))
+	return err == nil
+}
+
+// fresh returns true if the cache entry is complete and within the TTL
+func (c *cache) fresh(fs afero.Fs, entry string) bool {
+	info, err := fs.Stat(filepath.Join(entry, cacheMarker))
+	if err != nil {
+		return false
+	}
+
+	return time.Since(info.ModTime()) < c.ttl
+}
+
+// load copies the source from the cache into the destination directory,
+// returning false if the source is not cached, is not cacheable or the cached
+// copy has expired. Any cached copy, even of mutable sources and expired, is
+// used if the offline argument is true.
+func (c *cache) load(fs afero.Fs, sourceUrl, destDir string, offline bool) (bool, error) {
+	entry := c.entry(sourceUrl)
+	if offline {
+		if !c.complete(fs, entry) {
+			return false, nil
+		}
+	} else if !c.cacheable(sourceUrl) || !c.fresh(fs, entry) {
+		return false, nil
+	}
+
+	if info, err := fs.Stat(filepath.Join(entry, cacheMarker)); err == nil {
+		log.Infof("Using the copy of %s cached at %s", Redact(sourceUrl), info.ModTime().UTC().Format(time.RFC3339))
+	}
+	log.Debugf("Download cache hit: %s, copying from %s", Redact(sourceUrl), entry)
+	if err := copyTree(afero.NewIOFS(afero.NewBasePathFs(fs, entry)), ".", fs, destDir); err != nil {
+		return false, err
+	}
+
+	// the marker is part of the cache entry, not of the source
+	if err := fs.Remove(filepath.Join(destDir, cacheMarker)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// store copies the downloaded source from the destination directory into the
+// cache, if the source is cacheable. The copy is made into a temporary directory which then replaces the
+// cache entry, so a cache entry is never partially written.
+func (c *cache) store(fs afero.Fs, sourceUrl, destDir string) error {
+	if !c.cacheable(sourceUrl) {
+		log.Debugf("Not caching %s, it's not pinned to a commit or a digest", Redact(sourceUrl))
+		return nil
+	}
+
+	// nothing was downloaded
+	if exists, err := afero.DirExists(fs, destDir); err != nil || !exists {
+		return err
+	}
+
+	if err := fs.MkdirAll(c.dir, 0755); err != nil {
+		return err
+	}
+
+	tmp, err := afero.TempDir(fs, c.dir, "tmp-")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = fs.RemoveAll(tmp)
+	}()
+
+	if err := copyTree(afero.NewIOFS(afero.NewBasePathFs(fs, destDir)), ".", fs, tmp); err != nil {
+		return err
+	}
+
+	if err := afero.WriteFile(fs, filepath.Join(tmp, cacheMarker), []byte(sourceUrl), 0644); err != nil {
+		return err
+	}
+
+	entry := c.entry(sourceUrl)
+	if err := fs.RemoveAll(entry); err != nil {
+		return err
+	}
+
+	log.Debugf("Storing %s in the download cache at %s", Redact(sourceUrl), entry)
+	return fs.Rename(tmp, entry)
+}
+
+// PruneCache removes the cached sources that were cached longer than the TTL
+// ago, and any leftovers of incomplete cache entries
+func PruneCache(fs afero.Fs, dir string, ttl time.Duration) error {
+	entries, err := afero.ReadDir(fs, dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+
+	c := cache{dir: dir, ttl: ttl}
+	for _, e := range entries {
+		entry := filepath.Join(dir, e.Name())
+		if c.fresh(fs, entry) {
+			continue
+		}
+
+		// cache entry being written
+		if strings.HasPrefix(e.Name(), "tmp-") && time.Since(e.ModTime()) < ttl {
+			continue
+		}
+
+		log.Debugf("Pruning download cache entry %s", entry)
+		if err := fs.RemoveAll(entry); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,177 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package downloader
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+func TestDownloadCache(t *testing.T) {
+	source := "git::https://example.com/org/repo.git?ref=v1"
+
+	fs := afero.NewMemMapFs()
+	d := mockDownloader{}
+	ctx := utils.WithFS(context.Background(), fs)
+	ctx = WithCache(ctx, "/cache", time.Hour, true)
+	ctx = WithDownloadImpl(ctx, &d)
+
+	d.On("Download", ctx, mock.Anything, []string{source}).Run(func(args mock.Arguments) {
+		dest := args.String(1)
+		require.NoError(t, afero.WriteFile(fs, filepath.Join(dest, "policy", "main.rego"), []byte("package main"), 0644))
+	}).Return(nil).Once()
+
+	_, err := Download(ctx, "/work/1", source, false)
+	require.NoError(t, err)
+
+	// the second download is served from the cache
+	_, err = Download(ctx, "/work/2", source, false)
+	require.NoError(t, err)
+	d.AssertNumberOfCalls(t, "Download", 1)
+
+	content, err := afero.ReadFile(fs, "/work/2/policy/main.rego")
+	require.NoError(t, err)
+	assert.Equal(t, "package main", string(content))
+
+	exists, err := afero.Exists(fs, filepath.Join("/work/2", cacheMarker))
+	require.NoError(t, err)
+	assert.False(t, exists, "the cache marker should not be copied into the destination")
+
+	// other sources are not served from the cache
+	other := "git::https://example.com/org/repo.git?ref=v2"
+	d.On("Download", ctx, "/work/3", []string{other}).Return(nil).Once()
+	_, err = Download(ctx, "/work/3", other, false)
+	require.NoError(t, err)
+	d.AssertNumberOfCalls(t, "Download", 2)
+}
+
+func TestDownloadCacheExpired(t *testing.T) {
+	source := "git::https://example.com/org/repo.git"
+
+	fs := afero.NewMemMapFs()
+	d := mockDownloader{}
+	ctx := utils.WithFS(context.Background(), fs)
+	ctx = WithCache(ctx, "/cache", time.Hour, true)
+	ctx = WithDownloadImpl(ctx, &d)
+
+	d.On("Download", ctx, mock.Anything, []string{source}).Run(func(args mock.Arguments) {
+		require.NoError(t, afero.WriteFile(fs, filepath.Join(args.String(1), "main.rego"), []byte("package main"), 0644))
+	}).Return(nil)
+
+	_, err := Download(ctx, "/work/1", source, false)
+	require.NoError(t, err)
+
+	// cached two hours ago
+	c := downloadCache(ctx)
+	past := time.Now().Add(-2 * time.Hour)
+	require.NoError(t, fs.Chtimes(filepath.Join(c.entry(source), cacheMarker), past, past))
+
+	_, err = Download(ctx, "/work/2", source, false)
+	require.NoError(t, err)
+	d.AssertNumberOfCalls(t, "Download", 2)
+}
+
+func TestDownloadCachePinnedSources(t *testing.T) {
+	commit := "6c2dbc6f0b9cc8ed5c62e46ab4fad5b3d1ab3a31"
+	digest := "sha256:4e388ab32b10dc8dbc7e28144f552830adc74787c1e2c0824032078a79f227fb"
+
+	cases := []struct {
+		source string
+		cached bool
+	}{
+		{source: "git::https://example.com/org/repo.git?ref=" + commit, cached: true},
+		{source: "git::https://example.com/org/repo.git//policy?ref=" + commit + "&depth=1", cached: true},
+		{source: "oci::quay.io/org/policy@" + digest, cached: true},
+		{source: "quay.io/org/policy:v1@" + digest, cached: true},
+		{source: "git::https://example.com/org/repo.git?ref=main"},
+		{source: "git::https://example.com/org/repo.git?ref=" + commit[:7]},
+		{source: "git::https://example.com/org/repo.git"},
+		{source: "oci::quay.io/org/policy:v1"},
+		{source: "https://example.com/policy/main.rego"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.source, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			d := mockDownloader{}
+			ctx := utils.WithFS(context.Background(), fs)
+			ctx = WithCache(ctx, "/cache", time.Hour, false)
+			ctx = WithDownloadImpl(ctx, &d)
+
+			d.On("Download", ctx, mock.Anything, []string{c.source}).Run(func(args mock.Arguments) {
+				require.NoError(t, afero.WriteFile(fs, filepath.Join(args.String(1), "main.rego"), []byte("package main"), 0644))
+			}).Return(nil)
+
+			_, err := Download(ctx, "/work/1", c.source, false)
+			require.NoError(t, err)
+			_, err = Download(ctx, "/work/2", c.source, false)
+			require.NoError(t, err)
+
+			// mutable sources are downloaded each time
+			if c.cached {
+				d.AssertNumberOfCalls(t, "Download", 1)
+			} else {
+				d.AssertNumberOfCalls(t, "Download", 2)
+			}
+		})
+	}
+}
+
+func TestDownloadCacheLocalFiles(t *testing.T) {
+	dir := t.TempDir()
+
+	assert.False(t, isRemote(dir))
+	assert.False(t, isRemote("file::"+dir))
+	assert.True(t, isRemote("git::https://example.com/org/repo.git"))
+	assert.True(t, isRemote("oci::quay.io/org/policy:latest"))
+	assert.True(t, isRemote("github.com/org/repo"))
+}
+
+func TestPruneCache(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	c := cache{dir: "/cache", ttl: time.Hour, mutable: true}
+
+	for _, s := range []string{"fresh", "stale"} {
+		require.NoError(t, afero.WriteFile(fs, filepath.Join("/src", s, "file"), []byte(s), 0644))
+		require.NoError(t, c.store(fs, s, filepath.Join("/src", s)))
+	}
+	past := time.Now().Add(-2 * time.Hour)
+	require.NoError(t, fs.Chtimes(filepath.Join(c.entry("stale"), cacheMarker), past, past))
+
+	// incomplete entry
+	require.NoError(t, fs.MkdirAll("/cache/incomplete", 0755))
+
+	require.NoError(t, PruneCache(fs, "/cache", time.Hour))
+
+	entries, err := afero.ReadDir(fs, "/cache")
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, filepath.Base(c.entry("fresh")), entries[0].Name())
+
+	assert.NoError(t, PruneCache(fs, "/missing", time.Hour))
+}
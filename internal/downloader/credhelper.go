@@ -0,0 +1,234 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package downloader
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+	"sync"
+
+	getter "github.com/hashicorp/go-getter"
+	"github.com/open-policy-agent/conftest/downloader"
+	log "github.com/sirupsen/logrus"
+)
+
+const credentialHelpersKey key = 19
+
+// credentialHelperSchemes are the schemes of the sources credential helpers
+// can provide the credentials for
+var credentialHelperSchemes = []string{"gcs", "git", "s3"}
+
+// errCredentialsNotFound is the message the Docker credential helpers output
+// when they hold no credentials for the server
+const errCredentialsNotFound = "credentials not found"
+
+// gcsTokenEnv is the environment variable go-getter reads the OAuth access
+// token, used to access GCS, from
+const gcsTokenEnv = "GOOGLE_OAUTH_ACCESS_TOKEN"
+
+// gcsMutex serializes the GCS downloads using the credentials provided by a
+// credential helper, as go-getter reads them from the environment
+var gcsMutex sync.Mutex
+
+// CredentialHelpers holds the credential helpers, i.e. the external programs
+// providing the credentials, by the scheme of the sources
+type CredentialHelpers map[string]string
+
+// Credentials are the credentials output by a credential helper. The format
+// is the one of the Docker credential helpers, with the optional session
+// token of temporary AWS credentials added.
+type Credentials struct {
+	ServerURL    string
+	Username     string
+	Secret       string
+	SessionToken string `json:",omitempty"`
+}
+
+// ParseCredentialHelper parses the credential helper given as
+// `<scheme>=<helper>`, e.g. `s3=/usr/local/bin/vault-aws-credentials`, into
+// the credential helpers
+func (h CredentialHelpers) ParseCredentialHelper(s string) error {
+	scheme, helper, ok := strings.Cut(s, "=")
+	if !ok || scheme == "" || helper == "" {
+		return fmt.Errorf("invalid credential helper %q, expected <scheme>=<helper>", s)
+	}
+
+	scheme = normalizeScheme(scheme)
+	if scheme == "oci" {
+		return errors.New("the credential helpers for OCI registries are configured via credHelpers in the Docker configuration file")
+	}
+
+	if !slices.Contains(credentialHelperSchemes, scheme) {
+		return fmt.Errorf("credential helpers are not supported for the %s scheme, supported schemes are: %s", scheme, strings.Join(credentialHelperSchemes, ", "))
+	}
+
+	h[scheme] = helper
+
+	return nil
+}
+
+// WithCredentialHelpers returns a context under which the credentials used to
+// download the sources are requested from the credential helpers, for sources
+// that don't already include credentials in their URL. The credentials
+// requested are never cached so the helpers can provide short lived
+// credentials.
+func WithCredentialHelpers(ctx context.Context, helpers CredentialHelpers) context.Context {
+	return context.WithValue(ctx, credentialHelpersKey, helpers)
+}
+
+func credentialHelpers(ctx context.Context) CredentialHelpers {
+	if h, ok := ctx.Value(credentialHelpersKey).(CredentialHelpers); ok {
+		return h
+	}
+
+	return nil
+}
+
+// runCredentialHelper runs the credential helper following the protocol of
+// the Docker credential helpers: the helper is invoked with the `get` argument
+// and the server URL on the standard input, and outputs the credentials as
+// JSON on the standard output
+var runCredentialHelper = func(ctx context.Context, helper, serverURL string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, helper, "get")
+	cmd.Stdin = strings.NewReader(serverURL)
+	stderr := bytes.Buffer{}
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(string(out) + " " + stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%w: %s", err, msg)
+		}
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// helperCredentials returns the credentials for the server provided by the
+// credential helper, or nil if the helper has no credentials for the server
+func helperCredentials(ctx context.Context, helper, serverURL string) (*Credentials, error) {
+	log.Debugf("Requesting the credentials for %s from the credential helper %s", serverURL, helper)
+	out, err := runCredentialHelper(ctx, helper, serverURL)
+	if err != nil {
+		if strings.Contains(err.Error(), errCredentialsNotFound) {
+			log.Debugf("The credential helper %s has no credentials for %s", helper, serverURL)
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var creds Credentials
+	if err := json.Unmarshal(out, &creds); err != nil {
+		return nil, fmt.Errorf("unable to parse the output: %w", err)
+	}
+
+	if creds.Secret == "" {
+		return nil, nil
+	}
+
+	return &creds, nil
+}
+
+// withHelperCredentials returns the source URL with the credentials provided
+// by the credential helper for the scheme of the source set, if one is
+// configured and the source URL doesn't include any credentials already. Git
+// sources accessed over HTTPS get the credentials set as the userinfo and S3
+// sources as the aws_* query parameters. For GCS sources the access token is
+// set in the environment until the returned function is called.
+func withHelperCredentials(ctx context.Context, sourceUrl string) (string, func(), error) {
+	noop := func() {}
+
+	helpers := credentialHelpers(ctx)
+	if len(helpers) == 0 {
+		return sourceUrl, noop, nil
+	}
+
+	scheme := sourceScheme(sourceUrl)
+	helper, ok := helpers[scheme]
+	if !ok {
+		return sourceUrl, noop, nil
+	}
+
+	detected, err := downloader.Detect(sourceUrl, "")
+	if err != nil {
+		return sourceUrl, noop, nil
+	}
+
+	prefix := getterPrefix.FindString(detected)
+	u, err := url.Parse(detected[len(prefix):])
+	if err != nil || u.User != nil || u.Scheme != "https" {
+		return sourceUrl, noop, nil
+	}
+
+	q := u.Query()
+	if q.Has("aws_access_key_id") || q.Has("aws_profile") {
+		return sourceUrl, noop, nil
+	}
+
+	// the server URL excludes the subdirectory and the query parameters
+	serverURL, _ := getter.SourceDirSubdir((&url.URL{Scheme: u.Scheme, Host: u.Host, Path: u.Path}).String())
+	creds, err := helperCredentials(ctx, helper, serverURL)
+	if err != nil {
+		return "", nil, fmt.Errorf("requesting the credentials for %s from the credential helper %s: %w", serverURL, helper, err)
+	}
+	if creds == nil {
+		return sourceUrl, noop, nil
+	}
+
+	switch scheme {
+	case "git":
+		username := creds.Username
+		if username == "" {
+			username = gitUsername(u.Hostname())
+		}
+		u.User = url.UserPassword(username, creds.Secret)
+	case "s3":
+		q.Set("aws_access_key_id", creds.Username)
+		q.Set("aws_access_key_secret", creds.Secret)
+		if creds.SessionToken != "" {
+			q.Set("aws_access_token", creds.SessionToken)
+		}
+		u.RawQuery = q.Encode()
+	case "gcs":
+		gcsMutex.Lock()
+		previous, set := os.LookupEnv(gcsTokenEnv)
+		if err := os.Setenv(gcsTokenEnv, creds.Secret); err != nil {
+			gcsMutex.Unlock()
+			return "", nil, err
+		}
+
+		return sourceUrl, func() {
+			if set {
+				_ = os.Setenv(gcsTokenEnv, previous)
+			} else {
+				_ = os.Unsetenv(gcsTokenEnv)
+			}
+			gcsMutex.Unlock()
+		}, nil
+	}
+
+	return prefix + u.String(), noop, nil
+}
@@ -0,0 +1,156 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package downloader
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/spf13/afero"
+)
+
+// digestParam is the query parameter of the source URL holding the expected
+// digest of the source, e.g. `github.com/org/repo//policy?ref=v1&digest=sha256:...`
+const digestParam = "digest"
+
+var validDigest = regexp.MustCompile("^sha256:[0-9a-f]{64}$")
+
+// splitDigest removes the digest parameter from the source URL, returning the
+// URL to download from and the expected digest, if any
+func splitDigest(sourceUrl string) (string, string, error) {
+	i := strings.LastIndex(sourceUrl, "?")
+	if i == -1 {
+		return sourceUrl, "", nil
+	}
+
+	// the subdirectory can follow the query, e.g. `repo.git?ref=v1//policy`
+	query, subdir := sourceUrl[i+1:], ""
+	if j := strings.Index(query, "//"); j != -1 {
+		query, subdir = query[:j], query[j:]
+	}
+
+	var digest string
+	params := make([]string, 0, 1)
+	for _, p := range strings.Split(query, "&") {
+		if k, v, _ := strings.Cut(p, "="); k == digestParam {
+			digest = v
+			continue
+		}
+		params = append(params, p)
+	}
+
+	if digest == "" {
+		return sourceUrl, "", nil
+	}
+
+	if !validDigest.MatchString(digest) {
+		return "", "", fmt.Errorf("invalid digest %q of the source %s, expected sha256:<hex>", digest, Redact(sourceUrl[:i]))
+	}
+
+	if len(params) == 0 {
+		return sourceUrl[:i] + subdir, digest, nil
+	}
+
+	return sourceUrl[:i] + "?" + strings.Join(params, "&") + subdir, digest, nil
+}
+
+// Digest computes the digest of the downloaded source in the given directory.
+// That is the sha256 of the lines `<sha256 of the file>  <path of the file>`,
+// as printed by sha256sum, for each of the files in the directory sorted by
+// path, ignoring the .git directory. Equivalent of:
+//
+//	find . -type f -not -path './.git/*' | sed 's|^\./||' | LC_ALL=C sort | xargs sha256sum | sha256sum
+func Digest(fs afero.Fs, dir string) (string, error) {
+	// the directory can be a link to a local directory, see WithLocalInPlace
+	if l, ok := fs.(afero.Symlinker); ok {
+		if info, _, err := l.LstatIfPossible(dir); err == nil && info.Mode()&os.ModeSymlink != 0 {
+			target, err := l.ReadlinkIfPossible(dir)
+			if err != nil {
+				return "", err
+			}
+			if !filepath.IsAbs(target) {
+				target = filepath.Join(filepath.Dir(dir), target)
+			}
+			dir = target
+		}
+	}
+
+	var files []string
+	if err := afero.Walk(fs, dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() && info.Name() == ".git" {
+			return filepath.SkipDir
+		}
+
+		if info.Mode().IsRegular() {
+			rel, err := filepath.Rel(dir, path)
+			if err != nil {
+				return err
+			}
+			files = append(files, filepath.ToSlash(rel))
+		}
+
+		return nil
+	}); err != nil {
+		return "", err
+	}
+
+	sort.Strings(files)
+
+	h := sha256.New()
+	for _, file := range files {
+		f, err := fs.Open(filepath.Join(dir, filepath.FromSlash(file)))
+		if err != nil {
+			return "", err
+		}
+
+		fh := sha256.New()
+		_, err = io.Copy(fh, f)
+		f.Close()
+		if err != nil {
+			return "", err
+		}
+
+		fmt.Fprintf(h, "%x  %s\n", fh.Sum(nil), file)
+	}
+
+	return fmt.Sprintf("sha256:%x", h.Sum(nil)), nil
+}
+
+// verifyDigest returns an error if the digest of the source downloaded into
+// the given directory doesn't match the expected digest
+func verifyDigest(fs afero.Fs, sourceUrl, destDir, expected string) error {
+	actual, err := Digest(fs, destDir)
+	if err != nil {
+		return fmt.Errorf("computing the digest of %s: %w", Redact(sourceUrl), err)
+	}
+
+	if actual != expected {
+		return fmt.Errorf("digest mismatch for %s, expected %s but the downloaded content has the digest %s", Redact(sourceUrl), expected, actual)
+	}
+
+	return nil
+}
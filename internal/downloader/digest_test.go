@@ -0,0 +1,116 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package downloader
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+const (
+	// sha256sum of "a" and "b"
+	sumA = "ca978112ca1bbdcafac231b39a23dc4da786eff8147c4e72b9807785afee48bb"
+	sumB = "3e23e8160039594a33894f6564e1b1348bbd7a0088d42c4acb73eeaed59c009d"
+)
+
+func writeSource(t *testing.T, fs afero.Fs, dir string) {
+	require.NoError(t, afero.WriteFile(fs, dir+"/main.rego", []byte("a"), 0644))
+	require.NoError(t, afero.WriteFile(fs, dir+"/lib/lib.rego", []byte("b"), 0644))
+	// ignored
+	require.NoError(t, afero.WriteFile(fs, dir+"/.git/HEAD", []byte("ref: refs/heads/main"), 0644))
+}
+
+func expectedDigest() string {
+	return fmt.Sprintf("sha256:%x", sha256.Sum256([]byte(sumB+"  lib/lib.rego\n"+sumA+"  main.rego\n")))
+}
+
+func TestDigest(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	writeSource(t, fs, "/src")
+
+	digest, err := Digest(fs, "/src")
+	require.NoError(t, err)
+	assert.Equal(t, expectedDigest(), digest)
+}
+
+func TestSplitDigest(t *testing.T) {
+	digest := "sha256:" + sumA
+
+	cases := []struct {
+		name   string
+		url    string
+		source string
+		digest string
+		err    string
+	}{
+		{name: "no parameters", url: "github.com/org/repo//policy", source: "github.com/org/repo//policy"},
+		{name: "other parameters", url: "github.com/org/repo?ref=v1", source: "github.com/org/repo?ref=v1"},
+		{name: "only digest", url: "oci::quay.io/org/policy:v1?digest=" + digest, source: "oci::quay.io/org/policy:v1", digest: digest},
+		{name: "digest and ref", url: "github.com/org/repo//policy?ref=v1&digest=" + digest, source: "github.com/org/repo//policy?ref=v1", digest: digest},
+		{name: "subdirectory after the query", url: "github.com/org/repo?ref=v1&digest=" + digest + "//policy", source: "github.com/org/repo?ref=v1//policy", digest: digest},
+		{name: "digest first", url: "github.com/org/repo?digest=" + digest + "&ref=v1", source: "github.com/org/repo?ref=v1", digest: digest},
+		{name: "invalid digest", url: "github.com/org/repo?digest=md5:abc", err: `invalid digest "md5:abc" of the source github.com/org/repo, expected sha256:<hex>`},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			source, digest, err := splitDigest(c.url)
+			if c.err != "" {
+				assert.EqualError(t, err, c.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, c.source, source)
+			assert.Equal(t, c.digest, digest)
+		})
+	}
+}
+
+func TestDownloadVerifiesDigest(t *testing.T) {
+	source := "git::https://example.com/org/repo.git?ref=v1"
+
+	fs := afero.NewMemMapFs()
+	d := mockDownloader{}
+	ctx := utils.WithFS(context.Background(), fs)
+	ctx = WithDownloadImpl(ctx, &d)
+
+	d.On("Download", ctx, mock.Anything, []string{source}).Run(func(args mock.Arguments) {
+		writeSource(t, fs, args.String(1))
+	}).Return(nil)
+
+	t.Run("matching", func(t *testing.T) {
+		_, err := Download(ctx, "/work/1", source+"&digest="+expectedDigest(), false)
+		assert.NoError(t, err)
+	})
+
+	t.Run("mismatch", func(t *testing.T) {
+		other := "sha256:" + sumA
+		_, err := Download(ctx, "/work/2", source+"&digest="+other, false)
+		assert.EqualError(t, err, fmt.Sprintf("digest mismatch for %s, expected %s but the downloaded content has the digest %s", source, other, expectedDigest()))
+	})
+}
@@ -24,12 +24,16 @@ import (
 	"regexp"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/enterprise-contract/go-gather/gather"
 	"github.com/enterprise-contract/go-gather/metadata"
 	"github.com/open-policy-agent/conftest/downloader"
 	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
 
+	"github.com/enterprise-contract/ec-cli/internal/logging"
+	"github.com/enterprise-contract/ec-cli/internal/tracing"
 	"github.com/enterprise-contract/ec-cli/internal/utils"
 )
 
@@ -43,6 +47,9 @@ type downloadImpl interface {
 
 var gatherFunc = gather.Gather
 
+var conftestDownload = downloader.Download
+
+// dlMutex serializes the OCI downloads performed via conftest
 var dlMutex sync.Mutex
 
 // WithDownloadImpl replaces the downloadImpl implementation used
@@ -54,28 +61,173 @@ func WithDownloadImpl(ctx context.Context, d downloadImpl) context.Context {
 //
 // Note that it handles just one url at a time even though the equivalent
 // Conftest function can take a list of source urls.
-func Download(ctx context.Context, destDir string, sourceUrl string, showMsg bool) (metadata.Metadata, error) {
-	if !isSecure(sourceUrl) {
-		return nil, fmt.Errorf("attempting to download from insecure source: %s", sourceUrl)
+//
+// The source URL can pin the expected digest of the source with the digest
+// query parameter, e.g. `github.com/org/repo//policy?ref=v1&digest=sha256:...`,
+// see Digest for how it is computed. The download fails if the downloaded
+// content doesn't match the digest.
+//
+// Sources that fail to download are downloaded from their mirrors, if any are
+// configured, see WithMirrors.
+//
+// The download is aborted once the source exceeds the disk quota, if one is
+// configured, see WithDiskQuota.
+func Download(ctx context.Context, destDir string, sourceUrl string, showMsg bool) (_ metadata.Metadata, err error) {
+	ctx, span := tracing.Start(ctx, "download", attribute.String("source", Redact(sourceUrl)))
+	defer func(start time.Time) {
+		tracing.End(span, err)
+		entry := log.WithFields(log.Fields{
+			logging.PolicySourceField: Redact(sourceUrl),
+			logging.DurationField:     time.Since(start).Seconds(),
+		})
+		if err != nil {
+			entry.WithError(err).Debug("Unable to download the source")
+		} else {
+			entry.Debug("Downloaded the source")
+		}
+	}(time.Now())
+
+	sourceUrl, expected, err := splitDigest(sourceUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	stopWatching := func() error { return nil }
+	if q := diskQuota(ctx); q != nil {
+		if ctx, stopWatching, err = q.watch(ctx, utils.FS(ctx), sourceUrl, destDir); err != nil {
+			return nil, err
+		}
+	}
+
+	m, err := download(ctx, destDir, sourceUrl, showMsg)
+	if err != nil {
+		m, err = downloadFromMirrors(ctx, destDir, sourceUrl, showMsg, err)
+	}
+
+	// exceeding the quota takes precedence over the error caused by the
+	// aborted download
+	if qErr := stopWatching(); qErr != nil {
+		return m, qErr
+	}
+
+	if err != nil || expected == "" {
+		return m, err
+	}
+
+	return m, verifyDigest(utils.FS(ctx), sourceUrl, destDir, expected)
+}
+
+func download(ctx context.Context, destDir string, sourceUrl string, showMsg bool) (metadata.Metadata, error) {
+	if !isSecure(sourceUrl) && !isInsecureAllowed(ctx, sourceUrl) {
+		if !insecureSources(ctx) {
+			return nil, fmt.Errorf("attempting to download from insecure source: %s", Redact(sourceUrl))
+		}
+		log.Warnf("INSECURE: downloading from %s without network transport security, the content can be tampered with in transit", Redact(sourceUrl))
+	}
+
+	// the artifact is downloaded, and cached, as a whole and only the
+	// subdirectory is copied into the destination directory
+	if artifactUrl, subdir := splitOCISubdir(sourceUrl); subdir != "" {
+		return downloadOCISubdir(ctx, destDir, artifactUrl, subdir, showMsg)
+	}
+
+	// the archive is downloaded, and cached, as is and extracted into the
+	// destination directory
+	if archiveUrl, format, subdir, ok := splitArchive(sourceUrl); ok {
+		return downloadArchive(ctx, destDir, archiveUrl, format, subdir, showMsg)
+	}
+
+	// the verified artifact is downloaded, and cached, by its digest
+	if opts := signatureCheckOpts(ctx); opts != nil {
+		var err error
+		if sourceUrl, err = verifySignature(ctx, sourceUrl, opts); err != nil {
+			return nil, err
+		}
+	}
+
+	// embedded sources don't access the network, so they're not subjected to
+	// the download budget
+	if isEmbedded(sourceUrl) {
+		return nil, copyEmbedded(ctx, destDir, sourceUrl)
+	}
+
+	// ConfigMaps and Secrets are read from the cluster, like the policy
+	// itself, and are not cached as Secrets must not be stored on disk
+	if isKubernetes(sourceUrl) {
+		return nil, copyKubernetes(ctx, destDir, sourceUrl)
+	}
+
+	if localInPlace(ctx) {
+		if ok, err := linkLocal(ctx, destDir, sourceUrl); ok || err != nil {
+			return nil, err
+		}
+	}
+
+	remote := isRemote(sourceUrl)
+	offline := isOffline(ctx)
+	c := downloadCache(ctx)
+	if c != nil && !remote {
+		c = nil
+	}
+	if c != nil {
+		// in offline mode any cached copy is better than none
+		if ok, err := c.load(utils.FS(ctx), sourceUrl, destDir, offline); err != nil {
+			log.Warnf("Unable to use the cached copy of %s, downloading it instead: %v", Redact(sourceUrl), err)
+		} else if ok {
+			return nil, nil
+		}
+	}
+
+	if remote && offline {
+		return nil, fmt.Errorf("%w, the source %s is not available in the source cache", ErrOffline, Redact(sourceUrl))
 	}
 
-	msg := fmt.Sprintf("Downloading %s to %s", sourceUrl, destDir)
+	b := downloadBudget(ctx)
+	if b != nil {
+		if err := b.check(); err != nil {
+			return nil, err
+		}
+	}
+
+	events := downloadEvents(ctx)
+	var sizeBefore int64
+	if b != nil || events != nil {
+		var err error
+		if sizeBefore, err = dirSize(utils.FS(ctx), destDir); err != nil {
+			return nil, err
+		}
+	}
+
+	msg := fmt.Sprintf("Downloading %s to %s", Redact(sourceUrl), destDir)
 	log.Debug(msg)
-	if showMsg {
+	// the events report the progress in place of the message
+	if showMsg && events == nil {
 		fmt.Println(msg)
 	}
 
 	dl := func(ctx context.Context, sourceUrl, destDir string) (metadata.Metadata, error) {
 		// conftest's Download function leverages oras under the hood to fetch from OCI. It uses the
 		// global oras client and sets the user agent to "conftest". This is not a thread safe
-		// operation. Here we get around this limitation by ensuring a single download happens at a
-		// time.
-		dlMutex.Lock()
-		defer dlMutex.Unlock()
-		return nil, downloader.Download(ctx, destDir, []string{sourceUrl})
+		// operation. Here we get around this limitation by ensuring a single OCI download happens
+		// at a time, downloads using other protocols are not affected and run concurrently.
+		if isOCI(sourceUrl, destDir) {
+			dlMutex.Lock()
+			defer dlMutex.Unlock()
+		}
+		return nil, conftestDownload(ctx, destDir, []string{sourceUrl})
 	}
 
-	if utils.UseGoGather() {
+	// go-gather doesn't allow configuring the HTTP transport, so sources
+	// routed to it are downloaded via the getters using the Transport instead
+	if t := downloadTransport(ctx); t != nil {
+		dl = func(ctx context.Context, sourceUrl, destDir string) (metadata.Metadata, error) {
+			err := t.download(ctx, destDir, sourceUrl)
+			if err != nil {
+				log.Debug("Download failed!")
+			}
+			return nil, err
+		}
+	} else if backendFor(ctx, sourceUrl) == GoGather {
 		dl = func(ctx context.Context, sourceUrl, destDir string) (metadata.Metadata, error) {
 			m, err := gatherFunc(ctx, sourceUrl, destDir)
 			if err != nil {
@@ -93,13 +245,73 @@ func Download(ctx context.Context, destDir string, sourceUrl string, showMsg boo
 		}
 	}
 
-	m, err := dl(ctx, sourceUrl, destDir)
+	// the credentials are added only now so they're not part of the cache key
+	// and not present in the logs
+	dlUrl, release, err := withHelperCredentials(ctx, sourceUrl)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	dlUrl = withGitToken(ctx, dlUrl)
+	started := time.Now()
+	stopReporting := func() {}
+	if events != nil {
+		events.Started(Redact(sourceUrl), destDir)
+		stopReporting = reportTransferred(utils.FS(ctx), events, Redact(sourceUrl), destDir, sizeBefore)
+	}
+	m, err := withRetry(ctx, sourceUrl, func(ctx context.Context) (metadata.Metadata, error) {
+		release, err := acquireHost(ctx, sourceUrl)
+		if err != nil {
+			return nil, err
+		}
+		defer release()
+
+		return dl(ctx, dlUrl, destDir)
+	})
+	stopReporting()
+
+	if events != nil {
+		if err != nil {
+			events.Failed(Redact(sourceUrl), redactError(err), time.Since(started))
+		} else if size, err := dirSize(utils.FS(ctx), destDir); err == nil {
+			events.Completed(Redact(sourceUrl), size-sizeBefore, time.Since(started))
+		}
+	}
 
 	if err != nil {
 		log.Debug("Download failed!")
+	} else if c != nil {
+		if err := c.store(utils.FS(ctx), sourceUrl, destDir); err != nil {
+			log.Warnf("Unable to cache %s: %v", Redact(sourceUrl), err)
+		}
+	}
+
+	if err == nil && b != nil {
+		size, err := dirSize(utils.FS(ctx), destDir)
+		if err != nil {
+			return m, err
+		}
+
+		if err := b.add(size - sizeBefore); err != nil {
+			return m, fmt.Errorf("downloading %s: %w", Redact(sourceUrl), err)
+		}
+	}
+
+	// errors from git, OCI, S3... can include the source URL with credentials
+	return m, redactError(err)
+}
+
+// isOCI returns true if conftest downloads the source from an OCI registry,
+// either via the explicit oci:: getter or as detected from the URL. A source
+// that cannot be detected is treated as an OCI source to stay on the safe
+// side.
+func isOCI(sourceUrl, destDir string) bool {
+	detected, err := downloader.Detect(sourceUrl, destDir)
+	if err != nil {
+		return true
 	}
 
-	return m, err
+	return strings.HasPrefix(detected, "oci::") || strings.HasPrefix(detected, "oci://")
 }
 
 // matches insecure protocols, such as `git::http://...`
@@ -109,6 +321,7 @@ var insecure = regexp.MustCompile("^[A-Za-z0-9]*::http:")
 // if provided to Conftest downloader. The Conftest downloader supports the
 // following protocols:
 //   - file  -- deemed secure as it is not accessing over network
+//   - embed -- deemed secure as it is reading from the embedded filesystem
 //   - git   -- deemed secure if plaintext HTTP is not used
 //   - gcs   -- always uses HTTP+TLS
 //   - hg    -- deemed secure if plaintext HTTP is not used
@@ -116,6 +329,9 @@ var insecure = regexp.MustCompile("^[A-Za-z0-9]*::http:")
 //   - oci   -- always uses HTTP+TLS
 //   - http  -- not deemed secure
 //   - https -- deemed secure
+//
+// Insecure sources can still be allowed explicitly, see WithInsecureAllowList
+// and WithInsecureSources.
 func isSecure(url string) bool {
 	return !strings.HasPrefix(url, "http:") && !insecure.MatchString(url)
 }
@@ -22,6 +22,8 @@ import (
 	"context"
 	"errors"
 	"os"
+	"sync"
+	"sync/atomic"
 	"testing"
 
 	"github.com/enterprise-contract/go-gather/metadata"
@@ -152,3 +154,75 @@ func TestIsSecure(t *testing.T) {
 		assert.False(t, isSecure(u), `Expecting isSecure("%s") = false, but it was true`, u)
 	}
 }
+
+func TestDownloadConcurrency(t *testing.T) {
+	os.Unsetenv("USEGOGATHER")
+
+	originalConftestDownload := conftestDownload
+	t.Cleanup(func() {
+		conftestDownload = originalConftestDownload
+	})
+
+	// download starts the downloads of the given sources concurrently, each
+	// download reports its start and then waits to be released
+	download := func(t *testing.T, sources ...string) (started <-chan struct{}, release chan<- struct{}, maxInProgress func() int32) {
+		var inProgress, max atomic.Int32
+		s := make(chan struct{})
+		r := make(chan struct{})
+		conftestDownload = func(_ context.Context, _ string, _ []string) error {
+			n := inProgress.Add(1)
+			defer inProgress.Add(-1)
+			for {
+				if m := max.Load(); n <= m || max.CompareAndSwap(m, n) {
+					break
+				}
+			}
+			s <- struct{}{}
+			<-r
+			return nil
+		}
+
+		var wg sync.WaitGroup
+		for _, source := range sources {
+			wg.Add(1)
+			go func(source string) {
+				defer wg.Done()
+				_, err := Download(context.Background(), t.TempDir(), source, false)
+				assert.NoError(t, err)
+			}(source)
+		}
+		t.Cleanup(wg.Wait)
+
+		return s, r, max.Load
+	}
+
+	t.Run("non-OCI downloads overlap", func(t *testing.T) {
+		sources := []string{
+			"git::https://github.com/org/repo1.git",
+			"git::https://github.com/org/repo2.git",
+			"s3::https://s3.amazonaws.com/bucket/policy.tar.gz",
+		}
+		started, release, _ := download(t, sources...)
+
+		// all downloads are in progress before any of them completes
+		for range sources {
+			<-started
+		}
+		close(release)
+	})
+
+	t.Run("OCI downloads are serialized", func(t *testing.T) {
+		sources := []string{
+			"oci::quay.io/org/policy1:latest",
+			"quay.io/org/policy2:latest",
+		}
+		started, release, maxInProgress := download(t, sources...)
+
+		// each download completes before the next one starts
+		for range sources {
+			<-started
+			assert.Equal(t, int32(1), maxInProgress())
+			release <- struct{}{}
+		}
+	})
+}
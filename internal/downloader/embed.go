@@ -0,0 +1,119 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package downloader
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+const embeddedFSKey key = 2
+
+// EmbedScheme is the scheme of source URLs referring to files within the
+// embedded filesystem, e.g. embed://policy/release
+const EmbedScheme = "embed://"
+
+// WithEmbeddedFS registers the filesystem, typically an embed.FS, from which
+// the sources with the embed:// scheme are read. This allows programs
+// embedding ec-cli to provide the policy and data without any external
+// sources.
+func WithEmbeddedFS(ctx context.Context, fsys fs.FS) context.Context {
+	return context.WithValue(ctx, embeddedFSKey, fsys)
+}
+
+func embeddedFS(ctx context.Context) fs.FS {
+	if fsys, ok := ctx.Value(embeddedFSKey).(fs.FS); ok {
+		return fsys
+	}
+
+	return nil
+}
+
+// isEmbedded returns true if the source URL refers to the embedded filesystem
+func isEmbedded(sourceUrl string) bool {
+	return strings.HasPrefix(sourceUrl, EmbedScheme)
+}
+
+// copyEmbedded copies the files from the directory, or the file, of the
+// embedded filesystem referred to by the source URL into the destination
+// directory.
+func copyEmbedded(ctx context.Context, destDir, sourceUrl string) error {
+	fsys := embeddedFS(ctx)
+	if fsys == nil {
+		return fmt.Errorf("no embedded filesystem available to read %s from", sourceUrl)
+	}
+
+	root := path.Clean(strings.TrimPrefix(sourceUrl, EmbedScheme))
+	if root == "" || root == "/" {
+		root = "."
+	}
+	root = strings.TrimPrefix(root, "/")
+
+	if !fs.ValidPath(root) {
+		return fmt.Errorf("invalid path of embedded source %s", sourceUrl)
+	}
+
+	log.Debugf("Copying embedded %s to %s", root, destDir)
+
+	if err := copyTree(fsys, root, utils.FS(ctx), destDir); err != nil {
+		return fmt.Errorf("reading embedded source %s: %w", sourceUrl, err)
+	}
+
+	return nil
+}
+
+// copyTree copies the directory, or the file, at root within fsys into the
+// destination directory
+func copyTree(fsys fs.FS, root string, dest afero.Fs, destDir string) error {
+	return fs.WalkDir(fsys, root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel := path.Base(p)
+		if p != root || d.IsDir() {
+			if rel, err = filepath.Rel(root, p); err != nil {
+				return err
+			}
+		}
+		target := filepath.Join(destDir, filepath.FromSlash(rel))
+
+		if d.IsDir() {
+			return dest.MkdirAll(target, 0755)
+		}
+
+		content, err := fs.ReadFile(fsys, p)
+		if err != nil {
+			return err
+		}
+
+		if err := dest.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return err
+		}
+
+		return afero.WriteFile(dest, target, content, 0644)
+	})
+}
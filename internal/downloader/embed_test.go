@@ -0,0 +1,106 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package downloader
+
+import (
+	"context"
+	"embed"
+	"io/fs"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+//go:embed testdata/embedded
+var embedded embed.FS
+
+func TestDownloadEmbedded(t *testing.T) {
+	sub, err := fs.Sub(embedded, "testdata/embedded")
+	require.NoError(t, err)
+
+	cases := []struct {
+		name     string
+		url      string
+		expected map[string]string
+	}{
+		{
+			name: "directory",
+			url:  "embed://policy",
+			expected: map[string]string{
+				"/dest/release/main.rego": "package release",
+			},
+		},
+		{
+			name: "root",
+			url:  "embed://",
+			expected: map[string]string{
+				"/dest/policy/release/main.rego": "package release",
+				"/dest/data/rule_data.yml":       "rule_data:",
+			},
+		},
+		{
+			name: "file",
+			url:  "embed://data/rule_data.yml",
+			expected: map[string]string{
+				"/dest/rule_data.yml": "rule_data:",
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			ctx := utils.WithFS(context.Background(), fs)
+			ctx = WithEmbeddedFS(ctx, sub)
+			// the network is never accessed, the budget doesn't apply
+			ctx = WithDownloadBudget(ctx, 1)
+			d := mockDownloader{}
+			ctx = WithDownloadImpl(ctx, &d)
+
+			_, err := Download(ctx, "/dest", c.url, false)
+			require.NoError(t, err)
+
+			for file, prefix := range c.expected {
+				content, err := afero.ReadFile(fs, file)
+				require.NoError(t, err)
+				assert.Contains(t, string(content), prefix)
+			}
+			d.AssertNotCalled(t, "Download")
+		})
+	}
+}
+
+func TestDownloadEmbeddedErrors(t *testing.T) {
+	ctx := utils.WithFS(context.Background(), afero.NewMemMapFs())
+
+	_, err := Download(ctx, "/dest", "embed://policy", false)
+	assert.EqualError(t, err, "no embedded filesystem available to read embed://policy from")
+
+	ctx = WithEmbeddedFS(ctx, embedded)
+
+	_, err = Download(ctx, "/dest", "embed://missing", false)
+	assert.ErrorContains(t, err, "reading embedded source embed://missing")
+
+	_, err = Download(ctx, "/dest", "embed://../outside", false)
+	assert.EqualError(t, err, "invalid path of embedded source embed://../outside")
+}
@@ -0,0 +1,194 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package downloader
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/spf13/afero"
+)
+
+const downloadEventsKey key = 15
+
+// Events receives the lifecycle events of downloads, e.g. to display their
+// progress or to collect metrics. The source URLs given have any credentials
+// redacted. Sources downloaded concurrently report their events concurrently,
+// so implementations need to be safe for concurrent use. Sources read from the
+// cache or from the local filesystem are not downloaded, and report no events.
+type Events interface {
+	// Started is called when the download of the source into the directory
+	// starts
+	Started(source, destDir string)
+	// Transferred is called periodically while the source is downloading with
+	// the number of bytes downloaded so far
+	Transferred(source string, bytes int64)
+	// Completed is called when the source was downloaded with the number of
+	// bytes downloaded
+	Completed(source string, bytes int64, duration time.Duration)
+	// Failed is called when the download of the source failed, after any
+	// retries
+	Failed(source string, err error, duration time.Duration)
+}
+
+// WithEvents returns a context under which the lifecycle events of downloads
+// are reported to the given Events
+func WithEvents(ctx context.Context, events Events) context.Context {
+	return context.WithValue(ctx, downloadEventsKey, events)
+}
+
+// AddEvents returns a context under which the lifecycle events of downloads
+// are reported to the given Events in addition to any Events the context
+// already reports to
+func AddEvents(ctx context.Context, events Events) context.Context {
+	if existing := downloadEvents(ctx); existing != nil {
+		return WithEvents(ctx, multiEvents{existing, events})
+	}
+
+	return WithEvents(ctx, events)
+}
+
+// multiEvents reports the events to each of the Events in order
+type multiEvents []Events
+
+func (m multiEvents) Started(source, destDir string) {
+	for _, e := range m {
+		e.Started(source, destDir)
+	}
+}
+
+func (m multiEvents) Transferred(source string, bytes int64) {
+	for _, e := range m {
+		e.Transferred(source, bytes)
+	}
+}
+
+func (m multiEvents) Completed(source string, bytes int64, duration time.Duration) {
+	for _, e := range m {
+		e.Completed(source, bytes, duration)
+	}
+}
+
+func (m multiEvents) Failed(source string, err error, duration time.Duration) {
+	for _, e := range m {
+		e.Failed(source, err, duration)
+	}
+}
+
+func downloadEvents(ctx context.Context) Events {
+	if e, ok := ctx.Value(downloadEventsKey).(Events); ok {
+		return e
+	}
+
+	return nil
+}
+
+// progressInterval is the interval at which the number of bytes transferred
+// is reported
+var progressInterval = 500 * time.Millisecond
+
+// reportTransferred reports the number of bytes written to the destination
+// directory, beyond the given initial size, until the returned function is
+// called. go-getter and oras don't report the progress of downloads, so the
+// size of the destination directory is used instead.
+func reportTransferred(fs afero.Fs, events Events, source, destDir string, initial int64) func() {
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+
+		t := time.NewTicker(progressInterval)
+		defer t.Stop()
+
+		var last int64
+		for {
+			select {
+			case <-done:
+				return
+			case <-t.C:
+				size, err := dirSize(fs, destDir)
+				if err != nil || size-initial == last {
+					continue
+				}
+				last = size - initial
+				events.Transferred(source, last)
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		wg.Wait()
+	}
+}
+
+// progressPrinter is the Events implementation that prints the progress of
+// downloads, one line per event
+type progressPrinter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewProgressPrinter returns Events that print the progress of the downloads
+// to the given writer
+func NewProgressPrinter(w io.Writer) Events {
+	return &progressPrinter{w: w}
+}
+
+func (p *progressPrinter) printf(format string, args ...any) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	fmt.Fprintf(p.w, format+"\n", args...)
+}
+
+func (p *progressPrinter) Started(source, destDir string) {
+	p.printf("Downloading %s to %s", source, destDir)
+}
+
+func (p *progressPrinter) Transferred(source string, bytes int64) {
+	p.printf("Downloading %s: %s", source, byteCount(bytes))
+}
+
+func (p *progressPrinter) Completed(source string, bytes int64, duration time.Duration) {
+	p.printf("Downloaded %s: %s in %s", source, byteCount(bytes), duration.Round(time.Millisecond))
+}
+
+func (p *progressPrinter) Failed(source string, err error, duration time.Duration) {
+	p.printf("Failed to download %s after %s: %v", source, duration.Round(time.Millisecond), err)
+}
+
+// byteCount formats the number of bytes in binary units, e.g. 1.5 KiB
+func byteCount(b int64) string {
+	const unit = 1024
+	if b < unit {
+		return fmt.Sprintf("%d B", b)
+	}
+
+	div, exp := int64(unit), 0
+	for n := b / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f %ciB", float64(b)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,110 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package downloader
+
+import (
+	"context"
+	"net/url"
+	"strings"
+
+	"github.com/open-policy-agent/conftest/downloader"
+)
+
+const gitTokensKey key = 10
+
+// GitTokenEnv is the environment variable holding the token used to access
+// git repositories over HTTPS
+const GitTokenEnv = "EC_GIT_TOKEN"
+
+// GitTokens holds the tokens used to access git repositories over HTTPS, by
+// host, the token for the empty host is used for all other hosts
+type GitTokens map[string]string
+
+// ParseGitToken adds the token given either as `<token>`, used for any host,
+// or as `<host>=<token>`, used only for the given host
+func (t GitTokens) ParseGitToken(s string) {
+	if host, token, ok := strings.Cut(s, "="); ok && host != "" && !strings.ContainsAny(host, "/@") {
+		t[host] = token
+		return
+	}
+
+	t[""] = s
+}
+
+// WithGitTokens returns a context under which the tokens are used to access
+// the git repositories over HTTPS, for sources that don't already include
+// credentials in their URL
+func WithGitTokens(ctx context.Context, tokens GitTokens) context.Context {
+	return context.WithValue(ctx, gitTokensKey, tokens)
+}
+
+func gitTokens(ctx context.Context) GitTokens {
+	if t, ok := ctx.Value(gitTokensKey).(GitTokens); ok {
+		return t
+	}
+
+	return nil
+}
+
+// gitUsernames are the usernames accompanying the tokens on the hosts which
+// require a specific username, for all other hosts `x-access-token` is used,
+// same as on GitHub
+var gitUsernames = map[string]string{
+	"gitlab.com":    "oauth2",
+	"bitbucket.org": "x-token-auth",
+}
+
+// withGitToken returns the source URL with the token for the host of the git
+// repository set as the credentials, if the source is a git repository
+// accessed over HTTPS, and it doesn't include any credentials already
+func withGitToken(ctx context.Context, sourceUrl string) string {
+	tokens := gitTokens(ctx)
+	if len(tokens) == 0 {
+		return sourceUrl
+	}
+
+	detected, err := downloader.Detect(sourceUrl, "")
+	if err != nil || !strings.HasPrefix(detected, "git::https://") {
+		return sourceUrl
+	}
+
+	u, err := url.Parse(strings.TrimPrefix(detected, "git::"))
+	if err != nil || u.User != nil {
+		return sourceUrl
+	}
+
+	token, ok := tokens[u.Hostname()]
+	if !ok {
+		token, ok = tokens[""]
+	}
+	if !ok || token == "" {
+		return sourceUrl
+	}
+
+	u.User = url.UserPassword(gitUsername(u.Hostname()), token)
+
+	return "git::" + u.String()
+}
+
+// gitUsername returns the username accompanying tokens on the given host
+func gitUsername(host string) string {
+	if username, ok := gitUsernames[host]; ok {
+		return username
+	}
+
+	return "x-access-token"
+}
@@ -0,0 +1,136 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package downloader
+
+import (
+	"context"
+	"math"
+	"sync"
+
+	"github.com/open-policy-agent/conftest/downloader"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/time/rate"
+)
+
+const hostLimitsKey key = 13
+
+// HostLimits limits the downloads from each host, e.g. from each registry
+type HostLimits struct {
+	// Rate is the maximum number of downloads started per second, zero means
+	// no limit
+	Rate float64
+	// Burst is the number of downloads that can be started at once before
+	// the rate applies, at least one
+	Burst int
+	// MaxConcurrent is the maximum number of downloads in progress at once,
+	// zero means no limit
+	MaxConcurrent int
+}
+
+type hostLimiter struct {
+	limits HostLimits
+	mu     sync.Mutex
+	hosts  map[string]*hostLimit
+}
+
+type hostLimit struct {
+	limiter *rate.Limiter
+	slots   chan struct{}
+}
+
+// WithHostLimits returns a context under which the downloads, including each
+// retry, from each host are limited as given. Downloads over the limits wait
+// until they're within the limits or the context is cancelled.
+func WithHostLimits(ctx context.Context, limits HostLimits) context.Context {
+	return context.WithValue(ctx, hostLimitsKey, &hostLimiter{limits: limits, hosts: map[string]*hostLimit{}})
+}
+
+func hostLimits(ctx context.Context) *hostLimiter {
+	if l, ok := ctx.Value(hostLimitsKey).(*hostLimiter); ok {
+		return l
+	}
+
+	return nil
+}
+
+// sourceHost returns the host the source is downloaded from
+func sourceHost(sourceUrl string) string {
+	if detected, err := downloader.Detect(sourceUrl, ""); err == nil {
+		sourceUrl = detected
+	}
+
+	host, _ := hostAndPath(sourceUrl)
+	return host
+}
+
+func (l *hostLimiter) limit(host string) *hostLimit {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if h, ok := l.hosts[host]; ok {
+		return h
+	}
+
+	h := &hostLimit{limiter: rate.NewLimiter(rate.Inf, 0)}
+	if l.limits.Rate > 0 {
+		h.limiter = rate.NewLimiter(rate.Limit(l.limits.Rate), int(math.Max(1, float64(l.limits.Burst))))
+	}
+	if l.limits.MaxConcurrent > 0 {
+		h.slots = make(chan struct{}, l.limits.MaxConcurrent)
+	}
+	l.hosts[host] = h
+
+	return h
+}
+
+// acquireHost waits until the download of the source from its host is within the
+// limits configured via WithHostLimits, the returned function needs to be
+// called once the download is done
+func acquireHost(ctx context.Context, sourceUrl string) (func(), error) {
+	l := hostLimits(ctx)
+	if l == nil {
+		return func() {}, nil
+	}
+
+	host := sourceHost(sourceUrl)
+	h := l.limit(host)
+
+	if h.slots != nil {
+		select {
+		case h.slots <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	release := func() {
+		if h.slots != nil {
+			<-h.slots
+		}
+	}
+
+	if h.limiter.Tokens() < 1 {
+		log.Debugf("Rate limiting the downloads from %s", host)
+	}
+
+	if err := h.limiter.Wait(ctx); err != nil {
+		release()
+		return nil, err
+	}
+
+	return release, nil
+}
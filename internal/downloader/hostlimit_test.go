@@ -0,0 +1,107 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package downloader
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSourceHost(t *testing.T) {
+	assert.Equal(t, "quay.io", sourceHost("oci::quay.io/org/policy:v1"))
+	assert.Equal(t, "quay.io", sourceHost("quay.io/org/policy:v1"))
+	assert.Equal(t, "github.com", sourceHost("github.com/org/repo//policy"))
+	assert.Equal(t, "registry.internal:5000", sourceHost("oci::registry.internal:5000/org/policy:v1"))
+}
+
+func TestHostMaxConcurrent(t *testing.T) {
+	ctx := WithHostLimits(context.Background(), HostLimits{MaxConcurrent: 2})
+
+	var inProgress, max atomic.Int32
+	download := func(source string) {
+		release, err := acquireHost(ctx, source)
+		require.NoError(t, err)
+		defer release()
+
+		n := inProgress.Add(1)
+		defer inProgress.Add(-1)
+		for {
+			if m := max.Load(); n <= m || max.CompareAndSwap(m, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			download("oci::quay.io/org/policy:v1")
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(2), max.Load())
+}
+
+func TestHostRate(t *testing.T) {
+	ctx := WithHostLimits(context.Background(), HostLimits{Rate: 20, Burst: 1})
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		release, err := acquireHost(ctx, "oci::quay.io/org/policy:v1")
+		require.NoError(t, err)
+		release()
+	}
+	// 1 at once, then 2 more at 20 per second
+	assert.GreaterOrEqual(t, time.Since(start), 90*time.Millisecond)
+
+	// other hosts are limited independently
+	start = time.Now()
+	release, err := acquireHost(ctx, "oci::registry.internal/org/policy:v1")
+	require.NoError(t, err)
+	release()
+	assert.Less(t, time.Since(start), 50*time.Millisecond)
+}
+
+func TestHostLimitCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(WithHostLimits(context.Background(), HostLimits{MaxConcurrent: 1}))
+
+	release, err := acquireHost(ctx, "oci::quay.io/org/policy:v1")
+	require.NoError(t, err)
+	defer release()
+
+	cancel()
+	_, err = acquireHost(ctx, "oci::quay.io/org/policy:v2")
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestNoHostLimits(t *testing.T) {
+	release, err := acquireHost(context.Background(), "oci::quay.io/org/policy:v1")
+	require.NoError(t, err)
+	release()
+}
@@ -0,0 +1,103 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package downloader
+
+import (
+	"context"
+	"regexp"
+	"strings"
+)
+
+const insecureAllowListKey key = 4
+
+const insecureSourcesKey key = 7
+
+// WithInsecureAllowList returns a context under which sources not using
+// network transport security, e.g. plain HTTP, are still downloaded if they
+// match one of the given hosts or URL prefixes, e.g. "localhost:5000" or
+// "registry.internal/policies". A host without a port matches the host on any
+// port. This is meant for local registries and for mirrors within trusted
+// networks, all other insecure sources are still rejected.
+func WithInsecureAllowList(ctx context.Context, allowList []string) context.Context {
+	return context.WithValue(ctx, insecureAllowListKey, allowList)
+}
+
+func insecureAllowList(ctx context.Context) []string {
+	if l, ok := ctx.Value(insecureAllowListKey).([]string); ok {
+		return l
+	}
+
+	return nil
+}
+
+// WithInsecureSources returns a context under which all sources not using
+// network transport security are downloaded, logging a warning for each of
+// them instead of failing the download
+func WithInsecureSources(ctx context.Context, allowed bool) context.Context {
+	return context.WithValue(ctx, insecureSourcesKey, allowed)
+}
+
+func insecureSources(ctx context.Context) bool {
+	allowed, _ := ctx.Value(insecureSourcesKey).(bool)
+	return allowed
+}
+
+// matches the getter and the scheme prefixes, e.g. `git::http://`
+var schemePrefix = regexp.MustCompile("^([A-Za-z0-9]*::)?([A-Za-z0-9]+://)?")
+
+// hostAndPath returns the host, without any user information, and the path of
+// the URL, dropping the getter and the scheme
+func hostAndPath(url string) (string, string) {
+	url = schemePrefix.ReplaceAllString(url, "")
+
+	host, path, _ := strings.Cut(url, "/")
+	if i := strings.LastIndex(host, "@"); i != -1 {
+		host = host[i+1:]
+	}
+
+	return host, path
+}
+
+// isInsecureAllowed returns true if the URL matches any of the hosts or URL
+// prefixes configured via WithInsecureAllowList
+func isInsecureAllowed(ctx context.Context, url string) bool {
+	host, path := hostAndPath(url)
+	target := host + "/" + path
+
+	for _, allowed := range insecureAllowList(ctx) {
+		allowedHost, allowedPath := hostAndPath(strings.TrimSuffix(allowed, "/"))
+		if allowedHost == "" {
+			continue
+		}
+
+		if allowedPath == "" {
+			hostname, _, _ := strings.Cut(host, ":")
+			if host == allowedHost || (!strings.Contains(allowedHost, ":") && hostname == allowedHost) {
+				return true
+			}
+			continue
+		}
+
+		// the prefix needs to match whole path segments
+		prefix := allowedHost + "/" + allowedPath
+		if target == prefix || strings.HasPrefix(target, prefix+"/") || strings.HasPrefix(target, prefix+"?") {
+			return true
+		}
+	}
+
+	return false
+}
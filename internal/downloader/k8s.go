@@ -0,0 +1,105 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package downloader
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+
+	"github.com/enterprise-contract/ec-cli/internal/kubernetes"
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+// KubernetesScheme is the scheme of source URLs referring to ConfigMaps, or
+// Secrets, in the Kubernetes cluster, e.g. k8s://namespace/name
+const KubernetesScheme = "k8s://"
+
+// isKubernetes returns true if the source URL refers to a Kubernetes resource
+func isKubernetes(sourceUrl string) bool {
+	return strings.HasPrefix(sourceUrl, KubernetesScheme)
+}
+
+// copyKubernetes writes each key of the ConfigMap, or the Secret, referred to
+// by the source URL as a file into the destination directory. The source URL
+// has the form of `k8s://[<namespace>/]<name>`, with the current namespace
+// used if the namespace is omitted, and refers to a ConfigMap, or to a Secret
+// with the `kind=secret` query parameter. The sources are read from the
+// cluster each time, they're never cached.
+func copyKubernetes(ctx context.Context, destDir, sourceUrl string) error {
+	ref, query, _ := strings.Cut(strings.TrimPrefix(sourceUrl, KubernetesScheme), "?")
+	params, err := url.ParseQuery(query)
+	if err != nil {
+		return fmt.Errorf("invalid Kubernetes source %s: %w", sourceUrl, err)
+	}
+
+	k8s, err := kubernetes.NewClient(ctx)
+	if err != nil {
+		return fmt.Errorf("cannot initialize Kubernetes client: %w", err)
+	}
+
+	files := map[string][]byte{}
+	switch kind := strings.ToLower(params.Get("kind")); kind {
+	case "", "configmap":
+		cm, err := k8s.FetchConfigMap(ctx, ref)
+		if err != nil {
+			return err
+		}
+		for k, v := range cm.Data {
+			files[k] = []byte(v)
+		}
+		for k, v := range cm.BinaryData {
+			files[k] = v
+		}
+	case "secret":
+		secret, err := k8s.FetchSecret(ctx, ref)
+		if err != nil {
+			return err
+		}
+		for k, v := range secret.Data {
+			files[k] = v
+		}
+	default:
+		return fmt.Errorf("unsupported kind %q of the Kubernetes source %s, expected configmap or secret", kind, sourceUrl)
+	}
+
+	afs := utils.FS(ctx)
+	if err := afs.MkdirAll(destDir, 0755); err != nil {
+		return err
+	}
+
+	log.Debugf("Writing %d keys of %s to %s", len(files), sourceUrl, destDir)
+	for key, content := range files {
+		// keys are validated by Kubernetes, but make sure they can't escape
+		// the destination directory
+		if !fs.ValidPath(key) || strings.Contains(key, "/") {
+			return fmt.Errorf("invalid key %q of the Kubernetes source %s", key, sourceUrl)
+		}
+
+		if err := afero.WriteFile(afs, filepath.Join(destDir, key), content, 0600); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,153 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package downloader
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	ecc "github.com/enterprise-contract/enterprise-contract-controller/api/v1alpha1"
+	app "github.com/konflux-ci/application-api/api/v1alpha1"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	pipelinev1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/enterprise-contract/ec-cli/internal/kubernetes"
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+type fakeKubernetesClient struct {
+	configMaps map[string]corev1.ConfigMap
+	secrets    map[string]corev1.Secret
+}
+
+func (f *fakeKubernetesClient) FetchEnterpriseContractPolicy(context.Context, string) (*ecc.EnterpriseContractPolicy, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (f *fakeKubernetesClient) FetchSnapshot(context.Context, string) (*app.Snapshot, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (f *fakeKubernetesClient) FetchPipelineRun(context.Context, string) (*pipelinev1.PipelineRun, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (f *fakeKubernetesClient) FetchTaskRun(context.Context, string) (*pipelinev1.TaskRun, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (f *fakeKubernetesClient) FetchConfigMap(_ context.Context, ref string) (*corev1.ConfigMap, error) {
+	if cm, ok := f.configMaps[ref]; ok {
+		return &cm, nil
+	}
+	return nil, errors.New("ConfigMap " + ref + " not found")
+}
+
+func (f *fakeKubernetesClient) FetchSecret(_ context.Context, ref string) (*corev1.Secret, error) {
+	if s, ok := f.secrets[ref]; ok {
+		return &s, nil
+	}
+	return nil, errors.New("Secret " + ref + " not found")
+}
+
+func TestDownloadKubernetes(t *testing.T) {
+	client := fakeKubernetesClient{
+		configMaps: map[string]corev1.ConfigMap{
+			"ns/rule-data": {
+				Data:       map[string]string{"rule_data.yml": "rule_data: {}"},
+				BinaryData: map[string][]byte{"data.json": []byte(`{}`)},
+			},
+			"ns/invalid": {
+				Data: map[string]string{"..": "x"},
+			},
+		},
+		secrets: map[string]corev1.Secret{
+			"ns/private": {
+				Data: map[string][]byte{"private.json": []byte(`{"a": 1}`)},
+			},
+		},
+	}
+
+	setup := func() (context.Context, afero.Fs, *mockDownloader) {
+		fs := afero.NewMemMapFs()
+		d := mockDownloader{}
+		ctx := utils.WithFS(context.Background(), fs)
+		ctx = kubernetes.WithClient(ctx, &client)
+		ctx = WithDownloadImpl(ctx, &d)
+		ctx = WithCache(ctx, "/cache", DefaultCacheTTL, false)
+		return ctx, fs, &d
+	}
+
+	t.Run("config map", func(t *testing.T) {
+		ctx, fs, d := setup()
+
+		_, err := Download(ctx, "/dest", "k8s://ns/rule-data", false)
+		require.NoError(t, err)
+
+		content, err := afero.ReadFile(fs, "/dest/rule_data.yml")
+		require.NoError(t, err)
+		assert.Equal(t, "rule_data: {}", string(content))
+		content, err = afero.ReadFile(fs, "/dest/data.json")
+		require.NoError(t, err)
+		assert.Equal(t, "{}", string(content))
+
+		d.AssertNotCalled(t, "Download", mock.Anything, mock.Anything, mock.Anything)
+		// never cached
+		exists, err := afero.DirExists(fs, "/cache")
+		require.NoError(t, err)
+		assert.False(t, exists)
+	})
+
+	t.Run("secret", func(t *testing.T) {
+		ctx, fs, _ := setup()
+
+		_, err := Download(ctx, "/dest", "k8s://ns/private?kind=secret", false)
+		require.NoError(t, err)
+
+		content, err := afero.ReadFile(fs, "/dest/private.json")
+		require.NoError(t, err)
+		assert.Equal(t, `{"a": 1}`, string(content))
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		ctx, _, _ := setup()
+
+		_, err := Download(ctx, "/dest", "k8s://ns/missing", false)
+		assert.EqualError(t, err, "ConfigMap ns/missing not found")
+	})
+
+	t.Run("unsupported kind", func(t *testing.T) {
+		ctx, _, _ := setup()
+
+		_, err := Download(ctx, "/dest", "k8s://ns/rule-data?kind=pod", false)
+		assert.EqualError(t, err, `unsupported kind "pod" of the Kubernetes source k8s://ns/rule-data?kind=pod, expected configmap or secret`)
+	})
+
+	t.Run("invalid key", func(t *testing.T) {
+		ctx, _, _ := setup()
+
+		_, err := Download(ctx, "/dest", "k8s://ns/invalid", false)
+		assert.EqualError(t, err, `invalid key ".." of the Kubernetes source k8s://ns/invalid`)
+	})
+}
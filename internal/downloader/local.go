@@ -0,0 +1,96 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package downloader
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/open-policy-agent/conftest/downloader"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+const localInPlaceKey key = 11
+
+// WithLocalInPlace returns a context under which sources that are local
+// directories are not copied, instead the destination directory is a symbolic
+// link to the source directory, so the source is evaluated in place. Local
+// directories are copied regardless if the filesystem doesn't support
+// symbolic links.
+func WithLocalInPlace(ctx context.Context, inPlace bool) context.Context {
+	return context.WithValue(ctx, localInPlaceKey, inPlace)
+}
+
+func localInPlace(ctx context.Context) bool {
+	inPlace, _ := ctx.Value(localInPlaceKey).(bool)
+	return inPlace
+}
+
+// localDir returns the absolute path of the directory the source refers to,
+// if the source is a local directory
+func localDir(fs afero.Fs, sourceUrl string) (string, bool) {
+	pwd, err := os.Getwd()
+	if err != nil {
+		return "", false
+	}
+
+	detected, err := downloader.Detect(sourceUrl, pwd)
+	if err != nil {
+		return "", false
+	}
+
+	// forced getters are detected as `file::file:///path`
+	path := strings.TrimPrefix(detected, "file::")
+	path, ok := strings.CutPrefix(path, "file://")
+	if !ok || !filepath.IsAbs(path) {
+		return "", false
+	}
+
+	if info, err := fs.Stat(path); err != nil || !info.IsDir() {
+		return "", false
+	}
+
+	return path, true
+}
+
+// linkLocal makes the destination directory a symbolic link to the local
+// directory of the source, returning false if the source is not a local
+// directory or the filesystem doesn't support symbolic links
+func linkLocal(ctx context.Context, destDir, sourceUrl string) (bool, error) {
+	fs := utils.FS(ctx)
+	symlinker, ok := fs.(afero.Symlinker)
+	if !ok {
+		return false, nil
+	}
+
+	dir, ok := localDir(fs, sourceUrl)
+	if !ok {
+		return false, nil
+	}
+
+	if err := fs.MkdirAll(filepath.Dir(destDir), 0755); err != nil {
+		return false, err
+	}
+
+	log.Debugf("Using the local directory %s in place, linking it to %s", dir, destDir)
+	return true, symlinker.SymlinkIfPossible(dir, destDir)
+}
@@ -0,0 +1,100 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package downloader
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+func TestLocalInPlace(t *testing.T) {
+	src := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(src, "main.rego"), []byte("package main"), 0600))
+
+	fs := afero.NewOsFs()
+	d := mockDownloader{}
+	ctx := utils.WithFS(context.Background(), fs)
+	ctx = WithLocalInPlace(ctx, true)
+	ctx = WithDownloadImpl(ctx, &d)
+
+	for _, source := range []string{"file::" + src, "file://" + src, src} {
+		t.Run(source, func(t *testing.T) {
+			dest := filepath.Join(t.TempDir(), "policy", "abc")
+
+			_, err := Download(ctx, dest, source, false)
+			require.NoError(t, err)
+			d.AssertNotCalled(t, "Download", mock.Anything, mock.Anything, mock.Anything)
+
+			info, err := os.Lstat(dest)
+			require.NoError(t, err)
+			assert.True(t, info.Mode()&os.ModeSymlink != 0)
+
+			content, err := os.ReadFile(filepath.Join(dest, "main.rego"))
+			require.NoError(t, err)
+			assert.Equal(t, "package main", string(content))
+
+			// the digest is of the linked directory
+			digest, err := Digest(fs, dest)
+			require.NoError(t, err)
+			expected, err := Digest(fs, src)
+			require.NoError(t, err)
+			assert.Equal(t, expected, digest)
+		})
+	}
+}
+
+func TestLocalInPlaceNotApplicable(t *testing.T) {
+	src := t.TempDir()
+	file := filepath.Join(src, "data.json")
+	require.NoError(t, os.WriteFile(file, []byte("{}"), 0600))
+
+	cases := []struct {
+		name   string
+		fs     afero.Fs
+		source string
+	}{
+		{name: "file", fs: afero.NewOsFs(), source: "file::" + file},
+		{name: "remote", fs: afero.NewOsFs(), source: "git::https://github.com/org/repo.git"},
+		{name: "not symlinkable", fs: afero.NewMemMapFs(), source: "file::" + src},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			d := mockDownloader{}
+			ctx := utils.WithFS(context.Background(), c.fs)
+			ctx = WithLocalInPlace(ctx, true)
+			ctx = WithDownloadImpl(ctx, &d)
+
+			d.On("Download", ctx, "dir", []string{c.source}).Return(nil).Once()
+
+			_, err := Download(ctx, "dir", c.source, false)
+			require.NoError(t, err)
+			mock.AssertExpectationsForObjects(t, &d)
+		})
+	}
+}
@@ -0,0 +1,160 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package downloader
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/enterprise-contract/go-gather/metadata"
+	"github.com/hashicorp/go-multierror"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+const mirrorsKey key = 9
+
+// Mirror is an alternative location of the sources with URLs starting with
+// the prefix, the prefix is replaced with the URL of the mirror
+type Mirror struct {
+	Prefix string
+	URL    string
+}
+
+// ParseMirror parses the mirror given as `<prefix>=<mirror URL>`, e.g.
+// `quay.io/org=registry.internal/org`
+func ParseMirror(s string) (Mirror, error) {
+	prefix, url, ok := strings.Cut(s, "=")
+	if !ok || prefix == "" || url == "" {
+		return Mirror{}, fmt.Errorf("invalid mirror %q, expected <prefix>=<mirror URL>", s)
+	}
+
+	return Mirror{Prefix: prefix, URL: url}, nil
+}
+
+type mirrors struct {
+	mirrors []Mirror
+	mu      sync.Mutex
+	// used holds the mirror each source was downloaded from
+	used map[string]string
+}
+
+// WithMirrors returns a context under which a source that fails to download
+// is downloaded from the mirrors with a prefix matching the source URL, in the
+// given order. The prefix can be given without the getter, e.g. `quay.io/org`
+// matches `oci::quay.io/org/policy:v1`, the getter is then retained in the
+// mirror URL. The mirrors used can be retrieved with MirrorsUsed.
+func WithMirrors(ctx context.Context, m []Mirror) context.Context {
+	return context.WithValue(ctx, mirrorsKey, &mirrors{mirrors: m, used: map[string]string{}})
+}
+
+func downloadMirrors(ctx context.Context) *mirrors {
+	if m, ok := ctx.Value(mirrorsKey).(*mirrors); ok {
+		return m
+	}
+
+	return nil
+}
+
+// MirrorsUsed returns the sources, by URL, that were downloaded from a mirror
+// and the URL of the mirror used for each
+func MirrorsUsed(ctx context.Context) map[string]string {
+	m := downloadMirrors(ctx)
+	if m == nil {
+		return nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if len(m.used) == 0 {
+		return nil
+	}
+
+	used := make(map[string]string, len(m.used))
+	for s, u := range m.used {
+		used[s] = u
+	}
+
+	return used
+}
+
+// matches the getter prefix, e.g. `oci::`
+var getterPrefix = regexp.MustCompile("^[A-Za-z0-9]+::")
+
+// candidates returns the URLs of the mirrors of the source in order
+func (m *mirrors) candidates(sourceUrl string) []string {
+	if m == nil {
+		return nil
+	}
+
+	getter := getterPrefix.FindString(sourceUrl)
+	var urls []string
+	for _, mirror := range m.mirrors {
+		if strings.HasPrefix(sourceUrl, mirror.Prefix) {
+			urls = append(urls, mirror.URL+strings.TrimPrefix(sourceUrl, mirror.Prefix))
+		} else if getter != "" && !getterPrefix.MatchString(mirror.Prefix) && strings.HasPrefix(sourceUrl[len(getter):], mirror.Prefix) {
+			urls = append(urls, getter+mirror.URL+strings.TrimPrefix(sourceUrl[len(getter):], mirror.Prefix))
+		}
+	}
+
+	return urls
+}
+
+func (m *mirrors) record(sourceUrl, mirrorUrl string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.used[Redact(sourceUrl)] = Redact(mirrorUrl)
+}
+
+// downloadFromMirrors downloads the source from its mirrors, in order, after
+// the source itself failed to download with the given error
+func downloadFromMirrors(ctx context.Context, destDir, sourceUrl string, showMsg bool, err error) (metadata.Metadata, error) {
+	m := downloadMirrors(ctx)
+	candidates := m.candidates(sourceUrl)
+	if len(candidates) == 0 || ctx.Err() != nil || errors.Is(err, ErrDownloadBudgetExceeded) || errors.Is(err, ErrDiskQuotaExceeded) || errors.Is(err, ErrOffline) {
+		return nil, err
+	}
+
+	log.Warnf("Unable to download %s, trying its mirrors: %v", Redact(sourceUrl), err)
+
+	errs := multierror.Append(nil, err)
+	for _, mirrorUrl := range candidates {
+		// remove anything left behind by the failed download
+		if err := utils.FS(ctx).RemoveAll(destDir); err != nil {
+			return nil, err
+		}
+
+		var md metadata.Metadata
+		md, err = download(ctx, destDir, mirrorUrl, showMsg)
+		if err == nil {
+			m.record(sourceUrl, mirrorUrl)
+			log.Infof("Downloaded %s from the mirror %s", Redact(sourceUrl), Redact(mirrorUrl))
+			return md, nil
+		}
+
+		errs = multierror.Append(errs, fmt.Errorf("mirror %s: %w", Redact(mirrorUrl), err))
+	}
+
+	return nil, errs
+}
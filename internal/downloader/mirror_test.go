@@ -0,0 +1,115 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package downloader
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMirror(t *testing.T) {
+	m, err := ParseMirror("quay.io/org=registry.internal/org")
+	require.NoError(t, err)
+	assert.Equal(t, Mirror{Prefix: "quay.io/org", URL: "registry.internal/org"}, m)
+
+	for _, invalid := range []string{"quay.io/org", "=registry.internal/org", "quay.io/org="} {
+		_, err := ParseMirror(invalid)
+		assert.EqualError(t, err, `invalid mirror "`+invalid+`", expected <prefix>=<mirror URL>`)
+	}
+}
+
+func TestMirrorCandidates(t *testing.T) {
+	m := &mirrors{mirrors: []Mirror{
+		{Prefix: "quay.io/org", URL: "registry.internal/org"},
+		{Prefix: "oci::quay.io/org", URL: "oci::backup.internal/org"},
+		{Prefix: "github.com/org", URL: "gitlab.internal/org"},
+	}}
+
+	assert.Equal(t, []string{
+		"oci::registry.internal/org/policy:v1",
+		"oci::backup.internal/org/policy:v1",
+	}, m.candidates("oci::quay.io/org/policy:v1"))
+	assert.Equal(t, []string{"registry.internal/org/policy:v1"}, m.candidates("quay.io/org/policy:v1"))
+	assert.Equal(t, []string{"git::gitlab.internal/org/repo.git?ref=v1"}, m.candidates("git::github.com/org/repo.git?ref=v1"))
+	assert.Empty(t, m.candidates("oci::quay.io/other/policy:v1"))
+
+	var none *mirrors
+	assert.Empty(t, none.candidates("oci::quay.io/org/policy:v1"))
+}
+
+func TestDownloadFromMirrors(t *testing.T) {
+	source := "git::https://github.com/org/repo.git"
+	unreachable := errors.New("unreachable")
+
+	t.Run("uses the first working mirror", func(t *testing.T) {
+		d := mockDownloader{}
+		ctx := WithMirrors(context.Background(), []Mirror{
+			{Prefix: "https://github.com/org", URL: "https://mirror1.internal/org"},
+			{Prefix: "https://github.com/org", URL: "https://mirror2.internal/org"},
+			{Prefix: "https://github.com/org", URL: "https://mirror3.internal/org"},
+		})
+		ctx = WithDownloadImpl(ctx, &d)
+
+		d.On("Download", ctx, "dir", []string{source}).Return(unreachable).Once()
+		d.On("Download", ctx, "dir", []string{"git::https://mirror1.internal/org/repo.git"}).Return(unreachable).Once()
+		d.On("Download", ctx, "dir", []string{"git::https://mirror2.internal/org/repo.git"}).Return(nil).Once()
+
+		_, err := Download(ctx, "dir", source, false)
+		require.NoError(t, err)
+		mock.AssertExpectationsForObjects(t, &d)
+		d.AssertNumberOfCalls(t, "Download", 3)
+
+		assert.Equal(t, map[string]string{source: "git::https://mirror2.internal/org/repo.git"}, MirrorsUsed(ctx))
+	})
+
+	t.Run("all mirrors fail", func(t *testing.T) {
+		d := mockDownloader{}
+		ctx := WithMirrors(context.Background(), []Mirror{
+			{Prefix: "https://github.com/org", URL: "https://mirror.internal/org"},
+		})
+		ctx = WithDownloadImpl(ctx, &d)
+
+		d.On("Download", ctx, "dir", mock.Anything).Return(unreachable)
+
+		_, err := Download(ctx, "dir", source, false)
+		assert.ErrorIs(t, err, unreachable)
+		assert.ErrorContains(t, err, "mirror git::https://mirror.internal/org/repo.git: unreachable")
+		assert.Nil(t, MirrorsUsed(ctx))
+	})
+
+	t.Run("primary source works", func(t *testing.T) {
+		d := mockDownloader{}
+		ctx := WithMirrors(context.Background(), []Mirror{
+			{Prefix: "https://github.com/org", URL: "https://mirror.internal/org"},
+		})
+		ctx = WithDownloadImpl(ctx, &d)
+
+		d.On("Download", ctx, "dir", []string{source}).Return(nil).Once()
+
+		_, err := Download(ctx, "dir", source, false)
+		require.NoError(t, err)
+		d.AssertNumberOfCalls(t, "Download", 1)
+		assert.Nil(t, MirrorsUsed(ctx))
+	})
+}
@@ -0,0 +1,98 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package downloader
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/enterprise-contract/go-gather/metadata"
+	"github.com/open-policy-agent/conftest/downloader"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+// splitOCISubdir splits the OCI source URL with a subdirectory, e.g.
+// `oci::quay.io/org/policy:v1//release`, into the URL of the OCI artifact and
+// the subdirectory within it. For all other sources the subdirectory is
+// empty.
+func splitOCISubdir(sourceUrl string) (string, string) {
+	getter := getterPrefix.FindString(sourceUrl)
+	rest := sourceUrl[len(getter):]
+
+	// don't mistake the scheme for the subdirectory
+	offset := 0
+	if i := strings.Index(rest, "://"); i != -1 {
+		offset = i + 3
+	}
+
+	i := strings.Index(rest[offset:], "//")
+	if i == -1 {
+		return sourceUrl, ""
+	}
+
+	base := getter + rest[:offset+i]
+	detected, err := downloader.Detect(base, "")
+	if err != nil || (!strings.HasPrefix(detected, "oci::") && !strings.HasPrefix(detected, "oci://")) {
+		return sourceUrl, ""
+	}
+
+	return base, rest[offset+i+2:]
+}
+
+// downloadOCISubdir downloads the OCI artifact into a temporary directory and
+// copies only the subdirectory, or the file, within it into the destination
+// directory
+func downloadOCISubdir(ctx context.Context, destDir, artifactUrl, subdir string, showMsg bool) (metadata.Metadata, error) {
+	subdir = path.Clean(strings.TrimSuffix(subdir, "/"))
+	if !fs.ValidPath(subdir) || subdir == "." {
+		return nil, fmt.Errorf("invalid subdirectory %q of the source %s", subdir, Redact(artifactUrl))
+	}
+
+	afs := utils.FS(ctx)
+	parent := filepath.Dir(destDir)
+	if err := afs.MkdirAll(parent, 0755); err != nil {
+		return nil, err
+	}
+
+	tmp, err := afero.TempDir(afs, parent, "oci-")
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = afs.RemoveAll(tmp)
+	}()
+
+	artifactDir := filepath.Join(tmp, "artifact")
+	m, err := download(ctx, artifactDir, artifactUrl, showMsg)
+	if err != nil {
+		return m, err
+	}
+
+	if _, err := afs.Stat(filepath.Join(artifactDir, filepath.FromSlash(subdir))); err != nil {
+		return m, fmt.Errorf("the subdirectory %s was not found in the source %s", subdir, Redact(artifactUrl))
+	}
+
+	log.Debugf("Copying %s from %s to %s", subdir, Redact(artifactUrl), destDir)
+	return m, copyTree(afero.NewIOFS(afero.NewBasePathFs(afs, artifactDir)), subdir, afs, destDir)
+}
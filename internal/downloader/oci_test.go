@@ -0,0 +1,109 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package downloader
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+func TestSplitOCISubdir(t *testing.T) {
+	cases := []struct {
+		source   string
+		artifact string
+		subdir   string
+	}{
+		{source: "oci::quay.io/org/policy:v1//release", artifact: "oci::quay.io/org/policy:v1", subdir: "release"},
+		{source: "oci://quay.io/org/policy:v1//release/lib", artifact: "oci://quay.io/org/policy:v1", subdir: "release/lib"},
+		{source: "quay.io/org/policy@sha256:abc//release", artifact: "quay.io/org/policy@sha256:abc", subdir: "release"},
+		{source: "oci::quay.io/org/policy:v1", artifact: "oci::quay.io/org/policy:v1"},
+		{source: "oci://quay.io/org/policy:v1", artifact: "oci://quay.io/org/policy:v1"},
+		// subdirectories of other sources are handled by the getters
+		{source: "git::https://github.com/org/repo.git//policy", artifact: "git::https://github.com/org/repo.git//policy"},
+		{source: "github.com/org/repo//policy", artifact: "github.com/org/repo//policy"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.source, func(t *testing.T) {
+			artifact, subdir := splitOCISubdir(c.source)
+			assert.Equal(t, c.artifact, artifact)
+			assert.Equal(t, c.subdir, subdir)
+		})
+	}
+}
+
+func TestDownloadOCISubdir(t *testing.T) {
+	artifact := "oci::quay.io/org/policy:v1"
+
+	setup := func(t *testing.T) (afero.Fs, context.Context) {
+		fs := afero.NewMemMapFs()
+		d := mockDownloader{}
+		ctx := utils.WithFS(context.Background(), fs)
+		ctx = WithDownloadImpl(ctx, &d)
+
+		d.On("Download", ctx, mock.Anything, []string{artifact}).Run(func(args mock.Arguments) {
+			dest := args.String(1)
+			for _, f := range []string{"release/main.rego", "release/lib/lib.rego", "pipeline/main.rego"} {
+				require.NoError(t, afero.WriteFile(fs, filepath.Join(dest, f), []byte(f), 0644))
+			}
+		}).Return(nil)
+
+		return fs, ctx
+	}
+
+	t.Run("copies only the subdirectory", func(t *testing.T) {
+		fs, ctx := setup(t)
+
+		_, err := Download(ctx, "/work/policy/abc", artifact+"//release", false)
+		require.NoError(t, err)
+
+		// the temporary download of the whole artifact is removed
+		var files []string
+		require.NoError(t, afero.Walk(fs, "/work", func(p string, info os.FileInfo, err error) error {
+			if err == nil && !info.IsDir() {
+				files = append(files, p)
+			}
+			return err
+		}))
+		assert.ElementsMatch(t, []string{"/work/policy/abc/main.rego", "/work/policy/abc/lib/lib.rego"}, files)
+	})
+
+	t.Run("missing subdirectory", func(t *testing.T) {
+		_, ctx := setup(t)
+
+		_, err := Download(ctx, "/work/policy/abc", artifact+"//missing", false)
+		assert.EqualError(t, err, "the subdirectory missing was not found in the source oci::quay.io/org/policy:v1")
+	})
+
+	t.Run("escaping subdirectory", func(t *testing.T) {
+		_, ctx := setup(t)
+
+		_, err := Download(ctx, "/work/policy/abc", artifact+"//../../etc", false)
+		assert.EqualError(t, err, `invalid subdirectory "../../etc" of the source oci::quay.io/org/policy:v1`)
+	})
+}
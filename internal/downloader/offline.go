@@ -0,0 +1,41 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package downloader
+
+import (
+	"context"
+	"errors"
+)
+
+const offlineKey key = 12
+
+// ErrOffline is returned when a remote source is needed in offline mode
+var ErrOffline = errors.New("offline mode")
+
+// WithOffline returns a context under which no sources are downloaded over
+// the network. Remote sources are read from the source cache, see WithCache,
+// regardless of their age, and fail if they're not cached. Local and embedded
+// sources are not affected. A cache directory populated by an earlier online
+// run can be copied over to provide the sources in air-gapped environments.
+func WithOffline(ctx context.Context, offline bool) context.Context {
+	return context.WithValue(ctx, offlineKey, offline)
+}
+
+func isOffline(ctx context.Context) bool {
+	offline, _ := ctx.Value(offlineKey).(bool)
+	return offline
+}
@@ -0,0 +1,86 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package downloader
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+func TestOffline(t *testing.T) {
+	source := "git::https://example.com/org/repo.git?ref=v1"
+
+	fs := afero.NewMemMapFs()
+	d := mockDownloader{}
+	ctx := utils.WithFS(context.Background(), fs)
+	ctx = WithCache(ctx, "/cache", time.Hour, true)
+	ctx = WithDownloadImpl(ctx, &d)
+
+	// populate the cache online
+	d.On("Download", ctx, "/work/1", []string{source}).Run(func(args mock.Arguments) {
+		require.NoError(t, afero.WriteFile(fs, filepath.Join(args.String(1), "main.rego"), []byte("package main"), 0644))
+	}).Return(nil).Once()
+	_, err := Download(ctx, "/work/1", source, false)
+	require.NoError(t, err)
+
+	// expire the cache entry
+	marker := filepath.Join((&cache{dir: "/cache"}).entry(source), cacheMarker)
+	require.NoError(t, fs.Chtimes(marker, time.Now().Add(-48*time.Hour), time.Now().Add(-48*time.Hour)))
+
+	offline := WithOffline(ctx, true)
+
+	t.Run("cached sources are used regardless of age", func(t *testing.T) {
+		_, err := Download(offline, "/work/2", source, false)
+		require.NoError(t, err)
+
+		content, err := afero.ReadFile(fs, "/work/2/main.rego")
+		require.NoError(t, err)
+		assert.Equal(t, "package main", string(content))
+	})
+
+	t.Run("sources not in the cache fail", func(t *testing.T) {
+		_, err := Download(offline, "/work/3", "git::https://example.com/org/other.git", false)
+		assert.ErrorIs(t, err, ErrOffline)
+		assert.EqualError(t, err, "offline mode, the source git::https://example.com/org/other.git is not available in the source cache")
+	})
+
+	t.Run("without a cache", func(t *testing.T) {
+		_, err := Download(WithOffline(context.Background(), true), "/work/4", source, false)
+		assert.ErrorIs(t, err, ErrOffline)
+	})
+
+	t.Run("local sources are read", func(t *testing.T) {
+		local := "file::" + t.TempDir()
+		d.On("Download", offline, "/work/5", []string{local}).Return(nil).Once()
+
+		_, err := Download(offline, "/work/5", local, false)
+		assert.NoError(t, err)
+	})
+
+	d.AssertNumberOfCalls(t, "Download", 2)
+}
@@ -0,0 +1,173 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package downloader
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+)
+
+const diskQuotaKey key = 18
+
+// ErrDiskQuotaExceeded is returned when a source, or all sources together,
+// take up more disk space than the quota allows
+var ErrDiskQuotaExceeded = errors.New("disk quota exceeded")
+
+// DiskQuota limits the disk space taken up by the sources written into their
+// destination directories. A limit of zero means no limit.
+type DiskQuota struct {
+	// MaxSourceBytes is the maximum size of a single source
+	MaxSourceBytes int64
+	// MaxTotalBytes is the maximum total size of all sources
+	MaxTotalBytes int64
+}
+
+// quota accounts for the disk space taken up by the sources, it is shared by
+// all downloads performed with the same context
+type quota struct {
+	DiskQuota
+	used atomic.Int64
+}
+
+// WithDiskQuota returns a context under which the sources downloaded using it,
+// or any context derived from it, are limited to the given quota. Unlike the
+// download budget the quota is enforced while the sources are downloading:
+// the size of the destination directory is checked periodically and the
+// download is aborted with ErrDiskQuotaExceeded as soon as the quota is
+// exceeded. The quota applies to the content of the destination directories,
+// including the sources read from the cache, but not to the cache itself.
+func WithDiskQuota(ctx context.Context, q DiskQuota) context.Context {
+	return context.WithValue(ctx, diskQuotaKey, &quota{DiskQuota: q})
+}
+
+func diskQuota(ctx context.Context) *quota {
+	if q, ok := ctx.Value(diskQuotaKey).(*quota); ok {
+		return q
+	}
+
+	return nil
+}
+
+// exceeded returns an error if the source, having grown by the given number
+// of bytes, or all the sources together exceed the quota
+func (q *quota) exceeded(source string, grown int64) error {
+	if q.MaxSourceBytes > 0 && grown > q.MaxSourceBytes {
+		return fmt.Errorf("%w: the source %s is larger than the maximum of %d bytes", ErrDiskQuotaExceeded, Redact(source), q.MaxSourceBytes)
+	}
+
+	if used := q.used.Load(); q.MaxTotalBytes > 0 && used > q.MaxTotalBytes {
+		return fmt.Errorf("%w: the sources take up %d bytes, the maximum is %d bytes", ErrDiskQuotaExceeded, used, q.MaxTotalBytes)
+	}
+
+	return nil
+}
+
+// watch accounts for the growth of the destination directory while the source
+// is written into it. The returned context is cancelled as soon as the quota
+// is exceeded, and the returned function, to be called once the source has
+// been written, stops watching and returns ErrDiskQuotaExceeded if the quota
+// was exceeded. A destination directory that was empty is removed when the
+// quota is exceeded so the partially written source doesn't take up the disk.
+func (q *quota) watch(ctx context.Context, fs afero.Fs, source, destDir string) (context.Context, func() error, error) {
+	if err := q.exceeded(source, 0); err != nil {
+		return nil, nil, err
+	}
+
+	before, err := dirSize(fs, destDir)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var grown int64
+
+	// update accounts for the current size of the destination directory and
+	// returns an error if the quota has been exceeded
+	update := func() error {
+		mu.Lock()
+		defer mu.Unlock()
+
+		size, err := dirSize(fs, destDir)
+		if err != nil {
+			// keep the last known size
+			return nil
+		}
+
+		q.used.Add(size - before - grown)
+		grown = size - before
+
+		return q.exceeded(source, grown)
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		t := time.NewTicker(progressInterval)
+		defer t.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-t.C:
+				if err := update(); err != nil {
+					log.Debugf("Aborting the download of %s: %v", Redact(source), err)
+					cancel(err)
+					return
+				}
+			}
+		}
+	}()
+
+	return ctx, func() error {
+		close(done)
+		wg.Wait()
+
+		err := context.Cause(ctx)
+		if !errors.Is(err, ErrDiskQuotaExceeded) {
+			err = update()
+		}
+		cancel(nil)
+
+		if err == nil {
+			return nil
+		}
+
+		if before == 0 {
+			if rmErr := fs.RemoveAll(destDir); rmErr != nil {
+				log.Debugf("Unable to remove %s: %v", destDir, rmErr)
+			} else {
+				mu.Lock()
+				q.used.Add(-grown)
+				mu.Unlock()
+			}
+		}
+
+		return err
+	}, nil
+}
@@ -0,0 +1,126 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package downloader
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+func TestDiskQuotaSource(t *testing.T) {
+	t.Setenv("USEGOGATHER", "")
+
+	fs := afero.NewMemMapFs()
+	ctx := utils.WithFS(context.Background(), fs)
+	ctx = WithDownloadImpl(ctx, writingDownloader(fs, 40))
+	ctx = WithDiskQuota(ctx, DiskQuota{MaxSourceBytes: 30})
+
+	_, err := Download(ctx, "/dir/0", "https://example.com/org/repo.git", false)
+	assert.ErrorIs(t, err, ErrDiskQuotaExceeded)
+	assert.EqualError(t, err, "disk quota exceeded: the source https://example.com/org/repo.git is larger than the maximum of 30 bytes")
+
+	// the partially written source is removed
+	exists, err := afero.Exists(fs, "/dir/0")
+	require.NoError(t, err)
+	assert.False(t, exists)
+	assert.Equal(t, int64(0), diskQuota(ctx).used.Load())
+}
+
+func TestDiskQuotaTotal(t *testing.T) {
+	t.Setenv("USEGOGATHER", "")
+
+	fs := afero.NewMemMapFs()
+	d := writingDownloader(fs, 40)
+	ctx := utils.WithFS(context.Background(), fs)
+	ctx = WithDownloadImpl(ctx, d)
+	ctx = WithDiskQuota(ctx, DiskQuota{MaxTotalBytes: 100})
+
+	for i := 0; i < 2; i++ {
+		_, err := Download(ctx, fmt.Sprintf("/dir/%d", i), "https://example.com/org/repo.git", false)
+		require.NoError(t, err)
+	}
+
+	_, err := Download(ctx, "/dir/2", "https://example.com/org/repo.git", false)
+	assert.EqualError(t, err, "disk quota exceeded: the sources take up 120 bytes, the maximum is 100 bytes")
+	assert.Equal(t, int64(80), diskQuota(ctx).used.Load())
+
+	// existing content is not accounted for
+	require.NoError(t, afero.WriteFile(fs, "/dir/3/existing", bytes.Repeat([]byte{'x'}, 200), 0644))
+	_, err = Download(ctx, "/dir/3", "https://example.com/org/repo.git", false)
+	assert.ErrorIs(t, err, ErrDiskQuotaExceeded)
+	// the destination directory with existing content is kept
+	exists, err := afero.Exists(fs, "/dir/3/existing")
+	require.NoError(t, err)
+	assert.True(t, exists)
+}
+
+func TestDiskQuotaAbortsDownload(t *testing.T) {
+	t.Setenv("USEGOGATHER", "")
+
+	interval := progressInterval
+	t.Cleanup(func() {
+		progressInterval = interval
+	})
+	progressInterval = time.Millisecond
+
+	fs := afero.NewMemMapFs()
+	d := mockDownloader{}
+	// writes more than allowed and then keeps downloading until aborted
+	d.On("Download", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		ctx := args.Get(0).(context.Context)
+		if err := afero.WriteFile(fs, path.Join(args.String(1), "content"), bytes.Repeat([]byte{'x'}, 40), 0644); err != nil {
+			panic(err)
+		}
+		<-ctx.Done()
+	}).Return(context.Canceled)
+
+	ctx := utils.WithFS(context.Background(), fs)
+	ctx = WithDownloadImpl(ctx, &d)
+	ctx = WithDownloadRetry(ctx, 3, time.Millisecond)
+	ctx = WithDiskQuota(ctx, DiskQuota{MaxSourceBytes: 30})
+
+	_, err := Download(ctx, "/dir", "https://example.com/org/repo.git", false)
+	assert.EqualError(t, err, "disk quota exceeded: the source https://example.com/org/repo.git is larger than the maximum of 30 bytes")
+	// not retried
+	d.AssertNumberOfCalls(t, "Download", 1)
+}
+
+func TestDiskQuotaNotExceeded(t *testing.T) {
+	t.Setenv("USEGOGATHER", "")
+
+	fs := afero.NewMemMapFs()
+	ctx := utils.WithFS(context.Background(), fs)
+	ctx = WithDownloadImpl(ctx, writingDownloader(fs, 40))
+	ctx = WithDiskQuota(ctx, DiskQuota{MaxSourceBytes: 40, MaxTotalBytes: 40})
+
+	_, err := Download(ctx, "/dir", "https://example.com/org/repo.git", false)
+	require.NoError(t, err)
+	assert.Equal(t, int64(40), diskQuota(ctx).used.Load())
+}
@@ -0,0 +1,167 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package downloader
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"regexp"
+	"time"
+
+	"github.com/enterprise-contract/go-gather/metadata"
+	log "github.com/sirupsen/logrus"
+)
+
+const downloadRetryKey key = 3
+
+const downloadTimeoutKey key = 8
+
+// DefaultRetryDelay is the delay before the first retry of a failed download
+const DefaultRetryDelay = time.Second
+
+// retry holds the number of attempts made to download a source and the delay
+// before the first retry, the delay doubles with each subsequent retry
+type retry struct {
+	attempts int
+	delay    time.Duration
+}
+
+// WithDownloadRetry returns a context under which failed downloads are
+// attempted up to the given number of attempts, waiting for the given delay
+// before the first retry and doubling it before each subsequent retry. A
+// random jitter of up to half the delay is added to each delay so concurrent
+// downloads don't retry in lockstep. By default only one attempt is made.
+// Retries stop as soon as the context is cancelled, and are not made for
+// errors that are not transient, e.g. authentication failures or sources that
+// do not exist.
+func WithDownloadRetry(ctx context.Context, attempts int, delay time.Duration) context.Context {
+	return context.WithValue(ctx, downloadRetryKey, retry{attempts: attempts, delay: delay})
+}
+
+func downloadRetry(ctx context.Context) retry {
+	if r, ok := ctx.Value(downloadRetryKey).(retry); ok && r.attempts > 0 {
+		return r
+	}
+
+	return retry{attempts: 1}
+}
+
+// WithDownloadTimeout returns a context under which each download attempt is
+// aborted after the given duration. Attempts that time out are retried as
+// configured via WithDownloadRetry.
+func WithDownloadTimeout(ctx context.Context, timeout time.Duration) context.Context {
+	return context.WithValue(ctx, downloadTimeoutKey, timeout)
+}
+
+func downloadTimeout(ctx context.Context) time.Duration {
+	timeout, _ := ctx.Value(downloadTimeoutKey).(time.Duration)
+	return timeout
+}
+
+// matches the errors reported by git, HTTP and OCI registries for failures
+// that don't go away by retrying
+var permanentFailure = regexp.MustCompile(`(?i)` +
+	`\b(401|403|404)\b|unauthorized|forbidden|denied|authentication (failed|required)|` +
+	`not found|no such file|does not exist|invalid source|unsupported|manifest unknown|name unknown`)
+
+// isRetryable returns false for errors that are not transient
+func isRetryable(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, ErrDownloadBudgetExceeded) || errors.Is(err, ErrDiskQuotaExceeded) {
+		return false
+	}
+
+	// attempts timing out are retried
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	return !permanentFailure.MatchString(err.Error())
+}
+
+// jitter returns the delay increased by a random duration of up to half the
+// delay
+var jitter = func(delay time.Duration) time.Duration {
+	if delay <= 1 {
+		return delay
+	}
+
+	return delay + time.Duration(rand.Int63n(int64(delay/2)+1))
+}
+
+// withRetry invokes the download function, retrying it on failure as
+// configured via WithDownloadRetry. Each attempt is given a context which is
+// cancelled after the timeout configured via WithDownloadTimeout.
+func withRetry(ctx context.Context, sourceUrl string, dl func(context.Context) (metadata.Metadata, error)) (metadata.Metadata, error) {
+	r := downloadRetry(ctx)
+	timeout := downloadTimeout(ctx)
+
+	attempt := func() (metadata.Metadata, error) {
+		if timeout <= 0 {
+			return dl(ctx)
+		}
+
+		attemptCtx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+		return dl(attemptCtx)
+	}
+
+	delay := r.delay
+	for n := 1; ; n++ {
+		logger := log.WithFields(log.Fields{
+			"source":   Redact(sourceUrl),
+			"attempt":  n,
+			"attempts": r.attempts,
+		})
+		logger.Debug("Downloading")
+
+		started := time.Now()
+		m, err := attempt()
+		if err == nil {
+			logger.WithField("duration", time.Since(started)).Debug("Download succeeded")
+			return m, nil
+		}
+
+		logger = logger.WithFields(log.Fields{
+			"duration": time.Since(started),
+			"error":    redactError(err),
+		})
+
+		if n >= r.attempts || ctx.Err() != nil {
+			logger.Debug("Download failed")
+			return m, err
+		}
+
+		if !isRetryable(err) {
+			logger.Debug("Download failed, not retrying as the error is not transient")
+			return m, err
+		}
+
+		wait := jitter(delay)
+		logger.WithField("delay", wait).Debug("Download failed, retrying")
+
+		t := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return m, err
+		case <-t.C:
+		}
+
+		delay *= 2
+	}
+}
@@ -0,0 +1,173 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package downloader
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/enterprise-contract/go-gather/metadata"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestDownloadRetry(t *testing.T) {
+	source := "https://example.com/org/repo.git"
+	transient := errors.New("transient error")
+
+	t.Run("no retry by default", func(t *testing.T) {
+		d := mockDownloader{}
+		ctx := WithDownloadImpl(context.Background(), &d)
+		d.On("Download", ctx, "dir", []string{source}).Return(transient).Once()
+
+		_, err := Download(ctx, "dir", source, false)
+		assert.ErrorIs(t, err, transient)
+		d.AssertNumberOfCalls(t, "Download", 1)
+	})
+
+	t.Run("succeeds after retries", func(t *testing.T) {
+		d := mockDownloader{}
+		ctx := WithDownloadRetry(context.Background(), 3, time.Millisecond)
+		ctx = WithDownloadImpl(ctx, &d)
+		d.On("Download", ctx, "dir", []string{source}).Return(transient).Twice()
+		d.On("Download", ctx, "dir", []string{source}).Return(nil).Once()
+
+		_, err := Download(ctx, "dir", source, false)
+		assert.NoError(t, err)
+		d.AssertNumberOfCalls(t, "Download", 3)
+	})
+
+	t.Run("gives up after the attempts", func(t *testing.T) {
+		d := mockDownloader{}
+		ctx := WithDownloadRetry(context.Background(), 2, time.Millisecond)
+		ctx = WithDownloadImpl(ctx, &d)
+		d.On("Download", ctx, "dir", []string{source}).Return(transient)
+
+		_, err := Download(ctx, "dir", source, false)
+		assert.ErrorIs(t, err, transient)
+		d.AssertNumberOfCalls(t, "Download", 2)
+	})
+
+	t.Run("insecure sources are not retried", func(t *testing.T) {
+		d := mockDownloader{}
+		ctx := WithDownloadRetry(context.Background(), 3, time.Millisecond)
+		ctx = WithDownloadImpl(ctx, &d)
+
+		_, err := Download(ctx, "dir", "http://example.com/org/repo.git", false)
+		assert.EqualError(t, err, "attempting to download from insecure source: http://example.com/org/repo.git")
+		d.AssertNotCalled(t, "Download", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("stops retrying when cancelled", func(t *testing.T) {
+		d := mockDownloader{}
+		ctx, cancel := context.WithCancel(WithDownloadRetry(context.Background(), 5, time.Hour))
+		ctx = WithDownloadImpl(ctx, &d)
+		d.On("Download", ctx, "dir", []string{source}).Run(func(mock.Arguments) {
+			cancel()
+		}).Return(transient)
+
+		done := make(chan error)
+		go func() {
+			_, err := Download(ctx, "dir", source, false)
+			done <- err
+		}()
+
+		select {
+		case err := <-done:
+			assert.ErrorIs(t, err, transient)
+		case <-time.After(10 * time.Second):
+			assert.Fail(t, "download was not cancelled")
+		}
+		d.AssertNumberOfCalls(t, "Download", 1)
+	})
+}
+
+func TestRetryBackoff(t *testing.T) {
+	var delays []time.Duration
+	last := time.Now()
+	ctx := WithDownloadRetry(context.Background(), 3, 10*time.Millisecond)
+
+	_, err := withRetry(ctx, "https://example.com", func(context.Context) (m metadata.Metadata, err error) {
+		now := time.Now()
+		delays = append(delays, now.Sub(last))
+		last = now
+		return nil, errors.New("failed")
+	})
+
+	assert.Error(t, err)
+	assert.Len(t, delays, 3)
+	assert.GreaterOrEqual(t, delays[1], 10*time.Millisecond)
+	assert.GreaterOrEqual(t, delays[2], 20*time.Millisecond)
+}
+
+func TestRetryNotRetryable(t *testing.T) {
+	cases := []error{
+		errors.New("GET https://quay.io/v2/org/policy/manifests/v1: MANIFEST_UNKNOWN: manifest unknown"),
+		errors.New("error downloading 'https://example.com/policy.tar.gz': bad response code: 404"),
+		errors.New("remote: Repository not found."),
+		errors.New("fatal: Authentication failed for 'https://github.com/org/repo.git/'"),
+		ErrDownloadBudgetExceeded,
+	}
+
+	for _, c := range cases {
+		t.Run(c.Error(), func(t *testing.T) {
+			calls := 0
+			ctx := WithDownloadRetry(context.Background(), 3, time.Millisecond)
+			_, err := withRetry(ctx, "https://example.com", func(context.Context) (metadata.Metadata, error) {
+				calls++
+				return nil, c
+			})
+
+			assert.ErrorIs(t, err, c)
+			assert.Equal(t, 1, calls)
+		})
+	}
+
+	assert.True(t, isRetryable(errors.New("dial tcp 10.0.0.1:443: connect: connection refused")))
+	assert.True(t, isRetryable(errors.New("unexpected status code 503 Service Unavailable")))
+}
+
+func TestRetryTimeout(t *testing.T) {
+	calls := 0
+	ctx := WithDownloadRetry(context.Background(), 2, time.Millisecond)
+	ctx = WithDownloadTimeout(ctx, 10*time.Millisecond)
+
+	_, err := withRetry(ctx, "https://example.com", func(ctx context.Context) (metadata.Metadata, error) {
+		calls++
+		if calls == 1 {
+			// the first attempt hangs until it times out
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}
+		return nil, nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, calls)
+}
+
+func TestJitter(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		d := jitter(10 * time.Millisecond)
+		assert.GreaterOrEqual(t, d, 10*time.Millisecond)
+		assert.LessOrEqual(t, d, 15*time.Millisecond)
+	}
+}
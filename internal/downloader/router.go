@@ -0,0 +1,142 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package downloader
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/open-policy-agent/conftest/downloader"
+)
+
+const downloadRoutesKey key = 16
+
+// Backend is the implementation used to download sources
+type Backend string
+
+const (
+	// Conftest downloads sources via the conftest downloader, i.e. go-getter
+	// and oras
+	Conftest Backend = "conftest"
+	// GoGather downloads sources via go-gather
+	GoGather Backend = "go-gather"
+)
+
+// goGatherSchemes are the schemes go-gather is able to download
+var goGatherSchemes = []string{"file", "git", "http", "oci"}
+
+// Routes maps the scheme of the source, i.e. the getter or the protocol of
+// the source URL, to the backend used to download it. All schemes not routed
+// are downloaded via conftest.
+type Routes map[string]Backend
+
+// ParseRoute parses the route given as `<scheme>=<backend>`, e.g.
+// `git=go-gather`, into the routes
+func (r Routes) ParseRoute(s string) error {
+	scheme, backend, ok := strings.Cut(s, "=")
+	if !ok || scheme == "" {
+		return fmt.Errorf("invalid source backend %q, expected <scheme>=<backend>", s)
+	}
+
+	switch b := Backend(backend); b {
+	case Conftest:
+		r[normalizeScheme(scheme)] = b
+	case GoGather:
+		scheme = normalizeScheme(scheme)
+		if !supportedByGoGather(scheme) {
+			return fmt.Errorf("the %s backend doesn't support the %s scheme, supported schemes are: %s", b, scheme, strings.Join(goGatherSchemes, ", "))
+		}
+		r[scheme] = b
+	default:
+		return fmt.Errorf("unknown source backend %q, expected %s or %s", backend, Conftest, GoGather)
+	}
+
+	return nil
+}
+
+// WithRoutes returns a context under which sources are downloaded via the
+// backend routed for their scheme. This allows migrating the schemes to
+// go-gather one at a time. Routes given here take precedence over the
+// USEGOGATHER environment variable.
+func WithRoutes(ctx context.Context, routes Routes) context.Context {
+	return context.WithValue(ctx, downloadRoutesKey, routes)
+}
+
+// backendFor returns the backend used to download the source. Setting the
+// USEGOGATHER environment variable to 1 routes all schemes supported by
+// go-gather to go-gather, which is kept for compatibility.
+func backendFor(ctx context.Context, sourceUrl string) Backend {
+	scheme := sourceScheme(sourceUrl)
+
+	if routes, ok := ctx.Value(downloadRoutesKey).(Routes); ok {
+		if b, ok := routes[scheme]; ok {
+			return b
+		}
+	}
+
+	if os.Getenv("USEGOGATHER") == "1" && supportedByGoGather(scheme) {
+		return GoGather
+	}
+
+	return Conftest
+}
+
+func supportedByGoGather(scheme string) bool {
+	for _, s := range goGatherSchemes {
+		if s == scheme {
+			return true
+		}
+	}
+
+	return false
+}
+
+func normalizeScheme(scheme string) string {
+	scheme = strings.ToLower(scheme)
+	if scheme == "https" {
+		return "http"
+	}
+
+	return scheme
+}
+
+// sourceScheme returns the scheme of the source as detected by conftest: the
+// forced getter, e.g. git for `git::https://...`, or otherwise the protocol
+// of the URL, with https reported as http. Sources that cannot be detected
+// have no scheme.
+func sourceScheme(sourceUrl string) string {
+	detected, err := downloader.Detect(sourceUrl, "")
+	if err != nil {
+		if getter := getterPrefix.FindString(sourceUrl); getter != "" {
+			return normalizeScheme(strings.TrimSuffix(getter, "::"))
+		}
+		return ""
+	}
+
+	if getter := getterPrefix.FindString(detected); getter != "" {
+		return normalizeScheme(strings.TrimSuffix(getter, "::"))
+	}
+
+	scheme, _, ok := strings.Cut(detected, "://")
+	if !ok {
+		return ""
+	}
+
+	return normalizeScheme(scheme)
+}
@@ -0,0 +1,107 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package downloader
+
+import (
+	"context"
+	"testing"
+
+	"github.com/enterprise-contract/go-gather/metadata"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSourceScheme(t *testing.T) {
+	cases := map[string]string{
+		"git::https://github.com/org/repo.git//policy": "git",
+		"github.com/org/repo//policy":                  "git",
+		"oci::registry.io/org/policy:v1":               "oci",
+		"quay.io/org/policy:v1":                        "oci",
+		"https://example.com/policy.rego":              "http",
+		"s3::https://s3.amazonaws.com/bucket/foo":      "s3",
+		"/tmp/policy":                                  "file",
+	}
+
+	for source, expected := range cases {
+		t.Run(source, func(t *testing.T) {
+			assert.Equal(t, expected, sourceScheme(source))
+		})
+	}
+}
+
+func TestParseRoute(t *testing.T) {
+	routes := Routes{}
+	require.NoError(t, routes.ParseRoute("git=go-gather"))
+	require.NoError(t, routes.ParseRoute("HTTPS=go-gather"))
+	require.NoError(t, routes.ParseRoute("oci=conftest"))
+	assert.Equal(t, Routes{"git": GoGather, "http": GoGather, "oci": Conftest}, routes)
+
+	assert.EqualError(t, routes.ParseRoute("git"), `invalid source backend "git", expected <scheme>=<backend>`)
+	assert.EqualError(t, routes.ParseRoute("git=curl"), `unknown source backend "curl", expected conftest or go-gather`)
+	assert.EqualError(t, routes.ParseRoute("s3=go-gather"), "the go-gather backend doesn't support the s3 scheme, supported schemes are: file, git, http, oci")
+}
+
+func TestBackendFor(t *testing.T) {
+	t.Setenv("USEGOGATHER", "")
+
+	ctx := context.Background()
+	assert.Equal(t, Conftest, backendFor(ctx, "git::https://github.com/org/repo.git"))
+
+	routed := WithRoutes(ctx, Routes{"git": GoGather})
+	assert.Equal(t, GoGather, backendFor(routed, "git::https://github.com/org/repo.git"))
+	assert.Equal(t, Conftest, backendFor(routed, "oci::registry.io/org/policy:v1"))
+
+	t.Setenv("USEGOGATHER", "1")
+	assert.Equal(t, GoGather, backendFor(ctx, "oci::registry.io/org/policy:v1"))
+	// not supported by go-gather
+	assert.Equal(t, Conftest, backendFor(ctx, "s3::https://s3.amazonaws.com/bucket/foo"))
+	// the routes take precedence
+	assert.Equal(t, Conftest, backendFor(WithRoutes(ctx, Routes{"oci": Conftest}), "oci::registry.io/org/policy:v1"))
+}
+
+func TestDownloadRoutes(t *testing.T) {
+	t.Setenv("USEGOGATHER", "")
+
+	originalGatherFunction := gatherFunc
+	t.Cleanup(func() {
+		gatherFunc = originalGatherFunction
+	})
+
+	var gathered []string
+	gatherFunc = func(_ context.Context, source string, _ string) (metadata.Metadata, error) {
+		gathered = append(gathered, source)
+		return nil, nil
+	}
+
+	d := mockDownloader{}
+	ctx := WithDownloadImpl(context.Background(), &d)
+	ctx = WithRoutes(ctx, Routes{"git": GoGather})
+	d.On("Download", ctx, "dir", []string{"https://example.com/policy.rego"}).Return(nil)
+
+	_, err := Download(ctx, "dir", "git::https://github.com/org/repo.git", false)
+	require.NoError(t, err)
+	_, err = Download(ctx, "dir", "https://example.com/policy.rego", false)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"git::https://github.com/org/repo.git"}, gathered)
+	d.AssertExpectations(t)
+	d.AssertNumberOfCalls(t, "Download", 1)
+	d.AssertNotCalled(t, "Download", mock.Anything, mock.Anything, []string{"git::https://github.com/org/repo.git"})
+}
@@ -0,0 +1,109 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package downloader
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/open-policy-agent/conftest/downloader"
+	"github.com/sigstore/cosign/v2/pkg/cosign"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/enterprise-contract/ec-cli/internal/utils/oci"
+)
+
+const signatureCheckOptsKey key = 14
+
+// WithSignatureVerification returns a context under which the cosign
+// signature of OCI policy sources, i.e. `oci::` sources, is verified with the
+// given options, using either a public key or the keyless workflow, before the
+// source is downloaded. The tag of the source is resolved to a digest first,
+// and the source is downloaded by that digest, so the downloaded content is
+// the one that was verified. Sources not signed as expected fail to download.
+// Sources other than OCI are not affected.
+func WithSignatureVerification(ctx context.Context, opts *cosign.CheckOpts) context.Context {
+	return context.WithValue(ctx, signatureCheckOptsKey, opts)
+}
+
+func signatureCheckOpts(ctx context.Context) *cosign.CheckOpts {
+	if opts, ok := ctx.Value(signatureCheckOptsKey).(*cosign.CheckOpts); ok {
+		return opts
+	}
+
+	return nil
+}
+
+// ociReference returns the reference of the OCI artifact for sources
+// downloaded from an OCI registry, e.g. `quay.io/org/policy:v1` for the
+// `oci::quay.io/org/policy:v1` source, and false for all other sources
+func ociReference(sourceUrl string) (string, bool) {
+	// the explicit getter is used as is, the reference is not detected
+	if ref, ok := strings.CutPrefix(sourceUrl, "oci::"); ok {
+		return strings.TrimPrefix(ref, "oci://"), true
+	}
+
+	detected, err := downloader.Detect(sourceUrl, "")
+	if err != nil {
+		return "", false
+	}
+
+	if ref, ok := strings.CutPrefix(detected, "oci://"); ok {
+		return ref, true
+	}
+
+	return "", false
+}
+
+// verifySignature verifies the signature of the OCI source and returns the
+// source URL pinned to the digest of the verified artifact
+func verifySignature(ctx context.Context, sourceUrl string, opts *cosign.CheckOpts) (string, error) {
+	ref, ok := ociReference(sourceUrl)
+	if !ok {
+		return sourceUrl, nil
+	}
+
+	if isOffline(ctx) {
+		return "", fmt.Errorf("%w, the signature of the source %s cannot be verified", ErrOffline, Redact(sourceUrl))
+	}
+
+	parsed, err := name.ParseReference(ref)
+	if err != nil {
+		return "", fmt.Errorf("verifying the signature of %s: %w", Redact(sourceUrl), err)
+	}
+
+	client := oci.NewClient(ctx)
+	digest, err := client.ResolveDigest(parsed)
+	if err != nil {
+		return "", fmt.Errorf("verifying the signature of %s: %w", Redact(sourceUrl), err)
+	}
+
+	pinned := parsed.Context().Digest(digest)
+
+	// Set the ClaimVerifier on a shallow *copy* of CheckOpts to avoid unexpected side-effects
+	o := *opts
+	o.ClaimVerifier = cosign.SimpleClaimVerifier
+	if _, _, err := client.VerifyImageSignatures(pinned, &o); err != nil {
+		return "", fmt.Errorf("verifying the signature of %s: %w", Redact(sourceUrl), err)
+	}
+
+	log.Debugf("Verified the signature of %s, downloading it as %s", Redact(sourceUrl), pinned)
+
+	return "oci::" + pinned.String(), nil
+}
@@ -0,0 +1,118 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package downloader
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/sigstore/cosign/v2/pkg/cosign"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/enterprise-contract/ec-cli/internal/utils/oci"
+	"github.com/enterprise-contract/ec-cli/internal/utils/oci/fake"
+)
+
+const policyDigest = "sha256:ca978112ca1bbdcafac231b39a23dc4da786eff8147c4e72b9807785afee48bb"
+
+func TestOCIReference(t *testing.T) {
+	cases := []struct {
+		source string
+		ref    string
+		ok     bool
+	}{
+		{source: "oci::registry.io/org/policy:v1", ref: "registry.io/org/policy:v1", ok: true},
+		{source: "oci://registry.io/org/policy:v1", ref: "registry.io/org/policy:v1", ok: true},
+		{source: "quay.io/org/policy:v1", ref: "quay.io/org/policy:v1", ok: true},
+		{source: "git::https://github.com/org/repo.git//policy"},
+		{source: "github.com/org/repo//policy"},
+		{source: "https://example.com/policy.tar.gz"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.source, func(t *testing.T) {
+			ref, ok := ociReference(c.source)
+			assert.Equal(t, c.ok, ok)
+			assert.Equal(t, c.ref, ref)
+		})
+	}
+}
+
+func TestDownloadVerifiesSignature(t *testing.T) {
+	source := "oci::registry.io/org/policy:v1"
+	pinned := "oci::registry.io/org/policy@" + policyDigest
+	opts := &cosign.CheckOpts{}
+
+	setup := func(verifyErr error) (context.Context, *mockDownloader, *fake.FakeClient) {
+		client := fake.FakeClient{}
+		client.On("ResolveDigest", name.MustParseReference("registry.io/org/policy:v1")).Return(policyDigest, nil)
+		client.On("VerifyImageSignatures", name.MustParseReference("registry.io/org/policy@"+policyDigest), mock.MatchedBy(func(o *cosign.CheckOpts) bool {
+			return o.ClaimVerifier != nil
+		})).Return(nil, false, verifyErr)
+
+		d := mockDownloader{}
+		ctx := oci.WithClient(context.Background(), &client)
+		ctx = WithSignatureVerification(ctx, opts)
+		ctx = WithDownloadImpl(ctx, &d)
+
+		return ctx, &d, &client
+	}
+
+	t.Run("signed", func(t *testing.T) {
+		ctx, d, client := setup(nil)
+		d.On("Download", ctx, "dir", []string{pinned}).Return(nil)
+
+		_, err := Download(ctx, "dir", source, false)
+		assert.NoError(t, err)
+		client.AssertExpectations(t)
+		d.AssertExpectations(t)
+		// the options given are not modified
+		assert.Nil(t, opts.ClaimVerifier)
+	})
+
+	t.Run("not signed", func(t *testing.T) {
+		ctx, d, _ := setup(errors.New("no matching signatures"))
+
+		_, err := Download(ctx, "dir", source, false)
+		assert.EqualError(t, err, "verifying the signature of oci::registry.io/org/policy:v1: no matching signatures")
+		d.AssertNotCalled(t, "Download", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("offline", func(t *testing.T) {
+		ctx, d, _ := setup(nil)
+		ctx = WithOffline(ctx, true)
+
+		_, err := Download(ctx, "dir", source, false)
+		assert.ErrorIs(t, err, ErrOffline)
+		d.AssertNotCalled(t, "Download", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("other sources", func(t *testing.T) {
+		ctx, d, client := setup(nil)
+		git := "git::https://github.com/org/repo.git//policy"
+		d.On("Download", ctx, "dir", []string{git}).Return(nil)
+
+		_, err := Download(ctx, "dir", git, false)
+		assert.NoError(t, err)
+		client.AssertNotCalled(t, "ResolveDigest", mock.Anything)
+	})
+}
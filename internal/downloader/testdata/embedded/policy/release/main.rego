@@ -0,0 +1,8 @@
+package release
+
+import rego.v1
+
+deny contains result if {
+	input.spam
+	result := {"code": "release.spam", "msg": "spam is not allowed"}
+}
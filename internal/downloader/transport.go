@@ -0,0 +1,336 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package downloader
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	gitTransport "github.com/go-git/go-git/v5/plumbing/transport"
+	getter "github.com/hashicorp/go-getter"
+	"github.com/open-policy-agent/conftest/downloader"
+	"golang.org/x/net/http/httpproxy"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content/file"
+	"oras.land/oras-go/v2/registry"
+	"oras.land/oras-go/v2/registry/remote"
+	"oras.land/oras-go/v2/registry/remote/auth"
+	"oras.land/oras-go/v2/registry/remote/credentials"
+	orasRetry "oras.land/oras-go/v2/registry/remote/retry"
+)
+
+const transportKey key = 20
+
+// Transport is the network configuration used for downloading sources: the
+// proxy and the CA certificates trusted in addition to the system ones. The
+// HTTP transport is dedicated to the downloads, the default HTTP transport
+// and the environment of the process are left untouched.
+type Transport struct {
+	http     *http.Transport
+	caBundle []byte
+}
+
+// NewTransport returns the Transport using the given proxy, taking precedence
+// over the HTTP_PROXY and HTTPS_PROXY environment variables, and trusting the
+// CA certificates from the given PEM file in addition to the system ones.
+// Either can be empty. Hosts listed in the NO_PROXY environment variable are
+// still accessed directly.
+func NewTransport(proxy, caBundle string) (*Transport, error) {
+	t, ok := http.DefaultTransport.(*http.Transport)
+	if !ok {
+		return nil, errors.New("unable to configure the HTTP transport")
+	}
+
+	transport := Transport{http: t.Clone()}
+
+	if proxy != "" {
+		u, err := url.Parse(proxy)
+		if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https" && u.Scheme != "socks5") {
+			return nil, fmt.Errorf("invalid proxy URL %q, expected http://, https:// or socks5:// followed by the proxy host", Redact(proxy))
+		}
+
+		config := httpproxy.FromEnvironment()
+		config.HTTPProxy = proxy
+		config.HTTPSProxy = proxy
+		proxyFunc := config.ProxyFunc()
+		transport.http.Proxy = func(r *http.Request) (*url.URL, error) {
+			return proxyFunc(r.URL)
+		}
+	}
+
+	if caBundle != "" {
+		bundle, err := os.ReadFile(caBundle)
+		if err != nil {
+			return nil, fmt.Errorf("reading the CA bundle: %w", err)
+		}
+
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+
+		if !pool.AppendCertsFromPEM(bundle) {
+			return nil, fmt.Errorf("no certificates found in the CA bundle %s", caBundle)
+		}
+
+		if transport.http.TLSClientConfig == nil {
+			transport.http.TLSClientConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+		}
+		transport.http.TLSClientConfig.RootCAs = pool
+		transport.caBundle = bundle
+	}
+
+	return &transport, nil
+}
+
+// WithTransport returns a context under which sources are downloaded using the
+// given Transport. Without it the default HTTP transport, and the proxy
+// configured in the environment, are used.
+func WithTransport(ctx context.Context, t *Transport) context.Context {
+	return context.WithValue(ctx, transportKey, t)
+}
+
+func downloadTransport(ctx context.Context) *Transport {
+	if t, ok := ctx.Value(transportKey).(*Transport); ok {
+		return t
+	}
+
+	return nil
+}
+
+func (t *Transport) client() *http.Client {
+	return &http.Client{Transport: t.http}
+}
+
+// proxyFor returns the URL of the proxy used to access the given URL, if any
+func (t *Transport) proxyFor(u *url.URL) (*url.URL, error) {
+	if t.http.Proxy == nil {
+		return nil, nil
+	}
+
+	return t.http.Proxy(&http.Request{URL: u})
+}
+
+// download downloads the source like the conftest downloader does, but with
+// the git, HTTP and OCI getters using the Transport. Git sources accessed over
+// HTTP are cloned via go-git instead of the git command line, all other git
+// sources and the S3 and GCS sources use the network configuration of the
+// environment.
+func (t *Transport) download(ctx context.Context, destDir, sourceUrl string) error {
+	detected, err := downloader.Detect(sourceUrl, destDir)
+	if err != nil {
+		return fmt.Errorf("detecting url: %w", err)
+	}
+
+	httpGetter := &getter.HttpGetter{Client: t.client()}
+	client := &getter.Client{
+		Ctx:  ctx,
+		Src:  detected,
+		Dst:  destDir,
+		Pwd:  destDir,
+		Mode: getter.ClientModeAny,
+		Getters: map[string]getter.Getter{
+			"file":  new(getter.FileGetter),
+			"git":   &gitGetter{transport: t},
+			"gcs":   new(getter.GCSGetter),
+			"hg":    new(getter.HgGetter),
+			"s3":    new(getter.S3Getter),
+			"oci":   &ociGetter{transport: t},
+			"http":  httpGetter,
+			"https": httpGetter,
+		},
+	}
+
+	if err := client.Get(); err != nil {
+		return fmt.Errorf("client get: %w", err)
+	}
+
+	return nil
+}
+
+// gitGetter clones git repositories accessed over HTTP via go-git using the
+// proxy and the CA certificates of the Transport, other repositories are
+// cloned via the git command line, as go-getter does
+type gitGetter struct {
+	getter.GitGetter
+	transport *Transport
+}
+
+func (g *gitGetter) Get(dst string, u *url.URL) error {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return g.GitGetter.Get(dst, u)
+	}
+
+	q := u.Query()
+	ref := q.Get("ref")
+	depth := 0
+	if d := q.Get("depth"); d != "" {
+		var err error
+		if depth, err = strconv.Atoi(d); err != nil {
+			return fmt.Errorf("invalid depth %q: %w", d, err)
+		}
+	}
+	if q.Has("sshkey") {
+		return errors.New("the sshkey parameter is not supported for git sources accessed over HTTP")
+	}
+	q.Del("ref")
+	q.Del("depth")
+	repoUrl := *u
+	repoUrl.RawQuery = q.Encode()
+
+	opts := &git.CloneOptions{
+		URL:      repoUrl.String(),
+		Depth:    depth,
+		CABundle: g.transport.caBundle,
+	}
+	proxy, err := g.transport.proxyFor(&repoUrl)
+	if err != nil {
+		return err
+	}
+	if proxy != nil {
+		opts.ProxyOptions = gitTransport.ProxyOptions{URL: proxy.String()}
+	}
+	// the ref can be a commit, so the whole history is needed to check it out
+	if ref != "" {
+		opts.Depth = 0
+	}
+
+	r, err := git.PlainCloneContext(g.Context(), dst, false, opts)
+	if err != nil {
+		return fmt.Errorf("cloning the repository: %w", err)
+	}
+
+	if ref == "" {
+		return nil
+	}
+
+	// the ref is a tag, a branch, of which only the remote tracking branch
+	// exists after the clone, or a commit
+	h, err := r.ResolveRevision(plumbing.Revision(ref))
+	if err != nil {
+		if h, err = r.ResolveRevision(plumbing.Revision("origin/" + ref)); err != nil {
+			return fmt.Errorf("resolving the ref %s: %w", ref, err)
+		}
+	}
+
+	w, err := r.Worktree()
+	if err != nil {
+		return err
+	}
+
+	return w.Checkout(&git.CheckoutOptions{Hash: *h})
+}
+
+// ociGetter pulls OCI artifacts via oras, as the conftest OCI getter does,
+// using the Transport
+type ociGetter struct {
+	client    *getter.Client
+	transport *Transport
+}
+
+func (g *ociGetter) SetClient(c *getter.Client) {
+	g.client = c
+}
+
+func (g *ociGetter) ClientMode(_ *url.URL) (getter.ClientMode, error) {
+	return getter.ClientModeDir, nil
+}
+
+func (g *ociGetter) Get(dst string, u *url.URL) error {
+	// the URL is the reference, with or without a scheme
+	reference, rest, found := strings.Cut(u.String(), "://")
+	if found {
+		reference = rest
+	}
+
+	ref, err := registry.ParseReference(reference)
+	if err != nil {
+		return fmt.Errorf("reference: %w", err)
+	}
+	if ref.Reference == "" {
+		ref.Reference = "latest"
+	}
+
+	repository, err := remote.NewRepository(ref.String())
+	if err != nil {
+		return fmt.Errorf("repository: %w", err)
+	}
+
+	// registries on the loopback interface are accessed via plaintext HTTP,
+	// as Docker does
+	repository.PlainHTTP = isLoopback(ref.Host())
+
+	store, err := credentials.NewStoreFromDocker(credentials.StoreOptions{
+		AllowPlaintextPut:        true,
+		DetectDefaultNativeStore: true,
+	})
+	if err != nil {
+		return err
+	}
+
+	repository.Client = &auth.Client{
+		Client:     &http.Client{Transport: orasRetry.NewTransport(g.transport.http)},
+		Credential: credentials.Credential(store),
+		Cache:      auth.NewCache(),
+	}
+
+	if err := os.MkdirAll(dst, 0755); err != nil {
+		return fmt.Errorf("make policy directory: %w", err)
+	}
+
+	fileStore, err := file.New(dst)
+	if err != nil {
+		return fmt.Errorf("file store: %w", err)
+	}
+	defer fileStore.Close()
+
+	if _, err := oras.Copy(g.client.Ctx, repository, ref.Reference, fileStore, "", oras.DefaultCopyOptions); err != nil {
+		return fmt.Errorf("pulling policy: %w", err)
+	}
+
+	return nil
+}
+
+func (g *ociGetter) GetFile(_ string, _ *url.URL) error {
+	return errors.New("OCI artifacts can only be downloaded as directories")
+}
+
+// isLoopback returns true if the host, with or without a port, is on the
+// loopback interface
+func isLoopback(host string) bool {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+
+	if host == "localhost" {
+		return true
+	}
+
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
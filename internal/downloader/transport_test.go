@@ -0,0 +1,196 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package downloader
+
+import (
+	"context"
+	"encoding/pem"
+	"net/http"
+	"net/http/cgi"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewTransportProxy(t *testing.T) {
+	for _, v := range []string{"HTTPS_PROXY", "HTTP_PROXY", "https_proxy", "http_proxy", "no_proxy"} {
+		t.Setenv(v, "")
+	}
+	t.Setenv("NO_PROXY", "internal.example.com")
+
+	transport, err := NewTransport("http://proxy.example.com:3128", "")
+	require.NoError(t, err)
+
+	proxy := func(u string) *url.URL {
+		parsed, err := url.Parse(u)
+		require.NoError(t, err)
+		p, err := transport.proxyFor(parsed)
+		require.NoError(t, err)
+		return p
+	}
+
+	assert.Equal(t, "http://proxy.example.com:3128", proxy("https://quay.io/v2/").String())
+	assert.Nil(t, proxy("https://internal.example.com/policy.tar.gz"))
+
+	// the process wide configuration is left untouched
+	assert.Empty(t, os.Getenv("HTTPS_PROXY"))
+	assert.Empty(t, os.Getenv("HTTP_PROXY"))
+	req, err := http.NewRequest(http.MethodGet, "https://quay.io/v2/", nil)
+	require.NoError(t, err)
+	p, err := http.DefaultTransport.(*http.Transport).Proxy(req)
+	require.NoError(t, err)
+	assert.Nil(t, p)
+}
+
+func TestNewTransportInvalidProxy(t *testing.T) {
+	for _, p := range []string{"proxy.example.com:3128", "ftp://proxy.example.com", "http://"} {
+		_, err := NewTransport(p, "")
+		assert.ErrorContains(t, err, "invalid proxy URL")
+	}
+}
+
+func TestNewTransportCABundle(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("package main"))
+	}))
+	t.Cleanup(srv.Close)
+
+	get := func(c *http.Client) error {
+		resp, err := c.Get(srv.URL)
+		if err == nil {
+			resp.Body.Close()
+		}
+		return err
+	}
+
+	bundle := filepath.Join(t.TempDir(), "ca.pem")
+	cert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	require.NoError(t, os.WriteFile(bundle, cert, 0600))
+
+	environ := os.Environ()
+	transport, err := NewTransport("", bundle)
+	require.NoError(t, err)
+	assert.NoError(t, get(transport.client()))
+
+	// the test server's certificate is trusted only by the dedicated transport
+	assert.ErrorContains(t, get(&http.Client{Transport: http.DefaultTransport}), "certificate")
+	assert.Equal(t, environ, os.Environ())
+
+	// sources are downloaded using the transport
+	dest := t.TempDir()
+	require.NoError(t, transport.download(context.Background(), dest, srv.URL+"/policy.rego"))
+	downloaded, err := os.ReadFile(filepath.Join(dest, "policy.rego"))
+	require.NoError(t, err)
+	assert.Equal(t, "package main", string(downloaded))
+}
+
+func TestNewTransportCABundleInvalid(t *testing.T) {
+	_, err := NewTransport("", filepath.Join(t.TempDir(), "missing.pem"))
+	assert.ErrorContains(t, err, "reading the CA bundle")
+
+	empty := filepath.Join(t.TempDir(), "empty.pem")
+	require.NoError(t, os.WriteFile(empty, []byte("nothing here"), 0600))
+	_, err = NewTransport("", empty)
+	assert.EqualError(t, err, "no certificates found in the CA bundle "+empty)
+}
+
+func TestIsLoopback(t *testing.T) {
+	for host, expected := range map[string]bool{
+		"localhost":      true,
+		"localhost:5000": true,
+		"127.0.0.1:5000": true,
+		"[::1]:5000":     true,
+		"quay.io":        false,
+		"10.0.0.1:5000":  false,
+	} {
+		assert.Equal(t, expected, isLoopback(host), host)
+	}
+}
+
+func TestTransportGitSource(t *testing.T) {
+	gitPath, err := exec.LookPath("git")
+	if err != nil {
+		t.Skip("git is not installed")
+	}
+
+	root := t.TempDir()
+	repo := filepath.Join(root, "policy.git")
+	run := func(dir string, args ...string) string {
+		cmd := exec.Command(gitPath, args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com", "GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com")
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+		return strings.TrimSpace(string(out))
+	}
+
+	work := filepath.Join(root, "work")
+	require.NoError(t, os.MkdirAll(filepath.Join(work, "policy"), 0755))
+	run(work, "init", "-q", "-b", "main")
+	require.NoError(t, os.WriteFile(filepath.Join(work, "policy", "main.rego"), []byte("package main\n"), 0600))
+	run(work, "add", ".")
+	run(work, "commit", "-q", "-m", "first")
+	first := run(work, "rev-parse", "HEAD")
+	run(work, "tag", "v1")
+	require.NoError(t, os.WriteFile(filepath.Join(work, "policy", "main.rego"), []byte("package main\n\nimport rego.v1\n"), 0600))
+	run(work, "commit", "-q", "-am", "second")
+	run(root, "clone", "-q", "--bare", work, repo)
+
+	// the repository is served via the smart HTTP protocol of git
+	srv := httptest.NewTLSServer(&cgi.Handler{
+		Path: gitPath,
+		Args: []string{"http-backend"},
+		Env:  []string{"GIT_PROJECT_ROOT=" + root, "GIT_HTTP_EXPORT_ALL=1"},
+	})
+	t.Cleanup(srv.Close)
+
+	bundle := filepath.Join(root, "ca.pem")
+	require.NoError(t, os.WriteFile(bundle, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw}), 0600))
+	transport, err := NewTransport("", bundle)
+	require.NoError(t, err)
+
+	cases := []struct {
+		name     string
+		ref      string
+		expected string
+	}{
+		{name: "default branch", expected: "package main\n\nimport rego.v1\n"},
+		{name: "branch", ref: "?ref=main", expected: "package main\n\nimport rego.v1\n"},
+		{name: "tag", ref: "?ref=v1", expected: "package main\n"},
+		{name: "commit", ref: "?ref=" + first, expected: "package main\n"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			dest := filepath.Join(t.TempDir(), "source")
+			require.NoError(t, transport.download(context.Background(), dest, "git::"+srv.URL+"/policy.git//policy"+c.ref))
+
+			downloaded, err := os.ReadFile(filepath.Join(dest, "main.rego"))
+			require.NoError(t, err)
+			assert.Equal(t, c.expected, string(downloaded))
+		})
+	}
+}
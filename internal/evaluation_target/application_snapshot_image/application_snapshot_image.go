@@ -19,16 +19,23 @@ package application_snapshot_image
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"crypto/x509"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
 	"path"
+	"sort"
+	"time"
 
 	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
 	app "github.com/konflux-ci/application-api/api/v1alpha1"
 	"github.com/santhosh-tekuri/jsonschema/v5"
 	"github.com/sigstore/cosign/v2/pkg/cosign"
+	cosignOCI "github.com/sigstore/cosign/v2/pkg/oci"
+	sigstoreSig "github.com/sigstore/sigstore/pkg/signature"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/afero"
 
@@ -36,13 +43,21 @@ import (
 	"github.com/enterprise-contract/ec-cli/internal/evaluator"
 	"github.com/enterprise-contract/ec-cli/internal/fetchers/oci/config"
 	"github.com/enterprise-contract/ec-cli/internal/fetchers/oci/files"
+	"github.com/enterprise-contract/ec-cli/internal/metrics"
 	"github.com/enterprise-contract/ec-cli/internal/policy"
+	"github.com/enterprise-contract/ec-cli/internal/sbom"
 	"github.com/enterprise-contract/ec-cli/internal/signature"
+	"github.com/enterprise-contract/ec-cli/internal/tracing"
 	"github.com/enterprise-contract/ec-cli/internal/utils"
 	"github.com/enterprise-contract/ec-cli/internal/utils/oci"
+	"github.com/enterprise-contract/ec-cli/internal/vulnerability"
 	"github.com/enterprise-contract/ec-cli/pkg/schema"
 )
 
+// unknownSigner identifies keyless signatures whose certificates don't carry a
+// subject, all such signatures are counted as made by a single signer
+const unknownSigner = "unknown"
+
 var attestationSchemas = map[string]*jsonschema.Schema{
 	"https://slsa.dev/provenance/v0.2": schema.SLSA_Provenance_v0_2,
 }
@@ -51,11 +66,21 @@ var attestationSchemas = map[string]*jsonschema.Schema{
 type ApplicationSnapshotImage struct {
 	reference        name.Reference
 	checkOpts        cosign.CheckOpts
+	inclusionProof   bool
+	publicKeys       []policy.PublicKey
+	revocation       signature.Revocation
+	effectiveTime    time.Time
+	selection        policy.AttestationSelection
 	signatures       []signature.EntitySignature
 	configJSON       json.RawMessage
+	metadata         *config.ImageMetadata
 	parentConfigJSON json.RawMessage
+	parentMetadata   *config.ImageMetadata
 	parentRef        name.Reference
 	attestations     []attestation.Attestation
+	attesters        map[string]map[string]bool
+	sboms            []sbom.SBOM
+	vulnerabilities  []vulnerability.Report
 	Evaluators       []evaluator.Evaluator
 	files            map[string]json.RawMessage
 	component        app.SnapshotComponent
@@ -73,9 +98,14 @@ func NewApplicationSnapshotImage(ctx context.Context, component app.SnapshotComp
 		return nil, err
 	}
 	a := &ApplicationSnapshotImage{
-		checkOpts: *opts,
-		component: component,
-		snapshot:  snap,
+		checkOpts:      *opts,
+		inclusionProof: p.RekorInclusionProof(),
+		publicKeys:     p.PublicKeys(),
+		revocation:     p.Revocation(),
+		effectiveTime:  p.EffectiveTime(),
+		selection:      p.AttestationSelection(),
+		component:      component,
+		snapshot:       snap,
 	}
 
 	if err := a.SetImageURL(component.ContainerImage); err != nil {
@@ -117,6 +147,10 @@ func (a *ApplicationSnapshotImage) SetImageURL(url string) error {
 func (a *ApplicationSnapshotImage) FetchImageConfig(ctx context.Context) error {
 	var err error
 	a.configJSON, err = config.FetchImageConfig(ctx, a.reference)
+	if err != nil {
+		return err
+	}
+	a.metadata, err = config.FetchImageMetadata(ctx, a.reference)
 	return err
 }
 
@@ -127,6 +161,10 @@ func (a *ApplicationSnapshotImage) FetchParentImageConfig(ctx context.Context) e
 		return err
 	}
 	a.parentConfigJSON, err = config.FetchImageConfig(ctx, a.parentRef)
+	if err != nil {
+		return err
+	}
+	a.parentMetadata, err = config.FetchImageMetadata(ctx, a.parentRef)
 	return err
 }
 
@@ -140,17 +178,110 @@ func (a *ApplicationSnapshotImage) FetchImageFiles(ctx context.Context) error {
 	return err
 }
 
+// FetchImageSBOMs collects the SBOMs of the image, from the SBOM attestations
+// and the provenance attestations, and from the SBOMs attached to the image as
+// referrers artifacts. Must invoke [ValidateAttestationSignature] beforehand
+// for the SBOMs from the attestations to be included.
+func (a *ApplicationSnapshotImage) FetchImageSBOMs(ctx context.Context) error {
+	a.sboms = sbom.FromAttestations(a.attestations)
+
+	ref, err := a.digestReference(ctx)
+	if err != nil {
+		return err
+	}
+
+	referred, err := sbom.FetchReferrers(ctx, ref)
+	if err != nil {
+		return err
+	}
+	a.sboms = append(a.sboms, referred...)
+
+	return nil
+}
+
+// FetchVulnerabilityScans collects the vulnerability scan reports of the image,
+// from the vulnerability attestations and from the scanner API when one is
+// configured via vulnerability.WithScannerURL. Must invoke
+// [ValidateAttestationSignature] beforehand for the reports from the
+// attestations to be included.
+func (a *ApplicationSnapshotImage) FetchVulnerabilityScans(ctx context.Context) error {
+	a.vulnerabilities = vulnerability.FromAttestations(a.attestations)
+
+	if !vulnerability.ScannerConfigured(ctx) {
+		return nil
+	}
+
+	ref, err := a.digestReference(ctx)
+	if err != nil {
+		return err
+	}
+
+	report, err := vulnerability.FetchReport(ctx, ref)
+	if err != nil {
+		return err
+	}
+	if report != nil {
+		a.vulnerabilities = append(a.vulnerabilities, *report)
+	}
+
+	return nil
+}
+
+// digestReference returns the reference of the image by digest, resolving the
+// digest when the image is referenced by tag
+func (a *ApplicationSnapshotImage) digestReference(ctx context.Context) (name.Digest, error) {
+	if ref, ok := a.reference.(name.Digest); ok {
+		return ref, nil
+	}
+
+	digest, err := a.ResolveDigest(ctx)
+	if err != nil {
+		return name.Digest{}, err
+	}
+
+	return a.reference.Context().Digest(digest), nil
+}
+
 // ValidateImageSignature executes the cosign.VerifyImageSignature method on the ApplicationSnapshotImage image ref.
-func (a *ApplicationSnapshotImage) ValidateImageSignature(ctx context.Context) error {
+func (a *ApplicationSnapshotImage) ValidateImageSignature(ctx context.Context) (err error) {
+	ctx, span := tracing.Start(ctx, "verify.image_signature")
+	defer func(start time.Time) {
+		metrics.ObserveSignatureVerification(metrics.SignatureImage, err, time.Since(start))
+		tracing.End(span, err)
+	}(time.Now())
+
 	// Set the ClaimVerifier on a shallow *copy* of CheckOpts to avoid unexpected side-effects
 	opts := a.checkOpts
 	opts.ClaimVerifier = cosign.SimpleClaimVerifier
-	signatures, _, err := oci.NewClient(ctx).VerifyImageSignatures(a.reference, &opts)
+	verifyCertificates := a.verifiesCertificates(&opts)
+	signatures, err := a.withPublicKeys(&opts, false, func(opts *cosign.CheckOpts) ([]cosignOCI.Signature, error) {
+		signatures, _, err := oci.NewClient(ctx).VerifyImageSignatures(a.reference, opts)
+		return signatures, err
+	})
 	if err != nil {
 		return err
 	}
 
-	for _, s := range signatures {
+	for _, v := range signatures {
+		s := v.sig
+		if verifyCertificates {
+			if err := signature.VerifyCertificate(ctx, s, &opts); err != nil {
+				return err
+			}
+		}
+
+		if a.inclusionProof {
+			if err := signature.VerifyInclusionProof(ctx, s, &opts); err != nil {
+				return err
+			}
+		}
+
+		if !a.revocation.IsEmpty() {
+			if err := signature.VerifyRevocation(ctx, s, &opts, a.revocation); err != nil {
+				return err
+			}
+		}
+
 		es, err := signature.NewEntitySignature(s)
 		if err != nil {
 			return err
@@ -161,17 +292,238 @@ func (a *ApplicationSnapshotImage) ValidateImageSignature(ctx context.Context) e
 	return nil
 }
 
+// verifiesCertificates returns true if the certificate chain and the SCT of
+// keyless signatures are to be verified explicitly, i.e. when using the keyless
+// workflow with the Fulcio roots configured. In that case the SCT check
+// of cosign is disabled in the provided CheckOpts in favor of the explicit
+// check that reports clearer errors.
+func (a *ApplicationSnapshotImage) verifiesCertificates(opts *cosign.CheckOpts) bool {
+	if opts.SigVerifier != nil || opts.RootCerts == nil || opts.IgnoreSCT {
+		return false
+	}
+
+	opts.IgnoreSCT = true
+
+	return true
+}
+
+// verifiedSignature is a verified signature along with the verifier of the key
+// that verified it, nil for keyless signatures
+type verifiedSignature struct {
+	sig      cosignOCI.Signature
+	verifier sigstoreSig.Verifier
+}
+
+// withPublicKeys runs the verification with each of the public keys of the
+// policy applying to the repository of the image, until one of them verifies,
+// or with all of them if all is set, setting the verifier of that key in the
+// CheckOpts. Of the signatures verified with a key only those made while the
+// key was valid are returned, see signedAt, and the key doesn't verify if none
+// was. The error of the last key is returned when none verifies. Without public
+// keys listed in the policy the verification runs with the CheckOpts as they
+// are.
+func (a *ApplicationSnapshotImage) withPublicKeys(opts *cosign.CheckOpts, all bool, verify func(*cosign.CheckOpts) ([]cosignOCI.Signature, error)) ([]verifiedSignature, error) {
+	if len(a.publicKeys) == 0 {
+		sigs, err := verify(opts)
+		if err != nil {
+			return nil, err
+		}
+
+		verified := make([]verifiedSignature, 0, len(sigs))
+		for _, sig := range sigs {
+			verified = append(verified, verifiedSignature{sig: sig, verifier: opts.SigVerifier})
+		}
+		return verified, nil
+	}
+
+	repository := a.reference.Context().Name()
+	var err error
+	var verified []verifiedSignature
+	applied, anyVerified := false, false
+	for _, k := range a.publicKeys {
+		if !k.AppliesTo(repository) {
+			continue
+		}
+		applied = true
+
+		opts.SigVerifier = k.Verifier()
+		sigs, keyErr := verify(opts)
+		if keyErr == nil {
+			sigs, keyErr = a.signedWhileValid(k, sigs, opts)
+		}
+		if keyErr != nil {
+			err = keyErr
+			log.Debugf("Unable to verify %s with the public key %q: %v", a.reference, k.Key, err)
+			continue
+		}
+
+		for _, sig := range sigs {
+			verified = append(verified, verifiedSignature{sig: sig, verifier: k.Verifier()})
+		}
+		if !all {
+			return verified, nil
+		}
+		anyVerified = true
+	}
+
+	if !applied {
+		return nil, fmt.Errorf("none of the public keys of the policy applies to the repository %s", repository)
+	}
+
+	if anyVerified {
+		return verified, nil
+	}
+
+	return nil, err
+}
+
+// signedWhileValid returns the signatures made while the key was valid, or an
+// error if none of the signatures was
+func (a *ApplicationSnapshotImage) signedWhileValid(k policy.PublicKey, sigs []cosignOCI.Signature, opts *cosign.CheckOpts) ([]cosignOCI.Signature, error) {
+	var valid []cosignOCI.Signature
+	for _, sig := range sigs {
+		at := a.signedAt(sig, opts)
+		if !k.ValidAt(at) {
+			log.Debugf("The signature of %s made at %s is outside the validity of the public key %q", a.reference, at.Format(time.RFC3339), k.Key)
+			continue
+		}
+		valid = append(valid, sig)
+	}
+
+	if len(sigs) > 0 && len(valid) == 0 {
+		return nil, fmt.Errorf("none of the signatures of %s was made within the validity of the public key", a.reference)
+	}
+
+	return valid, nil
+}
+
+// signedAt returns the time the signature was made, that is the time it was
+// integrated in the Rekor transparency log according to its bundle. When the
+// transparency log is not verified, or the signature has no bundle, the time
+// the signature was made isn't known and the effective time of the policy is
+// returned instead.
+func (a *ApplicationSnapshotImage) signedAt(sig cosignOCI.Signature, opts *cosign.CheckOpts) time.Time {
+	if opts.IgnoreTlog {
+		return a.effectiveTime
+	}
+
+	bundle, err := sig.Bundle()
+	if err != nil || bundle == nil {
+		return a.effectiveTime
+	}
+
+	return time.Unix(bundle.Payload.IntegratedTime, 0)
+}
+
+// signerOf returns the identity of the signer of the verified signature, the
+// fingerprint of the public key that verified it, or the subject and the issuer
+// of its certificate for keyless signatures. The key IDs found in the
+// signatures are not used as they're not covered by the signatures.
+func signerOf(sig cosignOCI.Signature, verifier sigstoreSig.Verifier) (string, error) {
+	if verifier != nil {
+		pk, err := verifier.PublicKey()
+		if err != nil {
+			return "", err
+		}
+
+		der, err := x509.MarshalPKIXPublicKey(pk)
+		if err != nil {
+			return "", err
+		}
+
+		return fmt.Sprintf("sha256:%x", sha256.Sum256(der)), nil
+	}
+
+	es, err := signature.NewEntitySignature(sig)
+	if err != nil {
+		return "", err
+	}
+
+	if es.Certificate == "" {
+		return unknownSigner, nil
+	}
+
+	subject := es.Metadata["Subject Alternative Name"]
+	if subject == "" {
+		subject = es.Metadata["Subject"]
+	}
+	if subject == "" {
+		return unknownSigner, nil
+	}
+
+	issuer := es.Metadata["Fulcio Issuer (V2)"]
+	if issuer == "" {
+		issuer = es.Metadata["Fulcio Issuer"]
+	}
+	if issuer == "" {
+		return subject, nil
+	}
+
+	return fmt.Sprintf("%s (%s)", subject, issuer), nil
+}
+
 // ValidateAttestationSignature executes the cosign.VerifyImageAttestations method
-func (a *ApplicationSnapshotImage) ValidateAttestationSignature(ctx context.Context) error {
+func (a *ApplicationSnapshotImage) ValidateAttestationSignature(ctx context.Context) (err error) {
+	ctx, span := tracing.Start(ctx, "verify.attestation_signature")
+	defer func(start time.Time) {
+		metrics.ObserveSignatureVerification(metrics.SignatureAttestation, err, time.Since(start))
+		tracing.End(span, err)
+	}(time.Now())
+
 	// Set the ClaimVerifier on a shallow *copy* of CheckOpts to avoid unexpected side-effects
 	opts := a.checkOpts
 	opts.ClaimVerifier = cosign.IntotoSubjectClaimVerifier
-
-	layers, _, err := oci.NewClient(ctx).VerifyImageAttestations(a.reference, &opts)
+	verifyCertificates := a.verifiesCertificates(&opts)
+
+	// the attestations are verified with all of the public keys, so each is
+	// attributed to all of the keys verifying it
+	verified, err := a.withPublicKeys(&opts, true, func(opts *cosign.CheckOpts) ([]cosignOCI.Signature, error) {
+		layers, _, err := oci.NewClient(ctx).VerifyImageAttestations(a.reference, opts)
+		return layers, err
+	})
 	if err != nil {
 		return err
 	}
 
+	var layers []cosignOCI.Signature
+	signers := map[v1.Hash][]string{}
+	for _, v := range verified {
+		digest, err := v.sig.Digest()
+		if err != nil {
+			return err
+		}
+
+		signer, err := signerOf(v.sig, v.verifier)
+		if err != nil {
+			return err
+		}
+
+		if _, ok := signers[digest]; !ok {
+			layers = append(layers, v.sig)
+		}
+		signers[digest] = append(signers[digest], signer)
+	}
+
+	for _, sig := range layers {
+		if verifyCertificates {
+			if err := signature.VerifyCertificate(ctx, sig, &opts); err != nil {
+				return err
+			}
+		}
+
+		if a.inclusionProof {
+			if err := signature.VerifyInclusionProof(ctx, sig, &opts); err != nil {
+				return err
+			}
+		}
+
+		if !a.revocation.IsEmpty() {
+			if err := signature.VerifyRevocation(ctx, sig, &opts, a.revocation); err != nil {
+				return err
+			}
+		}
+	}
+
 	// Extract the signatures from the attestations here in order to also validate that
 	// the signatures do exist in the expected format.
 	for _, sig := range layers {
@@ -181,6 +533,24 @@ func (a *ApplicationSnapshotImage) ValidateAttestationSignature(ctx context.Cont
 		}
 		t := att.PredicateType()
 		log.Debugf("Found attestation with predicateType: %s", t)
+		if !a.selection.Selects(t) {
+			log.Debugf("Skipping attestation with predicateType %s not selected by the policy", t)
+			continue
+		}
+
+		digest, err := sig.Digest()
+		if err != nil {
+			return err
+		}
+		if a.attesters == nil {
+			a.attesters = map[string]map[string]bool{}
+		}
+		if _, ok := a.attesters[t]; !ok {
+			a.attesters[t] = map[string]bool{}
+		}
+		for _, signer := range signers[digest] {
+			a.attesters[t][signer] = true
+		}
 		switch t {
 		case attestation.PredicateSLSAProvenance:
 			// SLSAProvenanceFromSignature does the payload extraction
@@ -193,6 +563,13 @@ func (a *ApplicationSnapshotImage) ValidateAttestationSignature(ctx context.Cont
 			}
 			a.attestations = append(a.attestations, sp)
 
+		case attestation.PredicateSLSAProvenanceV1:
+			sp, err := attestation.SLSAProvenanceV1FromSignature(sig)
+			if err != nil {
+				return fmt.Errorf("unable to parse as SLSA v1.0: %w", err)
+			}
+			a.attestations = append(a.attestations, sp)
+
 		case attestation.PredicateSpdxDocument:
 			// It's an SPDX format SBOM
 			// Todo maybe: We could unmarshal it into a suitable SPDX struct
@@ -261,6 +638,43 @@ func (a *ApplicationSnapshotImage) Attestations() []attestation.Attestation {
 	return a.attestations
 }
 
+// AttestationSigners returns the sorted distinct signers of the verified
+// attestations of each predicate type, identified by the public keys or the
+// certificates verifying the attestations
+func (a *ApplicationSnapshotImage) AttestationSigners() map[string][]string {
+	signers := make(map[string][]string, len(a.attesters))
+	for t, s := range a.attesters {
+		names := make([]string, 0, len(s))
+		for n := range s {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		signers[t] = names
+	}
+
+	return signers
+}
+
+// BaseImages returns the base images recorded in the materials of the
+// provenance attestations
+func (a *ApplicationSnapshotImage) BaseImages() []attestation.BaseImage {
+	var images []attestation.BaseImage
+	for _, att := range a.attestations {
+		images = append(images, attestation.BaseImages(att)...)
+	}
+	return images
+}
+
+// Platform returns the platform of the image, e.g. linux/arm64/v8, from its
+// config, or an empty string if the config is not fetched
+func (a *ApplicationSnapshotImage) Platform() string {
+	if a.metadata == nil {
+		return ""
+	}
+
+	return v1.Platform{OS: a.metadata.OS, Architecture: a.metadata.Architecture, Variant: a.metadata.Variant}.String()
+}
+
 func (a *ApplicationSnapshotImage) Signatures() []signature.EntitySignature {
 	return a.signatures
 }
@@ -276,6 +690,9 @@ func (a *ApplicationSnapshotImage) ResolveDigest(ctx context.Context) (string, e
 type attestationData struct {
 	Statement  json.RawMessage             `json:"statement"`
 	Signatures []signature.EntitySignature `json:"signatures,omitempty"`
+	// Provenance holds the normalized view of the SLSA Provenance predicate,
+	// regardless of its version
+	Provenance *attestation.Provenance `json:"provenance,omitempty"`
 }
 
 // MarshalJSON returns a JSON representation of the attestationData. It is customized to take into
@@ -313,6 +730,20 @@ func (a attestationData) MarshalJSON() ([]byte, error) {
 		}
 	}
 
+	if a.Provenance != nil {
+		_, err = buffy.WriteString(`, "provenance":`)
+		if err != nil {
+			return nil, fmt.Errorf("write provenance key: %w", err)
+		}
+		provenance, err := json.Marshal(a.Provenance)
+		if err != nil {
+			return nil, fmt.Errorf("marshal json provenance: %w", err)
+		}
+		if _, err := buffy.Write(provenance); err != nil {
+			return nil, fmt.Errorf("write provenance value: %w", err)
+		}
+	}
+
 	if err := buffy.WriteByte('}'); err != nil {
 		return nil, fmt.Errorf("close json: %w", err)
 	}
@@ -324,9 +755,41 @@ type image struct {
 	Ref        string                      `json:"ref"`
 	Signatures []signature.EntitySignature `json:"signatures,omitempty"`
 	Config     json.RawMessage             `json:"config,omitempty"`
-	Parent     any                         `json:"parent,omitempty"`
-	Files      map[string]json.RawMessage  `json:"files,omitempty"`
-	Source     any                         `json:"source,omitempty"`
+	// Labels and Annotations hold the labels from the image config and the
+	// annotations from the image manifest
+	Labels      map[string]string `json:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+	// OS, Architecture, Variant and Created are taken from the image config,
+	// the Layers from the image manifest
+	OS           string                     `json:"os,omitempty"`
+	Architecture string                     `json:"architecture,omitempty"`
+	Variant      string                     `json:"variant,omitempty"`
+	Created      string                     `json:"created,omitempty"`
+	Layers       []config.Layer             `json:"layers,omitempty"`
+	Parent       any                        `json:"parent,omitempty"`
+	Files        map[string]json.RawMessage `json:"files,omitempty"`
+	Source       any                        `json:"source,omitempty"`
+	BaseImages   []attestation.BaseImage    `json:"base_images,omitempty"`
+	// SBOMs holds the SBOMs of the image in the normalized form
+	SBOMs []sbom.SBOM `json:"sboms,omitempty"`
+	// VulnerabilityScans holds the vulnerability scan reports of the image in
+	// the normalized form
+	VulnerabilityScans []vulnerability.Report `json:"vulnerability_scans,omitempty"`
+}
+
+// setMetadata sets the attributes of the image from the image metadata
+func (i *image) setMetadata(m *config.ImageMetadata) {
+	if m == nil {
+		return
+	}
+
+	i.Labels = m.Labels
+	i.Annotations = m.Annotations
+	i.OS = m.OS
+	i.Architecture = m.Architecture
+	i.Variant = m.Variant
+	i.Created = m.Created
+	i.Layers = m.Layers
 }
 
 type Input struct {
@@ -344,26 +807,34 @@ func (a *ApplicationSnapshotImage) WriteInputFile(ctx context.Context) (string,
 		attestations = append(attestations, attestationData{
 			Statement:  a.Statement(),
 			Signatures: a.Signatures(),
+			Provenance: attestation.NormalizedProvenance(a),
 		})
 	}
 
 	input := Input{
 		Attestations: attestations,
 		Image: image{
-			Ref:        a.reference.String(),
-			Signatures: a.signatures,
-			Config:     a.configJSON,
-			Files:      a.files,
-			Source:     a.component.Source,
+			Ref:                a.reference.String(),
+			Signatures:         a.signatures,
+			Config:             a.configJSON,
+			Files:              a.files,
+			Source:             a.component.Source,
+			BaseImages:         a.BaseImages(),
+			SBOMs:              a.sboms,
+			VulnerabilityScans: a.vulnerabilities,
 		},
 		AppSnapshot: a.snapshot,
 	}
 
+	input.Image.setMetadata(a.metadata)
+
 	if a.parentRef != nil {
-		input.Image.Parent = image{
+		parent := image{
 			Ref:    a.parentRef.String(),
 			Config: a.parentConfigJSON,
 		}
+		parent.setMetadata(a.parentMetadata)
+		input.Image.Parent = parent
 	}
 
 	fs := utils.FS(ctx)
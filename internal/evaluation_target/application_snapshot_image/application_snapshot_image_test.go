@@ -20,18 +20,27 @@ package application_snapshot_image
 
 import (
 	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
 	_ "embed"
 	"encoding/base64"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"regexp"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/gkampitakis/go-snaps/snaps"
 	"github.com/google/go-containerregistry/pkg/crane"
 	"github.com/google/go-containerregistry/pkg/name"
 	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
 	"github.com/google/go-containerregistry/pkg/v1/mutate"
 	"github.com/google/go-containerregistry/pkg/v1/types"
 	"github.com/in-toto/in-toto-golang/in_toto"
@@ -40,9 +49,12 @@ import (
 	app "github.com/konflux-ci/application-api/api/v1alpha1"
 	"github.com/secure-systems-lab/go-securesystemslib/dsse"
 	"github.com/sigstore/cosign/v2/pkg/cosign"
+	"github.com/sigstore/cosign/v2/pkg/cosign/bundle"
 	"github.com/sigstore/cosign/v2/pkg/oci"
 	"github.com/sigstore/cosign/v2/pkg/oci/static"
 	cosignTypes "github.com/sigstore/cosign/v2/pkg/types"
+	"github.com/sigstore/sigstore/pkg/cryptoutils"
+	sigstoreSig "github.com/sigstore/sigstore/pkg/signature"
 	"github.com/sigstore/sigstore/pkg/signature/payload"
 	"github.com/spf13/afero"
 	"github.com/stretchr/testify/assert"
@@ -50,6 +62,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/enterprise-contract/ec-cli/internal/attestation"
+	"github.com/enterprise-contract/ec-cli/internal/fetchers/oci/config"
 	"github.com/enterprise-contract/ec-cli/internal/policy"
 	"github.com/enterprise-contract/ec-cli/internal/signature"
 	"github.com/enterprise-contract/ec-cli/internal/utils"
@@ -166,6 +179,29 @@ func TestWriteInputFile(t *testing.T) {
 				configJSON: json.RawMessage(`{"Labels":{"io.k8s.display-name":"Test Image"}}`),
 			},
 		},
+		{
+			name: "image metadata",
+			snapshot: ApplicationSnapshotImage{
+				reference:  name.MustParseReference("registry.io/repository/image:tag"),
+				configJSON: json.RawMessage(`{"User":"1001","Entrypoint":["/app"],"Env":["PATH=/bin"]}`),
+				metadata: &config.ImageMetadata{
+					Labels:       map[string]string{"version": "1.0"},
+					Annotations:  map[string]string{"org.opencontainers.image.source": "https://github.com/org/repo"},
+					OS:           "linux",
+					Architecture: "amd64",
+					Created:      "2024-01-01T00:00:00Z",
+					Layers: []config.Layer{{
+						Digest:    "sha256:" + strings.Repeat("a", 64),
+						DiffID:    "sha256:" + strings.Repeat("b", 64),
+						Size:      42,
+						MediaType: "application/vnd.oci.image.layer.v1.tar+gzip",
+					}},
+				},
+				parentConfigJSON: json.RawMessage(`{"User":"root"}`),
+				parentMetadata:   &config.ImageMetadata{OS: "linux", Architecture: "amd64"},
+				parentRef:        name.MustParseReference("registry.io/repository/image/parent:tag"),
+			},
+		},
 		{
 			name: "parent image config",
 			snapshot: ApplicationSnapshotImage{
@@ -697,6 +733,170 @@ func TestValidateImageSignatureWithCertificates(t *testing.T) {
 	snaps.MatchSnapshot(t, a.signatures)
 }
 
+func TestValidateImageSignatureWithPublicKeys(t *testing.T) {
+	newKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	newPEM, err := cryptoutils.MarshalPublicKeyToPEM(newKey.Public())
+	require.NoError(t, err)
+
+	config, err := json.Marshal(map[string]any{
+		"publicKeys": []map[string]any{
+			{"key": utils.TestPublicKey, "until": "2024-06-01T00:00:00Z", "repositories": []string{"registry.io/*"}},
+			{"key": string(newPEM), "from": "2024-05-01T00:00:00Z", "repositories": []string{"registry.io/repository/*"}},
+		},
+	})
+	require.NoError(t, err)
+
+	withNewKey := mock.MatchedBy(func(opts *cosign.CheckOpts) bool {
+		pk, err := opts.SigVerifier.PublicKey()
+		return err == nil && newKey.PublicKey.Equal(pk)
+	})
+
+	// signatureAt returns a signature integrated in the transparency log at
+	// the given time, or without a bundle if the time is empty
+	signatureAt := func(integratedTime string) oci.Signature {
+		var opts []static.Option
+		if integratedTime != "" {
+			at, err := time.Parse(time.RFC3339, integratedTime)
+			require.NoError(t, err)
+			opts = append(opts, static.WithBundle(&bundle.RekorBundle{Payload: bundle.RekorPayload{IntegratedTime: at.Unix()}}))
+		}
+		sig, err := static.NewSignature([]byte("payload"), "c2lnbmF0dXJl", opts...)
+		require.NoError(t, err)
+		return sig
+	}
+
+	cases := []struct {
+		name          string
+		image         string
+		effectiveTime string
+		// the old key verifies a signature, integrated at the given time
+		oldKeySigned   bool
+		integratedTime string
+		calls          int
+		err            string
+	}{
+		{
+			name:          "rotation window",
+			image:         "registry.io/repository/image:tag",
+			effectiveTime: "2024-05-15T00:00:00Z",
+			calls:         2,
+		},
+		{
+			name:           "signed before the key expired",
+			image:          "registry.io/old/image:tag",
+			effectiveTime:  "2024-07-01T00:00:00Z",
+			oldKeySigned:   true,
+			integratedTime: "2024-05-15T00:00:00Z",
+			calls:          1,
+		},
+		{
+			name:           "signed after the key expired",
+			image:          "registry.io/old/image:tag",
+			effectiveTime:  "2024-05-15T00:00:00Z",
+			oldKeySigned:   true,
+			integratedTime: "2024-06-15T00:00:00Z",
+			err:            "none of the signatures of registry.io/old/image:tag was made within the validity of the public key",
+		},
+		{
+			name:          "signing time unknown, before the key expired",
+			image:         "registry.io/old/image:tag",
+			effectiveTime: "2024-05-15T00:00:00Z",
+			oldKeySigned:  true,
+			calls:         1,
+		},
+		{
+			name:          "signing time unknown, after the key expired",
+			image:         "registry.io/old/image:tag",
+			effectiveTime: "2024-07-01T00:00:00Z",
+			oldKeySigned:  true,
+			err:           "none of the signatures of registry.io/old/image:tag was made within the validity of the public key",
+		},
+		{
+			name:          "no key applies",
+			image:         "quay.io/other/image:tag",
+			effectiveTime: "2024-07-01T00:00:00Z",
+			err:           "none of the public keys of the policy applies to the repository quay.io/other/image",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			p, err := policy.NewPolicy(context.Background(), policy.Options{
+				PolicyRef:     string(config),
+				IgnoreRekor:   true,
+				EffectiveTime: c.effectiveTime,
+			})
+			require.NoError(t, err)
+			assert.False(t, p.Keyless())
+
+			ref, err := name.ParseReference(c.image)
+			require.NoError(t, err)
+			a := ApplicationSnapshotImage{
+				reference:     ref,
+				publicKeys:    p.PublicKeys(),
+				effectiveTime: p.EffectiveTime(),
+			}
+
+			client := fake.FakeClient{}
+			ctx := o.WithClient(context.Background(), &client)
+			client.On("VerifyImageSignatures", ref, withNewKey).Return([]oci.Signature{}, false, nil)
+			if c.oldKeySigned {
+				client.On("VerifyImageSignatures", ref, mock.Anything).Return([]oci.Signature{signatureAt(c.integratedTime)}, false, nil)
+			} else {
+				client.On("VerifyImageSignatures", ref, mock.Anything).Return(nil, false, errors.New("no matching signatures"))
+			}
+
+			err = a.ValidateImageSignature(ctx)
+			if c.err != "" {
+				assert.EqualError(t, err, c.err)
+				return
+			}
+			require.NoError(t, err)
+			client.AssertNumberOfCalls(t, "VerifyImageSignatures", c.calls)
+		})
+	}
+}
+
+func TestSignerOf(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	verifier, err := sigstoreSig.LoadVerifier(key.Public(), crypto.SHA256)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKIXPublicKey(key.Public())
+	require.NoError(t, err)
+
+	// the key IDs of the signatures are not covered by the signatures
+	keyed, err := static.NewSignature(
+		[]byte(`{"payload": "", "signatures": [{"keyid": "alice", "sig": ""}, {"keyid": "bob", "sig": ""}]}`),
+		"",
+		static.WithLayerMediaType(types.MediaType((cosignTypes.DssePayloadType))),
+	)
+	require.NoError(t, err)
+
+	signer, err := signerOf(keyed, verifier)
+	require.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf("sha256:%x", sha256.Sum256(der)), signer)
+
+	signer, err = signerOf(keyed, nil)
+	require.NoError(t, err)
+	assert.Equal(t, unknownSigner, signer)
+
+	keyless, err := static.NewSignature(
+		[]byte(`image`),
+		"signature",
+		static.WithCertChain(
+			signature.ChainguardReleaseCert,
+			signature.SigstoreChainCert,
+		),
+	)
+	require.NoError(t, err)
+
+	signer, err = signerOf(keyless, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "URIs:https://github.com/chainguard-images/images/.github/workflows/release.yaml@refs/heads/main (https://token.actions.githubusercontent.com)", signer)
+}
+
 func TestFetchImageConfig(t *testing.T) {
 	url := utils.WithDigest("registry.local/test-image")
 	ctx := context.Background()
@@ -756,3 +956,43 @@ kind: ClusterServiceVersion`),
 		"manifests/csv.yaml": json.RawMessage(`{"apiVersion":"operators.coreos.com/v1alpha1","kind":"ClusterServiceVersion"}`),
 	}, a.files)
 }
+
+func TestFetchImageSBOMs(t *testing.T) {
+	ref := name.MustParseReference("registry.io/repository/image:tag")
+	digest := "sha256:4e388ab32b10dc8dbc7e28144f552830adc74787c1e2c0824032078a79f227fb"
+	a := ApplicationSnapshotImage{reference: ref}
+
+	sbom := `{
+		"spdxVersion": "SPDX-2.3",
+		"name": "image",
+		"packages": [{"SPDXID": "SPDXRef-bash", "name": "bash", "versionInfo": "5.2.15"}]
+	}`
+	subject := v1.Descriptor{MediaType: types.OCIManifestSchema1, Digest: v1.Hash{Algorithm: "sha256", Hex: strings.TrimPrefix(digest, "sha256:")}, Size: 1}
+	artifact, err := o.ReferrerArtifact([]byte(sbom), "application/spdx+json", "application/spdx+json", subject, nil)
+	require.NoError(t, err)
+	artifactDigest, err := artifact.Digest()
+	require.NoError(t, err)
+
+	client := fake.FakeClient{}
+	client.On("ResolveDigest", ref).Return(digest, nil)
+	client.On("Referrers", ref.Context().Digest(digest)).Return(mutate.AppendManifests(empty.Index, mutate.IndexAddendum{Add: artifact}), nil)
+	client.On("Image", ref.Context().Digest(artifactDigest.String())).Return(artifact, nil)
+
+	ctx := utils.WithFS(o.WithClient(context.Background(), &client), afero.NewMemMapFs())
+
+	require.NoError(t, a.FetchImageSBOMs(ctx))
+
+	_, input, err := a.WriteInputFile(ctx)
+	require.NoError(t, err)
+
+	var actual struct {
+		Image struct {
+			SBOMs []map[string]any `json:"sboms"`
+		} `json:"image"`
+	}
+	require.NoError(t, json.Unmarshal(input, &actual))
+	require.Len(t, actual.Image.SBOMs, 1)
+	assert.Equal(t, "referrer", actual.Image.SBOMs[0]["source"])
+	assert.Equal(t, "spdx", actual.Image.SBOMs[0]["format"])
+	assert.Equal(t, []any{map[string]any{"name": "bash", "version": "5.2.15"}}, actual.Image.SBOMs[0]["components"])
+}
@@ -20,6 +20,7 @@ import (
 	"context"
 
 	ecc "github.com/enterprise-contract/enterprise-contract-controller/api/v1alpha1"
+	log "github.com/sirupsen/logrus"
 
 	"github.com/enterprise-contract/ec-cli/internal/evaluator"
 	"github.com/enterprise-contract/ec-cli/internal/policy"
@@ -30,26 +31,48 @@ var newConftestEvaluator = evaluator.NewConftestEvaluatorWithNamespace
 
 // DefinitionFile represents the structure needed to evaluate a pipeline definition file
 type Definition struct {
-	Fpath     []string
-	Evaluator evaluator.Evaluator
+	Fpath      []string
+	Evaluators []evaluator.Evaluator
 }
 
-// NewPipelineDefinitionFile returns a DefinitionFile struct with FPath and evaluator ready to use
-func NewDefinition(ctx context.Context, fpath []string, sources []source.PolicySource, namespace []string) (*Definition, error) {
-	p := &Definition{
+// NewPipelineDefinitionFile returns a DefinitionFile struct with FPath and
+// evaluators ready to use. The definitions are evaluated against the given
+// policy and data sources or, when a policy is given, against the source groups
+// of the policy, each with its configuration.
+func NewDefinition(ctx context.Context, fpath []string, sources []source.PolicySource, namespace []string, p policy.Policy) (*Definition, error) {
+	d := &Definition{
 		Fpath: fpath,
 	}
 
-	pol, err := policy.NewOfflinePolicy(ctx, policy.Now)
-	if err != nil {
-		return nil, err
+	if p == nil {
+		pol, err := policy.NewOfflinePolicy(ctx, policy.Now)
+		if err != nil {
+			return nil, err
+		}
+
+		c, err := newConftestEvaluator(ctx, sources, pol, ecc.Source{}, namespace)
+		if err != nil {
+			return nil, err
+		}
+		d.Evaluators = append(d.Evaluators, c)
+
+		return d, nil
 	}
 
-	c, err := newConftestEvaluator(ctx, sources, pol, ecc.Source{}, namespace)
-	if err != nil {
-		return nil, err
+	for _, sourceGroup := range p.Spec().Sources {
+		policySources, err := source.FetchPolicySources(sourceGroup)
+		if err != nil {
+			log.Debugf("Failed to fetch policy source group '%s'!", sourceGroup.Name)
+			return nil, err
+		}
+
+		c, err := newConftestEvaluator(ctx, policySources, p, sourceGroup, namespace)
+		if err != nil {
+			log.Debug("Failed to initialize the conftest evaluator!")
+			return nil, err
+		}
+		d.Evaluators = append(d.Evaluators, c)
 	}
-	p.Evaluator = c
 
-	return p, nil
+	return d, nil
 }
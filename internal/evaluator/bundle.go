@@ -0,0 +1,86 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package evaluator
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/open-policy-agent/opa/loader"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+)
+
+// isBundle returns true if the policy source in the given directory is an OPA
+// bundle, i.e. it has the .manifest file
+func isBundle(fs afero.Fs, dir string) (bool, error) {
+	return afero.Exists(fs, filepath.Join(dir, ".manifest"))
+}
+
+// isBundleData returns true for the names of the data documents of bundles
+func isBundleData(name string) bool {
+	switch name {
+	case "data.json", "data.yaml", "data.yml":
+		return true
+	}
+
+	return false
+}
+
+// writeBundleData writes the data documents of the OPA bundle in the given
+// policy source directory into a data.json file within the data directory.
+// Within bundles the documents are found under the path of the directory
+// holding them, e.g. the content of a/b/data.json is found at data.a.b, while
+// the files within the data directory are all loaded at the root of data, so
+// the documents are merged, as placed in the bundle, into a single file.
+// Policy sources that are not bundles are not affected, data files within them
+// are not loaded.
+func writeBundleData(fs afero.Fs, dir, dataDir string) error {
+	if ok, err := isBundle(fs, dir); err != nil || !ok {
+		return err
+	}
+
+	result, err := loader.NewFileLoader().
+		WithFS(afero.NewIOFS(afero.NewBasePathFs(fs, dir))).
+		Filtered([]string{"."}, func(_ string, info os.FileInfo, _ int) bool {
+			return !info.IsDir() && !isBundleData(info.Name())
+		})
+	if err != nil {
+		return fmt.Errorf("unable to load the data of the bundle in %s: %w", dir, err)
+	}
+
+	if len(result.Documents) == 0 {
+		return nil
+	}
+
+	data, err := json.Marshal(result.Documents)
+	if err != nil {
+		return err
+	}
+
+	dest := filepath.Join(dataDir, "bundle-"+filepath.Base(dir))
+	if err := fs.MkdirAll(dest, 0755); err != nil {
+		return err
+	}
+
+	file := filepath.Join(dest, "data.json")
+	log.Debugf("Writing the data of the bundle in %s to %s", dir, file)
+
+	return afero.WriteFile(fs, file, data, 0444)
+}
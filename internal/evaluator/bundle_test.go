@@ -0,0 +1,147 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package evaluator
+
+import (
+	"context"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/MakeNowJust/heredoc"
+	ecc "github.com/enterprise-contract/enterprise-contract-controller/api/v1alpha1"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/policy"
+	"github.com/enterprise-contract/ec-cli/internal/policy/source"
+)
+
+func TestWriteBundleData(t *testing.T) {
+	cases := []struct {
+		name     string
+		files    map[string]string
+		expected string
+	}{
+		{
+			name: "bundle",
+			files: map[string]string{
+				".manifest":           `{"revision": "1"}`,
+				"data.json":           `{"top": 1}`,
+				"lib/data.json":       `{"allowed": ["a"]}`,
+				"lib/nested/data.yml": `value: true`,
+				"lib/other.json":      `{"ignored": true}`,
+				"lib/main.rego":       `package lib`,
+			},
+			expected: `{"lib":{"allowed":["a"],"nested":{"value":true}},"top":1}`,
+		},
+		{
+			name: "bundle without data",
+			files: map[string]string{
+				".manifest":     `{"revision": "1"}`,
+				"lib/main.rego": `package lib`,
+			},
+		},
+		{
+			name: "not a bundle",
+			files: map[string]string{
+				"lib/data.json": `{"allowed": ["a"]}`,
+				"lib/main.rego": `package lib`,
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			for name, content := range c.files {
+				require.NoError(t, afero.WriteFile(fs, path.Join("/work/policy/abc", name), []byte(content), 0644))
+			}
+
+			require.NoError(t, writeBundleData(fs, "/work/policy/abc", "/work/data"))
+
+			data, err := afero.ReadFile(fs, "/work/data/bundle-abc/data.json")
+			if c.expected == "" {
+				assert.ErrorIs(t, err, os.ErrNotExist)
+				return
+			}
+			require.NoError(t, err)
+			assert.JSONEq(t, c.expected, string(data))
+		})
+	}
+}
+
+func TestWriteBundleDataConflict(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/policy/.manifest", []byte(`{}`), 0644))
+	require.NoError(t, afero.WriteFile(fs, "/policy/data.json", []byte(`{"lib": 1}`), 0644))
+	require.NoError(t, afero.WriteFile(fs, "/policy/lib/data.json", []byte(`{"a": 1}`), 0644))
+
+	assert.ErrorContains(t, writeBundleData(fs, "/policy", "/data"), "unable to load the data of the bundle in /policy")
+}
+
+func TestConftestEvaluatorBundle(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(path.Join(dir, "inputs"), 0755))
+	require.NoError(t, os.WriteFile(path.Join(dir, "inputs", "input.json"), []byte(`{"name": "b"}`), 0600))
+
+	bundleDir := path.Join(dir, "bundle")
+	for name, content := range map[string]string{
+		".manifest":     `{"revision": "1", "roots": ["allowed", "lib"]}`,
+		"lib/data.json": `{"allowed_names": ["a"]}`,
+		"allowed/allowed.rego": heredoc.Doc(`
+			package allowed
+
+			import future.keywords.contains
+			import future.keywords.if
+			import future.keywords.in
+
+			# METADATA
+			# title: Allowed
+			# custom:
+			#   short_name: name
+			deny contains result if {
+				not input.name in data.lib.allowed_names
+				result := {"code": "allowed.name", "msg": sprintf("%s is not allowed", [input.name])}
+			}
+		`),
+	} {
+		require.NoError(t, os.MkdirAll(path.Dir(path.Join(bundleDir, name)), 0755))
+		require.NoError(t, os.WriteFile(path.Join(bundleDir, name), []byte(content), 0600))
+	}
+
+	ctx := withCapabilities(context.Background(), testCapabilities)
+
+	p, err := policy.NewInertPolicy(ctx, "")
+	require.NoError(t, err)
+
+	evaluator, err := NewConftestEvaluator(ctx, []source.PolicySource{
+		&source.PolicyUrl{Url: bundleDir, Kind: source.PolicyKind},
+	}, p, ecc.Source{})
+	require.NoError(t, err)
+	t.Cleanup(evaluator.Destroy)
+
+	results, data, err := evaluator.Evaluate(ctx, EvaluationTarget{Inputs: []string{path.Join(dir, "inputs")}})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	require.Len(t, results[0].Failures, 1)
+	assert.Equal(t, "b is not allowed", results[0].Failures[0].Message)
+	assert.Equal(t, map[string]any{"allowed_names": []any{"a"}}, data["lib"])
+}
@@ -19,28 +19,34 @@ package evaluator
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"maps"
 	"net/url"
 	"os"
 	"path"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	ecc "github.com/enterprise-contract/enterprise-contract-controller/api/v1alpha1"
 	"github.com/open-policy-agent/conftest/output"
+	"github.com/open-policy-agent/conftest/parser"
 	conftest "github.com/open-policy-agent/conftest/policy"
 	"github.com/open-policy-agent/conftest/runner"
 	"github.com/open-policy-agent/opa/ast"
-	"github.com/open-policy-agent/opa/storage"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/afero"
+	"go.opentelemetry.io/otel/attribute"
 	"k8s.io/apimachinery/pkg/util/sets"
 
-	"github.com/enterprise-contract/ec-cli/internal/opa"
+	"github.com/enterprise-contract/ec-cli/internal/logging"
+	"github.com/enterprise-contract/ec-cli/internal/metrics"
 	"github.com/enterprise-contract/ec-cli/internal/opa/rule"
 	"github.com/enterprise-contract/ec-cli/internal/policy"
 	"github.com/enterprise-contract/ec-cli/internal/policy/source"
+	"github.com/enterprise-contract/ec-cli/internal/tracing"
 	"github.com/enterprise-contract/ec-cli/internal/utils"
 )
 
@@ -52,8 +58,8 @@ const (
 	effectiveTimeKey contextKey = "ec.evaluator.effective_time"
 )
 
-// trim removes all failure, warning, success or skipped results that depend on
-// a result reported as failure, warning or skipped. Dependencies are declared
+// trim removes all failure, warning, review, success or skipped results that
+// depend on a result reported as failure, warning, review or skipped. Dependencies are declared
 // by setting the metadata via metadataDependsOn.
 func trim(results *[]Outcome) {
 	// holds codes for all failures, warnings or skipped rules, as a map to ease
@@ -62,7 +68,7 @@ func trim(results *[]Outcome) {
 	reported := map[string]bool{}
 
 	for _, checks := range *results {
-		for _, results := range [][]Result{checks.Failures, checks.Warnings, checks.Skipped} {
+		for _, results := range [][]Result{checks.Failures, checks.Warnings, checks.Reviews, checks.Skipped} {
 			for _, result := range results {
 				if code, ok := result.Metadata[metadataCode].(string); ok {
 					reported[code] = true
@@ -120,6 +126,7 @@ func trim(results *[]Outcome) {
 	for i, checks := range *results {
 		(*results)[i].Failures = addNote(trimOutput(checks.Failures))
 		(*results)[i].Warnings = trimOutput(checks.Warnings)
+		(*results)[i].Reviews = trimOutput(checks.Reviews)
 		(*results)[i].Skipped = trimOutput(checks.Skipped)
 		(*results)[i].Successes = trimOutput(checks.Successes)
 	}
@@ -165,16 +172,18 @@ type testRunner interface {
 }
 
 const (
-	effectiveOnFormat   = "2006-01-02T15:04:05Z"
-	effectiveOnTimeout  = -90 * 24 * time.Hour // keep effective_on metadata up to 90 days
-	metadataCode        = "code"
-	metadataCollections = "collections"
-	metadataDependsOn   = "depends_on"
-	metadataDescription = "description"
-	metadataEffectiveOn = "effective_on"
-	metadataSolution    = "solution"
-	metadataTerm        = "term"
-	metadataTitle       = "title"
+	effectiveOnFormat        = "2006-01-02T15:04:05Z"
+	effectiveOnTimeout       = -90 * 24 * time.Hour // keep effective_on metadata up to 90 days
+	metadataCode             = "code"
+	metadataCollections      = "collections"
+	metadataDependsOn        = "depends_on"
+	metadataDescription      = "description"
+	metadataDocumentationUrl = "documentation_url"
+	metadataEffectiveOn      = "effective_on"
+	metadataSeverity         = "severity"
+	metadataSolution         = "solution"
+	metadataTerm             = "term"
+	metadataTitle            = "title"
 )
 
 // ConfigProvider is a subset of the policy.Policy interface. Its purpose is to codify which parts
@@ -197,23 +206,164 @@ type conftestEvaluator struct {
 	exclude       *Criteria
 	fs            afero.Fs
 	namespace     []string
+	// the policy sources are downloaded on the first evaluation within the
+	// Downloads of the context the evaluator was created with
+	downloads source.Downloads
+	prepared  *preparation
+}
+
+// preparation holds what the evaluator prepares on the first evaluation, the
+// rules of the downloaded policy sources, the data read from the data sources
+// and the compiled policy engines. The rules and data are shared read-only by
+// all following, possibly concurrent, evaluations. A failed preparation is not
+// kept, it is attempted again on the next evaluation.
+type preparation struct {
+	mu    sync.Mutex
+	rules policyRules
+	data  Data
+
+	// the engine records the name of the evaluated file in its store so each
+	// engine evaluates one query at a time, concurrent evaluations compile an
+	// engine of their own when none is idle. The idle engines are kept here
+	// for the following evaluations, so at most one engine is compiled per
+	// concurrent evaluation, e.g. per worker of the validate commands.
+	engines []*conftest.Engine
 }
 
 type conftestRunner struct {
 	runner.TestRunner
+	prepared *preparation
 }
 
-func (r conftestRunner) Run(ctx context.Context, fileList []string) (result []Outcome, data Data, err error) {
+// load returns an idle engine, or compiles the policies into a new engine when
+// none is idle, along with the data read from the data directory on the first
+// use. The returned function makes the engine idle again once the evaluation
+// is done with it.
+func (r conftestRunner) load(ctx context.Context) (*conftest.Engine, Data, func(), error) {
+	r.prepared.mu.Lock()
+	if n := len(r.prepared.engines); n > 0 {
+		engine, data := r.prepared.engines[n-1], r.prepared.data
+		r.prepared.engines = r.prepared.engines[:n-1]
+		r.prepared.mu.Unlock()
+
+		return engine, data, r.release(engine), nil
+	}
+	r.prepared.mu.Unlock()
+
+	// compiled without holding the lock so that concurrent evaluations
+	// compile their engines in parallel
+	engine, data, err := loadEngine(ctx, r.TestRunner)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	r.prepared.mu.Lock()
+	defer r.prepared.mu.Unlock()
+	if r.prepared.data == nil {
+		r.prepared.data = data
+	}
+
+	return engine, r.prepared.data, r.release(engine), nil
+}
+
+// release returns the function making the engine idle
+func (r conftestRunner) release(engine *conftest.Engine) func() {
+	return func() {
+		r.prepared.mu.Lock()
+		defer r.prepared.mu.Unlock()
+
+		r.prepared.engines = append(r.prepared.engines, engine)
+	}
+}
+
+func loadEngine(ctx context.Context, t runner.TestRunner) (*conftest.Engine, Data, error) {
+	engine, err := conftest.LoadWithData(t.Policy, t.Data, t.Capabilities, t.Strict)
+	if err != nil {
+		return nil, nil, fmt.Errorf("load: %w", err)
+	}
+
 	if log.IsLevelEnabled(log.TraceLevel) {
-		r.Trace = true
+		engine.EnableTracing()
+	}
+
+	store := engine.Store()
+
+	txn, err := store.NewTransaction(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer store.Abort(ctx, txn)
+
+	ids := []string{} // everything
+
+	d, err := store.Read(ctx, txn, ids)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	data, ok := d.(map[string]any)
+	if !ok {
+		return nil, nil, fmt.Errorf("could not retrieve data from the policy engine: Data is: %v", d)
+	}
+
+	// the engine adds to the data in the store when evaluating, the copy
+	// keeps the returned data as it was loaded
+	return engine, maps.Clone(data), nil
+}
+
+func (r conftestRunner) Run(ctx context.Context, fileList []string) (result []Outcome, data Data, err error) {
+	var engine *conftest.Engine
+	var release func()
+	engine, data, release, err = r.load(ctx)
+	if err != nil {
+		return
+	}
+	defer release()
+
+	var configurations map[string]any
+	configurations, err = parseInputs(fileList)
+	if err != nil {
+		return
 	}
 
 	var conftestResult []output.CheckResult
-	conftestResult, err = r.TestRunner.Run(ctx, fileList)
+	conftestResult, err = check(ctx, engine, configurations, r.namespaces(engine))
 	if err != nil {
 		return
 	}
 
+	return toOutcomes(conftestResult), data, nil
+}
+
+// namespaces returns the namespaces to evaluate, all namespaces of the
+// policies unless specific namespaces were given
+func (r conftestRunner) namespaces(engine *conftest.Engine) []string {
+	if r.AllNamespaces {
+		return engine.Namespaces()
+	}
+
+	return r.Namespace
+}
+
+// parseInputs parses the given input files and the files of supported formats
+// found in the given directories
+func parseInputs(fileList []string) (map[string]any, error) {
+	files, err := inputFiles(fileList)
+	if err != nil {
+		return nil, err
+	}
+
+	configurations, err := parser.ParseConfigurations(files)
+	if err != nil {
+		return nil, fmt.Errorf("parse configurations: %w", err)
+	}
+
+	return configurations, nil
+}
+
+// toOutcomes converts the results of the Conftest checks to outcomes
+func toOutcomes(conftestResult []output.CheckResult) []Outcome {
+	var result []Outcome
 	for _, res := range conftestResult {
 		if log.IsLevelEnabled(log.TraceLevel) {
 			for _, q := range res.Queries {
@@ -243,37 +393,62 @@ func (r conftestRunner) Run(ctx context.Context, fileList []string) (result []Ou
 		})
 	}
 
-	// we can't reference the engine from the test runner or from the results so
-	// we need to recreate it, this needs to remain the same as in
-	// runner.TestRunner's Run function
-	var engine *conftest.Engine
-	engine, err = conftest.LoadWithData(r.Policy, r.Data, r.Capabilities, r.Strict)
-	if err != nil {
-		return
-	}
+	return result
+}
 
-	store := engine.Store()
+func check(ctx context.Context, engine *conftest.Engine, configurations map[string]any, namespaces []string) ([]output.CheckResult, error) {
+	var results []output.CheckResult
+	for _, namespace := range namespaces {
+		result, err := engine.Check(ctx, configurations, namespace)
+		if err != nil {
+			return nil, fmt.Errorf("query rule: %w", err)
+		}
 
-	var txn storage.Transaction
-	txn, err = store.NewTransaction(ctx)
-	if err != nil {
-		return
+		results = append(results, result...)
 	}
 
-	ids := []string{} // everything
+	return results, nil
+}
 
-	var d any
-	d, err = store.Read(ctx, txn, ids)
-	if err != nil {
-		return
+// inputFiles returns the given files and the files of supported formats found
+// in the given directories, as runner.TestRunner's Run function does
+func inputFiles(fileList []string) ([]string, error) {
+	var files []string
+	for _, file := range fileList {
+		if file == "" {
+			continue
+		}
+
+		info, err := os.Stat(file)
+		if err != nil {
+			return nil, fmt.Errorf("get file info: %w", err)
+		}
+
+		if !info.IsDir() {
+			files = append(files, file)
+			continue
+		}
+
+		if err := filepath.WalkDir(file, func(p string, d os.DirEntry, err error) error {
+			if err != nil {
+				return fmt.Errorf("walk path: %w", err)
+			}
+
+			if !d.IsDir() && parser.FileSupported(p) {
+				files = append(files, p)
+			}
+
+			return nil
+		}); err != nil {
+			return nil, err
+		}
 	}
 
-	var ok bool
-	if data, ok = d.(map[string]any); !ok {
-		err = fmt.Errorf("could not retrieve data from the policy engine: Data is: %v", d)
+	if len(files) == 0 {
+		return nil, errors.New("no files found")
 	}
 
-	return
+	return files, nil
 }
 
 // NewConftestEvaluator returns initialized conftestEvaluator implementing
@@ -283,7 +458,7 @@ func NewConftestEvaluator(ctx context.Context, policySources []source.PolicySour
 }
 
 // set the policy namespace
-func NewConftestEvaluatorWithNamespace(ctx context.Context, policySources []source.PolicySource, p ConfigProvider, source ecc.Source, namespace []string) (Evaluator, error) {
+func NewConftestEvaluatorWithNamespace(ctx context.Context, policySources []source.PolicySource, p ConfigProvider, src ecc.Source, namespace []string) (Evaluator, error) {
 	fs := utils.FS(ctx)
 	c := conftestEvaluator{
 		policySources: policySources,
@@ -291,9 +466,11 @@ func NewConftestEvaluatorWithNamespace(ctx context.Context, policySources []sour
 		policy:        p,
 		fs:            fs,
 		namespace:     namespace,
+		downloads:     source.DownloadsOf(ctx),
+		prepared:      &preparation{},
 	}
 
-	c.include, c.exclude = computeIncludeExclude(source, p)
+	c.include, c.exclude = computeIncludeExclude(src, p)
 
 	dir, err := utils.CreateWorkDir(fs)
 	if err != nil {
@@ -333,18 +510,17 @@ func (c conftestEvaluator) CapabilitiesPath() string {
 type policyRules map[string]rule.Info
 
 func (r *policyRules) collect(a *ast.AnnotationsRef) error {
-	if a.Annotations == nil {
-		return nil
-	}
-
-	info := rule.RuleInfo(a)
-
-	if info.ShortName == "" {
+	info, ok := ruleInfo(a)
+	if !ok {
 		// no short name matching with the code from Metadata will not be
 		// deterministic
 		return nil
 	}
 
+	return r.add(info)
+}
+
+func (r *policyRules) add(info rule.Info) error {
 	code := info.Code
 
 	if _, ok := (*r)[code]; ok {
@@ -355,35 +531,62 @@ func (r *policyRules) collect(a *ast.AnnotationsRef) error {
 	return nil
 }
 
-func (c conftestEvaluator) Evaluate(ctx context.Context, target EvaluationTarget) ([]Outcome, Data, error) {
-	var results []Outcome
+// prepareSources downloads the policy sources and collects the rules found
+// within on the first successful call, the following calls return the same
+// rules so the sources are prepared once for all evaluations
+func (c conftestEvaluator) prepareSources(ctx context.Context) (policyRules, error) {
+	c.prepared.mu.Lock()
+	defer c.prepared.mu.Unlock()
 
+	if c.prepared.rules == nil {
+		rules, err := c.downloadSources(ctx)
+		if err != nil {
+			return nil, err
+		}
+		c.prepared.rules = rules
+	}
+
+	return c.prepared.rules, nil
+}
+
+func (c conftestEvaluator) downloadSources(ctx context.Context) (policyRules, error) {
 	// hold all rule annotations from all policy sources
 	// NOTE: emphasis on _all rules from all sources_; meaning that if two rules
 	// exist with the same code in two separate sources the collected rule
 	// information is not deterministic
 	rules := policyRules{}
-	// Download all sources
-	for _, s := range c.policySources {
-		dir, err := s.GetPolicy(ctx, c.workDir, false)
+	var failed []failedSource
+	// Download all sources, the downloaded sources are processed in order
+	ctx = source.WithDownloads(ctx, c.downloads)
+	for i, fetched := range fetchSources(ctx, c.policySources, c.workDir) {
+		s, dir, err := c.policySources[i], fetched.dir, fetched.err
 		if err != nil {
-			log.Debugf("Unable to download source from %s!", s.PolicyUrl())
-			// TODO do we want to download other policies instead of erroring out?
-			return nil, nil, err
+			log.WithField(logging.PolicySourceField, s.PolicyUrl()).Debugf("Unable to download source from %s!", s.PolicyUrl())
+			failed = append(failed, failedSource{source: s, err: err})
+			continue
 		}
 
-		annotations := []*ast.AnnotationsRef{}
+		var sourceInfo []rule.Info
 		fs := utils.FS(ctx)
 		// We only want to inspect the directory of policy subdirs, not config or data subdirs.
 		if s.Subdir() == "policy" {
-			annotations, err = opa.InspectDir(fs, dir)
+			if err := prepareRegoVersion(ctx, fs, dir); err != nil {
+				log.Debugf("Unable to prepare the rego version of policy source %s!", s.PolicyUrl())
+				return nil, err
+			}
+
+			if err := writeBundleData(fs, dir, c.dataDir); err != nil {
+				return nil, err
+			}
+
+			sourceInfo, err = sourceRules(ctx, fs, dir)
 			if err != nil {
 				errMsg := err
 				if err.Error() == "no rego files found in policy subdirectory" {
 					// Let's try to give some more robust messaging to the user.
 					policyURL, err := url.Parse(s.PolicyUrl())
 					if err != nil {
-						return nil, nil, errMsg
+						return nil, errMsg
 					}
 					// Do we have a prefix at the end of the URL path?
 					// If not, this means we aren't trying to access a specific file.
@@ -397,20 +600,35 @@ func (c conftestEvaluator) Evaluate(ctx context.Context, target EvaluationTarget
 						}
 					}
 				}
-				return nil, nil, errMsg
+				return nil, errMsg
 			}
 		}
 
-		for _, a := range annotations {
-			if a.Annotations == nil {
-				continue
-			}
-			if err := rules.collect(a); err != nil {
-				return nil, nil, err
+		for _, info := range sourceInfo {
+			if err := rules.add(info); err != nil {
+				return nil, err
 			}
 		}
 	}
 
+	if err := checkFailedSources(ctx, c.policySources, failed); err != nil {
+		return nil, err
+	}
+
+	return rules, nil
+}
+
+func (c conftestEvaluator) Evaluate(ctx context.Context, target EvaluationTarget) (_ []Outcome, _ Data, err error) {
+	ctx, span := tracing.Start(ctx, "evaluate", attribute.String("target", target.Target))
+	defer func() { tracing.End(span, err) }()
+
+	var results []Outcome
+
+	rules, err := c.prepareSources(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	var r testRunner
 	var ok bool
 	if r, ok = ctx.Value(runnerKey).(testRunner); r == nil || !ok {
@@ -421,8 +639,8 @@ func (c conftestEvaluator) Evaluate(ctx context.Context, target EvaluationTarget
 			allNamespaces = false
 		}
 
-		r = &conftestRunner{
-			runner.TestRunner{
+		cr := conftestRunner{
+			TestRunner: runner.TestRunner{
 				Data:          []string{c.dataDir},
 				Policy:        []string{c.policyDir},
 				Namespace:     c.namespace,
@@ -431,13 +649,22 @@ func (c conftestEvaluator) Evaluate(ctx context.Context, target EvaluationTarget
 				Output:        c.outputFormat,
 				Capabilities:  c.CapabilitiesPath(),
 			},
+			prepared: c.prepared,
+		}
+
+		if evaluationEngine(ctx) == EngineWasm {
+			r = &wasmRunner{conftestRunner: cr}
+		} else {
+			r = &cr
 		}
 	}
 
 	log.Debugf("runner: %#v", r)
 	log.Debugf("inputs: %#v", target.Inputs)
 
+	start := time.Now()
 	runResults, data, err := r.Run(ctx, target.Inputs)
+	metrics.ObservePolicyEvaluation(time.Since(start))
 	if err != nil {
 		// TODO do we want to evaluate further policies instead of erroring out?
 		return nil, nil, err
@@ -455,6 +682,7 @@ func (c conftestEvaluator) Evaluate(ctx context.Context, target EvaluationTarget
 	for i, result := range runResults {
 		log.Debugf("Evaluation result at %d: %#v", i, result)
 		warnings := []Result{}
+		var reviews []Result
 		failures := []Result{}
 		exceptions := []Result{}
 		skipped := []Result{}
@@ -467,6 +695,11 @@ func (c conftestEvaluator) Evaluate(ctx context.Context, target EvaluationTarget
 				log.Debugf("Skipping result warning: %#v", warning)
 				continue
 			}
+
+			if isReview(warning, rules) {
+				reviews = append(reviews, warning)
+				continue
+			}
 			warnings = append(warnings, warning)
 		}
 
@@ -479,7 +712,8 @@ func (c conftestEvaluator) Evaluate(ctx context.Context, target EvaluationTarget
 				continue
 			}
 
-			if !isResultEffective(failure, effectiveTime) {
+			code := ExtractStringFromMetadata(failure, metadataCode)
+			if !isResultEffective(failure, ruleEffectiveTime(ctx, rules[code], effectiveTime)) {
 				// TODO: Instead of moving to warnings, create new attribute: "futureViolations"
 				warnings = append(warnings, failure)
 			} else {
@@ -500,6 +734,7 @@ func (c conftestEvaluator) Evaluate(ctx context.Context, target EvaluationTarget
 		}
 
 		result.Warnings = warnings
+		result.Reviews = reviews
 		result.Failures = failures
 		result.Exceptions = exceptions
 		result.Skipped = skipped
@@ -507,7 +742,7 @@ func (c conftestEvaluator) Evaluate(ctx context.Context, target EvaluationTarget
 		// Replace the placeholder successes slice with the actual successes.
 		result.Successes = c.computeSuccesses(result, rules, effectiveTime, target.Target)
 
-		totalRules += len(result.Warnings) + len(result.Failures) + len(result.Successes)
+		totalRules += len(result.Warnings) + len(result.Reviews) + len(result.Failures) + len(result.Successes)
 
 		results = append(results, result)
 	}
@@ -544,7 +779,7 @@ func (c conftestEvaluator) computeSuccesses(result Outcome, rules policyRules, e
 	// what rules, by code, have we seen in the Conftest results, use map to
 	// take advantage of hashing for quicker lookup
 	seenRules := map[string]bool{}
-	for _, o := range [][]Result{result.Failures, result.Warnings, result.Skipped, result.Exceptions} {
+	for _, o := range [][]Result{result.Failures, result.Warnings, result.Reviews, result.Skipped, result.Exceptions} {
 		for _, r := range o {
 			if code, ok := r.Metadata[metadataCode].(string); ok {
 				seenRules[code] = true
@@ -584,6 +819,10 @@ func (c conftestEvaluator) computeSuccesses(result Outcome, rules policyRules, e
 			success.Metadata[metadataDescription] = rule.Description
 		}
 
+		if rule.DocumentationUrl != "" {
+			success.Metadata[metadataDocumentationUrl] = rule.DocumentationUrl
+		}
+
 		if len(rule.Collections) > 0 {
 			success.Metadata[metadataCollections] = rule.Collections
 		}
@@ -610,6 +849,18 @@ func (c conftestEvaluator) computeSuccesses(result Outcome, rules policyRules, e
 	return successes
 }
 
+// isReview returns true if the result was produced by a review rule, i.e. a
+// warn_review rule, which conftest reports as a warning.
+func isReview(result Result, rules policyRules) bool {
+	code, ok := result.Metadata[metadataCode].(string)
+	if !ok {
+		return false
+	}
+
+	r, ok := rules[code]
+	return ok && r.Kind == rule.Review
+}
+
 func addRuleMetadata(ctx context.Context, result *Result, rules policyRules) {
 	code, ok := (*result).Metadata[metadataCode].(string)
 	if ok {
@@ -656,6 +907,14 @@ func addMetadataToResults(ctx context.Context, r *Result, rule rule.Info) {
 	if rule.Solution != "" {
 		r.Metadata[metadataSolution] = rule.Solution
 	}
+	if rule.DocumentationUrl != "" {
+		r.Metadata[metadataDocumentationUrl] = rule.DocumentationUrl
+	}
+	// the severity given by the result, e.g. the severity of a vulnerability,
+	// takes precedence over the severity of the rule
+	if _, ok := r.Metadata[metadataSeverity]; !ok && rule.Severity != "" {
+		r.Metadata[metadataSeverity] = rule.Severity
+	}
 	if len(rule.Collections) > 0 {
 		r.Metadata[metadataCollections] = rule.Collections
 	}
@@ -666,6 +925,7 @@ func addMetadataToResults(ctx context.Context, r *Result, rule rule.Info) {
 	// If the rule has been effective for a long time, we'll consider
 	// the effective_on date not relevant and not bother including it
 	if effectiveTime, ok := ctx.Value(effectiveTimeKey).(time.Time); ok {
+		effectiveTime = ruleEffectiveTime(ctx, rule, effectiveTime)
 		if effectiveOnString, ok := r.Metadata[metadataEffectiveOn].(string); ok {
 			effectiveOnTime, err := time.Parse(effectiveOnFormat, effectiveOnString)
 			if err == nil {
@@ -947,6 +1207,12 @@ func strictCapabilities(ctx context.Context) (string, error) {
 		return c, nil
 	}
 
+	return defaultCapabilities()
+}
+
+// defaultCapabilities is the JSON serialized strict capabilities, computed once
+// as they don't change for the lifetime of the process
+var defaultCapabilities = sync.OnceValues(func() (string, error) {
 	capabilities := ast.CapabilitiesForThisVersion()
 	// An empty list means no hosts can be reached. However, a nil value means all
 	// hosts can be reached. Unfortunately, the required JSON marshalling process
@@ -978,4 +1244,4 @@ func strictCapabilities(ctx context.Context) (string, error) {
 		return "", err
 	}
 	return string(blob), nil
-}
+})
@@ -23,15 +23,20 @@ import (
 	"context"
 	"embed"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"io/fs"
 	"os"
 	"path"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"testing/fstest"
 	"time"
 
 	"github.com/MakeNowJust/heredoc"
@@ -1324,6 +1329,14 @@ func TestRuleMetadata(t *testing.T) {
 			Description: "Warning 3 description",
 			EffectiveOn: effectiveOnTest,
 		},
+		"failure3": rule.Info{
+			Title:            "Failure3",
+			DocumentationUrl: "https://enterprisecontract.dev/docs/ec-policies/release_policy.html#pkg__failure3",
+		},
+		"failure4": rule.Info{
+			Title:    "Failure4",
+			Severity: "low",
+		},
 	}
 	cases := []struct {
 		name   string
@@ -1403,6 +1416,55 @@ func TestRuleMetadata(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "add documentation url",
+			result: Result{
+				Metadata: map[string]any{
+					"code": "failure3",
+				},
+			},
+			rules: rules,
+			want: Result{
+				Metadata: map[string]any{
+					"code":              "failure3",
+					"title":             "Failure3",
+					"documentation_url": "https://enterprisecontract.dev/docs/ec-policies/release_policy.html#pkg__failure3",
+				},
+			},
+		},
+		{
+			name: "add severity",
+			result: Result{
+				Metadata: map[string]any{
+					"code": "failure4",
+				},
+			},
+			rules: rules,
+			want: Result{
+				Metadata: map[string]any{
+					"code":     "failure4",
+					"title":    "Failure4",
+					"severity": "low",
+				},
+			},
+		},
+		{
+			name: "keep the severity of the result",
+			result: Result{
+				Metadata: map[string]any{
+					"code":     "failure4",
+					"severity": "critical",
+				},
+			},
+			rules: rules,
+			want: Result{
+				Metadata: map[string]any{
+					"code":     "failure4",
+					"title":    "Failure4",
+					"severity": "critical",
+				},
+			},
+		},
 		{
 			name: "rule not found",
 			result: Result{
@@ -1791,6 +1853,191 @@ func TestUnconformingRule(t *testing.T) {
 	assert.EqualError(t, err, `the rule "deny = true { true }" returns an unsupported value, at no_msg.rego:3`)
 }
 
+func TestConftestEvaluatorReviewRules(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(path.Join(dir, "inputs"), 0755))
+	require.NoError(t, os.WriteFile(path.Join(dir, "inputs", "data.json"), []byte("{}"), 0600))
+
+	rules, err := rulesArchive(t, fstest.MapFS{
+		"review.rego": &fstest.MapFile{Data: []byte(heredoc.Doc(`
+			package review
+
+			import future.keywords.contains
+			import future.keywords.if
+
+			# METADATA
+			# title: Approval
+			# custom:
+			#   short_name: approval
+			warn_review contains result if {
+				not input.approval
+				result := {"code": "review.approval", "msg": "No approval record found"}
+			}
+
+			# METADATA
+			# title: Warning
+			# custom:
+			#   short_name: warning
+			warn contains result if {
+				result := {"code": "review.warning", "msg": "Warning!"}
+			}
+
+			# METADATA
+			# title: Dependent
+			# custom:
+			#   short_name: dependent
+			#   depends_on:
+			#   - review.approval
+			deny contains result if {
+				false
+				result := {"code": "review.dependent", "msg": "Failure!"}
+			}
+		`))},
+	})
+	require.NoError(t, err)
+
+	ctx := withCapabilities(context.Background(), testCapabilities)
+
+	p, err := policy.NewInertPolicy(ctx, "")
+	require.NoError(t, err)
+
+	evaluator, err := NewConftestEvaluator(ctx, []source.PolicySource{
+		&source.PolicyUrl{Url: rules, Kind: source.PolicyKind},
+	}, p, ecc.Source{})
+	require.NoError(t, err)
+
+	results, _, err := evaluator.Evaluate(ctx, EvaluationTarget{Inputs: []string{path.Join(dir, "inputs")}})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+
+	codes := func(results []Result) []string {
+		c := []string{}
+		for _, r := range results {
+			c = append(c, r.Metadata[metadataCode].(string))
+		}
+		return c
+	}
+
+	assert.Equal(t, []string{"review.approval"}, codes(results[0].Reviews))
+	assert.Equal(t, "No approval record found", results[0].Reviews[0].Message)
+	assert.Equal(t, []string{"review.warning"}, codes(results[0].Warnings))
+	assert.Empty(t, results[0].Failures)
+	// the success of the dependent rule is trimmed as the rule it depends on
+	// requires review
+	assert.Empty(t, results[0].Successes)
+}
+
+type countingPolicySource struct {
+	testPolicySource
+	calls *atomic.Int32
+	// number of calls failing before the source is available
+	failures int32
+}
+
+func (c countingPolicySource) GetPolicy(ctx context.Context, dest string, showMsg bool) (string, error) {
+	if c.calls.Add(1) <= c.failures {
+		return "", errors.New("connection refused")
+	}
+
+	return c.testPolicySource.GetPolicy(ctx, dest, showMsg)
+}
+
+func TestConftestEvaluatorPreparesSourcesOnce(t *testing.T) {
+	r := mockTestRunner{}
+	ctx := setupTestContext(&r, &mockDownloader{})
+
+	inputs := EvaluationTarget{Inputs: []string{"inputs"}}
+	r.On("Run", ctx, inputs.Inputs).Return([]Outcome{{Failures: []Result{{Message: "failure"}}}}, Data{}, nil)
+
+	pol, err := policy.NewOfflinePolicy(ctx, policy.Now)
+	require.NoError(t, err)
+
+	src := countingPolicySource{calls: &atomic.Int32{}, failures: 1}
+	evaluator, err := NewConftestEvaluator(ctx, []source.PolicySource{src}, pol, ecc.Source{})
+	require.NoError(t, err)
+
+	// failures are not kept, the next evaluation attempts the download again
+	_, _, err = evaluator.Evaluate(ctx, inputs)
+	assert.ErrorContains(t, err, "connection refused")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _, err := evaluator.Evaluate(ctx, inputs)
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(2), src.calls.Load())
+}
+
+func TestConftestEvaluatorReusesEngines(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"pass", "fail"} {
+		require.NoError(t, os.MkdirAll(path.Join(dir, name), 0755))
+		require.NoError(t, os.WriteFile(path.Join(dir, name, "input.json"), []byte(fmt.Sprintf(`{"fail": %t}`, name == "fail")), 0600))
+	}
+
+	rules, err := rulesArchive(t, fstest.MapFS{
+		"shared.rego": &fstest.MapFile{Data: []byte(heredoc.Doc(`
+			package shared
+
+			import future.keywords.contains
+			import future.keywords.if
+
+			# METADATA
+			# title: Failing
+			# custom:
+			#   short_name: failing
+			deny contains result if {
+				input.fail
+				result := {"code": "shared.failing", "msg": "Failure!"}
+			}
+		`))},
+	})
+	require.NoError(t, err)
+
+	ctx := withCapabilities(context.Background(), testCapabilities)
+
+	p, err := policy.NewInertPolicy(ctx, "")
+	require.NoError(t, err)
+
+	evaluator, err := NewConftestEvaluator(ctx, []source.PolicySource{
+		&source.PolicyUrl{Url: rules, Kind: source.PolicyKind},
+	}, p, ecc.Source{})
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		name := []string{"pass", "fail"}[i%2]
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results, data, err := evaluator.Evaluate(ctx, EvaluationTarget{Inputs: []string{path.Join(dir, name)}})
+			if !assert.NoError(t, err) || !assert.Len(t, results, 1) {
+				return
+			}
+			assert.Equal(t, path.Join(dir, name, "input.json"), results[0].FileName)
+			assert.Equal(t, name == "fail", len(results[0].Failures) == 1)
+			assert.Equal(t, name == "pass", len(results[0].Successes) == 1)
+			assert.NotContains(t, data, "conftest")
+		}()
+	}
+	wg.Wait()
+
+	// at most one engine is compiled per concurrent evaluation
+	engines := slices.Clone(evaluator.(conftestEvaluator).prepared.engines)
+	assert.NotEmpty(t, engines)
+	assert.LessOrEqual(t, len(engines), 2)
+
+	_, _, err = evaluator.Evaluate(ctx, EvaluationTarget{Inputs: []string{path.Join(dir, "pass")}})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, engines, evaluator.(conftestEvaluator).prepared.engines)
+}
+
 func TestNewConftestEvaluatorComputeIncludeExclude(t *testing.T) {
 	cases := []struct {
 		name            string
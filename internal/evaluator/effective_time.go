@@ -0,0 +1,88 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package evaluator
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/enterprise-contract/ec-cli/internal/opa/rule"
+)
+
+const ruleEffectiveTimesKey contextKey = "ec.evaluator.rule_effective_times"
+
+// RuleEffectiveTimes holds the effective times configured for rules, keyed by
+// the rule code or by the rule package.
+type RuleEffectiveTimes map[string]time.Time
+
+// WithRuleEffectiveTimes configures the effective times used for the given
+// rules instead of the effective time of the policy.
+func WithRuleEffectiveTimes(ctx context.Context, times RuleEffectiveTimes) context.Context {
+	return context.WithValue(ctx, ruleEffectiveTimesKey, times)
+}
+
+// ParseRuleEffectiveTimes parses overrides in the form of name=time, where the
+// name is either the rule code or the rule package and the time is in RFC3339
+// format, e.g. "attestation_type.known_attestation_type=2024-01-01T00:00:00Z".
+func ParseRuleEffectiveTimes(overrides []string) (RuleEffectiveTimes, error) {
+	times := RuleEffectiveTimes{}
+	for _, o := range overrides {
+		name, value, ok := strings.Cut(o, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid rule effective time %q: expecting name=time", o)
+		}
+
+		t, err := time.Parse(time.RFC3339, strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("invalid rule effective time %q: %w", o, err)
+		}
+
+		times[name] = t.UTC()
+	}
+
+	return times, nil
+}
+
+// ruleEffectiveTime returns the effective time to use for the given rule. In
+// order of precedence that is the time configured for the rule code, the time
+// configured for the rule package, the time declared by the rule via the
+// effective_time annotation, and lastly the effective time of the policy.
+func ruleEffectiveTime(ctx context.Context, info rule.Info, global time.Time) time.Time {
+	if times, ok := ctx.Value(ruleEffectiveTimesKey).(RuleEffectiveTimes); ok {
+		if t, ok := times[info.Code]; ok && info.Code != "" {
+			return t
+		}
+		if t, ok := times[info.CodePackage]; ok && info.CodePackage != "" {
+			return t
+		}
+	}
+
+	if info.EffectiveTime != "" {
+		t, err := time.Parse(time.RFC3339, info.EffectiveTime)
+		if err == nil {
+			return t.UTC()
+		}
+		log.Warnf("Ignoring invalid effective_time value %q of rule %q", info.EffectiveTime, info.Code)
+	}
+
+	return global
+}
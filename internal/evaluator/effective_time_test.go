@@ -0,0 +1,150 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package evaluator
+
+import (
+	"context"
+	"os"
+	"path"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/MakeNowJust/heredoc"
+	ecc "github.com/enterprise-contract/enterprise-contract-controller/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/opa/rule"
+	"github.com/enterprise-contract/ec-cli/internal/policy"
+	"github.com/enterprise-contract/ec-cli/internal/policy/source"
+)
+
+func TestParseRuleEffectiveTimes(t *testing.T) {
+	times, err := ParseRuleEffectiveTimes([]string{
+		"pkg.rule=2024-01-01T00:00:00Z",
+		" other = 2024-02-01T01:00:00+01:00",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, RuleEffectiveTimes{
+		"pkg.rule": time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		"other":    time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+	}, times)
+
+	_, err = ParseRuleEffectiveTimes([]string{"pkg.rule"})
+	assert.EqualError(t, err, `invalid rule effective time "pkg.rule": expecting name=time`)
+
+	_, err = ParseRuleEffectiveTimes([]string{"pkg.rule=yesterday"})
+	assert.ErrorContains(t, err, `invalid rule effective time "pkg.rule=yesterday": parsing time "yesterday"`)
+}
+
+func TestRuleEffectiveTime(t *testing.T) {
+	global := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	annotated := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	byPackage := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	byCode := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+
+	info := rule.Info{Code: "pkg.rule", CodePackage: "pkg", EffectiveTime: "2024-02-01T00:00:00Z"}
+
+	ctx := context.Background()
+	assert.Equal(t, global, ruleEffectiveTime(ctx, rule.Info{Code: "pkg.rule", CodePackage: "pkg"}, global))
+	assert.Equal(t, global, ruleEffectiveTime(ctx, rule.Info{Code: "pkg.rule", EffectiveTime: "invalid"}, global))
+	assert.Equal(t, annotated, ruleEffectiveTime(ctx, info, global))
+
+	ctx = WithRuleEffectiveTimes(context.Background(), RuleEffectiveTimes{"pkg": byPackage})
+	assert.Equal(t, byPackage, ruleEffectiveTime(ctx, info, global))
+
+	ctx = WithRuleEffectiveTimes(context.Background(), RuleEffectiveTimes{"pkg": byPackage, "pkg.rule": byCode})
+	assert.Equal(t, byCode, ruleEffectiveTime(ctx, info, global))
+	assert.Equal(t, global, ruleEffectiveTime(ctx, rule.Info{Code: "other.rule", CodePackage: "other"}, global))
+}
+
+func TestConftestEvaluatorRuleEffectiveTimes(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(path.Join(dir, "inputs"), 0755))
+	require.NoError(t, os.WriteFile(path.Join(dir, "inputs", "data.json"), []byte("{}"), 0600))
+
+	// all rules become effective on 2024-06-01, the global effective time is
+	// before that
+	rules, err := rulesArchive(t, fstest.MapFS{
+		"timed.rego": &fstest.MapFile{Data: []byte(heredoc.Doc(`
+			package timed
+
+			import future.keywords.contains
+			import future.keywords.if
+
+			# METADATA
+			# title: Global
+			# custom:
+			#   short_name: global
+			#   effective_on: '2024-06-01T00:00:00Z'
+			deny contains result if {
+				result := {"code": "timed.global", "msg": "Global"}
+			}
+
+			# METADATA
+			# title: Annotated
+			# custom:
+			#   short_name: annotated
+			#   effective_on: '2024-06-01T00:00:00Z'
+			#   effective_time: '2024-07-01T00:00:00Z'
+			deny contains result if {
+				result := {"code": "timed.annotated", "msg": "Annotated"}
+			}
+
+			# METADATA
+			# title: Configured
+			# custom:
+			#   short_name: configured
+			#   effective_on: '2024-06-01T00:00:00Z'
+			deny contains result if {
+				result := {"code": "timed.configured", "msg": "Configured"}
+			}
+		`))},
+	})
+	require.NoError(t, err)
+
+	ctx := withCapabilities(context.Background(), testCapabilities)
+	ctx = WithRuleEffectiveTimes(ctx, RuleEffectiveTimes{
+		"timed.configured": time.Date(2024, 8, 1, 0, 0, 0, 0, time.UTC),
+	})
+
+	p, err := policy.NewOfflinePolicy(ctx, "2024-01-01T00:00:00Z")
+	require.NoError(t, err)
+
+	evaluator, err := NewConftestEvaluator(ctx, []source.PolicySource{
+		&source.PolicyUrl{Url: rules, Kind: source.PolicyKind},
+	}, p, ecc.Source{})
+	require.NoError(t, err)
+
+	results, _, err := evaluator.Evaluate(ctx, EvaluationTarget{Inputs: []string{path.Join(dir, "inputs")}})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+
+	codes := func(results []Result) []string {
+		c := []string{}
+		for _, r := range results {
+			c = append(c, r.Metadata[metadataCode].(string))
+		}
+		return c
+	}
+
+	assert.ElementsMatch(t, []string{"timed.annotated", "timed.configured"}, codes(results[0].Failures))
+	assert.Equal(t, []string{"timed.global"}, codes(results[0].Warnings))
+}
@@ -43,6 +43,7 @@ type Outcome struct {
 	Successes  []Result `json:"successes,omitempty"`
 	Skipped    []Result `json:"skipped,omitempty"`
 	Warnings   []Result `json:"warnings,omitempty"`
+	Reviews    []Result `json:"reviews,omitempty"`
 	Failures   []Result `json:"failures,omitempty"`
 	Exceptions []Result `json:"exceptions,omitempty"`
 }
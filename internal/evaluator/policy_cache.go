@@ -0,0 +1,192 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package evaluator
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/open-policy-agent/opa/ast"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+
+	"github.com/enterprise-contract/ec-cli/internal/downloader"
+	"github.com/enterprise-contract/ec-cli/internal/opa"
+	"github.com/enterprise-contract/ec-cli/internal/opa/rule"
+	"github.com/enterprise-contract/ec-cli/internal/version"
+)
+
+const policyCacheKey contextKey = "ec.evaluator.policy_cache"
+
+// policyCache is a directory holding the rule metadata read from the policy
+// sources, each in a file named by the digest of the source content. Only the
+// metadata is cached, the policies are compiled for every evaluator.
+type policyCache struct {
+	dir string
+}
+
+// WithPolicyCache returns a context under which the rule metadata read from the
+// policy sources is stored in the given directory and reused when a policy
+// source with the same content is evaluated again, skipping the parsing of its
+// Rego files for the metadata. The entries are keyed by the digest of the downloaded
+// source, as computed by downloader.Digest, so they never go stale and are
+// removed only by clearing the cache.
+func WithPolicyCache(ctx context.Context, dir string) context.Context {
+	return context.WithValue(ctx, policyCacheKey, &policyCache{dir: dir})
+}
+
+func compiledPolicyCache(ctx context.Context) *policyCache {
+	if c, ok := ctx.Value(policyCacheKey).(*policyCache); ok {
+		return c
+	}
+
+	return nil
+}
+
+// DefaultPolicyCacheDir returns the directory where the rule metadata is cached
+// by default, that is ec/compiled within $XDG_CACHE_HOME, or the
+// platform specific user cache directory
+func DefaultPolicyCacheDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "ec", "compiled"), nil
+}
+
+// entry returns the path of the cache entry for the policy source with the
+// given digest. The version of ec is part of the key as the rules read from
+// the same source can differ between versions.
+func (c *policyCache) entry(digest string) string {
+	return filepath.Join(c.dir, fmt.Sprintf("%x.json", sha256.Sum256([]byte(version.Version+"\n"+digest))))
+}
+
+// load returns the cached rules of the policy source with the given digest,
+// or false if the source is not cached
+func (c *policyCache) load(fs afero.Fs, digest string) ([]rule.Info, bool) {
+	entry := c.entry(digest)
+	data, err := afero.ReadFile(fs, entry)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			log.Debugf("Unable to read the policy cache entry %s: %v", entry, err)
+		}
+		return nil, false
+	}
+
+	var rules []rule.Info
+	if err := json.Unmarshal(data, &rules); err != nil {
+		log.Debugf("Ignoring the malformed policy cache entry %s: %v", entry, err)
+		return nil, false
+	}
+
+	log.Debugf("Policy cache hit: %s", entry)
+	return rules, true
+}
+
+// store writes the rules of the policy source with the given digest into the
+// cache. The rules are written into a temporary file which then replaces the
+// cache entry, so a cache entry is never partially written.
+func (c *policyCache) store(fs afero.Fs, digest string, rules []rule.Info) error {
+	data, err := json.Marshal(rules)
+	if err != nil {
+		return err
+	}
+
+	if err := fs.MkdirAll(c.dir, 0755); err != nil {
+		return err
+	}
+
+	tmp, err := afero.TempFile(fs, c.dir, "tmp-")
+	if err != nil {
+		return err
+	}
+
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+
+	entry := c.entry(digest)
+	if err == nil {
+		log.Debugf("Storing the rule metadata in the policy cache at %s", entry)
+		err = fs.Rename(tmp.Name(), entry)
+	}
+
+	if err != nil {
+		_ = fs.Remove(tmp.Name())
+	}
+
+	return err
+}
+
+// sourceRules returns the rules of the policy source in the given directory,
+// from the policy cache when the source is found in it, otherwise by
+// inspecting the Rego files of the source. Only the rules with a short name
+// are returned, for others the matching with the code from the metadata would
+// not be deterministic.
+func sourceRules(ctx context.Context, fs afero.Fs, dir string) ([]rule.Info, error) {
+	cache := compiledPolicyCache(ctx)
+
+	var digest string
+	if cache != nil {
+		var err error
+		if digest, err = downloader.Digest(fs, dir); err != nil {
+			log.Debugf("Not using the policy cache, unable to compute the digest of %s: %v", dir, err)
+			cache = nil
+		} else if rules, ok := cache.load(fs, digest); ok {
+			return rules, nil
+		}
+	}
+
+	annotations, err := opa.InspectDir(fs, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	rules := make([]rule.Info, 0, len(annotations))
+	for _, a := range annotations {
+		if info, ok := ruleInfo(a); ok {
+			rules = append(rules, info)
+		}
+	}
+
+	if cache != nil {
+		if err := cache.store(fs, digest, rules); err != nil {
+			log.Warnf("Unable to store the rule metadata in the policy cache at %s: %v", cache.dir, err)
+		}
+	}
+
+	return rules, nil
+}
+
+// ruleInfo returns the information of the annotated rule, or false if the
+// rule has no annotations or no short name
+func ruleInfo(a *ast.AnnotationsRef) (rule.Info, bool) {
+	if a.Annotations == nil {
+		return rule.Info{}, false
+	}
+
+	info := rule.RuleInfo(a)
+
+	return info, info.ShortName != ""
+}
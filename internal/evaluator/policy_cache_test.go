@@ -0,0 +1,138 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package evaluator
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/downloader"
+	"github.com/enterprise-contract/ec-cli/internal/opa/rule"
+)
+
+var cachedPolicy = heredoc.Doc(`
+	package main
+
+	import rego.v1
+
+	# METADATA
+	# title: Cached
+	# custom:
+	#   short_name: cached
+	deny contains result if {
+		result := {"code": "main.cached", "msg": "cached"}
+	}
+
+	# METADATA
+	# title: Without short name
+	deny contains "no short name" if {
+		false
+	}
+`)
+
+func TestSourceRules(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/policy/main.rego", []byte(cachedPolicy), 0644))
+
+	expected := []rule.Info{{
+		Code:        "main.cached",
+		CodePackage: "main",
+		Collections: []string{},
+		DependsOn:   []string{},
+		Kind:        rule.Deny,
+		Package:     "main",
+		ShortName:   "cached",
+		Title:       "Cached",
+	}}
+
+	ctx := WithPolicyCache(context.Background(), "/cache")
+
+	rules, err := sourceRules(ctx, fs, "/policy")
+	require.NoError(t, err)
+	assert.Equal(t, expected, rules)
+
+	entries, err := afero.ReadDir(fs, "/cache")
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	entry := filepath.Join("/cache", entries[0].Name())
+
+	// the cached rules are used without inspecting the source again
+	cached := []rule.Info{{Code: "main.from_cache", ShortName: "from_cache"}}
+	cache := compiledPolicyCache(ctx)
+	require.Equal(t, entry, cache.entry(mustDigest(t, fs, "/policy")))
+	require.NoError(t, cache.store(fs, mustDigest(t, fs, "/policy"), cached))
+
+	rules, err = sourceRules(ctx, fs, "/policy")
+	require.NoError(t, err)
+	assert.Equal(t, cached, rules)
+
+	// a change of the source content is a cache miss
+	require.NoError(t, afero.WriteFile(fs, "/policy/other.rego", []byte("package other\n"), 0644))
+	rules, err = sourceRules(ctx, fs, "/policy")
+	require.NoError(t, err)
+	assert.Equal(t, expected, rules)
+
+	entries, err = afero.ReadDir(fs, "/cache")
+	require.NoError(t, err)
+	assert.Len(t, entries, 2)
+}
+
+func TestSourceRulesMalformedCacheEntry(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/policy/main.rego", []byte(cachedPolicy), 0644))
+
+	ctx := WithPolicyCache(context.Background(), "/cache")
+	cache := compiledPolicyCache(ctx)
+	entry := cache.entry(mustDigest(t, fs, "/policy"))
+	require.NoError(t, afero.WriteFile(fs, entry, []byte("not json"), 0644))
+
+	rules, err := sourceRules(ctx, fs, "/policy")
+	require.NoError(t, err)
+	require.Len(t, rules, 1)
+	assert.Equal(t, "main.cached", rules[0].Code)
+
+	// the malformed entry is replaced
+	_, ok := cache.load(fs, mustDigest(t, fs, "/policy"))
+	assert.True(t, ok)
+}
+
+func TestSourceRulesWithoutCache(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/policy/main.rego", []byte(cachedPolicy), 0644))
+
+	rules, err := sourceRules(context.Background(), fs, "/policy")
+	require.NoError(t, err)
+	require.Len(t, rules, 1)
+
+	exists, err := afero.Exists(fs, "/cache")
+	require.NoError(t, err)
+	assert.False(t, exists)
+}
+
+func mustDigest(t *testing.T, fs afero.Fs, dir string) string {
+	digest, err := downloader.Digest(fs, dir)
+	require.NoError(t, err)
+	return digest
+}
@@ -0,0 +1,265 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package evaluator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/format"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+)
+
+// Supported values for selecting the syntax of the rego policy modules.
+const (
+	// RegoV0 is the original Rego syntax, the default
+	RegoV0 = "v0"
+	// RegoV1 is the syntax enforced by OPA 1.0, i.e. no `import future` needed
+	RegoV1 = "v1"
+	// RegoAuto detects the syntax per policy source from the `rego_version`
+	// and `file_rego_versions` attributes of the OPA bundle `.manifest` file,
+	// defaulting to RegoV0 if not present
+	RegoAuto = "auto"
+)
+
+var RegoVersions = []string{RegoV0, RegoV1, RegoAuto}
+
+const regoVersionKey contextKey = "ec.evaluator.rego_version"
+
+// WithRegoVersion sets the syntax version of the rego policy modules, one of
+// RegoVersions, to use when evaluating.
+func WithRegoVersion(ctx context.Context, version string) context.Context {
+	return context.WithValue(ctx, regoVersionKey, version)
+}
+
+// ValidateRegoVersion returns an error if the given version is not supported
+func ValidateRegoVersion(version string) error {
+	for _, v := range RegoVersions {
+		if v == version {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("unsupported rego version %q, expecting one of: %s", version, strings.Join(RegoVersions, ", "))
+}
+
+func regoVersion(ctx context.Context) string {
+	if v, ok := ctx.Value(regoVersionKey).(string); ok && v != "" {
+		return v
+	}
+
+	return RegoV0
+}
+
+// bundleManifest holds the attributes of the OPA bundle manifest relevant to
+// determining the rego version of the modules within the bundle
+type bundleManifest struct {
+	RegoVersion      *int           `json:"rego_version,omitempty"`
+	FileRegoVersions map[string]int `json:"file_rego_versions,omitempty"`
+}
+
+// moduleVersion returns the rego version to use for the module at the given
+// path, relative to the bundle root.
+func (m bundleManifest) moduleVersion(file string) ast.RegoVersion {
+	file = "/" + strings.TrimPrefix(filepath.ToSlash(file), "/")
+	for pattern, v := range m.FileRegoVersions {
+		pattern = "/" + strings.TrimPrefix(pattern, "/")
+		if ok, _ := path.Match(pattern, file); ok {
+			return toRegoVersion(v)
+		}
+	}
+
+	if m.RegoVersion != nil {
+		return toRegoVersion(*m.RegoVersion)
+	}
+
+	return ast.RegoV0
+}
+
+func toRegoVersion(v int) ast.RegoVersion {
+	if v == 1 {
+		return ast.RegoV1
+	}
+
+	return ast.RegoV0
+}
+
+func readBundleManifest(fs afero.Fs, dir string) (bundleManifest, error) {
+	manifest := bundleManifest{}
+
+	b, err := afero.ReadFile(fs, filepath.Join(dir, ".manifest"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return manifest, nil
+		}
+		return manifest, err
+	}
+
+	if err := json.Unmarshal(b, &manifest); err != nil {
+		return manifest, fmt.Errorf("unable to parse bundle manifest in %s: %w", dir, err)
+	}
+
+	return manifest, nil
+}
+
+// prepareRegoVersion makes the rego modules in the given directory loadable by
+// the Conftest engine which always parses modules using the v0 syntax. Modules
+// written using the v1 syntax are rewritten to the equivalent v0 syntax that
+// is v1 compatible, i.e. with `import rego.v1`. Rewritten modules remain
+// valid v1 modules, so the conversion can safely be performed more than once.
+// The directory can be a symbolic link to the policy source, e.g. to a local
+// directory, in that case the link is replaced by a copy of the source within
+// the work directory, so the modules of the source are never rewritten.
+func prepareRegoVersion(ctx context.Context, afs afero.Fs, dir string) error {
+	version := regoVersion(ctx)
+	if version == RegoV0 {
+		return nil
+	}
+
+	manifest := bundleManifest{}
+	if version == RegoAuto {
+		var err error
+		if manifest, err = readBundleManifest(afs, dir); err != nil {
+			return err
+		}
+	}
+
+	// IMPORTANT: afero.Walk doesn't follow the directory if it is a symlink,
+	// fs.WalkDir does, see InspectDir
+	var modules []string
+	if err := fs.WalkDir(afero.NewIOFS(afero.NewBasePathFs(afs, dir)), ".", func(file string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() || filepath.Ext(file) != ".rego" {
+			return nil
+		}
+
+		if version == RegoAuto && manifest.moduleVersion(file) != ast.RegoV1 {
+			return nil
+		}
+
+		modules = append(modules, file)
+
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	if len(modules) == 0 {
+		return nil
+	}
+
+	if err := copyLinkedDir(afs, dir); err != nil {
+		return err
+	}
+
+	for _, m := range modules {
+		if err := convertToV0CompatV1(afs, filepath.Join(dir, m)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// copyLinkedDir replaces the directory, if it is a symbolic link, with a copy
+// of the directory it links to
+func copyLinkedDir(afs afero.Fs, dir string) error {
+	lstater, ok := afs.(afero.Lstater)
+	if !ok {
+		return nil
+	}
+
+	info, lstatCalled, err := lstater.LstatIfPossible(dir)
+	if err != nil {
+		return err
+	}
+	if !lstatCalled || info.Mode()&os.ModeSymlink == 0 {
+		return nil
+	}
+
+	tmp, err := afero.TempDir(afs, filepath.Dir(dir), filepath.Base(dir))
+	if err != nil {
+		return err
+	}
+
+	src := afero.NewIOFS(afero.NewBasePathFs(afs, dir))
+	if err := fs.WalkDir(src, ".", func(file string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		dest := filepath.Join(tmp, file)
+		if d.IsDir() {
+			return afs.MkdirAll(dest, 0755)
+		}
+
+		data, err := fs.ReadFile(src, file)
+		if err != nil {
+			return err
+		}
+
+		return afero.WriteFile(afs, dest, data, 0644)
+	}); err != nil {
+		_ = afs.RemoveAll(tmp)
+		return err
+	}
+
+	log.Debugf("Copied %s to convert its rego v1 modules", dir)
+
+	// removes only the link
+	if err := afs.Remove(dir); err != nil {
+		_ = afs.RemoveAll(tmp)
+		return err
+	}
+
+	return afs.Rename(tmp, dir)
+}
+
+func convertToV0CompatV1(afs afero.Fs, file string) error {
+	src, err := afero.ReadFile(afs, file)
+	if err != nil {
+		return err
+	}
+
+	module, err := ast.ParseModuleWithOpts(file, string(src), ast.ParserOptions{
+		RegoVersion:       ast.RegoV1,
+		ProcessAnnotation: true,
+	})
+	if err != nil {
+		return err
+	}
+
+	converted, err := format.AstWithOpts(module, format.Opts{RegoVersion: ast.RegoV0CompatV1})
+	if err != nil {
+		return fmt.Errorf("unable to convert %s to rego v0 syntax: %w", file, err)
+	}
+
+	log.Debugf("Converted rego v1 module %s", file)
+
+	return afero.WriteFile(afs, file, converted, 0644)
+}
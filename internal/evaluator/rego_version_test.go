@@ -0,0 +1,240 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package evaluator
+
+import (
+	"context"
+	"os"
+	"path"
+	"sort"
+	"testing"
+	"testing/fstest"
+
+	"github.com/MakeNowJust/heredoc"
+	ecc "github.com/enterprise-contract/enterprise-contract-controller/api/v1alpha1"
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/policy"
+	"github.com/enterprise-contract/ec-cli/internal/policy/source"
+)
+
+var regoV0Module = heredoc.Doc(`
+	package v0
+
+	import future.keywords.contains
+	import future.keywords.if
+
+	# METADATA
+	# title: v0 rule
+	# custom:
+	#   short_name: v0_rule
+	deny contains result if {
+		result := {"code": "v0.v0_rule", "msg": "from v0"}
+	}
+`)
+
+var regoV1Module = heredoc.Doc(`
+	package v1
+
+	# METADATA
+	# title: v1 rule
+	# custom:
+	#   short_name: v1_rule
+	deny contains result if {
+		result := {"code": "v1.v1_rule", "msg": "from v1"}
+	}
+`)
+
+func TestBundleManifestModuleVersion(t *testing.T) {
+	one := 1
+	zero := 0
+
+	cases := []struct {
+		name     string
+		manifest bundleManifest
+		file     string
+		expected ast.RegoVersion
+	}{
+		{name: "empty manifest", file: "a.rego", expected: ast.RegoV0},
+		{name: "bundle v1", manifest: bundleManifest{RegoVersion: &one}, file: "a.rego", expected: ast.RegoV1},
+		{name: "bundle v0", manifest: bundleManifest{RegoVersion: &zero}, file: "a.rego", expected: ast.RegoV0},
+		{
+			name:     "file override",
+			manifest: bundleManifest{RegoVersion: &zero, FileRegoVersions: map[string]int{"/lib/*.rego": 1}},
+			file:     "lib/a.rego",
+			expected: ast.RegoV1,
+		},
+		{
+			name:     "file override not matching",
+			manifest: bundleManifest{RegoVersion: &one, FileRegoVersions: map[string]int{"lib/*.rego": 0}},
+			file:     "main/a.rego",
+			expected: ast.RegoV1,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, c.expected, c.manifest.moduleVersion(c.file))
+		})
+	}
+}
+
+func TestValidateRegoVersion(t *testing.T) {
+	for _, v := range RegoVersions {
+		assert.NoError(t, ValidateRegoVersion(v))
+	}
+
+	assert.EqualError(t, ValidateRegoVersion("v2"), `unsupported rego version "v2", expecting one of: v0, v1, auto`)
+}
+
+func TestPrepareRegoVersion(t *testing.T) {
+	cases := []struct {
+		name      string
+		version   string
+		manifest  string
+		converted bool
+		err       string
+	}{
+		{name: "default", version: "", converted: false},
+		{name: "v0", version: RegoV0, converted: false},
+		{name: "v1", version: RegoV1, converted: true},
+		{name: "auto without manifest", version: RegoAuto, converted: false},
+		{name: "auto with v1 manifest", version: RegoAuto, manifest: `{"rego_version": 1}`, converted: true},
+		{name: "auto with v0 manifest", version: RegoAuto, manifest: `{"rego_version": 0}`, converted: false},
+		{name: "auto with invalid manifest", version: RegoAuto, manifest: `{`, err: "unable to parse bundle manifest in /policy: unexpected end of JSON input"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			require.NoError(t, afero.WriteFile(fs, "/policy/v1.rego", []byte(regoV1Module), 0644))
+			if c.manifest != "" {
+				require.NoError(t, afero.WriteFile(fs, "/policy/.manifest", []byte(c.manifest), 0644))
+			}
+
+			ctx := WithRegoVersion(context.Background(), c.version)
+			err := prepareRegoVersion(ctx, fs, "/policy")
+			if c.err != "" {
+				assert.EqualError(t, err, c.err)
+				return
+			}
+			require.NoError(t, err)
+
+			src, err := afero.ReadFile(fs, "/policy/v1.rego")
+			require.NoError(t, err)
+
+			_, err = ast.ParseModuleWithOpts("v1.rego", string(src), ast.ParserOptions{RegoVersion: ast.RegoV0})
+			if c.converted {
+				assert.NoError(t, err)
+				assert.Contains(t, string(src), "import rego.v1")
+			} else {
+				assert.Equal(t, regoV1Module, string(src))
+			}
+
+			// converting again must be a no-op
+			require.NoError(t, prepareRegoVersion(ctx, fs, "/policy"))
+			again, err := afero.ReadFile(fs, "/policy/v1.rego")
+			require.NoError(t, err)
+			assert.Equal(t, string(src), string(again))
+		})
+	}
+}
+
+func TestPrepareRegoVersionLinkedSource(t *testing.T) {
+	dir := t.TempDir()
+	source := path.Join(dir, "source")
+	require.NoError(t, os.MkdirAll(path.Join(source, "lib"), 0755))
+	require.NoError(t, os.WriteFile(path.Join(source, "lib", "v1.rego"), []byte(regoV1Module), 0600))
+	require.NoError(t, os.WriteFile(path.Join(source, "data.json"), []byte("{}"), 0600))
+
+	// local sources are linked into the work directory
+	policy := path.Join(dir, "work", "policy", "abc")
+	require.NoError(t, os.MkdirAll(path.Dir(policy), 0755))
+	require.NoError(t, os.Symlink(source, policy))
+
+	ctx := WithRegoVersion(context.Background(), RegoV1)
+	require.NoError(t, prepareRegoVersion(ctx, afero.NewOsFs(), policy))
+
+	// the source is left as is
+	src, err := os.ReadFile(path.Join(source, "lib", "v1.rego"))
+	require.NoError(t, err)
+	assert.Equal(t, regoV1Module, string(src))
+
+	// the converted modules are written in the work directory
+	info, err := os.Lstat(policy)
+	require.NoError(t, err)
+	assert.True(t, info.IsDir())
+
+	converted, err := os.ReadFile(path.Join(policy, "lib", "v1.rego"))
+	require.NoError(t, err)
+	assert.Contains(t, string(converted), "import rego.v1")
+
+	data, err := os.ReadFile(path.Join(policy, "data.json"))
+	require.NoError(t, err)
+	assert.Equal(t, "{}", string(data))
+
+	entries, err := os.ReadDir(path.Dir(policy))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func TestConftestEvaluatorMixedRegoVersions(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(path.Join(dir, "inputs"), 0755))
+	require.NoError(t, os.WriteFile(path.Join(dir, "inputs", "data.json"), []byte("{}"), 0600))
+
+	v0Rules, err := rulesArchive(t, fstest.MapFS{
+		"v0.rego": &fstest.MapFile{Data: []byte(regoV0Module)},
+	})
+	require.NoError(t, err)
+
+	v1Rules, err := rulesArchive(t, fstest.MapFS{
+		".manifest": &fstest.MapFile{Data: []byte(`{"rego_version": 1}`)},
+		"v1.rego":   &fstest.MapFile{Data: []byte(regoV1Module)},
+	})
+	require.NoError(t, err)
+
+	ctx := withCapabilities(context.Background(), testCapabilities)
+	ctx = WithRegoVersion(ctx, RegoAuto)
+
+	p, err := policy.NewInertPolicy(ctx, "")
+	require.NoError(t, err)
+
+	evaluator, err := NewConftestEvaluator(ctx, []source.PolicySource{
+		&source.PolicyUrl{Url: v0Rules, Kind: source.PolicyKind},
+		&source.PolicyUrl{Url: v1Rules, Kind: source.PolicyKind},
+	}, p, ecc.Source{})
+	require.NoError(t, err)
+
+	results, _, err := evaluator.Evaluate(ctx, EvaluationTarget{Inputs: []string{path.Join(dir, "inputs")}})
+	require.NoError(t, err)
+
+	messages := []string{}
+	for _, r := range results {
+		for _, f := range r.Failures {
+			messages = append(messages, f.Message)
+		}
+	}
+	sort.Strings(messages)
+
+	assert.Equal(t, []string{"from v0", "from v1"}, messages)
+}
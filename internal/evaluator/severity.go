@@ -0,0 +1,97 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package evaluator
+
+import (
+	"fmt"
+	"strings"
+)
+
+// The severities of the rules, from the most to the least severe. The
+// severity is set with the severity custom annotation of the rule, or with
+// the severity key of the metadata of the result.
+const (
+	SeverityCritical = "critical"
+	SeverityHigh     = "high"
+	SeverityMedium   = "medium"
+	SeverityLow      = "low"
+)
+
+// Severities holds the severities of the rules, from the most to the least
+// severe
+var Severities = []string{SeverityCritical, SeverityHigh, SeverityMedium, SeverityLow}
+
+// ParseSeverity returns the severity matching the given value, case
+// insensitively
+func ParseSeverity(value string) (string, error) {
+	severity := strings.ToLower(strings.TrimSpace(value))
+	if severityRank(severity) < 0 {
+		return "", fmt.Errorf("invalid severity %q, expecting one of: %s", value, strings.Join(Severities, ", "))
+	}
+
+	return severity, nil
+}
+
+// Severity returns the severity of the result, or an empty string if the
+// result has no known severity
+func (r Result) Severity() string {
+	severity := strings.ToLower(ExtractStringFromMetadata(r, metadataSeverity))
+	if severityRank(severity) < 0 {
+		return ""
+	}
+
+	return severity
+}
+
+// MeetsSeverity returns true if the result is at least as severe as the
+// given severity. Results without a known severity are considered to meet
+// any severity, so that they are never tolerated, as are all results when no
+// severity is given.
+func (r Result) MeetsSeverity(severity string) bool {
+	threshold := severityRank(severity)
+	if threshold < 0 {
+		return true
+	}
+
+	rank := severityRank(r.Severity())
+	return rank < 0 || rank <= threshold
+}
+
+// FailingResults returns the results at least as severe as the given
+// severity, see MeetsSeverity
+func FailingResults(results []Result, severity string) []Result {
+	failing := make([]Result, 0, len(results))
+	for _, r := range results {
+		if r.MeetsSeverity(severity) {
+			failing = append(failing, r)
+		}
+	}
+
+	return failing
+}
+
+// severityRank returns the position of the severity in Severities, or -1 if
+// the severity is not known
+func severityRank(severity string) int {
+	for i, s := range Severities {
+		if s == severity {
+			return i
+		}
+	}
+
+	return -1
+}
@@ -0,0 +1,64 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package evaluator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSeverity(t *testing.T) {
+	s, err := ParseSeverity("High")
+	assert.NoError(t, err)
+	assert.Equal(t, SeverityHigh, s)
+
+	_, err = ParseSeverity("urgent")
+	assert.EqualError(t, err, `invalid severity "urgent", expecting one of: critical, high, medium, low`)
+}
+
+func TestFailingResults(t *testing.T) {
+	critical := Result{Message: "critical", Metadata: map[string]any{"severity": "critical"}}
+	medium := Result{Message: "medium", Metadata: map[string]any{"severity": "Medium"}}
+	low := Result{Message: "low", Metadata: map[string]any{"severity": "low"}}
+	unknown := Result{Message: "unknown", Metadata: map[string]any{"severity": "whatever"}}
+	none := Result{Message: "none"}
+
+	results := []Result{critical, medium, low, unknown, none}
+
+	cases := []struct {
+		severity string
+		expected []Result
+	}{
+		{severity: "", expected: results},
+		{severity: SeverityLow, expected: results},
+		{severity: SeverityMedium, expected: []Result{critical, medium, unknown, none}},
+		{severity: SeverityHigh, expected: []Result{critical, unknown, none}},
+		{severity: SeverityCritical, expected: []Result{critical, unknown, none}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.severity, func(t *testing.T) {
+			assert.Equal(t, c.expected, FailingResults(results, c.severity))
+		})
+	}
+
+	assert.Equal(t, "medium", medium.Severity())
+	assert.Equal(t, "", unknown.Severity())
+}
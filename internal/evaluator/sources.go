@@ -0,0 +1,132 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package evaluator
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/enterprise-contract/ec-cli/internal/downloader"
+	"github.com/enterprise-contract/ec-cli/internal/policy/source"
+)
+
+const requireAllSourcesKey contextKey = "ec.evaluator.require_all_sources"
+
+// WithRequireAllSources makes the evaluation fail if any of the policy or
+// data sources fails to download, by default evaluation proceeds with the
+// sources that were downloaded as long as at least one of the policy sources
+// was downloaded.
+func WithRequireAllSources(ctx context.Context, require bool) context.Context {
+	return context.WithValue(ctx, requireAllSourcesKey, require)
+}
+
+func requireAllSources(ctx context.Context) bool {
+	require, _ := ctx.Value(requireAllSourcesKey).(bool)
+	return require
+}
+
+// sourceDownloadConcurrency is the number of sources downloaded at once
+const sourceDownloadConcurrency = 4
+
+// fetchedSource holds the directory a source was downloaded into, or the
+// error encountered when downloading it
+type fetchedSource struct {
+	dir string
+	err error
+}
+
+// fetchSources downloads the sources concurrently, at most
+// sourceDownloadConcurrency at once, and returns the outcomes in the order of
+// the sources. OCI downloads are still serialized by the downloader as they're
+// not thread safe.
+func fetchSources(ctx context.Context, sources []source.PolicySource, workDir string) []fetchedSource {
+	fetched := make([]fetchedSource, len(sources))
+
+	work := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < min(sourceDownloadConcurrency, len(sources)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range work {
+				fetched[i].dir, fetched[i].err = sources[i].GetPolicy(ctx, workDir, false)
+			}
+		}()
+	}
+
+	for i := range sources {
+		work <- i
+	}
+	close(work)
+	wg.Wait()
+
+	return fetched
+}
+
+// failedSource holds the error encountered when downloading a source
+type failedSource struct {
+	source source.PolicySource
+	err    error
+}
+
+// checkFailedSources returns an error if the evaluation can't proceed given
+// the sources that failed to download. With all sources required the error
+// names each of the failed sources. Otherwise an error is returned only if
+// none of the policy sources was downloaded, and the failed sources are
+// reported as warnings.
+func checkFailedSources(ctx context.Context, sources []source.PolicySource, failed []failedSource) error {
+	if len(failed) == 0 {
+		return nil
+	}
+
+	if requireAllSources(ctx) {
+		msgs := make([]string, 0, len(failed))
+		for _, f := range failed {
+			msgs = append(msgs, fmt.Sprintf("%s: %s", downloader.Redact(f.source.PolicyUrl()), f.err))
+		}
+		return fmt.Errorf("unable to download %d of %d policy and data sources: %s", len(failed), len(sources), strings.Join(msgs, "; "))
+	}
+
+	policySources := 0
+	failedPolicySources := 0
+	for _, s := range sources {
+		if s.Subdir() == string(source.PolicyKind) {
+			policySources++
+		}
+	}
+	for _, f := range failed {
+		if f.source.Subdir() == string(source.PolicyKind) {
+			failedPolicySources++
+		}
+	}
+
+	// without any policy there is nothing to evaluate, so this is an error
+	// even when tolerating failed sources
+	if policySources > 0 && policySources == failedPolicySources {
+		return failed[0].err
+	}
+
+	for _, f := range failed {
+		log.Warnf("Unable to download the source %s, proceeding without it: %s", downloader.Redact(f.source.PolicyUrl()), f.err)
+	}
+
+	return nil
+}
@@ -0,0 +1,170 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package evaluator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	ecc "github.com/enterprise-contract/enterprise-contract-controller/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/policy"
+	"github.com/enterprise-contract/ec-cli/internal/policy/source"
+)
+
+type failingPolicySource struct {
+	url    string
+	subdir string
+}
+
+func (f failingPolicySource) GetPolicy(ctx context.Context, dest string, showMsg bool) (string, error) {
+	return "", errors.New("connection refused")
+}
+
+func (f failingPolicySource) PolicyUrl() string {
+	return f.url
+}
+
+func (f failingPolicySource) Subdir() string {
+	return f.subdir
+}
+
+func TestConftestEvaluatorFailingSources(t *testing.T) {
+	failingData := failingPolicySource{url: "https://example.com/data.git", subdir: "data"}
+	failingPolicy := failingPolicySource{url: "https://example.com/policy.git", subdir: "policy"}
+
+	cases := []struct {
+		name       string
+		sources    []source.PolicySource
+		requireAll bool
+		err        string
+	}{
+		{
+			name:    "tolerant",
+			sources: []source.PolicySource{testPolicySource{}, failingData},
+		},
+		{
+			name:       "require all sources",
+			sources:    []source.PolicySource{testPolicySource{}, failingData},
+			requireAll: true,
+			err:        "unable to download 1 of 2 policy and data sources: https://example.com/data.git: connection refused",
+		},
+		{
+			name:       "require all sources with many failing",
+			sources:    []source.PolicySource{testPolicySource{}, failingData, failingPolicy},
+			requireAll: true,
+			err:        "unable to download 2 of 3 policy and data sources: https://example.com/data.git: connection refused; https://example.com/policy.git: connection refused",
+		},
+		{
+			name:    "tolerant without any policy",
+			sources: []source.PolicySource{failingPolicy, failingData},
+			err:     "connection refused",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			r := mockTestRunner{}
+			ctx := setupTestContext(&r, &mockDownloader{})
+			ctx = WithRequireAllSources(ctx, c.requireAll)
+
+			inputs := EvaluationTarget{Inputs: []string{"inputs"}}
+			r.On("Run", mock.Anything, inputs.Inputs).Return([]Outcome{{Failures: []Result{{Message: "Fails always", Metadata: map[string]any{}}}}}, Data(nil), nil)
+
+			p, err := policy.NewOfflinePolicy(ctx, policy.Now)
+			require.NoError(t, err)
+
+			evaluator, err := NewConftestEvaluator(ctx, c.sources, p, ecc.Source{})
+			require.NoError(t, err)
+
+			_, _, err = evaluator.Evaluate(ctx, inputs)
+			if c.err == "" {
+				assert.NoError(t, err)
+				r.AssertCalled(t, "Run", mock.Anything, inputs.Inputs)
+			} else {
+				assert.EqualError(t, err, c.err)
+				r.AssertNotCalled(t, "Run", mock.Anything, inputs.Inputs)
+			}
+		})
+	}
+}
+
+type blockingPolicySource struct {
+	url     string
+	started chan<- string
+	release <-chan struct{}
+}
+
+func (b blockingPolicySource) GetPolicy(ctx context.Context, dest string, showMsg bool) (string, error) {
+	b.started <- b.url
+	<-b.release
+	return dest + "/" + b.url, nil
+}
+
+func (b blockingPolicySource) PolicyUrl() string {
+	return b.url
+}
+
+func (b blockingPolicySource) Subdir() string {
+	return "policy"
+}
+
+func TestFetchSources(t *testing.T) {
+	started := make(chan string)
+	release := make(chan struct{})
+
+	sources := make([]source.PolicySource, 0, sourceDownloadConcurrency+2)
+	for i := 0; i < sourceDownloadConcurrency+2; i++ {
+		sources = append(sources, blockingPolicySource{url: fmt.Sprintf("source%d", i), started: started, release: release})
+	}
+
+	done := make(chan []fetchedSource)
+	go func() {
+		done <- fetchSources(context.Background(), sources, "work")
+	}()
+
+	// the sources are downloaded at once up to the limit
+	for i := 0; i < sourceDownloadConcurrency; i++ {
+		<-started
+	}
+	select {
+	case url := <-started:
+		t.Fatalf("%s started downloading above the limit of %d concurrent downloads", url, sourceDownloadConcurrency)
+	default:
+	}
+
+	// the remaining sources are downloaded as the downloads complete
+	for i := 0; i < 2; i++ {
+		release <- struct{}{}
+		<-started
+	}
+	close(release)
+
+	fetched := <-done
+	require.Len(t, fetched, len(sources))
+	for i, f := range fetched {
+		assert.NoError(t, f.err)
+		assert.Equal(t, fmt.Sprintf("work/source%d", i), f.dir)
+	}
+}
@@ -0,0 +1,341 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package evaluator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/open-policy-agent/conftest/output"
+	conftest "github.com/open-policy-agent/conftest/policy"
+	"github.com/open-policy-agent/opa/rego"
+)
+
+const (
+	// EngineConftest evaluates the policies using the Conftest engine, the
+	// Rego interpreter of OPA
+	EngineConftest = "conftest"
+	// EngineWasm evaluates the policies compiled to OPA WASM modules
+	EngineWasm = "wasm"
+)
+
+var Engines = []string{EngineConftest, EngineWasm}
+
+const engineKey contextKey = "ec.evaluator.engine"
+
+// wasmAvailable is true if the OPA WASM runtime is built in, it requires cgo
+var wasmAvailable = false
+
+// WithEngine sets the engine, one of Engines, used to evaluate the policies
+func WithEngine(ctx context.Context, engine string) context.Context {
+	return context.WithValue(ctx, engineKey, engine)
+}
+
+// ValidateEngine returns an error if the given engine is not supported
+func ValidateEngine(engine string) error {
+	if slices.Contains(Engines, engine) {
+		return nil
+	}
+
+	return fmt.Errorf("unsupported evaluator %q, expecting one of: %s", engine, strings.Join(Engines, ", "))
+}
+
+func evaluationEngine(ctx context.Context) string {
+	if e, ok := ctx.Value(engineKey).(string); ok && e != "" {
+		return e
+	}
+
+	return EngineConftest
+}
+
+var (
+	warningRegex = regexp.MustCompile("^warn(_[a-zA-Z0-9]+)*$")
+	failureRegex = regexp.MustCompile("^(deny|violation)(_[a-zA-Z0-9]+)*$")
+)
+
+// wasmRunner evaluates the policies loaded by the Conftest engine, with the
+// same queries and producing the same results as the Conftest engine does,
+// but with the queries compiled to WASM modules and executed by the WASM
+// runtime. The policies are compiled once for the lifetime of the evaluator,
+// the packages of the namespaces are compiled, as a single query, to a WASM
+// module for each evaluated document, as the WASM runtime of OPA doesn't
+// support evaluating a compiled query more than once without a data race in
+// its built-in function dispatcher. Unlike with
+// the Conftest engine, the name of the evaluated file is not available to the
+// policies in data.conftest.file.
+type wasmRunner struct {
+	conftestRunner
+}
+
+func (r wasmRunner) Run(ctx context.Context, fileList []string) ([]Outcome, Data, error) {
+	if !wasmAvailable {
+		return nil, nil, errors.New("the wasm evaluator is not available in this build of ec, it requires a build with cgo enabled")
+	}
+
+	engine, data, release, err := r.load(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer release()
+
+	configurations, err := parseInputs(fileList)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	namespaces := r.namespaces(engine)
+
+	// the namespaces are evaluated against each document at once, the results
+	// of the rules are read from the evaluated namespaces
+	evaluated := make(map[string][]map[string]map[string]any, len(configurations))
+	for path, config := range configurations {
+		// multi-document files are evaluated one document at a time with
+		// the results aggregated under the same file name
+		configs, ok := config.([]any)
+		if !ok {
+			configs = []any{config}
+		}
+
+		for _, c := range configs {
+			docs, err := r.evaluate(ctx, engine, namespaces, c)
+			if err != nil {
+				return nil, nil, fmt.Errorf("query rule: evaluate: %w", err)
+			}
+			evaluated[path] = append(evaluated[path], docs)
+		}
+	}
+
+	var results []output.CheckResult
+	for _, namespace := range namespaces {
+		for path := range configurations {
+			result := output.CheckResult{
+				FileName:  path,
+				Namespace: namespace,
+			}
+			for _, docs := range evaluated[path] {
+				r, err := r.check(engine, docs[namespace], namespace)
+				if err != nil {
+					return nil, nil, fmt.Errorf("query rule: check: %w", err)
+				}
+
+				result.Successes += r.Successes
+				result.Failures = append(result.Failures, r.Failures...)
+				result.Warnings = append(result.Warnings, r.Warnings...)
+				result.Exceptions = append(result.Exceptions, r.Exceptions...)
+				result.Queries = append(result.Queries, r.Queries...)
+			}
+
+			results = append(results, result)
+		}
+	}
+
+	return toOutcomes(results), data, nil
+}
+
+// evaluate evaluates the packages of the namespaces against the input with a
+// single query compiled to WASM, and returns the documents of the evaluated
+// packages by namespace
+func (r wasmRunner) evaluate(ctx context.Context, engine *conftest.Engine, namespaces []string, input any) (map[string]map[string]any, error) {
+	// namespaces without policies would leave the whole query undefined
+	var bindings []string
+	for i, namespace := range namespaces {
+		if slices.Contains(engine.Namespaces(), namespace) {
+			bindings = append(bindings, fmt.Sprintf("ns%d := data.%s", i, namespace))
+		}
+	}
+
+	docs := make(map[string]map[string]any, len(namespaces))
+	if len(bindings) == 0 {
+		return docs, nil
+	}
+
+	query := strings.Join(bindings, "; ")
+	pq, err := r.prepareQuery(ctx, engine, query)
+	if err != nil {
+		return nil, err
+	}
+
+	resultSet, err := pq.Eval(ctx, rego.EvalInput(input))
+	if err != nil {
+		return nil, fmt.Errorf("evaluating policy: %w", err)
+	}
+
+	if len(resultSet) == 0 {
+		return docs, nil
+	}
+
+	for i, namespace := range namespaces {
+		if doc, ok := resultSet[0].Bindings[fmt.Sprintf("ns%d", i)].(map[string]any); ok {
+			docs[namespace] = doc
+		}
+	}
+
+	return docs, nil
+}
+
+// check reads the results of the warn, deny and violation rules of the
+// namespace, and their exceptions, from the evaluated package of the
+// namespace, counting the successes as Conftest does: a rule without any
+// result is a success
+func (r wasmRunner) check(engine *conftest.Engine, doc map[string]any, namespace string) (output.CheckResult, error) {
+	var rules []string
+	var ruleCount int
+	for _, module := range engine.Modules() {
+		if strings.TrimPrefix(module.Package.Path.String(), "data.") != namespace {
+			continue
+		}
+
+		for _, rule := range module.Rules {
+			name := rule.Head.Name.String()
+			if !failureRegex.MatchString(name) && !warningRegex.MatchString(name) {
+				continue
+			}
+
+			ruleCount++
+			if !slices.Contains(rules, name) {
+				rules = append(rules, name)
+			}
+		}
+	}
+
+	result := output.CheckResult{Namespace: namespace}
+	var successes int
+	for _, rule := range rules {
+		exceptionQuery := fmt.Sprintf("data.%s.exception[_][_] == %q", namespace, removeRulePrefix(rule))
+		exceptionResult := output.QueryResult{Query: exceptionQuery}
+		var exceptions []output.Result
+		for _, e := range exceptionsOf(doc, removeRulePrefix(rule)) {
+			exceptionResult.Results = append(exceptionResult.Results, e)
+			e.Message = exceptionQuery
+			exceptions = append(exceptions, e)
+		}
+
+		ruleQuery := fmt.Sprintf("data.%s.%s", namespace, rule)
+		ruleResult := output.QueryResult{Query: ruleQuery}
+		if value, ok := doc[rule]; ok {
+			var err error
+			if ruleResult.Results, err = toResults(value); err != nil {
+				return output.CheckResult{}, fmt.Errorf("query rule: %w", err)
+			}
+		}
+
+		for _, res := range ruleResult.Results {
+			// exceptions are accounted for by the exception query
+			if len(exceptions) > 0 {
+				continue
+			}
+
+			switch {
+			case res.Passed():
+				successes++
+			case failureRegex.MatchString(rule):
+				result.Failures = append(result.Failures, res)
+			default:
+				result.Warnings = append(result.Warnings, res)
+			}
+		}
+
+		result.Exceptions = append(result.Exceptions, exceptions...)
+		result.Queries = append(result.Queries, exceptionResult, ruleResult)
+	}
+
+	// a single success is counted for a rule defined more than once
+	if count := len(result.Failures) + len(result.Warnings) + len(result.Exceptions) + successes; count < ruleCount {
+		successes += ruleCount - count
+	}
+	result.Successes = successes
+
+	return result, nil
+}
+
+// exceptionsOf returns a passing result for each exception of the rule in the
+// exception rule of the evaluated package, as the Conftest exception query
+// data.<namespace>.exception[_][_] == "<rule>" does
+func exceptionsOf(doc map[string]any, rule string) []output.Result {
+	exceptions, _ := doc["exception"].([]any)
+
+	var results []output.Result
+	for _, exception := range exceptions {
+		names, _ := exception.([]any)
+		for _, name := range names {
+			if name == rule {
+				results = append(results, output.Result{})
+			}
+		}
+	}
+
+	return results
+}
+
+// toResults converts the value of a rule to results: strings are messages,
+// objects are messages, in the msg attribute, with metadata, and a rule
+// without any values is a single passing result
+func toResults(value any) ([]output.Result, error) {
+	values, _ := value.([]any)
+	if len(values) == 0 {
+		return []output.Result{{}}, nil
+	}
+
+	var results []output.Result
+	for _, v := range values {
+		switch val := v.(type) {
+		case string:
+			results = append(results, output.Result{Message: val})
+		case map[string]any:
+			result, err := output.NewResult(val)
+			if err != nil {
+				return nil, fmt.Errorf("new result: %w", err)
+			}
+			results = append(results, result)
+		}
+	}
+
+	return results, nil
+}
+
+// prepareQuery returns the query compiled to WASM, to be evaluated only once
+func (r wasmRunner) prepareQuery(ctx context.Context, engine *conftest.Engine, query string) (rego.PreparedEvalQuery, error) {
+	pq, err := rego.New(
+		rego.Target(EngineWasm),
+		rego.Query(query),
+		rego.Compiler(engine.Compiler()),
+		rego.Store(engine.Store()),
+		rego.Runtime(engine.Runtime()),
+	).PrepareForEval(ctx)
+	if err != nil {
+		return rego.PreparedEvalQuery{}, fmt.Errorf("compiling the query %s to wasm: %w", query, err)
+	}
+
+	return pq, nil
+}
+
+// removeRulePrefix returns the name of the rule as matched by exceptions, that
+// is without the deny_, violation_ or warn_ prefix
+func removeRulePrefix(rule string) string {
+	if rule == "violation" || rule == "deny" || rule == "warn" {
+		return ""
+	}
+	rule = strings.TrimPrefix(rule, "violation_")
+	rule = strings.TrimPrefix(rule, "deny_")
+	rule = strings.TrimPrefix(rule, "warn_")
+
+	return rule
+}
@@ -0,0 +1,203 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build integration
+
+package evaluator
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/MakeNowJust/heredoc"
+	ecc "github.com/enterprise-contract/enterprise-contract-controller/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/policy"
+	"github.com/enterprise-contract/ec-cli/internal/policy/source"
+)
+
+// The WASM modules are compiled for each evaluated document, which takes too
+// long for the unit tests, hence these are run as integration tests
+
+var capabilities string
+
+func init() {
+	data, err := strictCapabilities(context.Background())
+	if err != nil {
+		panic(err)
+	}
+	capabilities = data
+}
+
+// policyDir writes the files to a directory used as the policy source
+func policyDir(t *testing.T, files fs.FS) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	rego, err := fs.ReadDir(files, ".")
+	require.NoError(t, err)
+	for _, r := range rego {
+		data, err := fs.ReadFile(files, r.Name())
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(path.Join(dir, r.Name()), data, 0600))
+	}
+
+	return dir
+}
+
+func TestWasmEvaluator(t *testing.T) {
+	if !wasmAvailable {
+		t.Skip("the wasm runtime requires cgo")
+	}
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(path.Join(dir, "input.json"), []byte(`{"name": "b", "version": 1}`), 0600))
+	require.NoError(t, os.WriteFile(path.Join(dir, "multi.yaml"), []byte("name: a\n---\nname: c\n"), 0600))
+
+	rules := policyDir(t, fstest.MapFS{
+		"main.rego": &fstest.MapFile{Data: []byte(heredoc.Doc(`
+			package main
+
+			import future.keywords.contains
+			import future.keywords.if
+			import future.keywords.in
+
+			# METADATA
+			# title: Allowed
+			# custom:
+			#   short_name: allowed
+			deny contains result if {
+				not input.name in {"a", "c"}
+				result := {"code": "main.allowed", "msg": sprintf("%s is not allowed", [input.name])}
+			}
+
+			# METADATA
+			# title: Versioned
+			# custom:
+			#   short_name: versioned
+			deny contains result if {
+				not input.version
+				result := {"code": "main.versioned", "msg": "no version"}
+			}
+
+			# METADATA
+			# title: Deprecated
+			# custom:
+			#   short_name: deprecated
+			warn contains result if {
+				input.version < 2
+				result := {"code": "main.deprecated", "msg": sprintf("version %d is deprecated", [input.version])}
+			}
+		`))},
+		"excepted.rego": &fstest.MapFile{Data: []byte(heredoc.Doc(`
+			package excepted
+
+			import future.keywords.contains
+			import future.keywords.if
+
+			deny_always contains "always" if {
+				true
+			}
+
+			exception contains rules if {
+				rules := ["always"]
+			}
+		`))},
+	})
+
+	evaluate := func(engine string) []Outcome {
+		ctx := WithEngine(withCapabilities(context.Background(), capabilities), engine)
+
+		p, err := policy.NewInertPolicy(ctx, "")
+		require.NoError(t, err)
+
+		evaluator, err := NewConftestEvaluator(ctx, []source.PolicySource{
+			&source.PolicyUrl{Url: rules, Kind: source.PolicyKind},
+		}, p, ecc.Source{})
+		require.NoError(t, err)
+		t.Cleanup(evaluator.Destroy)
+
+		results, _, err := evaluator.Evaluate(ctx, EvaluationTarget{Inputs: []string{dir}})
+		require.NoError(t, err)
+
+		// the order of the successes is not deterministic
+		for _, r := range results {
+			slices.SortFunc(r.Successes, func(a, b Result) int {
+				return strings.Compare(ExtractStringFromMetadata(a, metadataCode), ExtractStringFromMetadata(b, metadataCode))
+			})
+		}
+
+		return results
+	}
+
+	conftestResults := evaluate(EngineConftest)
+	wasmResults := evaluate(EngineWasm)
+
+	assert.ElementsMatch(t, conftestResults, wasmResults)
+	assert.Len(t, wasmResults, 4)
+}
+
+func TestWasmEvaluatorRepeated(t *testing.T) {
+	if !wasmAvailable {
+		t.Skip("the wasm runtime requires cgo")
+	}
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(path.Join(dir, "input.json"), []byte(`{}`), 0600))
+
+	rules := policyDir(t, fstest.MapFS{
+		"main.rego": &fstest.MapFile{Data: []byte(heredoc.Doc(`
+			package main
+
+			import future.keywords.contains
+			import future.keywords.if
+
+			# METADATA
+			# title: Failing
+			# custom:
+			#   short_name: failing
+			deny contains result if {
+				result := {"code": "main.failing", "msg": "Failure!"}
+			}
+		`))},
+	})
+
+	ctx := WithEngine(withCapabilities(context.Background(), capabilities), EngineWasm)
+
+	p, err := policy.NewInertPolicy(ctx, "")
+	require.NoError(t, err)
+
+	evaluator, err := NewConftestEvaluator(ctx, []source.PolicySource{
+		&source.PolicyUrl{Url: rules, Kind: source.PolicyKind},
+	}, p, ecc.Source{})
+	require.NoError(t, err)
+	t.Cleanup(evaluator.Destroy)
+
+	for i := 0; i < 2; i++ {
+		results, _, err := evaluator.Evaluate(ctx, EvaluationTarget{Inputs: []string{dir}})
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+		assert.Len(t, results[0].Failures, 1)
+	}
+}
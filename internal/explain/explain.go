@@ -0,0 +1,386 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package explain produces a focused explanation of the failures of a single
+// component from a previously generated validation report.
+package explain
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	ecc "github.com/enterprise-contract/enterprise-contract-controller/api/v1alpha1"
+
+	"github.com/enterprise-contract/ec-cli/internal/evaluator"
+	"github.com/enterprise-contract/ec-cli/internal/report"
+)
+
+// maxInputMatches limits the number of input values shown per failure
+const maxInputMatches = 10
+
+// Explanation describes all the failures of a component.
+type Explanation struct {
+	Component      string    `json:"component"`
+	ContainerImage string    `json:"containerImage"`
+	Failures       []Failure `json:"failures"`
+}
+
+// Failure describes a single failed rule.
+type Failure struct {
+	Code        string       `json:"code,omitempty"`
+	Title       string       `json:"title,omitempty"`
+	Message     string       `json:"msg"`
+	Description string       `json:"description,omitempty"`
+	Solution    string       `json:"solution,omitempty"`
+	Term        string       `json:"term,omitempty"`
+	Source      Source       `json:"source"`
+	Input       []InputMatch `json:"input,omitempty"`
+	Exclusions  []Exclusion  `json:"exclusions,omitempty"`
+	// Exclude holds the value to add to the exclude list of the policy
+	// configuration to exclude the failure
+	Exclude string `json:"exclude,omitempty"`
+}
+
+// Source describes where the failed rule is defined, the package of the rule
+// and the policy sources providing it.
+type Source struct {
+	Package string   `json:"package,omitempty"`
+	Policy  []string `json:"policy,omitempty"`
+}
+
+// InputMatch is a value within the policy input that relates to the failure,
+// the value is identified by a JSON pointer.
+type InputMatch struct {
+	Path  string `json:"path"`
+	Value any    `json:"value"`
+}
+
+// Exclusion is an exclusion from the policy configuration matching the failed
+// rule that did not apply, e.g. because it is not yet effective or it excludes
+// a different term.
+type Exclusion struct {
+	Value          string `json:"value"`
+	Source         string `json:"source,omitempty"`
+	EffectiveOn    string `json:"effectiveOn,omitempty"`
+	EffectiveUntil string `json:"effectiveUntil,omitempty"`
+	ImageRef       string `json:"imageRef,omitempty"`
+}
+
+// ReadInputs parses the policy input, as written by the policy-input output
+// format, i.e. one JSON document per component. The inputs are returned keyed
+// by the image reference of the component.
+func ReadInputs(data []byte) (map[string]any, error) {
+	inputs := map[string]any{}
+
+	s := bufio.NewScanner(bytes.NewReader(data))
+	s.Buffer(make([]byte, 0, 64*1024), len(data)+1)
+	for s.Scan() {
+		line := bytes.TrimSpace(s.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		var in struct {
+			Image struct {
+				Ref string `json:"ref"`
+			} `json:"image"`
+		}
+		if err := json.Unmarshal(line, &in); err != nil {
+			return nil, fmt.Errorf("unable to parse the policy input: %w", err)
+		}
+
+		var doc any
+		if err := json.Unmarshal(line, &doc); err != nil {
+			return nil, fmt.Errorf("unable to parse the policy input: %w", err)
+		}
+
+		inputs[in.Image.Ref] = doc
+	}
+
+	if err := s.Err(); err != nil {
+		return nil, fmt.Errorf("unable to read the policy input: %w", err)
+	}
+
+	return inputs, nil
+}
+
+// Explain explains the violations of the component with the given name, or
+// image reference, found in the report. The inputs, if provided, are used to
+// find the values of the policy input related to each of the violations.
+func Explain(r *report.Report, component string, inputs map[string]any) (*Explanation, error) {
+	var c *report.Component
+	for i := range r.Components {
+		if r.Components[i].Name == component || r.Components[i].ContainerImage == component {
+			c = &r.Components[i]
+			break
+		}
+	}
+
+	if c == nil {
+		names := make([]string, 0, len(r.Components))
+		for _, c := range r.Components {
+			names = append(names, c.Name)
+		}
+		return nil, fmt.Errorf("component %q not found in the report, the report contains: %s", component, strings.Join(names, ", "))
+	}
+
+	e := Explanation{
+		Component:      c.Name,
+		ContainerImage: c.ContainerImage,
+		Failures:       make([]Failure, 0, len(c.Violations)),
+	}
+
+	input := inputs[c.ContainerImage]
+	for _, v := range c.Violations {
+		f := Failure{
+			Code:        evaluator.ExtractStringFromMetadata(v, "code"),
+			Title:       evaluator.ExtractStringFromMetadata(v, "title"),
+			Message:     v.Message,
+			Description: evaluator.ExtractStringFromMetadata(v, "description"),
+			Solution:    evaluator.ExtractStringFromMetadata(v, "solution"),
+			Term:        evaluator.ExtractStringFromMetadata(v, "term"),
+		}
+
+		f.Source = source(r.Policy, f.Code)
+
+		if input != nil && f.Term != "" {
+			f.Input = findInInput(input, f.Term)
+		}
+
+		f.Exclusions = exclusions(r.Policy, f.Code)
+
+		// built-in checks can't be excluded
+		if f.Code != "" && !strings.HasPrefix(f.Code, "builtin.") {
+			f.Exclude = f.Code
+			if f.Term != "" {
+				f.Exclude += ":" + f.Term
+			}
+		}
+
+		e.Failures = append(e.Failures, f)
+	}
+
+	return &e, nil
+}
+
+// packageOf returns the package of the rule with the given code, i.e. the code
+// without the rule name
+func packageOf(code string) string {
+	if i := strings.LastIndex(code, "."); i > 0 {
+		return code[:i]
+	}
+
+	return ""
+}
+
+func source(p ecc.EnterpriseContractPolicySpec, code string) Source {
+	s := Source{Package: packageOf(code)}
+	if strings.HasPrefix(code, "builtin.") {
+		// built-in checks are not defined in policy sources
+		return s
+	}
+
+	for _, src := range p.Sources {
+		s.Policy = append(s.Policy, src.Policy...)
+	}
+
+	return s
+}
+
+// matches returns true if the exclusion value matches the rule with the given
+// code, the value can be the package name, optionally followed by ".*", or the
+// code of the rule, and either can be followed by ":" and a term. Exclusions
+// of any term match, the term of an exclusion not matching the term of the
+// failure explains why the failure was not excluded.
+func matches(value, code string) bool {
+	value, _, _ = strings.Cut(value, ":")
+	pkg := packageOf(code)
+
+	return value == code || value == pkg || value == pkg+".*"
+}
+
+func exclusions(p ecc.EnterpriseContractPolicySpec, code string) []Exclusion {
+	if code == "" {
+		return nil
+	}
+
+	var found []Exclusion
+
+	if p.Configuration != nil {
+		for _, e := range p.Configuration.Exclude {
+			if matches(e, code) {
+				found = append(found, Exclusion{Value: e})
+			}
+		}
+	}
+
+	for _, src := range p.Sources {
+		if src.Config != nil {
+			for _, e := range src.Config.Exclude {
+				if matches(e, code) {
+					found = append(found, Exclusion{Value: e, Source: src.Name})
+				}
+			}
+		}
+
+		if src.VolatileConfig != nil {
+			for _, e := range src.VolatileConfig.Exclude {
+				if matches(e.Value, code) {
+					found = append(found, Exclusion{
+						Value:          e.Value,
+						Source:         src.Name,
+						EffectiveOn:    e.EffectiveOn,
+						EffectiveUntil: e.EffectiveUntil,
+						ImageRef:       e.ImageRef,
+					})
+				}
+			}
+		}
+	}
+
+	return found
+}
+
+// findInInput returns the values within the input that are equal to, or
+// contain, the term
+func findInInput(input any, term string) []InputMatch {
+	var found []InputMatch
+
+	var walk func(path string, value any)
+	walk = func(path string, value any) {
+		if len(found) >= maxInputMatches {
+			return
+		}
+
+		switch v := value.(type) {
+		case map[string]any:
+			keys := make([]string, 0, len(v))
+			for k := range v {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				walk(path+"/"+escape(k), v[k])
+			}
+		case []any:
+			for i, e := range v {
+				walk(path+"/"+strconv.Itoa(i), e)
+			}
+		case string:
+			if strings.Contains(v, term) {
+				found = append(found, InputMatch{Path: path, Value: v})
+			}
+		case nil:
+			// nothing to match
+		default:
+			if fmt.Sprint(v) == term {
+				found = append(found, InputMatch{Path: path, Value: v})
+			}
+		}
+	}
+
+	walk("", input)
+
+	return found
+}
+
+// escape escapes the reference token of a JSON pointer, see RFC 6901
+func escape(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+}
+
+// WriteText writes the explanation in human readable form.
+func (e *Explanation) WriteText(w io.Writer) error {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "Component: %s\n", e.Component)
+	fmt.Fprintf(&b, "ImageRef: %s\n", e.ContainerImage)
+
+	if len(e.Failures) == 0 {
+		b.WriteString("\nNo failures found\n")
+	}
+
+	for _, f := range e.Failures {
+		b.WriteString("\n")
+		if f.Code != "" {
+			fmt.Fprintf(&b, "✕ %s\n", f.Code)
+		} else {
+			b.WriteString("✕ (no code)\n")
+		}
+		field := func(name, value string) {
+			if value != "" {
+				fmt.Fprintf(&b, "  %s: %s\n", name, value)
+			}
+		}
+		field("Title", f.Title)
+		field("Message", f.Message)
+		field("Description", f.Description)
+		field("Solution", f.Solution)
+		field("Term", f.Term)
+		field("Package", f.Source.Package)
+		if len(f.Source.Policy) > 0 {
+			fmt.Fprintf(&b, "  Policy sources: %s\n", strings.Join(f.Source.Policy, ", "))
+		}
+
+		if len(f.Input) > 0 {
+			b.WriteString("  Input:\n")
+			for _, m := range f.Input {
+				v, err := json.Marshal(m.Value)
+				if err != nil {
+					return err
+				}
+				fmt.Fprintf(&b, "    %s: %s\n", m.Path, v)
+			}
+		}
+
+		if len(f.Exclusions) > 0 {
+			b.WriteString("  Matching exclusions not applied:\n")
+			for _, x := range f.Exclusions {
+				fmt.Fprintf(&b, "    %s", x.Value)
+				var details []string
+				if x.Source != "" {
+					details = append(details, "source "+x.Source)
+				}
+				if x.EffectiveOn != "" {
+					details = append(details, "effective on "+x.EffectiveOn)
+				}
+				if x.EffectiveUntil != "" {
+					details = append(details, "effective until "+x.EffectiveUntil)
+				}
+				if x.ImageRef != "" {
+					details = append(details, "for image "+x.ImageRef)
+				}
+				if len(details) > 0 {
+					fmt.Fprintf(&b, " (%s)", strings.Join(details, ", "))
+				}
+				b.WriteString("\n")
+			}
+		}
+
+		if f.Exclude != "" {
+			fmt.Fprintf(&b, "  To exclude add %q to the exclude list of the policy configuration\n", f.Exclude)
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
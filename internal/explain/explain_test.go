@@ -0,0 +1,165 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package explain
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/report"
+)
+
+const validationReport = `{
+  "success": false,
+  "components": [
+    {
+      "name": "good",
+      "containerImage": "registry.io/good@sha256:4e388ab32b10dc8dbc7e28144f552830adc74787c1e2c0824032078a79f227fb",
+      "success": true
+    },
+    {
+      "name": "bad",
+      "containerImage": "registry.io/bad@sha256:5e388ab32b10dc8dbc7e28144f552830adc74787c1e2c0824032078a79f227fb",
+      "success": false,
+      "violations": [
+        {
+          "msg": "Attestation type \"spam\" is not known",
+          "metadata": {
+            "code": "attestation_type.known_attestation_type",
+            "title": "Known attestation type found",
+            "term": "spam"
+          }
+        },
+        {
+          "msg": "Image signature check failed: no signatures found",
+          "metadata": {
+            "code": "builtin.image.signature_check"
+          }
+        }
+      ]
+    }
+  ],
+  "policy": {
+    "sources": [
+      {
+        "name": "release",
+        "policy": ["github.com/org/policy//policy/release"],
+        "config": {
+          "exclude": ["attestation_type.known_attestation_type:ham", "cve"]
+        },
+        "volatileConfig": {
+          "exclude": [
+            {"value": "attestation_type", "effectiveOn": "2099-01-01T00:00:00Z"}
+          ]
+        }
+      }
+    ]
+  }
+}`
+
+const inputs = `{"image": {"ref": "registry.io/good@sha256:4e388ab32b10dc8dbc7e28144f552830adc74787c1e2c0824032078a79f227fb"}, "attestations": []}
+{"image": {"ref": "registry.io/bad@sha256:5e388ab32b10dc8dbc7e28144f552830adc74787c1e2c0824032078a79f227fb"}, "attestations": [{"statement": {"_type": "spam", "predicateType": "https://slsa.dev/provenance/v0.2"}}]}
+`
+
+func TestExplain(t *testing.T) {
+	r, err := report.ReadReport([]byte(validationReport))
+	require.NoError(t, err)
+
+	in, err := ReadInputs([]byte(inputs))
+	require.NoError(t, err)
+	assert.Len(t, in, 2)
+
+	e, err := Explain(r, "bad", in)
+	require.NoError(t, err)
+
+	require.Len(t, e.Failures, 2)
+	assert.Equal(t, Failure{
+		Code:    "attestation_type.known_attestation_type",
+		Title:   "Known attestation type found",
+		Message: `Attestation type "spam" is not known`,
+		Term:    "spam",
+		Source: Source{
+			Package: "attestation_type",
+			Policy:  []string{"github.com/org/policy//policy/release"},
+		},
+		Input: []InputMatch{
+			{Path: "/attestations/0/statement/_type", Value: "spam"},
+		},
+		Exclusions: []Exclusion{
+			{Value: "attestation_type.known_attestation_type:ham", Source: "release"},
+			{Value: "attestation_type", Source: "release", EffectiveOn: "2099-01-01T00:00:00Z"},
+		},
+		Exclude: "attestation_type.known_attestation_type:spam",
+	}, e.Failures[0])
+
+	assert.Equal(t, Failure{
+		Code:    "builtin.image.signature_check",
+		Message: "Image signature check failed: no signatures found",
+		Source:  Source{Package: "builtin.image"},
+	}, e.Failures[1])
+
+	var out bytes.Buffer
+	require.NoError(t, e.WriteText(&out))
+	assert.Equal(t, `Component: bad
+ImageRef: registry.io/bad@sha256:5e388ab32b10dc8dbc7e28144f552830adc74787c1e2c0824032078a79f227fb
+
+✕ attestation_type.known_attestation_type
+  Title: Known attestation type found
+  Message: Attestation type "spam" is not known
+  Term: spam
+  Package: attestation_type
+  Policy sources: github.com/org/policy//policy/release
+  Input:
+    /attestations/0/statement/_type: "spam"
+  Matching exclusions not applied:
+    attestation_type.known_attestation_type:ham (source release)
+    attestation_type (source release, effective on 2099-01-01T00:00:00Z)
+  To exclude add "attestation_type.known_attestation_type:spam" to the exclude list of the policy configuration
+
+✕ builtin.image.signature_check
+  Message: Image signature check failed: no signatures found
+  Package: builtin.image
+`, out.String())
+}
+
+func TestExplainByImageReference(t *testing.T) {
+	r, err := report.ReadReport([]byte(validationReport))
+	require.NoError(t, err)
+
+	e, err := Explain(r, "registry.io/good@sha256:4e388ab32b10dc8dbc7e28144f552830adc74787c1e2c0824032078a79f227fb", nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, "good", e.Component)
+	assert.Empty(t, e.Failures)
+
+	var out bytes.Buffer
+	require.NoError(t, e.WriteText(&out))
+	assert.Contains(t, out.String(), "No failures found")
+}
+
+func TestExplainUnknownComponent(t *testing.T) {
+	r, err := report.ReadReport([]byte(validationReport))
+	require.NoError(t, err)
+
+	_, err = Explain(r, "ugly", nil)
+	assert.EqualError(t, err, `component "ugly" not found in the report, the report contains: good, bad`)
+}
@@ -21,14 +21,55 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
 
 	"github.com/enterprise-contract/ec-cli/internal/utils/oci"
 )
 
-// FetchImageConfig retrieves the config for an image from its OCI registry.
-func FetchImageConfig(ctx context.Context, ref name.Reference) (json.RawMessage, error) {
+// ImageMetadata holds the labels, the platform and the creation time from the
+// config of an image, and the annotations and the layers from its manifest.
+type ImageMetadata struct {
+	Labels       map[string]string `json:"labels,omitempty"`
+	Annotations  map[string]string `json:"annotations,omitempty"`
+	OS           string            `json:"os,omitempty"`
+	Architecture string            `json:"architecture,omitempty"`
+	Variant      string            `json:"variant,omitempty"`
+	Created      string            `json:"created,omitempty"`
+	Layers       []Layer           `json:"layers,omitempty"`
+}
+
+// Layer describes a layer of an image, in the order found in the manifest.
+// The DiffID is the digest of the uncompressed layer from the config of the
+// image.
+type Layer struct {
+	Digest      string            `json:"digest"`
+	DiffID      string            `json:"diff_id,omitempty"`
+	Size        int64             `json:"size"`
+	MediaType   string            `json:"media_type,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+type fetched struct {
+	config   *v1.ConfigFile
+	manifest *v1.Manifest
+}
+
+// cache holds the fetched config and manifest of images referenced by digest,
+// as the content of those can't change
+var cache sync.Map
+
+func fetch(ctx context.Context, ref name.Reference) (*fetched, error) {
+	_, byDigest := ref.(name.Digest)
+	if byDigest {
+		if f, ok := cache.Load(ref.String()); ok {
+			return f.(*fetched), nil
+		}
+	}
+
 	image, err := oci.NewClient(ctx).Image(ref)
 	if err != nil {
 		return nil, err
@@ -37,8 +78,27 @@ func FetchImageConfig(ctx context.Context, ref name.Reference) (json.RawMessage,
 	if err != nil {
 		return nil, err
 	}
+	manifest, err := image.Manifest()
+	if err != nil {
+		return nil, err
+	}
+
+	f := &fetched{config: configFile, manifest: manifest}
+	if byDigest {
+		cache.Store(ref.String(), f)
+	}
+
+	return f, nil
+}
+
+// FetchImageConfig retrieves the config for an image from its OCI registry.
+func FetchImageConfig(ctx context.Context, ref name.Reference) (json.RawMessage, error) {
+	f, err := fetch(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
 
-	config, err := json.Marshal(configFile.Config)
+	config, err := json.Marshal(f.config.Config)
 	if err != nil {
 		return nil, err
 	}
@@ -46,6 +106,42 @@ func FetchImageConfig(ctx context.Context, ref name.Reference) (json.RawMessage,
 	return config, nil
 }
 
+// FetchImageMetadata retrieves the labels, the annotations, the platform, the
+// creation time and the layers of an image from its OCI registry.
+func FetchImageMetadata(ctx context.Context, ref name.Reference) (*ImageMetadata, error) {
+	f, err := fetch(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	metadata := ImageMetadata{
+		Labels:       f.config.Config.Labels,
+		Annotations:  f.manifest.Annotations,
+		OS:           f.config.OS,
+		Architecture: f.config.Architecture,
+		Variant:      f.config.Variant,
+	}
+
+	if !f.config.Created.IsZero() {
+		metadata.Created = f.config.Created.UTC().Format(time.RFC3339)
+	}
+
+	for i, l := range f.manifest.Layers {
+		layer := Layer{
+			Digest:      l.Digest.String(),
+			Size:        l.Size,
+			MediaType:   string(l.MediaType),
+			Annotations: l.Annotations,
+		}
+		if i < len(f.config.RootFS.DiffIDs) {
+			layer.DiffID = f.config.RootFS.DiffIDs[i].String()
+		}
+		metadata.Layers = append(metadata.Layers, layer)
+	}
+
+	return &metadata, nil
+}
+
 // FetchParentImage retrieves the reference to an image's parent image from its OCI registry.
 func FetchParentImage(ctx context.Context, ref name.Reference) (name.Reference, error) {
 	image, err := oci.NewClient(ctx).Image(ref)
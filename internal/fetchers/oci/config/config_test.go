@@ -23,12 +23,15 @@ import (
 	"errors"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/go-containerregistry/pkg/name"
 	v1 "github.com/google/go-containerregistry/pkg/v1"
 	"github.com/google/go-containerregistry/pkg/v1/empty"
 	v1fake "github.com/google/go-containerregistry/pkg/v1/fake"
 	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	"github.com/google/go-containerregistry/pkg/v1/types"
 	"github.com/stretchr/testify/require"
 
 	"github.com/enterprise-contract/ec-cli/internal/utils"
@@ -98,6 +101,68 @@ func TestFetchImageConfig(t *testing.T) {
 	}
 }
 
+func TestFetchImageMetadata(t *testing.T) {
+	ref, err := name.ParseReference(utils.WithDigest("registry.local/labeled-image"))
+	require.NoError(t, err)
+
+	layer := static.NewLayer([]byte("layer"), types.OCILayer)
+	image, err := mutate.AppendLayers(empty.Image, layer)
+	require.NoError(t, err)
+	image, err = mutate.Config(image, v1.Config{
+		Labels: map[string]string{
+			"maintainer": "Spam",
+		},
+	})
+	require.NoError(t, err)
+	image, err = mutate.ConfigFile(image, func() *v1.ConfigFile {
+		cf, err := image.ConfigFile()
+		require.NoError(t, err)
+		cf = cf.DeepCopy()
+		cf.OS = "linux"
+		cf.Architecture = "arm64"
+		cf.Variant = "v8"
+		cf.Created = v1.Time{Time: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+		return cf
+	}())
+	require.NoError(t, err)
+	image = mutate.Annotations(image, map[string]string{
+		"org.opencontainers.image.source": "https://github.com/org/repo",
+	}).(v1.Image)
+
+	layerDigest, err := layer.Digest()
+	require.NoError(t, err)
+	diffID, err := layer.DiffID()
+	require.NoError(t, err)
+
+	client := fake.FakeClient{}
+	client.On("Image", ref).Return(image, nil)
+	ctx := oci.WithClient(context.Background(), &client)
+
+	metadata, err := FetchImageMetadata(ctx, ref)
+	require.NoError(t, err)
+	require.Equal(t, &ImageMetadata{
+		Labels:       map[string]string{"maintainer": "Spam"},
+		Annotations:  map[string]string{"org.opencontainers.image.source": "https://github.com/org/repo"},
+		OS:           "linux",
+		Architecture: "arm64",
+		Variant:      "v8",
+		Created:      "2024-01-01T00:00:00Z",
+		Layers: []Layer{{
+			Digest:    layerDigest.String(),
+			DiffID:    diffID.String(),
+			Size:      5,
+			MediaType: string(types.OCILayer),
+		}},
+	}, metadata)
+
+	config, err := FetchImageConfig(ctx, ref)
+	require.NoError(t, err)
+	require.Equal(t, `{"Labels":{"maintainer":"Spam"}}`, string(config))
+
+	// the image is referenced by digest so it's fetched only once
+	client.AssertNumberOfCalls(t, "Image", 1)
+}
+
 func TestFetchParentImage(t *testing.T) {
 	ref := name.MustParseReference("registry.local/test-image:latest")
 	parentURL := utils.WithDigest("registry.local/base-image")
@@ -17,6 +17,7 @@
 package format
 
 import (
+	"fmt"
 	"io"
 	"net/url"
 	"strconv"
@@ -30,11 +31,19 @@ type Target struct {
 	Format  string
 	Options Options
 	writer  io.Writer
+	fs      afero.Fs
 }
 
 // options that can be configured per Target
 type Options struct {
 	ShowSuccesses bool
+	// GroupResults groups the identical results of multiple components
+	GroupResults bool
+	// Template is the path of the Go template file the report is rendered with
+	Template string
+	// Rego is the path of the Rego file transforming the report before it is
+	// rendered
+	Rego string
 }
 
 // mutate parses the given string as URL query parameters and sets the fields
@@ -53,9 +62,35 @@ func (o *Options) mutate(given string) error {
 		}
 	}
 
+	if v := vals.Get("group-results"); v != "" {
+		if f, err := strconv.ParseBool(v); err == nil {
+			o.GroupResults = f
+		} else {
+			return err
+		}
+	}
+
+	if v := vals.Get("template"); v != "" {
+		o.Template = v
+	}
+
+	if v := vals.Get("rego"); v != "" {
+		o.Rego = v
+	}
+
 	return nil
 }
 
+// ReadFile reads the file, e.g. the template given in the Options, from the
+// file system the Target writes to
+func (t *Target) ReadFile(path string) ([]byte, error) {
+	if t.fs == nil {
+		return nil, fmt.Errorf("unable to read %s, no file system available", path)
+	}
+
+	return afero.ReadFile(t.fs, path)
+}
+
 // Write proxies the write operation to the underlying writer.
 func (t *Target) Write(data []byte) (int, error) {
 	return t.writer.Write(data)
@@ -76,7 +111,7 @@ func NewTargetParser(targetName string, options Options, writer io.Writer, fs af
 
 // Parse creates a new Target given the provided target name.
 func (tm *TargetParser) Parse(given string) (*Target, error) {
-	target := Target{writer: tm.defaultWriter}
+	target := Target{writer: tm.defaultWriter, fs: tm.fs}
 
 	formatAndPath, opts, foundOpts := strings.Cut(given, "?")
 
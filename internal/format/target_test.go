@@ -47,6 +47,8 @@ func TestTargetParser(t *testing.T) {
 		{name: "format and option", expectedFormat: "spam", expectedOptions: Options{ShowSuccesses: true}, targetName: "spam?show-successes=true"},
 		{name: "format no file with option", expectedFormat: "spam", expectedOptions: Options{ShowSuccesses: true}, targetName: "spam=?show-successes=true"},
 		{name: "format with file and option", expectedFormat: "spam", expectedOptions: Options{ShowSuccesses: true}, targetName: "spam=spam.out?show-successes=true", expectedPath: "spam.out"},
+		{name: "group results", expectedFormat: "spam", expectedOptions: Options{GroupResults: true}, targetName: "spam?group-results=true"},
+		{name: "template", expectedFormat: "template", expectedOptions: Options{Template: "slack.tmpl", Rego: "transform.rego"}, targetName: "template=slack.json?template=slack.tmpl&rego=transform.rego", expectedPath: "slack.json"},
 	}
 
 	for _, c := range cases {
@@ -69,6 +71,22 @@ func TestTargetParser(t *testing.T) {
 	}
 }
 
+func TestTargetReadFile(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "report.tmpl", []byte("{{ .success }}"), 0600))
+
+	parser := NewTargetParser("json", Options{}, nil, fs)
+	target, err := parser.Parse("template?template=report.tmpl")
+	require.NoError(t, err)
+
+	data, err := target.ReadFile(target.Options.Template)
+	require.NoError(t, err)
+	assert.Equal(t, "{{ .success }}", string(data))
+
+	_, err = (&Target{}).ReadFile("report.tmpl")
+	assert.EqualError(t, err, "unable to read report.tmpl, no file system available")
+}
+
 func TestSimpleFileWriter(t *testing.T) {
 	fs := afero.NewMemMapFs()
 	writer := fileWriter{path: "out", fs: fs}
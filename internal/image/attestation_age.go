@@ -0,0 +1,100 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package image
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const maxAttestationAgeKey contextKey = "ec.image.max_attestation_age"
+
+// now is used to determine the age of attestations, mocked in tests
+var now = time.Now
+
+// WithMaxAttestationAge enables the built-in check that the build recorded in
+// the provenance finished no longer than the given duration ago.
+func WithMaxAttestationAge(ctx context.Context, maxAge time.Duration) context.Context {
+	return context.WithValue(ctx, maxAttestationAgeKey, maxAge)
+}
+
+func maxAttestationAge(ctx context.Context) time.Duration {
+	if maxAge, ok := ctx.Value(maxAttestationAgeKey).(time.Duration); ok {
+		return maxAge
+	}
+
+	return 0
+}
+
+// ParseMaxAttestationAge parses the given age as a Go duration, e.g. "36h", or
+// as a number of days, e.g. "30d".
+func ParseMaxAttestationAge(age string) (time.Duration, error) {
+	var duration time.Duration
+	if days, ok := strings.CutSuffix(age, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("invalid attestation age %q: %w", age, err)
+		}
+		duration = time.Duration(n) * 24 * time.Hour
+	} else {
+		var err error
+		if duration, err = time.ParseDuration(age); err != nil {
+			return 0, fmt.Errorf("invalid attestation age %q: %w", age, err)
+		}
+	}
+
+	if duration <= 0 {
+		return 0, fmt.Errorf("invalid attestation age %q: must be positive", age)
+	}
+
+	return duration, nil
+}
+
+// checkAttestationAge returns an error if the build finish time recorded in
+// the provenance is missing or is older than the given maximum age.
+func checkAttestationAge(attestationTime *time.Time, maxAge time.Duration) error {
+	if attestationTime == nil {
+		return errors.New("the provenance does not record the time the build finished")
+	}
+
+	age := now().Sub(*attestationTime)
+	if age > maxAge {
+		return fmt.Errorf("the provenance was created %s ago (at %s), exceeding the maximum age of %s", formatAge(age), attestationTime.UTC().Format(time.RFC3339), formatAge(maxAge))
+	}
+
+	return nil
+}
+
+// formatAge formats the duration in days and hours when longer than a day
+func formatAge(d time.Duration) string {
+	day := 24 * time.Hour
+	if d < day {
+		return d.Round(time.Second).String()
+	}
+
+	days := int(d / day)
+	hours := int((d % day) / time.Hour)
+	if hours == 0 {
+		return fmt.Sprintf("%dd", days)
+	}
+
+	return fmt.Sprintf("%dd%dh", days, hours)
+}
@@ -0,0 +1,60 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package image
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseMaxAttestationAge(t *testing.T) {
+	cases := []struct {
+		age      string
+		expected time.Duration
+		err      string
+	}{
+		{age: "30d", expected: 30 * 24 * time.Hour},
+		{age: "36h", expected: 36 * time.Hour},
+		{age: "1h30m", expected: 90 * time.Minute},
+		{age: "0d", err: `invalid attestation age "0d": must be positive`},
+		{age: "-1h", err: `invalid attestation age "-1h": must be positive`},
+		{age: "xd", err: `invalid attestation age "xd": strconv.Atoi: parsing "x": invalid syntax`},
+		{age: "month", err: `invalid attestation age "month": time: invalid duration "month"`},
+	}
+
+	for _, c := range cases {
+		t.Run(c.age, func(t *testing.T) {
+			d, err := ParseMaxAttestationAge(c.age)
+			if c.err != "" {
+				assert.EqualError(t, err, c.err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, c.expected, d)
+			}
+		})
+	}
+}
+
+func TestFormatAge(t *testing.T) {
+	assert.Equal(t, "1h2m3s", formatAge(time.Hour+2*time.Minute+3*time.Second))
+	assert.Equal(t, "2d", formatAge(48*time.Hour+10*time.Minute))
+	assert.Equal(t, "2d5h", formatAge(53*time.Hour))
+}
@@ -0,0 +1,106 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package image
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const requiredAttestationsKey contextKey = "ec.image.required_attestations"
+
+// RequiredAttestations holds the minimum number of distinct signers required
+// per predicate type
+type RequiredAttestations map[string]int
+
+// WithRequiredAttestations enables the built-in check that the image has
+// attestations of the given predicate types signed by at least the given
+// number of distinct signers.
+func WithRequiredAttestations(ctx context.Context, required RequiredAttestations) context.Context {
+	return context.WithValue(ctx, requiredAttestationsKey, required)
+}
+
+func requiredAttestations(ctx context.Context) RequiredAttestations {
+	if required, ok := ctx.Value(requiredAttestationsKey).(RequiredAttestations); ok {
+		return required
+	}
+
+	return nil
+}
+
+// ParseRequiredAttestations parses requirements in the form of
+// predicateType>=N, e.g. "https://slsa.dev/provenance/v0.2>=2".
+func ParseRequiredAttestations(requirements []string) (RequiredAttestations, error) {
+	required := RequiredAttestations{}
+	for _, r := range requirements {
+		predicateType, count, ok := strings.Cut(r, ">=")
+		predicateType = strings.TrimSpace(predicateType)
+		if !ok || predicateType == "" {
+			return nil, fmt.Errorf("invalid attestation requirement %q: expecting predicateType>=N", r)
+		}
+
+		n, err := strconv.Atoi(strings.TrimSpace(count))
+		if err != nil {
+			return nil, fmt.Errorf("invalid attestation requirement %q: %w", r, err)
+		}
+		if n <= 0 {
+			return nil, fmt.Errorf("invalid attestation requirement %q: the number of signers must be positive", r)
+		}
+
+		required[predicateType] = n
+	}
+
+	return required, nil
+}
+
+// checkRequiredAttestations returns a description of the signers counted for
+// each of the required predicate types, and an error if any of the
+// predicate types isn't signed by the required number of distinct signers.
+// The signers are those of the verified attestations of each predicate type,
+// identified by the public keys or the certificates verifying them.
+func checkRequiredAttestations(signers map[string][]string, required RequiredAttestations) (string, error) {
+
+	predicateTypes := make([]string, 0, len(required))
+	for pt := range required {
+		predicateTypes = append(predicateTypes, pt)
+	}
+	sort.Strings(predicateTypes)
+
+	var counted, missing []string
+	for _, pt := range predicateTypes {
+		s := signers[pt]
+		description := fmt.Sprintf("%s signed by %d distinct signer(s)", pt, len(s))
+		if len(s) > 0 {
+			description += ": " + strings.Join(s, ", ")
+		}
+		counted = append(counted, description)
+
+		if n := required[pt]; len(s) < n {
+			missing = append(missing, fmt.Sprintf("%s, at least %d required", description, n))
+		}
+	}
+
+	if len(missing) > 0 {
+		return strings.Join(counted, "; "), errors.New(strings.Join(missing, "; "))
+	}
+
+	return strings.Join(counted, "; "), nil
+}
@@ -0,0 +1,288 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package image
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+
+	gcr "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/in-toto/in-toto-golang/in_toto"
+	"github.com/in-toto/in-toto-golang/in_toto/slsa_provenance/common"
+	v02 "github.com/in-toto/in-toto-golang/in_toto/slsa_provenance/v0.2"
+	app "github.com/konflux-ci/application-api/api/v1alpha1"
+	"github.com/sigstore/cosign/v2/pkg/cosign"
+	"github.com/sigstore/cosign/v2/pkg/oci"
+	"github.com/sigstore/cosign/v2/pkg/oci/static"
+	cosignTypes "github.com/sigstore/cosign/v2/pkg/types"
+	"github.com/sigstore/sigstore/pkg/cryptoutils"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/evaluator"
+	"github.com/enterprise-contract/ec-cli/internal/policy"
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+	ecoci "github.com/enterprise-contract/ec-cli/internal/utils/oci"
+	"github.com/enterprise-contract/ec-cli/internal/utils/oci/fake"
+)
+
+func TestParseRequiredAttestations(t *testing.T) {
+	cases := []struct {
+		name         string
+		requirements []string
+		expected     RequiredAttestations
+		err          string
+	}{
+		{
+			name:         "single",
+			requirements: []string{"https://slsa.dev/provenance/v0.2>=2"},
+			expected:     RequiredAttestations{"https://slsa.dev/provenance/v0.2": 2},
+		},
+		{
+			name:         "multiple",
+			requirements: []string{"https://slsa.dev/provenance/v0.2>=2", " https://spdx.dev/Document >= 1"},
+			expected:     RequiredAttestations{"https://slsa.dev/provenance/v0.2": 2, "https://spdx.dev/Document": 1},
+		},
+		{
+			name:         "missing operator",
+			requirements: []string{"https://slsa.dev/provenance/v0.2"},
+			err:          `invalid attestation requirement "https://slsa.dev/provenance/v0.2": expecting predicateType>=N`,
+		},
+		{
+			name:         "missing predicate type",
+			requirements: []string{">=2"},
+			err:          `invalid attestation requirement ">=2": expecting predicateType>=N`,
+		},
+		{
+			name:         "not a number",
+			requirements: []string{"x>=two"},
+			err:          `invalid attestation requirement "x>=two": strconv.Atoi: parsing "two": invalid syntax`,
+		},
+		{
+			name:         "zero",
+			requirements: []string{"x>=0"},
+			err:          `invalid attestation requirement "x>=0": the number of signers must be positive`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			r, err := ParseRequiredAttestations(c.requirements)
+			if c.err != "" {
+				assert.EqualError(t, err, c.err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, c.expected, r)
+			}
+		})
+	}
+}
+
+// signWith creates an attestation of the given predicate type with DSSE
+// signatures using the given key IDs
+func signWith(predicateType string, keyIDs ...string) oci.Signature {
+	statement, err := json.Marshal(in_toto.Statement{
+		StatementHeader: in_toto.StatementHeader{
+			Type:          in_toto.StatementInTotoV01,
+			PredicateType: predicateType,
+			Subject: []in_toto.Subject{
+				{Name: imageRegistry, Digest: common.DigestSet{"sha256": imageDigest}},
+			},
+		},
+		Predicate: v02.ProvenancePredicate{
+			BuildType: "https://tekton.dev/attestations/chains/pipelinerun@v2",
+			Builder: common.ProvenanceBuilder{
+				ID: "scheme:uri",
+			},
+		},
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	signatures := make([]string, 0, len(keyIDs))
+	for _, id := range keyIDs {
+		signatures = append(signatures, fmt.Sprintf(`{"keyid": %q, "sig": "c2lnbmF0dXJl"}`, id))
+	}
+
+	payload := base64.StdEncoding.EncodeToString(statement)
+	s, err := static.NewSignature(
+		[]byte(`{"payload":"`+payload+`", "signatures": [`+strings.Join(signatures, ",")+`]}`),
+		"",
+		static.WithLayerMediaType(types.MediaType((cosignTypes.DssePayloadType))),
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	return s
+}
+
+// signer is a public key trusted by the policy, identified by the fingerprint
+// of the key
+type signer struct {
+	pem         string
+	fingerprint string
+	verifies    any
+}
+
+func newSigner(t *testing.T) signer {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	pem, err := cryptoutils.MarshalPublicKeyToPEM(key.Public())
+	require.NoError(t, err)
+
+	der, err := x509.MarshalPKIXPublicKey(key.Public())
+	require.NoError(t, err)
+
+	return signer{
+		pem:         string(pem),
+		fingerprint: fmt.Sprintf("sha256:%x", sha256.Sum256(der)),
+		verifies: mock.MatchedBy(func(opts *cosign.CheckOpts) bool {
+			pk, err := opts.SigVerifier.PublicKey()
+			return err == nil && key.PublicKey.Equal(pk)
+		}),
+	}
+}
+
+func TestRequiredAttestationsCheck(t *testing.T) {
+	alice, bob := newSigner(t), newSigner(t)
+	both := []string{alice.fingerprint, bob.fingerprint}
+	sort.Strings(both)
+
+	cases := []struct {
+		name               string
+		alice              []oci.Signature
+		bob                []oci.Signature
+		required           RequiredAttestations
+		expectedViolations []evaluator.Result
+		expectedMessage    string
+	}{
+		{
+			name:               "threshold met by distinct signers",
+			alice:              []oci.Signature{signWith(v02.PredicateSLSAProvenance, "a")},
+			bob:                []oci.Signature{signWith(v02.PredicateSLSAProvenance, "b")},
+			required:           RequiredAttestations{v02.PredicateSLSAProvenance: 2},
+			expectedViolations: []evaluator.Result{},
+			expectedMessage:    "Pass: https://slsa.dev/provenance/v0.2 signed by 2 distinct signer(s): " + strings.Join(both, ", "),
+		},
+		{
+			name:               "threshold met by signers of a single attestation",
+			alice:              []oci.Signature{signWith(v02.PredicateSLSAProvenance, "a", "b")},
+			bob:                []oci.Signature{signWith(v02.PredicateSLSAProvenance, "a", "b")},
+			required:           RequiredAttestations{v02.PredicateSLSAProvenance: 2},
+			expectedViolations: []evaluator.Result{},
+			expectedMessage:    "Pass: https://slsa.dev/provenance/v0.2 signed by 2 distinct signer(s): " + strings.Join(both, ", "),
+		},
+		{
+			name:     "key IDs do not identify signers",
+			alice:    []oci.Signature{signWith(v02.PredicateSLSAProvenance, "a", "b", "c")},
+			required: RequiredAttestations{v02.PredicateSLSAProvenance: 2},
+			expectedViolations: []evaluator.Result{
+				{Message: "Required attestations check failed: https://slsa.dev/provenance/v0.2 signed by 1 distinct signer(s): " + alice.fingerprint + ", at least 2 required", Metadata: map[string]any{
+					"code": "builtin.attestation.required_signers",
+				}},
+			},
+		},
+		{
+			name: "duplicate signers",
+			alice: []oci.Signature{
+				signWith(v02.PredicateSLSAProvenance, "a"),
+				signWith(v02.PredicateSLSAProvenance, "b"),
+			},
+			required: RequiredAttestations{v02.PredicateSLSAProvenance: 2},
+			expectedViolations: []evaluator.Result{
+				{Message: "Required attestations check failed: https://slsa.dev/provenance/v0.2 signed by 1 distinct signer(s): " + alice.fingerprint + ", at least 2 required", Metadata: map[string]any{
+					"code": "builtin.attestation.required_signers",
+				}},
+			},
+		},
+		{
+			name:     "signers of other predicate types are not counted",
+			alice:    []oci.Signature{signWith(v02.PredicateSLSAProvenance, "a")},
+			bob:      []oci.Signature{signWith("https://spdx.dev/Document", "b")},
+			required: RequiredAttestations{v02.PredicateSLSAProvenance: 2, "https://example.com/missing": 1},
+			expectedViolations: []evaluator.Result{
+				{Message: "Required attestations check failed: https://example.com/missing signed by 0 distinct signer(s), at least 1 required; https://slsa.dev/provenance/v0.2 signed by 1 distinct signer(s): " + alice.fingerprint + ", at least 2 required", Metadata: map[string]any{
+					"code": "builtin.attestation.required_signers",
+				}},
+			},
+		},
+	}
+
+	config, err := json.Marshal(map[string]any{
+		"publicKeys": []map[string]any{{"key": alice.pem}, {"key": bob.pem}},
+	})
+	require.NoError(t, err)
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctx := utils.WithFS(context.Background(), afero.NewMemMapFs())
+			p, err := policy.NewPolicy(ctx, policy.Options{
+				PolicyRef:     string(config),
+				IgnoreRekor:   true,
+				EffectiveTime: policy.Now,
+			})
+			require.NoError(t, err)
+
+			component := app.SnapshotComponent{ContainerImage: imageRef}
+			ctx = withImageConfig(ctx, component.ContainerImage)
+			ctx = WithRequiredAttestations(ctx, c.required)
+
+			client := ecoci.NewClient(ctx).(*fake.FakeClient)
+			client.On("Head", ref).Return(&gcr.Descriptor{MediaType: types.OCIManifestSchema1}, nil)
+			client.On("VerifyImageSignatures", refNoTag, mock.Anything).Return([]oci.Signature{validSignature}, true, nil)
+			for _, s := range []struct {
+				signer       signer
+				attestations []oci.Signature
+			}{{alice, c.alice}, {bob, c.bob}} {
+				if len(s.attestations) == 0 {
+					client.On("VerifyImageAttestations", refNoTag, s.signer.verifies).Return(nil, false, errors.New("no matching attestations"))
+				} else {
+					client.On("VerifyImageAttestations", refNoTag, s.signer.verifies).Return(s.attestations, true, nil)
+				}
+			}
+
+			actual, err := ValidateImage(ctx, component, &app.SnapshotSpec{}, p, []evaluator.Evaluator{}, false)
+			require.NoError(t, err)
+
+			assert.Equal(t, c.expectedViolations, actual.Violations())
+			require.NotNil(t, actual.RequiredAttestationsCheck)
+			if c.expectedMessage != "" {
+				assert.True(t, actual.RequiredAttestationsCheck.Passed)
+				assert.Equal(t, c.expectedMessage, actual.RequiredAttestationsCheck.Result.Message)
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package image
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/enterprise-contract/ec-cli/internal/attestation"
+)
+
+type contextKey string
+
+const allowedBaseImageRegistriesKey contextKey = "ec.image.allowed_base_image_registries"
+
+// WithAllowedBaseImageRegistries enables the built-in check that all base
+// images recorded in the provenance materials are hosted on one of the given
+// registries or repository prefixes.
+func WithAllowedBaseImageRegistries(ctx context.Context, registries []string) context.Context {
+	return context.WithValue(ctx, allowedBaseImageRegistriesKey, registries)
+}
+
+func allowedBaseImageRegistries(ctx context.Context) []string {
+	if registries, ok := ctx.Value(allowedBaseImageRegistriesKey).([]string); ok {
+		return registries
+	}
+
+	return nil
+}
+
+// checkBaseImageRegistries returns an error listing all base images not
+// coming from one of the allowed registries.
+func checkBaseImageRegistries(baseImages []attestation.BaseImage, allowed []string) error {
+	var disallowed []string
+	for _, b := range baseImages {
+		if !b.FromAllowedRegistry(allowed) {
+			disallowed = append(disallowed, b.Ref)
+		}
+	}
+
+	if len(disallowed) > 0 {
+		return fmt.Errorf("base images not from an allowed registry (%s): %s", strings.Join(allowed, ", "), strings.Join(disallowed, ", "))
+	}
+
+	return nil
+}
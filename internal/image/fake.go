@@ -20,13 +20,13 @@ import (
 	"encoding/json"
 
 	"github.com/in-toto/in-toto-golang/in_toto"
-	v02 "github.com/in-toto/in-toto-golang/in_toto/slsa_provenance/v0.2"
 
 	"github.com/enterprise-contract/ec-cli/internal/signature"
 )
 
 type fakeAtt struct {
-	statement in_toto.ProvenanceStatementSLSA02
+	// statement is either a SLSA Provenance v0.2 or v1.0 statement
+	statement any
 }
 
 func (f fakeAtt) Statement() []byte {
@@ -42,7 +42,11 @@ func (f fakeAtt) Type() string {
 }
 
 func (f fakeAtt) PredicateType() string {
-	return v02.PredicateSLSAProvenance
+	var header in_toto.StatementHeader
+	if err := json.Unmarshal(f.Statement(), &header); err != nil {
+		panic(err)
+	}
+	return header.PredicateType
 }
 
 func (f fakeAtt) Signatures() []signature.EntitySignature {
@@ -21,11 +21,12 @@ import (
 	"fmt"
 	"strings"
 
-	"github.com/google/go-containerregistry/pkg/authn"
 	"github.com/google/go-containerregistry/pkg/name"
 	v1 "github.com/google/go-containerregistry/pkg/v1"
 	"github.com/google/go-containerregistry/pkg/v1/remote"
 	"github.com/hashicorp/go-multierror"
+
+	"github.com/enterprise-contract/ec-cli/internal/utils/oci"
 )
 
 type key string
@@ -85,7 +86,7 @@ func (i ImageReference) resolveDigest(ctx context.Context, opts ...name.Option)
 	if rh, ok := ctx.Value(RemoteHead).(func(name.Reference, ...remote.Option) (*v1.Descriptor, error)); ok {
 		remoteHead = rh
 	}
-	descriptor, err := remoteHead(i.ref, remote.WithAuthFromKeychain(authn.DefaultKeychain))
+	descriptor, err := remoteHead(oci.MirrorsFrom(ctx).Reference(i.ref), remote.WithAuthFromKeychain(oci.Keychain(ctx)))
 	if err != nil {
 		return nil, err
 	}
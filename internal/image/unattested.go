@@ -0,0 +1,33 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package image
+
+import "context"
+
+const evaluateUnattestedKey contextKey = "ec.image.evaluate_unattested"
+
+// WithEvaluateUnattested enables evaluating the policy against images without
+// attestations, e.g. for policies with rules inspecting only the labels and
+// the annotations of the image. The missing attestations are still reported.
+func WithEvaluateUnattested(ctx context.Context) context.Context {
+	return context.WithValue(ctx, evaluateUnattestedKey, true)
+}
+
+func evaluateUnattested(ctx context.Context) bool {
+	enabled, _ := ctx.Value(evaluateUnattestedKey).(bool)
+	return enabled
+}
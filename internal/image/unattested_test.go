@@ -0,0 +1,112 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package image
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"testing/fstest"
+
+	"github.com/MakeNowJust/heredoc"
+	ecc "github.com/enterprise-contract/enterprise-contract-controller/api/v1alpha1"
+	gcr "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	app "github.com/konflux-ci/application-api/api/v1alpha1"
+	"github.com/sigstore/cosign/v2/pkg/oci"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/downloader"
+	"github.com/enterprise-contract/ec-cli/internal/evaluator"
+	"github.com/enterprise-contract/ec-cli/internal/policy"
+	"github.com/enterprise-contract/ec-cli/internal/policy/source"
+	ecoci "github.com/enterprise-contract/ec-cli/internal/utils/oci"
+	"github.com/enterprise-contract/ec-cli/internal/utils/oci/fake"
+)
+
+func TestLabelRulesWithoutAttestations(t *testing.T) {
+	ctx := downloader.WithEmbeddedFS(context.Background(), fstest.MapFS{
+		"policy/labels.rego": &fstest.MapFile{Data: []byte(heredoc.Doc(`
+			package labels
+
+			import rego.v1
+
+			# METADATA
+			# title: Display name
+			# custom:
+			#   short_name: display_name
+			deny contains result if {
+				input.image.labels["io.k8s.display-name"] != "Production Image"
+				result := {
+					"code": "labels.display_name",
+					"msg": sprintf("Unexpected display name %q", [input.image.labels["io.k8s.display-name"]]),
+				}
+			}
+
+			# METADATA
+			# title: Base image
+			# custom:
+			#   short_name: base_image
+			deny contains result if {
+				not input.image.annotations["org.opencontainers.image.base.name"]
+				result := {"code": "labels.base_image", "msg": "Missing base image annotation"}
+			}
+		`))},
+	})
+
+	p, err := policy.NewOfflinePolicy(ctx, policy.Now)
+	require.NoError(t, err)
+
+	e, err := evaluator.NewConftestEvaluator(ctx, []source.PolicySource{
+		&source.PolicyUrl{Url: "embed://policy", Kind: source.PolicyKind},
+	}, p, ecc.Source{})
+	require.NoError(t, err)
+	t.Cleanup(e.Destroy)
+
+	component := app.SnapshotComponent{ContainerImage: imageRef}
+	ctx = withImageConfig(ctx, component.ContainerImage)
+
+	client := ecoci.NewClient(ctx).(*fake.FakeClient)
+	client.On("Head", ref).Return(&gcr.Descriptor{MediaType: types.OCIManifestSchema1}, nil)
+	client.On("VerifyImageSignatures", refNoTag, mock.Anything).Return([]oci.Signature{validSignature}, true, nil)
+	client.On("VerifyImageAttestations", refNoTag, mock.Anything).Return(nil, false, errors.New("no matching attestations"))
+	client.On("ResolveDigest", refNoTag).Return("@sha256:"+imageDigest, nil)
+
+	attestationViolation := evaluator.Result{
+		Message:  "Image attestation check failed: no matching attestations",
+		Metadata: map[string]any{"code": "builtin.attestation.signature_check"},
+	}
+
+	// by default the policy isn't evaluated without attestations
+	actual, err := ValidateImage(ctx, component, &app.SnapshotSpec{}, p, []evaluator.Evaluator{e}, false)
+	require.NoError(t, err)
+	assert.Equal(t, []evaluator.Result{attestationViolation}, actual.Violations())
+
+	actual, err = ValidateImage(WithEvaluateUnattested(ctx), component, &app.SnapshotSpec{}, p, []evaluator.Evaluator{e}, false)
+	require.NoError(t, err)
+
+	messages := []string{}
+	for _, v := range actual.Violations() {
+		messages = append(messages, v.Message)
+	}
+	assert.ElementsMatch(t, []string{attestationViolation.Message, `Unexpected display name "Test Image"`}, messages)
+	assert.Contains(t, string(actual.PolicyInput), `"labels":{"io.k8s.display-name":"Test Image"}`)
+}
@@ -18,25 +18,34 @@ package image
 
 import (
 	"context"
-	"encoding/json"
 	"sort"
+	"strings"
 	"time"
 
+	"github.com/google/go-containerregistry/pkg/name"
 	app "github.com/konflux-ci/application-api/api/v1alpha1"
-	"github.com/qri-io/jsonpointer"
 	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
 
 	"github.com/enterprise-contract/ec-cli/internal/attestation"
 	"github.com/enterprise-contract/ec-cli/internal/evaluation_target/application_snapshot_image"
 	"github.com/enterprise-contract/ec-cli/internal/evaluator"
+	"github.com/enterprise-contract/ec-cli/internal/logging"
 	"github.com/enterprise-contract/ec-cli/internal/output"
 	"github.com/enterprise-contract/ec-cli/internal/policy"
+	"github.com/enterprise-contract/ec-cli/internal/tracing"
 )
 
 // ValidateImage executes the required method calls to evaluate a given policy
 // against a given image url.
-func ValidateImage(ctx context.Context, comp app.SnapshotComponent, snap *app.SnapshotSpec, p policy.Policy, evaluators []evaluator.Evaluator, detailed bool) (*output.Output, error) {
-	log.Debugf("Validating image %s", comp.ContainerImage)
+func ValidateImage(ctx context.Context, comp app.SnapshotComponent, snap *app.SnapshotSpec, p policy.Policy, evaluators []evaluator.Evaluator, detailed bool) (_ *output.Output, err error) {
+	ctx, span := tracing.Start(ctx, "validate.image", attribute.String("image", comp.ContainerImage))
+	defer func() { tracing.End(span, err) }()
+
+	log.WithFields(log.Fields{
+		logging.ComponentField: comp.Name,
+		logging.ImageField:     comp.ContainerImage,
+	}).Debugf("Validating image %s", comp.ContainerImage)
 
 	out := &output.Output{ImageURL: comp.ContainerImage, Detailed: detailed, Policy: p}
 	a, err := application_snapshot_image.NewApplicationSnapshotImage(ctx, comp, p, *snap)
@@ -54,11 +63,13 @@ func ValidateImage(ctx context.Context, comp app.SnapshotComponent, snap *app.Sn
 		return nil, err
 	} else {
 		out.ImageURL = resolved
+		out.ResolvedFrom = tagReference(comp.ContainerImage)
 	}
 
 	if err := a.FetchImageConfig(ctx); err != nil {
 		log.Debugf("Unable to fetch image config: %s", err)
 	}
+	out.Platform = a.Platform()
 	if err := a.FetchParentImageConfig(ctx); err != nil {
 		log.Debugf("Unable to fetch parent's image config: %s", err)
 	}
@@ -69,7 +80,7 @@ func ValidateImage(ctx context.Context, comp app.SnapshotComponent, snap *app.Sn
 	out.SetImageSignatureCheckFromError(a.ValidateImageSignature(ctx))
 
 	out.SetAttestationSignatureCheckFromError(a.ValidateAttestationSignature(ctx))
-	if !out.AttestationSignatureCheck.Passed {
+	if !out.AttestationSignatureCheck.Passed && !evaluateUnattested(ctx) {
 		return out, nil
 	}
 
@@ -77,9 +88,24 @@ func ValidateImage(ctx context.Context, comp app.SnapshotComponent, snap *app.Sn
 
 	out.Attestations = a.Attestations()
 
-	out.SetAttestationSyntaxCheckFromError(a.ValidateAttestationSyntax(ctx))
+	if out.AttestationSignatureCheck.Passed {
+		out.SetAttestationSyntaxCheckFromError(a.ValidateAttestationSyntax(ctx))
+	}
+
+	if allowed := allowedBaseImageRegistries(ctx); len(allowed) > 0 {
+		out.SetBaseImageRegistryCheckFromError(checkBaseImageRegistries(a.BaseImages(), allowed))
+	}
+
+	attestationTime := determineAttestationTime(ctx, a.Attestations())
+	if maxAge := maxAttestationAge(ctx); maxAge > 0 {
+		out.SetAttestationAgeCheckFromError(checkAttestationAge(attestationTime, maxAge))
+	}
+
+	if required := requiredAttestations(ctx); len(required) > 0 {
+		out.SetRequiredAttestationsCheckFromError(checkRequiredAttestations(a.AttestationSigners(), required))
+	}
 
-	if attestationTime := determineAttestationTime(ctx, a.Attestations()); attestationTime != nil {
+	if attestationTime != nil {
 		p.AttestationTime(*attestationTime)
 	}
 
@@ -87,26 +113,34 @@ func ValidateImage(ctx context.Context, comp app.SnapshotComponent, snap *app.Sn
 	attCount := len(att)
 	out.Attestations = att
 	log.Debugf("Found %d attestations", attCount)
-	if attCount == 0 {
+
+	var allResults []evaluator.Outcome
+	if attCount == 0 && out.AttestationSignatureCheck.Passed {
 		// This is very much a corner case.
-		out.SetPolicyCheck([]evaluator.Outcome{
-			{
-				Failures: []evaluator.Result{{
-					Message: "No attestations contain a subject that match the given image.",
-				}},
-			},
+		allResults = append(allResults, evaluator.Outcome{
+			Failures: []evaluator.Result{{
+				Message: "No attestations contain a subject that match the given image.",
+			}},
 		})
+	}
+	if attCount == 0 && !evaluateUnattested(ctx) {
+		out.SetPolicyCheck(allResults)
 		return out, nil
 	}
 
+	if err := a.FetchImageSBOMs(ctx); err != nil {
+		log.Debugf("Unable to fetch the image SBOMs: %s", err)
+	}
+	if err := a.FetchVulnerabilityScans(ctx); err != nil {
+		log.Warnf("Unable to fetch the vulnerability scans of %s: %s", comp.ContainerImage, err)
+	}
+
 	inputPath, inputJSON, err := a.WriteInputFile(ctx)
 	if err != nil {
 		log.Debug("Problem writing input files!")
 		return nil, err
 	}
 
-	var allResults []evaluator.Outcome
-
 	for _, e := range evaluators {
 		// Todo maybe: Handle each one concurrently
 		target := evaluator.EvaluationTarget{Inputs: []string{inputPath}}
@@ -160,40 +194,37 @@ func resolveAndSetImageUrl(ctx context.Context, url string, asi *application_sna
 	return resolved, nil
 }
 
+// tagReference returns the repository and the tag of the image reference when
+// it has an explicit tag, e.g. registry.io/repository/image:tag for both
+// registry.io/repository/image:tag and registry.io/repository/image:tag@sha256:...
+func tagReference(url string) string {
+	repository := strings.Split(url, "@")[0]
+	if _, err := name.NewTag(repository, name.StrictValidation); err != nil {
+		return ""
+	}
+
+	return repository
+}
+
+// determineAttestationTime returns the latest time a build finished as recorded
+// in the SLSA Provenance attestations, of any supported predicate version
 func determineAttestationTime(ctx context.Context, attestations []attestation.Attestation) *time.Time {
 	if len(attestations) == 0 {
 		log.Debug("No attestations provided to determine attestation time")
 		return nil
 	}
 
-	pointer, err := jsonpointer.Parse("/predicate/metadata/buildFinishedOn")
-	if err != nil {
-		log.Debugf("Failed to parse the fixed JSON Pointer: %v", err)
-		panic(err)
-	}
-
 	times := make([]time.Time, 0, len(attestations))
-	for i, attestation := range attestations {
-		data := attestation.Statement()
-		obj := map[string]any{}
-		if err := json.Unmarshal(data, &obj); err != nil {
-			continue
-		}
-		maybeFinishTime, err := pointer.Eval(obj)
-		if err != nil {
-			log.Debugf("Failed to evaluate JSON Pointer %s for attestation at %d", pointer, i)
-			continue
-		}
-
-		finishTime, ok := maybeFinishTime.(string)
-		if !ok {
-			log.Debugf("Unexpected buildFinishedOn value for attestation at %d: %v", i, maybeFinishTime)
+	for i, att := range attestations {
+		provenance := attestation.NormalizedProvenance(att)
+		if provenance == nil || provenance.FinishedOn == "" {
+			log.Debugf("No build finish time in attestation at %d", i)
 			continue
 		}
 
-		time, err := time.Parse(time.RFC3339, finishTime)
+		time, err := time.Parse(time.RFC3339, provenance.FinishedOn)
 		if err != nil {
-			log.Debugf("Unable to parse buildFinishedOn `%s` as RFC3339 time of attestation at %d", finishTime, i)
+			log.Debugf("Unable to parse build finish time `%s` as RFC3339 time of attestation at %d", provenance.FinishedOn, i)
 			continue
 		}
 
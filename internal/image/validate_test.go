@@ -35,6 +35,7 @@ import (
 	"github.com/in-toto/in-toto-golang/in_toto"
 	"github.com/in-toto/in-toto-golang/in_toto/slsa_provenance/common"
 	v02 "github.com/in-toto/in-toto-golang/in_toto/slsa_provenance/v0.2"
+	v1slsa "github.com/in-toto/in-toto-golang/in_toto/slsa_provenance/v1"
 	app "github.com/konflux-ci/application-api/api/v1alpha1"
 	"github.com/sigstore/cosign/v2/pkg/oci"
 	"github.com/sigstore/cosign/v2/pkg/oci/static"
@@ -170,6 +171,244 @@ func TestBuiltinChecks(t *testing.T) {
 	}
 }
 
+func TestBaseImageRegistryCheck(t *testing.T) {
+	attestationWithBaseImage := func(baseImage string) oci.Signature {
+		return sign(&in_toto.Statement{
+			StatementHeader: in_toto.StatementHeader{
+				Type:          in_toto.StatementInTotoV01,
+				PredicateType: v02.PredicateSLSAProvenance,
+				Subject: []in_toto.Subject{
+					{Name: imageRegistry, Digest: common.DigestSet{"sha256": imageDigest}},
+				},
+			},
+			Predicate: v02.ProvenancePredicate{
+				BuildType: "https://tekton.dev/attestations/chains/pipelinerun@v2",
+				Builder: common.ProvenanceBuilder{
+					ID: "scheme:uri",
+				},
+				Materials: []common.ProvenanceMaterial{
+					{URI: "git+https://github.com/org/repo.git", Digest: common.DigestSet{"sha1": "abc"}},
+					{URI: "oci://" + baseImage, Digest: common.DigestSet{"sha256": imageDigest}},
+				},
+			},
+		})
+	}
+
+	cases := []struct {
+		name               string
+		baseImage          string
+		allowed            []string
+		expectedViolations []evaluator.Result
+	}{
+		{
+			name:               "check disabled",
+			baseImage:          "evil.io/base",
+			expectedViolations: []evaluator.Result{},
+		},
+		{
+			name:               "approved base image",
+			baseImage:          "registry.io/base",
+			allowed:            []string{"registry.io"},
+			expectedViolations: []evaluator.Result{},
+		},
+		{
+			name:      "disallowed base image",
+			baseImage: "evil.io/base",
+			allowed:   []string{"registry.io"},
+			expectedViolations: []evaluator.Result{
+				{Message: "Base image registry check failed: base images not from an allowed registry (registry.io): evil.io/base", Metadata: map[string]interface{}{
+					"code": "builtin.image.base_image_registry",
+				}},
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctx := utils.WithFS(context.Background(), afero.NewMemMapFs())
+			p, err := policy.NewOfflinePolicy(ctx, policy.Now)
+			require.NoError(t, err)
+
+			component := app.SnapshotComponent{ContainerImage: imageRef}
+			ctx = withImageConfig(ctx, component.ContainerImage)
+			if len(c.allowed) > 0 {
+				ctx = WithAllowedBaseImageRegistries(ctx, c.allowed)
+			}
+
+			client := ecoci.NewClient(ctx).(*fake.FakeClient)
+			client.On("Head", ref).Return(&gcr.Descriptor{MediaType: types.OCIManifestSchema1}, nil)
+			client.On("VerifyImageSignatures", refNoTag, mock.Anything).Return([]oci.Signature{validSignature}, true, nil)
+			client.On("VerifyImageAttestations", refNoTag, mock.Anything).Return([]oci.Signature{attestationWithBaseImage(c.baseImage)}, true, nil)
+
+			actual, err := ValidateImage(ctx, component, &app.SnapshotSpec{}, p, []evaluator.Evaluator{}, false)
+			require.NoError(t, err)
+
+			assert.Equal(t, c.expectedViolations, actual.Violations())
+			assert.Contains(t, string(actual.PolicyInput), `"base_images":[{"ref":"`+c.baseImage+`"`)
+		})
+	}
+}
+
+func TestAttestationAgeCheck(t *testing.T) {
+	current := time.Date(2024, 6, 30, 12, 0, 0, 0, time.UTC)
+	now = func() time.Time { return current }
+	t.Cleanup(func() { now = time.Now })
+
+	attestationFinishedOn := func(finishedOn *time.Time, slsaV1 bool) oci.Signature {
+		if slsaV1 {
+			predicate := v1slsa.ProvenancePredicate{
+				BuildDefinition: v1slsa.ProvenanceBuildDefinition{
+					BuildType: "https://tekton.dev/chains/v2/slsa",
+				},
+				RunDetails: v1slsa.ProvenanceRunDetails{
+					Builder: v1slsa.Builder{
+						ID: "scheme:uri",
+					},
+					BuildMetadata: v1slsa.BuildMetadata{FinishedOn: finishedOn},
+				},
+			}
+
+			return sign(&in_toto.Statement{
+				StatementHeader: in_toto.StatementHeader{
+					Type:          in_toto.StatementInTotoV01,
+					PredicateType: v1slsa.PredicateSLSAProvenance,
+					Subject: []in_toto.Subject{
+						{Name: imageRegistry, Digest: common.DigestSet{"sha256": imageDigest}},
+					},
+				},
+				Predicate: predicate,
+			})
+		}
+
+		predicate := v02.ProvenancePredicate{
+			BuildType: "https://tekton.dev/attestations/chains/pipelinerun@v2",
+			Builder: common.ProvenanceBuilder{
+				ID: "scheme:uri",
+			},
+		}
+		if finishedOn != nil {
+			predicate.Metadata = &v02.ProvenanceMetadata{BuildFinishedOn: finishedOn}
+		}
+
+		return sign(&in_toto.Statement{
+			StatementHeader: in_toto.StatementHeader{
+				Type:          in_toto.StatementInTotoV01,
+				PredicateType: v02.PredicateSLSAProvenance,
+				Subject: []in_toto.Subject{
+					{Name: imageRegistry, Digest: common.DigestSet{"sha256": imageDigest}},
+				},
+			},
+			Predicate: predicate,
+		})
+	}
+
+	fresh := current.Add(-24 * time.Hour)
+	stale := current.Add(-45*24*time.Hour - 6*time.Hour)
+
+	cases := []struct {
+		name               string
+		finishedOn         *time.Time
+		slsaV1             bool
+		maxAge             time.Duration
+		expectedViolations []evaluator.Result
+	}{
+		{
+			name:               "check disabled",
+			finishedOn:         &stale,
+			expectedViolations: []evaluator.Result{},
+		},
+		{
+			name:               "fresh provenance",
+			finishedOn:         &fresh,
+			maxAge:             30 * 24 * time.Hour,
+			expectedViolations: []evaluator.Result{},
+		},
+		{
+			name:       "stale provenance",
+			finishedOn: &stale,
+			maxAge:     30 * 24 * time.Hour,
+			expectedViolations: []evaluator.Result{
+				{Message: "Attestation age check failed: the provenance was created 45d6h ago (at 2024-05-16T06:00:00Z), exceeding the maximum age of 30d", Metadata: map[string]interface{}{
+					"code": "builtin.attestation.age_check",
+				}},
+			},
+		},
+		{
+			name:               "fresh SLSA v1.0 provenance",
+			finishedOn:         &fresh,
+			slsaV1:             true,
+			maxAge:             30 * 24 * time.Hour,
+			expectedViolations: []evaluator.Result{},
+		},
+		{
+			name:       "stale SLSA v1.0 provenance",
+			finishedOn: &stale,
+			slsaV1:     true,
+			maxAge:     30 * 24 * time.Hour,
+			expectedViolations: []evaluator.Result{
+				{Message: "Attestation age check failed: the provenance was created 45d6h ago (at 2024-05-16T06:00:00Z), exceeding the maximum age of 30d", Metadata: map[string]interface{}{
+					"code": "builtin.attestation.age_check",
+				}},
+			},
+		},
+		{
+			name:   "provenance without build finish time",
+			maxAge: 30 * 24 * time.Hour,
+			expectedViolations: []evaluator.Result{
+				{Message: "Attestation age check failed: the provenance does not record the time the build finished", Metadata: map[string]interface{}{
+					"code": "builtin.attestation.age_check",
+				}},
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctx := utils.WithFS(context.Background(), afero.NewMemMapFs())
+			p, err := policy.NewOfflinePolicy(ctx, policy.Now)
+			require.NoError(t, err)
+
+			component := app.SnapshotComponent{ContainerImage: imageRef}
+			ctx = withImageConfig(ctx, component.ContainerImage)
+			if c.maxAge > 0 {
+				ctx = WithMaxAttestationAge(ctx, c.maxAge)
+			}
+
+			client := ecoci.NewClient(ctx).(*fake.FakeClient)
+			client.On("Head", ref).Return(&gcr.Descriptor{MediaType: types.OCIManifestSchema1}, nil)
+			client.On("VerifyImageSignatures", refNoTag, mock.Anything).Return([]oci.Signature{validSignature}, true, nil)
+			client.On("VerifyImageAttestations", refNoTag, mock.Anything).Return([]oci.Signature{attestationFinishedOn(c.finishedOn, c.slsaV1)}, true, nil)
+
+			actual, err := ValidateImage(ctx, component, &app.SnapshotSpec{}, p, []evaluator.Evaluator{}, false)
+			require.NoError(t, err)
+
+			assert.Equal(t, c.expectedViolations, actual.Violations())
+		})
+	}
+}
+
+func TestTagReference(t *testing.T) {
+	digest := "sha256:4e388ab32b10dc8dbc7e28144f552830adc74787c1e2c0824032078a79f227fb"
+
+	cases := []struct {
+		url      string
+		expected string
+	}{
+		{"registry.io/repository/image:tag", "registry.io/repository/image:tag"},
+		{"registry.io/repository/image:tag@" + digest, "registry.io/repository/image:tag"},
+		{"localhost:5000/image:tag@" + digest, "localhost:5000/image:tag"},
+		{"registry.io/repository/image@" + digest, ""},
+		{"localhost:5000/image@" + digest, ""},
+		{"registry.io/repository/image", ""},
+	}
+
+	for _, c := range cases {
+		t.Run(c.url, func(t *testing.T) {
+			assert.Equal(t, c.expected, tagReference(c.url))
+		})
+	}
+}
+
 func TestDetermineAttestationTime(t *testing.T) {
 	time1 := time.Date(2001, 2, 3, 4, 5, 6, 7, time.UTC)
 	time2 := time.Date(2010, 11, 12, 13, 14, 15, 16, time.UTC)
@@ -204,6 +443,21 @@ func TestDetermineAttestationTime(t *testing.T) {
 			},
 		},
 	}
+	time3 := time.Date(2020, 1, 2, 3, 4, 5, 6, time.UTC)
+	att4 := fakeAtt{
+		statement: in_toto.ProvenanceStatementSLSA1{
+			StatementHeader: in_toto.StatementHeader{
+				PredicateType: v1slsa.PredicateSLSAProvenance,
+			},
+			Predicate: v1slsa.ProvenancePredicate{
+				RunDetails: v1slsa.ProvenanceRunDetails{
+					BuildMetadata: v1slsa.BuildMetadata{
+						FinishedOn: &time3,
+					},
+				},
+			},
+		},
+	}
 
 	cases := []struct {
 		name         string
@@ -214,6 +468,8 @@ func TestDetermineAttestationTime(t *testing.T) {
 		{name: "one attestation", attestations: []attestation.Attestation{att1}, expected: &time1},
 		{name: "two attestations", attestations: []attestation.Attestation{att1, att2}, expected: &time2},
 		{name: "two attestations and one without time", attestations: []attestation.Attestation{att1, att2, att3}, expected: &time2},
+		{name: "SLSA v1.0 attestation", attestations: []attestation.Attestation{att4}, expected: &time3},
+		{name: "SLSA v0.2 and v1.0 attestations", attestations: []attestation.Attestation{att1, att2, att4}, expected: &time3},
 	}
 
 	for _, c := range cases {
@@ -315,6 +571,7 @@ func TestEvaluatorLifecycle(t *testing.T) {
 	client.On("VerifyImageSignatures", refNoTag, mock.Anything).Return([]oci.Signature{validSignature}, true, nil)
 	client.On("VerifyImageAttestations", refNoTag, mock.Anything).Return([]oci.Signature{validAttestation}, true, nil)
 	client.On("ResolveDigest", refNoTag).Return("@sha256:"+imageDigest, nil)
+	client.On("Referrers", mock.Anything).Return(empty.Index, nil)
 	ctx = ecoci.WithClient(ctx, &client)
 
 	component := app.SnapshotComponent{
@@ -346,3 +603,37 @@ func TestEvaluatorLifecycle(t *testing.T) {
 
 	require.NoError(t, err)
 }
+
+func TestAttestationSelection(t *testing.T) {
+	ctx := utils.WithFS(context.Background(), afero.NewMemMapFs())
+	p, err := policy.NewInputPolicy(ctx, `{
+		"sources": [{"policy": ["github.com/org/policy"]}],
+		"attestations": {
+			"include": ["https://slsa.dev/provenance/*", "https://example.com/*"],
+			"exclude": ["https://example.com/excluded"]
+		}
+	}`, policy.Now)
+	require.NoError(t, err)
+
+	component := app.SnapshotComponent{ContainerImage: imageRef}
+	ctx = withImageConfig(ctx, component.ContainerImage)
+
+	client := ecoci.NewClient(ctx).(*fake.FakeClient)
+	client.On("Head", ref).Return(&gcr.Descriptor{MediaType: types.OCIManifestSchema1}, nil)
+	client.On("VerifyImageSignatures", refNoTag, mock.Anything).Return([]oci.Signature{validSignature}, true, nil)
+	client.On("VerifyImageAttestations", refNoTag, mock.Anything).Return([]oci.Signature{
+		signWith(v02.PredicateSLSAProvenance, "alice"),
+		signWith("https://spdx.dev/Document", "alice"),
+		signWith("https://example.com/custom", "alice"),
+		signWith("https://example.com/excluded", "alice"),
+	}, true, nil)
+
+	actual, err := ValidateImage(ctx, component, &app.SnapshotSpec{}, p, []evaluator.Evaluator{}, false)
+	require.NoError(t, err)
+
+	predicateTypes := make([]string, 0, len(actual.Attestations))
+	for _, a := range actual.Attestations {
+		predicateTypes = append(predicateTypes, a.PredicateType())
+	}
+	assert.Equal(t, []string{v02.PredicateSLSAProvenance, "https://example.com/custom"}, predicateTypes)
+}
@@ -29,6 +29,7 @@ import (
 	"github.com/enterprise-contract/ec-cli/internal/evaluator"
 	"github.com/enterprise-contract/ec-cli/internal/format"
 	"github.com/enterprise-contract/ec-cli/internal/policy"
+	"github.com/enterprise-contract/ec-cli/internal/policy/source"
 	"github.com/enterprise-contract/ec-cli/internal/version"
 )
 
@@ -36,6 +37,7 @@ type Input struct {
 	FilePath     string             `json:"filepath"`
 	Violations   []evaluator.Result `json:"violations"`
 	Warnings     []evaluator.Result `json:"warnings"`
+	Reviews      []evaluator.Result `json:"reviews,omitempty"`
 	Successes    []evaluator.Result `json:"successes"`
 	Success      bool               `json:"success"`
 	SuccessCount int                `json:"success-count"`
@@ -50,6 +52,22 @@ type Report struct {
 	Data          any                              `json:"-"`
 	EffectiveTime time.Time                        `json:"effective-time"`
 	PolicyInput   [][]byte                         `json:"-"`
+
+	// ReviewRequired is set when any of the files has results from review
+	// rules, i.e. the outcome needs to be reviewed manually
+	ReviewRequired bool `json:"review-required,omitempty"`
+
+	// SourceMirrors holds the URLs of the policy and data sources that were
+	// downloaded from a mirror, and the URL of the mirror used for each
+	SourceMirrors map[string]string `json:"source-mirrors,omitempty"`
+
+	// ResolvedSources holds the commit, image digest and content digest of
+	// each of the policy and data sources downloaded for the validation
+	ResolvedSources []source.SourceInfo `json:"resolved-sources,omitempty"`
+
+	// PolicyLineage holds the references of the policies the policy extends,
+	// the policy extended directly first
+	PolicyLineage []string `json:"policy-lineage,omitempty"`
 }
 
 type summary struct {
@@ -94,26 +112,32 @@ const (
 // the filepaths provided.
 func NewReport(inputs []Input, policy policy.Policy, data any, policyInput [][]byte) (Report, error) {
 	success := true
+	reviewRequired := false
 
 	// Set the report success, remains true if all the files were successfully validated
 	for _, fpath := range inputs {
 		if !fpath.Success {
 			success = false
-			break
+		}
+		if len(fpath.Reviews) > 0 {
+			reviewRequired = true
 		}
 	}
 
 	info, _ := version.ComputeInfo()
 
 	return Report{
-		Success:       success,
-		created:       time.Now().UTC(),
-		FilePaths:     inputs,
-		Policy:        policy.Spec(),
-		EcVersion:     info.Version,
-		Data:          data,
-		EffectiveTime: policy.EffectiveTime().UTC(),
-		PolicyInput:   policyInput,
+		Success:         success,
+		created:         time.Now().UTC(),
+		FilePaths:       inputs,
+		Policy:          policy.Spec(),
+		EcVersion:       info.Version,
+		Data:            data,
+		EffectiveTime:   policy.EffectiveTime().UTC(),
+		PolicyInput:     policyInput,
+		ReviewRequired:  reviewRequired,
+		ResolvedSources: source.DownloadedSources(policy.Spec()),
+		PolicyLineage:   policy.Lineage(),
 	}, nil
 }
 
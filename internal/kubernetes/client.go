@@ -19,14 +19,23 @@ package kubernetes
 import (
 	"context"
 	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
 
 	ecc "github.com/enterprise-contract/enterprise-contract-controller/api/v1alpha1"
 	app "github.com/konflux-ci/application-api/api/v1alpha1"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
+	pipelinev1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
@@ -39,55 +48,227 @@ const clientContextKey contextKey = "ec.kubernetes.client"
 type Client interface {
 	FetchEnterpriseContractPolicy(ctx context.Context, ref string) (*ecc.EnterpriseContractPolicy, error)
 	FetchSnapshot(ctx context.Context, ref string) (*app.Snapshot, error)
+	FetchPipelineRun(ctx context.Context, ref string) (*pipelinev1.PipelineRun, error)
+	FetchTaskRun(ctx context.Context, ref string) (*pipelinev1.TaskRun, error)
+	FetchConfigMap(ctx context.Context, ref string) (*corev1.ConfigMap, error)
+	FetchSecret(ctx context.Context, ref string) (*corev1.Secret, error)
 }
 
 type kubernetesClient struct {
 	client dynamic.Interface
+	// config the namespace of references without one is determined from
+	config Config
+	// currentNamespace returns the namespace of references without one
+	currentNamespace func() (string, error)
+	// timeout of each request, no timeout other than the deadline of the
+	// context is applied when zero
+	timeout time.Duration
 }
 
-var kubeconfig string
+// Option configures the client created via NewClientWith
+type Option func(*kubernetesClient)
 
-func AddKubeconfigFlag(cmd *cobra.Command) {
-	cmd.PersistentFlags().StringVar(&kubeconfig, "kubeconfig", "", "path to the Kubernetes config file to use")
+// WithFetchTimeout limits the time each fetch from the cluster can take
+func WithFetchTimeout(timeout time.Duration) Option {
+	return func(k *kubernetesClient) {
+		k.timeout = timeout
+	}
+}
+
+// WithNamespace sets the namespace used for references without a namespace
+// instead of the namespace of the current context
+func WithNamespace(namespace string) Option {
+	return func(k *kubernetesClient) {
+		k.config.Namespace = namespace
+	}
+}
+
+// Config selects the cluster, and the namespace within it, the client
+// connects to
+type Config struct {
+	// Kubeconfig is the path of the Kubernetes config file, the default
+	// loading rules, i.e. the KUBECONFIG environment variable or
+	// ~/.kube/config, apply when empty
+	Kubeconfig string
+	// Context is the context of the Kubernetes config file to use instead of
+	// the current context
+	Context string
+	// Namespace is used for references without a namespace instead of the
+	// namespace of the context
+	Namespace string
+}
+
+// config is the configuration given by the flags added via
+// AddKubeconfigFlags
+var config Config
+
+// AddKubeconfigFlags adds the flags selecting the cluster, and the namespace,
+// the client created via NewClient connects to
+func AddKubeconfigFlags(cmd *cobra.Command) {
+	cmd.PersistentFlags().StringVar(&config.Kubeconfig, "kubeconfig", "", "path to the Kubernetes config file to use")
+	cmd.PersistentFlags().StringVar(&config.Context, "context", "", "name of the Kubernetes config context to use instead of the current context")
+	cmd.PersistentFlags().StringVar(&config.Namespace, "namespace", "", "Kubernetes namespace used for references without a namespace instead of the namespace of the context")
+}
+
+// clientConfig returns the client configuration loaded as by kubectl, with the
+// context and the namespace overridden if given
+func (c Config) clientConfig() clientcmd.ClientConfig {
+	o := overrides
+	if c.Context != "" {
+		o.CurrentContext = c.Context
+	}
+	if c.Namespace != "" {
+		o.Context.Namespace = c.Namespace
+	}
+
+	return clientcmd.NewNonInteractiveDeferredLoadingClientConfig(c.loadingRules(), &o)
+}
+
+// loadingRules returns the rules the kubeconfig is loaded with, as by kubectl
+// unless a kubeconfig is given
+func (c Config) loadingRules() *clientcmd.ClientConfigLoadingRules {
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	if c.Kubeconfig != "" {
+		rules.ExplicitPath = c.Kubeconfig
+	}
+
+	return rules
+}
+
+// inClusterConfig provides the configuration of the cluster the pod runs in,
+// replaced in tests
+var inClusterConfig = rest.InClusterConfig
+
+// restConfig returns the configuration to connect to the cluster with. When
+// running in a pod, i.e. KUBERNETES_SERVICE_HOST is set, and neither a
+// kubeconfig nor a context has been selected, the in-cluster configuration is
+// tried first, the kubeconfig is loaded otherwise or if that fails. The error
+// returned lists the configurations that were attempted.
+func (c Config) restConfig() (*rest.Config, error) {
+	var attempts []string
+
+	if c.Kubeconfig != "" || c.Context != "" || os.Getenv(clientcmd.RecommendedConfigPathEnvVar) != "" {
+		attempts = append(attempts, "in-cluster configuration: skipped, a kubeconfig or a context was selected")
+	} else if os.Getenv("KUBERNETES_SERVICE_HOST") == "" {
+		attempts = append(attempts, "in-cluster configuration: skipped, not running in a pod, KUBERNETES_SERVICE_HOST is not set")
+	} else {
+		restConfig, err := inClusterConfig()
+		if err == nil {
+			log.Debug("Using the in-cluster Kubernetes configuration")
+			return restConfig, nil
+		}
+		log.Debugf("Unable to use the in-cluster Kubernetes configuration: %v", err)
+		attempts = append(attempts, fmt.Sprintf("in-cluster configuration: %v", err))
+	}
+
+	rules := c.loadingRules()
+	paths := rules.GetLoadingPrecedence()
+	if rules.ExplicitPath != "" {
+		paths = []string{rules.ExplicitPath}
+	}
+
+	restConfig, err := c.clientConfig().ClientConfig()
+	if err == nil {
+		log.Debugf("Using the Kubernetes configuration from %s", strings.Join(paths, ", "))
+		return restConfig, nil
+	}
+	attempts = append(attempts, fmt.Sprintf("kubeconfig %s: %v", strings.Join(paths, ", "), err))
+
+	return nil, fmt.Errorf("unable to configure the connection to the Kubernetes cluster, attempted:\n  - %s", strings.Join(attempts, "\n  - "))
 }
 
 func WithClient(ctx context.Context, client Client) context.Context {
 	return context.WithValue(ctx, clientContextKey, client)
 }
 
-// NewClient constructs a new kubernetes with the default "live" client
+// clientKey identifies the shared clients, the KUBECONFIG environment variable
+// is part of it as it determines the cluster when no kubeconfig is given
+type clientKey struct {
+	config     Config
+	kubeconfig string
+}
+
+var (
+	sharedClientsMu sync.Mutex
+	// sharedClients holds the clients created via NewClient
+	sharedClients = map[clientKey]Client{}
+)
+
+// NewClient returns the kubernetes client with the default "live" client,
+// connecting to the cluster selected via the flags added by
+// AddKubeconfigFlags, or the client injected via WithClient, e.g. a fake one
+// in tests. The live client is created on first use and shared by all further
+// invocations so the Kubernetes config is loaded only once.
 func NewClient(ctx context.Context) (Client, error) {
 	client, ok := ctx.Value(clientContextKey).(Client)
 	if ok && client != nil {
 		return client, nil
 	}
 
-	c, err := createK8SClient()
+	key := clientKey{config: config, kubeconfig: os.Getenv(clientcmd.RecommendedConfigPathEnvVar)}
+
+	sharedClientsMu.Lock()
+	defer sharedClientsMu.Unlock()
+
+	if client, ok := sharedClients[key]; ok {
+		return client, nil
+	}
+
+	client, err := NewClientFor(config)
+	if err != nil {
+		return nil, err
+	}
+	sharedClients[key] = client
+
+	return client, nil
+}
+
+// NewClientFor constructs a new kubernetes client connecting to the cluster
+// selected by the given configuration
+func NewClientFor(cfg Config, options ...Option) (Client, error) {
+	c, err := createK8SClient(cfg)
 	if err != nil {
 		log.Debug("Failed to create k8s client!")
 		return nil, err
 	}
 
-	return &kubernetesClient{
-		client: c,
-	}, nil
+	client := NewClientWith(c, append([]Option{withConfig(cfg)}, options...)...)
+	// the Kubernetes config doesn't change while the client is used
+	k := client.(*kubernetesClient)
+	k.currentNamespace = sync.OnceValues(k.currentNamespace)
+
+	return client, nil
 }
 
-func createK8SClient() (client dynamic.Interface, err error) {
-	rules := clientcmd.NewDefaultClientConfigLoadingRules()
-	if kubeconfig != "" {
-		rules.ExplicitPath = kubeconfig
+func withConfig(cfg Config) Option {
+	return func(k *kubernetesClient) {
+		k.config = cfg
+	}
+}
+
+// NewClientWith constructs a new kubernetes client using the given dynamic
+// client, e.g. one created via k8s.io/client-go/dynamic/fake in tests
+func NewClientWith(client dynamic.Interface, options ...Option) Client {
+	k := &kubernetesClient{
+		client: client,
 	}
 
-	clientConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules, nil)
+	for _, o := range options {
+		o(k)
+	}
+	k.currentNamespace = k.config.currentNamespace
+
+	return k
+}
 
-	var config *rest.Config
-	config, err = clientConfig.ClientConfig()
+func createK8SClient(cfg Config) (client dynamic.Interface, err error) {
+	var restConfig *rest.Config
+	restConfig, err = cfg.restConfig()
 	if err != nil {
 		return
 	}
 
-	client, err = dynamic.NewForConfig(config)
+	client, err = dynamic.NewForConfig(restConfig)
 
 	return
 }
@@ -103,7 +284,7 @@ func (k *kubernetesClient) FetchEnterpriseContractPolicy(ctx context.Context, re
 	}
 	log.Debugf("Raw policy reference: %q", ref)
 
-	name, err := NamespacedName(ref)
+	name, err := k.namespacedName(ref)
 	if err != nil {
 		return nil, err
 	}
@@ -113,7 +294,7 @@ func (k *kubernetesClient) FetchEnterpriseContractPolicy(ctx context.Context, re
 	}
 
 	var unstructuredPolicy *unstructured.Unstructured
-	if unstructuredPolicy, err = k.client.Resource(ecc.GroupVersion.WithResource("enterprisecontractpolicies")).Namespace(name.Namespace).Get(ctx, name.Name, v1.GetOptions{}); err != nil {
+	if unstructuredPolicy, err = k.get(ctx, ecc.GroupVersion.WithResource("enterprisecontractpolicies"), "EnterpriseContractPolicy", *name); err != nil {
 		log.Debugf("Failed to fetch the policy from cluster: %s", err)
 		return nil, err
 	}
@@ -140,7 +321,7 @@ func (k *kubernetesClient) FetchSnapshot(ctx context.Context, ref string) (*app.
 	}
 	log.Debugf("Raw snapshot reference: %q", ref)
 
-	name, err := NamespacedName(ref)
+	name, err := k.namespacedName(ref)
 	if err != nil {
 		return nil, err
 	}
@@ -150,7 +331,7 @@ func (k *kubernetesClient) FetchSnapshot(ctx context.Context, ref string) (*app.
 	}
 
 	var unstructuredSnapshot *unstructured.Unstructured
-	if unstructuredSnapshot, err = k.client.Resource(app.GroupVersion.WithResource("snapshots")).Namespace(name.Namespace).Get(ctx, name.Name, v1.GetOptions{}); err != nil {
+	if unstructuredSnapshot, err = k.get(ctx, app.GroupVersion.WithResource("snapshots"), "Snapshot", *name); err != nil {
 		log.Debugf("Failed to fetch the snapshot from cluster: %s", err)
 		return nil, err
 	}
@@ -165,3 +346,86 @@ func (k *kubernetesClient) FetchSnapshot(ctx context.Context, ref string) (*app.
 
 	return &snapshot, nil
 }
+
+// FetchPipelineRun gets the Tekton PipelineRun from the given reference.
+func (k *kubernetesClient) FetchPipelineRun(ctx context.Context, ref string) (*pipelinev1.PipelineRun, error) {
+	return fetch[pipelinev1.PipelineRun](ctx, k, pipelinev1.SchemeGroupVersion.WithResource("pipelineruns"), "PipelineRun", "pipeline run", ref)
+}
+
+// FetchTaskRun gets the Tekton TaskRun from the given reference.
+func (k *kubernetesClient) FetchTaskRun(ctx context.Context, ref string) (*pipelinev1.TaskRun, error) {
+	return fetch[pipelinev1.TaskRun](ctx, k, pipelinev1.SchemeGroupVersion.WithResource("taskruns"), "TaskRun", "task run", ref)
+}
+
+// FetchConfigMap gets the ConfigMap from the given reference.
+func (k *kubernetesClient) FetchConfigMap(ctx context.Context, ref string) (*corev1.ConfigMap, error) {
+	return fetch[corev1.ConfigMap](ctx, k, corev1.SchemeGroupVersion.WithResource("configmaps"), "ConfigMap", "config map", ref)
+}
+
+// FetchSecret gets the Secret from the given reference.
+func (k *kubernetesClient) FetchSecret(ctx context.Context, ref string) (*corev1.Secret, error) {
+	return fetch[corev1.Secret](ctx, k, corev1.SchemeGroupVersion.WithResource("secrets"), "Secret", "secret", ref)
+}
+
+// fetch gets the resource of the given kind from the given reference in a
+// Kubernetes cluster, the description names the resource in the errors and
+// the log messages. Only the namespace and the name of the fetched resource
+// are logged, so that the content of secrets is never logged.
+//
+// The reference is expected to be in the format [<namespace>/]<name>. If it does not contain
+// a namespace, the current namespace is used.
+func fetch[T any, PT interface {
+	*T
+	v1.Object
+}](ctx context.Context, k *kubernetesClient, resource schema.GroupVersionResource, kind, description, ref string) (PT, error) {
+	if len(ref) == 0 {
+		return nil, fmt.Errorf("%s reference cannot be empty", description)
+	}
+	log.Debugf("Raw %s reference: %q", description, ref)
+
+	name, err := k.namespacedName(ref)
+	if err != nil {
+		return nil, err
+	}
+	log.Debugf("Parsed %s reference: %v", description, name)
+	if name.Namespace == "" {
+		return nil, fmt.Errorf("unable to determine namespace for %s", description)
+	}
+
+	u, err := k.get(ctx, resource, kind, *name)
+	if err != nil {
+		log.Debugf("Failed to fetch the %s from cluster: %s", description, err)
+		return nil, err
+	}
+
+	obj := PT(new(T))
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.UnstructuredContent(), obj); err != nil {
+		log.Debugf("Failed to convert unstructured content to concrete %s structure: %s", description, err)
+		return nil, err
+	}
+
+	log.Debugf("%s successfully fetched from cluster: %s/%s", kind, obj.GetNamespace(), obj.GetName())
+
+	return obj, nil
+}
+
+// get fetches the resource with the given name from the cluster, honoring the
+// deadline of the context and the configured timeout
+func (k *kubernetesClient) get(ctx context.Context, resource schema.GroupVersionResource, kind string, name types.NamespacedName) (*unstructured.Unstructured, error) {
+	if k.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, k.timeout)
+		defer cancel()
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("%s %s: %w", kind, name, err)
+	}
+
+	u, err := k.client.Resource(resource).Namespace(name.Namespace).Get(ctx, name.Name, v1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("%s %s: %w", kind, name, err)
+	}
+
+	return u, nil
+}
@@ -20,17 +20,23 @@ package kubernetes
 
 import (
 	"context"
+	"errors"
 	"os"
 	"path"
 	"testing"
+	"time"
 
 	ecc "github.com/enterprise-contract/enterprise-contract-controller/api/v1alpha1"
 	app "github.com/konflux-ci/application-api/api/v1alpha1"
 	"github.com/stretchr/testify/assert"
+	pipelinev1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/rest"
 )
 
 var fakeClient dynamic.Interface
@@ -76,6 +82,34 @@ var testSnapshot = app.Snapshot{
 	},
 }
 
+var testPipelineRun = pipelinev1.PipelineRun{
+	TypeMeta: v1.TypeMeta{
+		Kind:       "PipelineRun",
+		APIVersion: "tekton.dev/v1",
+	},
+	ObjectMeta: v1.ObjectMeta{
+		Name:      "pipeline-run",
+		Namespace: "test",
+	},
+	Spec: pipelinev1.PipelineRunSpec{
+		PipelineRef: &pipelinev1.PipelineRef{Name: "build"},
+	},
+}
+
+var testTaskRun = pipelinev1.TaskRun{
+	TypeMeta: v1.TypeMeta{
+		Kind:       "TaskRun",
+		APIVersion: "tekton.dev/v1",
+	},
+	ObjectMeta: v1.ObjectMeta{
+		Name:      "task-run",
+		Namespace: "test",
+	},
+	Spec: pipelinev1.TaskRunSpec{
+		TaskRef: &pipelinev1.TaskRef{Name: "clone"},
+	},
+}
+
 var testKubeconfig = []byte(`
 apiVersion: v1
 kind: Config
@@ -91,16 +125,50 @@ contexts:
 current-context: test-context
 `)
 
+var testConfigMap = corev1.ConfigMap{
+	TypeMeta: v1.TypeMeta{
+		Kind:       "ConfigMap",
+		APIVersion: "v1",
+	},
+	ObjectMeta: v1.ObjectMeta{
+		Name:      "config",
+		Namespace: "test",
+	},
+	Data: map[string]string{
+		"policy.yaml": "sources: []",
+	},
+}
+
+var testSecret = corev1.Secret{
+	TypeMeta: v1.TypeMeta{
+		Kind:       "Secret",
+		APIVersion: "v1",
+	},
+	ObjectMeta: v1.ObjectMeta{
+		Name:      "secret",
+		Namespace: "test",
+	},
+	Data: map[string][]byte{
+		"data.json": []byte(`{"key": "value"}`),
+	},
+}
+
 func init() {
 	scheme := runtime.NewScheme()
+	if err := corev1.AddToScheme(scheme); err != nil {
+		panic(err)
+	}
 	if err := ecc.AddToScheme(scheme); err != nil {
 		panic(err)
 	}
 	if err := app.AddToScheme(scheme); err != nil {
 		panic(err)
 	}
+	if err := pipelinev1.AddToScheme(scheme); err != nil {
+		panic(err)
+	}
 
-	fakeClient = fake.NewSimpleDynamicClient(scheme, &testECP, &testSnapshot)
+	fakeClient = fake.NewSimpleDynamicClient(scheme, &testECP, &testSnapshot, &testPipelineRun, &testTaskRun, &testConfigMap, &testSecret)
 }
 
 func Test_FetchEnterpriseContractPolicy(t *testing.T) {
@@ -157,9 +225,118 @@ func Test_FetchEnterpriseContractPolicy(t *testing.T) {
 
 func Test_FailureToCreateClient(t *testing.T) {
 	t.Setenv("KUBECONFIG", "/nonexistant")
-	_, err := createK8SClient()
+	_, err := createK8SClient(Config{})
+
+	assert.EqualError(t, err, `unable to configure the connection to the Kubernetes cluster, attempted:
+  - in-cluster configuration: skipped, a kubeconfig or a context was selected
+  - kubeconfig /nonexistant: invalid configuration: no configuration has been provided, try setting KUBERNETES_MASTER environment variable`)
+}
+
+func Test_NewClientFor(t *testing.T) {
+	t.Setenv("KUBECONFIG", "/nonexistant")
+
+	kubeconfig := path.Join(t.TempDir(), "kubeconfig")
+	assert.NoError(t, os.WriteFile(kubeconfig, testKubeconfig, 0400))
+
+	c, err := NewClientFor(Config{Kubeconfig: kubeconfig, Namespace: "ci"})
+	assert.NoError(t, err)
+	assert.Equal(t, Config{Kubeconfig: kubeconfig, Namespace: "ci"}, c.(*kubernetesClient).config)
+
+	_, err = NewClientFor(Config{Kubeconfig: kubeconfig, Context: "missing-context"})
+	assert.ErrorContains(t, err, `context "missing-context" does not exist`)
+}
+
+func Test_RestConfig(t *testing.T) {
+	kubeconfig := path.Join(t.TempDir(), "kubeconfig")
+	assert.NoError(t, os.WriteFile(kubeconfig, testKubeconfig, 0400))
+
+	inCluster := inClusterConfig
+	t.Cleanup(func() {
+		inClusterConfig = inCluster
+	})
+
+	t.Run("in-cluster", func(t *testing.T) {
+		t.Setenv("KUBERNETES_SERVICE_HOST", "10.0.0.1")
+		t.Setenv("KUBECONFIG", "")
+		inClusterConfig = func() (*rest.Config, error) {
+			return &rest.Config{Host: "https://10.0.0.1:443"}, nil
+		}
+
+		c, err := Config{}.restConfig()
+		assert.NoError(t, err)
+		assert.Equal(t, "https://10.0.0.1:443", c.Host)
+
+		// the kubeconfig given takes precedence
+		c, err = Config{Kubeconfig: kubeconfig}.restConfig()
+		assert.NoError(t, err)
+		assert.NotEqual(t, "https://10.0.0.1:443", c.Host)
+	})
+
+	t.Run("in-cluster failure", func(t *testing.T) {
+		t.Setenv("KUBERNETES_SERVICE_HOST", "10.0.0.1")
+		t.Setenv("KUBECONFIG", "")
+		inClusterConfig = func() (*rest.Config, error) {
+			return nil, errors.New("open /var/run/secrets/kubernetes.io/serviceaccount/token: no such file or directory")
+		}
+
+		_, err := Config{Context: "missing-context"}.restConfig()
+		assert.ErrorContains(t, err, "in-cluster configuration: skipped, a kubeconfig or a context was selected")
+
+		_, err = Config{}.restConfig()
+		if err != nil {
+			assert.ErrorContains(t, err, "unable to configure the connection to the Kubernetes cluster, attempted:\n  - in-cluster configuration: open /var/run/secrets/kubernetes.io/serviceaccount/token: no such file or directory\n  - kubeconfig ")
+		}
+	})
+
+	t.Run("not in a pod", func(t *testing.T) {
+		t.Setenv("KUBERNETES_SERVICE_HOST", "")
+		t.Setenv("KUBECONFIG", "/nonexistant")
+
+		_, err := Config{Context: "missing-context"}.restConfig()
+		assert.EqualError(t, err, `unable to configure the connection to the Kubernetes cluster, attempted:
+  - in-cluster configuration: skipped, a kubeconfig or a context was selected
+  - kubeconfig /nonexistant: context "missing-context" does not exist`)
+
+		_, err = Config{Kubeconfig: kubeconfig, Context: "missing-context"}.restConfig()
+		assert.ErrorContains(t, err, "  - kubeconfig "+kubeconfig+": ")
+	})
+}
+
+func Test_NewClientShared(t *testing.T) {
+	kubeconfig := path.Join(t.TempDir(), "kubeconfig")
+	assert.NoError(t, os.WriteFile(kubeconfig, testKubeconfig, 0400))
+	t.Setenv("KUBECONFIG", kubeconfig)
+
+	t.Cleanup(func() {
+		sharedClients = map[clientKey]Client{}
+	})
+
+	c1, err := NewClient(context.Background())
+	assert.NoError(t, err)
+	c2, err := NewClient(context.Background())
+	assert.NoError(t, err)
+	assert.Same(t, c1, c2)
+
+	// a different cluster gets a different client
+	other := path.Join(t.TempDir(), "kubeconfig")
+	assert.NoError(t, os.WriteFile(other, testKubeconfig, 0400))
+	t.Setenv("KUBECONFIG", other)
+
+	c3, err := NewClient(context.Background())
+	assert.NoError(t, err)
+	assert.NotSame(t, c1, c3)
+
+	// errors are not retained
+	t.Setenv("KUBECONFIG", "/nonexistant")
+	_, err = NewClient(context.Background())
+	assert.Error(t, err)
+	assert.Len(t, sharedClients, 2)
 
-	assert.EqualError(t, err, "invalid configuration: no configuration has been provided, try setting KUBERNETES_MASTER environment variable")
+	// the injected client takes precedence
+	injected := NewClientWith(fakeClient)
+	c4, err := NewClient(WithClient(context.Background(), injected))
+	assert.NoError(t, err)
+	assert.Same(t, injected, c4)
 }
 
 func Test_FetchSnapshot(t *testing.T) {
@@ -213,3 +390,262 @@ func Test_FetchSnapshot(t *testing.T) {
 		})
 	}
 }
+
+func Test_FetchPipelineRun(t *testing.T) {
+	testCases := []struct {
+		name            string
+		pipelineRunName string
+		pipelineRun     *pipelinev1.PipelineRun
+		err             string
+	}{
+		{
+			name:            "fetch-with-name-and-namespace",
+			pipelineRunName: "test/pipeline-run",
+			pipelineRun:     &testPipelineRun,
+		},
+		{
+			name:            "fetch-with-name-only",
+			pipelineRunName: "pipeline-run",
+			pipelineRun:     &testPipelineRun,
+		},
+		{
+			name:            "fetch-pipeline-run-not-found",
+			pipelineRunName: "missing/pipeline-run",
+			err:             `pipelineruns.tekton.dev "pipeline-run" not found`,
+		},
+		{
+			name: "empty-reference",
+			err:  "pipeline run reference cannot be empty",
+		},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.name, func(t *testing.T) {
+			k := kubernetesClient{
+				client: fakeClient,
+			}
+
+			kubeconfigFile := path.Join(t.TempDir(), "KUBECONFIG")
+			err := os.WriteFile(kubeconfigFile, testKubeconfig, 0400)
+			assert.NoError(t, err)
+			t.Setenv("KUBECONFIG", kubeconfigFile)
+
+			got, err := k.FetchPipelineRun(context.TODO(), c.pipelineRunName)
+
+			if c.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, c.err)
+			}
+
+			if c.pipelineRun == nil {
+				assert.Nil(t, got)
+			} else {
+				assert.Equal(t, *c.pipelineRun, *got, "should return the stubbed PipelineRun")
+			}
+		})
+	}
+}
+
+func Test_FetchTaskRun(t *testing.T) {
+	testCases := []struct {
+		name        string
+		taskRunName string
+		taskRun     *pipelinev1.TaskRun
+		err         string
+	}{
+		{
+			name:        "fetch-with-name-and-namespace",
+			taskRunName: "test/task-run",
+			taskRun:     &testTaskRun,
+		},
+		{
+			name:        "fetch-with-name-only",
+			taskRunName: "task-run",
+			taskRun:     &testTaskRun,
+		},
+		{
+			name:        "fetch-task-run-not-found",
+			taskRunName: "missing/task-run",
+			err:         `taskruns.tekton.dev "task-run" not found`,
+		},
+		{
+			name: "empty-reference",
+			err:  "task run reference cannot be empty",
+		},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.name, func(t *testing.T) {
+			k := kubernetesClient{
+				client: fakeClient,
+			}
+
+			kubeconfigFile := path.Join(t.TempDir(), "KUBECONFIG")
+			err := os.WriteFile(kubeconfigFile, testKubeconfig, 0400)
+			assert.NoError(t, err)
+			t.Setenv("KUBECONFIG", kubeconfigFile)
+
+			got, err := k.FetchTaskRun(context.TODO(), c.taskRunName)
+
+			if c.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, c.err)
+			}
+
+			if c.taskRun == nil {
+				assert.Nil(t, got)
+			} else {
+				assert.Equal(t, *c.taskRun, *got, "should return the stubbed TaskRun")
+			}
+		})
+	}
+}
+
+func Test_FetchConfigMap(t *testing.T) {
+	testCases := []struct {
+		name          string
+		configMapName string
+		configMap     *corev1.ConfigMap
+		err           string
+	}{
+		{
+			name:          "fetch-with-name-and-namespace",
+			configMapName: "config@test",
+			configMap:     &testConfigMap,
+		},
+		{
+			name:          "fetch-with-name-only",
+			configMapName: "config",
+			configMap:     &testConfigMap,
+		},
+		{
+			name:          "fetch-config-map-not-found",
+			configMapName: "missing/config",
+			err:           `configmaps "config" not found`,
+		},
+		{
+			name: "empty-reference",
+			err:  "config map reference cannot be empty",
+		},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.name, func(t *testing.T) {
+			k := kubernetesClient{
+				client: fakeClient,
+			}
+
+			kubeconfigFile := path.Join(t.TempDir(), "KUBECONFIG")
+			err := os.WriteFile(kubeconfigFile, testKubeconfig, 0400)
+			assert.NoError(t, err)
+			t.Setenv("KUBECONFIG", kubeconfigFile)
+
+			got, err := k.FetchConfigMap(context.TODO(), c.configMapName)
+
+			if c.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, c.err)
+			}
+
+			if c.configMap == nil {
+				assert.Nil(t, got)
+			} else {
+				assert.Equal(t, *c.configMap, *got, "should return the stubbed ConfigMap")
+			}
+		})
+	}
+}
+
+func Test_FetchSecret(t *testing.T) {
+	testCases := []struct {
+		name       string
+		secretName string
+		secret     *corev1.Secret
+		err        string
+	}{
+		{
+			name:       "fetch-with-name-and-namespace",
+			secretName: "test/secret",
+			secret:     &testSecret,
+		},
+		{
+			name:       "fetch-with-name-only",
+			secretName: "secret",
+			secret:     &testSecret,
+		},
+		{
+			name:       "fetch-secret-not-found",
+			secretName: "missing/secret",
+			err:        `secrets "secret" not found`,
+		},
+		{
+			name: "empty-reference",
+			err:  "secret reference cannot be empty",
+		},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.name, func(t *testing.T) {
+			k := kubernetesClient{
+				client: fakeClient,
+			}
+
+			kubeconfigFile := path.Join(t.TempDir(), "KUBECONFIG")
+			err := os.WriteFile(kubeconfigFile, testKubeconfig, 0400)
+			assert.NoError(t, err)
+			t.Setenv("KUBECONFIG", kubeconfigFile)
+
+			got, err := k.FetchSecret(context.TODO(), c.secretName)
+
+			if c.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, c.err)
+			}
+
+			if c.secret == nil {
+				assert.Nil(t, got)
+			} else {
+				assert.Equal(t, *c.secret, *got, "should return the stubbed Secret")
+			}
+		})
+	}
+}
+
+func Test_NewClientWith(t *testing.T) {
+	kubeconfigFile := path.Join(t.TempDir(), "KUBECONFIG")
+	assert.NoError(t, os.WriteFile(kubeconfigFile, testKubeconfig, 0400))
+	t.Setenv("KUBECONFIG", kubeconfigFile)
+
+	k := NewClientWith(fakeClient, WithFetchTimeout(time.Minute))
+
+	t.Run("found", func(t *testing.T) {
+		got, err := k.FetchEnterpriseContractPolicy(context.Background(), "test/ec-policy")
+		assert.NoError(t, err)
+		assert.Equal(t, testECP, *got)
+	})
+
+	t.Run("found in the current namespace", func(t *testing.T) {
+		got, err := k.FetchEnterpriseContractPolicy(context.Background(), "ec-policy")
+		assert.NoError(t, err)
+		assert.Equal(t, testECP, *got)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		_, err := k.FetchEnterpriseContractPolicy(context.Background(), "missing/ec-policy")
+		assert.EqualError(t, err, `EnterpriseContractPolicy missing/ec-policy: enterprisecontractpolicies.appstudio.redhat.com "ec-policy" not found`)
+		assert.True(t, apierrors.IsNotFound(err))
+	})
+
+	t.Run("deadline exceeded", func(t *testing.T) {
+		ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+		defer cancel()
+
+		_, err := k.FetchEnterpriseContractPolicy(ctx, "test/ec-policy")
+		assert.EqualError(t, err, "EnterpriseContractPolicy test/ec-policy: context deadline exceeded")
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+}
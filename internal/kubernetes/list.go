@@ -0,0 +1,97 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package kubernetes
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	ecc "github.com/enterprise-contract/enterprise-contract-controller/api/v1alpha1"
+	log "github.com/sirupsen/logrus"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// policyLister is implemented by clients able to list the
+// EnterpriseContractPolicy resources
+type policyLister interface {
+	listEnterpriseContractPolicies(ctx context.Context, selector labels.Selector) ([]ecc.EnterpriseContractPolicy, error)
+}
+
+// ListEnterpriseContractPolicies lists the EnterpriseContractPolicy resources
+// matching the label selector, e.g. team=payments, in all namespaces the user
+// is allowed to list them in, or in the namespace selected via the --namespace
+// flag. The policies are sorted by namespace and name.
+func ListEnterpriseContractPolicies(ctx context.Context, selector string) ([]ecc.EnterpriseContractPolicy, error) {
+	s, err := labels.Parse(selector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid label selector %q: %w", selector, err)
+	}
+
+	k8s, err := NewClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("cannot initialize Kubernetes client: %w", err)
+	}
+
+	l, ok := k8s.(policyLister)
+	if !ok {
+		return nil, errors.New("the Kubernetes client does not support listing policies")
+	}
+
+	return l.listEnterpriseContractPolicies(ctx, s)
+}
+
+func (k *kubernetesClient) listEnterpriseContractPolicies(ctx context.Context, selector labels.Selector) ([]ecc.EnterpriseContractPolicy, error) {
+	if k.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, k.timeout)
+		defer cancel()
+	}
+
+	// an empty namespace lists across all namespaces
+	namespace := k.config.Namespace
+	log.Debugf("Listing EnterpriseContractPolicy resources matching %q in namespace %q", selector, namespace)
+
+	list, err := k.client.Resource(ecc.GroupVersion.WithResource("enterprisecontractpolicies")).Namespace(namespace).List(ctx, v1.ListOptions{
+		LabelSelector: selector.String(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to list EnterpriseContractPolicy resources matching %q: %w", selector, err)
+	}
+
+	policies := make([]ecc.EnterpriseContractPolicy, 0, len(list.Items))
+	for _, u := range list.Items {
+		policy := ecc.EnterpriseContractPolicy{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.UnstructuredContent(), &policy); err != nil {
+			return nil, fmt.Errorf("unable to convert the EnterpriseContractPolicy %s/%s: %w", u.GetNamespace(), u.GetName(), err)
+		}
+		policies = append(policies, policy)
+	}
+
+	slices.SortFunc(policies, func(a, b ecc.EnterpriseContractPolicy) int {
+		if c := cmp.Compare(a.Namespace, b.Namespace); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Name, b.Name)
+	})
+
+	return policies, nil
+}
@@ -0,0 +1,93 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package kubernetes
+
+import (
+	"context"
+	"testing"
+
+	ecc "github.com/enterprise-contract/enterprise-contract-controller/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic/fake"
+)
+
+func TestListEnterpriseContractPolicies(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, ecc.AddToScheme(scheme))
+
+	policy := func(namespace, name string, labels map[string]string) *ecc.EnterpriseContractPolicy {
+		p := testECP.DeepCopy()
+		p.Namespace = namespace
+		p.Name = name
+		p.Labels = labels
+		return p
+	}
+
+	dyn := fake.NewSimpleDynamicClient(scheme,
+		policy("team-b", "release", map[string]string{"team": "payments"}),
+		policy("team-a", "release", map[string]string{"team": "payments", "stage": "prod"}),
+		policy("team-a", "other", map[string]string{"team": "search"}),
+		policy("team-a", "dev", map[string]string{"team": "payments", "stage": "dev"}),
+	)
+
+	names := func(policies []ecc.EnterpriseContractPolicy) []string {
+		n := make([]string, 0, len(policies))
+		for _, p := range policies {
+			n = append(n, p.Namespace+"/"+p.Name)
+		}
+		return n
+	}
+
+	cases := []struct {
+		name      string
+		selector  string
+		namespace string
+		expected  []string
+		err       string
+	}{
+		{name: "across namespaces", selector: "team=payments", expected: []string{"team-a/dev", "team-a/release", "team-b/release"}},
+		{name: "in namespace", selector: "team=payments", namespace: "team-b", expected: []string{"team-b/release"}},
+		{name: "set based", selector: "team=payments,stage in (prod)", expected: []string{"team-a/release"}},
+		{name: "none matching", selector: "team=unknown", expected: []string{}},
+		{name: "invalid", selector: "team in payments", err: `invalid label selector "team in payments"`},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctx := WithClient(context.Background(), NewClientWith(dyn, WithNamespace(c.namespace)))
+
+			policies, err := ListEnterpriseContractPolicies(ctx, c.selector)
+			if c.err != "" {
+				assert.ErrorContains(t, err, c.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, c.expected, names(policies))
+		})
+	}
+}
+
+func TestListEnterpriseContractPoliciesNotSupported(t *testing.T) {
+	ctx := WithClient(context.Background(), notWatchingClient{})
+
+	_, err := ListEnterpriseContractPolicies(ctx, "team=payments")
+	assert.EqualError(t, err, "the Kubernetes client does not support listing policies")
+}
@@ -17,6 +17,7 @@
 package kubernetes
 
 import (
+	"fmt"
 	"strings"
 
 	log "github.com/sirupsen/logrus"
@@ -25,9 +26,53 @@ import (
 )
 
 // NamespacedName constructs a NamespacedName from the provided name by either
-// splitting it with the default spearator or augmenting it with the namespace
-// selected in the Kubernetes Context configuration.
+// parsing it with ParseNamespacedName or augmenting it with the namespace
+// selected in the Kubernetes Context configuration, or via the --namespace
+// flag.
 func NamespacedName(name string) (*types.NamespacedName, error) {
+	return config.namespacedName(name)
+}
+
+func (c Config) namespacedName(name string) (*types.NamespacedName, error) {
+	return withCurrentNamespace(name, c.currentNamespace)
+}
+
+func (k *kubernetesClient) namespacedName(name string) (*types.NamespacedName, error) {
+	if k.currentNamespace == nil {
+		return k.config.namespacedName(name)
+	}
+
+	return withCurrentNamespace(name, k.currentNamespace)
+}
+
+// withCurrentNamespace parses the name with ParseNamespacedName and augments it
+// with the namespace returned by currentNamespace if it has no namespace
+func withCurrentNamespace(name string, currentNamespace func() (string, error)) (*types.NamespacedName, error) {
+	n, err := ParseNamespacedName(name)
+	if err != nil {
+		return nil, err
+	}
+	if n.Namespace != "" {
+		return n, nil
+	}
+
+	namespace, err := currentNamespace()
+	if err != nil {
+		log.Debug("Failed to get current k8s namespace!")
+		return nil, err
+	}
+	log.Debugf("Found k8s namespace %s", namespace)
+
+	n.Namespace = namespace
+
+	return n, nil
+}
+
+// ParseNamespacedName constructs a NamespacedName from the provided name by
+// splitting it with the default spearator, as in namespace/name, or with the
+// at sign, as in name@namespace. The namespace is left empty if the name
+// contains neither.
+func ParseNamespacedName(name string) (*types.NamespacedName, error) {
 	policyParts := strings.SplitN(name, string(types.Separator), 2)
 	if len(policyParts) == 2 {
 		return &types.NamespacedName{
@@ -36,29 +81,30 @@ func NamespacedName(name string) (*types.NamespacedName, error) {
 		}, nil
 	}
 
-	namespace, err := currentNamespace()
-	if err != nil {
-		log.Debug("Failed to get current k8s namespace!")
-		return nil, err
+	if n, namespace, ok := strings.Cut(name, "@"); ok {
+		if n == "" || namespace == "" {
+			return nil, fmt.Errorf("invalid reference %q, expecting name@namespace", name)
+		}
+		return &types.NamespacedName{
+			Namespace: namespace,
+			Name:      n,
+		}, nil
 	}
-	log.Debugf("Found k8s namespace %s", namespace)
 
-	return &types.NamespacedName{
-		Name:      name,
-		Namespace: namespace,
-	}, nil
+	return &types.NamespacedName{Name: name}, nil
 }
 
 // used in tests to provide an override simulating in-cluster configuration
 var overrides = clientcmd.ConfigOverrides{}
 
-// currentNamespace returns the namespace of the current context if one is set.
-func currentNamespace() (string, error) {
-	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
-
-	clientCfg := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, &overrides)
+// currentNamespace returns the namespace given by the configuration, or the
+// namespace of the context if one is set.
+func (c Config) currentNamespace() (string, error) {
+	if c.Namespace != "" {
+		return c.Namespace, nil
+	}
 
-	if namespace, _, err := clientCfg.Namespace(); err != nil {
+	if namespace, _, err := c.clientConfig().Namespace(); err != nil {
 		return "", err
 	} else {
 		return namespace, nil
@@ -46,6 +46,19 @@ func Test_NamespacedName(t *testing.T) {
 				Namespace: "namespace",
 			},
 		},
+		{
+			test: "with namespace after at sign",
+			name: "name@namespace",
+			expected: &types.NamespacedName{
+				Name:      "name",
+				Namespace: "namespace",
+			},
+		},
+		{
+			test: "with empty namespace after at sign",
+			name: "name@",
+			err:  `invalid reference "name@", expecting name@namespace`,
+		},
 		{
 			test: "without namespace, with .kube/config",
 			name: "name",
@@ -121,3 +134,50 @@ current-context: test-context`,
 		})
 	}
 }
+
+func Test_NamespacedNameWithConfig(t *testing.T) {
+	kubeconfig := path.Join(t.TempDir(), "kubeconfig")
+	assert.NoError(t, os.WriteFile(kubeconfig, []byte(`apiVersion: v1
+kind: Config
+clusters:
+- cluster:
+    server: https://api.test
+  name: test-cluster
+contexts:
+- context:
+    cluster: test-cluster
+    namespace: test
+  name: test-context
+- context:
+    cluster: test-cluster
+    namespace: ci
+  name: ci-context
+current-context: test-context`), 0400))
+
+	t.Setenv("KUBECONFIG", "/non/existent/path")
+	overrides = clientcmd.ConfigOverrides{}
+
+	cases := []struct {
+		test     string
+		config   Config
+		expected string
+	}{
+		{test: "kubeconfig", config: Config{Kubeconfig: kubeconfig}, expected: "test"},
+		{test: "context", config: Config{Kubeconfig: kubeconfig, Context: "ci-context"}, expected: "ci"},
+		{test: "namespace", config: Config{Kubeconfig: kubeconfig, Context: "ci-context", Namespace: "override"}, expected: "override"},
+		{test: "namespace without kubeconfig", config: Config{Namespace: "override"}, expected: "override"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.test, func(t *testing.T) {
+			n, err := c.config.namespacedName("name")
+			assert.NoError(t, err)
+			assert.Equal(t, &types.NamespacedName{Name: "name", Namespace: c.expected}, n)
+
+			// references with a namespace are not affected
+			n, err = c.config.namespacedName("other/name")
+			assert.NoError(t, err)
+			assert.Equal(t, &types.NamespacedName{Name: "name", Namespace: "other"}, n)
+		})
+	}
+}
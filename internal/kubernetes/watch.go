@@ -0,0 +1,119 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package kubernetes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	ecc "github.com/enterprise-contract/enterprise-contract-controller/api/v1alpha1"
+	log "github.com/sirupsen/logrus"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/tools/cache"
+)
+
+// PolicyCallback is invoked with the EnterpriseContractPolicy each time it is
+// created or changed, and with nil when it is deleted
+type PolicyCallback func(policy *ecc.EnterpriseContractPolicy)
+
+// policyWatcher is implemented by clients able to watch the
+// EnterpriseContractPolicy resources
+type policyWatcher interface {
+	watchEnterpriseContractPolicy(ctx context.Context, ref string, callback PolicyCallback) error
+}
+
+// WatchEnterpriseContractPolicy watches the EnterpriseContractPolicy with the
+// given reference, in the format [<namespace>/]<name>, invoking the callback
+// with the current policy, if it exists, and then each time it changes, until
+// the context is done. It returns once the current policy has been read from
+// the cluster. The callbacks are invoked sequentially from a separate
+// goroutine, so the long running callbacks delay the following ones.
+func WatchEnterpriseContractPolicy(ctx context.Context, ref string, callback PolicyCallback) error {
+	if len(ref) == 0 {
+		return errors.New("policy reference cannot be empty")
+	}
+
+	k8s, err := NewClient(ctx)
+	if err != nil {
+		return fmt.Errorf("cannot initialize Kubernetes client: %w", err)
+	}
+
+	w, ok := k8s.(policyWatcher)
+	if !ok {
+		return errors.New("the Kubernetes client does not support watching policies")
+	}
+
+	return w.watchEnterpriseContractPolicy(ctx, ref, callback)
+}
+
+func (k *kubernetesClient) watchEnterpriseContractPolicy(ctx context.Context, ref string, callback PolicyCallback) error {
+	name, err := k.namespacedName(ref)
+	if err != nil {
+		return err
+	}
+	if name.Namespace == "" {
+		return errors.New("unable to determine namespace for policy")
+	}
+
+	// only the single policy is listed and watched
+	factory := dynamicinformer.NewFilteredDynamicSharedInformerFactory(k.client, 0, name.Namespace, func(o *v1.ListOptions) {
+		o.FieldSelector = fields.OneTermEqualSelector("metadata.name", name.Name).String()
+	})
+	informer := factory.ForResource(ecc.GroupVersion.WithResource("enterprisecontractpolicies")).Informer()
+
+	notify := func(obj any) {
+		u, ok := obj.(*unstructured.Unstructured)
+		if !ok || u.GetName() != name.Name {
+			return
+		}
+
+		policy := ecc.EnterpriseContractPolicy{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.UnstructuredContent(), &policy); err != nil {
+			log.Warnf("Unable to convert the EnterpriseContractPolicy %s: %v", name, err)
+			return
+		}
+
+		log.Debugf("EnterpriseContractPolicy %s changed, resource version %s", name, policy.ResourceVersion)
+		callback(&policy)
+	}
+
+	if _, err := informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: notify,
+		UpdateFunc: func(_, obj any) {
+			notify(obj)
+		},
+		DeleteFunc: func(any) {
+			log.Debugf("EnterpriseContractPolicy %s deleted", name)
+			callback(nil)
+		},
+	}); err != nil {
+		return err
+	}
+
+	log.Debugf("Watching EnterpriseContractPolicy %s", name)
+	factory.Start(ctx.Done())
+	if !cache.WaitForCacheSync(ctx.Done(), informer.HasSynced) {
+		return fmt.Errorf("unable to watch EnterpriseContractPolicy %s: %w", name, context.Cause(ctx))
+	}
+
+	return nil
+}
@@ -0,0 +1,114 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package kubernetes
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	ecc "github.com/enterprise-contract/enterprise-contract-controller/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic/fake"
+)
+
+func TestWatchEnterpriseContractPolicy(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, ecc.AddToScheme(scheme))
+
+	other := testECP.DeepCopy()
+	other.Name = "other-policy"
+	dyn := fake.NewSimpleDynamicClient(scheme, testECP.DeepCopy(), other)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	ctx = WithClient(ctx, NewClientWith(dyn))
+
+	policies := make(chan *ecc.EnterpriseContractPolicy, 10)
+	err := WatchEnterpriseContractPolicy(ctx, "test/ec-policy", func(p *ecc.EnterpriseContractPolicy) {
+		policies <- p
+	})
+	require.NoError(t, err)
+
+	next := func() *ecc.EnterpriseContractPolicy {
+		select {
+		case p := <-policies:
+			return p
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for the policy")
+			return nil
+		}
+	}
+
+	// the current policy
+	p := next()
+	require.NotNil(t, p)
+	assert.Equal(t, testECP.Spec, p.Spec)
+
+	resource := dyn.Resource(ecc.GroupVersion.WithResource("enterprisecontractpolicies")).Namespace("test")
+
+	// changes to other policies are not reported
+	changedOther := other.DeepCopy()
+	changedOther.Spec.Description = "other"
+	u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(changedOther)
+	require.NoError(t, err)
+	_, err = resource.Update(ctx, &unstructured.Unstructured{Object: u}, v1.UpdateOptions{})
+	require.NoError(t, err)
+
+	changed := testECP.DeepCopy()
+	changed.Spec.Description = "changed"
+	u, err = runtime.DefaultUnstructuredConverter.ToUnstructured(changed)
+	require.NoError(t, err)
+	_, err = resource.Update(ctx, &unstructured.Unstructured{Object: u}, v1.UpdateOptions{})
+	require.NoError(t, err)
+
+	p = next()
+	require.NotNil(t, p)
+	assert.Equal(t, "changed", p.Spec.Description)
+
+	require.NoError(t, resource.Delete(ctx, "ec-policy", v1.DeleteOptions{}))
+	assert.Nil(t, next())
+}
+
+type notWatchingClient struct {
+	Client
+}
+
+func TestWatchEnterpriseContractPolicyNotSupported(t *testing.T) {
+	ctx := WithClient(context.Background(), notWatchingClient{})
+
+	err := WatchEnterpriseContractPolicy(ctx, "test/ec-policy", func(*ecc.EnterpriseContractPolicy) {})
+	assert.EqualError(t, err, "the Kubernetes client does not support watching policies")
+
+	err = WatchEnterpriseContractPolicy(ctx, "", func(*ecc.EnterpriseContractPolicy) {})
+	assert.EqualError(t, err, "policy reference cannot be empty")
+}
+
+func TestWatchEnterpriseContractPolicyCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	ctx = WithClient(ctx, NewClientWith(fakeClient))
+
+	err := WatchEnterpriseContractPolicy(ctx, "test/ec-policy", func(*ecc.EnterpriseContractPolicy) {})
+	assert.EqualError(t, err, "unable to watch EnterpriseContractPolicy test/ec-policy: context canceled")
+}
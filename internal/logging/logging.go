@@ -28,6 +28,26 @@ import (
 	"k8s.io/klog/v2"
 )
 
+// Formats of the log output
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// Fields set consistently on the log entries, so the entries can be filtered
+// and aggregated when logging in the JSON format
+const (
+	// ComponentField is the name of the component being validated
+	ComponentField = "component"
+	// ImageField is the reference of the image being validated
+	ImageField = "image"
+	// PolicySourceField is the URL of the policy, data or configuration
+	// source, with any credentials redacted
+	PolicySourceField = "policy_source"
+	// DurationField is the duration of the operation in seconds
+	DurationField = "duration"
+)
+
 // There are seven log levels supported by logrus but let's not
 // expose all that to the user. Instead let's say we have the
 // following effective modes of logging: "debug", "verbose",
@@ -43,17 +63,32 @@ import (
 // We're expecting only one of the bool params to be set, but if
 // there are multiple set we'll accept it and the more verbose
 // option will take precedence.
-func InitLogging(verbose, quiet, debug, trace bool, logfile string) {
+//
+// The format is either FormatText, the default, or FormatJSON, the latter
+// logging each entry as a JSON object with the fields of the entry as its
+// attributes.
+func InitLogging(verbose, quiet, debug, trace bool, logfile, format string) error {
+	var formatter log.Formatter
+	switch format {
+	case "", FormatText:
+		formatter = &log.TextFormatter{}
+	case FormatJSON:
+		formatter = &log.JSONFormatter{}
+	default:
+		return fmt.Errorf("invalid log format %q, expected one of: %s, %s", format, FormatText, FormatJSON)
+	}
+	log.SetFormatter(formatter)
+
 	var level log.Level
 	var v string
 	switch {
 	case trace:
 		level = log.TraceLevel
-		setupDebugMode()
+		setupDebugMode(formatter)
 		v = "9"
 	case debug:
 		level = log.DebugLevel
-		setupDebugMode()
+		setupDebugMode(formatter)
 		v = "6"
 	case verbose:
 		level = log.DebugLevel
@@ -89,30 +124,36 @@ func InitLogging(verbose, quiet, debug, trace bool, logfile string) {
 			fmt.Fprintf(os.Stderr, "Unable to create log file %q, log lines will appear on standard error. Error was: %s\n", logfile, err.Error())
 		}
 	}
+
+	return nil
 }
 
-func setupDebugMode() {
+func setupDebugMode(formatter log.Formatter) {
 	// Show the file, line number and function name when logging
 	log.SetReportCaller(true)
 
 	// Tweak the output since the defaults are not good
-	customTextFormatter := &log.TextFormatter{
-		CallerPrettyfier: func(f *runtime.Frame) (string, string) {
-			// The full path is way too long. Extract just the file name.
-			shortFile := filepath.Base(f.File)
+	prettyfier := func(f *runtime.Frame) (string, string) {
+		// The full path is way too long. Extract just the file name.
+		shortFile := filepath.Base(f.File)
 
-			// The function name includes the full package which is also way too long.
-			// Extract just the function name by itself.
-			// (We're abusing filepath.Ext here but I think we can get away with it)
-			shortFunction := filepath.Ext(f.Function)[1:]
+		// The function name includes the full package which is also way too long.
+		// Extract just the function name by itself.
+		// (We're abusing filepath.Ext here but I think we can get away with it)
+		shortFunction := filepath.Ext(f.Function)[1:]
 
-			// Include the line number as well
-			shortFileandLineNumber := fmt.Sprintf(" %s:%d", shortFile, f.Line)
+		// Include the line number as well
+		shortFileandLineNumber := fmt.Sprintf(" %s:%d", shortFile, f.Line)
+
+		return shortFunction, shortFileandLineNumber
+	}
 
-			return shortFunction, shortFileandLineNumber
-		},
+	switch f := formatter.(type) {
+	case *log.TextFormatter:
+		f.CallerPrettyfier = prettyfier
+	case *log.JSONFormatter:
+		f.CallerPrettyfier = prettyfier
 	}
-	log.SetFormatter(customTextFormatter)
 }
 
 // logrusSink implements logr.LogSink to pass klog messages to logrus
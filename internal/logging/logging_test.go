@@ -19,11 +19,14 @@
 package logging
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"testing"
 
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestEntry(t *testing.T) {
@@ -55,3 +58,56 @@ func TestToLevel(t *testing.T) {
 		})
 	}
 }
+
+func TestInitLoggingFormat(t *testing.T) {
+	logger := logrus.StandardLogger()
+	out, formatter, level, reportCaller := logger.Out, logger.Formatter, logger.Level, logger.ReportCaller
+	t.Cleanup(func() {
+		logger.SetOutput(out)
+		logger.SetFormatter(formatter)
+		logger.SetLevel(level)
+		logger.SetReportCaller(reportCaller)
+	})
+
+	t.Run("json", func(t *testing.T) {
+		require.NoError(t, InitLogging(false, false, false, false, "", FormatJSON))
+		buffy := bytes.Buffer{}
+		logger.SetOutput(&buffy)
+
+		logrus.WithFields(logrus.Fields{
+			ComponentField: "spam",
+			ImageField:     "registry.io/spam:latest",
+			DurationField:  1.5,
+		}).Warn("Validated component")
+
+		var entry map[string]any
+		require.NoError(t, json.Unmarshal(buffy.Bytes(), &entry))
+		assert.Equal(t, "warning", entry["level"])
+		assert.Equal(t, "Validated component", entry["msg"])
+		assert.Equal(t, "spam", entry[ComponentField])
+		assert.Equal(t, "registry.io/spam:latest", entry[ImageField])
+		assert.Equal(t, 1.5, entry[DurationField])
+	})
+
+	t.Run("json with debug", func(t *testing.T) {
+		require.NoError(t, InitLogging(false, false, true, false, "", FormatJSON))
+		buffy := bytes.Buffer{}
+		logger.SetOutput(&buffy)
+
+		logrus.Debug("debugging")
+
+		var entry map[string]any
+		require.NoError(t, json.Unmarshal(buffy.Bytes(), &entry))
+		assert.Regexp(t, `^func\d+$`, entry["func"])
+		assert.Regexp(t, `^ logging_test.go:\d+$`, entry["file"])
+	})
+
+	t.Run("text", func(t *testing.T) {
+		require.NoError(t, InitLogging(false, false, false, false, "", FormatText))
+		assert.IsType(t, &logrus.TextFormatter{}, logger.Formatter)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		assert.EqualError(t, InitLogging(false, false, false, false, "", "xml"), `invalid log format "xml", expected one of: text, json`)
+	})
+}
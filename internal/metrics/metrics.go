@@ -0,0 +1,142 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package metrics holds the Prometheus metrics exposed by the server modes.
+// The metrics are recorded regardless of the mode, they are only exposed when
+// serving.
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "ec"
+
+// Outcomes of validations
+const (
+	ResultSuccess = "success"
+	ResultFailure = "failure"
+	ResultError   = "error"
+)
+
+// Kinds of signatures verified
+const (
+	SignatureImage       = "image"
+	SignatureAttestation = "attestation"
+)
+
+var (
+	registry = prometheus.NewRegistry()
+
+	validations = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "validations_total",
+		Help:      "Number of images validated by result, one of success, failure or error.",
+	}, []string{"result"})
+
+	validationDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "validation_duration_seconds",
+		Help:      "Duration of the validation of an image by result, one of success, failure or error.",
+		Buckets:   []float64{.1, .25, .5, 1, 2.5, 5, 10, 30, 60, 120},
+	}, []string{"result"})
+
+	downloadDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "source_download_duration_seconds",
+		Help:      "Duration of the download of a policy, data or configuration source by source and result, one of success or failure.",
+		Buckets:   []float64{.1, .25, .5, 1, 2.5, 5, 10, 30, 60},
+	}, []string{"source", "result"})
+
+	signatureVerificationDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "signature_verification_duration_seconds",
+		Help:      "Duration of the verification of the signatures of an image by kind, one of image or attestation, and result, one of success or failure.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"kind", "result"})
+
+	policyEvaluationDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "policy_evaluation_duration_seconds",
+		Help:      "Duration of the evaluation of the policy rules of a source group against an input.",
+		Buckets:   prometheus.DefBuckets,
+	})
+)
+
+func init() {
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		validations,
+		validationDuration,
+		downloadDuration,
+		signatureVerificationDuration,
+		policyEvaluationDuration,
+	)
+}
+
+// Handler returns the handler serving the metrics in the Prometheus
+// exposition format
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{Registry: registry})
+}
+
+// ObserveValidation records the validation of an image with the result, one
+// of ResultSuccess, ResultFailure or ResultError, taking the given duration
+func ObserveValidation(result string, duration time.Duration) {
+	validations.WithLabelValues(result).Inc()
+	validationDuration.WithLabelValues(result).Observe(duration.Seconds())
+}
+
+// ObserveSignatureVerification records the verification of the signatures of
+// the kind, SignatureImage or SignatureAttestation, taking the given duration
+func ObserveSignatureVerification(kind string, err error, duration time.Duration) {
+	signatureVerificationDuration.WithLabelValues(kind, result(err)).Observe(duration.Seconds())
+}
+
+// ObservePolicyEvaluation records the evaluation of the policy rules taking
+// the given duration
+func ObservePolicyEvaluation(duration time.Duration) {
+	policyEvaluationDuration.Observe(duration.Seconds())
+}
+
+func result(err error) string {
+	if err != nil {
+		return ResultFailure
+	}
+	return ResultSuccess
+}
+
+// DownloadEvents records the duration of the downloads of the sources, it
+// implements downloader.Events
+type DownloadEvents struct{}
+
+func (DownloadEvents) Started(string, string) {}
+
+func (DownloadEvents) Transferred(string, int64) {}
+
+func (DownloadEvents) Completed(source string, _ int64, duration time.Duration) {
+	downloadDuration.WithLabelValues(source, ResultSuccess).Observe(duration.Seconds())
+}
+
+func (DownloadEvents) Failed(source string, _ error, duration time.Duration) {
+	downloadDuration.WithLabelValues(source, ResultFailure).Observe(duration.Seconds())
+}
@@ -0,0 +1,65 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package metrics
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestObserveValidation(t *testing.T) {
+	before := testutil.ToFloat64(validations.WithLabelValues(ResultFailure))
+
+	ObserveValidation(ResultFailure, time.Second)
+
+	assert.Equal(t, before+1, testutil.ToFloat64(validations.WithLabelValues(ResultFailure)))
+}
+
+func TestHandler(t *testing.T) {
+	ObserveValidation(ResultSuccess, time.Second)
+	ObserveSignatureVerification(SignatureImage, nil, time.Second)
+	ObserveSignatureVerification(SignatureAttestation, errors.New("boom"), time.Second)
+	ObservePolicyEvaluation(time.Second)
+	DownloadEvents{}.Completed("https://example.com/org/repo.git", 10, time.Second)
+	DownloadEvents{}.Failed("https://example.com/org/other.git", errors.New("boom"), time.Second)
+
+	rec := httptest.NewRecorder()
+	Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	body := rec.Body.String()
+	for _, expected := range []string{
+		`ec_validations_total{result="success"}`,
+		`ec_validation_duration_seconds_count{result="success"}`,
+		`ec_signature_verification_duration_seconds_count{kind="image",result="success"} 1`,
+		`ec_signature_verification_duration_seconds_count{kind="attestation",result="failure"} 1`,
+		`ec_policy_evaluation_duration_seconds_count 1`,
+		`ec_source_download_duration_seconds_count{result="success",source="https://example.com/org/repo.git"} 1`,
+		`ec_source_download_duration_seconds_count{result="failure",source="https://example.com/org/other.git"} 1`,
+		`go_goroutines`,
+	} {
+		assert.Contains(t, body, expected)
+	}
+}
@@ -58,10 +58,18 @@ func effectiveOn(a *ast.AnnotationsRef) string {
 	return customAnnotationString(a, "effective_on")
 }
 
+func effectiveTime(a *ast.AnnotationsRef) string {
+	return customAnnotationString(a, "effective_time")
+}
+
 func solution(a *ast.AnnotationsRef) string {
 	return xrefRegExp.ReplaceAllString(customAnnotationString(a, "solution"), "$1")
 }
 
+func severity(a *ast.AnnotationsRef) string {
+	return strings.ToLower(customAnnotationString(a, "severity"))
+}
+
 func lastTerm(a *ast.AnnotationsRef) string {
 	if a == nil || len(a.Path) == 0 {
 		return ""
@@ -78,6 +86,8 @@ func kind(a *ast.AnnotationsRef) RuleKind {
 		return Deny
 	case "warn":
 		return Warn
+	case "warn_review":
+		return Review
 	default:
 		return Other
 	}
@@ -249,9 +259,12 @@ func dependsOn(a *ast.AnnotationsRef) []string {
 type RuleKind string
 
 const (
-	Deny  RuleKind = "deny"
-	Warn  RuleKind = "warn"
-	Other RuleKind = "other"
+	Deny RuleKind = "deny"
+	Warn RuleKind = "warn"
+	// Review rules are defined as warn_review rules, conftest evaluates them
+	// as warnings, their results are reported as requiring review
+	Review RuleKind = "review"
+	Other  RuleKind = "other"
 )
 
 type Info struct {
@@ -262,8 +275,10 @@ type Info struct {
 	Description      string
 	DocumentationUrl string
 	EffectiveOn      string
+	EffectiveTime    string
 	Kind             RuleKind
 	Package          string
+	Severity         string
 	ShortName        string
 	Solution         string
 	Title            string
@@ -278,9 +293,11 @@ func RuleInfo(a *ast.AnnotationsRef) Info {
 		DependsOn:        dependsOn(a),
 		DocumentationUrl: documentationUrl(a),
 		EffectiveOn:      effectiveOn(a),
+		EffectiveTime:    effectiveTime(a),
 		Solution:         solution(a),
 		Kind:             kind(a),
 		Package:          packageName(a),
+		Severity:         severity(a),
 		ShortName:        shortName(a),
 		Title:            title(a),
 	}
@@ -181,6 +181,15 @@ func TestKind(t *testing.T) {
 				warn() { true }`)),
 			expected: Warn,
 		},
+		{
+			name: "review rule",
+			annotation: annotationRef(heredoc.Doc(`
+				package a
+				# METADATA
+				# title: test
+				warn_review() { true }`)),
+			expected: Review,
+		},
 	}
 
 	for i, c := range cases {
@@ -302,6 +311,16 @@ func TestEffectiveOn(t *testing.T) {
 	}
 }
 
+func TestEffectiveTime(t *testing.T) {
+	// edge cases are covered in TestEffectiveOn above
+	assert.Equal(t, "2022-01-01T00:00:00Z", effectiveTime(annotationRef(heredoc.Doc(`
+		package a
+		# METADATA
+		# custom:
+		#   effective_time: '2022-01-01T00:00:00Z'
+		deny() { true }`))))
+}
+
 func TestSolution(t *testing.T) {
 	cases := []struct {
 		name       string
@@ -341,6 +360,40 @@ func TestSolution(t *testing.T) {
 	}
 }
 
+func TestSeverity(t *testing.T) {
+	cases := []struct {
+		name       string
+		annotation *ast.AnnotationsRef
+		expected   string
+	}{
+		{
+			name: "with severity",
+			annotation: annotationRef(heredoc.Doc(`
+				package a
+				# METADATA
+				# custom:
+				#   severity: High
+				deny() { true }`)),
+			expected: "high",
+		},
+		{
+			name: "without severity",
+			annotation: annotationRef(heredoc.Doc(`
+				package a
+				# METADATA
+				# title: Chunky bacon
+				deny() { true }`)),
+			expected: "",
+		},
+	}
+
+	for i, c := range cases {
+		t.Run(fmt.Sprintf("[%d] - %s", i, c.name), func(t *testing.T) {
+			assert.Equal(t, c.expected, severity(c.annotation))
+		})
+	}
+}
+
 func TestCollections(t *testing.T) {
 	cases := []struct {
 		name       string
@@ -79,11 +79,16 @@ type Output struct {
 	ImageSignatureCheck       VerificationStatus          `json:"imageSignatureCheck"`
 	AttestationSignatureCheck VerificationStatus          `json:"attestationSignatureCheck"`
 	AttestationSyntaxCheck    VerificationStatus          `json:"attestationSyntaxCheck"`
+	BaseImageRegistryCheck    *VerificationStatus         `json:"baseImageRegistryCheck,omitempty"`
+	AttestationAgeCheck       *VerificationStatus         `json:"attestationAgeCheck,omitempty"`
+	RequiredAttestationsCheck *VerificationStatus         `json:"requiredAttestationsCheck,omitempty"`
 	PolicyCheck               []evaluator.Outcome         `json:"policyCheck"`
 	ExitCode                  int                         `json:"-"`
 	Signatures                []signature.EntitySignature `json:"signatures,omitempty"`
 	Attestations              []attestation.Attestation   `json:"attestations,omitempty"`
 	ImageURL                  string                      `json:"-"`
+	Platform                  string                      `json:"-"`
+	ResolvedFrom              string                      `json:"-"`
 	Detailed                  bool                        `json:"-"`
 	Data                      []evaluator.Data            `json:"-"`
 	Policy                    policy.Policy               `json:"-"`
@@ -189,6 +194,91 @@ func (o *Output) SetAttestationSyntaxCheckFromError(err error) {
 	o.AttestationSyntaxCheck.Result = result
 }
 
+// SetBaseImageRegistryCheckFromError sets the passed and result.message fields of the
+// BaseImageRegistryCheck to the given values.
+func (o *Output) SetBaseImageRegistryCheckFromError(err error) {
+	metadata := map[string]interface{}{
+		"code":        "builtin.image.base_image_registry",
+		"title":       "Base image registry check passed",
+		"description": "The base images recorded in the provenance come from allowed registries.",
+	}
+	var message string
+
+	check := &VerificationStatus{}
+	if err == nil {
+		check.Passed = true
+		message = "Pass"
+		log.Debug("Base image registry check passed")
+	} else {
+		check.Passed = false
+		message = fmt.Sprintf("Base image registry check failed: %s", err)
+		log.Debug(message)
+	}
+	result := &evaluator.Result{Message: message, Metadata: metadata}
+	if !o.Detailed {
+		keepSomeMetadataSingle(*result)
+	}
+	check.Result = result
+	o.BaseImageRegistryCheck = check
+}
+
+// SetAttestationAgeCheckFromError sets the passed and result.message fields of the
+// AttestationAgeCheck to the given values.
+func (o *Output) SetAttestationAgeCheckFromError(err error) {
+	metadata := map[string]interface{}{
+		"code":        "builtin.attestation.age_check",
+		"title":       "Attestation age check passed",
+		"description": "The build recorded in the provenance is not older than the maximum allowed age.",
+	}
+	var message string
+
+	check := &VerificationStatus{}
+	if err == nil {
+		check.Passed = true
+		message = "Pass"
+		log.Debug("Attestation age check passed")
+	} else {
+		check.Passed = false
+		message = fmt.Sprintf("Attestation age check failed: %s", err)
+		log.Debug(message)
+	}
+	result := &evaluator.Result{Message: message, Metadata: metadata}
+	if !o.Detailed {
+		keepSomeMetadataSingle(*result)
+	}
+	check.Result = result
+	o.AttestationAgeCheck = check
+}
+
+// SetRequiredAttestationsCheckFromError sets the passed and result.message
+// fields of the RequiredAttestationsCheck to the given values. The signers
+// describes the signers that were counted towards the required attestations.
+func (o *Output) SetRequiredAttestationsCheckFromError(signers string, err error) {
+	metadata := map[string]interface{}{
+		"code":        "builtin.attestation.required_signers",
+		"title":       "Required attestations check passed",
+		"description": "The attestations of the required predicate types are signed by enough distinct signers.",
+	}
+	var message string
+
+	check := &VerificationStatus{}
+	if err == nil {
+		check.Passed = true
+		message = fmt.Sprintf("Pass: %s", signers)
+		log.Debug("Required attestations check passed")
+	} else {
+		check.Passed = false
+		message = fmt.Sprintf("Required attestations check failed: %s", err)
+		log.Debug(message)
+	}
+	result := &evaluator.Result{Message: message, Metadata: metadata}
+	if !o.Detailed {
+		keepSomeMetadataSingle(*result)
+	}
+	check.Result = result
+	o.RequiredAttestationsCheck = check
+}
+
 // SetPolicyCheck sets the PolicyCheck and ExitCode to the results and exit code of the Results
 func (o *Output) SetPolicyCheck(results []evaluator.Outcome) {
 	for r := range results {
@@ -202,6 +292,7 @@ func (o *Output) SetPolicyCheck(results []evaluator.Outcome) {
 			keepSomeMetadata(results[r].Successes)
 			keepSomeMetadata(results[r].Skipped)
 			keepSomeMetadata(results[r].Warnings)
+			keepSomeMetadata(results[r].Reviews)
 		}
 
 		if len(results[r].Failures) > 0 {
@@ -242,6 +333,15 @@ func (o Output) Violations() []evaluator.Result {
 	violations = o.ImageAccessibleCheck.addToViolations(violations)
 	violations = o.AttestationSignatureCheck.addToViolations(violations)
 	violations = o.AttestationSyntaxCheck.addToViolations(violations)
+	if o.BaseImageRegistryCheck != nil {
+		violations = o.BaseImageRegistryCheck.addToViolations(violations)
+	}
+	if o.AttestationAgeCheck != nil {
+		violations = o.AttestationAgeCheck.addToViolations(violations)
+	}
+	if o.RequiredAttestationsCheck != nil {
+		violations = o.RequiredAttestationsCheck.addToViolations(violations)
+	}
 	violations = o.addCheckResultsToViolations(violations)
 
 	violations = sortResults(violations)
@@ -259,6 +359,17 @@ func (o Output) Warnings() []evaluator.Result {
 	return warnings
 }
 
+// Reviews aggregates and returns all results requiring review.
+func (o Output) Reviews() []evaluator.Result {
+	reviews := make([]evaluator.Result, 0, 10)
+	for _, result := range o.PolicyCheck {
+		reviews = append(reviews, result.Reviews...)
+	}
+
+	reviews = sortResults(reviews)
+	return reviews
+}
+
 // Successes aggregates and returns all successes.
 func (o Output) Successes() []evaluator.Result {
 	successes := make([]evaluator.Result, 0, 10)
@@ -269,6 +380,15 @@ func (o Output) Successes() []evaluator.Result {
 	successes = o.ImageSignatureCheck.addToSuccesses(successes)
 	successes = o.AttestationSignatureCheck.addToSuccesses(successes)
 	successes = o.AttestationSyntaxCheck.addToSuccesses(successes)
+	if o.BaseImageRegistryCheck != nil {
+		successes = o.BaseImageRegistryCheck.addToSuccesses(successes)
+	}
+	if o.AttestationAgeCheck != nil {
+		successes = o.AttestationAgeCheck.addToSuccesses(successes)
+	}
+	if o.RequiredAttestationsCheck != nil {
+		successes = o.RequiredAttestationsCheck.addToSuccesses(successes)
+	}
 
 	successes = sortResults(successes)
 	return successes
@@ -786,6 +786,53 @@ func Test_Warnings(t *testing.T) {
 	}
 }
 
+func Test_Reviews(t *testing.T) {
+	cases := []struct {
+		name     string
+		output   Output
+		expected []evaluator.Result
+	}{
+		{
+			name:     "no-reviews",
+			output:   Output{},
+			expected: []evaluator.Result{},
+		},
+		{
+			name: "mixed results",
+			output: Output{
+				PolicyCheck: []evaluator.Outcome{
+					{
+						Warnings: []evaluator.Result{
+							{Message: "warning for policy check 1"},
+						},
+						Reviews: []evaluator.Result{
+							{Message: "review for policy check 2"},
+						},
+					},
+					{
+						Failures: []evaluator.Result{
+							{Message: "failure for policy check 3"},
+						},
+						Reviews: []evaluator.Result{
+							{Message: "review for policy check 4"},
+						},
+					},
+				},
+			},
+			expected: []evaluator.Result{
+				{Message: "review for policy check 2"},
+				{Message: "review for policy check 4"},
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, c.expected, c.output.Reviews())
+		})
+	}
+}
+
 func TestSetImageAccessibleCheckFromError(t *testing.T) {
 	cases := []struct {
 		name           string
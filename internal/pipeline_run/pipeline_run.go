@@ -0,0 +1,365 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package pipeline_run converts Tekton PipelineRuns into input for the rego
+// policy evaluation.
+package pipeline_run
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline"
+	pipelinev1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	corev1 "k8s.io/api/core/v1"
+	"knative.dev/pkg/apis"
+	"sigs.k8s.io/yaml"
+
+	"github.com/enterprise-contract/ec-cli/internal/kubernetes"
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+// Input is the policy input for a PipelineRun
+type Input struct {
+	PipelineRun PipelineRun `json:"pipeline_run"`
+}
+
+// PipelineRun holds the information about a PipelineRun exposed to policy rules
+type PipelineRun struct {
+	Name           string                           `json:"name"`
+	Namespace      string                           `json:"namespace,omitempty"`
+	Labels         map[string]string                `json:"labels,omitempty"`
+	Annotations    map[string]string                `json:"annotations,omitempty"`
+	Pipeline       string                           `json:"pipeline,omitempty"`
+	Status         string                           `json:"status"`
+	Reason         string                           `json:"reason,omitempty"`
+	StartTime      *time.Time                       `json:"start_time,omitempty"`
+	CompletionTime *time.Time                       `json:"completion_time,omitempty"`
+	Params         map[string]pipelinev1.ParamValue `json:"params"`
+	Results        map[string]pipelinev1.ParamValue `json:"results"`
+	Tasks          []Task                           `json:"tasks"`
+	SkippedTasks   []string                         `json:"skipped_tasks"`
+}
+
+// Task is a task that ran as part of the PipelineRun, the reference to the
+// task, its status, parameters and results are known only when its TaskRun
+// is, the reference is otherwise taken from the resolved pipeline spec
+type Task struct {
+	Name    string                           `json:"name"`
+	Run     string                           `json:"run"`
+	Kind    string                           `json:"kind,omitempty"`
+	Ref     *TaskRef                         `json:"ref,omitempty"`
+	Status  string                           `json:"status,omitempty"`
+	Reason  string                           `json:"reason,omitempty"`
+	Params  map[string]pipelinev1.ParamValue `json:"params,omitempty"`
+	Results map[string]pipelinev1.ParamValue `json:"results,omitempty"`
+}
+
+// TaskRef is the reference to the Task that ran, for Tasks resolved from
+// bundles the bundle holds the image reference of the bundle
+type TaskRef struct {
+	Name     string            `json:"name,omitempty"`
+	Kind     string            `json:"kind,omitempty"`
+	Bundle   string            `json:"bundle,omitempty"`
+	Resolver string            `json:"resolver,omitempty"`
+	Params   map[string]string `json:"params,omitempty"`
+}
+
+// list is a Kubernetes List, as output by kubectl get -o yaml
+type list struct {
+	Kind  string            `json:"kind"`
+	Items []json.RawMessage `json:"items"`
+}
+
+// Load reads the PipelineRun from the file at the given path or, if no such
+// file exists, fetches it from the cluster using the given reference in the
+// format [<namespace>/]<name>. The file holds either the PipelineRun or a List
+// of the PipelineRun and its TaskRuns. The TaskRuns of the PipelineRun from
+// the cluster are fetched from the cluster, the ones that can't be fetched,
+// e.g. because they were pruned, are skipped.
+func Load(ctx context.Context, ref string) (*pipelinev1.PipelineRun, []pipelinev1.TaskRun, error) {
+	fs := utils.FS(ctx)
+
+	if exists, err := afero.Exists(fs, ref); err != nil {
+		return nil, nil, err
+	} else if exists {
+		log.Debugf("Reading pipeline run from file %s", ref)
+		b, err := afero.ReadFile(fs, ref)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return parse(ref, b)
+	}
+
+	log.Debugf("Fetching pipeline run %s from cluster", ref)
+	client, err := kubernetes.NewClient(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pr, err := client.FetchPipelineRun(ctx, ref)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	taskRuns := make([]pipelinev1.TaskRun, 0, len(pr.Status.ChildReferences))
+	for _, child := range pr.Status.ChildReferences {
+		if child.Kind != "TaskRun" {
+			continue
+		}
+
+		tr, err := client.FetchTaskRun(ctx, pr.Namespace+"/"+child.Name)
+		if err != nil {
+			log.Warnf("Unable to fetch the task run %s of the pipeline run %s: %v", child.Name, ref, err)
+			continue
+		}
+		taskRuns = append(taskRuns, *tr)
+	}
+
+	return pr, taskRuns, nil
+}
+
+// parse parses the PipelineRun, or the List of the PipelineRun and its
+// TaskRuns
+func parse(file string, b []byte) (*pipelinev1.PipelineRun, []pipelinev1.TaskRun, error) {
+	l := list{}
+	if err := yaml.Unmarshal(b, &l); err != nil {
+		return nil, nil, fmt.Errorf("unable to parse PipelineRun from %s: %w", file, err)
+	}
+
+	if l.Kind != "List" {
+		pr := pipelinev1.PipelineRun{}
+		if err := yaml.Unmarshal(b, &pr); err != nil {
+			return nil, nil, fmt.Errorf("unable to parse PipelineRun from %s: %w", file, err)
+		}
+
+		return &pr, nil, nil
+	}
+
+	var pr *pipelinev1.PipelineRun
+	var taskRuns []pipelinev1.TaskRun
+	for _, item := range l.Items {
+		meta := struct {
+			Kind string `json:"kind"`
+		}{}
+		if err := json.Unmarshal(item, &meta); err != nil {
+			return nil, nil, fmt.Errorf("unable to parse the List in %s: %w", file, err)
+		}
+
+		switch meta.Kind {
+		case "PipelineRun":
+			if pr != nil {
+				return nil, nil, fmt.Errorf("more than one PipelineRun found in %s", file)
+			}
+			pr = &pipelinev1.PipelineRun{}
+			if err := json.Unmarshal(item, pr); err != nil {
+				return nil, nil, fmt.Errorf("unable to parse PipelineRun from %s: %w", file, err)
+			}
+		case "TaskRun":
+			tr := pipelinev1.TaskRun{}
+			if err := json.Unmarshal(item, &tr); err != nil {
+				return nil, nil, fmt.Errorf("unable to parse TaskRun from %s: %w", file, err)
+			}
+			taskRuns = append(taskRuns, tr)
+		}
+	}
+
+	if pr == nil {
+		return nil, nil, fmt.Errorf("no PipelineRun found in %s", file)
+	}
+
+	return pr, taskRuns, nil
+}
+
+// NewInput converts the PipelineRun, and the TaskRuns it created, to the
+// policy input
+func NewInput(pr *pipelinev1.PipelineRun, taskRuns []pipelinev1.TaskRun) Input {
+	p := PipelineRun{
+		Name:         pr.Name,
+		Namespace:    pr.Namespace,
+		Labels:       pr.Labels,
+		Annotations:  pr.Annotations,
+		Status:       string(corev1.ConditionUnknown),
+		Params:       map[string]pipelinev1.ParamValue{},
+		Results:      map[string]pipelinev1.ParamValue{},
+		Tasks:        []Task{},
+		SkippedTasks: []string{},
+	}
+
+	if pr.Spec.PipelineRef != nil {
+		p.Pipeline = pr.Spec.PipelineRef.Name
+	}
+
+	if c := pr.Status.GetCondition(apis.ConditionSucceeded); c != nil {
+		p.Status = string(c.Status)
+		p.Reason = c.Reason
+	}
+
+	if pr.Status.StartTime != nil {
+		t := pr.Status.StartTime.UTC()
+		p.StartTime = &t
+	}
+
+	if pr.Status.CompletionTime != nil {
+		t := pr.Status.CompletionTime.UTC()
+		p.CompletionTime = &t
+	}
+
+	for _, param := range pr.Spec.Params {
+		p.Params[param.Name] = param.Value
+	}
+
+	for _, result := range pr.Status.Results {
+		p.Results[result.Name] = result.Value
+	}
+
+	pipelineTasks := map[string]pipelinev1.PipelineTask{}
+	if spec := pr.Status.PipelineSpec; spec != nil {
+		for _, t := range spec.Tasks {
+			pipelineTasks[t.Name] = t
+		}
+		for _, t := range spec.Finally {
+			pipelineTasks[t.Name] = t
+		}
+	}
+
+	runs := make(map[string]pipelinev1.TaskRun, len(taskRuns))
+	for _, tr := range taskRuns {
+		runs[tr.Name] = tr
+	}
+
+	for _, child := range pr.Status.ChildReferences {
+		task := Task{
+			Name: child.PipelineTaskName,
+			Run:  child.Name,
+			Kind: child.Kind,
+		}
+
+		if pt, ok := pipelineTasks[child.PipelineTaskName]; ok {
+			task.Ref = newTaskRef(pt.TaskRef)
+		}
+
+		if tr, ok := runs[child.Name]; ok {
+			addTaskRun(&task, tr)
+		}
+
+		p.Tasks = append(p.Tasks, task)
+	}
+
+	for _, skipped := range pr.Status.SkippedTasks {
+		p.SkippedTasks = append(p.SkippedTasks, skipped.Name)
+	}
+
+	return Input{PipelineRun: p}
+}
+
+// addTaskRun adds the information from the TaskRun to the task
+func addTaskRun(task *Task, tr pipelinev1.TaskRun) {
+	if tr.Spec.TaskRef != nil {
+		task.Ref = newTaskRef(tr.Spec.TaskRef)
+	}
+
+	// the name of a Task resolved by a resolver is known only once resolved
+	if name := tr.Labels[pipeline.TaskLabelKey]; name != "" {
+		if task.Ref == nil {
+			task.Ref = &TaskRef{}
+		}
+		if task.Ref.Name == "" {
+			task.Ref.Name = name
+		}
+	}
+
+	task.Status = string(corev1.ConditionUnknown)
+	if c := tr.Status.GetCondition(apis.ConditionSucceeded); c != nil {
+		task.Status = string(c.Status)
+		task.Reason = c.Reason
+	}
+
+	task.Params = map[string]pipelinev1.ParamValue{}
+	for _, param := range tr.Spec.Params {
+		task.Params[param.Name] = param.Value
+	}
+
+	task.Results = map[string]pipelinev1.ParamValue{}
+	for _, result := range tr.Status.Results {
+		task.Results[result.Name] = result.Value
+	}
+}
+
+func newTaskRef(ref *pipelinev1.TaskRef) *TaskRef {
+	if ref == nil {
+		return nil
+	}
+
+	r := TaskRef{
+		Name:     ref.Name,
+		Kind:     string(ref.Kind),
+		Resolver: string(ref.Resolver),
+	}
+
+	if len(ref.Params) > 0 {
+		r.Params = make(map[string]string, len(ref.Params))
+		for _, p := range ref.Params {
+			r.Params[p.Name] = p.Value.StringVal
+		}
+	}
+
+	if ref.Resolver == "bundles" {
+		r.Bundle = r.Params["bundle"]
+		if r.Name == "" {
+			r.Name = r.Params["name"]
+		}
+		if r.Kind == "" {
+			r.Kind = r.Params["kind"]
+		}
+	}
+
+	return &r
+}
+
+// WriteInputFile loads the PipelineRun using the given reference and writes
+// the policy input for it to a temporary file, returning the path of the file.
+func WriteInputFile(ctx context.Context, ref string) (string, error) {
+	pr, taskRuns, err := Load(ctx, ref)
+	if err != nil {
+		return "", err
+	}
+
+	b, err := json.Marshal(NewInput(pr, taskRuns))
+	if err != nil {
+		return "", err
+	}
+
+	fs := utils.FS(ctx)
+	f, err := afero.TempFile(fs, "", "pipeline-run-*.json")
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	if _, err := f.Write(b); err != nil {
+		return "", err
+	}
+
+	log.Debugf("Pipeline run input written to %s", f.Name())
+
+	return f.Name(), nil
+}
@@ -0,0 +1,282 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package pipeline_run
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/MakeNowJust/heredoc"
+	ecc "github.com/enterprise-contract/enterprise-contract-controller/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	pipelinev1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/enterprise-contract/ec-cli/internal/evaluator"
+	"github.com/enterprise-contract/ec-cli/internal/kubernetes"
+	"github.com/enterprise-contract/ec-cli/internal/policy"
+	"github.com/enterprise-contract/ec-cli/internal/policy/source"
+)
+
+func TestNewInput(t *testing.T) {
+	pr, taskRuns, err := Load(context.Background(), "testdata/pipeline_run.yaml")
+	require.NoError(t, err)
+	assert.Empty(t, taskRuns)
+
+	b, err := json.Marshal(NewInput(pr, taskRuns))
+	require.NoError(t, err)
+
+	assert.JSONEq(t, `{
+		"pipeline_run": {
+			"name": "build-1",
+			"namespace": "builds",
+			"labels": {"app": "bacon"},
+			"pipeline": "docker-build",
+			"status": "True",
+			"reason": "Succeeded",
+			"start_time": "2024-06-30T10:00:00Z",
+			"completion_time": "2024-06-30T10:10:00Z",
+			"params": {
+				"git-url": "https://git.localhost/bacon.git",
+				"build-args": ["A=1"]
+			},
+			"results": {
+				"IMAGE_DIGEST": "sha256:4e388ab32b10dc8dbc7e28144f552830adc74787c1e2c0824032078a79f227fb"
+			},
+			"tasks": [
+				{"name": "clone", "run": "build-1-clone", "kind": "TaskRun"},
+				{"name": "build", "run": "build-1-build", "kind": "TaskRun"}
+			],
+			"skipped_tasks": ["scan"]
+		}
+	}`, string(b))
+}
+
+func TestNewInputTaskRuns(t *testing.T) {
+	pr, taskRuns, err := Load(context.Background(), "testdata/pipeline_run_list.yaml")
+	require.NoError(t, err)
+	assert.Len(t, taskRuns, 2)
+
+	b, err := json.Marshal(NewInput(pr, taskRuns))
+	require.NoError(t, err)
+
+	assert.JSONEq(t, `{
+		"pipeline_run": {
+			"name": "build-1",
+			"namespace": "builds",
+			"pipeline": "docker-build",
+			"status": "True",
+			"reason": "Succeeded",
+			"params": {},
+			"results": {},
+			"tasks": [
+				{
+					"name": "clone",
+					"run": "build-1-clone",
+					"kind": "TaskRun",
+					"ref": {
+						"name": "git-clone",
+						"kind": "task",
+						"bundle": "registry.io/tasks/git-clone:0.1@sha256:4e388ab32b10dc8dbc7e28144f552830adc74787c1e2c0824032078a79f227fb",
+						"resolver": "bundles",
+						"params": {
+							"bundle": "registry.io/tasks/git-clone:0.1@sha256:4e388ab32b10dc8dbc7e28144f552830adc74787c1e2c0824032078a79f227fb",
+							"name": "git-clone",
+							"kind": "task"
+						}
+					},
+					"status": "True",
+					"reason": "Succeeded",
+					"params": {"url": "https://git.localhost/bacon.git"},
+					"results": {"commit": "6c1f093c0c197add71579d392da8a79a984fcd62"}
+				},
+				{
+					"name": "build",
+					"run": "build-1-build",
+					"kind": "TaskRun",
+					"ref": {
+						"name": "buildah",
+						"resolver": "git",
+						"params": {
+							"url": "https://git.localhost/tasks.git",
+							"revision": "main",
+							"pathInRepo": "tasks/buildah.yaml"
+						}
+					},
+					"status": "False",
+					"reason": "Failed"
+				},
+				{
+					"name": "notify",
+					"run": "build-1-notify",
+					"kind": "TaskRun",
+					"ref": {"name": "notify"}
+				}
+			],
+			"skipped_tasks": []
+		}
+	}`, string(b))
+}
+
+func TestNewInputEmpty(t *testing.T) {
+	b, err := json.Marshal(NewInput(&pipelinev1.PipelineRun{}, nil))
+	require.NoError(t, err)
+
+	assert.JSONEq(t, `{
+		"pipeline_run": {
+			"name": "",
+			"status": "Unknown",
+			"params": {},
+			"results": {},
+			"tasks": [],
+			"skipped_tasks": []
+		}
+	}`, string(b))
+}
+
+func TestLoadFromCluster(t *testing.T) {
+	expected := pipelinev1.PipelineRun{ObjectMeta: v1.ObjectMeta{Name: "build-1", Namespace: "builds"}}
+	expected.Status.ChildReferences = []pipelinev1.ChildStatusReference{
+		{Name: "build-1-clone", PipelineTaskName: "clone", TypeMeta: runtime.TypeMeta{Kind: "TaskRun"}},
+		{Name: "build-1-pruned", PipelineTaskName: "pruned", TypeMeta: runtime.TypeMeta{Kind: "TaskRun"}},
+		{Name: "build-1-custom", PipelineTaskName: "custom", TypeMeta: runtime.TypeMeta{Kind: "CustomRun"}},
+	}
+	clone := pipelinev1.TaskRun{ObjectMeta: v1.ObjectMeta{Name: "build-1-clone", Namespace: "builds"}}
+	ctx := kubernetes.WithClient(context.Background(), &policy.FakeKubernetesClient{
+		PipelineRun: expected,
+		TaskRuns:    map[string]pipelinev1.TaskRun{"builds/build-1-clone": clone},
+	})
+
+	pr, taskRuns, err := Load(ctx, "builds/build-1")
+	require.NoError(t, err)
+	assert.Equal(t, expected, *pr)
+	// the pruned task run is skipped
+	assert.Equal(t, []pipelinev1.TaskRun{clone}, taskRuns)
+
+	ctx = kubernetes.WithClient(context.Background(), &policy.FakeKubernetesClient{FetchError: true})
+	_, _, err = Load(ctx, "builds/build-1")
+	assert.EqualError(t, err, "no fetching for you")
+}
+
+func TestLoadInvalidFile(t *testing.T) {
+	cases := []struct {
+		name    string
+		content string
+		err     string
+	}{
+		{name: "invalid", content: "spec: [}", err: "unable to parse PipelineRun from "},
+		{name: "invalid item", content: "kind: List\nitems: [{kind: PipelineRun, spec: []}]", err: "unable to parse PipelineRun from "},
+		{name: "no pipeline run", content: "kind: List\nitems: [{kind: TaskRun}]", err: "no PipelineRun found in "},
+		{name: "many pipeline runs", content: "kind: List\nitems: [{kind: PipelineRun}, {kind: PipelineRun}]", err: "more than one PipelineRun found in "},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			file := path.Join(t.TempDir(), "pr.yaml")
+			require.NoError(t, os.WriteFile(file, []byte(c.content), 0600))
+
+			_, _, err := Load(context.Background(), file)
+			assert.ErrorContains(t, err, c.err+file)
+		})
+	}
+}
+
+func TestRequiredTaskRule(t *testing.T) {
+	rules := t.TempDir()
+	require.NoError(t, os.WriteFile(path.Join(rules, "required_tasks.rego"), []byte(heredoc.Doc(`
+		package required_tasks
+
+		import rego.v1
+
+		required := {"clone", "build"}
+
+		# METADATA
+		# title: Required tasks
+		# custom:
+		#   short_name: missing
+		deny contains result if {
+			some name in required
+			not name in {t.name | some t in input.pipeline_run.tasks}
+			result := {
+				"code": "required_tasks.missing",
+				"msg": sprintf("Required task %q did not run", [name]),
+			}
+		}
+	`)), 0600))
+
+	cases := []struct {
+		name     string
+		tasks    []pipelinev1.ChildStatusReference
+		failures []string
+	}{
+		{
+			name: "all required tasks ran",
+			tasks: []pipelinev1.ChildStatusReference{
+				{Name: "build-1-clone", PipelineTaskName: "clone"},
+				{Name: "build-1-build", PipelineTaskName: "build"},
+			},
+		},
+		{
+			name: "required task missing",
+			tasks: []pipelinev1.ChildStatusReference{
+				{Name: "build-1-clone", PipelineTaskName: "clone"},
+			},
+			failures: []string{`Required task "build" did not run`},
+		},
+	}
+
+	p, err := policy.NewInertPolicy(context.Background(), "")
+	require.NoError(t, err)
+
+	e, err := evaluator.NewConftestEvaluator(context.Background(), []source.PolicySource{
+		&source.PolicyUrl{Url: rules, Kind: source.PolicyKind},
+	}, p, ecc.Source{})
+	require.NoError(t, err)
+	t.Cleanup(e.Destroy)
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			pr := pipelinev1.PipelineRun{ObjectMeta: v1.ObjectMeta{Name: "build-1", Namespace: "builds"}}
+			pr.Status.ChildReferences = c.tasks
+
+			ctx := kubernetes.WithClient(context.Background(), &policy.FakeKubernetesClient{PipelineRun: pr})
+
+			inputFile, err := WriteInputFile(ctx, "builds/build-1")
+			require.NoError(t, err)
+			t.Cleanup(func() { _ = os.Remove(inputFile) })
+
+			results, _, err := e.Evaluate(ctx, evaluator.EvaluationTarget{Inputs: []string{inputFile}})
+			require.NoError(t, err)
+
+			var failures []string
+			for _, r := range results {
+				for _, f := range r.Failures {
+					failures = append(failures, f.Message)
+				}
+			}
+
+			assert.Equal(t, c.failures, failures)
+		})
+	}
+}
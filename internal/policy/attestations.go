@@ -0,0 +1,103 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package policy
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// attestationsKey is the key of the policy spec selecting the attestations
+// included in the policy input, it is not part of the EnterpriseContractPolicy
+// schema
+const attestationsKey = "attestations"
+
+// AttestationSelection selects the attestations of an image included in the
+// policy input by their predicate type. A predicate type ending with "*"
+// matches all predicate types with the preceding prefix. Without any included
+// predicate types all attestations are included. The excluded predicate types
+// take precedence over the included ones.
+type AttestationSelection struct {
+	Include []string `json:"include,omitempty"`
+	Exclude []string `json:"exclude,omitempty"`
+}
+
+// IsEmpty returns true if no predicate types are included nor excluded
+func (s AttestationSelection) IsEmpty() bool {
+	return len(s.Include) == 0 && len(s.Exclude) == 0
+}
+
+// Selects returns true if the attestations with the predicate type are
+// included in the policy input
+func (s AttestationSelection) Selects(predicateType string) bool {
+	if matchesPattern(s.Exclude, predicateType) {
+		return false
+	}
+
+	return len(s.Include) == 0 || matchesPattern(s.Include, predicateType)
+}
+
+// matchesPattern returns true if the value is one of the patterns, or starts
+// with the prefix of a pattern ending with "*"
+func matchesPattern(patterns []string, value string) bool {
+	for _, p := range patterns {
+		if p == value {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(p, "*"); ok && strings.HasPrefix(value, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Merge returns the selection with the predicate types of the other selection
+// added, as done for the included and excluded rules by MergeSpecs
+func (s AttestationSelection) Merge(other AttestationSelection) AttestationSelection {
+	return AttestationSelection{
+		Include: appendNew(s.Include, other.Include...),
+		Exclude: appendNew(s.Exclude, other.Exclude...),
+	}
+}
+
+// splitAttestations removes the attestations key from the policy
+// configuration, in JSON or YAML format, returning the configuration without it
+// and the attestation selection. The configuration is returned as is when it
+// doesn't select attestations.
+func splitAttestations(policyConfig string) (string, AttestationSelection, error) {
+	rest, value, err := splitKey(policyConfig, attestationsKey)
+	if err != nil || value == nil {
+		return rest, AttestationSelection{}, err
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", AttestationSelection{}, err
+	}
+
+	var selection AttestationSelection
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.DisallowUnknownFields()
+	if err := d.Decode(&selection); err != nil {
+		return "", AttestationSelection{}, fmt.Errorf("invalid %s, expecting the include and exclude lists of predicate types, got: %s", attestationsKey, data)
+	}
+
+	return rest, selection, nil
+}
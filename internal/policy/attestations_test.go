@@ -0,0 +1,165 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package policy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+func TestAttestationSelectionSelects(t *testing.T) {
+	cases := []struct {
+		name          string
+		selection     AttestationSelection
+		predicateType string
+		expected      bool
+	}{
+		{
+			name:          "empty",
+			predicateType: "https://slsa.dev/provenance/v0.2",
+			expected:      true,
+		},
+		{
+			name:          "included",
+			selection:     AttestationSelection{Include: []string{"https://slsa.dev/provenance/v0.2"}},
+			predicateType: "https://slsa.dev/provenance/v0.2",
+			expected:      true,
+		},
+		{
+			name:          "not included",
+			selection:     AttestationSelection{Include: []string{"https://slsa.dev/provenance/v0.2"}},
+			predicateType: "https://spdx.dev/Document",
+		},
+		{
+			name:          "included by prefix",
+			selection:     AttestationSelection{Include: []string{"https://slsa.dev/provenance/*"}},
+			predicateType: "https://slsa.dev/provenance/v1",
+			expected:      true,
+		},
+		{
+			name:          "excluded",
+			selection:     AttestationSelection{Exclude: []string{"https://spdx.dev/Document"}},
+			predicateType: "https://spdx.dev/Document",
+		},
+		{
+			name:          "not excluded",
+			selection:     AttestationSelection{Exclude: []string{"https://spdx.dev/Document"}},
+			predicateType: "https://cyclonedx.org/bom",
+			expected:      true,
+		},
+		{
+			name: "exclusion wins",
+			selection: AttestationSelection{
+				Include: []string{"https://example.com/*"},
+				Exclude: []string{"https://example.com/internal"},
+			},
+			predicateType: "https://example.com/internal",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, c.expected, c.selection.Selects(c.predicateType))
+		})
+	}
+}
+
+func TestSplitAttestations(t *testing.T) {
+	cases := []struct {
+		name      string
+		config    string
+		rest      string
+		selection AttestationSelection
+		err       string
+	}{
+		{
+			name:   "none",
+			config: `{"sources": []}`,
+			rest:   `{"sources": []}`,
+		},
+		{
+			name:      "spec",
+			config:    "attestations: {include: [https://slsa.dev/provenance/*]}\nname: spec",
+			rest:      `{"name":"spec"}`,
+			selection: AttestationSelection{Include: []string{"https://slsa.dev/provenance/*"}},
+		},
+		{
+			name:      "resource",
+			config:    `{"apiVersion": "appstudio.redhat.com/v1alpha1", "spec": {"attestations": {"exclude": ["https://spdx.dev/Document"]}}}`,
+			rest:      `{"apiVersion":"appstudio.redhat.com/v1alpha1","spec":{}}`,
+			selection: AttestationSelection{Exclude: []string{"https://spdx.dev/Document"}},
+		},
+		{
+			name:   "invalid",
+			config: `{"attestations": ["https://spdx.dev/Document"]}`,
+			err:    `invalid attestations, expecting the include and exclude lists of predicate types, got: ["https://spdx.dev/Document"]`,
+		},
+		{
+			name:   "unknown field",
+			config: `{"attestations": {"only": ["https://spdx.dev/Document"]}}`,
+			err:    `invalid attestations, expecting the include and exclude lists of predicate types, got: {"only":["https://spdx.dev/Document"]}`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			rest, selection, err := splitAttestations(c.config)
+			if c.err != "" {
+				assert.EqualError(t, err, c.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, c.rest, rest)
+			assert.Equal(t, c.selection, selection)
+		})
+	}
+}
+
+func TestNewPolicyAttestations(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	ctx := utils.WithFS(context.Background(), fs)
+
+	require.NoError(t, afero.WriteFile(fs, "base.yaml", []byte(`
+attestations:
+  include: ["https://slsa.dev/provenance/*"]
+sources:
+  - policy: [oci::quay.io/org/policy:v1]
+`), 0600))
+
+	p, err := NewInertPolicy(ctx, `{"extends": "file:base.yaml", "attestations": {"include": ["https://cyclonedx.org/bom"], "exclude": ["https://slsa.dev/provenance/v0.1"]}}`)
+	require.NoError(t, err)
+
+	assert.Equal(t, AttestationSelection{
+		Include: []string{"https://cyclonedx.org/bom", "https://slsa.dev/provenance/*"},
+		Exclude: []string{"https://slsa.dev/provenance/v0.1"},
+	}, p.AttestationSelection())
+}
+
+func TestValidatePolicyAttestations(t *testing.T) {
+	ctx := utils.WithFS(context.Background(), afero.NewMemMapFs())
+
+	assert.NoError(t, ValidatePolicy(ctx, `{"sources": [{"policy": ["github.com/org/policy"]}], "attestations": {"include": ["https://slsa.dev/provenance/*"]}}`))
+	assert.ErrorContains(t, ValidatePolicy(ctx, `{"attestations": "https://slsa.dev/provenance/*"}`), "invalid attestations")
+}
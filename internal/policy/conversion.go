@@ -0,0 +1,95 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package policy
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	ecc "github.com/enterprise-contract/enterprise-contract-controller/api/v1alpha1"
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/yaml"
+)
+
+// conversion translates an EnterpriseContractPolicy resource of a particular
+// API version into the spec used internally, i.e. the v1alpha1 spec
+type conversion func(data []byte) (ecc.EnterpriseContractPolicySpec, error)
+
+// conversions holds the conversion of each API version of the
+// EnterpriseContractPolicy published by the controller. When the controller
+// publishes a new API version, the conversion of its fields that differ from
+// v1alpha1 is added here.
+var conversions = map[string]conversion{
+	ecc.GroupVersion.Version: specOf,
+}
+
+// specOf returns the spec of the resource as is
+func specOf(data []byte) (ecc.EnterpriseContractPolicySpec, error) {
+	ecp := ecc.EnterpriseContractPolicy{}
+	if err := yaml.Unmarshal(data, &ecp); err != nil {
+		return ecc.EnterpriseContractPolicySpec{}, err
+	}
+
+	return ecp.Spec, nil
+}
+
+// decodePolicy decodes the EnterpriseContractPolicy spec from the JSON or YAML
+// data holding either the whole EnterpriseContractPolicy resource, of any of
+// the supported API versions, or just its spec. The returned boolean reports if
+// the resource was converted from an API version other than v1alpha1.
+func decodePolicy(data []byte) (ecc.EnterpriseContractPolicySpec, bool, error) {
+	meta := struct {
+		APIVersion string `json:"apiVersion"`
+	}{}
+	if err := yaml.Unmarshal(data, &meta); err != nil || meta.APIVersion == "" {
+		log.Debug("Attempting to parse as EnterpriseContractPolicySpec")
+		spec := ecc.EnterpriseContractPolicySpec{}
+		if err := yaml.Unmarshal(data, &spec); err != nil {
+			log.Debugf("Unable to parse EnterpriseContractPolicySpec from %q", data)
+			return ecc.EnterpriseContractPolicySpec{}, false, fmt.Errorf("unable to parse EnterpriseContractPolicySpec: %w", err)
+		}
+
+		return spec, false, nil
+	}
+
+	gv, err := schema.ParseGroupVersion(meta.APIVersion)
+	if err != nil || gv.Group != ecc.GroupVersion.Group {
+		return ecc.EnterpriseContractPolicySpec{}, false, fmt.Errorf("unsupported EnterpriseContractPolicy API version %q, expecting the %s API group", meta.APIVersion, ecc.GroupVersion.Group)
+	}
+	version := gv.Version
+
+	convert, ok := conversions[version]
+	if !ok {
+		versions := make([]string, 0, len(conversions))
+		for v := range conversions {
+			versions = append(versions, v)
+		}
+		slices.Sort(versions)
+		return ecc.EnterpriseContractPolicySpec{}, false, fmt.Errorf("unsupported EnterpriseContractPolicy API version %q, supported versions are: %s", meta.APIVersion, strings.Join(versions, ", "))
+	}
+
+	log.Debugf("Read EnterpriseContractPolicy of API version %s", meta.APIVersion)
+	spec, err := convert(data)
+	if err != nil {
+		log.Debugf("Unable to parse EnterpriseContractPolicy from %q", data)
+		return ecc.EnterpriseContractPolicySpec{}, false, fmt.Errorf("unable to parse EnterpriseContractPolicy %s: %w", meta.APIVersion, err)
+	}
+
+	return spec, version != ecc.GroupVersion.Version, nil
+}
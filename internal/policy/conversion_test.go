@@ -0,0 +1,93 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package policy
+
+import (
+	"testing"
+
+	ecc "github.com/enterprise-contract/enterprise-contract-controller/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodePolicy(t *testing.T) {
+	expected := ecc.EnterpriseContractPolicySpec{
+		PublicKey: "key",
+		Sources:   []ecc.Source{{Policy: []string{"github.com/org/policy"}}},
+	}
+
+	cases := []struct {
+		name      string
+		data      string
+		converted bool
+		err       string
+	}{
+		{
+			name: "spec",
+			data: `{"publicKey": "key", "sources": [{"policy": ["github.com/org/policy"]}]}`,
+		},
+		{
+			name: "v1alpha1",
+			data: `apiVersion: appstudio.redhat.com/v1alpha1
+kind: EnterpriseContractPolicy
+spec:
+  publicKey: key
+  sources:
+    - policy: [github.com/org/policy]`,
+		},
+		{
+			name: "core group",
+			data: `{"apiVersion": "v1", "spec": {"publicKey": "key", "sources": [{"policy": ["github.com/org/policy"]}]}}`,
+			err:  `unsupported EnterpriseContractPolicy API version "v1", expecting the appstudio.redhat.com API group`,
+		},
+		{
+			name: "other group",
+			data: `{"apiVersion": "example.com/v1alpha1", "spec": {}}`,
+			err:  `unsupported EnterpriseContractPolicy API version "example.com/v1alpha1", expecting the appstudio.redhat.com API group`,
+		},
+		{
+			name: "invalid API version",
+			data: `{"apiVersion": "a/b/c", "spec": {}}`,
+			err:  `unsupported EnterpriseContractPolicy API version "a/b/c", expecting the appstudio.redhat.com API group`,
+		},
+		{
+			name: "unpublished version",
+			data: `{"apiVersion": "appstudio.redhat.com/v1", "spec": {}}`,
+			err:  `unsupported EnterpriseContractPolicy API version "appstudio.redhat.com/v1", supported versions are: v1alpha1`,
+		},
+		{
+			name: "invalid",
+			data: `[]`,
+			err:  "unable to parse EnterpriseContractPolicySpec",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			spec, converted, err := decodePolicy([]byte(c.data))
+			if c.err != "" {
+				assert.ErrorContains(t, err, c.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, expected, spec)
+			assert.Equal(t, c.converted, converted)
+		})
+	}
+}
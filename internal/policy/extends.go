@@ -0,0 +1,161 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package policy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	ecc "github.com/enterprise-contract/enterprise-contract-controller/api/v1alpha1"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+	"sigs.k8s.io/yaml"
+
+	"github.com/enterprise-contract/ec-cli/internal/policy/source"
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+// extendsKey is the key of the policy spec holding the reference of the policy
+// it extends, it is not part of the EnterpriseContractPolicy schema
+const extendsKey = "extends"
+
+// splitExtends removes the extends key from the policy configuration, in JSON
+// or YAML format, returning the configuration without it and the reference of
+// the policy extended. The configuration is returned as is when it doesn't
+// extend a policy.
+func splitExtends(policyConfig string) (string, string, error) {
+	rest, extends, err := splitKey(policyConfig, extendsKey)
+	if err != nil || extends == nil {
+		return rest, "", err
+	}
+
+	base, ok := extends.(string)
+	if !ok || base == "" {
+		return "", "", fmt.Errorf("invalid %s, expecting the reference of the policy extended, got: %v", extendsKey, extends)
+	}
+
+	return rest, base, nil
+}
+
+// splitKey removes the key, which is not part of the EnterpriseContractPolicy
+// schema, from the spec in the policy configuration, in JSON or YAML format,
+// returning the configuration without it and the value of the key. The
+// configuration is returned as is, with a nil value, when the key is not set
+// or the configuration can't be parsed.
+func splitKey(policyConfig, key string) (string, any, error) {
+	data, err := yaml.YAMLToJSON([]byte(policyConfig))
+	if err != nil {
+		// reported when decoding the policy
+		return policyConfig, nil, nil
+	}
+
+	var doc map[string]any
+	d := json.NewDecoder(bytes.NewReader(data))
+	// keeps the numbers of the rule data as they are
+	d.UseNumber()
+	if err := d.Decode(&doc); err != nil || doc == nil {
+		return policyConfig, nil, nil
+	}
+
+	spec := doc
+	if s, ok := doc["spec"].(map[string]any); ok && doc["apiVersion"] != nil {
+		spec = s
+	}
+
+	value, ok := spec[key]
+	if !ok {
+		return policyConfig, nil, nil
+	}
+	delete(spec, key)
+
+	data, err = json.Marshal(doc)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return string(data), value, nil
+}
+
+// extendSpec merges the spec into the spec of the base policy it extends, see
+// MergeSpecs. The chain holds the references of the policies already being
+// loaded, a base policy found in it is reported as a cycle.
+func (p *policy) extendSpec(ctx context.Context, spec ecc.EnterpriseContractPolicySpec, base string, chain []string) (ecc.EnterpriseContractPolicySpec, []string, error) {
+	if slices.Contains(chain, base) {
+		return ecc.EnterpriseContractPolicySpec{}, nil, fmt.Errorf("cycle of policies extending each other: %s", strings.Join(append(chain, base), " -> "))
+	}
+	chain = append(slices.Clone(chain), base)
+
+	log.Debugf("Loading the policy %s extended", base)
+	baseRef, err := resolveBase(ctx, base)
+	if err != nil {
+		return ecc.EnterpriseContractPolicySpec{}, nil, fmt.Errorf("unable to load the policy %s extended: %w", base, err)
+	}
+
+	baseSpec, lineage, err := p.loadSpec(ctx, baseRef, chain)
+	if err != nil {
+		return ecc.EnterpriseContractPolicySpec{}, nil, fmt.Errorf("unable to load the policy %s extended: %w", base, err)
+	}
+
+	merged, err := MergeSpecs(baseSpec, spec)
+	if err != nil {
+		return ecc.EnterpriseContractPolicySpec{}, nil, err
+	}
+
+	return merged, append([]string{base}, lineage...), nil
+}
+
+// resolveBase returns the reference, as accepted by ParseRef, of the policy
+// extended. In addition to the references accepted by ParseRef the policy can
+// be extended from a git or https URL, or a JSON or YAML file path, like the
+// --policy flag accepts them.
+func resolveBase(ctx context.Context, base string) (string, error) {
+	if ref, err := ParseRef(base); err == nil {
+		switch ref.Kind {
+		case FileRef, ConfigMapRef, OCIRef:
+			return base, nil
+		}
+	}
+
+	switch {
+	case source.SourceIsGit(base) && !source.SourceIsFile(base) || source.SourceIsHttp(base):
+		fs := utils.FS(ctx)
+		tmpDir, err := utils.CreateWorkDir(fs)
+		if err != nil {
+			return "", err
+		}
+		defer utils.CleanupWorkDir(fs, tmpDir)
+
+		configFile, err := source.GoGetterDownload(ctx, tmpDir, base)
+		if err != nil {
+			return "", err
+		}
+
+		data, err := afero.ReadFile(fs, configFile)
+		if err != nil {
+			return "", err
+		}
+		return string(data), nil
+	case source.SourceIsFile(base) && utils.HasJsonOrYamlExt(base):
+		return filePrefix + base, nil
+	}
+
+	return base, nil
+}
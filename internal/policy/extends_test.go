@@ -0,0 +1,142 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package policy
+
+import (
+	"context"
+	"testing"
+
+	ecc "github.com/enterprise-contract/enterprise-contract-controller/api/v1alpha1"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+func TestSplitExtends(t *testing.T) {
+	cases := []struct {
+		name   string
+		config string
+		rest   string
+		base   string
+		err    string
+	}{
+		{
+			name:   "none",
+			config: `{"sources": []}`,
+			rest:   `{"sources": []}`,
+		},
+		{
+			name:   "spec",
+			config: "extends: file:base.yaml\nruleData: {max: 12345678901234567890}",
+			rest:   `{"ruleData":{"max":12345678901234567890}}`,
+			base:   "file:base.yaml",
+		},
+		{
+			name:   "resource",
+			config: `{"apiVersion": "appstudio.redhat.com/v1alpha1", "spec": {"extends": "ns/base"}}`,
+			rest:   `{"apiVersion":"appstudio.redhat.com/v1alpha1","spec":{}}`,
+			base:   "ns/base",
+		},
+		{
+			name:   "invalid",
+			config: `{"extends": ["a", "b"]}`,
+			err:    "invalid extends, expecting the reference of the policy extended, got: [a b]",
+		},
+		{
+			name:   "not a policy",
+			config: `not: [valid`,
+			rest:   `not: [valid`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			rest, base, err := splitExtends(c.config)
+			if c.err != "" {
+				assert.EqualError(t, err, c.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, c.rest, rest)
+			assert.Equal(t, c.base, base)
+		})
+	}
+}
+
+func TestNewPolicyExtends(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	ctx := utils.WithFS(context.Background(), fs)
+
+	require.NoError(t, afero.WriteFile(fs, "root.yaml", []byte(`
+name: root
+configuration:
+  include: ["@minimal"]
+sources:
+  - name: release
+    policy: [oci::quay.io/org/policy:v1]
+    data: [oci::quay.io/org/data:v1]
+`), 0600))
+	require.NoError(t, afero.WriteFile(fs, "team.yaml", []byte(`
+extends: root.yaml
+name: team
+sources:
+  - name: release
+    policy: [oci::quay.io/org/policy:v2]
+`), 0600))
+
+	p, err := NewInertPolicy(ctx, `{"extends": "file:team.yaml", "configuration": {"exclude": ["cve"]}}`)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"file:team.yaml", "root.yaml"}, p.Lineage())
+	spec := p.Spec()
+	assert.Equal(t, "team", spec.Name)
+	assert.Equal(t, &ecc.EnterpriseContractPolicyConfiguration{Include: []string{"@minimal"}, Exclude: []string{"cve"}}, spec.Configuration)
+	require.Len(t, spec.Sources, 1)
+	assert.Equal(t, []string{"oci::quay.io/org/policy:v2"}, spec.Sources[0].Policy)
+	assert.Equal(t, []string{"oci::quay.io/org/data:v1"}, spec.Sources[0].Data)
+}
+
+func TestNewPolicyExtendsCycle(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	ctx := utils.WithFS(context.Background(), fs)
+
+	require.NoError(t, afero.WriteFile(fs, "a.yaml", []byte(`{"extends": "file:b.yaml"}`), 0600))
+	require.NoError(t, afero.WriteFile(fs, "b.yaml", []byte(`{"extends": "file:a.yaml"}`), 0600))
+
+	_, err := NewInertPolicy(ctx, "file:a.yaml")
+	assert.ErrorContains(t, err, "cycle of policies extending each other: file:a.yaml -> file:b.yaml -> file:a.yaml")
+}
+
+func TestNewPolicyExtendsMissing(t *testing.T) {
+	ctx := utils.WithFS(context.Background(), afero.NewMemMapFs())
+
+	_, err := NewInertPolicy(ctx, `{"extends": "file:missing.yaml"}`)
+	assert.ErrorContains(t, err, "unable to load the policy file:missing.yaml extended: unable to read policy configuration")
+}
+
+func TestValidatePolicyExtends(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	ctx := utils.WithFS(context.Background(), fs)
+
+	require.NoError(t, afero.WriteFile(fs, "base.yaml", []byte(`{"sources": [{"policy": ["github.com/org/policy"]}]}`), 0600))
+
+	assert.NoError(t, ValidatePolicy(ctx, `{"extends": "file:base.yaml"}`))
+}
@@ -19,15 +19,22 @@ package policy
 import (
 	"context"
 	"errors"
+	"fmt"
 
 	ecc "github.com/enterprise-contract/enterprise-contract-controller/api/v1alpha1"
 	app "github.com/konflux-ci/application-api/api/v1alpha1"
+	pipelinev1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	corev1 "k8s.io/api/core/v1"
 )
 
 type FakeKubernetesClient struct {
-	Policy     ecc.EnterpriseContractPolicySpec
-	Snapshot   app.SnapshotSpec
-	FetchError bool
+	Policy      ecc.EnterpriseContractPolicySpec
+	Snapshot    app.SnapshotSpec
+	PipelineRun pipelinev1.PipelineRun
+	TaskRuns    map[string]pipelinev1.TaskRun
+	ConfigMap   corev1.ConfigMap
+	Secret      corev1.Secret
+	FetchError  bool
 }
 
 func (c *FakeKubernetesClient) FetchEnterpriseContractPolicy(ctx context.Context, ref string) (*ecc.EnterpriseContractPolicy, error) {
@@ -43,3 +50,35 @@ func (c *FakeKubernetesClient) FetchSnapshot(ctx context.Context, ref string) (*
 	}
 	return &app.Snapshot{Spec: c.Snapshot}, nil
 }
+
+func (c *FakeKubernetesClient) FetchPipelineRun(ctx context.Context, ref string) (*pipelinev1.PipelineRun, error) {
+	if c.FetchError {
+		return nil, errors.New("no fetching for you")
+	}
+	return &c.PipelineRun, nil
+}
+
+func (c *FakeKubernetesClient) FetchTaskRun(ctx context.Context, ref string) (*pipelinev1.TaskRun, error) {
+	if c.FetchError {
+		return nil, errors.New("no fetching for you")
+	}
+	tr, ok := c.TaskRuns[ref]
+	if !ok {
+		return nil, fmt.Errorf("task run %s not found", ref)
+	}
+	return &tr, nil
+}
+
+func (c *FakeKubernetesClient) FetchConfigMap(ctx context.Context, ref string) (*corev1.ConfigMap, error) {
+	if c.FetchError {
+		return nil, errors.New("no fetching for you")
+	}
+	return &c.ConfigMap, nil
+}
+
+func (c *FakeKubernetesClient) FetchSecret(ctx context.Context, ref string) (*corev1.Secret, error) {
+	if c.FetchError {
+		return nil, errors.New("no fetching for you")
+	}
+	return &c.Secret, nil
+}
@@ -0,0 +1,84 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package policy
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// keylessKey is the key of the policy spec holding the constraints on the
+// certificates of keyless signatures in addition to the identity, it is not
+// part of the EnterpriseContractPolicy schema
+const keylessKey = "keyless"
+
+// KeylessConstraints constrains the Fulcio certificates of keyless signatures
+// beyond the identity and issuer. The GitHub workflow repository and ref are
+// matched against the extensions Fulcio records in certificates issued to
+// GitHub Actions workflows, e.g. "org/repo" and "refs/heads/main". IgnoreSCT
+// accepts certificates without a signed certificate timestamp, e.g. issued by
+// a private Fulcio instance not backed by a certificate transparency log.
+type KeylessConstraints struct {
+	GithubWorkflowRepository string `json:"githubWorkflowRepository,omitempty"`
+	GithubWorkflowRef        string `json:"githubWorkflowRef,omitempty"`
+	IgnoreSCT                bool   `json:"ignoreSCT,omitempty"`
+}
+
+// IsEmpty returns true if no constraints are set
+func (k KeylessConstraints) IsEmpty() bool {
+	return k == KeylessConstraints{}
+}
+
+// Merge returns the constraints with the constraints not set taken from the
+// other constraints
+func (k KeylessConstraints) Merge(other KeylessConstraints) KeylessConstraints {
+	if k.GithubWorkflowRepository == "" {
+		k.GithubWorkflowRepository = other.GithubWorkflowRepository
+	}
+	if k.GithubWorkflowRef == "" {
+		k.GithubWorkflowRef = other.GithubWorkflowRef
+	}
+	k.IgnoreSCT = k.IgnoreSCT || other.IgnoreSCT
+
+	return k
+}
+
+// splitKeyless removes the keyless key from the policy configuration, in JSON
+// or YAML format, returning the configuration without it and the keyless
+// constraints. The configuration is returned as is when it doesn't constrain
+// keyless signatures.
+func splitKeyless(policyConfig string) (string, KeylessConstraints, error) {
+	rest, value, err := splitKey(policyConfig, keylessKey)
+	if err != nil || value == nil {
+		return rest, KeylessConstraints{}, err
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", KeylessConstraints{}, err
+	}
+
+	var constraints KeylessConstraints
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.DisallowUnknownFields()
+	if err := d.Decode(&constraints); err != nil {
+		return "", KeylessConstraints{}, fmt.Errorf("invalid %s, expecting the githubWorkflowRepository, githubWorkflowRef and ignoreSCT constraints, got: %s", keylessKey, data)
+	}
+
+	return rest, constraints, nil
+}
@@ -0,0 +1,114 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package policy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+func TestSplitKeyless(t *testing.T) {
+	cases := []struct {
+		name        string
+		config      string
+		rest        string
+		constraints KeylessConstraints
+		err         string
+	}{
+		{
+			name:   "none",
+			config: `{"sources": []}`,
+			rest:   `{"sources": []}`,
+		},
+		{
+			name:        "spec",
+			config:      "keyless: {githubWorkflowRepository: org/repo, ignoreSCT: true}\nname: spec",
+			rest:        `{"name":"spec"}`,
+			constraints: KeylessConstraints{GithubWorkflowRepository: "org/repo", IgnoreSCT: true},
+		},
+		{
+			name:        "resource",
+			config:      `{"apiVersion": "appstudio.redhat.com/v1alpha1", "spec": {"keyless": {"githubWorkflowRef": "refs/heads/main"}}}`,
+			rest:        `{"apiVersion":"appstudio.redhat.com/v1alpha1","spec":{}}`,
+			constraints: KeylessConstraints{GithubWorkflowRef: "refs/heads/main"},
+		},
+		{
+			name:   "unknown constraint",
+			config: `{"keyless": {"githubWorkflowTrigger": "push"}}`,
+			err:    `invalid keyless, expecting the githubWorkflowRepository, githubWorkflowRef and ignoreSCT constraints, got: {"githubWorkflowTrigger":"push"}`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			rest, constraints, err := splitKeyless(c.config)
+			if c.err != "" {
+				assert.EqualError(t, err, c.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, c.rest, rest)
+			assert.Equal(t, c.constraints, constraints)
+		})
+	}
+}
+
+func TestKeylessConstraintsMerge(t *testing.T) {
+	k := KeylessConstraints{GithubWorkflowRepository: "org/repo"}
+
+	assert.Equal(t, KeylessConstraints{
+		GithubWorkflowRepository: "org/repo",
+		GithubWorkflowRef:        "refs/heads/main",
+		IgnoreSCT:                true,
+	}, k.Merge(KeylessConstraints{GithubWorkflowRepository: "other/repo", GithubWorkflowRef: "refs/heads/main", IgnoreSCT: true}))
+}
+
+func TestNewPolicyKeyless(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	ctx := utils.WithFS(context.Background(), fs)
+
+	require.NoError(t, afero.WriteFile(fs, "base.yaml", []byte(`
+keyless:
+  githubWorkflowRepository: org/base
+  githubWorkflowRef: refs/heads/main
+sources:
+  - policy: [oci::quay.io/org/policy:v1]
+`), 0600))
+
+	p, err := NewInertPolicy(ctx, `{"extends": "file:base.yaml", "keyless": {"githubWorkflowRepository": "org/team"}}`)
+	require.NoError(t, err)
+
+	assert.Equal(t, KeylessConstraints{
+		GithubWorkflowRepository: "org/team",
+		GithubWorkflowRef:        "refs/heads/main",
+	}, p.KeylessConstraints())
+}
+
+func TestValidatePolicyKeyless(t *testing.T) {
+	ctx := utils.WithFS(context.Background(), afero.NewMemMapFs())
+
+	assert.NoError(t, ValidatePolicy(ctx, `{"sources": [{"policy": ["github.com/org/policy"]}], "keyless": {"githubWorkflowRef": "refs/heads/main"}}`))
+	assert.ErrorContains(t, ValidatePolicy(ctx, `{"keyless": "org/repo"}`), "invalid keyless")
+}
@@ -0,0 +1,42 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package policy
+
+import (
+	"strings"
+
+	"github.com/sigstore/sigstore/pkg/signature/kms"
+	// The KMS providers register themselves for their reference schemes,
+	// i.e. awskms://, azurekms://, gcpkms:// and hashivault://, resolving the
+	// public key of a reference via the provider's API
+	_ "github.com/sigstore/sigstore/pkg/signature/kms/aws"
+	_ "github.com/sigstore/sigstore/pkg/signature/kms/azure"
+	_ "github.com/sigstore/sigstore/pkg/signature/kms/gcp"
+	_ "github.com/sigstore/sigstore/pkg/signature/kms/hashivault"
+)
+
+// isKMSRef returns true if the public key reference is the reference of a key
+// in one of the supported KMS providers
+func isKMSRef(publicKey string) bool {
+	for _, p := range kms.SupportedProviders() {
+		if strings.HasPrefix(publicKey, p) {
+			return true
+		}
+	}
+
+	return false
+}
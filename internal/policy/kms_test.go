@@ -0,0 +1,45 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package policy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsKMSRef(t *testing.T) {
+	cases := []struct {
+		ref      string
+		expected bool
+	}{
+		{ref: "awskms:///arn:aws:kms:us-east-1:123456789012:key/cosign", expected: true},
+		{ref: "azurekms://vault.vault.azure.net/cosign", expected: true},
+		{ref: "gcpkms://projects/p/locations/global/keyRings/r/cryptoKeys/cosign", expected: true},
+		{ref: "hashivault://cosign", expected: true},
+		{ref: "k8s://namespace/cosign"},
+		{ref: "cosign.pub"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.ref, func(t *testing.T) {
+			assert.Equal(t, c.expected, isKMSRef(c.ref))
+		})
+	}
+}
@@ -0,0 +1,166 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package policy
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	ecc "github.com/enterprise-contract/enterprise-contract-controller/api/v1alpha1"
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+)
+
+// MergeSpecs layers the given policy specs, each one overriding the ones
+// before it:
+//   - the name, description, public key, Rekor URL and identity are taken
+//     from the last spec that sets them
+//   - the include, exclude and collections of the configuration are appended
+//   - sources are matched by name, sources without a name or with a name not
+//     found in the specs before are appended
+//
+// Of the sources matched by name the policy and data are taken from the last
+// source that sets them, the keys of the rule data are merged with the values
+// of the later source taking precedence, and the include and exclude of the
+// config and of the volatile config are appended.
+func MergeSpecs(specs ...ecc.EnterpriseContractPolicySpec) (ecc.EnterpriseContractPolicySpec, error) {
+	merged := ecc.EnterpriseContractPolicySpec{}
+
+	for _, spec := range specs {
+		if spec.Name != "" {
+			merged.Name = spec.Name
+		}
+		if spec.Description != "" {
+			merged.Description = spec.Description
+		}
+		if spec.PublicKey != "" {
+			merged.PublicKey = spec.PublicKey
+		}
+		if spec.RekorUrl != "" {
+			merged.RekorUrl = spec.RekorUrl
+		}
+		if spec.Identity != nil {
+			identity := *spec.Identity
+			merged.Identity = &identity
+		}
+
+		if spec.Configuration != nil {
+			if merged.Configuration == nil {
+				merged.Configuration = &ecc.EnterpriseContractPolicyConfiguration{}
+			}
+			merged.Configuration.Include = appendNew(merged.Configuration.Include, spec.Configuration.Include...)
+			merged.Configuration.Exclude = appendNew(merged.Configuration.Exclude, spec.Configuration.Exclude...)
+			merged.Configuration.Collections = appendNew(merged.Configuration.Collections, spec.Configuration.Collections...)
+		}
+
+		for _, src := range spec.Sources {
+			i := -1
+			if src.Name != "" {
+				i = slices.IndexFunc(merged.Sources, func(s ecc.Source) bool {
+					return s.Name == src.Name
+				})
+			}
+
+			if i == -1 {
+				merged.Sources = append(merged.Sources, *src.DeepCopy())
+				continue
+			}
+
+			if err := mergeSource(&merged.Sources[i], src); err != nil {
+				return ecc.EnterpriseContractPolicySpec{}, err
+			}
+		}
+	}
+
+	return merged, nil
+}
+
+// mergeSource merges the source into the source with the same name
+func mergeSource(into *ecc.Source, src ecc.Source) error {
+	if len(src.Policy) > 0 {
+		into.Policy = slices.Clone(src.Policy)
+	}
+	if len(src.Data) > 0 {
+		into.Data = slices.Clone(src.Data)
+	}
+
+	if src.RuleData != nil {
+		ruleData, err := mergeRuleData(into.RuleData, src.RuleData)
+		if err != nil {
+			return fmt.Errorf("unable to merge the rule data of the source %q: %w", src.Name, err)
+		}
+		into.RuleData = ruleData
+	}
+
+	if src.Config != nil {
+		if into.Config == nil {
+			into.Config = &ecc.SourceConfig{}
+		}
+		into.Config.Include = appendNew(into.Config.Include, src.Config.Include...)
+		into.Config.Exclude = appendNew(into.Config.Exclude, src.Config.Exclude...)
+	}
+
+	if src.VolatileConfig != nil {
+		if into.VolatileConfig == nil {
+			into.VolatileConfig = &ecc.VolatileSourceConfig{}
+		}
+		into.VolatileConfig.Include = append(into.VolatileConfig.Include, src.VolatileConfig.Include...)
+		into.VolatileConfig.Exclude = append(into.VolatileConfig.Exclude, src.VolatileConfig.Exclude...)
+	}
+
+	return nil
+}
+
+// mergeRuleData merges the top level keys of the rule data, the values of the
+// override take precedence
+func mergeRuleData(base, override *extv1.JSON) (*extv1.JSON, error) {
+	if base == nil {
+		return override.DeepCopy(), nil
+	}
+
+	values := map[string]json.RawMessage{}
+	if err := json.Unmarshal(base.Raw, &values); err != nil {
+		return nil, err
+	}
+
+	overrides := map[string]json.RawMessage{}
+	if err := json.Unmarshal(override.Raw, &overrides); err != nil {
+		return nil, err
+	}
+
+	for k, v := range overrides {
+		values[k] = v
+	}
+
+	raw, err := json.Marshal(values)
+	if err != nil {
+		return nil, err
+	}
+
+	return &extv1.JSON{Raw: raw}, nil
+}
+
+// appendNew appends the values not already present
+func appendNew(values []string, more ...string) []string {
+	for _, v := range more {
+		if !slices.Contains(values, v) {
+			values = append(values, v)
+		}
+	}
+
+	return values
+}
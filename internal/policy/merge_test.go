@@ -0,0 +1,118 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package policy
+
+import (
+	"testing"
+
+	ecc "github.com/enterprise-contract/enterprise-contract-controller/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+)
+
+func TestMergeSpecs(t *testing.T) {
+	base := ecc.EnterpriseContractPolicySpec{
+		Name:      "base",
+		PublicKey: "base-key",
+		RekorUrl:  "https://rekor.example.com",
+		Configuration: &ecc.EnterpriseContractPolicyConfiguration{
+			Include: []string{"@minimal"},
+		},
+		Sources: []ecc.Source{
+			{
+				Name:     "release",
+				Policy:   []string{"oci::quay.io/org/policy:v1"},
+				Data:     []string{"oci::quay.io/org/data:v1"},
+				RuleData: &extv1.JSON{Raw: []byte(`{"allowed_registries": ["quay.io"], "max_age": 30}`)},
+				Config: &ecc.SourceConfig{
+					Include: []string{"@redhat"},
+					Exclude: []string{"cve"},
+				},
+			},
+			{
+				Policy: []string{"github.com/org/unnamed"},
+			},
+		},
+	}
+
+	team := ecc.EnterpriseContractPolicySpec{
+		Name:      "team",
+		PublicKey: "team-key",
+		Configuration: &ecc.EnterpriseContractPolicyConfiguration{
+			Include: []string{"@minimal", "@slsa3"},
+		},
+		Sources: []ecc.Source{
+			{
+				Name:     "release",
+				Policy:   []string{"oci::quay.io/org/policy:v2"},
+				RuleData: &extv1.JSON{Raw: []byte(`{"max_age": 7}`)},
+				Config: &ecc.SourceConfig{
+					Exclude: []string{"cve", "test"},
+				},
+				VolatileConfig: &ecc.VolatileSourceConfig{
+					Exclude: []ecc.VolatileCriteria{{Value: "hermetic", EffectiveUntil: "2030-01-01T00:00:00Z"}},
+				},
+			},
+			{
+				Name:   "team",
+				Policy: []string{"github.com/team/policy"},
+			},
+		},
+	}
+
+	merged, err := MergeSpecs(base, team)
+	require.NoError(t, err)
+
+	assert.Equal(t, "team", merged.Name)
+	assert.Equal(t, "team-key", merged.PublicKey)
+	assert.Equal(t, "https://rekor.example.com", merged.RekorUrl)
+	assert.Equal(t, []string{"@minimal", "@slsa3"}, merged.Configuration.Include)
+
+	require.Len(t, merged.Sources, 3)
+	release := merged.Sources[0]
+	assert.Equal(t, []string{"oci::quay.io/org/policy:v2"}, release.Policy)
+	assert.Equal(t, []string{"oci::quay.io/org/data:v1"}, release.Data)
+	assert.JSONEq(t, `{"allowed_registries": ["quay.io"], "max_age": 7}`, string(release.RuleData.Raw))
+	assert.Equal(t, &ecc.SourceConfig{Include: []string{"@redhat"}, Exclude: []string{"cve", "test"}}, release.Config)
+	assert.Equal(t, []ecc.VolatileCriteria{{Value: "hermetic", EffectiveUntil: "2030-01-01T00:00:00Z"}}, release.VolatileConfig.Exclude)
+	assert.Equal(t, []string{"github.com/org/unnamed"}, merged.Sources[1].Policy)
+	assert.Equal(t, "team", merged.Sources[2].Name)
+
+	// the specs merged are not modified
+	assert.Equal(t, []string{"oci::quay.io/org/policy:v1"}, base.Sources[0].Policy)
+	assert.Equal(t, []string{"cve"}, base.Sources[0].Config.Exclude)
+}
+
+func TestMergeSpecsInvalidRuleData(t *testing.T) {
+	spec := func(ruleData string) ecc.EnterpriseContractPolicySpec {
+		return ecc.EnterpriseContractPolicySpec{
+			Sources: []ecc.Source{{Name: "release", RuleData: &extv1.JSON{Raw: []byte(ruleData)}}},
+		}
+	}
+
+	_, err := MergeSpecs(spec(`{"a": 1}`), spec(`[1]`))
+	assert.ErrorContains(t, err, `unable to merge the rule data of the source "release": json: cannot unmarshal array`)
+}
+
+func TestMergeSpecsNone(t *testing.T) {
+	merged, err := MergeSpecs()
+	require.NoError(t, err)
+	assert.Equal(t, ecc.EnterpriseContractPolicySpec{}, merged)
+}
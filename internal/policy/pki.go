@@ -0,0 +1,152 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package policy
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/sigstore/cosign/v2/pkg/blob"
+	"github.com/sigstore/sigstore/pkg/cryptoutils"
+
+	"github.com/enterprise-contract/ec-cli/internal/signature"
+)
+
+// pkiKey is the key of the policy spec holding the private certificate
+// authority keyless signatures are verified against, it is not part of the
+// EnterpriseContractPolicy schema
+const pkiKey = "pki"
+
+// PKI is a private certificate authority issuing the certificates of keyless
+// signatures instead of Fulcio. The roots and intermediates are bundles of PEM
+// encoded certificates, given inline or as a file or URL. The certificates are
+// checked not to be revoked against the CRLs, files or URLs of PEM or DER
+// encoded certificate revocation lists, and, with OCSP, against the OCSP
+// responders named in the certificates.
+type PKI struct {
+	Roots         string   `json:"roots,omitempty"`
+	Intermediates string   `json:"intermediates,omitempty"`
+	CRLs          []string `json:"crls,omitempty"`
+	OCSP          bool     `json:"ocsp,omitempty"`
+}
+
+// IsEmpty returns true if no private certificate authority is set
+func (k PKI) IsEmpty() bool {
+	return k.Roots == "" && k.Intermediates == "" && len(k.CRLs) == 0 && !k.OCSP
+}
+
+// Merge returns the certificate authority with the roots and intermediates
+// not set taken from the other one, the CRLs of both, and OCSP enabled if
+// enabled in either
+func (k PKI) Merge(other PKI) PKI {
+	if k.Roots == "" {
+		k.Roots = other.Roots
+	}
+	if k.Intermediates == "" {
+		k.Intermediates = other.Intermediates
+	}
+	k.CRLs = appendNew(k.CRLs, other.CRLs...)
+	k.OCSP = k.OCSP || other.OCSP
+
+	return k
+}
+
+// certPool returns the pool of the certificates of the bundle, given inline
+// or as a file or URL
+func certPool(bundle string) (*x509.CertPool, error) {
+	data := []byte(bundle)
+	if !strings.Contains(bundle, "-----BEGIN CERTIFICATE-----") {
+		var err error
+		if data, err = blob.LoadFileOrURL(bundle); err != nil {
+			return nil, err
+		}
+	}
+
+	certs, err := cryptoutils.UnmarshalCertificatesFromPEM(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(certs) == 0 {
+		return nil, errors.New("no certificates found")
+	}
+
+	pool := x509.NewCertPool()
+	for _, c := range certs {
+		pool.AddCert(c)
+	}
+
+	return pool, nil
+}
+
+// revocationList returns the CRL from the file or URL
+func revocationList(ref string) (*x509.RevocationList, error) {
+	data, err := blob.LoadFileOrURL(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	if block, _ := pem.Decode(data); block != nil {
+		data = block.Bytes
+	}
+
+	return x509.ParseRevocationList(data)
+}
+
+// revocation returns the revocation checks of the certificate authority with
+// the CRLs loaded
+func (k PKI) revocation() (signature.Revocation, error) {
+	r := signature.Revocation{OCSP: k.OCSP}
+	for _, ref := range k.CRLs {
+		crl, err := revocationList(ref)
+		if err != nil {
+			return signature.Revocation{}, fmt.Errorf("unable to load the CRL %s: %w", ref, err)
+		}
+		r.CRLs = append(r.CRLs, crl)
+	}
+
+	return r, nil
+}
+
+// splitPKI removes the pki key from the policy configuration, in JSON or YAML
+// format, returning the configuration without it and the certificate
+// authority. The configuration is returned as is when it doesn't set a
+// certificate authority.
+func splitPKI(policyConfig string) (string, PKI, error) {
+	rest, value, err := splitKey(policyConfig, pkiKey)
+	if err != nil || value == nil {
+		return rest, PKI{}, err
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", PKI{}, err
+	}
+
+	var k PKI
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.DisallowUnknownFields()
+	if err := d.Decode(&k); err != nil {
+		return "", PKI{}, fmt.Errorf("invalid %s, expecting the roots, intermediates, crls and ocsp of the certificate authority, got: %s", pkiKey, data)
+	}
+
+	return rest, k, nil
+}
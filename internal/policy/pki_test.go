@@ -0,0 +1,259 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package policy
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/sigstore/sigstore/pkg/cryptoutils"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+func TestSplitPKI(t *testing.T) {
+	cases := []struct {
+		name   string
+		config string
+		rest   string
+		pki    PKI
+		err    string
+	}{
+		{
+			name:   "none",
+			config: `{"sources": []}`,
+			rest:   `{"sources": []}`,
+		},
+		{
+			name:   "spec",
+			config: "pki: {roots: roots.pem, intermediates: intermediates.pem, crls: [ca.crl], ocsp: true}\nname: spec",
+			rest:   `{"name":"spec"}`,
+			pki:    PKI{Roots: "roots.pem", Intermediates: "intermediates.pem", CRLs: []string{"ca.crl"}, OCSP: true},
+		},
+		{
+			name:   "resource",
+			config: `{"apiVersion": "appstudio.redhat.com/v1alpha1", "spec": {"pki": {"roots": "roots.pem"}}}`,
+			rest:   `{"apiVersion":"appstudio.redhat.com/v1alpha1","spec":{}}`,
+			pki:    PKI{Roots: "roots.pem"},
+		},
+		{
+			name:   "unknown field",
+			config: `{"pki": {"ca": "roots.pem"}}`,
+			err:    `invalid pki, expecting the roots, intermediates, crls and ocsp of the certificate authority, got: {"ca":"roots.pem"}`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			rest, pki, err := splitPKI(c.config)
+			if c.err != "" {
+				assert.EqualError(t, err, c.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, c.rest, rest)
+			assert.Equal(t, c.pki, pki)
+		})
+	}
+}
+
+func TestPKIMerge(t *testing.T) {
+	pki := PKI{Roots: "roots.pem", CRLs: []string{"a.crl"}}
+
+	assert.Equal(t, PKI{
+		Roots:         "roots.pem",
+		Intermediates: "intermediates.pem",
+		CRLs:          []string{"a.crl", "b.crl"},
+		OCSP:          true,
+	}, pki.Merge(PKI{Roots: "other.pem", Intermediates: "intermediates.pem", CRLs: []string{"a.crl", "b.crl"}, OCSP: true}))
+}
+
+// testCRL writes a PEM encoded CRL, issued by a new certificate authority,
+// revoking the certificate with the serial number 7 and returns its path
+func testCRL(t *testing.T) string {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, key.Public(), key)
+	require.NoError(t, err)
+	ca, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	der, err = x509.CreateRevocationList(rand.Reader, &x509.RevocationList{
+		Number:     big.NewInt(1),
+		ThisUpdate: time.Now().Add(-time.Hour),
+		NextUpdate: time.Now().Add(time.Hour),
+		RevokedCertificateEntries: []x509.RevocationListEntry{
+			{SerialNumber: big.NewInt(7), RevocationTime: time.Now()},
+		},
+	}, ca, key)
+	require.NoError(t, err)
+
+	crl := path.Join(t.TempDir(), "ca.crl")
+	require.NoError(t, os.WriteFile(crl, pem.EncodeToMemory(&pem.Block{Type: "X509 CRL", Bytes: der}), 0600))
+
+	return crl
+}
+
+func TestNewPolicyPKI(t *testing.T) {
+	roots := path.Join(t.TempDir(), "roots.pem")
+	require.NoError(t, os.WriteFile(roots, []byte(utils.TestFulcioRootCert), 0600))
+	crl := testCRL(t)
+	empty := path.Join(t.TempDir(), "empty.pem")
+	require.NoError(t, os.WriteFile(empty, nil, 0600))
+
+	identity := `"identity": {"issuer": "my-issuer", "subject": "my-subject"}`
+
+	cases := []struct {
+		name          string
+		policy        string
+		pki           PKI
+		intermediates bool
+		crls          int
+		ocsp          bool
+		err           string
+	}{
+		{
+			name:   "inline roots from the policy",
+			policy: `{` + identity + `, "pki": {"roots": ` + string(mustMarshal(t, utils.TestFulcioRootCert)) + `}}`,
+		},
+		{
+			name:          "options override the policy",
+			policy:        `{` + identity + `, "pki": {"roots": "` + path.Join(t.TempDir(), "missing.pem") + `"}}`,
+			pki:           PKI{Roots: roots, Intermediates: roots},
+			intermediates: true,
+		},
+		{
+			name:   "revocation checks",
+			policy: `{` + identity + `, "pki": {"roots": "` + roots + `", "crls": ["` + crl + `"], "ocsp": true}}`,
+			crls:   1,
+			ocsp:   true,
+		},
+		{
+			name:   "intermediates without roots",
+			policy: `{` + identity + `}`,
+			pki:    PKI{Intermediates: roots},
+			err:    "the intermediate certificates of the certificate authority can only be used with its root certificates",
+		},
+		{
+			name:   "missing CRL",
+			policy: `{` + identity + `, "pki": {"roots": "` + roots + `", "crls": ["` + path.Join(t.TempDir(), "missing.crl") + `"]}}`,
+			err:    "unable to load the CRL",
+		},
+		{
+			name:   "missing roots",
+			policy: `{` + identity + `, "pki": {"roots": "` + path.Join(t.TempDir(), "missing.pem") + `"}}`,
+			err:    "unable to load the root certificates of the certificate authority",
+		},
+		{
+			name:   "no certificates",
+			policy: `{` + identity + `, "pki": {"roots": "` + empty + `"}}`,
+			err:    "unable to load the root certificates of the certificate authority: no certificates found",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			p, err := NewPolicy(context.Background(), Options{
+				PolicyRef:     c.policy,
+				PKI:           c.pki,
+				IgnoreRekor:   true,
+				EffectiveTime: Now,
+			})
+			if c.err != "" {
+				assert.ErrorContains(t, err, c.err)
+				return
+			}
+			require.NoError(t, err)
+
+			opts, err := p.CheckOpts()
+			require.NoError(t, err)
+
+			certs, err := cryptoutils.UnmarshalCertificatesFromPEM([]byte(utils.TestFulcioRootCert))
+			require.NoError(t, err)
+			_, err = certs[0].Verify(x509.VerifyOptions{Roots: opts.RootCerts, KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageAny}})
+			assert.NoError(t, err)
+
+			assert.Equal(t, c.intermediates, opts.IntermediateCerts != nil)
+			assert.True(t, opts.IgnoreSCT)
+			assert.Nil(t, opts.CTLogPubKeys)
+
+			assert.Len(t, p.Revocation().CRLs, c.crls)
+			assert.Equal(t, c.ocsp, p.Revocation().OCSP)
+		})
+	}
+}
+
+func TestNewPolicyPKIExtends(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	ctx := utils.WithFS(context.Background(), fs)
+
+	require.NoError(t, afero.WriteFile(fs, "base.yaml", []byte(`
+pki:
+  roots: https://pki.example.com/roots.pem
+  crls: [https://pki.example.com/base.crl]
+sources:
+  - policy: [oci::quay.io/org/policy:v1]
+`), 0600))
+
+	p, err := NewInertPolicy(ctx, `{"extends": "file:base.yaml", "pki": {"crls": ["https://pki.example.com/team.crl"], "ocsp": true}}`)
+	require.NoError(t, err)
+
+	assert.Equal(t, PKI{
+		Roots: "https://pki.example.com/roots.pem",
+		CRLs:  []string{"https://pki.example.com/team.crl", "https://pki.example.com/base.crl"},
+		OCSP:  true,
+	}, p.PKI())
+}
+
+func TestValidatePolicyPKI(t *testing.T) {
+	ctx := utils.WithFS(context.Background(), afero.NewMemMapFs())
+
+	assert.NoError(t, ValidatePolicy(ctx, `{"sources": [{"policy": ["github.com/org/policy"]}], "pki": {"roots": "roots.pem"}}`))
+	assert.ErrorContains(t, ValidatePolicy(ctx, `{"pki": "roots.pem"}`), "invalid pki")
+}
+
+func mustMarshal(t *testing.T, v any) []byte {
+	data, err := json.Marshal(v)
+	require.NoError(t, err)
+	return data
+}
@@ -25,6 +25,7 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"sync"
 	"time"
 
 	ecc "github.com/enterprise-contract/enterprise-contract-controller/api/v1alpha1"
@@ -37,10 +38,13 @@ import (
 	cosignSig "github.com/sigstore/cosign/v2/pkg/signature"
 	"github.com/sigstore/sigstore/pkg/cryptoutils"
 	sigstoreSig "github.com/sigstore/sigstore/pkg/signature"
+	"github.com/sigstore/sigstore/pkg/tuf"
 	log "github.com/sirupsen/logrus"
 	"sigs.k8s.io/yaml"
 
 	"github.com/enterprise-contract/ec-cli/internal/kubernetes"
+	"github.com/enterprise-contract/ec-cli/internal/signature"
+	"github.com/enterprise-contract/ec-cli/internal/tracing"
 )
 
 const (
@@ -52,8 +56,62 @@ const (
 // allows controlling time in tests
 var now = time.Now
 
+// ValidatePolicy checks that the policy configuration conforms to the
+// EnterpriseContractPolicy schema and reports all the semantic problems found,
+// e.g. missing sources or rules both included and excluded, of the policy
+// merged with the policies it extends
 func ValidatePolicy(ctx context.Context, policyConfig string) error {
-	return validatePolicyConfig(policyConfig)
+	policyConfig, base, err := splitExtends(policyConfig)
+	if err != nil {
+		return err
+	}
+
+	policyConfig, _, err = splitAttestations(policyConfig)
+	if err != nil {
+		return err
+	}
+
+	policyConfig, _, err = splitKeyless(policyConfig)
+	if err != nil {
+		return err
+	}
+
+	policyConfig, _, err = splitIgnoreRekor(policyConfig)
+	if err != nil {
+		return err
+	}
+
+	policyConfig, _, err = splitTUF(policyConfig)
+	if err != nil {
+		return err
+	}
+
+	policyConfig, _, err = splitPublicKeys(policyConfig)
+	if err != nil {
+		return err
+	}
+
+	policyConfig, _, err = splitPKI(policyConfig)
+	if err != nil {
+		return err
+	}
+
+	if err := validatePolicyConfig(policyConfig); err != nil {
+		return err
+	}
+
+	spec, _, err := decodePolicy([]byte(policyConfig))
+	if err != nil {
+		return err
+	}
+
+	if base != "" {
+		if spec, _, err = (&policy{}).extendSpec(ctx, spec, base, nil); err != nil {
+			return err
+		}
+	}
+
+	return multierror.Append(checkSources(spec), checkSpec(spec)).ErrorOrNil()
 }
 
 // Create a JSON schema from a Go type, and return the JSON as a byte slice
@@ -87,6 +145,15 @@ type Policy interface {
 	Identity() cosign.Identity
 	Keyless() bool
 	SigstoreOpts() (SigstoreOpts, error)
+	Lineage() []string
+	AttestationSelection() AttestationSelection
+	KeylessConstraints() KeylessConstraints
+	IgnoreRekor() bool
+	RekorInclusionProof() bool
+	TUFRoot() TUFRoot
+	PublicKeys() []PublicKey
+	PKI() PKI
+	Revocation() signature.Revocation
 }
 
 type policy struct {
@@ -96,10 +163,43 @@ type policy struct {
 	effectiveTime   *time.Time
 	attestationTime *time.Time
 	identity        cosign.Identity
-	ignoreRekor     bool
+	// ignoreRekor skips the Rekor transparency log checks, set by the options,
+	// the policy or the policies extended
+	ignoreRekor bool
+	// rekorOffline verifies the transparency log entries only against the
+	// Rekor bundles of the signatures, without contacting Rekor
+	rekorOffline bool
+	// rekorInclusionProof verifies the inclusion proofs of the transparency
+	// log entries recorded in the Rekor bundles of the signatures
+	rekorInclusionProof bool
+	// skipCertificateChecks skips the verification of the certificate chain
+	// and of the SCT of keyless signatures
+	skipCertificateChecks bool
+	// lineage holds the references of the policies extended
+	lineage []string
+	// attestations selects the attestations included in the policy input,
+	// merged from the policy and the policies extended
+	attestations AttestationSelection
+	// keyless constrains the certificates of keyless signatures, merged from
+	// the options, the policy and the policies extended
+	keyless KeylessConstraints
+	// tuf is the TUF repository the Sigstore trust root is initialized from,
+	// merged from the options, the policy and the policies extended
+	tuf TUFRoot
+	// publicKeys are the trusted public keys, each possibly scoped to a time
+	// window or to repositories, from the policy and the policies extended
+	publicKeys []PublicKey
+	// pki is the private certificate authority issuing the certificates of
+	// keyless signatures, merged from the options, the policy and the
+	// policies extended
+	pki PKI
+	// revocation holds the revocation checks of the certificates of keyless
+	// signatures, with the CRLs of the pki loaded
+	revocation signature.Revocation
 }
 
-// PublicKeyPEM returns the PublicKey in PEM format.
+// PublicKeyPEM returns the PublicKey in PEM format. With several public keys
+// the keys are concatenated.
 func (p *policy) PublicKeyPEM() ([]byte, error) {
 	// Public key is not involved when using keyless verification
 	if p.Keyless() {
@@ -108,11 +208,29 @@ func (p *policy) PublicKeyPEM() ([]byte, error) {
 	if p.checkOpts == nil || p.checkOpts.SigVerifier == nil {
 		return nil, errors.New("no check options or sig verifier configured")
 	}
-	pk, err := p.checkOpts.SigVerifier.PublicKey()
-	if err != nil {
-		return nil, err
+
+	verifiers := []sigstoreSig.Verifier{p.checkOpts.SigVerifier}
+	if keys := p.PublicKeys(); len(keys) > 0 {
+		verifiers = make([]sigstoreSig.Verifier, 0, len(keys))
+		for _, k := range keys {
+			verifiers = append(verifiers, k.Verifier())
+		}
+	}
+
+	var pems []byte
+	for _, v := range verifiers {
+		pk, err := v.PublicKey()
+		if err != nil {
+			return nil, err
+		}
+		key, err := cryptoutils.MarshalPublicKeyToPEM(pk)
+		if err != nil {
+			return nil, err
+		}
+		pems = append(pems, key...)
 	}
-	return cryptoutils.MarshalPublicKeyToPEM(pk)
+
+	return pems, nil
 }
 
 func (p *policy) CheckOpts() (*cosign.CheckOpts, error) {
@@ -132,7 +250,7 @@ func (p *policy) Identity() cosign.Identity {
 
 // Keyless returns whether or not the Policy uses the keyless workflow for verification.
 func (p *policy) Keyless() bool {
-	return p.PublicKey == ""
+	return p.PublicKey == "" && len(p.publicKeys) == 0
 }
 
 func (p *policy) SigstoreOpts() (SigstoreOpts, error) {
@@ -155,12 +273,18 @@ func (p *policy) SigstoreOpts() (SigstoreOpts, error) {
 }
 
 type Options struct {
-	EffectiveTime string
-	Identity      cosign.Identity
-	IgnoreRekor   bool
-	PolicyRef     string
-	PublicKey     string
-	RekorURL      string
+	EffectiveTime         string
+	Identity              cosign.Identity
+	IgnoreRekor           bool
+	Keyless               KeylessConstraints
+	PolicyRef             string
+	PublicKey             string
+	RekorInclusionProof   bool
+	RekorOffline          bool
+	RekorURL              string
+	PKI                   PKI
+	SkipCertificateChecks bool
+	TUF                   TUFRoot
 }
 
 // NewOfflinePolicy construct and return a new instance of Policy that is used
@@ -234,7 +358,10 @@ func NewInputPolicy(ctx context.Context, policyRef string, effectiveTime string)
 //
 // The public key is resolved as part of object construction. If the public key is a reference
 // to a kubernetes resource, for example, the cluster will be contacted.
-func NewPolicy(ctx context.Context, opts Options) (Policy, error) {
+func NewPolicy(ctx context.Context, opts Options) (_ Policy, err error) {
+	ctx, span := tracing.Start(ctx, "policy.resolve")
+	defer func() { tracing.End(span, err) }()
+
 	p := policy{
 		choosenTime: opts.EffectiveTime,
 	}
@@ -248,14 +375,39 @@ func NewPolicy(ctx context.Context, opts Options) (Policy, error) {
 		log.Debugf("Updated rekor URL in policy to %q", opts.RekorURL)
 	}
 
-	p.ignoreRekor = opts.IgnoreRekor
+	p.ignoreRekor = p.ignoreRekor || opts.IgnoreRekor
+
+	if opts.RekorOffline && opts.RekorInclusionProof {
+		return nil, errors.New("the inclusion proofs of the Rekor entries can't be verified in offline mode, Rekor needs to be contacted to fetch them")
+	}
+
+	if opts.RekorInclusionProof {
+		if p.ignoreRekor {
+			return nil, errors.New("the inclusion proofs of the Rekor entries can't be verified when ignoring Rekor")
+		}
+		if p.RekorUrl == "" {
+			return nil, errors.New("the Rekor URL is needed to verify the inclusion proofs of the Rekor entries")
+		}
+	}
+	p.rekorOffline = opts.RekorOffline
+	p.rekorInclusionProof = opts.RekorInclusionProof
+
+	p.tuf = opts.TUF.Merge(p.tuf)
+	if err := initializeTUF(ctx, p.tuf); err != nil {
+		return nil, err
+	}
 
 	if opts.PublicKey != "" && opts.PublicKey != p.PublicKey {
 		p.PublicKey = opts.PublicKey
 		log.Debugf("Updated public key in policy to %q", opts.PublicKey)
 	}
 
-	if p.PublicKey == "" {
+	if opts.PublicKey != "" && len(p.publicKeys) > 0 {
+		p.publicKeys = nil
+		log.Debugf("Using only the public key %q instead of the public keys from the policy", opts.PublicKey)
+	}
+
+	if p.Keyless() {
 		if opts.Identity != (cosign.Identity{}) {
 			p.identity = opts.Identity
 		} else if p.EnterpriseContractPolicySpec.Identity != nil {
@@ -271,6 +423,26 @@ func NewPolicy(ctx context.Context, opts Options) (Policy, error) {
 		if err := validateIdentity(p.identity); err != nil {
 			return nil, err
 		}
+
+		p.keyless = opts.Keyless.Merge(p.keyless)
+
+		p.pki = opts.PKI.Merge(p.pki)
+		if p.pki.Intermediates != "" && p.pki.Roots == "" {
+			return nil, errors.New("the intermediate certificates of the certificate authority can only be used with its root certificates")
+		}
+		if p.revocation, err = p.pki.revocation(); err != nil {
+			return nil, err
+		}
+
+		if opts.SkipCertificateChecks {
+			// The certificate checks can only be skipped when verifying offline
+			// against a trust bundle provided by the user
+			if !p.ignoreRekor || os.Getenv("SIGSTORE_ROOT_FILE") == "" {
+				return nil, errors.New("the certificate chain and SCT checks can only be skipped in offline mode, i.e. when ignoring Rekor, with a trust bundle loaded via the SIGSTORE_ROOT_FILE environment variable")
+			}
+			log.Warn("Skipping the certificate chain and SCT checks of keyless signatures")
+			p.skipCertificateChecks = true
+		}
 	}
 
 	if efn, err := parseEffectiveTime(opts.EffectiveTime); err != nil {
@@ -296,55 +468,140 @@ func (p *policy) loadPolicy(ctx context.Context, policyRef string) error {
 		// publicKey param.
 		return nil
 	}
+
+	// the references of the policies being loaded, to detect cycles of extends
+	var chain []string
+	if ref, err := ParseRef(policyRef); err == nil && ref.Kind != InlineRef {
+		chain = append(chain, policyRef)
+	}
+
+	spec, lineage, err := p.loadSpec(ctx, policyRef, chain)
+	if err != nil {
+		return err
+	}
+	p.EnterpriseContractPolicySpec = spec
+	p.lineage = lineage
+
+	return checkLoaded(p.EnterpriseContractPolicySpec)
+}
+
+// loadSpec loads the policy spec from the reference, resolving the policies it
+// extends. The references of the policies extended are returned, the policy
+// extended directly first.
+func (p *policy) loadSpec(ctx context.Context, policyRef string, chain []string) (ecc.EnterpriseContractPolicySpec, []string, error) {
+	ref, err := ParseRef(policyRef)
+	if err != nil {
+		return ecc.EnterpriseContractPolicySpec{}, nil, fmt.Errorf("invalid policy reference: %w", err)
+	}
+
+	if ref.Kind == ClusterRef {
+		log.Debug("Read EnterpriseContractPolicy as k8s resource")
+		k8s, err := kubernetes.NewClient(ctx)
+		if err != nil {
+			log.Debug("Failed to initialize Kubernetes client")
+			return ecc.EnterpriseContractPolicySpec{}, nil, fmt.Errorf("cannot initialize Kubernetes client: %w", err)
+		}
+		log.Debug("Initialized Kubernetes client")
+
+		ecp, err := k8s.FetchEnterpriseContractPolicy(ctx, ref.String())
+		if err != nil {
+			log.Debug("Failed to fetch the enterprise contract policy from the cluster!")
+			return ecc.EnterpriseContractPolicySpec{}, nil, fmt.Errorf("unable to fetch EnterpriseContractPolicy: %w", err)
+		}
+		return ecp.Spec, nil, nil
+	}
+
 	/*
 		Note: by the time we arrive here, if our policyRef was originally a URI for a
 		JSON / YAML the document will have already opened / downloaded and it would be
-		a JSON / YAML string which is why we can use `yaml.Unmarshal` below.
+		a JSON / YAML string which is why we can use `yaml.Unmarshal` below. The
+		file:, configmap: and oci:: references are resolved to the JSON / YAML
+		string here.
 
 		Before we unmarshal we need to check if the policyRef text conforms to the
 		EnprerpriseContractPolicySpec schema. If it does, we can proceed to unmarshal
 		it. If it does not conform to the spec, we should return an error.
 	*/
-	if strings.Contains(policyRef, ":") { // Should detect JSON or YAML objects 🤞
-		log.Debug("Read EnterpriseContractPolicy as YAML")
-		ecp := ecc.EnterpriseContractPolicy{}
-		if err := yaml.Unmarshal([]byte(policyRef), &ecp); err == nil && ecp.APIVersion != "" {
-			p.EnterpriseContractPolicySpec = ecp.Spec
-		} else {
-			log.Debugf("Unable to parse EnterpriseContractPolicy from %q", policyRef)
-			log.Debug("Attempting to parse as EnterpriseContractPolicySpec")
-			if err := yaml.Unmarshal([]byte(policyRef), &p.EnterpriseContractPolicySpec); err != nil {
-				log.Debugf("Unable to parse EnterpriseContractPolicySpec from %q", policyRef)
-				return fmt.Errorf("unable to parse EnterpriseContractPolicySpec: %w", err)
-			}
-		}
-		// Check if the policyRef is conformant to the schema
-		if policyRef != "" {
-			ok, err := p.isConformant(policyRef)
-			if err != nil {
-				return err
-			}
-			if !ok {
-				return fmt.Errorf("policy does not conform to the schema")
-			}
-		}
-	} else {
-		log.Debug("Read EnterpriseContractPolicy as k8s resource")
-		k8s, err := kubernetes.NewClient(ctx)
+	policyRef, err = resolveRef(ctx, ref)
+	if err != nil {
+		return ecc.EnterpriseContractPolicySpec{}, nil, err
+	}
+
+	// extends, attestations, keyless, ignoreRekor, tuf, publicKeys and pki are
+	// not part of the schema
+	policyRef, base, err := splitExtends(policyRef)
+	if err != nil {
+		return ecc.EnterpriseContractPolicySpec{}, nil, err
+	}
+
+	policyRef, selection, err := splitAttestations(policyRef)
+	if err != nil {
+		return ecc.EnterpriseContractPolicySpec{}, nil, err
+	}
+	p.attestations = p.attestations.Merge(selection)
+
+	policyRef, constraints, err := splitKeyless(policyRef)
+	if err != nil {
+		return ecc.EnterpriseContractPolicySpec{}, nil, err
+	}
+	p.keyless = p.keyless.Merge(constraints)
+
+	policyRef, ignoreRekor, err := splitIgnoreRekor(policyRef)
+	if err != nil {
+		return ecc.EnterpriseContractPolicySpec{}, nil, err
+	}
+	p.ignoreRekor = p.ignoreRekor || ignoreRekor
+
+	policyRef, tufRoot, err := splitTUF(policyRef)
+	if err != nil {
+		return ecc.EnterpriseContractPolicySpec{}, nil, err
+	}
+	p.tuf = p.tuf.Merge(tufRoot)
+
+	policyRef, keys, err := splitPublicKeys(policyRef)
+	if err != nil {
+		return ecc.EnterpriseContractPolicySpec{}, nil, err
+	}
+	p.publicKeys = append(p.publicKeys, keys...)
+
+	policyRef, pki, err := splitPKI(policyRef)
+	if err != nil {
+		return ecc.EnterpriseContractPolicySpec{}, nil, err
+	}
+	p.pki = p.pki.Merge(pki)
+
+	log.Debug("Read EnterpriseContractPolicy as YAML")
+	spec, converted, err := decodePolicy([]byte(policyRef))
+	if err != nil {
+		return ecc.EnterpriseContractPolicySpec{}, nil, err
+	}
+
+	// The schema describes the v1alpha1 spec, so the spec converted from other
+	// API versions is checked instead
+	if converted {
+		j, err := json.Marshal(spec)
 		if err != nil {
-			log.Debug("Failed to initialize Kubernetes client")
-			return fmt.Errorf("cannot initialize Kubernetes client: %w", err)
+			return ecc.EnterpriseContractPolicySpec{}, nil, err
 		}
-		log.Debug("Initialized Kubernetes client")
+		policyRef = string(j)
+	}
 
-		ecp, err := k8s.FetchEnterpriseContractPolicy(ctx, policyRef)
+	// Check if the policyRef is conformant to the schema
+	if policyRef != "" {
+		ok, err := p.isConformant(policyRef)
 		if err != nil {
-			log.Debug("Failed to fetch the enterprise contract policy from the cluster!")
-			return fmt.Errorf("unable to fetch EnterpriseContractPolicy: %w", err)
+			return ecc.EnterpriseContractPolicySpec{}, nil, err
+		}
+		if !ok {
+			return ecc.EnterpriseContractPolicySpec{}, nil, fmt.Errorf("policy does not conform to the schema")
 		}
-		p.EnterpriseContractPolicySpec = ecp.Spec
 	}
-	return nil
+
+	if base == "" {
+		return spec, nil, nil
+	}
+
+	return p.extendSpec(ctx, spec, base, chain)
 }
 
 // isConformant checks if the given policy conforms to the Enterprise Contract
@@ -358,6 +615,70 @@ func (p *policy) isConformant(policyRef string) (bool, error) {
 	return true, nil
 }
 
+// Lineage returns the references of the policies the policy extends, the
+// policy extended directly first
+func (p *policy) Lineage() []string {
+	return p.lineage
+}
+
+// AttestationSelection returns the selection of the attestations included in
+// the policy input
+func (p *policy) AttestationSelection() AttestationSelection {
+	return p.attestations
+}
+
+// KeylessConstraints returns the constraints on the certificates of keyless
+// signatures
+func (p *policy) KeylessConstraints() KeylessConstraints {
+	return p.keyless
+}
+
+// IgnoreRekor returns whether or not the Rekor transparency log checks are
+// skipped
+func (p *policy) IgnoreRekor() bool {
+	return p.ignoreRekor
+}
+
+// RekorInclusionProof returns whether or not the inclusion proofs of the
+// transparency log entries recorded in the Rekor bundles of the signatures are
+// verified against Rekor
+func (p *policy) RekorInclusionProof() bool {
+	return p.rekorInclusionProof
+}
+
+// TUFRoot returns the TUF repository the Sigstore trust root is initialized
+// from
+func (p *policy) TUFRoot() TUFRoot {
+	return p.tuf
+}
+
+// PKI returns the private certificate authority issuing the certificates of
+// keyless signatures
+func (p *policy) PKI() PKI {
+	return p.pki
+}
+
+// Revocation returns the revocation checks of the certificates of keyless
+// signatures
+func (p *policy) Revocation() signature.Revocation {
+	return p.revocation
+}
+
+// PublicKeys returns the trusted public keys listed in the policy, preceded by
+// the public key of the policy once resolved, or nil if the policy doesn't
+// list public keys
+func (p *policy) PublicKeys() []PublicKey {
+	if len(p.publicKeys) == 0 {
+		return nil
+	}
+
+	if p.PublicKey != "" && p.checkOpts != nil {
+		return append([]PublicKey{{Key: p.PublicKey, verifier: p.checkOpts.SigVerifier}}, p.publicKeys...)
+	}
+
+	return p.publicKeys
+}
+
 func (p *policy) WithSpec(spec ecc.EnterpriseContractPolicySpec) Policy {
 	p.EnterpriseContractPolicySpec = spec
 
@@ -424,32 +745,70 @@ func checkOpts(ctx context.Context, p *policy) (*cosign.CheckOpts, error) {
 	var err error
 	opts := cosign.CheckOpts{}
 
-	if p.PublicKey != "" {
+	if !p.Keyless() {
 		log.Debug("Using long-lived key workflow")
-		if opts.SigVerifier, err = signatureVerifier(ctx, p); err != nil {
-			return nil, err
+		if p.PublicKey != "" {
+			if opts.SigVerifier, err = signatureVerifier(ctx, p.PublicKey); err != nil {
+				return nil, err
+			}
+		}
+
+		for i := range p.publicKeys {
+			if p.publicKeys[i].verifier, err = signatureVerifier(ctx, p.publicKeys[i].Key); err != nil {
+				return nil, fmt.Errorf("unable to load the public key %d of the policy: %w", i, err)
+			}
+		}
+
+		// used by default, e.g. by the rego functions verifying signatures,
+		// the public keys applying to an image are used when validating it
+		if opts.SigVerifier == nil {
+			opts.SigVerifier = p.publicKeys[0].verifier
 		}
 	} else {
 		log.Debug("Using keyless workflow")
 		log.Debugf("TUF_ROOT=%s", os.Getenv("TUF_ROOT"))
 		opts.Identities = []cosign.Identity{p.identity}
 
-		// Get Fulcio certificates
-		if opts.RootCerts, err = fulcio.GetRoots(); err != nil {
-			return nil, err
-		}
-		if opts.IntermediateCerts, err = fulcio.GetIntermediates(); err != nil {
-			return nil, err
-		}
+		if p.pki.Roots != "" {
+			// A private certificate authority is not backed by a Certificate
+			// Transparency log, its certificates have no SCT
+			log.Debug("Using the certificates of the private certificate authority")
+			if opts.RootCerts, err = certPool(p.pki.Roots); err != nil {
+				return nil, fmt.Errorf("unable to load the root certificates of the certificate authority: %w", err)
+			}
+			if p.pki.Intermediates != "" {
+				if opts.IntermediateCerts, err = certPool(p.pki.Intermediates); err != nil {
+					return nil, fmt.Errorf("unable to load the intermediate certificates of the certificate authority: %w", err)
+				}
+			}
+			opts.IgnoreSCT = true
+		} else {
+			// Get Fulcio certificates
+			if opts.RootCerts, err = fulcio.GetRoots(); err != nil {
+				return nil, err
+			}
+			if opts.IntermediateCerts, err = fulcio.GetIntermediates(); err != nil {
+				return nil, err
+			}
 
-		// Get Certificate Transparency Log public keys
-		if opts.CTLogPubKeys, err = cosign.GetCTLogPubs(ctx); err != nil {
-			return nil, err
+			// Get Certificate Transparency Log public keys
+			if opts.CTLogPubKeys, err = cosign.GetCTLogPubs(ctx); err != nil {
+				return nil, err
+			}
+			log.Debug("Retrieved Rekor public keys")
+
+			opts.IgnoreSCT = p.skipCertificateChecks || p.keyless.IgnoreSCT
+			if p.keyless.IgnoreSCT {
+				log.Warn("Not requiring a signed certificate timestamp in the certificates of keyless signatures")
+			}
 		}
-		log.Debug("Retrieved Rekor public keys")
+
+		opts.CertGithubWorkflowRepository = p.keyless.GithubWorkflowRepository
+		opts.CertGithubWorkflowRef = p.keyless.GithubWorkflowRef
 	}
 
 	opts.IgnoreTlog = p.ignoreRekor
+	opts.Offline = p.rekorOffline
 
 	if !opts.IgnoreTlog {
 		// NOTE: The value of the RekorURL may not be used by cosign during verification.
@@ -462,8 +821,8 @@ func checkOpts(ctx context.Context, p *policy) (*cosign.CheckOpts, error) {
 		// SignedEntryTimestamp to the signatures and attestations it creates.
 		rekorURL := p.RekorUrl
 		// NOTE: A Rekor client is only needed when a SignedEntryTimestamp is not available
-		// on the signature/attestation.
-		if rekorURL != "" {
+		// on the signature/attestation. In offline mode signatures without it are rejected.
+		if rekorURL != "" && !p.rekorOffline {
 			if opts.RekorClient, err = rekor.NewClient(rekorURL); err != nil {
 				log.Debugf("Problem creating a rekor client using url %q", rekorURL)
 				return nil, err
@@ -482,6 +841,7 @@ func checkOpts(ctx context.Context, p *policy) (*cosign.CheckOpts, error) {
 
 type signatureClient interface {
 	publicKeyFromKeyRef(context.Context, string) (sigstoreSig.Verifier, error)
+	initializeTUF(context.Context, string, []byte) error
 }
 
 type cosignClient struct{}
@@ -490,6 +850,10 @@ func (c *cosignClient) publicKeyFromKeyRef(ctx context.Context, publicKey string
 	return cosignSig.PublicKeyFromKeyRef(ctx, publicKey)
 }
 
+func (c *cosignClient) initializeTUF(ctx context.Context, mirror string, root []byte) error {
+	return tuf.Initialize(ctx, mirror, root)
+}
+
 type contextKey string
 
 const signatureClientContextKey contextKey = "ec.policy.signature.client"
@@ -507,10 +871,8 @@ func newSignatureClient(ctx context.Context) signatureClient {
 	return &cosignClient{}
 }
 
-// signatureVerifier creates a new instance based on the PublicKey from the Policy.
-func signatureVerifier(ctx context.Context, p *policy) (sigstoreSig.Verifier, error) {
-	publicKey := p.PublicKey
-
+// signatureVerifier creates a new instance based on a public key of the Policy.
+func signatureVerifier(ctx context.Context, publicKey string) (sigstoreSig.Verifier, error) {
 	if strings.Contains(publicKey, "-----BEGIN PUBLIC KEY-----") {
 		verifier, err := cosignSig.LoadPublicKeyRaw([]byte(publicKey), crypto.SHA256)
 		if err != nil {
@@ -519,8 +881,15 @@ func signatureVerifier(ctx context.Context, p *policy) (sigstoreSig.Verifier, er
 		return verifier, nil
 	}
 
+	if isKMSRef(publicKey) {
+		log.Debugf("Fetching the public key %s from the KMS", publicKey)
+	}
+
 	verifier, err := newSignatureClient(ctx).publicKeyFromKeyRef(ctx, publicKey)
 	if err != nil {
+		if isKMSRef(publicKey) {
+			return nil, fmt.Errorf("unable to fetch the public key %s from the KMS: %w", publicKey, err)
+		}
 		return nil, err
 	}
 	return verifier, nil
@@ -542,8 +911,14 @@ func validateIdentity(identity cosign.Identity) error {
 	return errs
 }
 
+// policySchema is the schema of the EnterpriseContractPolicy spec, compiled
+// once for all the policies validated against it
+var policySchema = sync.OnceValues(func() (*jsonschema.Schema, error) {
+	return jsonschema.CompileString("schema.json", ecc.Schema)
+})
+
 func validatePolicyConfig(policyConfig string) error {
-	policySchema, err := jsonschema.CompileString("schema.json", ecc.Schema)
+	schema, err := policySchema()
 	if err != nil {
 		log.Errorf("Failed to compile schema: %s", err)
 		return err
@@ -566,7 +941,7 @@ func validatePolicyConfig(policyConfig string) error {
 	}
 
 	// Validate the policy against the schema.
-	if err := policySchema.Validate(v); err != nil {
+	if err := schema.Validate(v); err != nil {
 		log.Error(err)
 		return err
 	}
@@ -255,12 +255,22 @@ func TestNewPolicyFailures(t *testing.T) {
 
 type FakeCosignClient struct {
 	publicKey string
+	// mirror and root record the TUF repository initialized
+	mirror string
+	root   []byte
+	tufErr error
 }
 
 func (c *FakeCosignClient) publicKeyFromKeyRef(context.Context, string) (sigstoreSig.Verifier, error) {
 	return cosignSig.LoadPublicKeyRaw([]byte(c.publicKey), crypto.SHA256)
 }
 
+func (c *FakeCosignClient) initializeTUF(_ context.Context, mirror string, root []byte) error {
+	c.mirror = mirror
+	c.root = root
+	return c.tufErr
+}
+
 func TestCheckOpts(t *testing.T) {
 	cases := []struct {
 		name            string
@@ -271,6 +281,8 @@ func TestCheckOpts(t *testing.T) {
 		remotePublicKey string
 		identity        cosign.Identity
 		expectKeyless   bool
+		skipCertChecks  bool
+		noTrustBundle   bool
 		err             string
 	}{
 		{
@@ -287,6 +299,16 @@ func TestCheckOpts(t *testing.T) {
 			publicKey:       "k8s://test/cosign-public-key",
 			remotePublicKey: utils.TestPublicKey,
 		},
+		{
+			name:            "KMS public key",
+			publicKey:       "awskms:///arn:aws:kms:us-east-1:123456789012:key/cosign",
+			remotePublicKey: utils.TestPublicKey,
+		},
+		{
+			name:      "unavailable KMS public key",
+			publicKey: "gcpkms://projects/p/locations/global/keyRings/r/cryptoKeys/cosign",
+			err:       "unable to fetch the public key gcpkms://projects/p/locations/global/keyRings/r/cryptoKeys/cosign from the KMS",
+		},
 		{
 			name:      "with rekor public key",
 			rekorUrl:  utils.TestRekorURL,
@@ -315,6 +337,37 @@ func TestCheckOpts(t *testing.T) {
 				Subject: "my-subject",
 			},
 		},
+		{
+			name:           "keyless skipping certificate checks offline",
+			ignoreRekor:    true,
+			expectKeyless:  true,
+			skipCertChecks: true,
+			identity: cosign.Identity{
+				Issuer:  "my-issuer",
+				Subject: "my-subject",
+			},
+		},
+		{
+			name:           "keyless skipping certificate checks online",
+			rekorUrl:       utils.TestRekorURL,
+			skipCertChecks: true,
+			identity: cosign.Identity{
+				Issuer:  "my-issuer",
+				Subject: "my-subject",
+			},
+			err: "the certificate chain and SCT checks can only be skipped in offline mode",
+		},
+		{
+			name:           "keyless skipping certificate checks without trust bundle",
+			ignoreRekor:    true,
+			skipCertChecks: true,
+			noTrustBundle:  true,
+			identity: cosign.Identity{
+				Issuer:  "my-issuer",
+				Subject: "my-subject",
+			},
+			err: "with a trust bundle loaded via the SIGSTORE_ROOT_FILE environment variable",
+		},
 		{
 			name:          "keyless with regexp issuer",
 			rekorUrl:      utils.TestRekorURL,
@@ -385,14 +438,18 @@ func TestCheckOpts(t *testing.T) {
 			utils.SetTestRekorPublicKey(t)
 			utils.SetTestFulcioRoots(t)
 			utils.SetTestCTLogPublicKey(t)
+			if c.noTrustBundle {
+				t.Setenv("SIGSTORE_ROOT_FILE", "")
+			}
 
 			p, err := NewPolicy(ctx, Options{
-				PolicyRef:     c.policyRef,
-				RekorURL:      c.rekorUrl,
-				IgnoreRekor:   c.ignoreRekor,
-				PublicKey:     c.publicKey,
-				EffectiveTime: Now,
-				Identity:      c.identity,
+				PolicyRef:             c.policyRef,
+				RekorURL:              c.rekorUrl,
+				IgnoreRekor:           c.ignoreRekor,
+				PublicKey:             c.publicKey,
+				EffectiveTime:         Now,
+				Identity:              c.identity,
+				SkipCertificateChecks: c.skipCertChecks,
 			})
 			if c.err != "" {
 				assert.Empty(t, p)
@@ -427,6 +484,7 @@ func TestCheckOpts(t *testing.T) {
 				assert.NotEmpty(t, opts.RootCerts)
 				assert.NotEmpty(t, opts.IntermediateCerts)
 				assert.NotEmpty(t, opts.CTLogPubKeys)
+				assert.Equal(t, c.skipCertChecks, opts.IgnoreSCT)
 			} else {
 				assert.NotEmpty(t, opts.SigVerifier)
 				assert.Empty(t, opts.Identities)
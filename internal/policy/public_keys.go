@@ -0,0 +1,127 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package policy
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	sigstoreSig "github.com/sigstore/sigstore/pkg/signature"
+)
+
+// publicKeysKey is the key of the policy spec holding the list of trusted
+// public keys, it is not part of the EnterpriseContractPolicy schema
+const publicKeysKey = "publicKeys"
+
+// PublicKey is one of the public keys trusted to sign the images, as with
+// publicKey it is either the key in PEM format or a reference to it. The key
+// is valid only from and until the given times, in RFC3339 format, allowing
+// overlapping keys when rotating them. The validity is compared with the time
+// of each signature, see ValidAt. When repositories are given the key applies
+// only to the images of the matching repositories, a repository ending with
+// "*" matches all the repositories with the preceding prefix.
+type PublicKey struct {
+	Key          string   `json:"key"`
+	From         string   `json:"from,omitempty"`
+	Until        string   `json:"until,omitempty"`
+	Repositories []string `json:"repositories,omitempty"`
+	verifier     sigstoreSig.Verifier
+}
+
+// Verifier returns the verifier of the signatures made with the key, set once
+// the key is resolved by NewPolicy
+func (k PublicKey) Verifier() sigstoreSig.Verifier {
+	return k.verifier
+}
+
+// AppliesTo returns true if the key is trusted to sign the images of the
+// repository
+func (k PublicKey) AppliesTo(repository string) bool {
+	return len(k.Repositories) == 0 || matchesPattern(k.Repositories, repository)
+}
+
+// ValidAt returns true if the key is valid at the given time, i.e. the time a
+// signature made with the key was recorded in the transparency log. When that
+// time isn't known, the effective time of the policy is used instead.
+func (k PublicKey) ValidAt(at time.Time) bool {
+	// the times are checked when the policy is loaded
+	if from, err := time.Parse(time.RFC3339, k.From); err == nil && at.Before(from) {
+		return false
+	}
+	if until, err := time.Parse(time.RFC3339, k.Until); err == nil && !at.Before(until) {
+		return false
+	}
+
+	return true
+}
+
+func (k PublicKey) validate() error {
+	if k.Key == "" {
+		return errors.New("the key is required")
+	}
+
+	var from, until time.Time
+	var err error
+	if k.From != "" {
+		if from, err = time.Parse(time.RFC3339, k.From); err != nil {
+			return fmt.Errorf("invalid from time %q, expecting RFC3339 format", k.From)
+		}
+	}
+	if k.Until != "" {
+		if until, err = time.Parse(time.RFC3339, k.Until); err != nil {
+			return fmt.Errorf("invalid until time %q, expecting RFC3339 format", k.Until)
+		}
+	}
+	if !from.IsZero() && !until.IsZero() && !from.Before(until) {
+		return fmt.Errorf("the from time %s is not before the until time %s", k.From, k.Until)
+	}
+
+	return nil
+}
+
+// splitPublicKeys removes the publicKeys key from the policy configuration, in
+// JSON or YAML format, returning the configuration without it and the public
+// keys. The configuration is returned as is when it doesn't list public keys.
+func splitPublicKeys(policyConfig string) (string, []PublicKey, error) {
+	rest, value, err := splitKey(policyConfig, publicKeysKey)
+	if err != nil || value == nil {
+		return rest, nil, err
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var keys []PublicKey
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.DisallowUnknownFields()
+	if err := d.Decode(&keys); err != nil {
+		return "", nil, fmt.Errorf("invalid %s, expecting a list of keys with the key, from, until and repositories, got: %s", publicKeysKey, data)
+	}
+
+	for i, k := range keys {
+		if err := k.validate(); err != nil {
+			return "", nil, fmt.Errorf("invalid %s, key %d: %w", publicKeysKey, i, err)
+		}
+	}
+
+	return rest, keys, nil
+}
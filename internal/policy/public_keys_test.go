@@ -0,0 +1,222 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package policy
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+func TestSplitPublicKeys(t *testing.T) {
+	cases := []struct {
+		name   string
+		config string
+		rest   string
+		keys   []PublicKey
+		err    string
+	}{
+		{
+			name:   "none",
+			config: `{"sources": []}`,
+			rest:   `{"sources": []}`,
+		},
+		{
+			name:   "spec",
+			config: "publicKeys:\n- key: k8s://test/old\n  until: '2024-06-01T00:00:00Z'\n- key: k8s://test/new\n  from: '2024-05-01T00:00:00Z'\n  repositories: [registry.io/org/*]\nname: spec",
+			rest:   `{"name":"spec"}`,
+			keys: []PublicKey{
+				{Key: "k8s://test/old", Until: "2024-06-01T00:00:00Z"},
+				{Key: "k8s://test/new", From: "2024-05-01T00:00:00Z", Repositories: []string{"registry.io/org/*"}},
+			},
+		},
+		{
+			name:   "resource",
+			config: `{"apiVersion": "appstudio.redhat.com/v1alpha1", "spec": {"publicKeys": [{"key": "k8s://test/key"}]}}`,
+			rest:   `{"apiVersion":"appstudio.redhat.com/v1alpha1","spec":{}}`,
+			keys:   []PublicKey{{Key: "k8s://test/key"}},
+		},
+		{
+			name:   "not a list",
+			config: `{"publicKeys": "k8s://test/key"}`,
+			err:    `invalid publicKeys, expecting a list of keys with the key, from, until and repositories, got: "k8s://test/key"`,
+		},
+		{
+			name:   "missing key",
+			config: `{"publicKeys": [{"key": "k8s://test/key"}, {"from": "2024-05-01T00:00:00Z"}]}`,
+			err:    "invalid publicKeys, key 1: the key is required",
+		},
+		{
+			name:   "invalid time",
+			config: `{"publicKeys": [{"key": "k8s://test/key", "until": "2024-06-01"}]}`,
+			err:    `invalid publicKeys, key 0: invalid until time "2024-06-01", expecting RFC3339 format`,
+		},
+		{
+			name:   "empty window",
+			config: `{"publicKeys": [{"key": "k8s://test/key", "from": "2024-06-01T00:00:00Z", "until": "2024-05-01T00:00:00Z"}]}`,
+			err:    "invalid publicKeys, key 0: the from time 2024-06-01T00:00:00Z is not before the until time 2024-05-01T00:00:00Z",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			rest, keys, err := splitPublicKeys(c.config)
+			if c.err != "" {
+				assert.EqualError(t, err, c.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, c.rest, rest)
+			assert.Equal(t, c.keys, keys)
+		})
+	}
+}
+
+func TestPublicKeyAppliesTo(t *testing.T) {
+	key := PublicKey{
+		Key:          "k8s://test/key",
+		Repositories: []string{"registry.io/org/*", "registry.io/other/image"},
+	}
+
+	cases := []struct {
+		repository string
+		applies    bool
+	}{
+		{"registry.io/org/image", true},
+		{"registry.io/other/image", true},
+		{"registry.io/other/image2", false},
+		{"quay.io/org/image", false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.repository, func(t *testing.T) {
+			assert.Equal(t, c.applies, key.AppliesTo(c.repository))
+		})
+	}
+
+	assert.True(t, PublicKey{Key: "k8s://test/key"}.AppliesTo("registry.io/any/image"))
+}
+
+func TestPublicKeyValidAt(t *testing.T) {
+	key := PublicKey{
+		Key:   "k8s://test/key",
+		From:  "2024-05-01T00:00:00Z",
+		Until: "2024-06-01T00:00:00Z",
+	}
+
+	cases := []struct {
+		at    string
+		valid bool
+	}{
+		{"2024-05-01T00:00:00Z", true},
+		{"2024-05-15T00:00:00Z", true},
+		{"2024-04-30T23:59:59Z", false},
+		{"2024-06-01T00:00:00Z", false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.at, func(t *testing.T) {
+			at, err := time.Parse(time.RFC3339, c.at)
+			require.NoError(t, err)
+			assert.Equal(t, c.valid, key.ValidAt(at))
+		})
+	}
+
+	assert.True(t, PublicKey{Key: "k8s://test/key"}.ValidAt(time.Now()))
+}
+
+func TestNewPolicyPublicKeys(t *testing.T) {
+	config, err := json.Marshal(map[string]any{
+		"publicKey":  utils.TestPublicKey,
+		"publicKeys": []map[string]any{{"key": utils.TestPublicKey, "from": "2024-05-01T00:00:00Z"}},
+	})
+	require.NoError(t, err)
+
+	p, err := NewPolicy(context.Background(), Options{
+		PolicyRef:     string(config),
+		IgnoreRekor:   true,
+		EffectiveTime: Now,
+	})
+	require.NoError(t, err)
+
+	assert.False(t, p.Keyless())
+	keys := p.PublicKeys()
+	require.Len(t, keys, 2)
+	// the public key of the policy applies at any time
+	assert.Equal(t, utils.TestPublicKey, keys[0].Key)
+	assert.Empty(t, keys[0].From)
+	assert.Equal(t, "2024-05-01T00:00:00Z", keys[1].From)
+	for _, k := range keys {
+		assert.NotNil(t, k.Verifier())
+	}
+
+	pem, err := p.PublicKeyPEM()
+	require.NoError(t, err)
+	assert.Equal(t, 2, strings.Count(string(pem), "BEGIN PUBLIC KEY"))
+
+	// the public key given in the options is used instead
+	p, err = NewPolicy(context.Background(), Options{
+		PolicyRef:     string(config),
+		PublicKey:     utils.TestPublicKey,
+		IgnoreRekor:   true,
+		EffectiveTime: Now,
+	})
+	require.NoError(t, err)
+	assert.Nil(t, p.PublicKeys())
+
+	// without any public key the keyless workflow is used
+	_, err = NewPolicy(context.Background(), Options{
+		PolicyRef:     `{"publicKeys": []}`,
+		IgnoreRekor:   true,
+		EffectiveTime: Now,
+	})
+	assert.ErrorContains(t, err, "certificate OIDC issuer must be provided for keyless workflow")
+}
+
+func TestNewPolicyPublicKeysExtends(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	ctx := utils.WithFS(context.Background(), fs)
+
+	require.NoError(t, afero.WriteFile(fs, "base.yaml", []byte(`
+publicKeys:
+  - key: k8s://test/base
+sources:
+  - policy: [oci::quay.io/org/policy:v1]
+`), 0600))
+
+	p, err := NewInertPolicy(ctx, `{"extends": "file:base.yaml", "publicKeys": [{"key": "k8s://test/team"}]}`)
+	require.NoError(t, err)
+
+	assert.Equal(t, []PublicKey{{Key: "k8s://test/team"}, {Key: "k8s://test/base"}}, p.PublicKeys())
+}
+
+func TestValidatePolicyPublicKeys(t *testing.T) {
+	ctx := utils.WithFS(context.Background(), afero.NewMemMapFs())
+
+	assert.NoError(t, ValidatePolicy(ctx, `{"sources": [{"policy": ["github.com/org/policy"]}], "publicKeys": [{"key": "k8s://test/key"}]}`))
+	assert.ErrorContains(t, ValidatePolicy(ctx, `{"publicKeys": [{"key": ""}]}`), "invalid publicKeys, key 0: the key is required")
+}
@@ -0,0 +1,209 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package policy
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+
+	"github.com/enterprise-contract/ec-cli/internal/kubernetes"
+	"github.com/enterprise-contract/ec-cli/internal/policy/source"
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+// RefKind determines how the policy configuration is resolved from a policy
+// reference
+type RefKind string
+
+const (
+	// InlineRef is a policy configuration given as JSON or YAML
+	InlineRef RefKind = "inline"
+	// ClusterRef is an EnterpriseContractPolicy resource in the cluster
+	ClusterRef RefKind = "cluster"
+	// FileRef is a file holding the policy configuration
+	FileRef RefKind = "file"
+	// ConfigMapRef is a key of a ConfigMap in the cluster holding the policy
+	// configuration
+	ConfigMapRef RefKind = "configmap"
+	// OCIRef is an OCI artifact holding the policy configuration
+	OCIRef RefKind = "oci"
+)
+
+const (
+	filePrefix      = "file:"
+	configMapPrefix = "configmap:"
+	configMapURL    = "configmap://"
+	ociPrefix       = "oci::"
+
+	// defaultConfigMapKey is the ConfigMap key holding the policy
+	// configuration when none is specified
+	defaultConfigMapKey = "policy.yaml"
+)
+
+// Ref is a parsed policy reference
+type Ref struct {
+	Kind RefKind
+	// Value holds the policy configuration of inline references, the path of
+	// file references, or the URL of OCI references
+	Value string
+	// Name and Namespace of cluster and ConfigMap references, an empty
+	// namespace stands for the current namespace
+	Name      string
+	Namespace string
+	// Key of the ConfigMap holding the policy configuration
+	Key string
+}
+
+// namespacedName returns the name of cluster and ConfigMap references in the
+// [<namespace>/]<name> format
+func (r Ref) namespacedName() string {
+	if r.Namespace == "" {
+		return r.Name
+	}
+
+	return r.Namespace + "/" + r.Name
+}
+
+func (r Ref) String() string {
+	switch r.Kind {
+	case ClusterRef:
+		return r.namespacedName()
+	case ConfigMapRef:
+		return configMapPrefix + r.namespacedName() + "#" + r.Key
+	case FileRef:
+		return filePrefix + r.Value
+	}
+
+	return r.Value
+}
+
+// ParseRef parses the policy reference given in one of the forms:
+//   - name, or name@namespace, or namespace/name of an EnterpriseContractPolicy
+//     resource in the cluster
+//   - file:path of a file holding the policy configuration
+//   - configmap:name[@namespace][#key], or configmap://namespace/name[/key], of
+//     a ConfigMap holding the policy configuration in the given key, by default
+//     policy.yaml
+//   - oci::reference of an OCI artifact holding the policy configuration
+//   - the policy configuration in JSON or YAML format
+//
+// When the namespace is omitted the current namespace is used when fetching
+// the resource from the cluster.
+func ParseRef(ref string) (Ref, error) {
+	switch {
+	case strings.HasPrefix(ref, ociPrefix):
+		return Ref{Kind: OCIRef, Value: ref}, nil
+	case strings.HasPrefix(ref, filePrefix):
+		path := strings.TrimPrefix(ref, filePrefix)
+		if path == "" {
+			return Ref{}, fmt.Errorf("invalid policy reference %q, expecting file:path", ref)
+		}
+		return Ref{Kind: FileRef, Value: path}, nil
+	case strings.HasPrefix(ref, configMapURL):
+		parts := strings.Split(strings.TrimPrefix(ref, configMapURL), "/")
+		if len(parts) < 2 || len(parts) > 3 || slices.Contains(parts, "") {
+			return Ref{}, fmt.Errorf("invalid policy reference %q, expecting configmap://namespace/name[/key]", ref)
+		}
+		key := defaultConfigMapKey
+		if len(parts) == 3 {
+			key = parts[2]
+		}
+		return Ref{Kind: ConfigMapRef, Name: parts[1], Namespace: parts[0], Key: key}, nil
+	case strings.HasPrefix(ref, configMapPrefix):
+		name, key, _ := strings.Cut(strings.TrimPrefix(ref, configMapPrefix), "#")
+		if name == "" {
+			return Ref{}, fmt.Errorf("invalid policy reference %q, expecting configmap:name[@namespace][#key]", ref)
+		}
+		if key == "" {
+			key = defaultConfigMapKey
+		}
+		n, err := kubernetes.ParseNamespacedName(name)
+		if err != nil {
+			return Ref{}, err
+		}
+		return Ref{Kind: ConfigMapRef, Name: n.Name, Namespace: n.Namespace, Key: key}, nil
+	case strings.ContainsAny(ref, ":\n"): // Should detect JSON or YAML objects 🤞
+		return Ref{Kind: InlineRef, Value: ref}, nil
+	}
+
+	n, err := kubernetes.ParseNamespacedName(ref)
+	if err != nil {
+		return Ref{}, err
+	}
+
+	return Ref{Kind: ClusterRef, Name: n.Name, Namespace: n.Namespace}, nil
+}
+
+// resolveRef returns the policy configuration, in JSON or YAML format, of the
+// file, ConfigMap and OCI references
+func resolveRef(ctx context.Context, ref Ref) (string, error) {
+	switch ref.Kind {
+	case FileRef:
+		log.Debugf("Reading policy configuration from file %s", ref.Value)
+		data, err := afero.ReadFile(utils.FS(ctx), ref.Value)
+		if err != nil {
+			return "", fmt.Errorf("unable to read policy configuration: %w", err)
+		}
+		return string(data), nil
+	case ConfigMapRef:
+		log.Debugf("Reading policy configuration from config map %s", ref)
+		k8s, err := kubernetes.NewClient(ctx)
+		if err != nil {
+			return "", fmt.Errorf("cannot initialize Kubernetes client: %w", err)
+		}
+		cm, err := k8s.FetchConfigMap(ctx, ref.namespacedName())
+		if err != nil {
+			return "", fmt.Errorf("unable to fetch ConfigMap: %w", err)
+		}
+		data, ok := cm.Data[ref.Key]
+		if !ok {
+			keys := make([]string, 0, len(cm.Data))
+			for k := range cm.Data {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			return "", fmt.Errorf("the ConfigMap %s has no %q key, found keys: %s", ref.namespacedName(), ref.Key, strings.Join(keys, ", "))
+		}
+		return data, nil
+	case OCIRef:
+		log.Debugf("Fetching policy configuration from %s", ref.Value)
+		fs := utils.FS(ctx)
+		tmpDir, err := utils.CreateWorkDir(fs)
+		if err != nil {
+			return "", err
+		}
+		defer utils.CleanupWorkDir(fs, tmpDir)
+
+		configFile, err := source.GoGetterDownload(ctx, tmpDir, ref.Value)
+		if err != nil {
+			return "", err
+		}
+		data, err := afero.ReadFile(fs, configFile)
+		if err != nil {
+			return "", fmt.Errorf("unable to read policy configuration: %w", err)
+		}
+		return string(data), nil
+	}
+
+	return ref.Value, nil
+}
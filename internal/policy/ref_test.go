@@ -0,0 +1,117 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package policy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/enterprise-contract/ec-cli/internal/kubernetes"
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+func TestParseRef(t *testing.T) {
+	cases := []struct {
+		ref      string
+		expected Ref
+		err      string
+	}{
+		{ref: "ec-policy", expected: Ref{Kind: ClusterRef, Name: "ec-policy"}},
+		{ref: "ec-policy@test", expected: Ref{Kind: ClusterRef, Name: "ec-policy", Namespace: "test"}},
+		{ref: "test/ec-policy", expected: Ref{Kind: ClusterRef, Name: "ec-policy", Namespace: "test"}},
+		{ref: "ec-policy@", err: `invalid reference "ec-policy@", expecting name@namespace`},
+		{ref: "@test", err: `invalid reference "@test", expecting name@namespace`},
+		{ref: "file:policy.yaml", expected: Ref{Kind: FileRef, Value: "policy.yaml"}},
+		{ref: "file:", err: `invalid policy reference "file:", expecting file:path`},
+		{ref: "configmap:config", expected: Ref{Kind: ConfigMapRef, Name: "config", Key: "policy.yaml"}},
+		{ref: "configmap:config@test", expected: Ref{Kind: ConfigMapRef, Name: "config", Namespace: "test", Key: "policy.yaml"}},
+		{ref: "configmap:config@test#ec.json", expected: Ref{Kind: ConfigMapRef, Name: "config", Namespace: "test", Key: "ec.json"}},
+		{ref: "configmap:test/config#ec.json", expected: Ref{Kind: ConfigMapRef, Name: "config", Namespace: "test", Key: "ec.json"}},
+		{ref: "configmap://test/config", expected: Ref{Kind: ConfigMapRef, Name: "config", Namespace: "test", Key: "policy.yaml"}},
+		{ref: "configmap://test/config/ec.json", expected: Ref{Kind: ConfigMapRef, Name: "config", Namespace: "test", Key: "ec.json"}},
+		{ref: "configmap://config", err: `invalid policy reference "configmap://config", expecting configmap://namespace/name[/key]`},
+		{ref: "configmap://test//ec.json", err: `invalid policy reference "configmap://test//ec.json", expecting configmap://namespace/name[/key]`},
+		{ref: "configmap://test/config/ec.json/x", err: `invalid policy reference "configmap://test/config/ec.json/x", expecting configmap://namespace/name[/key]`},
+		{ref: "configmap:", err: `invalid policy reference "configmap:", expecting configmap:name[@namespace][#key]`},
+		{ref: "configmap:#key", err: `invalid policy reference "configmap:#key", expecting configmap:name[@namespace][#key]`},
+		{ref: "oci::quay.io/org/policy:latest", expected: Ref{Kind: OCIRef, Value: "oci::quay.io/org/policy:latest"}},
+		{ref: `{"publicKey": "key"}`, expected: Ref{Kind: InlineRef, Value: `{"publicKey": "key"}`}},
+		{ref: "---\npublicKey: key\n", expected: Ref{Kind: InlineRef, Value: "---\npublicKey: key\n"}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.ref, func(t *testing.T) {
+			got, err := ParseRef(c.ref)
+			if c.err != "" {
+				assert.EqualError(t, err, c.err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, c.expected, got)
+		})
+	}
+}
+
+func TestLoadPolicyFromRef(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "policy.yaml", []byte("publicKey: from-file\n"), 0400))
+
+	client := &FakeKubernetesClient{ConfigMap: corev1.ConfigMap{
+		Data: map[string]string{
+			"policy.yaml": "publicKey: from-config-map\n",
+			"ec.json":     `{"publicKey": "from-config-map-key"}`,
+		},
+	}}
+
+	ctx := utils.WithFS(context.Background(), fs)
+	ctx = kubernetes.WithClient(ctx, client)
+
+	cases := []struct {
+		ref       string
+		publicKey string
+		err       string
+	}{
+		{ref: "file:policy.yaml", publicKey: "from-file"},
+		{ref: "file:missing.yaml", err: "unable to read policy configuration"},
+		{ref: "configmap:config@test", publicKey: "from-config-map"},
+		{ref: "configmap:config@test#ec.json", publicKey: "from-config-map-key"},
+		{ref: "configmap://test/config/ec.json", publicKey: "from-config-map-key"},
+		{ref: "configmap:config@test#missing", err: `the ConfigMap test/config has no "missing" key, found keys: ec.json, policy.yaml`},
+		{ref: "ec-policy@", err: "invalid policy reference"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.ref, func(t *testing.T) {
+			p, err := NewInertPolicy(ctx, c.ref)
+			if c.err != "" {
+				assert.ErrorContains(t, err, c.err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, c.publicKey, p.Spec().PublicKey)
+		})
+	}
+}
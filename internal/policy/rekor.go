@@ -0,0 +1,44 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package policy
+
+import (
+	"fmt"
+)
+
+// ignoreRekorKey is the key of the policy spec skipping the Rekor transparency
+// log checks, as the --ignore-rekor flag does, it is not part of the
+// EnterpriseContractPolicy schema
+const ignoreRekorKey = "ignoreRekor"
+
+// splitIgnoreRekor removes the ignoreRekor key from the policy configuration,
+// in JSON or YAML format, returning the configuration without it and whether
+// the Rekor checks are skipped. The configuration is returned as is when it
+// doesn't set ignoreRekor.
+func splitIgnoreRekor(policyConfig string) (string, bool, error) {
+	rest, value, err := splitKey(policyConfig, ignoreRekorKey)
+	if err != nil || value == nil {
+		return rest, false, err
+	}
+
+	ignore, ok := value.(bool)
+	if !ok {
+		return "", false, fmt.Errorf("invalid %s, expecting a boolean, got: %v", ignoreRekorKey, value)
+	}
+
+	return rest, ignore, nil
+}
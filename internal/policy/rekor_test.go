@@ -0,0 +1,185 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package policy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+func TestSplitIgnoreRekor(t *testing.T) {
+	cases := []struct {
+		name   string
+		config string
+		rest   string
+		ignore bool
+		err    string
+	}{
+		{
+			name:   "none",
+			config: `{"sources": []}`,
+			rest:   `{"sources": []}`,
+		},
+		{
+			name:   "spec",
+			config: "ignoreRekor: true\nname: spec",
+			rest:   `{"name":"spec"}`,
+			ignore: true,
+		},
+		{
+			name:   "resource",
+			config: `{"apiVersion": "appstudio.redhat.com/v1alpha1", "spec": {"ignoreRekor": false}}`,
+			rest:   `{"apiVersion":"appstudio.redhat.com/v1alpha1","spec":{}}`,
+		},
+		{
+			name:   "not a boolean",
+			config: `{"ignoreRekor": "yes"}`,
+			err:    "invalid ignoreRekor, expecting a boolean, got: yes",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			rest, ignore, err := splitIgnoreRekor(c.config)
+			if c.err != "" {
+				assert.EqualError(t, err, c.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, c.rest, rest)
+			assert.Equal(t, c.ignore, ignore)
+		})
+	}
+}
+
+func TestNewPolicyRekor(t *testing.T) {
+	cases := []struct {
+		name           string
+		policyRef      string
+		rekorURL       string
+		offline        bool
+		inclusionProof bool
+		ignoreRekor    bool
+		err            string
+	}{
+		{
+			name:        "ignore Rekor in the policy",
+			policyRef:   `{"ignoreRekor": true}`,
+			ignoreRekor: true,
+		},
+		{
+			name:     "offline",
+			rekorURL: utils.TestRekorURL,
+			offline:  true,
+		},
+		{
+			name:           "inclusion proof",
+			rekorURL:       utils.TestRekorURL,
+			inclusionProof: true,
+		},
+		{
+			name:           "inclusion proof with the Rekor URL from the policy",
+			policyRef:      `{"rekorUrl": "` + utils.TestRekorURL + `"}`,
+			inclusionProof: true,
+		},
+		{
+			name:           "inclusion proof without Rekor URL",
+			inclusionProof: true,
+			err:            "the Rekor URL is needed to verify the inclusion proofs of the Rekor entries",
+		},
+		{
+			name:           "inclusion proof offline",
+			rekorURL:       utils.TestRekorURL,
+			offline:        true,
+			inclusionProof: true,
+			err:            "the inclusion proofs of the Rekor entries can't be verified in offline mode",
+		},
+		{
+			name:           "inclusion proof ignoring Rekor",
+			policyRef:      `{"ignoreRekor": true}`,
+			rekorURL:       utils.TestRekorURL,
+			inclusionProof: true,
+			err:            "the inclusion proofs of the Rekor entries can't be verified when ignoring Rekor",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctx := context.Background()
+			utils.SetTestRekorPublicKey(t)
+
+			p, err := NewPolicy(ctx, Options{
+				PolicyRef:           c.policyRef,
+				PublicKey:           utils.TestPublicKey,
+				RekorURL:            c.rekorURL,
+				RekorOffline:        c.offline,
+				RekorInclusionProof: c.inclusionProof,
+				EffectiveTime:       Now,
+			})
+			if c.err != "" {
+				assert.ErrorContains(t, err, c.err)
+				return
+			}
+			require.NoError(t, err)
+
+			assert.Equal(t, c.ignoreRekor, p.IgnoreRekor())
+			assert.Equal(t, c.inclusionProof, p.RekorInclusionProof())
+
+			opts, err := p.CheckOpts()
+			require.NoError(t, err)
+			assert.Equal(t, c.ignoreRekor, opts.IgnoreTlog)
+			assert.Equal(t, c.offline, opts.Offline)
+			if c.inclusionProof {
+				assert.NotNil(t, opts.RekorClient)
+			}
+			if c.offline {
+				assert.Nil(t, opts.RekorClient)
+			}
+		})
+	}
+}
+
+func TestNewPolicyIgnoreRekorExtends(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	ctx := utils.WithFS(context.Background(), fs)
+
+	require.NoError(t, afero.WriteFile(fs, "base.yaml", []byte(`
+ignoreRekor: true
+sources:
+  - policy: [oci::quay.io/org/policy:v1]
+`), 0600))
+
+	p, err := NewInertPolicy(ctx, `{"extends": "file:base.yaml"}`)
+	require.NoError(t, err)
+
+	assert.True(t, p.IgnoreRekor())
+}
+
+func TestValidatePolicyIgnoreRekor(t *testing.T) {
+	ctx := utils.WithFS(context.Background(), afero.NewMemMapFs())
+
+	assert.NoError(t, ValidatePolicy(ctx, `{"sources": [{"policy": ["github.com/org/policy"]}], "ignoreRekor": true}`))
+	assert.ErrorContains(t, ValidatePolicy(ctx, `{"ignoreRekor": 1}`), "invalid ignoreRekor")
+}
@@ -0,0 +1,207 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package source
+
+import (
+	"archive/tar"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+
+	"github.com/enterprise-contract/go-gather/metadata"
+	fileMetadata "github.com/enterprise-contract/go-gather/metadata/file"
+	gitMetadata "github.com/enterprise-contract/go-gather/metadata/git"
+	ociMetadata "github.com/enterprise-contract/go-gather/metadata/oci"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+
+	"github.com/enterprise-contract/ec-cli/internal/downloader"
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+// SourcesManifestFile is the name of the manifest file within the sources
+// archive
+const SourcesManifestFile = "manifest.json"
+
+// SourcesManifest describes the content of the sources archive
+type SourcesManifest struct {
+	Sources []SavedSource `json:"sources"`
+}
+
+// SavedSource describes a downloaded source within the sources archive
+type SavedSource struct {
+	// URL the source was downloaded from
+	URL string `json:"url"`
+	// Path of the source tree within the archive
+	Path string `json:"path"`
+	// Digest of the downloaded content, the commit for git sources or the
+	// image digest for OCI sources, if known
+	Digest string `json:"digest,omitempty"`
+}
+
+// downloaded is a source downloaded so far
+type downloaded struct {
+	url      string
+	dir      string
+	metadata metadata.Metadata
+}
+
+// SaveSources writes a tar archive to the given path containing all sources
+// downloaded so far, along with a manifest of the source URLs and digests.
+func SaveSources(ctx context.Context, archivePath string) error {
+	fs := utils.FS(ctx)
+
+	var sources []downloaded
+	DownloadsOf(ctx).cache.Range(func(key, value any) bool {
+		dir, c := value.(func() (string, cacheContent))()
+		if c.err != nil {
+			return true
+		}
+
+		if exists, err := afero.DirExists(fs, dir); err != nil || !exists {
+			log.Debugf("Downloaded source %s is no longer present in %s, not saving", downloader.Redact(c.sourceUrl), dir)
+			return true
+		}
+
+		sources = append(sources, downloaded{url: c.sourceUrl, dir: dir, metadata: c.metadata})
+		return true
+	})
+
+	// Ensure some consistency in output.
+	sort.Slice(sources, func(i, j int) bool {
+		return sources[i].url < sources[j].url
+	})
+
+	f, err := fs.Create(archivePath)
+	if err != nil {
+		return fmt.Errorf("unable to create sources archive: %w", err)
+	}
+
+	if err := writeSources(fs, f, sources); err != nil {
+		// the error writing the archive takes precedence
+		_ = f.Close()
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("unable to write sources archive: %w", err)
+	}
+
+	return nil
+}
+
+// writeSources writes the tar archive of the sources, and their manifest, to
+// the given writer
+func writeSources(fs afero.Fs, w io.Writer, sources []downloaded) error {
+	ar := tar.NewWriter(w)
+
+	manifest := SourcesManifest{Sources: make([]SavedSource, 0, len(sources))}
+	for i, s := range sources {
+		archiveDir := path.Join("sources", fmt.Sprintf("%d", i))
+		if err := addDirToArchive(fs, ar, s.dir, archiveDir); err != nil {
+			return fmt.Errorf("unable to add source %s to archive: %w", downloader.Redact(s.url), err)
+		}
+
+		manifest.Sources = append(manifest.Sources, SavedSource{
+			URL:    downloader.Redact(s.url),
+			Path:   archiveDir,
+			Digest: digestOf(s.metadata),
+		})
+	}
+
+	m, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := ar.WriteHeader(&tar.Header{
+		Name: SourcesManifestFile,
+		Mode: 0644,
+		Size: int64(len(m)),
+	}); err != nil {
+		return err
+	}
+
+	if _, err := ar.Write(m); err != nil {
+		return err
+	}
+
+	if err := ar.Close(); err != nil {
+		return fmt.Errorf("unable to write sources archive: %w", err)
+	}
+
+	return nil
+}
+
+// addDirToArchive adds all regular files from the given directory to the
+// archive under the archiveDir prefix. The .git directory is not included.
+func addDirToArchive(fs afero.Fs, ar *tar.Writer, dir string, archiveDir string) error {
+	return afero.Walk(fs, dir, func(file string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() && info.Name() == ".git" {
+			return filepath.SkipDir
+		}
+
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, file)
+		if err != nil {
+			return err
+		}
+
+		if err := ar.WriteHeader(&tar.Header{
+			Name:    path.Join(archiveDir, filepath.ToSlash(rel)),
+			Mode:    int64(info.Mode().Perm()),
+			Size:    info.Size(),
+			ModTime: info.ModTime(),
+		}); err != nil {
+			return err
+		}
+
+		src, err := fs.Open(file)
+		if err != nil {
+			return err
+		}
+		defer src.Close()
+
+		_, err = io.Copy(ar, src)
+		return err
+	})
+}
+
+func digestOf(m metadata.Metadata) string {
+	switch m := m.(type) {
+	case *gitMetadata.GitMetadata:
+		return m.LatestCommit
+	case *ociMetadata.OCIMetadata:
+		return m.Digest
+	case *fileMetadata.FileMetadata:
+		return m.SHA
+	}
+
+	return ""
+}
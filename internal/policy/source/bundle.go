@@ -0,0 +1,145 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package source
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/open-policy-agent/opa/bundle"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+
+	"github.com/enterprise-contract/ec-cli/internal/downloader"
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+const bundleVerificationKey key = 1
+
+const signaturesFile = ".signatures.json"
+
+// bundleKeyID is the identifier the verification key is registered under, it
+// takes precedence over the key identifier given in the signature
+const bundleKeyID = "ec"
+
+// DefaultBundleSigningAlg is the algorithm the bundles are expected to be
+// signed with unless another one is given, the same default as OPA's
+const DefaultBundleSigningAlg = "RS256"
+
+// NewBundleVerificationConfig returns the configuration for verifying the
+// signatures of OPA bundles signed with the private key of the given PEM
+// encoded public key using the given algorithm, e.g. RS256 or ES256
+func NewBundleVerificationConfig(publicKey []byte, alg string) *bundle.VerificationConfig {
+	return bundle.NewVerificationConfig(map[string]*bundle.KeyConfig{
+		bundleKeyID: {Key: string(publicKey), Algorithm: alg},
+	}, bundleKeyID, "", nil)
+}
+
+// WithBundleVerification returns a context under which the policy and data
+// sources are required to be OPA bundles signed as described in the OPA bundle
+// specification, i.e. with the .signatures.json file holding the JWT signing
+// the hashes of all files within the bundle. The signature is verified, using
+// the given configuration, once the source is downloaded and before it is
+// used. Sources that are not signed bundles, or have been tampered with, fail
+// to download. Configuration sources are not affected.
+func WithBundleVerification(ctx context.Context, config *bundle.VerificationConfig) context.Context {
+	return context.WithValue(ctx, bundleVerificationKey, config)
+}
+
+func bundleVerification(ctx context.Context) *bundle.VerificationConfig {
+	if config, ok := ctx.Value(bundleVerificationKey).(*bundle.VerificationConfig); ok {
+		return config
+	}
+
+	return nil
+}
+
+// verifyBundle verifies the signature of the OPA bundle in the given directory
+// downloaded from the source URL, all files within the bundle, apart from the
+// .git directory, must be signed. The modules are not parsed, as the reader of
+// OPA bundles does, so they can be prepared for the evaluation as configured.
+func verifyBundle(ctx context.Context, sourceUrl, dir string, config *bundle.VerificationConfig) error {
+	afs := utils.FS(ctx)
+	redacted := downloader.Redact(sourceUrl)
+
+	signatures, err := afero.ReadFile(afs, filepath.Join(dir, signaturesFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("the source %s is not a signed bundle, the %s file is missing", redacted, signaturesFile)
+		}
+		return err
+	}
+
+	var sc bundle.SignaturesConfig
+	if err := json.Unmarshal(signatures, &sc); err != nil {
+		return fmt.Errorf("unable to parse the %s file of the bundle %s: %w", signaturesFile, redacted, err)
+	}
+
+	files, err := bundle.VerifyBundleSignature(sc, config)
+	if err != nil {
+		return fmt.Errorf("verifying the signature of the bundle %s: %w", redacted, err)
+	}
+
+	// the directory can be a symlink, fs.WalkDir follows the root symlink
+	fsys := afero.NewIOFS(afero.NewBasePathFs(afs, dir))
+	if err := fs.WalkDir(fsys, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() {
+			if path == ".git" {
+				return fs.SkipDir
+			}
+			return nil
+		}
+
+		if path == signaturesFile {
+			return nil
+		}
+
+		content, err := fs.ReadFile(fsys, path)
+		if err != nil {
+			return err
+		}
+
+		return bundle.VerifyBundleFile(path, *bytes.NewBuffer(content), files)
+	}); err != nil {
+		return fmt.Errorf("verifying the signature of the bundle %s: %w", redacted, err)
+	}
+
+	// the verified files are removed from files
+	if len(files) > 0 {
+		missing := make([]string, 0, len(files))
+		for f := range files {
+			missing = append(missing, f)
+		}
+		sort.Strings(missing)
+		return fmt.Errorf("verifying the signature of the bundle %s: signed files not found in the bundle: %s", redacted, strings.Join(missing, ", "))
+	}
+
+	log.Debugf("Verified the signature of the bundle %s", redacted)
+
+	return nil
+}
@@ -0,0 +1,219 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package source
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"path/filepath"
+	"testing"
+
+	"github.com/open-policy-agent/opa/bundle"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+const bundleModule = `package main
+
+deny contains "fails" if { true }
+`
+
+// signingKey returns a PEM encoded RSA private and public key pair
+func signingKey(t *testing.T) (string, []byte) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	public, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	require.NoError(t, err)
+
+	return string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})),
+		pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: public})
+}
+
+// bundleFiles returns the files of a bundle, signed with the private key if
+// one is given
+func bundleFiles(t *testing.T, privateKey string) map[string][]byte {
+	b := bundle.Bundle{
+		Manifest: bundle.Manifest{Revision: "v1", Roots: &[]string{"main"}},
+		Data:     map[string]any{"main": map[string]any{"allowed": true}},
+		Modules:  []bundle.ModuleFile{{URL: "/main/main.rego", Path: "/main/main.rego", Raw: []byte(bundleModule)}},
+	}
+
+	manifest, err := json.Marshal(b.Manifest)
+	require.NoError(t, err)
+	data, err := json.Marshal(b.Data)
+	require.NoError(t, err)
+
+	files := map[string][]byte{
+		".manifest":      manifest,
+		"data.json":      data,
+		"main/main.rego": []byte(bundleModule),
+	}
+
+	if privateKey != "" {
+		require.NoError(t, b.GenerateSignature(bundle.NewSigningConfig(privateKey, DefaultBundleSigningAlg, ""), "", false))
+		signatures, err := json.Marshal(b.Signatures)
+		require.NoError(t, err)
+		files[".signatures.json"] = signatures
+	}
+
+	return files
+}
+
+func TestBundleVerification(t *testing.T) {
+	privateKey, publicKey := signingKey(t)
+	_, otherPublicKey := signingKey(t)
+
+	cases := []struct {
+		name   string
+		kind   policyKind
+		files  map[string][]byte
+		modify func(map[string][]byte)
+		key    []byte
+		err    string
+	}{
+		{
+			name:  "signed bundle",
+			kind:  PolicyKind,
+			files: bundleFiles(t, privateKey),
+			key:   publicKey,
+		},
+		{
+			name:  "signed data bundle",
+			kind:  DataKind,
+			files: bundleFiles(t, privateKey),
+			key:   publicKey,
+		},
+		{
+			name:  "unsigned bundle",
+			kind:  PolicyKind,
+			files: bundleFiles(t, ""),
+			key:   publicKey,
+			err:   "the source https://example.com/bundle.tar.gz is not a signed bundle, the .signatures.json file is missing",
+		},
+		{
+			name:  "not a bundle",
+			kind:  PolicyKind,
+			files: map[string][]byte{"main.rego": []byte(bundleModule)},
+			key:   publicKey,
+			err:   "the source https://example.com/bundle.tar.gz is not a signed bundle, the .signatures.json file is missing",
+		},
+		{
+			name:  "modified module",
+			kind:  PolicyKind,
+			files: bundleFiles(t, privateKey),
+			modify: func(files map[string][]byte) {
+				files["main/main.rego"] = []byte("package main\n")
+			},
+			key: publicKey,
+			err: "verifying the signature of the bundle https://example.com/bundle.tar.gz: main/main.rego: digest mismatch",
+		},
+		{
+			name:  "added module",
+			kind:  PolicyKind,
+			files: bundleFiles(t, privateKey),
+			modify: func(files map[string][]byte) {
+				files["main/other.rego"] = []byte("package main\n")
+			},
+			key: publicKey,
+			err: "verifying the signature of the bundle https://example.com/bundle.tar.gz: file main/other.rego not included in bundle signature",
+		},
+		{
+			name:  "removed data",
+			kind:  PolicyKind,
+			files: bundleFiles(t, privateKey),
+			modify: func(files map[string][]byte) {
+				delete(files, "data.json")
+			},
+			key: publicKey,
+			err: "verifying the signature of the bundle https://example.com/bundle.tar.gz: signed files not found in the bundle: data.json",
+		},
+		{
+			name:  "git directory is not verified",
+			kind:  PolicyKind,
+			files: bundleFiles(t, privateKey),
+			modify: func(files map[string][]byte) {
+				files[".git/HEAD"] = []byte("ref: refs/heads/main\n")
+			},
+			key: publicKey,
+		},
+		{
+			name:  "different key",
+			kind:  PolicyKind,
+			files: bundleFiles(t, privateKey),
+			key:   otherPublicKey,
+			err:   "verifying the signature of the bundle https://example.com/bundle.tar.gz: failed to verify message",
+		},
+		{
+			name:  "configuration is not verified",
+			kind:  ConfigKind,
+			files: map[string][]byte{"policy.yaml": []byte("sources: []")},
+			key:   publicKey,
+		},
+		{
+			name:  "verification not configured",
+			kind:  PolicyKind,
+			files: bundleFiles(t, ""),
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if c.modify != nil {
+				c.modify(c.files)
+			}
+
+			fs := afero.NewMemMapFs()
+			ctx := utils.WithFS(context.Background(), fs)
+			if c.key != nil {
+				ctx = WithBundleVerification(ctx, NewBundleVerificationConfig(c.key, DefaultBundleSigningAlg))
+			}
+
+			dl := mockDownloader{}
+			dl.On("Download", mock.Anything, mock.Anything, false).Run(func(args mock.Arguments) {
+				dest := args.String(0)
+				for name, content := range c.files {
+					require.NoError(t, afero.WriteFile(fs, filepath.Join(dest, name), content, 0644))
+				}
+			}).Return(nil)
+
+			// each case downloads the source anew, not from the download cache
+			downloadCache.Delete("https://example.com/bundle.tar.gz")
+			t.Cleanup(func() {
+				downloadCache.Delete("https://example.com/bundle.tar.gz")
+			})
+
+			p := PolicyUrl{Url: "https://example.com/bundle.tar.gz", Kind: c.kind}
+			_, err := p.GetPolicy(usingDownloader(ctx, &dl), "/work", false)
+			if c.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, c.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package source
+
+import (
+	"bufio"
+	"bytes"
+	"path/filepath"
+	"strings"
+
+	ecc "github.com/enterprise-contract/enterprise-contract-controller/api/v1alpha1"
+	"github.com/enterprise-contract/go-gather/metadata"
+	gitMetadata "github.com/enterprise-contract/go-gather/metadata/git"
+	ociMetadata "github.com/enterprise-contract/go-gather/metadata/oci"
+	"github.com/spf13/afero"
+
+	"github.com/enterprise-contract/ec-cli/internal/downloader"
+)
+
+// SourceInfo describes the content downloaded for a policy or data source
+type SourceInfo struct {
+	// Url of the source, without any credentials
+	Url string `json:"url"`
+	// Commit is the git commit the source was resolved to
+	Commit string `json:"commit,omitempty"`
+	// Digest is the digest of the OCI image the source was resolved to
+	Digest string `json:"digest,omitempty"`
+	// ContentDigest is the digest of the downloaded files, see downloader.Digest
+	ContentDigest string `json:"content-digest,omitempty"`
+}
+
+// DownloadedSources returns the information about the content downloaded for
+// each of the policy and data sources of the policy. Sources that were not
+// downloaded, or failed to download, are omitted, as are the sources downloaded
+// within Downloads set via WithDownloads.
+func DownloadedSources(spec ecc.EnterpriseContractPolicySpec) []SourceInfo {
+	var infos []SourceInfo
+	seen := map[string]bool{}
+	for _, s := range spec.Sources {
+		for _, url := range append(append([]string{}, s.Policy...), s.Data...) {
+			if seen[url] {
+				continue
+			}
+			seen[url] = true
+
+			dfn, ok := downloadCache.Load(url)
+			if !ok {
+				continue
+			}
+
+			_, c := dfn.(func() (string, cacheContent))()
+			if c.err != nil {
+				continue
+			}
+
+			infos = append(infos, c.info)
+		}
+	}
+
+	return infos
+}
+
+// sourceInfo gathers the information about the source downloaded into the
+// given directory from the metadata returned by the downloader, if any, and
+// from the downloaded content
+func sourceInfo(fs afero.Fs, sourceUrl, dir string, m metadata.Metadata) SourceInfo {
+	info := SourceInfo{Url: downloader.Redact(sourceUrl)}
+
+	switch m := m.(type) {
+	case *gitMetadata.GitMetadata:
+		info.Commit = m.LatestCommit
+	case *ociMetadata.OCIMetadata:
+		info.Digest = m.Digest
+	}
+
+	if info.Commit == "" {
+		info.Commit = gitCommit(fs, dir)
+	}
+
+	if digest, err := downloader.Digest(fs, dir); err == nil {
+		info.ContentDigest = digest
+	}
+
+	return info
+}
+
+// gitCommit returns the commit checked out in the git repository cloned into
+// the given directory, or an empty string if there is no git repository, e.g.
+// when only a subdirectory of the repository was downloaded
+func gitCommit(fs afero.Fs, dir string) string {
+	gitDir := filepath.Join(dir, ".git")
+	head, err := afero.ReadFile(fs, filepath.Join(gitDir, "HEAD"))
+	if err != nil {
+		return ""
+	}
+
+	ref, ok := strings.CutPrefix(strings.TrimSpace(string(head)), "ref: ")
+	if !ok {
+		// detached HEAD
+		return strings.TrimSpace(string(head))
+	}
+
+	if commit, err := afero.ReadFile(fs, filepath.Join(gitDir, filepath.FromSlash(ref))); err == nil {
+		return strings.TrimSpace(string(commit))
+	}
+
+	packed, err := afero.ReadFile(fs, filepath.Join(gitDir, "packed-refs"))
+	if err != nil {
+		return ""
+	}
+
+	s := bufio.NewScanner(bytes.NewReader(packed))
+	for s.Scan() {
+		if commit, name, ok := strings.Cut(s.Text(), " "); ok && name == ref {
+			return commit
+		}
+	}
+
+	return ""
+}
@@ -77,9 +77,42 @@ type PolicyUrl struct {
 // downloadCache is a concurrent map used to cache downloaded files.
 var downloadCache sync.Map
 
+const downloadsKey key = 2
+
+// Downloads holds the policy sources downloaded, each source is downloaded
+// once within the same Downloads. Unless set via WithDownloads, the downloads
+// are held for the lifetime of the process, which suits a single validation,
+// but not a server using policies whose sources change, or whose downloaded
+// files are removed, over time.
+type Downloads struct {
+	cache *sync.Map
+}
+
+// NewDownloads returns Downloads not holding any sources
+func NewDownloads() Downloads {
+	return Downloads{cache: &sync.Map{}}
+}
+
+// WithDownloads returns a context under which the policy sources are
+// downloaded within the given Downloads
+func WithDownloads(ctx context.Context, d Downloads) context.Context {
+	return context.WithValue(ctx, downloadsKey, d)
+}
+
+// DownloadsOf returns the Downloads the policy sources are downloaded within
+// under the context
+func DownloadsOf(ctx context.Context) Downloads {
+	if d, ok := ctx.Value(downloadsKey).(Downloads); ok && d.cache != nil {
+		return d
+	}
+
+	return Downloads{cache: &downloadCache}
+}
+
 type cacheContent struct {
 	sourceUrl string
 	metadata  metadata.Metadata
+	info      SourceInfo
 	err       error
 }
 
@@ -90,12 +123,15 @@ func getPolicyThroughCache(ctx context.Context, s PolicySource, workDir string,
 	// Load or store the downloaded policy file from the given source URL.
 	// If the file is already in the download cache, it is loaded from there.
 	// Otherwise, it is downloaded from the source URL and stored in the cache.
-	dfn, _ := downloadCache.LoadOrStore(sourceUrl, sync.OnceValues(func() (string, cacheContent) {
-		log.Debugf("Download cache miss: %s", sourceUrl)
+	dfn, _ := DownloadsOf(ctx).cache.LoadOrStore(sourceUrl, sync.OnceValues(func() (string, cacheContent) {
+		log.Debugf("Download cache miss: %s", downloader.Redact(sourceUrl))
 		// Checkout policy repo into work directory.
-		log.Debugf("Downloading policy files from source url %s to destination %s", sourceUrl, dest)
+		log.Debugf("Downloading policy files from source url %s to destination %s", downloader.Redact(sourceUrl), dest)
 		m, err := dl(sourceUrl, dest)
-		c := &cacheContent{sourceUrl, m, err}
+		c := &cacheContent{sourceUrl: sourceUrl, metadata: m, err: err}
+		if err == nil {
+			c.info = sourceInfo(utils.FS(ctx), sourceUrl, dest, m)
+		}
 		return dest, *c
 	}))
 
@@ -151,11 +187,25 @@ func getPolicyThroughCache(ctx context.Context, s PolicySource, workDir string,
 // GetPolicies clones the repository for a given PolicyUrl
 func (p *PolicyUrl) GetPolicy(ctx context.Context, workDir string, showMsg bool) (string, error) {
 	dl := func(source string, dest string) (metadata.Metadata, error) {
+		var m metadata.Metadata
+		var err error
 		x := ctx.Value(DownloaderFuncKey)
 		if dl, ok := x.(downloaderFunc); ok {
-			return dl.Download(ctx, dest, source, showMsg)
+			m, err = dl.Download(ctx, dest, source, showMsg)
+		} else {
+			m, err = downloader.Download(ctx, dest, source, showMsg)
 		}
-		return downloader.Download(ctx, dest, source, showMsg)
+		if err != nil {
+			return m, err
+		}
+
+		// the bundle is verified before it is used and before its files are
+		// modified, e.g. when preparing the rego version
+		if config := bundleVerification(ctx); config != nil && p.Kind != ConfigKind {
+			return m, verifyBundle(ctx, source, dest, config)
+		}
+
+		return m, nil
 	}
 
 	return getPolicyThroughCache(ctx, p, workDir, dl)
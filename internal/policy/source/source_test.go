@@ -258,3 +258,38 @@ func TestGetPolicyThroughCache(t *testing.T) {
 		test(t, afero.NewMemMapFs(), 2)
 	})
 }
+
+func TestGetPolicyThroughCacheWithDownloads(t *testing.T) {
+	fs := afero.NewBasePathFs(afero.NewOsFs(), t.TempDir())
+
+	invocations := 0
+	dl := func(source, dest string) (metadata.Metadata, error) {
+		invocations++
+		if err := fs.MkdirAll(dest, 0755); err != nil {
+			return nil, err
+		}
+
+		return nil, afero.WriteFile(fs, filepath.Join(dest, "p.rego"), []byte("package p"), 0400)
+	}
+
+	// the source is downloaded once within each Downloads, even if the
+	// sources downloaded within the other Downloads are removed
+	for _, workDir := range []string{"/workdir1", "/workdir2"} {
+		ctx := WithDownloads(utils.WithFS(context.Background(), fs), NewDownloads())
+
+		s1, err := getPolicyThroughCache(ctx, &mockPolicySource{}, workDir, dl)
+		require.NoError(t, err)
+		s2, err := getPolicyThroughCache(ctx, &mockPolicySource{}, workDir+"-shared", dl)
+		require.NoError(t, err)
+
+		for _, s := range []string{s1, s2} {
+			exists, err := afero.Exists(fs, filepath.Join(s, "p.rego"))
+			require.NoError(t, err)
+			assert.True(t, exists)
+		}
+
+		require.NoError(t, fs.RemoveAll(workDir))
+	}
+
+	assert.Equal(t, 2, invocations)
+}
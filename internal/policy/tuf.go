@@ -0,0 +1,108 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package policy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/sigstore/cosign/v2/pkg/blob"
+	log "github.com/sirupsen/logrus"
+)
+
+// tufKey is the key of the policy spec holding the TUF repository the
+// Sigstore trust root is initialized from, it is not part of the
+// EnterpriseContractPolicy schema
+const tufKey = "tuf"
+
+// TUFRoot points to the TUF repository of a private Sigstore deployment, the
+// trusted Fulcio certificates and the Rekor and CT log keys are then taken
+// from it instead of the public Sigstore infrastructure. The mirror is the URL
+// of the TUF repository and the root the file or URL of its initial trusted
+// root.json, as with `ec sigstore initialize`.
+type TUFRoot struct {
+	Mirror string `json:"mirror,omitempty"`
+	Root   string `json:"root,omitempty"`
+}
+
+// IsEmpty returns true if no TUF repository is set
+func (t TUFRoot) IsEmpty() bool {
+	return t == TUFRoot{}
+}
+
+// Merge returns the TUF repository with the values not set taken from the
+// other TUF repository
+func (t TUFRoot) Merge(other TUFRoot) TUFRoot {
+	if t.Mirror == "" {
+		t.Mirror = other.Mirror
+	}
+	if t.Root == "" {
+		t.Root = other.Root
+	}
+
+	return t
+}
+
+// initializeTUF initializes the Sigstore trust root from the TUF repository,
+// unless none is set. This needs to happen before any trusted material is
+// read, the Sigstore libraries keep the trust root for the whole process.
+func initializeTUF(ctx context.Context, t TUFRoot) error {
+	if t.IsEmpty() {
+		return nil
+	}
+
+	var root []byte
+	if t.Root != "" {
+		var err error
+		if root, err = blob.LoadFileOrURL(t.Root); err != nil {
+			return fmt.Errorf("unable to load the TUF root %s: %w", t.Root, err)
+		}
+	}
+
+	log.Debugf("Initializing the Sigstore trust root from the TUF mirror %q", t.Mirror)
+	if err := newSignatureClient(ctx).initializeTUF(ctx, t.Mirror, root); err != nil {
+		return fmt.Errorf("unable to initialize the Sigstore trust root from the TUF mirror %q: %w", t.Mirror, err)
+	}
+
+	return nil
+}
+
+// splitTUF removes the tuf key from the policy configuration, in JSON or YAML
+// format, returning the configuration without it and the TUF repository. The
+// configuration is returned as is when it doesn't set a TUF repository.
+func splitTUF(policyConfig string) (string, TUFRoot, error) {
+	rest, value, err := splitKey(policyConfig, tufKey)
+	if err != nil || value == nil {
+		return rest, TUFRoot{}, err
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", TUFRoot{}, err
+	}
+
+	var t TUFRoot
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.DisallowUnknownFields()
+	if err := d.Decode(&t); err != nil {
+		return "", TUFRoot{}, fmt.Errorf("invalid %s, expecting the mirror and root of the TUF repository, got: %s", tufKey, data)
+	}
+
+	return rest, t, nil
+}
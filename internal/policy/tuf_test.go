@@ -0,0 +1,165 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package policy
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+func TestSplitTUF(t *testing.T) {
+	cases := []struct {
+		name   string
+		config string
+		rest   string
+		tuf    TUFRoot
+		err    string
+	}{
+		{
+			name:   "none",
+			config: `{"sources": []}`,
+			rest:   `{"sources": []}`,
+		},
+		{
+			name:   "spec",
+			config: "tuf: {mirror: 'https://tuf.example.com', root: root.json}\nname: spec",
+			rest:   `{"name":"spec"}`,
+			tuf:    TUFRoot{Mirror: "https://tuf.example.com", Root: "root.json"},
+		},
+		{
+			name:   "resource",
+			config: `{"apiVersion": "appstudio.redhat.com/v1alpha1", "spec": {"tuf": {"mirror": "https://tuf.example.com"}}}`,
+			rest:   `{"apiVersion":"appstudio.redhat.com/v1alpha1","spec":{}}`,
+			tuf:    TUFRoot{Mirror: "https://tuf.example.com"},
+		},
+		{
+			name:   "unknown field",
+			config: `{"tuf": {"url": "https://tuf.example.com"}}`,
+			err:    `invalid tuf, expecting the mirror and root of the TUF repository, got: {"url":"https://tuf.example.com"}`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			rest, tuf, err := splitTUF(c.config)
+			if c.err != "" {
+				assert.EqualError(t, err, c.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, c.rest, rest)
+			assert.Equal(t, c.tuf, tuf)
+		})
+	}
+}
+
+func TestTUFRootMerge(t *testing.T) {
+	tuf := TUFRoot{Mirror: "https://tuf.example.com"}
+
+	assert.Equal(t, TUFRoot{
+		Mirror: "https://tuf.example.com",
+		Root:   "root.json",
+	}, tuf.Merge(TUFRoot{Mirror: "https://other.example.com", Root: "root.json"}))
+}
+
+func TestNewPolicyTUF(t *testing.T) {
+	root := path.Join(t.TempDir(), "root.json")
+	require.NoError(t, os.WriteFile(root, []byte(`{"signed": {}}`), 0600))
+
+	cases := []struct {
+		name     string
+		policy   string
+		tuf      TUFRoot
+		tufErr   error
+		mirror   string
+		root     []byte
+		expected TUFRoot
+		err      string
+	}{
+		{
+			name: "none",
+		},
+		{
+			name:     "from the policy",
+			policy:   `{"tuf": {"mirror": "https://tuf.example.com", "root": "` + root + `"}}`,
+			mirror:   "https://tuf.example.com",
+			root:     []byte(`{"signed": {}}`),
+			expected: TUFRoot{Mirror: "https://tuf.example.com", Root: root},
+		},
+		{
+			name:     "options override the policy",
+			policy:   `{"tuf": {"mirror": "https://tuf.example.com", "root": "` + root + `"}}`,
+			tuf:      TUFRoot{Mirror: "https://other.example.com"},
+			mirror:   "https://other.example.com",
+			root:     []byte(`{"signed": {}}`),
+			expected: TUFRoot{Mirror: "https://other.example.com", Root: root},
+		},
+		{
+			name: "missing root",
+			tuf:  TUFRoot{Mirror: "https://tuf.example.com", Root: path.Join(t.TempDir(), "missing.json")},
+			err:  "unable to load the TUF root",
+		},
+		{
+			name:   "initialization failure",
+			tuf:    TUFRoot{Mirror: "https://tuf.example.com"},
+			tufErr: errors.New("expected"),
+			err:    `unable to initialize the Sigstore trust root from the TUF mirror "https://tuf.example.com": expected`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			client := &FakeCosignClient{tufErr: c.tufErr}
+			ctx := withSignatureClient(context.Background(), client)
+			utils.SetTestRekorPublicKey(t)
+
+			p, err := NewPolicy(ctx, Options{
+				PolicyRef:     c.policy,
+				PublicKey:     utils.TestPublicKey,
+				TUF:           c.tuf,
+				EffectiveTime: Now,
+			})
+			if c.err != "" {
+				assert.ErrorContains(t, err, c.err)
+				return
+			}
+			require.NoError(t, err)
+
+			assert.Equal(t, c.expected, p.TUFRoot())
+			assert.Equal(t, c.mirror, client.mirror)
+			assert.Equal(t, c.root, client.root)
+		})
+	}
+}
+
+func TestValidatePolicyTUF(t *testing.T) {
+	ctx := utils.WithFS(context.Background(), afero.NewMemMapFs())
+
+	assert.NoError(t, ValidatePolicy(ctx, `{"sources": [{"policy": ["github.com/org/policy"]}], "tuf": {"mirror": "https://tuf.example.com"}}`))
+	assert.ErrorContains(t, ValidatePolicy(ctx, `{"tuf": "https://tuf.example.com"}`), "invalid tuf")
+}
@@ -0,0 +1,147 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package policy
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	ecc "github.com/enterprise-contract/enterprise-contract-controller/api/v1alpha1"
+	"github.com/hashicorp/go-multierror"
+	log "github.com/sirupsen/logrus"
+)
+
+// checkLoaded checks the spec of a policy being loaded. The problems with the
+// sources are only reported as warnings as policies without sources are
+// commonly used to provide just the public key.
+func checkLoaded(spec ecc.EnterpriseContractPolicySpec) error {
+	var merr *multierror.Error
+	if errors.As(checkSources(spec), &merr) {
+		for _, err := range merr.Errors {
+			log.Warnf("EnterpriseContractPolicy: %v", err)
+		}
+	}
+
+	if err := checkSpec(spec); err != nil {
+		return fmt.Errorf("invalid EnterpriseContractPolicy: %w", err)
+	}
+
+	return nil
+}
+
+// checkSources checks that the policy spec has at least one source and that
+// each source has at least one policy, all problems found are returned
+func checkSources(spec ecc.EnterpriseContractPolicySpec) error {
+	var problems error
+
+	if len(spec.Sources) == 0 {
+		problems = multierror.Append(problems, fmt.Errorf("the policy has no sources"))
+	}
+
+	for i, src := range spec.Sources {
+		if len(src.Policy) == 0 {
+			problems = multierror.Append(problems, fmt.Errorf("%s has no policy", sourceName(i, src)))
+		}
+	}
+
+	return problems
+}
+
+// checkSpec performs the semantic checks of the policy spec the schema can't
+// express, all problems found are returned:
+//   - no rule is both included and excluded, in the configuration or the
+//     config of a source
+//   - the effectiveOn and effectiveUntil of the volatile config are RFC3339
+//     timestamps, with effectiveOn before effectiveUntil
+func checkSpec(spec ecc.EnterpriseContractPolicySpec) error {
+	var problems error
+
+	if c := spec.Configuration; c != nil {
+		problems = appendConflicts(problems, "configuration", c.Include, c.Exclude)
+	}
+
+	for i, src := range spec.Sources {
+		name := sourceName(i, src)
+
+		if c := src.Config; c != nil {
+			problems = appendConflicts(problems, name+" config", c.Include, c.Exclude)
+		}
+
+		if c := src.VolatileConfig; c != nil {
+			for j, criteria := range c.Include {
+				problems = appendTimeProblems(problems, fmt.Sprintf("%s volatileConfig include %d (%s)", name, j, criteria.Value), criteria)
+			}
+			for j, criteria := range c.Exclude {
+				problems = appendTimeProblems(problems, fmt.Sprintf("%s volatileConfig exclude %d (%s)", name, j, criteria.Value), criteria)
+			}
+		}
+	}
+
+	return problems
+}
+
+// sourceName identifies the source in the problems reported
+func sourceName(i int, src ecc.Source) string {
+	if src.Name != "" {
+		return fmt.Sprintf("source %d (%s)", i, src.Name)
+	}
+
+	return fmt.Sprintf("source %d", i)
+}
+
+// appendConflicts appends a problem for each value both included and excluded
+func appendConflicts(problems error, where string, include, exclude []string) error {
+	excluded := make(map[string]bool, len(exclude))
+	for _, e := range exclude {
+		excluded[e] = true
+	}
+
+	for _, i := range include {
+		if excluded[i] {
+			problems = multierror.Append(problems, fmt.Errorf("%s: %q is both included and excluded", where, i))
+		}
+	}
+
+	return problems
+}
+
+// appendTimeProblems appends a problem for each effectiveOn or effectiveUntil
+// of the criteria that is not a RFC3339 timestamp, or if the criteria would
+// never be in effect
+func appendTimeProblems(problems error, where string, criteria ecc.VolatileCriteria) error {
+	var on, until time.Time
+	var err error
+
+	if criteria.EffectiveOn != "" {
+		if on, err = time.Parse(time.RFC3339, criteria.EffectiveOn); err != nil {
+			problems = multierror.Append(problems, fmt.Errorf("%s: effectiveOn %q is not a RFC3339 timestamp, e.g. 2024-01-01T00:00:00Z", where, criteria.EffectiveOn))
+		}
+	}
+
+	if criteria.EffectiveUntil != "" {
+		if until, err = time.Parse(time.RFC3339, criteria.EffectiveUntil); err != nil {
+			problems = multierror.Append(problems, fmt.Errorf("%s: effectiveUntil %q is not a RFC3339 timestamp, e.g. 2024-01-01T00:00:00Z", where, criteria.EffectiveUntil))
+		}
+	}
+
+	if !on.IsZero() && !until.IsZero() && !on.Before(until) {
+		problems = multierror.Append(problems, fmt.Errorf("%s: effectiveOn %q is not before effectiveUntil %q", where, criteria.EffectiveOn, criteria.EffectiveUntil))
+	}
+
+	return problems
+}
@@ -0,0 +1,124 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package policy
+
+import (
+	"context"
+	"testing"
+
+	ecc "github.com/enterprise-contract/enterprise-contract-controller/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckSpec(t *testing.T) {
+	cases := []struct {
+		name     string
+		spec     ecc.EnterpriseContractPolicySpec
+		problems []string
+	}{
+		{
+			name: "valid",
+			spec: ecc.EnterpriseContractPolicySpec{
+				Configuration: &ecc.EnterpriseContractPolicyConfiguration{Include: []string{"@minimal"}, Exclude: []string{"cve"}},
+				Sources: []ecc.Source{{
+					Policy: []string{"github.com/org/policy"},
+					VolatileConfig: &ecc.VolatileSourceConfig{
+						Exclude: []ecc.VolatileCriteria{{Value: "test", EffectiveOn: "2024-01-01T00:00:00Z", EffectiveUntil: "2025-01-01T00:00:00Z"}},
+					},
+				}},
+			},
+		},
+		{
+			name: "conflicts",
+			spec: ecc.EnterpriseContractPolicySpec{
+				Configuration: &ecc.EnterpriseContractPolicyConfiguration{Include: []string{"cve", "test"}, Exclude: []string{"cve"}},
+				Sources: []ecc.Source{{
+					Name:   "release",
+					Policy: []string{"github.com/org/policy"},
+					Config: &ecc.SourceConfig{Include: []string{"@slsa3"}, Exclude: []string{"@slsa3"}},
+				}},
+			},
+			problems: []string{
+				`configuration: "cve" is both included and excluded`,
+				`source 0 (release) config: "@slsa3" is both included and excluded`,
+			},
+		},
+		{
+			name: "times",
+			spec: ecc.EnterpriseContractPolicySpec{
+				Sources: []ecc.Source{{
+					Policy: []string{"github.com/org/policy"},
+					VolatileConfig: &ecc.VolatileSourceConfig{
+						Include: []ecc.VolatileCriteria{{Value: "a", EffectiveOn: "2024-01-01"}},
+						Exclude: []ecc.VolatileCriteria{
+							{Value: "b", EffectiveUntil: "tomorrow"},
+							{Value: "c", EffectiveOn: "2025-01-01T00:00:00Z", EffectiveUntil: "2024-01-01T00:00:00Z"},
+						},
+					},
+				}},
+			},
+			problems: []string{
+				`source 0 volatileConfig include 0 (a): effectiveOn "2024-01-01" is not a RFC3339 timestamp, e.g. 2024-01-01T00:00:00Z`,
+				`source 0 volatileConfig exclude 0 (b): effectiveUntil "tomorrow" is not a RFC3339 timestamp, e.g. 2024-01-01T00:00:00Z`,
+				`source 0 volatileConfig exclude 1 (c): effectiveOn "2025-01-01T00:00:00Z" is not before effectiveUntil "2024-01-01T00:00:00Z"`,
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := checkSpec(c.spec)
+			if len(c.problems) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+			for _, p := range c.problems {
+				assert.ErrorContains(t, err, p)
+			}
+		})
+	}
+}
+
+func TestCheckSources(t *testing.T) {
+	assert.ErrorContains(t, checkSources(ecc.EnterpriseContractPolicySpec{}), "the policy has no sources")
+
+	err := checkSources(ecc.EnterpriseContractPolicySpec{Sources: []ecc.Source{{Name: "data", Data: []string{"github.com/org/data"}}}})
+	assert.ErrorContains(t, err, "source 0 (data) has no policy")
+
+	assert.NoError(t, checkSources(ecc.EnterpriseContractPolicySpec{Sources: []ecc.Source{{Policy: []string{"github.com/org/policy"}}}}))
+}
+
+func TestValidatePolicySemantics(t *testing.T) {
+	err := ValidatePolicy(context.Background(), `{"configuration": {"include": ["cve"], "exclude": ["cve"]}}`)
+	assert.ErrorContains(t, err, "the policy has no sources")
+	assert.ErrorContains(t, err, `configuration: "cve" is both included and excluded`)
+
+	err = ValidatePolicy(context.Background(), `{"sources": [{"policy": ["github.com/org/policy"]}]}`)
+	assert.NoError(t, err)
+}
+
+func TestNewPolicyInvalidSpec(t *testing.T) {
+	_, err := NewInertPolicy(context.Background(), `{"sources": [{"policy": ["github.com/org/policy"], "config": {"include": ["cve"], "exclude": ["cve"]}}]}`)
+	assert.ErrorContains(t, err, `invalid EnterpriseContractPolicy: 1 error occurred:`)
+	assert.ErrorContains(t, err, `source 0 config: "cve" is both included and excluded`)
+
+	// policies without sources can be loaded
+	_, err = NewInertPolicy(context.Background(), `{"publicKey": "key"}`)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,164 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package report
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/enterprise-contract/ec-cli/internal/evaluator"
+)
+
+// Diff holds the differences between two reports. The results are matched by
+// the component, the name or, if not set, the image reference, and by the
+// code, term and message of the result.
+type Diff struct {
+	// Introduced holds the violations found only in the new report
+	Introduced []Change `json:"introduced"`
+	// Resolved holds the violations found only in the old report
+	Resolved []Change `json:"resolved"`
+	// UnchangedWarnings holds the warnings found in both reports
+	UnchangedWarnings []Change `json:"unchangedWarnings"`
+}
+
+// Change is a single result that differs, or not, between the reports.
+type Change struct {
+	Component      string `json:"component"`
+	ContainerImage string `json:"containerImage,omitempty"`
+	Code           string `json:"code,omitempty"`
+	Term           string `json:"term,omitempty"`
+	Message        string `json:"msg"`
+}
+
+// Compare returns the differences between the old and the new report.
+func Compare(old, updated *Report) Diff {
+	oldViolations := changes(old, func(c Component) []evaluator.Result { return c.Violations })
+	newViolations := changes(updated, func(c Component) []evaluator.Result { return c.Violations })
+	oldWarnings := changes(old, func(c Component) []evaluator.Result { return c.Warnings })
+	newWarnings := changes(updated, func(c Component) []evaluator.Result { return c.Warnings })
+
+	return Diff{
+		Introduced:        difference(newViolations, oldViolations),
+		Resolved:          difference(oldViolations, newViolations),
+		UnchangedWarnings: intersection(newWarnings, oldWarnings),
+	}
+}
+
+// Regressions returns true if the new report has violations that are not in
+// the old report.
+func (d Diff) Regressions() bool {
+	return len(d.Introduced) > 0
+}
+
+type changeKey struct {
+	component string
+	code      string
+	term      string
+	message   string
+}
+
+func (c Change) key() changeKey {
+	return changeKey{component: c.Component, code: c.Code, term: c.Term, message: c.Message}
+}
+
+func changes(r *Report, results func(Component) []evaluator.Result) []Change {
+	all := []Change{}
+	for _, c := range r.Components {
+		name := c.Name
+		if name == "" {
+			name = c.ContainerImage
+		}
+		for _, res := range results(c) {
+			all = append(all, Change{
+				Component:      name,
+				ContainerImage: c.ContainerImage,
+				Code:           evaluator.ExtractStringFromMetadata(res, "code"),
+				Term:           evaluator.ExtractStringFromMetadata(res, "term"),
+				Message:        res.Message,
+			})
+		}
+	}
+
+	return all
+}
+
+// difference returns the changes in a that are not in b
+func difference(a, b []Change) []Change {
+	return filter(a, b, false)
+}
+
+// intersection returns the changes in a that are also in b
+func intersection(a, b []Change) []Change {
+	return filter(a, b, true)
+}
+
+func filter(a, b []Change, in bool) []Change {
+	keys := make(map[changeKey]bool, len(b))
+	for _, c := range b {
+		keys[c.key()] = true
+	}
+
+	filtered := []Change{}
+	seen := map[changeKey]bool{}
+	for _, c := range a {
+		k := c.key()
+		if keys[k] == in && !seen[k] {
+			seen[k] = true
+			filtered = append(filtered, c)
+		}
+	}
+
+	sort.SliceStable(filtered, func(i, j int) bool {
+		if filtered[i].Component != filtered[j].Component {
+			return filtered[i].Component < filtered[j].Component
+		}
+		return filtered[i].Code < filtered[j].Code
+	})
+
+	return filtered
+}
+
+// WriteText writes the differences in human readable form.
+func (d Diff) WriteText(w io.Writer) error {
+	var b strings.Builder
+
+	section := func(title, indicator string, changes []Change) {
+		fmt.Fprintf(&b, "%s: %d\n", title, len(changes))
+		for _, c := range changes {
+			code := c.Code
+			if code == "" {
+				code = "(no code)"
+			}
+			fmt.Fprintf(&b, "%s [%s] %s\n", indicator, c.Component, code)
+			if c.Term != "" {
+				fmt.Fprintf(&b, "  Term: %s\n", c.Term)
+			}
+			fmt.Fprintf(&b, "  Reason: %s\n", c.Message)
+		}
+	}
+
+	section("Introduced violations", "✕", d.Introduced)
+	b.WriteString("\n")
+	section("Resolved violations", "✓", d.Resolved)
+	b.WriteString("\n")
+	section("Unchanged warnings", "›", d.UnchangedWarnings)
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
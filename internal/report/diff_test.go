@@ -0,0 +1,109 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package report
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const oldReport = `{
+	"components": [
+		{
+			"name": "frontend",
+			"containerImage": "registry.io/frontend@sha256:old",
+			"violations": [
+				{"msg": "Not signed", "metadata": {"code": "attestation.signed"}},
+				{"msg": "CVE found", "metadata": {"code": "cve.found", "term": "CVE-1"}}
+			],
+			"warnings": [
+				{"msg": "Old task", "metadata": {"code": "tasks.current", "term": "buildah"}},
+				{"msg": "Resolved warning", "metadata": {"code": "tasks.other"}}
+			]
+		}
+	]
+}`
+
+const newReport = `
+components:
+  - name: frontend
+    containerImage: registry.io/frontend@sha256:new
+    violations:
+      - msg: CVE found
+        metadata: {code: cve.found, term: CVE-2}
+      - msg: CVE found
+        metadata: {code: cve.found, term: CVE-1}
+    warnings:
+      - msg: Old task
+        metadata: {code: tasks.current, term: buildah}
+  - containerImage: registry.io/backend@sha256:new
+    violations:
+      - msg: Not signed
+        metadata: {code: attestation.signed}
+`
+
+func TestCompare(t *testing.T) {
+	old, err := ReadReport([]byte(oldReport))
+	require.NoError(t, err)
+	updated, err := ReadReport([]byte(newReport))
+	require.NoError(t, err)
+
+	diff := Compare(old, updated)
+
+	assert.Equal(t, Diff{
+		Introduced: []Change{
+			{Component: "frontend", ContainerImage: "registry.io/frontend@sha256:new", Code: "cve.found", Term: "CVE-2", Message: "CVE found"},
+			{Component: "registry.io/backend@sha256:new", ContainerImage: "registry.io/backend@sha256:new", Code: "attestation.signed", Message: "Not signed"},
+		},
+		Resolved: []Change{
+			{Component: "frontend", ContainerImage: "registry.io/frontend@sha256:old", Code: "attestation.signed", Message: "Not signed"},
+		},
+		UnchangedWarnings: []Change{
+			{Component: "frontend", ContainerImage: "registry.io/frontend@sha256:new", Code: "tasks.current", Term: "buildah", Message: "Old task"},
+		},
+	}, diff)
+	assert.True(t, diff.Regressions())
+
+	assert.False(t, Compare(old, old).Regressions())
+}
+
+func TestWriteText(t *testing.T) {
+	diff := Diff{
+		Introduced: []Change{{Component: "frontend", Code: "cve.found", Term: "CVE-2", Message: "CVE found"}},
+		Resolved:   []Change{{Component: "frontend", Message: "Not signed"}},
+	}
+
+	buf := bytes.Buffer{}
+	require.NoError(t, diff.WriteText(&buf))
+
+	assert.Equal(t, `Introduced violations: 1
+✕ [frontend] cve.found
+  Term: CVE-2
+  Reason: CVE found
+
+Resolved violations: 1
+✓ [frontend] (no code)
+  Reason: Not signed
+
+Unchanged warnings: 0
+`, buf.String())
+}
@@ -0,0 +1,108 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package report
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/sigstore/sigstore/pkg/signature"
+	"sigs.k8s.io/yaml"
+
+	"github.com/enterprise-contract/ec-cli/internal/utils/oci"
+)
+
+const (
+	// ArtifactType is the artifact type of the reports pushed to the registry,
+	// used to discover the reports via the referrers API
+	ArtifactType = "application/vnd.enterprisecontract.report.v1+json"
+
+	// SignatureAnnotation holds the base64 encoded signature of the report
+	// when the report was signed
+	SignatureAnnotation = "dev.enterprisecontract.report.signature"
+)
+
+// ReferrerArtifact returns an OCI artifact holding the report in JSON format,
+// referring to the image described by subject. The signature, if given, is
+// added as the SignatureAnnotation annotation.
+func ReferrerArtifact(report []byte, subject v1.Descriptor, sig []byte) (v1.Image, error) {
+	var annotations map[string]string
+	if len(sig) > 0 {
+		annotations = map[string]string{SignatureAnnotation: base64.StdEncoding.EncodeToString(sig)}
+	}
+
+	return oci.ReferrerArtifact(report, types.MediaType("application/json"), ArtifactType, subject, annotations)
+}
+
+// Push pushes the report, given in JSON or YAML format, to the registry as an
+// artifact referring to the image of each component in the report. When a
+// signer is given the report is signed. The references of the pushed
+// artifacts are returned in the order of the components.
+func Push(ctx context.Context, data []byte, signer signature.Signer) ([]name.Digest, error) {
+	r, err := ReadReport(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(r.Components) == 0 {
+		return nil, fmt.Errorf("the report has no components")
+	}
+
+	report, err := yaml.YAMLToJSON(data)
+	if err != nil {
+		return nil, fmt.Errorf("unable to convert the report to JSON: %w", err)
+	}
+
+	var sig []byte
+	if signer != nil {
+		if sig, err = signer.SignMessage(bytes.NewReader(report)); err != nil {
+			return nil, fmt.Errorf("unable to sign the report: %w", err)
+		}
+	}
+
+	client := oci.NewClient(ctx)
+	pushed := make([]name.Digest, 0, len(r.Components))
+	for _, c := range r.Components {
+		ref, err := name.ParseReference(c.ContainerImage)
+		if err != nil {
+			return pushed, fmt.Errorf("unable to parse the image reference %q: %w", c.ContainerImage, err)
+		}
+
+		desc, err := client.Head(ref)
+		if err != nil {
+			return pushed, fmt.Errorf("unable to fetch the descriptor of the image %q: %w", c.ContainerImage, err)
+		}
+
+		artifact, err := ReferrerArtifact(report, *desc, sig)
+		if err != nil {
+			return pushed, err
+		}
+
+		target, err := oci.PushReferrer(client, ref, artifact)
+		if err != nil {
+			return pushed, fmt.Errorf("unable to push the report for the image %q: %w", c.ContainerImage, err)
+		}
+		pushed = append(pushed, target)
+	}
+
+	return pushed, nil
+}
@@ -0,0 +1,160 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package report
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/sigstore/sigstore/pkg/signature"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/utils/oci"
+	"github.com/enterprise-contract/ec-cli/internal/utils/oci/fake"
+)
+
+const pushedReport = `{"components":[{"name":"frontend","containerImage":"registry.io/frontend:tag","success":true}]}`
+
+var subject = v1.Descriptor{
+	MediaType: types.OCIManifestSchema1,
+	Size:      1234,
+	Digest:    v1.Hash{Algorithm: "sha256", Hex: "4e388ab32b10dc8dbc7e28144f552830adc74787c1e2c0824032078a79f227fb"},
+}
+
+func TestReferrerArtifact(t *testing.T) {
+	img, err := ReferrerArtifact([]byte(pushedReport), subject, []byte("signature"))
+	require.NoError(t, err)
+
+	manifest, err := img.Manifest()
+	require.NoError(t, err)
+
+	assert.Equal(t, types.OCIManifestSchema1, manifest.MediaType)
+	assert.Equal(t, types.MediaType(ArtifactType), manifest.Config.MediaType)
+	assert.Equal(t, &subject, manifest.Subject)
+	assert.Equal(t, map[string]string{SignatureAnnotation: base64.StdEncoding.EncodeToString([]byte("signature"))}, manifest.Annotations)
+
+	layers, err := img.Layers()
+	require.NoError(t, err)
+	require.Len(t, layers, 1)
+
+	rc, err := layers[0].Uncompressed()
+	require.NoError(t, err)
+	defer rc.Close()
+	content, err := io.ReadAll(rc)
+	require.NoError(t, err)
+	assert.Equal(t, pushedReport, string(content))
+}
+
+func TestPush(t *testing.T) {
+	ref := name.MustParseReference("registry.io/frontend:tag")
+
+	client := fake.FakeClient{}
+	client.On("Head", ref).Return(&subject, nil)
+	client.On("Write", mock.Anything, mock.Anything).Return(nil)
+	ctx := oci.WithClient(context.Background(), &client)
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	signer, err := signature.LoadECDSASignerVerifier(key, crypto.SHA256)
+	require.NoError(t, err)
+
+	// given in YAML, pushed in JSON
+	pushed, err := Push(ctx, []byte("components:\n- name: frontend\n  containerImage: registry.io/frontend:tag\n  success: true\n"), signer)
+	require.NoError(t, err)
+	require.Len(t, pushed, 1)
+	assert.Equal(t, "registry.io/frontend", pushed[0].Context().String())
+
+	img := client.Calls[1].Arguments.Get(1).(v1.Image)
+	digest, err := img.Digest()
+	require.NoError(t, err)
+	assert.Equal(t, digest.String(), pushed[0].DigestStr())
+
+	manifest, err := img.Manifest()
+	require.NoError(t, err)
+	assert.Equal(t, subject.Digest, manifest.Subject.Digest)
+
+	sig, err := base64.StdEncoding.DecodeString(manifest.Annotations[SignatureAnnotation])
+	require.NoError(t, err)
+	assert.NoError(t, signer.VerifySignature(bytes.NewReader(sig), bytes.NewReader([]byte(`{"components":[{"containerImage":"registry.io/frontend:tag","name":"frontend","success":true}]}`))))
+}
+
+func TestPushErrors(t *testing.T) {
+	ref := name.MustParseReference("registry.io/frontend:tag")
+
+	cases := []struct {
+		name   string
+		report string
+		setup  func(*fake.FakeClient)
+		err    string
+	}{
+		{
+			name:   "no components",
+			report: `{"components": []}`,
+			err:    "the report has no components",
+		},
+		{
+			name:   "invalid image reference",
+			report: `{"components": [{"containerImage": "not valid"}]}`,
+			err:    `unable to parse the image reference "not valid"`,
+		},
+		{
+			name:   "missing image",
+			report: pushedReport,
+			setup: func(c *fake.FakeClient) {
+				c.On("Head", ref).Return(nil, errors.New("not found"))
+			},
+			err: `unable to fetch the descriptor of the image "registry.io/frontend:tag": not found`,
+		},
+		{
+			name:   "push failure",
+			report: pushedReport,
+			setup: func(c *fake.FakeClient) {
+				c.On("Head", ref).Return(&subject, nil)
+				c.On("Write", mock.Anything, mock.Anything).Return(errors.New("denied"))
+			},
+			err: `unable to push the report for the image "registry.io/frontend:tag": denied`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			client := fake.FakeClient{}
+			if c.setup != nil {
+				c.setup(&client)
+			}
+			ctx := oci.WithClient(context.Background(), &client)
+
+			_, err := Push(ctx, []byte(c.report), nil)
+			assert.ErrorContains(t, err, c.err)
+		})
+	}
+}
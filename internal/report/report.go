@@ -0,0 +1,53 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package report reads, compares and pushes the reports produced by the
+// validate commands.
+package report
+
+import (
+	"fmt"
+
+	ecc "github.com/enterprise-contract/enterprise-contract-controller/api/v1alpha1"
+	"sigs.k8s.io/yaml"
+
+	"github.com/enterprise-contract/ec-cli/internal/evaluator"
+)
+
+// Report holds the parts of the report produced by the validate commands
+// needed to compare it with another report or to explain its failures.
+type Report struct {
+	Components []Component                      `json:"components"`
+	Policy     ecc.EnterpriseContractPolicySpec `json:"policy"`
+}
+
+// Component holds the results of a single component within the Report.
+type Component struct {
+	Name           string             `json:"name"`
+	ContainerImage string             `json:"containerImage"`
+	Violations     []evaluator.Result `json:"violations,omitempty"`
+	Warnings       []evaluator.Result `json:"warnings,omitempty"`
+}
+
+// ReadReport parses the report given in JSON or YAML format.
+func ReadReport(data []byte) (*Report, error) {
+	var r Report
+	if err := yaml.Unmarshal(data, &r); err != nil {
+		return nil, fmt.Errorf("unable to parse the report: %w", err)
+	}
+
+	return &r, nil
+}
@@ -0,0 +1,54 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package report
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadReport(t *testing.T) {
+	r, err := ReadReport([]byte(`components:
+- name: a
+  containerImage: registry.io/a@sha256:abc
+  violations:
+  - msg: Not signed
+  warnings:
+  - msg: Deprecated
+policy:
+  sources:
+  - policy:
+    - oci::quay.io/policy
+`))
+	require.NoError(t, err)
+
+	require.Len(t, r.Components, 1)
+	assert.Equal(t, "a", r.Components[0].Name)
+	assert.Equal(t, "registry.io/a@sha256:abc", r.Components[0].ContainerImage)
+	assert.Equal(t, "Not signed", r.Components[0].Violations[0].Message)
+	assert.Equal(t, "Deprecated", r.Components[0].Warnings[0].Message)
+	assert.Equal(t, []string{"oci::quay.io/policy"}, r.Policy.Sources[0].Policy)
+}
+
+func TestReadReportInvalid(t *testing.T) {
+	_, err := ReadReport([]byte("components: ["))
+	assert.ErrorContains(t, err, "unable to parse the report")
+}
@@ -0,0 +1,176 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package sbom
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/enterprise-contract/ec-cli/internal/attestation"
+	"github.com/enterprise-contract/ec-cli/internal/utils/oci"
+)
+
+// Sources of the SBOMs attached to an image
+const (
+	SourceAttestation = "attestation"
+	SourceReferrer    = "referrer"
+	SourceProvenance  = "provenance"
+)
+
+// Predicate types of the SBOM attestations created by cosign
+const (
+	PredicateCycloneDX = "https://cyclonedx.org/bom"
+	PredicateSPDX      = attestation.PredicateSpdxDocument
+)
+
+// mediaTypes are the media types of SBOMs attached as referrers artifacts or
+// recorded as byproducts in the provenance
+var mediaTypes = map[string]bool{
+	"application/vnd.cyclonedx+json": true,
+	"application/spdx+json":          true,
+}
+
+// FromAttestations returns the SBOMs found in the attestations, i.e. the
+// predicate of the CycloneDX and SPDX SBOM attestations, and the SBOMs
+// embedded in the byproducts of SLSA v1.0 provenance attestations. SBOMs that
+// can't be parsed are skipped.
+func FromAttestations(attestations []attestation.Attestation) []SBOM {
+	var sboms []SBOM
+	for _, att := range attestations {
+		switch att.PredicateType() {
+		case PredicateCycloneDX, PredicateSPDX:
+			var statement struct {
+				Predicate json.RawMessage `json:"predicate"`
+			}
+			if err := json.Unmarshal(att.Statement(), &statement); err != nil {
+				log.Debugf("Unable to parse the %s attestation: %v", att.PredicateType(), err)
+				continue
+			}
+			sboms = appendParsed(sboms, statement.Predicate, SourceAttestation, att.PredicateType())
+		default:
+			// the content of the byproducts is base64 encoded
+			var statement struct {
+				Predicate struct {
+					RunDetails struct {
+						Byproducts []struct {
+							Name      string `json:"name"`
+							MediaType string `json:"mediaType"`
+							Content   []byte `json:"content"`
+						} `json:"byproducts"`
+					} `json:"runDetails"`
+				} `json:"predicate"`
+			}
+			if err := json.Unmarshal(att.Statement(), &statement); err != nil {
+				log.Debugf("Unable to parse the %s attestation: %v", att.PredicateType(), err)
+				continue
+			}
+			for _, b := range statement.Predicate.RunDetails.Byproducts {
+				if mediaTypes[b.MediaType] && len(b.Content) > 0 {
+					sboms = appendParsed(sboms, b.Content, SourceProvenance, b.Name)
+				}
+			}
+		}
+	}
+
+	return sboms
+}
+
+// FetchReferrers returns the SBOMs attached to the image as OCI referrers
+// artifacts, i.e. artifacts with the artifact type of a CycloneDX or a SPDX
+// SBOM in JSON format. SBOMs that can't be parsed are skipped.
+func FetchReferrers(ctx context.Context, ref name.Digest) ([]SBOM, error) {
+	client := oci.NewClient(ctx)
+
+	index, err := client.Referrers(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest, err := index.IndexManifest()
+	if err != nil {
+		return nil, err
+	}
+
+	var sboms []SBOM
+	for _, m := range manifest.Manifests {
+		if !mediaTypes[m.ArtifactType] {
+			continue
+		}
+
+		artifactRef := ref.Context().Digest(m.Digest.String())
+		artifact, err := client.Image(artifactRef)
+		if err != nil {
+			return nil, fmt.Errorf("fetching the SBOM artifact %s: %w", artifactRef, err)
+		}
+
+		layers, err := artifact.Layers()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, l := range layers {
+			mediaType, err := l.MediaType()
+			if err != nil {
+				return nil, err
+			}
+			if !mediaTypes[string(mediaType)] {
+				continue
+			}
+
+			data, err := readLayer(l)
+			if err != nil {
+				return nil, fmt.Errorf("reading the SBOM artifact %s: %w", artifactRef, err)
+			}
+
+			sboms = appendParsed(sboms, data, SourceReferrer, artifactRef.String())
+		}
+	}
+
+	return sboms, nil
+}
+
+// readLayer returns the content of the layer as stored in the registry, the
+// SBOM layers are not compressed
+func readLayer(l v1.Layer) ([]byte, error) {
+	r, err := l.Compressed()
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	return io.ReadAll(r)
+}
+
+// appendParsed appends the parsed SBOM, recording its source, or logs why the
+// SBOM was skipped when it can't be parsed
+func appendParsed(sboms []SBOM, data []byte, source, origin string) []SBOM {
+	s, err := Parse(data)
+	if err != nil {
+		log.Debugf("Skipping the SBOM from %s %s: %v", source, origin, err)
+		return sboms
+	}
+
+	s.Source = source
+
+	return append(sboms, *s)
+}
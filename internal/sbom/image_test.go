@@ -0,0 +1,164 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package sbom
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/in-toto/in-toto-golang/in_toto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/attestation"
+	"github.com/enterprise-contract/ec-cli/internal/signature"
+	"github.com/enterprise-contract/ec-cli/internal/utils/oci"
+	"github.com/enterprise-contract/ec-cli/internal/utils/oci/fake"
+)
+
+const imageDigest = "registry.io/repository/image@sha256:4e388ab32b10dc8dbc7e28144f552830adc74787c1e2c0824032078a79f227fb"
+
+type statement struct {
+	predicateType string
+	predicate     string
+}
+
+func (s statement) Type() string {
+	return in_toto.StatementInTotoV01
+}
+
+func (s statement) PredicateType() string {
+	return s.predicateType
+}
+
+func (s statement) Statement() []byte {
+	return []byte(fmt.Sprintf(`{"_type": %q, "predicateType": %q, "predicate": %s}`, in_toto.StatementInTotoV01, s.predicateType, s.predicate))
+}
+
+func (s statement) Signatures() []signature.EntitySignature {
+	return nil
+}
+
+func (s statement) Subject() []in_toto.Subject {
+	return nil
+}
+
+func testdata(t *testing.T, file string) []byte {
+	data, err := os.ReadFile(file)
+	require.NoError(t, err)
+
+	return data
+}
+
+func TestFromAttestations(t *testing.T) {
+	cyclonedx := testdata(t, "testdata/cyclonedx.json")
+	spdx := testdata(t, "testdata/spdx.json")
+
+	byproducts, err := json.Marshal(map[string]any{
+		"buildDefinition": map[string]any{"buildType": "https://example.com/build"},
+		"runDetails": map[string]any{
+			"byproducts": []map[string]any{
+				{"name": "sbom.json", "mediaType": "application/vnd.cyclonedx+json", "content": cyclonedx},
+				{"name": "build.log", "mediaType": "text/plain", "content": []byte("log")},
+				{"name": "broken.json", "mediaType": "application/spdx+json", "content": []byte("{}")},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	sboms := FromAttestations([]attestation.Attestation{
+		statement{predicateType: PredicateCycloneDX, predicate: string(cyclonedx)},
+		statement{predicateType: PredicateSPDX, predicate: string(spdx)},
+		statement{predicateType: PredicateSPDX, predicate: `{"spdxVersion": 2}`},
+		statement{predicateType: "https://slsa.dev/provenance/v1", predicate: string(byproducts)},
+		statement{predicateType: attestation.PredicateSLSAProvenance, predicate: `{"buildType": "https://example.com/build"}`},
+	})
+
+	require.Len(t, sboms, 3)
+	for i, expected := range []struct {
+		source string
+		format string
+	}{
+		{SourceAttestation, CycloneDX},
+		{SourceAttestation, SPDX},
+		{SourceProvenance, CycloneDX},
+	} {
+		assert.Equal(t, expected.source, sboms[i].Source)
+		assert.Equal(t, expected.format, sboms[i].Format)
+		assert.Equal(t, expectedComponents, sboms[i].Components)
+	}
+}
+
+func TestFetchReferrers(t *testing.T) {
+	ref := name.MustParseReference(imageDigest).(name.Digest)
+	subject := v1.Descriptor{MediaType: types.OCIManifestSchema1, Digest: v1.Hash{Algorithm: "sha256", Hex: strings.TrimPrefix(ref.DigestStr(), "sha256:")}, Size: 1}
+
+	client := fake.FakeClient{}
+	var index v1.ImageIndex = empty.Index
+	for _, a := range []struct {
+		content      []byte
+		artifactType types.MediaType
+	}{
+		{testdata(t, "testdata/spdx.json"), "application/spdx+json"},
+		{testdata(t, "testdata/cyclonedx.json"), "application/vnd.cyclonedx+json"},
+		{[]byte(`{"not": "a SBOM"}`), "application/vnd.cyclonedx+json"},
+		{[]byte(`{"report": true}`), "application/vnd.example.report+json"},
+	} {
+		artifact, err := oci.ReferrerArtifact(a.content, a.artifactType, a.artifactType, subject, nil)
+		require.NoError(t, err)
+		digest, err := artifact.Digest()
+		require.NoError(t, err)
+
+		index = mutate.AppendManifests(index, mutate.IndexAddendum{Add: artifact})
+		client.On("Image", ref.Context().Digest(digest.String())).Return(artifact, nil)
+	}
+	client.On("Referrers", ref).Return(index, nil)
+
+	sboms, err := FetchReferrers(oci.WithClient(context.Background(), &client), ref)
+	require.NoError(t, err)
+
+	formats := []string{}
+	for _, s := range sboms {
+		assert.Equal(t, SourceReferrer, s.Source)
+		assert.Equal(t, expectedComponents, s.Components)
+		formats = append(formats, s.Format)
+	}
+	assert.ElementsMatch(t, []string{SPDX, CycloneDX}, formats)
+	client.AssertNumberOfCalls(t, "Image", 3)
+}
+
+func TestFetchReferrersError(t *testing.T) {
+	ref := name.MustParseReference(imageDigest).(name.Digest)
+
+	client := fake.FakeClient{}
+	client.On("Referrers", ref).Return(nil, errors.New("expected"))
+
+	_, err := FetchReferrers(oci.WithClient(context.Background(), &client), ref)
+	assert.EqualError(t, err, "expected")
+}
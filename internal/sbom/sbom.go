@@ -0,0 +1,289 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package sbom converts CycloneDX and SPDX SBOMs, in JSON format, into a
+// normalized input for the rego policy evaluation, so that the same rules
+// apply regardless of the format of the SBOM.
+package sbom
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+const (
+	CycloneDX = "cyclonedx"
+	SPDX      = "spdx"
+)
+
+// Input is the policy input for a SBOM
+type Input struct {
+	SBOM SBOM `json:"sbom"`
+}
+
+// SBOM is the normalized SBOM exposed to policy rules, the document holds the
+// SBOM as provided for rules specific to a format. The source is set only for
+// the SBOMs attached to an image.
+type SBOM struct {
+	Source      string      `json:"source,omitempty"`
+	Format      string      `json:"format"`
+	SpecVersion string      `json:"spec_version"`
+	Name        string      `json:"name,omitempty"`
+	Components  []Component `json:"components"`
+	Document    any         `json:"document"`
+}
+
+// Component is a software component, or a package, listed in the SBOM
+type Component struct {
+	Name     string   `json:"name"`
+	Version  string   `json:"version,omitempty"`
+	Purl     string   `json:"purl,omitempty"`
+	Licenses []string `json:"licenses,omitempty"`
+}
+
+// Parse detects the format of the SBOM and converts it into the normalized
+// form
+func Parse(data []byte) (*SBOM, error) {
+	var header struct {
+		BOMFormat   string `json:"bomFormat"`
+		SPDXVersion string `json:"spdxVersion"`
+	}
+	if err := json.Unmarshal(data, &header); err != nil {
+		return nil, fmt.Errorf("unable to parse the SBOM, only JSON SBOMs are supported: %w", err)
+	}
+
+	var s *SBOM
+	var err error
+	switch {
+	case header.BOMFormat == "CycloneDX":
+		s, err = parseCycloneDX(data)
+	case strings.HasPrefix(header.SPDXVersion, "SPDX-"):
+		s, err = parseSPDX(data)
+	default:
+		return nil, errors.New("unknown SBOM format, expecting a CycloneDX or a SPDX SBOM in JSON format")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, &s.Document); err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(s.Components, func(i, j int) bool {
+		if s.Components[i].Name == s.Components[j].Name {
+			return s.Components[i].Version < s.Components[j].Version
+		}
+		return s.Components[i].Name < s.Components[j].Name
+	})
+
+	return s, nil
+}
+
+type cyclonedxComponent struct {
+	Name     string `json:"name"`
+	Version  string `json:"version"`
+	Purl     string `json:"purl"`
+	Licenses []struct {
+		License struct {
+			ID   string `json:"id"`
+			Name string `json:"name"`
+		} `json:"license"`
+		Expression string `json:"expression"`
+	} `json:"licenses"`
+	Components []cyclonedxComponent `json:"components"`
+}
+
+func parseCycloneDX(data []byte) (*SBOM, error) {
+	var doc struct {
+		SpecVersion string `json:"specVersion"`
+		Metadata    struct {
+			Component struct {
+				Name string `json:"name"`
+			} `json:"component"`
+		} `json:"metadata"`
+		Components []cyclonedxComponent `json:"components"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("unable to parse the CycloneDX SBOM: %w", err)
+	}
+
+	s := SBOM{
+		Format:      CycloneDX,
+		SpecVersion: doc.SpecVersion,
+		Name:        doc.Metadata.Component.Name,
+		Components:  []Component{},
+	}
+
+	// components can be nested within components
+	var add func([]cyclonedxComponent)
+	add = func(components []cyclonedxComponent) {
+		for _, c := range components {
+			component := Component{
+				Name:    c.Name,
+				Version: c.Version,
+				Purl:    c.Purl,
+			}
+			for _, l := range c.Licenses {
+				switch {
+				case l.Expression != "":
+					component.Licenses = append(component.Licenses, l.Expression)
+				case l.License.ID != "":
+					component.Licenses = append(component.Licenses, l.License.ID)
+				case l.License.Name != "":
+					component.Licenses = append(component.Licenses, l.License.Name)
+				}
+			}
+			s.Components = append(s.Components, component)
+			add(c.Components)
+		}
+	}
+	add(doc.Components)
+
+	return &s, nil
+}
+
+// spdxLicense returns the license unless it is one of the SPDX values denoting
+// a missing license
+func spdxLicense(l string) string {
+	switch l {
+	case "", "NOASSERTION", "NONE":
+		return ""
+	}
+
+	return l
+}
+
+func parseSPDX(data []byte) (*SBOM, error) {
+	var doc struct {
+		SPDXVersion       string   `json:"spdxVersion"`
+		Name              string   `json:"name"`
+		DocumentDescribes []string `json:"documentDescribes"`
+		Packages          []struct {
+			SPDXID           string `json:"SPDXID"`
+			Name             string `json:"name"`
+			VersionInfo      string `json:"versionInfo"`
+			LicenseConcluded string `json:"licenseConcluded"`
+			LicenseDeclared  string `json:"licenseDeclared"`
+			ExternalRefs     []struct {
+				ReferenceType    string `json:"referenceType"`
+				ReferenceLocator string `json:"referenceLocator"`
+			} `json:"externalRefs"`
+		} `json:"packages"`
+		Relationships []struct {
+			SPDXElementID      string `json:"spdxElementId"`
+			RelationshipType   string `json:"relationshipType"`
+			RelatedSPDXElement string `json:"relatedSpdxElement"`
+		} `json:"relationships"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("unable to parse the SPDX SBOM: %w", err)
+	}
+
+	// the packages described by the document are the subject of the SBOM,
+	// equivalent to the metadata component of CycloneDX, not its components
+	described := map[string]bool{}
+	for _, id := range doc.DocumentDescribes {
+		described[id] = true
+	}
+	for _, r := range doc.Relationships {
+		if r.SPDXElementID == "SPDXRef-DOCUMENT" && r.RelationshipType == "DESCRIBES" {
+			described[r.RelatedSPDXElement] = true
+		}
+	}
+
+	s := SBOM{
+		Format:      SPDX,
+		SpecVersion: doc.SPDXVersion,
+		Name:        doc.Name,
+		Components:  []Component{},
+	}
+
+	for _, p := range doc.Packages {
+		if described[p.SPDXID] {
+			s.Name = p.Name
+			continue
+		}
+
+		component := Component{
+			Name:    p.Name,
+			Version: p.VersionInfo,
+		}
+
+		for _, r := range p.ExternalRefs {
+			if r.ReferenceType == "purl" {
+				component.Purl = r.ReferenceLocator
+				break
+			}
+		}
+
+		license := spdxLicense(p.LicenseConcluded)
+		if license == "" {
+			license = spdxLicense(p.LicenseDeclared)
+		}
+		if license != "" {
+			component.Licenses = []string{license}
+		}
+
+		s.Components = append(s.Components, component)
+	}
+
+	return &s, nil
+}
+
+// WriteInputFile reads the SBOM from the given file and writes the policy input
+// for it to a temporary file, returning the path to the file
+func WriteInputFile(ctx context.Context, path string) (string, error) {
+	fs := utils.FS(ctx)
+
+	data, err := afero.ReadFile(fs, path)
+	if err != nil {
+		return "", err
+	}
+
+	s, err := Parse(data)
+	if err != nil {
+		return "", err
+	}
+
+	content, err := json.Marshal(Input{SBOM: *s})
+	if err != nil {
+		return "", err
+	}
+
+	f, err := afero.TempFile(fs, "", "sbom-*.json")
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	if _, err := f.Write(content); err != nil {
+		return "", err
+	}
+
+	log.Debugf("Input for %s SBOM %s written to %s", s.Format, path, f.Name())
+
+	return f.Name(), nil
+}
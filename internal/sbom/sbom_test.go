@@ -0,0 +1,105 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package sbom
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+var expectedComponents = []Component{
+	{Name: "bash", Version: "5.2.15", Purl: "pkg:rpm/redhat/bash@5.2.15", Licenses: []string{"GPL-3.0-or-later"}},
+	{Name: "requests", Version: "2.31.0", Purl: "pkg:pypi/requests@2.31.0", Licenses: []string{"Apache-2.0"}},
+	{Name: "urllib3", Version: "2.0.7", Purl: "pkg:pypi/urllib3@2.0.7", Licenses: []string{"MIT"}},
+}
+
+func TestParse(t *testing.T) {
+	cases := []struct {
+		file        string
+		format      string
+		specVersion string
+	}{
+		{file: "testdata/cyclonedx.json", format: CycloneDX, specVersion: "1.5"},
+		{file: "testdata/spdx.json", format: SPDX, specVersion: "SPDX-2.3"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.format, func(t *testing.T) {
+			data, err := os.ReadFile(c.file)
+			require.NoError(t, err)
+
+			s, err := Parse(data)
+			require.NoError(t, err)
+
+			assert.Equal(t, c.format, s.Format)
+			assert.Equal(t, c.specVersion, s.SpecVersion)
+			assert.Equal(t, "registry.io/repository/image", s.Name)
+			assert.Equal(t, expectedComponents, s.Components)
+			assert.NotNil(t, s.Document)
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	_, err := Parse([]byte("bomFormat: CycloneDX"))
+	assert.ErrorContains(t, err, "unable to parse the SBOM, only JSON SBOMs are supported")
+
+	_, err = Parse([]byte(`{"kind": "Task"}`))
+	assert.EqualError(t, err, "unknown SBOM format, expecting a CycloneDX or a SPDX SBOM in JSON format")
+}
+
+// TestConsistentInput verifies that the policy input, apart from the format
+// specific fields, is the same for both formats
+func TestConsistentInput(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	ctx := utils.WithFS(context.Background(), fs)
+
+	normalized := func(file string) map[string]any {
+		data, err := os.ReadFile(file)
+		require.NoError(t, err)
+		require.NoError(t, afero.WriteFile(fs, file, data, 0644))
+
+		path, err := WriteInputFile(ctx, file)
+		require.NoError(t, err)
+
+		content, err := afero.ReadFile(fs, path)
+		require.NoError(t, err)
+
+		var input map[string]any
+		require.NoError(t, json.Unmarshal(content, &input))
+
+		s := input["sbom"].(map[string]any)
+		for _, f := range []string{"format", "spec_version", "document"} {
+			assert.Contains(t, s, f)
+			delete(s, f)
+		}
+
+		return input
+	}
+
+	assert.Equal(t, normalized("testdata/cyclonedx.json"), normalized("testdata/spdx.json"))
+}
@@ -0,0 +1,146 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/enterprise-contract/ec-cli/internal/evaluator"
+	"github.com/enterprise-contract/ec-cli/internal/policy"
+)
+
+// Compiled is a policy along with the evaluators of its sources, ready to be
+// used for validation
+type Compiled struct {
+	Policy     policy.Policy
+	Evaluators []evaluator.Evaluator
+}
+
+func (c *Compiled) destroy() {
+	for _, e := range c.Evaluators {
+		e.Destroy()
+	}
+}
+
+// LoadFunc loads and compiles the policy with the given reference
+type LoadFunc func(ctx context.Context, policyRef string) (*Compiled, error)
+
+// PolicyCache holds the compiled policies by their reference for the TTL.
+// Expired policies are reloaded on the next use, so changes to the policy and
+// to the effective time are picked up. The evaluators of an expired policy
+// are destroyed once all of the validations using it complete. Failures to
+// load a policy are not cached.
+type PolicyCache struct {
+	ttl     time.Duration
+	load    LoadFunc
+	now     func() time.Time
+	mu      sync.Mutex
+	entries map[string]*cacheEntry
+}
+
+type cacheEntry struct {
+	ready    chan struct{}
+	loaded   time.Time
+	compiled *Compiled
+	err      error
+	users    sync.WaitGroup
+}
+
+// NewPolicyCache returns an empty PolicyCache loading the policies using the
+// given function
+func NewPolicyCache(ttl time.Duration, load LoadFunc) *PolicyCache {
+	return &PolicyCache{
+		ttl:     ttl,
+		load:    load,
+		now:     time.Now,
+		entries: map[string]*cacheEntry{},
+	}
+}
+
+// Get returns the compiled policy with the given reference, loading it if
+// it is not cached or has expired. The returned function needs to be invoked
+// once the compiled policy is no longer used.
+func (c *PolicyCache) Get(ctx context.Context, policyRef string) (*Compiled, func(), error) {
+	c.mu.Lock()
+	e, ok := c.entries[policyRef]
+	if ok && c.now().Sub(e.loaded) >= c.ttl {
+		delete(c.entries, policyRef)
+		retire(e)
+		ok = false
+	}
+
+	if ok {
+		e.users.Add(1)
+		c.mu.Unlock()
+		<-e.ready
+	} else {
+		// Loading happens outside of the lock so that the requests using
+		// other policies are not held up, the requests for the same policy
+		// wait on the ready channel instead
+		e = &cacheEntry{ready: make(chan struct{}), loaded: c.now()}
+		e.users.Add(1)
+		c.entries[policyRef] = e
+		c.mu.Unlock()
+
+		e.compiled, e.err = c.load(ctx, policyRef)
+		if e.err != nil {
+			c.mu.Lock()
+			if c.entries[policyRef] == e {
+				delete(c.entries, policyRef)
+			}
+			c.mu.Unlock()
+		}
+		close(e.ready)
+	}
+
+	if e.err != nil {
+		e.users.Done()
+		return nil, nil, e.err
+	}
+
+	return e.compiled, e.users.Done, nil
+}
+
+// Close removes all of the policies from the cache, destroying their
+// evaluators once they are no longer used
+func (c *PolicyCache) Close() {
+	c.mu.Lock()
+	entries := c.entries
+	c.entries = map[string]*cacheEntry{}
+	c.mu.Unlock()
+
+	for _, e := range entries {
+		e.release()
+	}
+}
+
+// retire releases the entry in the background
+func retire(e *cacheEntry) {
+	go e.release()
+}
+
+// release destroys the evaluators of the entry once it has been loaded and
+// all of its users are done with it
+func (e *cacheEntry) release() {
+	<-e.ready
+	e.users.Wait()
+	if e.compiled != nil {
+		e.compiled.destroy()
+	}
+}
@@ -0,0 +1,121 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/evaluator"
+)
+
+type countingEvaluator struct {
+	evaluator.Evaluator
+	destroyed atomic.Int32
+}
+
+func (e *countingEvaluator) Destroy() {
+	e.destroyed.Add(1)
+}
+
+func TestPolicyCacheReusesPolicies(t *testing.T) {
+	var loads atomic.Int32
+	cache := NewPolicyCache(time.Minute, func(_ context.Context, ref string) (*Compiled, error) {
+		loads.Add(1)
+		return &Compiled{Evaluators: []evaluator.Evaluator{&countingEvaluator{}}}, nil
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c, release, err := cache.Get(context.Background(), "policy")
+			assert.NoError(t, err)
+			assert.NotNil(t, c)
+			release()
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), loads.Load())
+
+	_, release, err := cache.Get(context.Background(), "other")
+	require.NoError(t, err)
+	release()
+	assert.Equal(t, int32(2), loads.Load())
+}
+
+func TestPolicyCacheExpiry(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	var loaded []*countingEvaluator
+	cache := NewPolicyCache(time.Minute, func(context.Context, string) (*Compiled, error) {
+		e := &countingEvaluator{}
+		loaded = append(loaded, e)
+		return &Compiled{Evaluators: []evaluator.Evaluator{e}}, nil
+	})
+	cache.now = func() time.Time { return now }
+
+	first, releaseFirst, err := cache.Get(context.Background(), "policy")
+	require.NoError(t, err)
+
+	now = now.Add(time.Minute)
+
+	second, releaseSecond, err := cache.Get(context.Background(), "policy")
+	require.NoError(t, err)
+	assert.NotSame(t, first, second)
+	require.Len(t, loaded, 2)
+
+	// still in use by the first caller
+	time.Sleep(10 * time.Millisecond)
+	assert.Equal(t, int32(0), loaded[0].destroyed.Load())
+
+	releaseFirst()
+	assert.Eventually(t, func() bool { return loaded[0].destroyed.Load() == 1 }, time.Second, time.Millisecond)
+
+	releaseSecond()
+	cache.Close()
+	assert.Equal(t, int32(1), loaded[1].destroyed.Load())
+}
+
+func TestPolicyCacheDoesNotCacheFailures(t *testing.T) {
+	var loads atomic.Int32
+	cache := NewPolicyCache(time.Minute, func(context.Context, string) (*Compiled, error) {
+		if loads.Add(1) == 1 {
+			return nil, errors.New("boom")
+		}
+		return &Compiled{}, nil
+	})
+
+	_, _, err := cache.Get(context.Background(), "policy")
+	assert.EqualError(t, err, "boom")
+
+	c, release, err := cache.Get(context.Background(), "policy")
+	require.NoError(t, err)
+	assert.NotNil(t, c)
+	release()
+	assert.Equal(t, int32(2), loads.Load())
+}
@@ -0,0 +1,111 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package service implements the REST API for validating images against the
+// Enterprise Contract as a long-running service.
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	app "github.com/konflux-ci/application-api/api/v1alpha1"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/enterprise-contract/ec-cli/internal/applicationsnapshot"
+)
+
+// maxRequestBytes limits the size of the validation request accepted
+const maxRequestBytes = 1024 * 1024
+
+// ValidationRequest is the body of the POST /validate request
+type ValidationRequest struct {
+	// Image is the reference of the image to validate
+	Image string `json:"image"`
+	// Policy is the reference of the policy configuration, in any of the
+	// forms accepted by the --policy flag, if empty the default policy of
+	// the service is used
+	Policy string `json:"policy,omitempty"`
+}
+
+// ValidateFunc validates the component against the compiled policy
+type ValidateFunc func(ctx context.Context, comp app.SnapshotComponent, compiled *Compiled) (applicationsnapshot.Component, error)
+
+// Handler handles the POST /validate requests, responding with the outcome
+// of the validation of the image in JSON format. The policies are taken from
+// the cache, so only the first request for each policy incurs the cost of
+// downloading and compiling it.
+type Handler struct {
+	Cache         *PolicyCache
+	DefaultPolicy string
+	Validate      ValidateFunc
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+func (h Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		respond(w, http.StatusMethodNotAllowed, errorResponse{"only POST is supported"})
+		return
+	}
+
+	var req ValidationRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBytes)).Decode(&req); err != nil {
+		respond(w, http.StatusBadRequest, errorResponse{fmt.Sprintf("unable to decode the request: %v", err)})
+		return
+	}
+
+	if req.Image == "" {
+		respond(w, http.StatusBadRequest, errorResponse{"the image needs to be provided"})
+		return
+	}
+
+	policyRef := req.Policy
+	if policyRef == "" {
+		policyRef = h.DefaultPolicy
+	}
+	if policyRef == "" {
+		respond(w, http.StatusBadRequest, errorResponse{"the policy needs to be provided"})
+		return
+	}
+
+	compiled, release, err := h.Cache.Get(r.Context(), policyRef)
+	if err != nil {
+		respond(w, http.StatusInternalServerError, errorResponse{fmt.Sprintf("unable to load the policy: %v", err)})
+		return
+	}
+	defer release()
+
+	component, err := h.Validate(r.Context(), app.SnapshotComponent{Name: "Unnamed", ContainerImage: req.Image}, compiled)
+	if err != nil {
+		respond(w, http.StatusInternalServerError, errorResponse{fmt.Sprintf("unable to validate the image: %v", err)})
+		return
+	}
+
+	respond(w, http.StatusOK, component)
+}
+
+func respond(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		log.Errorf("unable to write the response: %v", err)
+	}
+}
@@ -0,0 +1,138 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	app "github.com/konflux-ci/application-api/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/enterprise-contract/ec-cli/internal/applicationsnapshot"
+	"github.com/enterprise-contract/ec-cli/internal/evaluator"
+)
+
+func TestHandler(t *testing.T) {
+	load := func(_ context.Context, ref string) (*Compiled, error) {
+		if ref == "broken" {
+			return nil, errors.New("no such policy")
+		}
+		return &Compiled{}, nil
+	}
+
+	validate := func(_ context.Context, comp app.SnapshotComponent, _ *Compiled) (applicationsnapshot.Component, error) {
+		switch comp.ContainerImage {
+		case "registry.io/bad:tag":
+			return applicationsnapshot.Component{
+				SnapshotComponent: comp,
+				Violations:        []evaluator.Result{{Message: "Not signed"}},
+			}, nil
+		case "registry.io/error:tag":
+			return applicationsnapshot.Component{}, errors.New("boom")
+		}
+		return applicationsnapshot.Component{SnapshotComponent: comp, Success: true}, nil
+	}
+
+	cases := []struct {
+		name          string
+		method        string
+		body          string
+		defaultPolicy string
+		status        int
+		response      string
+	}{
+		{
+			name:          "conforming",
+			body:          `{"image": "registry.io/good:tag"}`,
+			defaultPolicy: "policy",
+			status:        http.StatusOK,
+			response:      `{"name": "Unnamed", "containerImage": "registry.io/good:tag", "source": {}, "success": true}`,
+		},
+		{
+			name:     "violations",
+			body:     `{"image": "registry.io/bad:tag", "policy": "policy"}`,
+			status:   http.StatusOK,
+			response: `{"name": "Unnamed", "containerImage": "registry.io/bad:tag", "source": {}, "success": false, "violations": [{"msg": "Not signed"}]}`,
+		},
+		{
+			name:     "wrong method",
+			method:   http.MethodGet,
+			status:   http.StatusMethodNotAllowed,
+			response: `{"error": "only POST is supported"}`,
+		},
+		{
+			name:     "invalid body",
+			body:     `nope`,
+			status:   http.StatusBadRequest,
+			response: `{"error": "unable to decode the request: invalid character 'o' in literal null (expecting 'u')"}`,
+		},
+		{
+			name:     "no image",
+			body:     `{"policy": "policy"}`,
+			status:   http.StatusBadRequest,
+			response: `{"error": "the image needs to be provided"}`,
+		},
+		{
+			name:     "no policy",
+			body:     `{"image": "registry.io/good:tag"}`,
+			status:   http.StatusBadRequest,
+			response: `{"error": "the policy needs to be provided"}`,
+		},
+		{
+			name:     "policy failure",
+			body:     `{"image": "registry.io/good:tag", "policy": "broken"}`,
+			status:   http.StatusInternalServerError,
+			response: `{"error": "unable to load the policy: no such policy"}`,
+		},
+		{
+			name:     "validation failure",
+			body:     `{"image": "registry.io/error:tag", "policy": "policy"}`,
+			status:   http.StatusInternalServerError,
+			response: `{"error": "unable to validate the image: boom"}`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			h := Handler{
+				Cache:         NewPolicyCache(time.Minute, load),
+				DefaultPolicy: c.defaultPolicy,
+				Validate:      validate,
+			}
+
+			method := c.method
+			if method == "" {
+				method = http.MethodPost
+			}
+
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest(method, "/validate", strings.NewReader(c.body)))
+
+			assert.Equal(t, c.status, rec.Code)
+			assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+			assert.JSONEq(t, c.response, rec.Body.String())
+		})
+	}
+}
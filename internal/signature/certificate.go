@@ -109,8 +109,17 @@ func utf8String(oid asn1.ObjectIdentifier) func(*x509.Certificate) (string, erro
 	}
 }
 
+func joined(values func(*x509.Certificate) []string) func(*x509.Certificate) (string, error) {
+	return func(c *x509.Certificate) (string, error) {
+		return strings.Join(values(c), ", "), nil
+	}
+}
+
 var certificateMetadata = map[string]extract{
 	"Subject":                                   nameFrom(func(c *x509.Certificate) pkix.Name { return c.Subject }),
+	"Subject Common Name":                       joined(func(c *x509.Certificate) []string { return []string{c.Subject.CommonName} }),
+	"Subject Organization":                      joined(func(c *x509.Certificate) []string { return c.Subject.Organization }),
+	"Subject Organizational Unit":               joined(func(c *x509.Certificate) []string { return c.Subject.OrganizationalUnit }),
 	"Subject Alternative Name":                  san,
 	"Issuer":                                    nameFrom(func(c *x509.Certificate) pkix.Name { return c.Issuer }),
 	"Serial Number":                             func(c *x509.Certificate) (string, error) { return c.SerialNumber.Text(16), nil },
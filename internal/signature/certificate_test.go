@@ -19,10 +19,16 @@
 package signature
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
 	"crypto/x509"
+	"crypto/x509/pkix"
 	_ "embed"
 	"encoding/pem"
+	"math/big"
 	"testing"
+	"time"
 
 	"github.com/gkampitakis/go-snaps/snaps"
 	"github.com/google/go-containerregistry/pkg/v1/types"
@@ -56,6 +62,33 @@ func TestAddCertificateMetadata(t *testing.T) {
 	}
 }
 
+func TestAddCertificateSubjectMetadata(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject: pkix.Name{
+			CommonName:         "build.example.com",
+			Organization:       []string{"Example", "Example Builds"},
+			OrganizationalUnit: []string{"Release Engineering"},
+		},
+		NotBefore: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+		NotAfter:  time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, key.Public(), key)
+	require.NoError(t, err)
+	cer, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	metadata := map[string]string{}
+	require.NoError(t, addCertificateMetadataTo(&metadata, cer))
+
+	assert.Equal(t, "build.example.com", metadata["Subject Common Name"])
+	assert.Equal(t, "Example, Example Builds", metadata["Subject Organization"])
+	assert.Equal(t, "Release Engineering", metadata["Subject Organizational Unit"])
+}
+
 func TestNewEntitySignature(t *testing.T) {
 	signature, err := static.NewSignature(
 		[]byte(`image`),
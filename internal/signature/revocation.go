@@ -0,0 +1,162 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package signature
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/sigstore/cosign/v2/pkg/cosign"
+	"github.com/sigstore/cosign/v2/pkg/oci"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/crypto/ocsp"
+)
+
+// ocspClient is used to query the OCSP responders, the requests are bounded
+// by the timeout so that an unresponsive responder doesn't stall the
+// validation
+var ocspClient = &http.Client{Timeout: 10 * time.Second}
+
+// maxOCSPResponseSize bounds the size of the OCSP responses read
+const maxOCSPResponseSize = 1 << 20
+
+// Revocation configures the revocation checks of the certificates of keyless
+// signatures, against certificate revocation lists (CRLs) and, when enabled,
+// the OCSP responders named in the certificates.
+type Revocation struct {
+	CRLs []*x509.RevocationList
+	OCSP bool
+}
+
+// IsEmpty returns true if no revocation checks are configured
+func (r Revocation) IsEmpty() bool {
+	return len(r.CRLs) == 0 && !r.OCSP
+}
+
+// VerifyRevocation verifies that none of the certificates in the chain of the
+// certificate of a keyless signature, up to the trusted root, is revoked. A
+// certificate is checked against the CRLs signed by its issuer and, with OCSP
+// enabled, against the OCSP responder named in it, if any.
+func VerifyRevocation(ctx context.Context, sig oci.Signature, opts *cosign.CheckOpts, r Revocation) error {
+	cert, err := sig.Cert()
+	if err != nil {
+		return err
+	}
+	if cert == nil {
+		return errors.New("the signature has no certificate")
+	}
+
+	chain, err := sig.Chain()
+	if err != nil {
+		return err
+	}
+
+	verified, err := VerifyCertificateChain(cert, chain, opts.RootCerts, opts.IntermediateCerts)
+	if err != nil {
+		return err
+	}
+
+	// the trusted root is not checked, it has no issuer to revoke it
+	for i := 0; i < len(verified)-1; i++ {
+		c, issuer := verified[i], verified[i+1]
+
+		if err := checkCRLs(c, issuer, r.CRLs); err != nil {
+			return err
+		}
+
+		if r.OCSP {
+			if err := checkOCSP(ctx, c, issuer); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func checkCRLs(cert, issuer *x509.Certificate, crls []*x509.RevocationList) error {
+	for _, crl := range crls {
+		// only the CRLs of the issuer apply
+		if crl.CheckSignatureFrom(issuer) != nil {
+			continue
+		}
+
+		// an expired CRL doesn't list the certificates revoked since
+		if !crl.NextUpdate.IsZero() && time.Now().After(crl.NextUpdate) {
+			return fmt.Errorf("the CRL number %s issued by %q expired at %s", crl.Number, issuer.Subject, crl.NextUpdate.UTC().Format(time.RFC3339))
+		}
+
+		for _, e := range crl.RevokedCertificateEntries {
+			if e.SerialNumber.Cmp(cert.SerialNumber) == 0 {
+				return fmt.Errorf("the certificate with serial number %s issued by %q is revoked since %s", cert.SerialNumber.Text(16), cert.Issuer, e.RevocationTime.UTC().Format(time.RFC3339))
+			}
+		}
+	}
+
+	return nil
+}
+
+func checkOCSP(ctx context.Context, cert, issuer *x509.Certificate) error {
+	if len(cert.OCSPServer) == 0 {
+		log.Debugf("The certificate with serial number %s names no OCSP responder, not checking its status", cert.SerialNumber.Text(16))
+		return nil
+	}
+
+	req, err := ocsp.CreateRequest(cert, issuer, nil)
+	if err != nil {
+		return err
+	}
+
+	server := cert.OCSPServer[0]
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, server, bytes.NewReader(req))
+	if err != nil {
+		return err
+	}
+	httpReq.Header.Set("Content-Type", "application/ocsp-request")
+
+	log.Debugf("Checking the status of the certificate with serial number %s with the OCSP responder %s", cert.SerialNumber.Text(16), server)
+	resp, err := ocspClient.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("unable to check the status of the certificate with serial number %s with the OCSP responder %s: %w", cert.SerialNumber.Text(16), server, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxOCSPResponseSize))
+	if err != nil {
+		return err
+	}
+
+	status, err := ocsp.ParseResponseForCert(body, cert, issuer)
+	if err != nil {
+		return fmt.Errorf("invalid response from the OCSP responder %s: %w", server, err)
+	}
+
+	switch status.Status {
+	case ocsp.Good:
+		return nil
+	case ocsp.Revoked:
+		return fmt.Errorf("the certificate with serial number %s issued by %q is revoked since %s", cert.SerialNumber.Text(16), cert.Issuer, status.RevokedAt.UTC().Format(time.RFC3339))
+	default:
+		return fmt.Errorf("the status of the certificate with serial number %s issued by %q is unknown to the OCSP responder %s", cert.SerialNumber.Text(16), cert.Issuer, server)
+	}
+}
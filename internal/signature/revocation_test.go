@@ -0,0 +1,216 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package signature
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/sigstore/cosign/v2/pkg/cosign"
+	"github.com/sigstore/cosign/v2/pkg/oci"
+	"github.com/sigstore/cosign/v2/pkg/oci/static"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ocsp"
+)
+
+var revokedAt = time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+
+// crl returns the CRL of the PKI revoking the certificates with the given
+// serial numbers
+func (p testPKI) crl(t *testing.T, serials ...int64) *x509.RevocationList {
+	return p.crlUntil(t, time.Now().Add(time.Hour), serials...)
+}
+
+// crlUntil returns the CRL of the PKI revoking the certificates with the given
+// serial numbers, with its next update at the given time
+func (p testPKI) crlUntil(t *testing.T, nextUpdate time.Time, serials ...int64) *x509.RevocationList {
+	entries := make([]x509.RevocationListEntry, 0, len(serials))
+	for _, s := range serials {
+		entries = append(entries, x509.RevocationListEntry{SerialNumber: big.NewInt(s), RevocationTime: revokedAt})
+	}
+
+	der, err := x509.CreateRevocationList(rand.Reader, &x509.RevocationList{
+		Number:                    big.NewInt(1),
+		ThisUpdate:                nextUpdate.Add(-2 * time.Hour),
+		NextUpdate:                nextUpdate,
+		RevokedCertificateEntries: entries,
+	}, p.ca, p.caKey)
+	require.NoError(t, err)
+
+	crl, err := x509.ParseRevocationList(der)
+	require.NoError(t, err)
+
+	return crl
+}
+
+// issueWithOCSP returns a code signing certificate naming the OCSP responder
+func (p testPKI) issueWithOCSP(t *testing.T, responder string) *x509.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	der, err := x509.CreateCertificate(rand.Reader, &x509.Certificate{
+		SerialNumber: big.NewInt(8),
+		NotBefore:    time.Now().Add(-time.Minute),
+		NotAfter:     time.Now().Add(10 * time.Minute),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+		OCSPServer:   []string{responder},
+	}, p.ca, key.Public(), p.caKey)
+	require.NoError(t, err)
+
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return cert
+}
+
+// ocspResponder returns an OCSP responder of the PKI answering with the given
+// status
+func (p testPKI) ocspResponder(t *testing.T, status int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		req, err := ocsp.ParseRequest(body)
+		require.NoError(t, err)
+
+		resp, err := ocsp.CreateResponse(p.ca, p.ca, ocsp.Response{
+			Status:       status,
+			SerialNumber: req.SerialNumber,
+			ThisUpdate:   time.Now().Add(-time.Hour),
+			NextUpdate:   time.Now().Add(time.Hour),
+			RevokedAt:    revokedAt,
+		}, p.caKey)
+		require.NoError(t, err)
+
+		w.Header().Set("Content-Type", "application/ocsp-response")
+		_, _ = w.Write(resp)
+	}))
+}
+
+func signatureWith(t *testing.T, cert *x509.Certificate) oci.Signature {
+	sig, err := static.NewSignature([]byte("payload"), "c2lnbmF0dXJl", static.WithCertChain(
+		pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw}), nil))
+	require.NoError(t, err)
+
+	return sig
+}
+
+func TestVerifyRevocationCRLs(t *testing.T) {
+	ctx := context.Background()
+	pki := newTestPKI(t)
+	other := newTestPKI(t)
+	sig := signatureWith(t, pki.issue(t, false))
+	opts := &cosign.CheckOpts{RootCerts: pool(pki.ca)}
+
+	assert.NoError(t, VerifyRevocation(ctx, sig, opts, Revocation{CRLs: []*x509.RevocationList{pki.crl(t, 1, 3)}}))
+
+	// the CRLs of other issuers don't apply
+	assert.NoError(t, VerifyRevocation(ctx, sig, opts, Revocation{CRLs: []*x509.RevocationList{other.crl(t, 7)}}))
+
+	assert.EqualError(t, VerifyRevocation(ctx, sig, opts, Revocation{CRLs: []*x509.RevocationList{other.crl(t, 7), pki.crl(t, 7)}}),
+		`the certificate with serial number 7 issued by "CN=Test CA" is revoked since 2024-05-01T00:00:00Z`)
+
+	// expired CRLs are rejected
+	expiredAt := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	assert.EqualError(t, VerifyRevocation(ctx, sig, opts, Revocation{CRLs: []*x509.RevocationList{pki.crlUntil(t, expiredAt)}}),
+		`the CRL number 1 issued by "CN=Test CA" expired at 2024-06-01T00:00:00Z`)
+
+	// the chain is verified before checking the revocation
+	assert.ErrorContains(t, VerifyRevocation(ctx, sig, &cosign.CheckOpts{RootCerts: pool(other.ca)}, Revocation{CRLs: []*x509.RevocationList{pki.crl(t)}}),
+		"certificate chain is incomplete or untrusted")
+
+	noCert, err := static.NewSignature([]byte("payload"), "c2lnbmF0dXJl")
+	require.NoError(t, err)
+	assert.EqualError(t, VerifyRevocation(ctx, noCert, opts, Revocation{OCSP: true}), "the signature has no certificate")
+}
+
+func TestVerifyRevocationOCSP(t *testing.T) {
+	ctx := context.Background()
+	pki := newTestPKI(t)
+	opts := &cosign.CheckOpts{RootCerts: pool(pki.ca)}
+
+	cases := []struct {
+		name   string
+		status int
+		err    string
+	}{
+		{name: "good", status: ocsp.Good},
+		{
+			name:   "revoked",
+			status: ocsp.Revoked,
+			err:    `the certificate with serial number 8 issued by "CN=Test CA" is revoked since 2024-05-01T00:00:00Z`,
+		},
+		{
+			name:   "unknown",
+			status: ocsp.Unknown,
+			err:    `the status of the certificate with serial number 8 issued by "CN=Test CA" is unknown to the OCSP responder `,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			responder := pki.ocspResponder(t, c.status)
+			defer responder.Close()
+
+			err := VerifyRevocation(ctx, signatureWith(t, pki.issueWithOCSP(t, responder.URL)), opts, Revocation{OCSP: true})
+			if c.err != "" {
+				assert.ErrorContains(t, err, c.err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+
+	// certificates naming no OCSP responder are not checked
+	assert.NoError(t, VerifyRevocation(ctx, signatureWith(t, pki.issue(t, false)), opts, Revocation{OCSP: true}))
+
+	assert.ErrorContains(t, VerifyRevocation(ctx, signatureWith(t, pki.issueWithOCSP(t, "http://127.0.0.1:0")), opts, Revocation{OCSP: true}),
+		"unable to check the status of the certificate with serial number 8 with the OCSP responder http://127.0.0.1:0")
+}
+
+func TestVerifyRevocationOCSPTimeout(t *testing.T) {
+	client := ocspClient
+	t.Cleanup(func() { ocspClient = client })
+	ocspClient = &http.Client{Timeout: 10 * time.Millisecond}
+
+	pki := newTestPKI(t)
+	opts := &cosign.CheckOpts{RootCerts: pool(pki.ca)}
+
+	// the responder doesn't answer before the test completes
+	done := make(chan struct{})
+	responder := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, _ *http.Request) {
+		<-done
+	}))
+	defer responder.Close()
+	defer close(done)
+
+	assert.ErrorContains(t, VerifyRevocation(context.Background(), signatureWith(t, pki.issueWithOCSP(t, responder.URL)), opts, Revocation{OCSP: true}),
+		"Client.Timeout exceeded")
+}
@@ -0,0 +1,40 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package signature
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	cosignsig "github.com/sigstore/cosign/v2/pkg/signature"
+	sigstoresig "github.com/sigstore/sigstore/pkg/signature"
+)
+
+// LoadSigner loads the private key with the given reference, a path or a KMS
+// URI, to sign with. The password of an encrypted key is read from the
+// COSIGN_PASSWORD environment variable.
+func LoadSigner(ctx context.Context, keyRef string) (sigstoresig.Signer, error) {
+	signer, err := cosignsig.SignerFromKeyRef(ctx, keyRef, func(bool) ([]byte, error) {
+		return []byte(os.Getenv("COSIGN_PASSWORD")), nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to load the signing key: %w", err)
+	}
+
+	return signer, nil
+}
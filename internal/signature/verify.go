@@ -0,0 +1,147 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package signature
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+
+	"github.com/sigstore/cosign/v2/pkg/cosign"
+	"github.com/sigstore/cosign/v2/pkg/oci"
+	"github.com/sigstore/rekor/pkg/generated/client/entries"
+)
+
+// VerifyCertificate verifies the certificate of a keyless signature: that it
+// chains up to one of the trusted Fulcio roots and that its embedded SCT is
+// valid against the Certificate Transparency log keys.
+func VerifyCertificate(ctx context.Context, sig oci.Signature, opts *cosign.CheckOpts) error {
+	cert, err := sig.Cert()
+	if err != nil {
+		return err
+	}
+	if cert == nil {
+		return errors.New("the signature has no certificate")
+	}
+
+	chain, err := sig.Chain()
+	if err != nil {
+		return err
+	}
+
+	verified, err := VerifyCertificateChain(cert, chain, opts.RootCerts, opts.IntermediateCerts)
+	if err != nil {
+		return err
+	}
+
+	return VerifySCT(ctx, verified, opts.CTLogPubKeys)
+}
+
+// VerifyCertificateChain builds the chain from the certificate up to one of
+// the trusted roots, using the intermediate certificates and the chain of
+// certificates provided with the signature, if any, and returns the chain
+// starting with the certificate. As with cosign the certificate validity is
+// checked at the time it was issued, signatures made within the validity are
+// accepted afterwards.
+func VerifyCertificateChain(cert *x509.Certificate, chain []*x509.Certificate, roots, intermediates *x509.CertPool) ([]*x509.Certificate, error) {
+	if roots == nil {
+		return nil, errors.New("no trusted root certificates configured")
+	}
+
+	pool := x509.NewCertPool()
+	if intermediates != nil {
+		pool = intermediates.Clone()
+	}
+	for _, c := range chain {
+		pool.AddCert(c)
+	}
+
+	chains, err := cosign.TrustedCert(cert, roots, pool)
+	if err != nil {
+		return nil, fmt.Errorf("certificate chain is incomplete or untrusted, unable to verify the certificate with serial number %s issued by %q up to a trusted root: %w", cert.SerialNumber.Text(16), cert.Issuer, err)
+	}
+
+	return chains[0], nil
+}
+
+// VerifySCT verifies the SCT embedded in the first certificate of the chain,
+// the second certificate being its issuer, against the Certificate
+// Transparency log keys.
+func VerifySCT(ctx context.Context, chain []*x509.Certificate, pubKeys *cosign.TrustedTransparencyLogPubKeys) error {
+	if len(chain) < 2 {
+		return errors.New("certificate chain is incomplete, the issuer of the certificate is needed to verify the SCT")
+	}
+
+	contains, err := cosign.ContainsSCT(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: chain[0].Raw}))
+	if err != nil {
+		return fmt.Errorf("unable to read the SCT of the certificate: %w", err)
+	}
+	if !contains {
+		return errors.New("the certificate has no embedded SCT")
+	}
+
+	if err := cosign.VerifyEmbeddedSCT(ctx, chain, pubKeys); err != nil {
+		return fmt.Errorf("invalid SCT: %w", err)
+	}
+
+	return nil
+}
+
+// VerifyInclusionProof verifies that the transparency log entry recorded in
+// the Rekor bundle of the signature is included in the Rekor log. The entry is
+// fetched from Rekor by its log index, checked to be the entry of the bundle,
+// and its inclusion proof and signed entry timestamp are verified against the
+// Rekor public keys. Signatures without a bundle are skipped, cosign verifies
+// their inclusion proof when looking up their entry online.
+func VerifyInclusionProof(ctx context.Context, sig oci.Signature, opts *cosign.CheckOpts) error {
+	b, err := sig.Bundle()
+	if err != nil {
+		return err
+	}
+	if b == nil {
+		return nil
+	}
+
+	if opts.RekorClient == nil {
+		return errors.New("no Rekor client configured to fetch the inclusion proof of the Rekor entry")
+	}
+
+	idx := b.Payload.LogIndex
+	params := entries.NewGetLogEntryByIndexParamsWithContext(ctx)
+	params.SetLogIndex(idx)
+	resp, err := opts.RekorClient.Entries.GetLogEntryByIndex(params)
+	if err != nil {
+		return fmt.Errorf("unable to fetch the Rekor entry with log index %d: %w", idx, err)
+	}
+
+	for _, e := range resp.Payload {
+		body, ok := e.Body.(string)
+		if !ok || body != b.Payload.Body || e.IntegratedTime == nil || *e.IntegratedTime != b.Payload.IntegratedTime || e.LogID == nil || *e.LogID != b.Payload.LogID {
+			return fmt.Errorf("the Rekor entry with log index %d does not match the Rekor bundle of the signature", idx)
+		}
+
+		if err := cosign.VerifyTLogEntryOffline(ctx, &e, opts.RekorPubKeys); err != nil {
+			return fmt.Errorf("unable to verify the Rekor entry with log index %d: %w", idx, err)
+		}
+
+		return nil
+	}
+
+	return fmt.Errorf("no Rekor entry found with log index %d", idx)
+}
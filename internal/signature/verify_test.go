@@ -0,0 +1,341 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package signature
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/go-openapi/runtime"
+	ct "github.com/google/certificate-transparency-go"
+	cttls "github.com/google/certificate-transparency-go/tls"
+	ctx509 "github.com/google/certificate-transparency-go/x509"
+	"github.com/sigstore/cosign/v2/pkg/cosign"
+	"github.com/sigstore/cosign/v2/pkg/cosign/bundle"
+	"github.com/sigstore/cosign/v2/pkg/oci/static"
+	"github.com/sigstore/rekor/pkg/generated/client"
+	"github.com/sigstore/rekor/pkg/generated/client/entries"
+	"github.com/sigstore/rekor/pkg/generated/models"
+	"github.com/sigstore/sigstore/pkg/cryptoutils"
+	"github.com/sigstore/sigstore/pkg/tuf"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testPKI is a certificate authority issuing Fulcio-like code signing
+// certificates with an SCT, signed by the CT log key, embedded
+type testPKI struct {
+	ca     *x509.Certificate
+	caKey  *ecdsa.PrivateKey
+	logKey *ecdsa.PrivateKey
+}
+
+func newTestPKI(t *testing.T) testPKI {
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, caKey.Public(), caKey)
+	require.NoError(t, err)
+	ca, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	logKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	return testPKI{ca: ca, caKey: caKey, logKey: logKey}
+}
+
+// logPubKeys returns the CT log keys trusting the CT log of the PKI
+func (p testPKI) logPubKeys(t *testing.T) *cosign.TrustedTransparencyLogPubKeys {
+	pem, err := cryptoutils.MarshalPublicKeyToPEM(p.logKey.Public())
+	require.NoError(t, err)
+
+	keys := cosign.NewTrustedTransparencyLogPubKeys()
+	require.NoError(t, keys.AddTransparencyLogPubKey(pem, tuf.Active))
+	return &keys
+}
+
+// issue returns a code signing certificate, with an embedded SCT if withSCT
+// is set
+func (p testPKI) issue(t *testing.T, withSCT bool) *x509.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(7),
+		NotBefore:    time.Now().Add(-time.Minute),
+		NotAfter:     time.Now().Add(10 * time.Minute),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+	}
+
+	create := func() *x509.Certificate {
+		der, err := x509.CreateCertificate(rand.Reader, tmpl, p.ca, key.Public(), p.caKey)
+		require.NoError(t, err)
+		cert, err := x509.ParseCertificate(der)
+		require.NoError(t, err)
+		return cert
+	}
+
+	if !withSCT {
+		return create()
+	}
+
+	// the SCT is issued for the precertificate, which is the certificate with
+	// the poison extension in place of the SCT extension
+	tmpl.ExtraExtensions = []pkix.Extension{{Id: asn1.ObjectIdentifier(ctx509.OIDExtensionCTPoison), Critical: true, Value: asn1.NullBytes}}
+	precert := create()
+	leaf, err := ctx509.ParseCertificate(precert.Raw)
+	require.NoError(t, err)
+	issuer, err := ctx509.ParseCertificate(p.ca.Raw)
+	require.NoError(t, err)
+
+	timestamp := uint64(time.Now().UnixMilli())
+	entry, err := ct.MerkleTreeLeafFromChain([]*ctx509.Certificate{leaf, issuer}, ct.PrecertLogEntryType, timestamp)
+	require.NoError(t, err)
+
+	logPub, err := x509.MarshalPKIXPublicKey(p.logKey.Public())
+	require.NoError(t, err)
+
+	sct := ct.SignedCertificateTimestamp{
+		SCTVersion: ct.V1,
+		LogID:      ct.LogID{KeyID: sha256.Sum256(logPub)},
+		Timestamp:  timestamp,
+	}
+	input, err := ct.SerializeSCTSignatureInput(sct, ct.LogEntry{Leaf: *entry})
+	require.NoError(t, err)
+	digest := sha256.Sum256(input)
+	sig, err := ecdsa.SignASN1(rand.Reader, p.logKey, digest[:])
+	require.NoError(t, err)
+	sct.Signature = ct.DigitallySigned{
+		Algorithm: cttls.SignatureAndHashAlgorithm{Hash: cttls.SHA256, Signature: cttls.ECDSA},
+		Signature: sig,
+	}
+
+	serialized, err := cttls.Marshal(sct)
+	require.NoError(t, err)
+	list, err := cttls.Marshal(ctx509.SignedCertificateTimestampList{SCTList: []ctx509.SerializedSCT{{Val: serialized}}})
+	require.NoError(t, err)
+	value, err := asn1.Marshal(list)
+	require.NoError(t, err)
+
+	tmpl.ExtraExtensions = []pkix.Extension{{Id: asn1.ObjectIdentifier(ctx509.OIDExtensionCTSCT), Value: value}}
+
+	return create()
+}
+
+func pool(certs ...*x509.Certificate) *x509.CertPool {
+	p := x509.NewCertPool()
+	for _, c := range certs {
+		p.AddCert(c)
+	}
+	return p
+}
+
+func TestVerifyCertificateChainAndSCT(t *testing.T) {
+	pki := newTestPKI(t)
+	cert := pki.issue(t, true)
+
+	chain, err := VerifyCertificateChain(cert, nil, pool(pki.ca), nil)
+	require.NoError(t, err)
+	assert.Equal(t, []*x509.Certificate{cert, pki.ca}, chain)
+
+	// the chain provided with the signature is used in building the chain
+	chain, err = VerifyCertificateChain(cert, []*x509.Certificate{pki.ca}, pool(pki.ca), x509.NewCertPool())
+	require.NoError(t, err)
+	assert.Equal(t, []*x509.Certificate{cert, pki.ca}, chain)
+
+	assert.NoError(t, VerifySCT(context.Background(), chain, pki.logPubKeys(t)))
+}
+
+func TestVerifyCertificateChainBroken(t *testing.T) {
+	pki := newTestPKI(t)
+	cert := pki.issue(t, true)
+	other := newTestPKI(t)
+
+	_, err := VerifyCertificateChain(cert, nil, pool(other.ca), nil)
+	assert.ErrorContains(t, err, `certificate chain is incomplete or untrusted, unable to verify the certificate with serial number 7 issued by "CN=Test CA" up to a trusted root: `)
+	assert.ErrorContains(t, err, "x509: certificate signed by unknown authority")
+
+	_, err = VerifyCertificateChain(cert, nil, nil, nil)
+	assert.EqualError(t, err, "no trusted root certificates configured")
+}
+
+func TestVerifySCTFailures(t *testing.T) {
+	pki := newTestPKI(t)
+	ctx := context.Background()
+
+	cert := pki.issue(t, true)
+	assert.EqualError(t, VerifySCT(ctx, []*x509.Certificate{cert}, pki.logPubKeys(t)),
+		"certificate chain is incomplete, the issuer of the certificate is needed to verify the SCT")
+
+	assert.EqualError(t, VerifySCT(ctx, []*x509.Certificate{pki.issue(t, false), pki.ca}, pki.logPubKeys(t)),
+		"the certificate has no embedded SCT")
+
+	// the SCT is not signed by a trusted CT log
+	other := newTestPKI(t)
+	assert.ErrorContains(t, VerifySCT(ctx, []*x509.Certificate{cert, pki.ca}, other.logPubKeys(t)), "invalid SCT: ")
+
+	// the SCT was issued for a certificate of a different issuer
+	assert.EqualError(t, VerifySCT(ctx, []*x509.Certificate{cert, other.ca}, pki.logPubKeys(t)),
+		"invalid SCT: error verifying embedded SCT")
+}
+
+// fakeEntries serves the Rekor entries by their log index
+type fakeEntries struct {
+	entries.ClientService
+	log map[int64]models.LogEntry
+	err error
+}
+
+func (f fakeEntries) GetLogEntryByIndex(params *entries.GetLogEntryByIndexParams, _ ...entries.ClientOption) (*entries.GetLogEntryByIndexOK, error) {
+	return &entries.GetLogEntryByIndexOK{Payload: f.log[params.LogIndex]}, f.err
+}
+
+func (fakeEntries) SetTransport(runtime.ClientTransport) {}
+
+// rekorEntry returns a Rekor entry, the single entry of a log, with its
+// inclusion proof and signed entry timestamp signed by the log key
+func rekorEntry(t *testing.T, logKey *ecdsa.PrivateKey, logID string, logIndex int64, entry []byte) models.LogEntryAnon {
+	body := base64.StdEncoding.EncodeToString(entry)
+	integratedTime := time.Now().Unix()
+
+	// the keys are sorted when marshalled, as in the canonical form
+	payload, err := json.Marshal(map[string]any{
+		"body":           body,
+		"integratedTime": integratedTime,
+		"logID":          logID,
+		"logIndex":       logIndex,
+	})
+	require.NoError(t, err)
+	digest := sha256.Sum256(payload)
+	set, err := ecdsa.SignASN1(rand.Reader, logKey, digest[:])
+	require.NoError(t, err)
+
+	// in a log of a single entry the root hash is the hash of the leaf
+	leaf := sha256.Sum256(append([]byte{0}, entry...))
+	rootHash := hex.EncodeToString(leaf[:])
+	treeSize := int64(1)
+	proofIndex := int64(0)
+
+	return models.LogEntryAnon{
+		Body:           body,
+		IntegratedTime: &integratedTime,
+		LogID:          &logID,
+		LogIndex:       &logIndex,
+		Verification: &models.LogEntryAnonVerification{
+			InclusionProof: &models.InclusionProof{
+				Hashes:   []string{},
+				LogIndex: &proofIndex,
+				RootHash: &rootHash,
+				TreeSize: &treeSize,
+			},
+			SignedEntryTimestamp: set,
+		},
+	}
+}
+
+func TestVerifyInclusionProof(t *testing.T) {
+	ctx := context.Background()
+	logKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	logID := "c0d23d6ad406973f9559f3ba2d1ca01f84147d8ffc5b8445c224f98b9591801d"
+
+	entry := rekorEntry(t, logKey, logID, 42, []byte(`{"kind": "hashedrekord"}`))
+	other := rekorEntry(t, logKey, logID, 42, []byte(`{"kind": "intoto"}`))
+	// the same entry with an inclusion proof not leading to the root hash
+	untrusted := entry
+	proof := *entry.Verification.InclusionProof
+	proof.Hashes = []string{hex.EncodeToString(make([]byte, sha256.Size))}
+	untrusted.Verification = &models.LogEntryAnonVerification{
+		InclusionProof:       &proof,
+		SignedEntryTimestamp: entry.Verification.SignedEntryTimestamp,
+	}
+
+	sig, err := static.NewSignature([]byte("payload"), "c2lnbmF0dXJl", static.WithBundle(&bundle.RekorBundle{
+		SignedEntryTimestamp: entry.Verification.SignedEntryTimestamp,
+		Payload: bundle.RekorPayload{
+			Body:           entry.Body,
+			IntegratedTime: *entry.IntegratedTime,
+			LogIndex:       *entry.LogIndex,
+			LogID:          *entry.LogID,
+		},
+	}))
+	require.NoError(t, err)
+
+	noBundle, err := static.NewSignature([]byte("payload"), "c2lnbmF0dXJl")
+	require.NoError(t, err)
+
+	rekorPubKeys := &cosign.TrustedTransparencyLogPubKeys{Keys: map[string]cosign.TransparencyLogPubKey{
+		logID: {PubKey: &logKey.PublicKey, Status: tuf.Active},
+	}}
+
+	opts := func(e fakeEntries) *cosign.CheckOpts {
+		return &cosign.CheckOpts{
+			RekorClient:  &client.Rekor{Entries: e},
+			RekorPubKeys: rekorPubKeys,
+		}
+	}
+
+	logOf := func(e models.LogEntryAnon) map[int64]models.LogEntry {
+		return map[int64]models.LogEntry{42: {"uuid": e}}
+	}
+
+	assert.NoError(t, VerifyInclusionProof(ctx, sig, opts(fakeEntries{log: logOf(entry)})))
+
+	// signatures without a bundle are verified online by cosign
+	assert.NoError(t, VerifyInclusionProof(ctx, noBundle, &cosign.CheckOpts{}))
+
+	assert.EqualError(t, VerifyInclusionProof(ctx, sig, &cosign.CheckOpts{}),
+		"no Rekor client configured to fetch the inclusion proof of the Rekor entry")
+
+	assert.EqualError(t, VerifyInclusionProof(ctx, sig, opts(fakeEntries{err: errors.New("expected")})),
+		"unable to fetch the Rekor entry with log index 42: expected")
+
+	assert.EqualError(t, VerifyInclusionProof(ctx, sig, opts(fakeEntries{})),
+		"no Rekor entry found with log index 42")
+
+	assert.EqualError(t, VerifyInclusionProof(ctx, sig, opts(fakeEntries{log: logOf(other)})),
+		"the Rekor entry with log index 42 does not match the Rekor bundle of the signature")
+
+	assert.ErrorContains(t, VerifyInclusionProof(ctx, sig, opts(fakeEntries{log: logOf(untrusted)})),
+		"unable to verify the Rekor entry with log index 42: verifying inclusion proof: ")
+}
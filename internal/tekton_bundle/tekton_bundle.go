@@ -0,0 +1,222 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package tekton_bundle fetches the Task and Pipeline definitions from Tekton
+// OCI bundles and converts them into input for the rego policy evaluation.
+package tekton_bundle
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/sigstore/cosign/v2/pkg/cosign"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+	tektonoci "github.com/tektoncd/pipeline/pkg/remote/oci"
+	"sigs.k8s.io/yaml"
+
+	"github.com/enterprise-contract/ec-cli/internal/policy"
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+	"github.com/enterprise-contract/ec-cli/internal/utils/oci"
+)
+
+// Bundle is a Tekton bundle with the definitions it contains
+type Bundle struct {
+	// Ref is the reference to the bundle, pinned to the digest
+	Ref         name.Digest
+	Definitions []Definition
+}
+
+// Definition is a Task or Pipeline definition contained in a Tekton bundle
+type Definition struct {
+	Kind       string
+	Name       string
+	APIVersion string
+	Object     map[string]any
+}
+
+// Input is the policy input for a single definition within a Tekton bundle
+type Input struct {
+	Bundle     Descriptor     `json:"bundle"`
+	Definition map[string]any `json:"definition"`
+}
+
+// Descriptor describes the bundle and the layer the definition was read from
+type Descriptor struct {
+	Ref        string `json:"ref"`
+	Digest     string `json:"digest"`
+	Kind       string `json:"kind"`
+	Name       string `json:"name"`
+	APIVersion string `json:"api_version"`
+}
+
+// Fetch pulls the Tekton bundle with the given reference and reads all the
+// definitions from its layers. The bundle is fetched the same way as the
+// images being validated are, note that the OCI getter of the policy
+// downloader cannot be used here as it only extracts layers carrying the
+// org.opencontainers.image.title annotation, which Tekton bundles lack.
+func Fetch(ctx context.Context, ref string) (*Bundle, error) {
+	r, err := name.ParseReference(ref)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse bundle reference %s: %w", ref, err)
+	}
+
+	img, err := oci.NewClient(ctx).Image(r)
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch bundle %s: %w", ref, err)
+	}
+
+	digest, err := img.Digest()
+	if err != nil {
+		return nil, err
+	}
+
+	manifest, err := img.Manifest()
+	if err != nil {
+		return nil, fmt.Errorf("unable to read manifest of bundle %s: %w", ref, err)
+	}
+
+	if len(manifest.Layers) > tektonoci.MaximumBundleObjects {
+		return nil, fmt.Errorf("bundle %s contains more than the maximum %d allowed objects", ref, tektonoci.MaximumBundleObjects)
+	}
+
+	layers, err := img.Layers()
+	if err != nil {
+		return nil, fmt.Errorf("unable to read layers of bundle %s: %w", ref, err)
+	}
+
+	bundle := Bundle{
+		Ref:         r.Context().Digest(digest.String()),
+		Definitions: make([]Definition, 0, len(manifest.Layers)),
+	}
+
+	for i, l := range manifest.Layers {
+		d := Definition{
+			Kind:       l.Annotations[tektonoci.KindAnnotation],
+			Name:       l.Annotations[tektonoci.TitleAnnotation],
+			APIVersion: l.Annotations[tektonoci.APIVersionAnnotation],
+		}
+
+		if d.Kind == "" || d.Name == "" || d.APIVersion == "" {
+			return nil, fmt.Errorf("invalid tekton bundle: layer %s of %s does not contain the %s, %s and %s annotations",
+				l.Digest, ref, tektonoci.KindAnnotation, tektonoci.TitleAnnotation, tektonoci.APIVersionAnnotation)
+		}
+
+		if d.Object, err = readDefinition(layers[i]); err != nil {
+			return nil, fmt.Errorf("unable to read %s %s from bundle %s: %w", d.Kind, d.Name, ref, err)
+		}
+
+		log.Debugf("Read %s %s from bundle %s", d.Kind, d.Name, bundle.Ref)
+		bundle.Definitions = append(bundle.Definitions, d)
+	}
+
+	return &bundle, nil
+}
+
+// readDefinition reads the definition from the layer. Layers are expected to
+// be tarballs with a single file, for compatibility with Tekton, layers
+// holding the plain definition are also accepted.
+func readDefinition(layer v1.Layer) (map[string]any, error) {
+	rc, err := layer.Uncompressed()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	content, err := io.ReadAll(rc)
+	if err != nil {
+		return nil, err
+	}
+
+	tr := tar.NewReader(bytes.NewReader(content))
+	if _, err := tr.Next(); err == nil {
+		if content, err = io.ReadAll(tr); err != nil {
+			return nil, err
+		}
+	}
+
+	var obj map[string]any
+	if err := yaml.Unmarshal(content, &obj); err != nil {
+		return nil, err
+	}
+
+	if obj == nil {
+		return nil, errors.New("empty definition")
+	}
+
+	return obj, nil
+}
+
+// VerifySignature verifies the signature of the bundle image using the
+// signing materials from the policy.
+func VerifySignature(ctx context.Context, b *Bundle, p policy.Policy) error {
+	opts, err := p.CheckOpts()
+	if err != nil {
+		return err
+	}
+
+	// Set the ClaimVerifier on a shallow *copy* of CheckOpts to avoid unexpected side-effects
+	o := *opts
+	o.ClaimVerifier = cosign.SimpleClaimVerifier
+	_, _, err = oci.NewClient(ctx).VerifyImageSignatures(b.Ref, &o)
+
+	return err
+}
+
+// NewInput converts the definition from the bundle to the policy input
+func NewInput(b *Bundle, d Definition) Input {
+	return Input{
+		Bundle: Descriptor{
+			Ref:        b.Ref.String(),
+			Digest:     b.Ref.DigestStr(),
+			Kind:       d.Kind,
+			Name:       d.Name,
+			APIVersion: d.APIVersion,
+		},
+		Definition: d.Object,
+	}
+}
+
+// WriteInputFile writes the policy input for the definition from the bundle
+// to a temporary file, returning the path of the file.
+func WriteInputFile(ctx context.Context, b *Bundle, d Definition) (string, error) {
+	content, err := json.Marshal(NewInput(b, d))
+	if err != nil {
+		return "", err
+	}
+
+	fs := utils.FS(ctx)
+	f, err := afero.TempFile(fs, "", "tekton-bundle-*.json")
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	if _, err := f.Write(content); err != nil {
+		return "", err
+	}
+
+	log.Debugf("Input for %s %s from bundle %s written to %s", d.Kind, d.Name, b.Ref, f.Name())
+
+	return f.Name(), nil
+}
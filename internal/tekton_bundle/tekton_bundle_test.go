@@ -0,0 +1,198 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package tekton_bundle
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	tektonoci "github.com/tektoncd/pipeline/pkg/remote/oci"
+
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+func tarLayer(t *testing.T, name, content string) v1.Layer {
+	var buff bytes.Buffer
+	w := tar.NewWriter(&buff)
+	require.NoError(t, w.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content))}))
+	_, err := w.Write([]byte(content))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	return static.NewLayer(buff.Bytes(), types.OCILayer)
+}
+
+func bundleImage(t *testing.T, layers ...mutate.Addendum) v1.Image {
+	img, err := mutate.Append(empty.Image, layers...)
+	require.NoError(t, err)
+
+	return img
+}
+
+func taskLayer(l v1.Layer, name string) mutate.Addendum {
+	return mutate.Addendum{
+		Layer: l,
+		Annotations: map[string]string{
+			tektonoci.KindAnnotation:       "task",
+			tektonoci.TitleAnnotation:      name,
+			tektonoci.APIVersionAnnotation: "v1",
+		},
+	}
+}
+
+const buildTask = `apiVersion: tekton.dev/v1
+kind: Task
+metadata:
+  name: build
+spec:
+  steps:
+  - name: build
+    image: registry.io/builder@sha256:0c6e9f3b1e8e5a4c0e2e6b5eb2a6fa1b8d5a7d7b1c1b4e0a6d8f6d6b7a0e3c1d
+`
+
+const testTask = `{"apiVersion": "tekton.dev/v1", "kind": "Task", "metadata": {"name": "test"}}`
+
+func TestFetch(t *testing.T) {
+	l := &bytes.Buffer{}
+	r := httptest.NewServer(registry.New(registry.Logger(log.New(l, "", 0))))
+	t.Cleanup(r.Close)
+
+	u, err := url.Parse(r.URL)
+	require.NoError(t, err)
+
+	ref, err := name.ParseReference(fmt.Sprintf("localhost:%s/tasks/bundle:v1", u.Port()))
+	require.NoError(t, err)
+
+	img := bundleImage(t,
+		taskLayer(tarLayer(t, "build", buildTask), "build"),
+		taskLayer(static.NewLayer([]byte(testTask), types.OCILayer), "test"))
+	require.NoError(t, remote.Push(ref, img))
+
+	digest, err := img.Digest()
+	require.NoError(t, err)
+
+	b, err := Fetch(context.Background(), ref.String())
+	require.NoError(t, err)
+
+	assert.Equal(t, fmt.Sprintf("localhost:%s/tasks/bundle@%s", u.Port(), digest), b.Ref.String())
+	require.Len(t, b.Definitions, 2)
+
+	assert.Equal(t, "task", b.Definitions[0].Kind)
+	assert.Equal(t, "build", b.Definitions[0].Name)
+	assert.Equal(t, "v1", b.Definitions[0].APIVersion)
+	assert.Equal(t, "build", b.Definitions[0].Object["metadata"].(map[string]any)["name"])
+	assert.NotNil(t, b.Definitions[0].Object["spec"])
+
+	assert.Equal(t, "test", b.Definitions[1].Name)
+	assert.Equal(t, "Task", b.Definitions[1].Object["kind"])
+}
+
+func TestFetchErrors(t *testing.T) {
+	cases := []struct {
+		name   string
+		layers []mutate.Addendum
+		err    string
+	}{
+		{
+			name:   "missing annotations",
+			layers: []mutate.Addendum{{Layer: tarLayer(t, "build", buildTask)}},
+			err:    "does not contain the dev.tekton.image.kind, dev.tekton.image.name and dev.tekton.image.apiVersion annotations",
+		},
+		{
+			name:   "empty definition",
+			layers: []mutate.Addendum{taskLayer(tarLayer(t, "build", ""), "build")},
+			err:    "unable to read task build from bundle",
+		},
+	}
+
+	l := &bytes.Buffer{}
+	r := httptest.NewServer(registry.New(registry.Logger(log.New(l, "", 0))))
+	t.Cleanup(r.Close)
+
+	u, err := url.Parse(r.URL)
+	require.NoError(t, err)
+
+	for i, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ref, err := name.ParseReference(fmt.Sprintf("localhost:%s/tasks/bundle:%d", u.Port(), i))
+			require.NoError(t, err)
+
+			require.NoError(t, remote.Push(ref, bundleImage(t, c.layers...)))
+
+			_, err = Fetch(context.Background(), ref.String())
+			assert.ErrorContains(t, err, c.err)
+		})
+	}
+}
+
+func TestWriteInputFile(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	ctx := utils.WithFS(context.Background(), fs)
+
+	b := &Bundle{
+		Ref: name.MustParseReference("registry.io/tasks/bundle@sha256:4e388ab32b10dc8dbc7e28144f552830adc74787c1e2c0824032078a79f227fb").(name.Digest),
+	}
+	d := Definition{
+		Kind:       "task",
+		Name:       "build",
+		APIVersion: "v1",
+		Object:     map[string]any{"kind": "Task"},
+	}
+
+	path, err := WriteInputFile(ctx, b, d)
+	require.NoError(t, err)
+
+	f, err := fs.Open(path)
+	require.NoError(t, err)
+	content, err := io.ReadAll(f)
+	require.NoError(t, err)
+
+	var input map[string]any
+	require.NoError(t, json.Unmarshal(content, &input))
+
+	assert.Equal(t, map[string]any{
+		"bundle": map[string]any{
+			"ref":         "registry.io/tasks/bundle@sha256:4e388ab32b10dc8dbc7e28144f552830adc74787c1e2c0824032078a79f227fb",
+			"digest":      "sha256:4e388ab32b10dc8dbc7e28144f552830adc74787c1e2c0824032078a79f227fb",
+			"kind":        "task",
+			"name":        "build",
+			"api_version": "v1",
+		},
+		"definition": map[string]any{"kind": "Task"},
+	}, input)
+}
@@ -0,0 +1,162 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package tekton_bundle
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/hashicorp/go-multierror"
+	"sigs.k8s.io/yaml"
+
+	"github.com/enterprise-contract/ec-cli/internal/format"
+	"github.com/enterprise-contract/ec-cli/internal/image"
+	"github.com/enterprise-contract/ec-cli/internal/policy"
+	"github.com/enterprise-contract/ec-cli/internal/tracker"
+	"github.com/enterprise-contract/ec-cli/internal/version"
+)
+
+// Possible formats the trust report can be written as.
+const (
+	JSON = "json"
+	YAML = "yaml"
+)
+
+// TrustOptions configure the verification of the trust of a bundle
+type TrustOptions struct {
+	// ExpiringWithin is the duration within which expiring bundles are
+	// reported as expiring, with zero all bundles with an expiration are
+	ExpiringWithin time.Duration
+	// IgnoreSignature skips the verification of the signature of the bundle
+	IgnoreSignature bool
+}
+
+// TrustResult is the outcome of the verification of the trust of a bundle
+type TrustResult struct {
+	Ref               string        `json:"ref"`
+	Digest            string        `json:"digest"`
+	Trust             tracker.Trust `json:"trust"`
+	ExpiresOn         *time.Time    `json:"expires_on,omitempty"`
+	SignatureVerified bool          `json:"signature_verified"`
+	SignatureError    string        `json:"signature_error,omitempty"`
+	Success           bool          `json:"success"`
+}
+
+// VerifyTrust verifies that the bundle with the given reference is in the
+// trusted tasks, at the effective time of the policy, and that it is signed
+// according to the signing materials from the policy. The bundle is
+// successfully verified when it is trusted, or expiring, and its signature is
+// verified.
+func VerifyTrust(ctx context.Context, ref string, trusted tracker.Tracker, p policy.Policy, opts TrustOptions) (*TrustResult, error) {
+	r, err := image.ParseAndResolve(ctx, ref, name.StrictValidation)
+	if err != nil {
+		return nil, fmt.Errorf("unable to resolve bundle %s: %w", ref, err)
+	}
+
+	digest, err := name.NewDigest(r.Repository+"@"+r.Digest, name.StrictValidation)
+	if err != nil {
+		return nil, fmt.Errorf("unable to resolve bundle %s: %w", ref, err)
+	}
+
+	result := TrustResult{
+		Ref:    ref,
+		Digest: digest.DigestStr(),
+	}
+	result.Trust, result.ExpiresOn = trusted.BundleTrust(r.Repository, r.Tag, r.Digest, p.EffectiveTime(), opts.ExpiringWithin)
+
+	if !opts.IgnoreSignature {
+		if err := VerifySignature(ctx, &Bundle{Ref: digest}, p); err != nil {
+			result.SignatureError = err.Error()
+		} else {
+			result.SignatureVerified = true
+		}
+	}
+
+	result.Success = (result.Trust == tracker.Trusted || result.Trust == tracker.Expiring) &&
+		(opts.IgnoreSignature || result.SignatureVerified)
+
+	return &result, nil
+}
+
+// TrustReport is the report of the verification of the trust of bundles
+type TrustReport struct {
+	Success       bool          `json:"success"`
+	Bundles       []TrustResult `json:"bundles"`
+	EffectiveTime time.Time     `json:"effective-time"`
+	EcVersion     string        `json:"ec-version"`
+}
+
+// NewTrustReport returns the report of the given results, successful when all
+// the bundles were verified successfully
+func NewTrustReport(results []TrustResult, p policy.Policy) TrustReport {
+	success := true
+	for _, r := range results {
+		if !r.Success {
+			success = false
+		}
+	}
+
+	info, _ := version.ComputeInfo()
+
+	return TrustReport{
+		Success:       success,
+		Bundles:       results,
+		EffectiveTime: p.EffectiveTime().UTC(),
+		EcVersion:     info.Version,
+	}
+}
+
+// WriteAll writes the report to all the given targets.
+func (r TrustReport) WriteAll(targets []string, p format.TargetParser) (allErrors error) {
+	if len(targets) == 0 {
+		targets = append(targets, JSON)
+	}
+	for _, targetName := range targets {
+		target, err := p.Parse(targetName)
+		if err != nil {
+			allErrors = multierror.Append(allErrors, err)
+			continue
+		}
+
+		var data []byte
+		switch target.Format {
+		case JSON:
+			data, err = json.Marshal(r)
+		case YAML:
+			data, err = yaml.Marshal(r)
+		default:
+			err = fmt.Errorf("%q is not a valid report format", target.Format)
+		}
+		if err != nil {
+			allErrors = multierror.Append(allErrors, err)
+			continue
+		}
+
+		if !bytes.HasSuffix(data, []byte{'\n'}) {
+			data = append(data, "\n"...)
+		}
+
+		if _, err := target.Write(data); err != nil {
+			allErrors = multierror.Append(allErrors, err)
+		}
+	}
+	return
+}
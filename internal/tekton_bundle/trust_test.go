@@ -0,0 +1,187 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package tekton_bundle
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	hd "github.com/MakeNowJust/heredoc"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/format"
+	"github.com/enterprise-contract/ec-cli/internal/image"
+	"github.com/enterprise-contract/ec-cli/internal/policy"
+	"github.com/enterprise-contract/ec-cli/internal/tracker"
+	"github.com/enterprise-contract/ec-cli/internal/utils/oci"
+	"github.com/enterprise-contract/ec-cli/internal/utils/oci/fake"
+)
+
+const (
+	trustedDigest  = "sha256:4e388ab32b10dc8dbc7e28144f552830adc74787c1e2c0824032078a79f227fb"
+	replacedDigest = "sha256:0c6e9f3b1e8e5a4c0e2e6b5eb2a6fa1b8d5a7d7b1c1b4e0a6d8f6d6b7a0e3c1d"
+)
+
+func TestVerifyTrust(t *testing.T) {
+	trusted, err := tracker.Parse([]byte(hd.Doc(`
+		trusted_tasks:
+		  oci://registry.io/tasks/buildah:0.1:
+		    - ref: ` + trustedDigest + `
+		      effective_on: "2024-06-01T00:00:00Z"
+		    - ref: ` + replacedDigest + `
+		      effective_on: "2024-05-01T00:00:00Z"
+		      expires_on: "2024-06-01T00:00:00Z"
+	`)))
+	require.NoError(t, err)
+
+	expiresOn := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		name      string
+		ref       string
+		signature error
+		opts      TrustOptions
+		expected  TrustResult
+	}{
+		{
+			name: "trusted by tag",
+			ref:  "registry.io/tasks/buildah:0.1",
+			expected: TrustResult{
+				Ref:               "registry.io/tasks/buildah:0.1",
+				Digest:            trustedDigest,
+				Trust:             tracker.Trusted,
+				SignatureVerified: true,
+				Success:           true,
+			},
+		},
+		{
+			name: "expiring",
+			ref:  "registry.io/tasks/buildah:0.1@" + replacedDigest,
+			expected: TrustResult{
+				Ref:               "registry.io/tasks/buildah:0.1@" + replacedDigest,
+				Digest:            replacedDigest,
+				Trust:             tracker.Expiring,
+				ExpiresOn:         &expiresOn,
+				SignatureVerified: true,
+				Success:           true,
+			},
+		},
+		{
+			name: "untrusted",
+			ref:  "registry.io/tasks/buildah:0.2@" + trustedDigest,
+			expected: TrustResult{
+				Ref:               "registry.io/tasks/buildah:0.2@" + trustedDigest,
+				Digest:            trustedDigest,
+				Trust:             tracker.Untrusted,
+				SignatureVerified: true,
+			},
+		},
+		{
+			name:      "unsigned",
+			ref:       "registry.io/tasks/buildah:0.1",
+			signature: errors.New("no signatures found"),
+			expected: TrustResult{
+				Ref:            "registry.io/tasks/buildah:0.1",
+				Digest:         trustedDigest,
+				Trust:          tracker.Trusted,
+				SignatureError: "no signatures found",
+			},
+		},
+		{
+			name:      "ignored signature",
+			ref:       "registry.io/tasks/buildah:0.1",
+			signature: errors.New("no signatures found"),
+			opts:      TrustOptions{IgnoreSignature: true},
+			expected: TrustResult{
+				Ref:     "registry.io/tasks/buildah:0.1",
+				Digest:  trustedDigest,
+				Trust:   tracker.Trusted,
+				Success: true,
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			client := fake.FakeClient{}
+			client.On("VerifyImageSignatures", mock.Anything, mock.Anything).Return(nil, true, c.signature)
+
+			ctx := oci.WithClient(context.Background(), &client)
+			ctx = context.WithValue(ctx, image.RemoteHead, func(name.Reference, ...remote.Option) (*v1.Descriptor, error) {
+				return &v1.Descriptor{Digest: v1.Hash{Algorithm: "sha256", Hex: trustedDigest[7:]}}, nil
+			})
+
+			p, err := policy.NewInputPolicy(ctx, "", "2024-05-20T00:00:00Z")
+			require.NoError(t, err)
+
+			result, err := VerifyTrust(ctx, c.ref, trusted, p, c.opts)
+			require.NoError(t, err)
+			assert.Equal(t, c.expected, *result)
+		})
+	}
+}
+
+func TestVerifyTrustInvalidReference(t *testing.T) {
+	p, err := policy.NewInputPolicy(context.Background(), "", policy.Now)
+	require.NoError(t, err)
+
+	_, err = VerifyTrust(context.Background(), "registry.io/tasks/Buildah", tracker.Tracker{}, p, TrustOptions{})
+	assert.ErrorContains(t, err, "unable to resolve bundle registry.io/tasks/Buildah")
+}
+
+func TestTrustReport(t *testing.T) {
+	p, err := policy.NewInputPolicy(context.Background(), "", "2024-05-20T00:00:00Z")
+	require.NoError(t, err)
+
+	report := NewTrustReport([]TrustResult{
+		{Ref: "registry.io/tasks/buildah:0.1", Digest: trustedDigest, Trust: tracker.Trusted, Success: true},
+		{Ref: "registry.io/tasks/buildah:0.2", Digest: trustedDigest, Trust: tracker.Untrusted},
+	}, p)
+	assert.False(t, report.Success)
+
+	fs := afero.NewMemMapFs()
+	var out bytes.Buffer
+	err = report.WriteAll([]string{"json", "yaml=/report.yaml"}, format.NewTargetParser(JSON, format.Options{}, &out, fs))
+	require.NoError(t, err)
+
+	got := TrustReport{}
+	require.NoError(t, json.Unmarshal(out.Bytes(), &got))
+	assert.Equal(t, report.Bundles, got.Bundles)
+	assert.Equal(t, time.Date(2024, 5, 20, 0, 0, 0, 0, time.UTC), got.EffectiveTime)
+
+	yaml, err := afero.ReadFile(fs, "/report.yaml")
+	require.NoError(t, err)
+	assert.Contains(t, string(yaml), "trust: untrusted")
+
+	err = report.WriteAll([]string{"summary"}, format.NewTargetParser(JSON, format.Options{}, &out, fs))
+	assert.EqualError(t, err, `1 error occurred:
+	* "summary" is not a valid report format
+
+`)
+}
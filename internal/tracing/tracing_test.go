@@ -0,0 +1,123 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package tracing
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func recordSpans(t *testing.T) *tracetest.InMemoryExporter {
+	exporter := tracetest.NewInMemoryExporter()
+	tracer = sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)).Tracer(tracerName)
+	t.Cleanup(func() {
+		tracer = nil
+	})
+
+	return exporter
+}
+
+func TestStartEnd(t *testing.T) {
+	exporter := recordSpans(t)
+
+	ctx, parent := Start(context.Background(), "parent")
+	_, child := Start(ctx, "child", attribute.String("source", "git::example.com/org/repo"))
+	End(child, errors.New("boom"))
+	End(parent, nil)
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 2)
+
+	assert.Equal(t, "child", spans[0].Name)
+	assert.Equal(t, spans[1].SpanContext.SpanID(), spans[0].Parent.SpanID())
+	assert.Equal(t, []attribute.KeyValue{attribute.String("source", "git::example.com/org/repo")}, spans[0].Attributes)
+	assert.Equal(t, codes.Error, spans[0].Status.Code)
+	assert.Equal(t, "boom", spans[0].Status.Description)
+	require.Len(t, spans[0].Events, 1)
+	assert.Equal(t, "exception", spans[0].Events[0].Name)
+
+	assert.Equal(t, "parent", spans[1].Name)
+	assert.Equal(t, codes.Unset, spans[1].Status.Code)
+}
+
+func TestDisabled(t *testing.T) {
+	ctx := context.Background()
+	got, span := Start(ctx, "span")
+	assert.Equal(t, ctx, got)
+	assert.False(t, span.IsRecording())
+	End(span, errors.New("boom"))
+
+	called := false
+	Handler("request", http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		called = true
+		assert.Equal(t, ctx, r.Context())
+	})).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/validate", nil).WithContext(ctx))
+	assert.True(t, called)
+}
+
+func TestHandler(t *testing.T) {
+	exporter := recordSpans(t)
+
+	ctx, server := Start(context.Background(), "server")
+	h := Handler("request", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, span := Start(r.Context(), "work")
+		End(span, nil)
+	}))
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/validate", nil).WithContext(ctx))
+	End(server, nil)
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 3)
+
+	work, request := spans[0], spans[1]
+	assert.Equal(t, "work", work.Name)
+	assert.Equal(t, request.SpanContext.SpanID(), work.Parent.SpanID())
+	assert.Equal(t, "request", request.Name)
+	assert.False(t, request.Parent.IsValid())
+	assert.NotEqual(t, spans[2].SpanContext.TraceID(), request.SpanContext.TraceID())
+}
+
+func TestSetup(t *testing.T) {
+	previous := otel.GetTracerProvider()
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previous)
+		tracer = nil
+	})
+
+	_, err := Setup(context.Background(), "localhost:4317")
+	assert.EqualError(t, err, `invalid OTLP endpoint "localhost:4317", expected a URL, e.g. http://localhost:4317`)
+
+	shutdown, err := Setup(context.Background(), "http://localhost:4317")
+	require.NoError(t, err)
+	assert.IsType(t, &sdktrace.TracerProvider{}, otel.GetTracerProvider())
+	assert.NotNil(t, tracer)
+	assert.NoError(t, shutdown(context.Background()))
+}
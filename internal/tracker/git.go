@@ -27,6 +27,7 @@ import (
 
 	gba "github.com/Maldris/go-billy-afero"
 	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/plumbing/cache"
 	"github.com/go-git/go-git/v5/plumbing/object"
 	"github.com/go-git/go-git/v5/storage/filesystem"
@@ -91,13 +92,39 @@ func clone(ctx context.Context, repository string) (*git.Repository, error) {
 	return git.CloneContext(ctx, s, bfs, &opts)
 }
 
-func (g *gitTracker) GitResolve(ctx context.Context, repository, path string) (string, error) {
+// repository returns the clone of the repository, cloning it only once
+func (g *gitTracker) repository(ctx context.Context, repository string) (*git.Repository, error) {
 	cfn := func() (*git.Repository, error) {
 		return clone(ctx, repository)
 	}
 	rfn, _ := g.repositories.LoadOrStore(repository, sync.OnceValues(cfn))
 
-	r, err := rfn.(func() (*git.Repository, error))()
+	return rfn.(func() (*git.Repository, error))()
+}
+
+// GitResolveRevision returns the id of the commit the revision, i.e. a branch, a tag
+// or an abbreviated commit id, points to in the repository
+func (g *gitTracker) GitResolveRevision(ctx context.Context, repository, revision string) (string, error) {
+	r, err := g.repository(ctx, repository)
+	if err != nil {
+		return "", err
+	}
+
+	hash, err := r.ResolveRevision(plumbing.Revision(revision))
+	if errors.Is(err, plumbing.ErrReferenceNotFound) {
+		// only the default branch is cloned as a local branch, other
+		// branches are found among the remote branches
+		hash, err = r.ResolveRevision(plumbing.Revision("origin/" + revision))
+	}
+	if err != nil {
+		return "", fmt.Errorf("unable to resolve the revision %q in %s: %w", revision, repository, err)
+	}
+
+	return hash.String(), nil
+}
+
+func (g *gitTracker) GitResolve(ctx context.Context, repository, path string) (string, error) {
+	r, err := g.repository(ctx, repository)
 	if err != nil {
 		return "", err
 	}
@@ -20,7 +20,6 @@ import (
 	"context"
 	"io"
 
-	"github.com/google/go-containerregistry/pkg/authn"
 	"github.com/google/go-containerregistry/pkg/name"
 	v1 "github.com/google/go-containerregistry/pkg/v1"
 	"github.com/google/go-containerregistry/pkg/v1/empty"
@@ -28,6 +27,8 @@ import (
 	"github.com/google/go-containerregistry/pkg/v1/remote"
 	"github.com/google/go-containerregistry/pkg/v1/static"
 	"github.com/google/go-containerregistry/pkg/v1/types"
+
+	"github.com/enterprise-contract/ec-cli/internal/utils/oci"
 )
 
 const (
@@ -64,7 +65,7 @@ func PullImage(ctx context.Context, imageRef string) ([]byte, error) {
 		return nil, err
 	}
 
-	img, err := r(ctx).read(ref, remote.WithAuthFromKeychain(authn.DefaultKeychain))
+	img, err := r(ctx).read(ref, remote.WithAuthFromKeychain(oci.Keychain(ctx)))
 	if err != nil {
 		return nil, err
 	}
@@ -128,7 +129,7 @@ func PushImage(ctx context.Context, imageRef string, data []byte, invocation str
 		return
 	}
 
-	return r(ctx).write(ref, bundle, remote.WithAuthFromKeychain(authn.DefaultKeychain))
+	return r(ctx).write(ref, bundle, remote.WithAuthFromKeychain(oci.Keychain(ctx)))
 }
 
 func r(ctx context.Context) registry {
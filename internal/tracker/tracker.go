@@ -20,6 +20,7 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"regexp"
 	"strings"
 	"time"
 
@@ -175,6 +176,9 @@ func (t *Tracker) trackImageReferences(ctx context.Context, urls []string, fresh
 	return nil
 }
 
+// commitID matches full SHA-1 or SHA-256 git commit ids
+var commitID = regexp.MustCompile(`^[0-9a-f]{40}([0-9a-f]{24})?$`)
+
 func (t *Tracker) trackGitReferences(ctx context.Context, urls []string, freshen bool) error {
 	effective_on := effectiveOn()
 
@@ -216,9 +220,22 @@ func (t *Tracker) trackGitReferences(ctx context.Context, urls []string, freshen
 				return err
 			}
 			path = rest
-		} else if freshen {
-			// nothing prevents the user using --freshen and revision, so log what revision is being used.
-			log.Debugf("--freshen used, but a revision is also provided. Using provided revision: %q", rev)
+		} else {
+			if freshen {
+				// nothing prevents the user using --freshen and revision, so log what revision is being used.
+				log.Debugf("--freshen used, but a revision is also provided. Using provided revision: %q", rev)
+			}
+
+			if !commitID.MatchString(rev) {
+				// branches, tags and abbreviated commit ids can move or
+				// become ambiguous, so only commit ids are recorded
+				resolved, err := g.GitResolveRevision(ctx, repository, rev)
+				if err != nil {
+					return err
+				}
+				log.Debugf("Resolved revision %q of %q to commit %q", rev, u, resolved)
+				rev = resolved
+			}
 		}
 
 		t.addTrustedTaskRecord("", taskRecord{
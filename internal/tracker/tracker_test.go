@@ -544,16 +544,16 @@ func TestTrackGitReferences(t *testing.T) {
 		TrustedTasks: make(map[string][]taskRecord),
 	}
 
-	require.NoError(t, tracker.trackGitReferences(context.Background(), []string{"git+https://git.io/organization/repository//task1.yaml@rev1", "git+ssh://got.io/organization/repository//dir/task2.yaml@rev2"}, false))
+	require.NoError(t, tracker.trackGitReferences(context.Background(), []string{"git+https://git.io/organization/repository//task1.yaml@2d2ecc8ef0c2b1d1cc3d46e4f4b1a2bd9cf8d6a1", "git+ssh://got.io/organization/repository//dir/task2.yaml@4f6c5e2c9a8f2d1f2f9e6b7b8a0c1d2e3f4a5b6c"}, false))
 
 	expected := map[string][]taskRecord{
 		"git+https://git.io/organization/repository//task1.yaml": {{
-			Ref:         "rev1",
+			Ref:         "2d2ecc8ef0c2b1d1cc3d46e4f4b1a2bd9cf8d6a1",
 			Repository:  "git+https://git.io/organization/repository//task1.yaml",
 			EffectiveOn: effectiveOn(),
 		}},
 		"git+ssh://got.io/organization/repository//dir/task2.yaml": {{
-			Ref:         "rev2",
+			Ref:         "4f6c5e2c9a8f2d1f2f9e6b7b8a0c1d2e3f4a5b6c",
 			Repository:  "git+ssh://got.io/organization/repository//dir/task2.yaml",
 			EffectiveOn: effectiveOn(),
 		}},
@@ -610,6 +610,55 @@ func TestTrackGitReferencesWithoutCommitId(t *testing.T) {
 	assert.Nil(t, matches)
 }
 
+func TestTrackGitReferencesResolvingRevisions(t *testing.T) {
+	tracker := &Tracker{
+		TrustedTasks: make(map[string][]taskRecord),
+	}
+
+	fs := afero.NewMemMapFs()
+	ctx := utils.WithFS(context.Background(), fs)
+
+	f, err := os.Open("testdata/repository.zip")
+	require.NoError(t, err)
+
+	i, err := os.Stat("testdata/repository.zip")
+	require.NoError(t, err)
+
+	z, err := zip.NewReader(f, i.Size())
+	require.NoError(t, err)
+
+	rfs := gba.New(zipfs.New(z), "", false)
+
+	client.InstallProtocol("test", server.NewServer(server.NewFilesystemLoader(rfs)))
+
+	require.NoError(t, tracker.trackGitReferences(ctx, []string{"git+test://git.io/repository/.git//tasks/task1/0.1/task.yaml@0916963", "git+test://git.io/repository/.git//tasks/task2/0.2/task.yaml@main"}, false))
+
+	expected := map[string][]taskRecord{
+		"git+test://git.io/repository/.git//tasks/task1/0.1/task.yaml": {{
+			Ref:         "0916963bac30ea708c0ded4dd9d160fc148fd46f",
+			Repository:  "git+test://git.io/repository/.git//tasks/task1/0.1/task.yaml",
+			EffectiveOn: effectiveOn(),
+		}},
+		"git+test://git.io/repository/.git//tasks/task2/0.2/task.yaml": {{
+			Ref:         "acf3f1907b51c0e15809a61536bba71809daec68",
+			Repository:  "git+test://git.io/repository/.git//tasks/task2/0.2/task.yaml",
+			EffectiveOn: effectiveOn(),
+		}},
+	}
+
+	if !cmp.Equal(tracker.TrustedTasks, expected) {
+		t.Errorf("expected vs got: %s", cmp.Diff(tracker.TrustedTasks, expected))
+	}
+
+	err = tracker.trackGitReferences(ctx, []string{"git+test://git.io/repository/.git//tasks/task1/0.1/task.yaml@nope"}, false)
+	assert.ErrorContains(t, err, `unable to resolve the revision "nope" in git+test://git.io/repository/.git`)
+
+	// check to make sure we do not leave temp files around
+	matches, err := afero.Glob(fs, "tmp/*")
+	require.NoError(t, err)
+	assert.Nil(t, matches)
+}
+
 func TestTrackGitReferencesWithoutFreshen(t *testing.T) {
 	tracker := &Tracker{
 		TrustedTasks: map[string][]taskRecord{
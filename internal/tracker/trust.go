@@ -0,0 +1,96 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package tracker
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Trust is the trust of a Tekton bundle according to the trusted tasks
+type Trust string
+
+const (
+	// Trusted bundles are in the trusted tasks and do not expire
+	Trusted Trust = "trusted"
+	// Expiring bundles are in the trusted tasks, but expire as a newer bundle
+	// replaces them
+	Expiring Trust = "expiring"
+	// Expired bundles were in the trusted tasks, but have expired
+	Expired Trust = "expired"
+	// Untrusted bundles are not in the trusted tasks
+	Untrusted Trust = "untrusted"
+)
+
+// Parse parses the trusted tasks, as output by Track
+func Parse(data []byte) (Tracker, error) {
+	if len(data) == 0 {
+		return Tracker{}, errors.New("no trusted tasks found, the trusted tasks are empty")
+	}
+
+	t, err := newTracker(data)
+	if err != nil {
+		return Tracker{}, fmt.Errorf("unable to parse the trusted tasks: %w", err)
+	}
+
+	return t, nil
+}
+
+// BundleTrust returns the trust of the Tekton bundle from the given repository
+// with the given tag, which can be empty, and digest at the given time. The
+// bundle is looked up in the records of the repository with the tag, and of
+// the repository. Bundles expiring within the given duration from the given
+// time are reported as expiring, with a zero duration all bundles with an
+// expiration are. The expiration of the trusted, expiring and expired bundles
+// is returned.
+func (t Tracker) BundleTrust(repository, tag, digest string, now time.Time, within time.Duration) (Trust, *time.Time) {
+	groups := []string{ociPrefix + repository}
+	if tag != "" {
+		groups = append([]string{fmt.Sprintf("%s%s:%s", ociPrefix, repository, tag)}, groups...)
+	}
+
+	trust := Untrusted
+	var expiresOn *time.Time
+	for _, group := range groups {
+		for _, r := range t.TrustedTasks[group] {
+			if r.Ref != digest {
+				continue
+			}
+
+			if r.ExpiresOn == nil {
+				return Trusted, nil
+			}
+
+			if now.Before(*r.ExpiresOn) {
+				if within != 0 && r.ExpiresOn.Sub(now) > within {
+					return Trusted, r.ExpiresOn
+				}
+				if trust != Expiring || r.ExpiresOn.After(*expiresOn) {
+					trust, expiresOn = Expiring, r.ExpiresOn
+				}
+				continue
+			}
+
+			if trust == Untrusted || (trust == Expired && r.ExpiresOn.After(*expiresOn)) {
+				trust, expiresOn = Expired, r.ExpiresOn
+			}
+		}
+	}
+
+	return trust, expiresOn
+}
@@ -0,0 +1,97 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package tracker
+
+import (
+	"testing"
+	"time"
+
+	hd "github.com/MakeNowJust/heredoc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	_, err := Parse(nil)
+	assert.EqualError(t, err, "no trusted tasks found, the trusted tasks are empty")
+
+	_, err = Parse([]byte("trusted_tasks: ["))
+	assert.ErrorContains(t, err, "unable to parse the trusted tasks")
+
+	tracker, err := Parse([]byte(hd.Doc(`
+		trusted_tasks:
+		  oci://registry.io/tasks/buildah:0.1:
+		    - ref: sha256:abc
+		      effective_on: "2024-01-01T00:00:00Z"
+	`)))
+	require.NoError(t, err)
+	assert.Len(t, tracker.TrustedTasks["oci://registry.io/tasks/buildah:0.1"], 1)
+}
+
+func TestBundleTrust(t *testing.T) {
+	tracker, err := Parse([]byte(hd.Doc(`
+		trusted_tasks:
+		  oci://registry.io/tasks/buildah:0.1:
+		    - ref: sha256:latest
+		      effective_on: "2024-06-01T00:00:00Z"
+		    - ref: sha256:replaced
+		      effective_on: "2024-05-01T00:00:00Z"
+		      expires_on: "2024-06-01T00:00:00Z"
+		    - ref: sha256:expired
+		      effective_on: "2024-04-01T00:00:00Z"
+		      expires_on: "2024-05-01T00:00:00Z"
+		  oci://registry.io/tasks/git-clone:
+		    - ref: sha256:untagged
+		      effective_on: "2024-05-01T00:00:00Z"
+	`)))
+	require.NoError(t, err)
+
+	now := time.Date(2024, 5, 20, 0, 0, 0, 0, time.UTC)
+	expiresOn := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	expiredOn := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		name       string
+		repository string
+		tag        string
+		digest     string
+		within     time.Duration
+		trust      Trust
+		expiresOn  *time.Time
+	}{
+		{name: "latest", repository: "registry.io/tasks/buildah", tag: "0.1", digest: "sha256:latest", trust: Trusted},
+		{name: "replaced", repository: "registry.io/tasks/buildah", tag: "0.1", digest: "sha256:replaced", trust: Expiring, expiresOn: &expiresOn},
+		{name: "replaced within", repository: "registry.io/tasks/buildah", tag: "0.1", digest: "sha256:replaced", within: 14 * 24 * time.Hour, trust: Expiring, expiresOn: &expiresOn},
+		{name: "replaced not within", repository: "registry.io/tasks/buildah", tag: "0.1", digest: "sha256:replaced", within: 7 * 24 * time.Hour, trust: Trusted, expiresOn: &expiresOn},
+		{name: "expired", repository: "registry.io/tasks/buildah", tag: "0.1", digest: "sha256:expired", trust: Expired, expiresOn: &expiredOn},
+		{name: "unknown digest", repository: "registry.io/tasks/buildah", tag: "0.1", digest: "sha256:unknown", trust: Untrusted},
+		{name: "other tag", repository: "registry.io/tasks/buildah", tag: "0.2", digest: "sha256:latest", trust: Untrusted},
+		{name: "untagged record", repository: "registry.io/tasks/git-clone", tag: "0.1", digest: "sha256:untagged", trust: Trusted},
+		{name: "untagged bundle", repository: "registry.io/tasks/git-clone", digest: "sha256:untagged", trust: Trusted},
+		{name: "unknown repository", repository: "registry.io/tasks/unknown", digest: "sha256:latest", trust: Untrusted},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			trust, expiresOn := tracker.BundleTrust(c.repository, c.tag, c.digest, now, c.within)
+			assert.Equal(t, c.trust, trust)
+			assert.Equal(t, c.expiresOn, expiresOn)
+		})
+	}
+}
@@ -124,10 +124,6 @@ func Experimental() bool {
 	return os.Getenv("EC_EXPERIMENTAL") == "1"
 }
 
-func UseGoGather() bool {
-	return os.Getenv("USEGOGATHER") == "1"
-}
-
 // detect if the string is json
 func IsJson(data string) bool {
 	var jsMsg json.RawMessage
@@ -163,6 +159,28 @@ func HasJsonOrYamlExt(str string) bool {
 
 var ColorEnabled bool
 
+// Possible values of the color mode, see ColorModeFlags
+const (
+	ColorAlways = "always"
+	ColorNever  = "never"
+	ColorAuto   = "auto"
+)
+
+// ColorModeFlags returns the values of the no color and force color flags, as
+// used by SetColorEnabled, for the given color mode
+func ColorModeFlags(mode string) (noColor bool, forceColor bool, err error) {
+	switch mode {
+	case ColorAlways:
+		return false, true, nil
+	case ColorNever:
+		return true, false, nil
+	case ColorAuto, "":
+		return false, false, nil
+	default:
+		return false, false, fmt.Errorf("invalid color mode %q, expected one of: %s, %s, %s", mode, ColorAlways, ColorNever, ColorAuto)
+	}
+}
+
 func SetColorEnabled(flagNoColor, flagForceColor bool) {
 	ColorEnabled = setColorEnabled(flagNoColor, flagForceColor)
 }
@@ -270,3 +270,28 @@ func TestHasJsonOrYamlExt(t *testing.T) {
 		assert.Equal(t, tt.want, HasJsonOrYamlExt(tt.src))
 	}
 }
+
+func TestColorModeFlags(t *testing.T) {
+	tests := []struct {
+		mode       string
+		noColor    bool
+		forceColor bool
+		err        string
+	}{
+		{mode: "always", forceColor: true},
+		{mode: "never", noColor: true},
+		{mode: "auto"},
+		{mode: ""},
+		{mode: "sometimes", err: `invalid color mode "sometimes", expected one of: always, never, auto`},
+	}
+	for _, tt := range tests {
+		noColor, forceColor, err := ColorModeFlags(tt.mode)
+		if tt.err != "" {
+			assert.EqualError(t, err, tt.err)
+			continue
+		}
+		assert.NoError(t, err)
+		assert.Equal(t, tt.noColor, noColor)
+		assert.Equal(t, tt.forceColor, forceColor)
+	}
+}
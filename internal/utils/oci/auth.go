@@ -0,0 +1,246 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package oci
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	ecr "github.com/awslabs/amazon-ecr-credential-helper/ecr-login"
+	"github.com/chrismellard/docker-credential-acr-env/pkg/credhelper"
+	"github.com/docker/cli/cli/config"
+	"github.com/docker/cli/cli/config/configfile"
+	"github.com/docker/cli/cli/config/types"
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/google"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+const keychainContextKey contextKey = "ec.oci.keychain"
+
+// keychains are the cloud provider keychains that can be chained with the
+// default keychain, by name
+var keychains = map[string]func() authn.Keychain{
+	"ecr": func() authn.Keychain {
+		return authn.NewKeychainFromHelper(ecr.NewECRHelper(ecr.WithLogger(io.Discard)))
+	},
+	"gcr": func() authn.Keychain { return google.Keychain },
+	"acr": func() authn.Keychain { return authn.NewKeychainFromHelper(credhelper.NewACRCredentialsHelper()) },
+}
+
+// RegistryAuth configures how to authenticate to the registries. The Config is
+// the path to a Docker config.json file, or to the directory holding it, used
+// instead of the default Docker config. The Token is an OAuth bearer token
+// used for all registries. The Keychains name the cloud provider keychains,
+// one of ecr, gcr or acr, consulted after the Docker config.
+type RegistryAuth struct {
+	Config    string
+	Token     string
+	Keychains []string
+}
+
+// registryAuth is the authentication given by the flags added via
+// AddRegistryFlags
+var registryAuth RegistryAuth
+
+// AddRegistryFlags adds the flags configuring the authentication to the
+// registries and their mirrors, see WithRegistryAuth and WithRegistryMirrors
+func AddRegistryFlags(cmd *cobra.Command) {
+	cmd.PersistentFlags().StringVar(&registryAuth.Config, "registry-config", "", "path to the Docker config.json file, or to the directory holding it, with the credentials of the registries, used instead of the default Docker config")
+	cmd.PersistentFlags().StringVar(&registryAuth.Token, "registry-token", "", "OAuth bearer token to authenticate to the registries with, takes precedence over the Docker config")
+	cmd.PersistentFlags().StringSliceVar(&registryAuth.Keychains, "registry-keychain", nil, fmt.Sprintf("cloud provider keychain to look up the credentials of the registries in when not found in the Docker config, one of: %s. May be used multiple times", strings.Join(keychainNames(), ", ")))
+	cmd.PersistentFlags().StringSliceVar(&registryMirrors, "registry-mirror", nil, "mirror, or pull-through proxy, to fetch the images, their signatures, attestations and referrers of a registry or repository from, in the source=mirror form, e.g. registry.redhat.io=mirror.internal/redhat. May be used multiple times")
+}
+
+// WithRegistryAuth returns the context with the keychain of the
+// authentication given by the flags added via AddRegistryFlags, the context
+// is returned as is when no authentication is given
+func WithRegistryAuth(ctx context.Context) (context.Context, error) {
+	if registryAuth.IsEmpty() {
+		return ctx, nil
+	}
+
+	kc, err := registryAuth.Keychain(ctx)
+	if err != nil {
+		return ctx, err
+	}
+
+	return WithKeychain(ctx, kc), nil
+}
+
+// WithKeychain returns the context with the keychain used to authenticate to
+// the registries
+func WithKeychain(ctx context.Context, kc authn.Keychain) context.Context {
+	return context.WithValue(ctx, keychainContextKey, kc)
+}
+
+// Keychain returns the keychain used to authenticate to the registries, the
+// default keychain reading the Docker config unless one is set on the context
+// via WithKeychain
+func Keychain(ctx context.Context) authn.Keychain {
+	if kc, ok := ctx.Value(keychainContextKey).(authn.Keychain); ok && kc != nil {
+		return kc
+	}
+
+	return authn.DefaultKeychain
+}
+
+// IsEmpty returns true if no authentication is configured
+func (a RegistryAuth) IsEmpty() bool {
+	return a.Config == "" && a.Token == "" && len(a.Keychains) == 0
+}
+
+// Keychain returns the keychain of the authentication, consulting the token,
+// the Docker config and the cloud provider keychains in that order
+func (a RegistryAuth) Keychain(ctx context.Context) (authn.Keychain, error) {
+	var chain []authn.Keychain
+
+	if a.Token != "" {
+		chain = append(chain, tokenKeychain{token: a.Token})
+	}
+
+	if a.Config != "" {
+		cf, err := loadDockerConfig(ctx, a.Config)
+		if err != nil {
+			return nil, fmt.Errorf("unable to load the registry config %s: %w", a.Config, err)
+		}
+		chain = append(chain, configKeychain{cf})
+	} else {
+		chain = append(chain, authn.DefaultKeychain)
+	}
+
+	for _, k := range a.Keychains {
+		kc, ok := keychains[k]
+		if !ok {
+			return nil, fmt.Errorf("unknown registry keychain %q, expecting one of: %s", k, strings.Join(keychainNames(), ", "))
+		}
+		chain = append(chain, kc())
+	}
+
+	return authn.NewMultiKeychain(chain...), nil
+}
+
+func keychainNames() []string {
+	names := make([]string, 0, len(keychains))
+	for n := range keychains {
+		names = append(names, n)
+	}
+	// in a stable order for the help and the error messages
+	sort.Strings(names)
+
+	return names
+}
+
+// loadDockerConfig loads the Docker config from the file, or from the
+// config.json file in the directory
+func loadDockerConfig(ctx context.Context, path string) (*configfile.ConfigFile, error) {
+	fs := utils.FS(ctx)
+	if info, err := fs.Stat(path); err == nil && info.IsDir() {
+		path = filepath.Join(path, config.ConfigFileName)
+	}
+
+	data, err := afero.ReadFile(fs, path)
+	if err != nil {
+		return nil, err
+	}
+
+	cf, err := config.LoadFromReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	cf.Filename = path
+
+	return cf, nil
+}
+
+// tokenKeychain authenticates to all registries with the bearer token
+type tokenKeychain struct {
+	token string
+}
+
+func (k tokenKeychain) Resolve(authn.Resource) (authn.Authenticator, error) {
+	return &authn.Bearer{Token: k.token}, nil
+}
+
+// configKeychain looks up the credentials of the registries in the Docker
+// config, including the credential helpers it configures. As with the default
+// keychain the credentials of a repository are looked up from the most
+// specific key, the repository itself, to its registry.
+type configKeychain struct {
+	cf *configfile.ConfigFile
+}
+
+func (k configKeychain) Resolve(target authn.Resource) (authn.Authenticator, error) {
+	var empty types.AuthConfig
+	for _, key := range authKeys(target) {
+		cfg, err := k.cf.GetAuthConfig(key)
+		if err != nil {
+			return nil, err
+		}
+		// the server address is always set, it is not a credential
+		cfg.ServerAddress = ""
+		if cfg == empty {
+			continue
+		}
+
+		return authn.FromConfig(authn.AuthConfig{
+			Username:      cfg.Username,
+			Password:      cfg.Password,
+			Auth:          cfg.Auth,
+			IdentityToken: cfg.IdentityToken,
+			RegistryToken: cfg.RegistryToken,
+		}), nil
+	}
+
+	return authn.Anonymous, nil
+}
+
+// authKeys returns the keys of the Docker config the credentials of the target
+// are looked up with, from the repository and its parents to the registry
+func authKeys(target authn.Resource) []string {
+	var repository string
+	switch t := target.(type) {
+	case name.Repository:
+		repository = t.String()
+	case name.Tag:
+		repository = t.Context().String()
+	case name.Digest:
+		repository = t.Context().String()
+	}
+
+	registry := target.RegistryStr()
+	var keys []string
+	for strings.HasPrefix(repository, registry+"/") {
+		keys = append(keys, repository)
+		repository = repository[:strings.LastIndex(repository, "/")]
+	}
+
+	if registry == name.DefaultRegistry {
+		registry = authn.DefaultAuthKey
+	}
+
+	return append(keys, registry)
+}
@@ -0,0 +1,123 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package oci
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistryAuthConfig(t *testing.T) {
+	cases := []struct {
+		repository string
+		user       string
+	}{
+		{"registry.io/foo/bar/baz", "baz"},
+		{"registry.io/foo/bar", "bar"},
+		{"registry.io/foo", "foo"},
+		{"registry.io", "quay"},
+		{"registry.io/foo/bar/baz:tag", "baz"},
+		{"registry.io/foo/bar/baz/fiz", "baz"},
+		{"registry.io/foo/for", "foo"},
+		{"registry.io/foo/bar/baz@sha256:0000000000000000000000000000000000000000000000000000000000000000", "baz"},
+		{"other.io/foo", ""},
+	}
+
+	// DOCKER_CONFIG is not used with an explicit config
+	t.Setenv("DOCKER_CONFIG", t.TempDir())
+
+	for _, config := range []string{"__test__", "__test__/config.json"} {
+		kc, err := RegistryAuth{Config: config}.Keychain(context.Background())
+		require.NoError(t, err)
+
+		for _, c := range cases {
+			t.Run(config+"/"+c.repository, func(t *testing.T) {
+				var target authn.Resource
+				if c.repository == "registry.io" {
+					registry, err := name.NewRegistry(c.repository)
+					require.NoError(t, err)
+					target = registry
+				} else {
+					// tags and digests are resources as well
+					ref, err := name.ParseReference(c.repository)
+					require.NoError(t, err)
+					target = ref.(authn.Resource)
+				}
+
+				authenticator, err := kc.Resolve(target)
+				require.NoError(t, err)
+
+				authconfig, err := authenticator.Authorization()
+				require.NoError(t, err)
+
+				assert.Equal(t, c.user, authconfig.Username)
+			})
+		}
+	}
+}
+
+func TestRegistryAuthToken(t *testing.T) {
+	kc, err := RegistryAuth{Config: "__test__", Token: "t0k3n"}.Keychain(context.Background())
+	require.NoError(t, err)
+
+	authenticator, err := kc.Resolve(name.MustParseReference("registry.io/foo/bar").Context())
+	require.NoError(t, err)
+
+	authconfig, err := authenticator.Authorization()
+	require.NoError(t, err)
+
+	// the token takes precedence over the config
+	assert.Equal(t, &authn.AuthConfig{RegistryToken: "t0k3n"}, authconfig)
+}
+
+func TestRegistryAuthErrors(t *testing.T) {
+	_, err := RegistryAuth{Config: "__test__/missing.json"}.Keychain(context.Background())
+	assert.ErrorContains(t, err, "unable to load the registry config __test__/missing.json")
+
+	_, err = RegistryAuth{Keychains: []string{"ecr", "quay"}}.Keychain(context.Background())
+	assert.EqualError(t, err, `unknown registry keychain "quay", expecting one of: acr, ecr, gcr`)
+
+	_, err = RegistryAuth{Keychains: []string{"ecr", "gcr", "acr"}}.Keychain(context.Background())
+	assert.NoError(t, err)
+}
+
+func TestKeychain(t *testing.T) {
+	ctx := context.Background()
+	assert.Equal(t, authn.DefaultKeychain, Keychain(ctx))
+
+	kc := authn.NewMultiKeychain()
+	assert.Equal(t, kc, Keychain(WithKeychain(ctx, kc)))
+
+	registryAuth = RegistryAuth{}
+	t.Cleanup(func() { registryAuth = RegistryAuth{} })
+
+	same, err := WithRegistryAuth(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, ctx, same)
+
+	registryAuth = RegistryAuth{Token: "t0k3n"}
+	ctx, err = WithRegistryAuth(ctx)
+	require.NoError(t, err)
+	assert.NotEqual(t, authn.DefaultKeychain, Keychain(ctx))
+}
@@ -26,7 +26,6 @@ import (
 	"sync"
 	"time"
 
-	"github.com/google/go-containerregistry/pkg/authn"
 	"github.com/google/go-containerregistry/pkg/name"
 	v1 "github.com/google/go-containerregistry/pkg/v1"
 	"github.com/google/go-containerregistry/pkg/v1/cache"
@@ -39,8 +38,9 @@ import (
 
 // imageRefTransport is used to inject the type of transport to use with the
 // remote.WithTransport function. By default, remote.DefaultTransport is
-// equivalent to http.DefaultTransport, with a reduced timeout and keep-alive
-var imageRefTransport = remote.WithTransport(remote.DefaultTransport)
+// equivalent to http.DefaultTransport, with a reduced timeout and keep-alive,
+// and the responses of the registries are cached, see httpCache
+var imageRefTransport = remote.WithTransport(&httpCache{next: remote.DefaultTransport, dir: httpCacheDir})
 
 type contextKey string
 
@@ -61,7 +61,7 @@ func (t *tracingRoundTripper) RoundTrip(req *http.Request) (*http.Response, erro
 
 func init() {
 	if log.IsLevelEnabled(log.TraceLevel) {
-		imageRefTransport = remote.WithTransport(&tracingRoundTripper{})
+		imageRefTransport = remote.WithTransport(&httpCache{next: &tracingRoundTripper{}, dir: httpCacheDir})
 	}
 }
 
@@ -96,7 +96,7 @@ func createRemoteOptions(ctx context.Context) []remote.Option {
 	return []remote.Option{
 		imageRefTransport,
 		remote.WithContext(ctx),
-		remote.WithAuthFromKeychain(authn.DefaultKeychain),
+		remote.WithAuthFromKeychain(Keychain(ctx)),
 		remote.WithRetryBackoff(backoff),
 	}
 }
@@ -109,6 +109,8 @@ type Client interface {
 	Image(name.Reference) (v1.Image, error)
 	Layer(name.Digest) (v1.Layer, error)
 	Index(name.Reference) (v1.ImageIndex, error)
+	Referrers(name.Digest) (v1.ImageIndex, error)
+	Write(name.Reference, v1.Image) error
 }
 
 func WithClient(ctx context.Context, client Client) context.Context {
@@ -127,26 +129,27 @@ func NewClient(ctx context.Context, opts ...remote.Option) Client {
 		o = createRemoteOptions(ctx)
 	}
 
-	return &defaultClient{ctx, o}
+	return &defaultClient{ctx: ctx, opts: o, mirrors: MirrorsFrom(ctx)}
 }
 
 type defaultClient struct {
-	ctx  context.Context
-	opts []remote.Option
+	ctx     context.Context
+	opts    []remote.Option
+	mirrors Mirrors
 }
 
 func (c *defaultClient) VerifyImageSignatures(ref name.Reference, opts *cosign.CheckOpts) ([]oci.Signature, bool, error) {
 	opts.RegistryClientOpts = append(opts.RegistryClientOpts, ociremote.WithRemoteOptions(c.opts...))
-	return cosign.VerifyImageSignatures(c.ctx, ref, opts)
+	return cosign.VerifyImageSignatures(c.ctx, c.mirrors.Reference(ref), opts)
 }
 
 func (c *defaultClient) VerifyImageAttestations(ref name.Reference, opts *cosign.CheckOpts) ([]oci.Signature, bool, error) {
 	opts.RegistryClientOpts = append(opts.RegistryClientOpts, ociremote.WithRemoteOptions(c.opts...))
-	return cosign.VerifyImageAttestations(c.ctx, ref, opts)
+	return cosign.VerifyImageAttestations(c.ctx, c.mirrors.Reference(ref), opts)
 }
 
 func (c *defaultClient) Head(ref name.Reference) (*v1.Descriptor, error) {
-	return remote.Head(ref, c.opts...)
+	return remote.Head(c.mirrors.Reference(ref), c.opts...)
 }
 
 // gather all attestation uris and digests associated with an image
@@ -156,7 +159,7 @@ func (c *defaultClient) AttestationUri(img string) (string, error) {
 		return "", err
 	}
 
-	digest, err := ociremote.ResolveDigest(imgRef, ociremote.WithRemoteOptions(c.opts...))
+	digest, err := ociremote.ResolveDigest(c.mirrors.Reference(imgRef), ociremote.WithRemoteOptions(c.opts...))
 	if err != nil {
 		return "", err
 	}
@@ -170,7 +173,7 @@ func (c *defaultClient) AttestationUri(img string) (string, error) {
 }
 
 func (c *defaultClient) ResolveDigest(ref name.Reference) (string, error) {
-	digest, err := ociremote.ResolveDigest(ref, ociremote.WithRemoteOptions(c.opts...))
+	digest, err := ociremote.ResolveDigest(c.mirrors.Reference(ref), ociremote.WithRemoteOptions(c.opts...))
 	if err != nil {
 		return "", err
 	}
@@ -182,7 +185,7 @@ func (c *defaultClient) ResolveDigest(ref name.Reference) (string, error) {
 }
 
 func (c *defaultClient) Image(ref name.Reference) (v1.Image, error) {
-	img, err := remote.Image(ref, c.opts...)
+	img, err := remote.Image(c.mirrors.Reference(ref), c.opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -197,7 +200,7 @@ func (c *defaultClient) Image(ref name.Reference) (v1.Image, error) {
 func (c *defaultClient) Layer(ref name.Digest) (v1.Layer, error) {
 	// TODO: Caching a layer directly is difficult and may not be possible, see:
 	//   https://github.com/google/go-containerregistry/issues/1821
-	layer, err := remote.Layer(ref, c.opts...)
+	layer, err := remote.Layer(c.mirrors.Digest(ref), c.opts...)
 	if err != nil {
 		return nil, fmt.Errorf("fetching layer: %w", err)
 	}
@@ -205,10 +208,30 @@ func (c *defaultClient) Layer(ref name.Digest) (v1.Layer, error) {
 }
 
 func (c *defaultClient) Index(ref name.Reference) (v1.ImageIndex, error) {
-	index, err := remote.Index(ref, c.opts...)
+	index, err := remote.Index(c.mirrors.Reference(ref), c.opts...)
 	if err != nil {
 		return nil, fmt.Errorf("fetching index: %w", err)
 	}
 
 	return index, nil
 }
+
+// Referrers returns the index of the artifacts referring to the image with the
+// given digest, using the referrers API or the referrers tag schema when the
+// registry does not support the API
+func (c *defaultClient) Referrers(ref name.Digest) (v1.ImageIndex, error) {
+	index, err := remote.Referrers(c.mirrors.Digest(ref), c.opts...)
+	if err != nil {
+		return nil, fmt.Errorf("fetching referrers: %w", err)
+	}
+
+	return index, nil
+}
+
+func (c *defaultClient) Write(ref name.Reference, img v1.Image) error {
+	if err := remote.Write(ref, img, c.opts...); err != nil {
+		return fmt.Errorf("pushing image: %w", err)
+	}
+
+	return nil
+}
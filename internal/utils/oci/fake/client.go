@@ -73,6 +73,7 @@ func WithTestImageConfig(ctx context.Context, url string) context.Context {
 	client := &FakeClient{}
 	client.On("Image", ref, mock.Anything).Return(image, nil)
 	client.On("Image", parentRef, mock.Anything).Return(parentImage, nil)
+	client.On("Referrers", ref).Return(empty.Index, nil)
 
 	return oci.WithClient(ctx, client)
 }
@@ -143,3 +144,18 @@ func (m *FakeClient) Index(ref name.Reference) (v1.ImageIndex, error) {
 	}
 	return index, args.Error(1)
 }
+
+func (m *FakeClient) Referrers(ref name.Digest) (v1.ImageIndex, error) {
+	args := m.Called(ref)
+	var index v1.ImageIndex
+	if maybeIndex, ok := args.Get(0).(v1.ImageIndex); ok {
+		index = maybeIndex
+	}
+	return index, args.Error(1)
+}
+
+func (m *FakeClient) Write(ref name.Reference, img v1.Image) error {
+	args := m.Called(ref, img)
+
+	return args.Error(0)
+}
@@ -0,0 +1,364 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package oci
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+
+	"github.com/enterprise-contract/ec-cli/internal/utils"
+)
+
+// registryPath matches the paths of the registry API the responses of which
+// are cached, capturing the kind of the request and the reference
+var registryPath = regexp.MustCompile(`^/v2/.+/(manifests|blobs|referrers)/([^/]+)$`)
+
+// cachedHeaders are the response headers kept in the cache, the registry
+// clients rely only on these
+var cachedHeaders = []string{"Content-Type", "Content-Length", "Docker-Content-Digest", "ETag", "OCI-Filters-Applied"}
+
+var httpCacheDir = sync.OnceValue(initHTTPCacheDir)
+
+func initHTTPCacheDir() string {
+	// if a value was set and it is parsed as false, turn the cache off
+	if v, err := strconv.ParseBool(os.Getenv("EC_CACHE")); err == nil && !v {
+		return ""
+	}
+
+	dir, err := DefaultHTTPCacheDir()
+	if err != nil {
+		log.Debug("unable to find user cache directory")
+		return ""
+	}
+	log.Debugf("using %q directory to store the HTTP cache", dir)
+
+	return dir
+}
+
+// DefaultHTTPCacheDir returns the directory where the responses of the
+// registries are cached, that is ec/http within $XDG_CACHE_HOME, or the
+// platform specific user cache directory
+func DefaultHTTPCacheDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "ec", "http"), nil
+}
+
+// httpCache is a transport caching the responses of the registries to the GET
+// requests of manifests, blobs and referrers. The responses to requests by
+// digest never change and are served from the cache without contacting the
+// registry. The responses to requests of tags and referrers can change, they
+// are revalidated using their ETag, or their digest, and served from the
+// cache only when the registry reports them as not modified. The bodies are
+// stored by their digest, and verified against the digest requested, or the
+// one reported by the registry, before they're stored.
+type httpCache struct {
+	next http.RoundTripper
+	dir  func() string
+}
+
+// httpCacheEntry is the cached response to a request, the body is stored
+// separately by its digest
+type httpCacheEntry struct {
+	Header http.Header `json:"header"`
+	Digest string      `json:"digest"`
+}
+
+func (c *httpCache) RoundTrip(req *http.Request) (*http.Response, error) {
+	dir := c.dir()
+	if dir == "" || req.Method != http.MethodGet || req.Header.Get("Range") != "" {
+		return c.next.RoundTrip(req)
+	}
+
+	match := registryPath.FindStringSubmatch(req.URL.Path)
+	if match == nil {
+		return c.next.RoundTrip(req)
+	}
+	// only the manifests and the blobs requested by digest never change
+	immutable := match[1] != "referrers" && strings.HasPrefix(match[2], "sha256:")
+
+	fs := utils.FS(req.Context())
+	key := c.key(req)
+	cached, ok := c.load(fs, dir, key)
+	if ok && immutable {
+		log.Debugf("HTTP cache hit: %s", req.URL)
+		return c.response(fs, dir, req, cached)
+	}
+
+	if ok {
+		if etag := cached.etag(); etag != "" {
+			req = req.Clone(req.Context())
+			req.Header.Set("If-None-Match", etag)
+		}
+	}
+
+	resp, err := c.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if ok && resp.StatusCode == http.StatusNotModified {
+		_ = resp.Body.Close()
+		log.Debugf("HTTP cache revalidated: %s", req.URL)
+		return c.response(fs, dir, req, cached)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return resp, nil
+	}
+
+	expected := resp.Header.Get("Docker-Content-Digest")
+	if immutable {
+		expected = match[2]
+	}
+	if !strings.HasPrefix(expected, "sha256:") && (immutable || resp.Header.Get("ETag") == "") {
+		// the body can be neither verified nor revalidated
+		return resp, nil
+	}
+
+	tmp, err := c.tempFile(fs, dir)
+	if err != nil {
+		log.Debugf("Not caching %s, unable to create a temporary file: %v", req.URL, err)
+		return resp, nil
+	}
+
+	header := http.Header{}
+	for _, h := range cachedHeaders {
+		for _, v := range resp.Header.Values(h) {
+			header.Add(h, v)
+		}
+	}
+
+	resp.Body = &cachingBody{
+		ReadCloser: resp.Body,
+		tmp:        tmp,
+		hash:       sha256.New(),
+		done: func(digest string) {
+			if strings.HasPrefix(expected, "sha256:") && expected != digest {
+				log.Debugf("Not caching %s, the digest of the body %s is not the expected %s", req.URL, digest, expected)
+				_ = fs.Remove(tmp.Name())
+				return
+			}
+
+			if err := c.store(fs, dir, key, tmp.Name(), httpCacheEntry{Header: header, Digest: digest}); err != nil {
+				log.Debugf("Unable to store %s in the HTTP cache at %s: %v", req.URL, dir, err)
+			}
+		},
+		discard: func() {
+			_ = fs.Remove(tmp.Name())
+		},
+	}
+
+	return resp, nil
+}
+
+// key returns the key of the cache entry of the request, the accepted media
+// types are part of the key as they determine the manifest returned
+func (c *httpCache) key(req *http.Request) string {
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(req.URL.String()+"\n"+strings.Join(req.Header.Values("Accept"), ","))))
+}
+
+func (c *httpCache) entry(dir, key string) string {
+	return filepath.Join(dir, "entries", key+".json")
+}
+
+func (c *httpCache) blob(dir, digest string) string {
+	return filepath.Join(dir, "blobs", strings.Replace(digest, ":", "-", 1))
+}
+
+// load returns the cached response to the request with the given key, or
+// false if the response is not cached
+func (c *httpCache) load(fs afero.Fs, dir, key string) (httpCacheEntry, bool) {
+	entry := c.entry(dir, key)
+	data, err := afero.ReadFile(fs, entry)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			log.Debugf("Unable to read the HTTP cache entry %s: %v", entry, err)
+		}
+		return httpCacheEntry{}, false
+	}
+
+	var cached httpCacheEntry
+	if err := json.Unmarshal(data, &cached); err != nil {
+		log.Debugf("Ignoring the malformed HTTP cache entry %s: %v", entry, err)
+		return httpCacheEntry{}, false
+	}
+
+	if _, err := fs.Stat(c.blob(dir, cached.Digest)); err != nil {
+		log.Debugf("Ignoring the HTTP cache entry %s without a body: %v", entry, err)
+		return httpCacheEntry{}, false
+	}
+
+	return cached, true
+}
+
+// response returns the cached response to the request
+func (c *httpCache) response(fs afero.Fs, dir string, req *http.Request, cached httpCacheEntry) (*http.Response, error) {
+	body, err := fs.Open(c.blob(dir, cached.Digest))
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := body.Stat()
+	if err != nil {
+		_ = body.Close()
+		return nil, err
+	}
+
+	header := cached.Header.Clone()
+	header.Set("Content-Length", strconv.FormatInt(info.Size(), 10))
+
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          body,
+		ContentLength: info.Size(),
+		Request:       req,
+	}, nil
+}
+
+func (c *httpCache) tempFile(fs afero.Fs, dir string) (afero.File, error) {
+	if err := fs.MkdirAll(filepath.Join(dir, "blobs"), 0700); err != nil {
+		return nil, err
+	}
+
+	return afero.TempFile(fs, filepath.Join(dir, "blobs"), "tmp-")
+}
+
+// store moves the temporary file holding the body into the cache and writes
+// the cache entry pointing to it. Both are written into temporary files which
+// then replace the cache files, so the cache files are never partially
+// written.
+func (c *httpCache) store(fs afero.Fs, dir, key, tmpBody string, cached httpCacheEntry) error {
+	if err := fs.Rename(tmpBody, c.blob(dir, cached.Digest)); err != nil {
+		_ = fs.Remove(tmpBody)
+		return err
+	}
+
+	data, err := json.Marshal(cached)
+	if err != nil {
+		return err
+	}
+
+	if err := fs.MkdirAll(filepath.Join(dir, "entries"), 0700); err != nil {
+		return err
+	}
+
+	tmp, err := afero.TempFile(fs, filepath.Join(dir, "entries"), "tmp-")
+	if err != nil {
+		return err
+	}
+
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err == nil {
+		err = fs.Rename(tmp.Name(), c.entry(dir, key))
+	}
+
+	if err != nil {
+		_ = fs.Remove(tmp.Name())
+	}
+
+	return err
+}
+
+func (e httpCacheEntry) etag() string {
+	if etag := e.Header.Get("ETag"); etag != "" {
+		return etag
+	}
+
+	// registries commonly accept the digest as the ETag
+	if digest := e.Header.Get("Docker-Content-Digest"); digest != "" {
+		return `"` + digest + `"`
+	}
+
+	return ""
+}
+
+// cachingBody copies the body into a temporary file while it is read, once
+// the body is read fully the done function is called with its digest,
+// otherwise the discard function is called when the body is closed
+type cachingBody struct {
+	io.ReadCloser
+	tmp     afero.File
+	hash    hash.Hash
+	done    func(digest string)
+	discard func()
+	closed  bool
+}
+
+func (b *cachingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if n > 0 && !b.closed {
+		if _, werr := b.tmp.Write(p[:n]); werr != nil {
+			b.finish(false)
+		} else {
+			b.hash.Write(p[:n])
+		}
+	}
+
+	if errors.Is(err, io.EOF) {
+		b.finish(true)
+	}
+
+	return n, err
+}
+
+func (b *cachingBody) Close() error {
+	b.finish(false)
+	return b.ReadCloser.Close()
+}
+
+func (b *cachingBody) finish(complete bool) {
+	if b.closed {
+		return
+	}
+	b.closed = true
+
+	if err := b.tmp.Close(); err != nil || !complete {
+		b.discard()
+		return
+	}
+
+	b.done(v1.Hash{Algorithm: "sha256", Hex: hex.EncodeToString(b.hash.Sum(nil))}.String())
+}
@@ -0,0 +1,177 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package oci
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPCacheImmutable(t *testing.T) {
+	img, err := random.Image(1024, 2)
+	require.NoError(t, err)
+
+	l := &bytes.Buffer{}
+	registry := httptest.NewServer(registry.New(registry.Logger(log.New(l, "", 0))))
+	t.Cleanup(registry.Close)
+
+	u, err := url.Parse(registry.URL)
+	require.NoError(t, err)
+
+	ref, err := name.ParseReference(fmt.Sprintf("localhost:%s/repository/image:tag", u.Port()))
+	require.NoError(t, err)
+	require.NoError(t, remote.Push(ref, img))
+
+	digest, err := img.Digest()
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	transport := remote.WithTransport(&httpCache{next: http.DefaultTransport, dir: func() string { return dir }})
+
+	fetchFully := func() {
+		img, err := remote.Image(ref.Context().Digest(digest.String()), transport)
+		require.NoError(t, err)
+		_, err = img.ConfigFile()
+		require.NoError(t, err)
+		layers, err := img.Layers()
+		require.NoError(t, err)
+		for _, l := range layers {
+			r, err := l.Compressed()
+			require.NoError(t, err)
+			_, err = io.ReadAll(r)
+			require.NoError(t, err)
+			require.NoError(t, r.Close())
+		}
+	}
+
+	fetchFully()
+	fetchFully()
+	fetchFully()
+
+	// the manifest, the config and the two layers are fetched only once
+	assert.Equal(t, 1, strings.Count(l.String(), "GET /v2/repository/image/manifests/sha256:"))
+	assert.Equal(t, 3, strings.Count(l.String(), "GET /v2/repository/image/blobs/sha256:"))
+}
+
+func TestHTTPCacheRevalidation(t *testing.T) {
+	body := []byte(`{"schemaVersion": 2}`)
+	digest := v1.Hash{Algorithm: "sha256", Hex: fmt.Sprintf("%x", sha256.Sum256(body))}.String()
+
+	requests := 0
+	notModified := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			notModified++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("Content-Type", "application/vnd.oci.image.manifest.v1+json")
+		w.Header().Set("Docker-Content-Digest", digest)
+		w.Header().Set("ETag", `"v1"`)
+		_, _ = w.Write(body)
+	}))
+	t.Cleanup(server.Close)
+
+	dir := t.TempDir()
+	client := http.Client{Transport: &httpCache{next: http.DefaultTransport, dir: func() string { return dir }}}
+
+	for i := 0; i < 3; i++ {
+		resp, err := client.Get(server.URL + "/v2/repository/image/manifests/latest")
+		require.NoError(t, err)
+		got, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, body, got)
+		assert.Equal(t, "application/vnd.oci.image.manifest.v1+json", resp.Header.Get("Content-Type"))
+		assert.Equal(t, digest, resp.Header.Get("Docker-Content-Digest"))
+	}
+
+	// the tag is revalidated each time
+	assert.Equal(t, 3, requests)
+	assert.Equal(t, 2, notModified)
+}
+
+func TestHTTPCacheNotCached(t *testing.T) {
+	wrong := "sha256:0000000000000000000000000000000000000000000000000000000000000000"
+
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		_, _ = w.Write([]byte("blob"))
+	}))
+	t.Cleanup(server.Close)
+
+	dir := t.TempDir()
+	cached := http.Client{Transport: &httpCache{next: http.DefaultTransport, dir: func() string { return dir }}}
+	disabled := http.Client{Transport: &httpCache{next: http.DefaultTransport, dir: func() string { return "" }}}
+
+	get := func(client http.Client, path string, header ...string) {
+		req, err := http.NewRequest(http.MethodGet, server.URL+path, nil)
+		require.NoError(t, err)
+		if len(header) == 2 {
+			req.Header.Set(header[0], header[1])
+		}
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		_, err = io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+	}
+
+	cases := []struct {
+		name   string
+		client http.Client
+		path   string
+		header []string
+	}{
+		{name: "digest mismatch", client: cached, path: "/v2/repository/image/blobs/" + wrong},
+		{name: "tag without digest or etag", client: cached, path: "/v2/repository/image/manifests/latest"},
+		{name: "range", client: cached, path: "/v2/repository/image/blobs/" + wrong, header: []string{"Range", "bytes=0-1"}},
+		{name: "other paths", client: cached, path: "/v2/_catalog"},
+		{name: "disabled", client: disabled, path: "/v2/repository/image/blobs/" + wrong},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			requests = 0
+			get(c.client, c.path, c.header...)
+			get(c.client, c.path, c.header...)
+			assert.Equal(t, 2, requests)
+		})
+	}
+}
@@ -0,0 +1,172 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package oci
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	log "github.com/sirupsen/logrus"
+)
+
+const mirrorsContextKey contextKey = "ec.oci.mirrors"
+
+// Mirrors maps registries, or repositories, to the mirrors, or pull-through
+// proxies, the images are fetched from instead, e.g. registry.redhat.io to
+// mirror.internal/redhat. The images keep their original references, only
+// the manifests, the layers, the signatures, the attestations and the
+// referrers are fetched from the mirror.
+type Mirrors map[string]string
+
+// registryMirrors are the mirrors given by the flag added via
+// AddRegistryFlags, in the source=mirror form
+var registryMirrors []string
+
+// ParseMirrors parses the mirrors given in the source=mirror form, where the
+// source and the mirror are registries or repositories
+func ParseMirrors(values []string) (Mirrors, error) {
+	mirrors := Mirrors{}
+	for _, v := range values {
+		source, mirror, ok := strings.Cut(v, "=")
+		source, mirror = strings.TrimSpace(source), strings.TrimSpace(mirror)
+		if !ok || source == "" || mirror == "" {
+			return nil, fmt.Errorf("invalid registry mirror %q, expecting source=mirror, e.g. registry.redhat.io=mirror.internal/redhat", v)
+		}
+
+		// normalized as in the references, e.g. docker.io is index.docker.io
+		key, err := repositoryName(source)
+		if err != nil {
+			return nil, fmt.Errorf("invalid registry mirror %q, the source %s is not a registry or repository: %w", v, source, err)
+		}
+		if _, err := repositoryName(mirror); err != nil {
+			return nil, fmt.Errorf("invalid registry mirror %q, the mirror %s is not a registry or repository: %w", v, mirror, err)
+		}
+
+		mirrors[key] = mirror
+	}
+
+	return mirrors, nil
+}
+
+// repositoryName returns the name of the registry or the repository as in the
+// references, the Docker Hub repositories are not prefixed with library/ as
+// the source may be a prefix of the repositories, e.g. docker.io/library
+func repositoryName(s string) (string, error) {
+	host, path, _ := strings.Cut(s, "/")
+	r, err := name.NewRegistry(host)
+	if err != nil {
+		return "", err
+	}
+	if path == "" {
+		return r.Name(), nil
+	}
+
+	if _, err := name.NewRepository(s); err != nil {
+		return "", err
+	}
+
+	return r.Name() + "/" + path, nil
+}
+
+// WithRegistryMirrors returns the context with the mirrors given by the flag
+// added via AddRegistryFlags, the context is returned as is when no mirrors
+// are given
+func WithRegistryMirrors(ctx context.Context) (context.Context, error) {
+	if len(registryMirrors) == 0 {
+		return ctx, nil
+	}
+
+	mirrors, err := ParseMirrors(registryMirrors)
+	if err != nil {
+		return ctx, err
+	}
+
+	return WithMirrors(ctx, mirrors), nil
+}
+
+// WithMirrors returns the context with the mirrors the images are fetched from
+func WithMirrors(ctx context.Context, mirrors Mirrors) context.Context {
+	return context.WithValue(ctx, mirrorsContextKey, mirrors)
+}
+
+// MirrorsFrom returns the mirrors set on the context via WithMirrors, if any
+func MirrorsFrom(ctx context.Context) Mirrors {
+	if m, ok := ctx.Value(mirrorsContextKey).(Mirrors); ok {
+		return m
+	}
+
+	return nil
+}
+
+// Reference returns the reference of the image in the mirror of its
+// repository, or the reference as is if the repository is not mirrored
+func (m Mirrors) Reference(ref name.Reference) name.Reference {
+	repo, ok := m.repository(ref.Context())
+	if !ok {
+		return ref
+	}
+
+	switch r := ref.(type) {
+	case name.Digest:
+		return repo.Digest(r.DigestStr())
+	case name.Tag:
+		return repo.Tag(r.TagStr())
+	default:
+		return ref
+	}
+}
+
+// Digest returns the digest in the mirror of its repository, or the digest as
+// is if the repository is not mirrored
+func (m Mirrors) Digest(ref name.Digest) name.Digest {
+	repo, ok := m.repository(ref.Context())
+	if !ok {
+		return ref
+	}
+
+	return repo.Digest(ref.DigestStr())
+}
+
+// repository returns the mirror of the repository, taken from the mirror of
+// the most specific source matching it
+func (m Mirrors) repository(repo name.Repository) (name.Repository, bool) {
+	if len(m) == 0 {
+		return repo, false
+	}
+
+	original := repo.Name()
+	source := ""
+	for s := range m {
+		if (original == s || strings.HasPrefix(original, s+"/")) && len(s) > len(source) {
+			source = s
+		}
+	}
+	if source == "" {
+		return repo, false
+	}
+
+	mirrored, err := name.NewRepository(m[source] + strings.TrimPrefix(original, source))
+	if err != nil {
+		log.Warnf("Unable to use the mirror %s of the repository %s: %v", m[source], original, err)
+		return repo, false
+	}
+	log.Debugf("Fetching the repository %s from the mirror %s", original, mirrored)
+
+	return mirrored, true
+}
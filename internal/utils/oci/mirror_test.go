@@ -0,0 +1,194 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package oci
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMirrors(t *testing.T) {
+	cases := []struct {
+		name    string
+		values  []string
+		mirrors Mirrors
+		err     string
+	}{
+		{name: "none", mirrors: Mirrors{}},
+		{
+			name:    "registry",
+			values:  []string{"registry.redhat.io=mirror.internal"},
+			mirrors: Mirrors{"registry.redhat.io": "mirror.internal"},
+		},
+		{
+			name:   "repositories",
+			values: []string{"registry.redhat.io/ubi9 = mirror.internal/redhat/ubi9", "docker.io=mirror.internal/hub"},
+			mirrors: Mirrors{
+				"registry.redhat.io/ubi9": "mirror.internal/redhat/ubi9",
+				"index.docker.io":         "mirror.internal/hub",
+			},
+		},
+		{
+			name:   "missing mirror",
+			values: []string{"registry.redhat.io"},
+			err:    `invalid registry mirror "registry.redhat.io", expecting source=mirror, e.g. registry.redhat.io=mirror.internal/redhat`,
+		},
+		{
+			name:   "empty source",
+			values: []string{"=mirror.internal"},
+			err:    `invalid registry mirror "=mirror.internal", expecting source=mirror, e.g. registry.redhat.io=mirror.internal/redhat`,
+		},
+		{
+			name:   "invalid source",
+			values: []string{"registry.redhat.io/UBI=mirror.internal"},
+			err:    `invalid registry mirror "registry.redhat.io/UBI=mirror.internal", the source registry.redhat.io/UBI is not a registry or repository`,
+		},
+		{
+			name:   "invalid mirror",
+			values: []string{"registry.redhat.io=mirror.internal/Redhat"},
+			err:    `invalid registry mirror "registry.redhat.io=mirror.internal/Redhat", the mirror mirror.internal/Redhat is not a registry or repository`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			mirrors, err := ParseMirrors(c.values)
+			if c.err != "" {
+				assert.ErrorContains(t, err, c.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, c.mirrors, mirrors)
+		})
+	}
+}
+
+func TestMirrorsReference(t *testing.T) {
+	mirrors, err := ParseMirrors([]string{
+		"registry.redhat.io=mirror.internal/redhat",
+		"registry.redhat.io/ubi9=mirror.internal/ubi",
+		"docker.io/library=mirror.internal/hub",
+	})
+	require.NoError(t, err)
+
+	digest := "sha256:4e388ab32b10dc8dbc7e28144f552830adc74787c1e2c0824032078a79f227fb"
+
+	cases := []struct {
+		ref      string
+		expected string
+	}{
+		{"registry.redhat.io/rhel9/toolbox:latest", "mirror.internal/redhat/rhel9/toolbox:latest"},
+		{"registry.redhat.io/ubi9:latest", "mirror.internal/ubi:latest"},
+		{"registry.redhat.io/ubi9/ubi@" + digest, "mirror.internal/ubi/ubi@" + digest},
+		{"registry.redhat.io/ubi9-minimal:latest", "mirror.internal/redhat/ubi9-minimal:latest"},
+		{"busybox:latest", "mirror.internal/hub/busybox:latest"},
+		{"quay.io/redhat/ubi9:latest", "quay.io/redhat/ubi9:latest"},
+		{"redhat.io/ubi9:latest", "redhat.io/ubi9:latest"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.ref, func(t *testing.T) {
+			ref, err := name.ParseReference(c.ref)
+			require.NoError(t, err)
+
+			assert.Equal(t, c.expected, mirrors.Reference(ref).String())
+		})
+	}
+
+	ref, err := name.NewDigest("registry.redhat.io/ubi9/ubi@" + digest)
+	require.NoError(t, err)
+	assert.Equal(t, "mirror.internal/ubi/ubi@"+digest, mirrors.Digest(ref).String())
+
+	// no mirrors is a noop
+	assert.Equal(t, ref, Mirrors(nil).Reference(ref))
+}
+
+func TestWithRegistryMirrors(t *testing.T) {
+	ctx := context.Background()
+	assert.Nil(t, MirrorsFrom(ctx))
+
+	registryMirrors = nil
+	t.Cleanup(func() { registryMirrors = nil })
+
+	same, err := WithRegistryMirrors(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, ctx, same)
+
+	registryMirrors = []string{"registry.redhat.io"}
+	_, err = WithRegistryMirrors(ctx)
+	assert.ErrorContains(t, err, "invalid registry mirror")
+
+	registryMirrors = []string{"registry.redhat.io=mirror.internal"}
+	ctx, err = WithRegistryMirrors(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, Mirrors{"registry.redhat.io": "mirror.internal"}, MirrorsFrom(ctx))
+}
+
+func TestClientMirrors(t *testing.T) {
+	img, err := random.Image(1024, 1)
+	require.NoError(t, err)
+
+	l := &bytes.Buffer{}
+	registry := httptest.NewServer(registry.New(registry.Logger(log.New(l, "", 0))))
+	t.Cleanup(registry.Close)
+
+	u, err := url.Parse(registry.URL)
+	require.NoError(t, err)
+
+	mirrored, err := name.ParseReference(fmt.Sprintf("localhost:%s/repository/image:tag", u.Port()))
+	require.NoError(t, err)
+	require.NoError(t, remote.Push(mirrored, img))
+
+	mirrors, err := ParseMirrors([]string{fmt.Sprintf("registry.io/org=localhost:%s/repository", u.Port())})
+	require.NoError(t, err)
+
+	ctx := WithMirrors(context.Background(), mirrors)
+	assert.Equal(t, mirrors, NewClient(ctx).(*defaultClient).mirrors)
+
+	client := &defaultClient{ctx: ctx, mirrors: mirrors}
+
+	// the image is not in registry.io, only in the mirror
+	ref := name.MustParseReference("registry.io/org/image:tag")
+
+	descriptor, err := client.Head(ref)
+	require.NoError(t, err)
+
+	expected, err := img.Digest()
+	require.NoError(t, err)
+	assert.Equal(t, expected, descriptor.Digest)
+
+	digest, err := client.ResolveDigest(ref)
+	require.NoError(t, err)
+	assert.Equal(t, expected.String(), digest)
+
+	assert.Equal(t, 2, strings.Count(l.String(), "HEAD /v2/repository/image/manifests/tag"))
+}
@@ -0,0 +1,66 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package oci
+
+import (
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+)
+
+// ReferrerArtifact returns an OCI artifact with the given artifact type,
+// holding the content as its single layer, and referring to the image
+// described by subject. The artifact type is set as the media type of the
+// config, from which the registry derives the artifact type reported by the
+// referrers API.
+func ReferrerArtifact(content []byte, contentType, artifactType types.MediaType, subject v1.Descriptor, annotations map[string]string) (v1.Image, error) {
+	img, err := mutate.AppendLayers(empty.Image, static.NewLayer(content, contentType))
+	if err != nil {
+		return nil, err
+	}
+
+	img = mutate.MediaType(img, types.OCIManifestSchema1)
+	img = mutate.ConfigMediaType(img, artifactType)
+	if len(annotations) > 0 {
+		img = mutate.Annotations(img, annotations).(v1.Image)
+	}
+
+	// only the media type, size and digest of the subject are relevant
+	subject = v1.Descriptor{MediaType: subject.MediaType, Size: subject.Size, Digest: subject.Digest}
+
+	return mutate.Subject(img, subject).(v1.Image), nil
+}
+
+// PushReferrer pushes the artifact, created via ReferrerArtifact, by digest
+// to the repository of the given image reference and returns the reference
+// of the pushed artifact.
+func PushReferrer(client Client, ref name.Reference, artifact v1.Image) (name.Digest, error) {
+	digest, err := artifact.Digest()
+	if err != nil {
+		return name.Digest{}, err
+	}
+
+	target := ref.Context().Digest(digest.String())
+	if err := client.Write(target, artifact); err != nil {
+		return name.Digest{}, err
+	}
+
+	return target, nil
+}
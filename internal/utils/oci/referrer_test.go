@@ -0,0 +1,76 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package oci
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPushReferrer(t *testing.T) {
+	img, err := random.Image(4096, 1)
+	require.NoError(t, err)
+
+	l := &bytes.Buffer{}
+	registry := httptest.NewServer(registry.New(registry.Logger(log.New(l, "", 0)), registry.WithReferrersSupport(true)))
+	t.Cleanup(registry.Close)
+
+	u, err := url.Parse(registry.URL)
+	require.NoError(t, err)
+
+	ref, err := name.ParseReference(fmt.Sprintf("localhost:%s/repository/image:tag", u.Port()))
+	require.NoError(t, err)
+
+	require.NoError(t, remote.Push(ref, img))
+
+	client := &defaultClient{ctx: context.Background()}
+	subject, err := client.Head(ref)
+	require.NoError(t, err)
+
+	artifact, err := ReferrerArtifact([]byte(`{"spam": true}`), "application/json", "application/vnd.example+json", *subject, map[string]string{"spam": "bacon"})
+	require.NoError(t, err)
+
+	pushed, err := PushReferrer(client, ref, artifact)
+	require.NoError(t, err)
+	assert.Equal(t, ref.Context().String(), pushed.Context().String())
+
+	index, err := remote.Referrers(ref.Context().Digest(subject.Digest.String()))
+	require.NoError(t, err)
+	manifest, err := index.IndexManifest()
+	require.NoError(t, err)
+	require.Len(t, manifest.Manifests, 1)
+
+	referrer := manifest.Manifests[0]
+	assert.Equal(t, pushed.DigestStr(), referrer.Digest.String())
+	assert.Equal(t, "application/vnd.example+json", referrer.ArtifactType)
+	assert.Equal(t, types.OCIManifestSchema1, referrer.MediaType)
+}
@@ -82,6 +82,22 @@ func RenderFromTemplatesWithGlob(input any, main string, glob []string, efs embe
 	return buf.Bytes(), nil
 }
 
+// RenderTemplate renders the input with the given template text, e.g. a template
+// provided by the user, with the standard set of helper functions available
+func RenderTemplate(name, text string, input any) ([]byte, error) {
+	t, err := template.New(name).Funcs(templateHelpers).Parse(text)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, input); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
 // Helper funcs for use in templates
 
 func passWarnFailChooser(color string, choices []string) string {
@@ -120,6 +136,15 @@ func colorIndicator(color string) string {
 	return colorText(color, indicator(color))
 }
 
+// Make the text a clickable link to the url in terminals supporting OSC 8
+// hyperlinks. Like the colors it is only done when writing to a terminal.
+func hyperlink(url string, str string) string {
+	if url == "" || !ColorEnabled {
+		return str
+	}
+	return fmt.Sprintf("\x1b]8;;%s\x1b\\%s\x1b]8;;\x1b\\", url, str)
+}
+
 // Wrap text to a certain width
 func wrap(width int, s string) string {
 	return wordwrap.WrapString(s, uint(width))
@@ -167,6 +192,7 @@ var templateHelpers = template.FuncMap{
 	"colorText":      colorText,
 	"indicator":      indicator,
 	"colorIndicator": colorIndicator,
+	"hyperlink":      hyperlink,
 	"wrap":           wrap,
 	"indent":         indent,
 	"indentWrap":     indentWrap,
@@ -84,3 +84,14 @@ func TestSetupTemplate(t *testing.T) {
 		assert.Equal(t, tt.expected, buf.String())
 	}
 }
+
+func TestHyperlink(t *testing.T) {
+	t.Cleanup(func() { ColorEnabled = false })
+
+	ColorEnabled = false
+	assert.Equal(t, "docs", hyperlink("https://example.com/docs", "docs"))
+
+	ColorEnabled = true
+	assert.Equal(t, "\x1b]8;;https://example.com/docs\x1b\\docs\x1b]8;;\x1b\\", hyperlink("https://example.com/docs", "docs"))
+	assert.Equal(t, "docs", hyperlink("", "docs"))
+}
@@ -18,17 +18,30 @@ package validate
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 
+	ecc "github.com/enterprise-contract/enterprise-contract-controller/api/v1alpha1"
+
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/afero"
 
+	"github.com/enterprise-contract/ec-cli/internal/policy"
 	"github.com/enterprise-contract/ec-cli/internal/policy/source"
 	"github.com/enterprise-contract/ec-cli/internal/utils"
 )
 
 // Determine policyConfig
 func GetPolicyConfig(ctx context.Context, policyConfiguration string) (string, error) {
+	// The file:, configmap: and oci:: references are resolved when loading the
+	// policy.
+	if ref, err := policy.ParseRef(policyConfiguration); err == nil {
+		switch ref.Kind {
+		case policy.FileRef, policy.ConfigMapRef, policy.OCIRef:
+			return policyConfiguration, nil
+		}
+	}
+
 	// If policyConfiguration is not detected as a file and is detected as a git URL,
 	// or if policyConfiguration is an https URL try to download a config file from
 	// the provided source. If successful we read its contents and return it.
@@ -64,6 +77,84 @@ func GetPolicyConfig(ctx context.Context, policyConfiguration string) (string, e
 	return policyConfiguration, nil
 }
 
+// GetPolicyConfigs determines the policy configuration from the given policy
+// references. A single reference is determined as by GetPolicyConfig, several
+// references are each loaded and merged, in the order given, into a single
+// policy configuration, see policy.MergeSpecs.
+func GetPolicyConfigs(ctx context.Context, policyConfigurations []string) (string, error) {
+	switch len(policyConfigurations) {
+	case 0:
+		return "", nil
+	case 1:
+		return GetPolicyConfig(ctx, policyConfigurations[0])
+	}
+
+	specs := make([]ecc.EnterpriseContractPolicySpec, 0, len(policyConfigurations))
+	var attestations policy.AttestationSelection
+	var keyless policy.KeylessConstraints
+	var tuf policy.TUFRoot
+	var publicKeys []policy.PublicKey
+	var pki policy.PKI
+	ignoreRekor := false
+	for _, ref := range policyConfigurations {
+		policyConfiguration, err := GetPolicyConfig(ctx, ref)
+		if err != nil {
+			return "", err
+		}
+
+		p, err := policy.NewInertPolicy(ctx, policyConfiguration)
+		if err != nil {
+			return "", fmt.Errorf("unable to load the policy %s: %w", ref, err)
+		}
+		specs = append(specs, p.Spec())
+		attestations = attestations.Merge(p.AttestationSelection())
+		// the later policies take precedence, as when merging the specs
+		keyless = p.KeylessConstraints().Merge(keyless)
+		ignoreRekor = ignoreRekor || p.IgnoreRekor()
+		tuf = p.TUFRoot().Merge(tuf)
+		publicKeys = append(publicKeys, p.PublicKeys()...)
+		pki = p.PKI().Merge(pki)
+	}
+
+	merged, err := policy.MergeSpecs(specs...)
+	if err != nil {
+		return "", err
+	}
+
+	// the attestation selection, the keyless constraints, ignoreRekor, the TUF
+	// repository, the public keys and the certificate authority are not part of
+	// the spec
+	combined := struct {
+		ecc.EnterpriseContractPolicySpec
+		Attestations *policy.AttestationSelection `json:"attestations,omitempty"`
+		Keyless      *policy.KeylessConstraints   `json:"keyless,omitempty"`
+		IgnoreRekor  bool                         `json:"ignoreRekor,omitempty"`
+		TUF          *policy.TUFRoot              `json:"tuf,omitempty"`
+		PublicKeys   []policy.PublicKey           `json:"publicKeys,omitempty"`
+		PKI          *policy.PKI                  `json:"pki,omitempty"`
+	}{EnterpriseContractPolicySpec: merged, IgnoreRekor: ignoreRekor, PublicKeys: publicKeys}
+	if !attestations.IsEmpty() {
+		combined.Attestations = &attestations
+	}
+	if !keyless.IsEmpty() {
+		combined.Keyless = &keyless
+	}
+	if !tuf.IsEmpty() {
+		combined.TUF = &tuf
+	}
+	if !pki.IsEmpty() {
+		combined.PKI = &pki
+	}
+
+	config, err := json.Marshal(combined)
+	if err != nil {
+		return "", err
+	}
+	log.Debugf("Merged %d policies into: %s", len(policyConfigurations), config)
+
+	return string(config), nil
+}
+
 // Read file from the workspace and return its contents.
 func ReadFile(ctx context.Context, fileName string) (string, error) {
 	fs := utils.FS(ctx)
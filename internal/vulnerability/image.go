@@ -0,0 +1,173 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package vulnerability
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/enterprise-contract/ec-cli/internal/attestation"
+)
+
+// Sources of the vulnerability scan reports of an image
+const (
+	SourceAttestation = "attestation"
+	SourceScanner     = "scanner"
+)
+
+// PredicateCosignVuln is the predicate type of the vulnerability scan
+// attestations created by cosign, holding the report of the scanner
+const PredicateCosignVuln = "https://cosign.sigstore.dev/attestation/vuln/v1"
+
+// digestPlaceholder is replaced by the digest of the image in the URL of the
+// scanner API
+const digestPlaceholder = "{digest}"
+
+// tokenEnv is the environment variable holding the bearer token used to
+// authenticate to the scanner API
+const tokenEnv = "EC_VULNERABILITY_SCAN_TOKEN"
+
+type contextKey string
+
+const scannerURLKey contextKey = "ec.vulnerability.scanner_url"
+
+// WithScannerURL enables fetching the vulnerability scan report of each image
+// from the scanner API at the given URL, in which {digest} is replaced by the
+// digest of the image, e.g.
+// https://clair.example.com/matcher/api/v1/vulnerability_report/{digest}
+func WithScannerURL(ctx context.Context, url string) context.Context {
+	return context.WithValue(ctx, scannerURLKey, url)
+}
+
+func scannerURL(ctx context.Context) string {
+	u, _ := ctx.Value(scannerURLKey).(string)
+	return u
+}
+
+// ScannerConfigured returns true if the scanner API is configured via
+// WithScannerURL
+func ScannerConfigured(ctx context.Context) bool {
+	return scannerURL(ctx) != ""
+}
+
+// ValidateScannerURL returns an error if the URL of the scanner API is not an
+// HTTP(S) URL with the {digest} placeholder
+func ValidateScannerURL(u string) error {
+	parsed, err := url.Parse(strings.ReplaceAll(u, digestPlaceholder, "digest"))
+	if err != nil || parsed.Host == "" || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+		return fmt.Errorf("invalid vulnerability scan URL %q, expecting an http:// or https:// URL", u)
+	}
+
+	if !strings.Contains(u, digestPlaceholder) {
+		return fmt.Errorf("invalid vulnerability scan URL %q, expecting the %s placeholder for the image digest", u, digestPlaceholder)
+	}
+
+	return nil
+}
+
+// FromAttestations returns the reports of the cosign vulnerability scan
+// attestations. Reports that can't be parsed are skipped.
+func FromAttestations(attestations []attestation.Attestation) []Report {
+	var reports []Report
+	for _, att := range attestations {
+		if att.PredicateType() != PredicateCosignVuln {
+			continue
+		}
+
+		var statement struct {
+			Predicate struct {
+				Scanner struct {
+					URI    string          `json:"uri"`
+					Result json.RawMessage `json:"result"`
+				} `json:"scanner"`
+				Metadata struct {
+					ScanFinishedOn string `json:"scanFinishedOn"`
+				} `json:"metadata"`
+			} `json:"predicate"`
+		}
+		if err := json.Unmarshal(att.Statement(), &statement); err != nil {
+			log.Debugf("Unable to parse the %s attestation: %v", att.PredicateType(), err)
+			continue
+		}
+
+		r, err := Parse(statement.Predicate.Scanner.Result)
+		if err != nil {
+			log.Debugf("Skipping the vulnerability report of %s from the attestation: %v", statement.Predicate.Scanner.URI, err)
+			continue
+		}
+
+		r.Source = SourceAttestation
+		r.ScannedOn = utcTime(statement.Predicate.Metadata.ScanFinishedOn)
+		reports = append(reports, *r)
+	}
+
+	return reports
+}
+
+// FetchReport returns the vulnerability scan report of the image from the
+// scanner API configured via WithScannerURL, or nil if no scanner API is
+// configured. The bearer token from the EC_VULNERABILITY_SCAN_TOKEN
+// environment variable, if set, is used to authenticate.
+func FetchReport(ctx context.Context, ref name.Digest) (*Report, error) {
+	u := scannerURL(ctx)
+	if u == "" {
+		return nil, nil
+	}
+
+	u = strings.ReplaceAll(u, digestPlaceholder, url.PathEscape(ref.DigestStr()))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if token := os.Getenv(tokenEnv); token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	log.Debugf("Fetching the vulnerability report of %s from %s", ref, u)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetching the vulnerability report of %s: %w", ref, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching the vulnerability report of %s: unexpected status %s from %s", ref, resp.Status, u)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("reading the vulnerability report of %s: %w", ref, err)
+	}
+
+	r, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("the vulnerability report of %s: %w", ref, err)
+	}
+	r.Source = SourceScanner
+
+	return r, nil
+}
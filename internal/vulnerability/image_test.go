@@ -0,0 +1,140 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package vulnerability
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/in-toto/in-toto-golang/in_toto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enterprise-contract/ec-cli/internal/attestation"
+	"github.com/enterprise-contract/ec-cli/internal/signature"
+)
+
+const imageDigest = "registry.io/repository/image@sha256:4e388ab32b10dc8dbc7e28144f552830adc74787c1e2c0824032078a79f227fb"
+
+type statement struct {
+	predicateType string
+	predicate     string
+}
+
+func (s statement) Type() string {
+	return in_toto.StatementInTotoV01
+}
+
+func (s statement) PredicateType() string {
+	return s.predicateType
+}
+
+func (s statement) Statement() []byte {
+	return []byte(fmt.Sprintf(`{"_type": %q, "predicateType": %q, "predicate": %s}`, in_toto.StatementInTotoV01, s.predicateType, s.predicate))
+}
+
+func (s statement) Signatures() []signature.EntitySignature {
+	return nil
+}
+
+func (s statement) Subject() []in_toto.Subject {
+	return nil
+}
+
+func TestValidateScannerURL(t *testing.T) {
+	cases := []struct {
+		url string
+		err string
+	}{
+		{url: "https://clair.example.com/matcher/api/v1/vulnerability_report/{digest}"},
+		{url: "http://localhost:8080/scan?digest={digest}"},
+		{url: "clair.example.com/{digest}", err: `invalid vulnerability scan URL "clair.example.com/{digest}", expecting an http:// or https:// URL`},
+		{url: "ftp://clair.example.com/{digest}", err: `invalid vulnerability scan URL "ftp://clair.example.com/{digest}", expecting an http:// or https:// URL`},
+		{url: "https://clair.example.com/report", err: `invalid vulnerability scan URL "https://clair.example.com/report", expecting the {digest} placeholder for the image digest`},
+	}
+
+	for _, c := range cases {
+		t.Run(c.url, func(t *testing.T) {
+			err := ValidateScannerURL(c.url)
+			if c.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, c.err)
+			}
+		})
+	}
+}
+
+func TestFromAttestations(t *testing.T) {
+	trivy, err := os.ReadFile("testdata/trivy.json")
+	require.NoError(t, err)
+
+	reports := FromAttestations([]attestation.Attestation{
+		statement{predicateType: PredicateCosignVuln, predicate: fmt.Sprintf(`{
+			"scanner": {"uri": "pkg:github/aquasecurity/trivy@0.45.0", "result": %s},
+			"metadata": {"scanStartedOn": "2023-10-01T10:00:00+02:00", "scanFinishedOn": "2023-10-01T10:05:00+02:00"}
+		}`, trivy)},
+		statement{predicateType: PredicateCosignVuln, predicate: `{"scanner": {"uri": "pkg:example/scanner", "result": {"findings": []}}}`},
+		statement{predicateType: attestation.PredicateSLSAProvenance, predicate: `{"buildType": "https://example.com/build"}`},
+	})
+
+	assert.Equal(t, []Report{
+		{Source: SourceAttestation, Scanner: Trivy, ScannedOn: "2023-10-01T08:05:00Z", Vulnerabilities: expectedVulnerabilities},
+	}, reports)
+}
+
+func TestFetchReport(t *testing.T) {
+	clair, err := os.ReadFile("testdata/clair.json")
+	require.NoError(t, err)
+
+	ref := name.MustParseReference(imageDigest).(name.Digest)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/report/"+ref.DigestStr() || r.Header.Get("Authorization") != "Bearer t0k3n" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write(clair)
+	}))
+	t.Cleanup(server.Close)
+
+	t.Setenv("EC_VULNERABILITY_SCAN_TOKEN", "t0k3n")
+
+	t.Run("not configured", func(t *testing.T) {
+		r, err := FetchReport(context.Background(), ref)
+		require.NoError(t, err)
+		assert.Nil(t, r)
+	})
+
+	t.Run("configured", func(t *testing.T) {
+		r, err := FetchReport(WithScannerURL(context.Background(), server.URL+"/report/{digest}"), ref)
+		require.NoError(t, err)
+		assert.Equal(t, &Report{Source: SourceScanner, Scanner: Clair, Vulnerabilities: expectedVulnerabilities}, r)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		_, err := FetchReport(WithScannerURL(context.Background(), server.URL+"/missing/{digest}"), ref)
+		assert.ErrorContains(t, err, "fetching the vulnerability report of "+imageDigest+": unexpected status 404 Not Found")
+	})
+}
@@ -0,0 +1,225 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package vulnerability converts the vulnerability scan reports of Clair,
+// Trivy and Grype, in JSON format, into a normalized input for the rego policy
+// evaluation, so that the same rules apply regardless of the scanner used.
+package vulnerability
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	Clair = "clair"
+	Trivy = "trivy"
+	Grype = "grype"
+)
+
+// Report is the normalized vulnerability scan report exposed to policy rules
+type Report struct {
+	Source          string          `json:"source"`
+	Scanner         string          `json:"scanner"`
+	ScannedOn       string          `json:"scanned_on,omitempty"`
+	Vulnerabilities []Vulnerability `json:"vulnerabilities"`
+}
+
+// Vulnerability is a vulnerability found in a package of the image. The
+// severity is one of critical, high, medium, low, negligible or unknown, and
+// the published time is given in RFC3339 format, when known.
+type Vulnerability struct {
+	ID           string `json:"id"`
+	Severity     string `json:"severity"`
+	Package      string `json:"package"`
+	Version      string `json:"version,omitempty"`
+	FixedVersion string `json:"fixed_version,omitempty"`
+	Published    string `json:"published,omitempty"`
+}
+
+// Parse detects the scanner which produced the report and converts it into
+// the normalized form
+func Parse(data []byte) (*Report, error) {
+	var header map[string]json.RawMessage
+	if err := json.Unmarshal(data, &header); err != nil {
+		return nil, fmt.Errorf("unable to parse the vulnerability report, only JSON reports are supported: %w", err)
+	}
+
+	var r *Report
+	var err error
+	switch {
+	case header["SchemaVersion"] != nil:
+		r, err = parseTrivy(data)
+	case header["matches"] != nil:
+		r, err = parseGrype(data)
+	case header["manifest_hash"] != nil:
+		r, err = parseClair(data)
+	default:
+		return nil, errors.New("unknown vulnerability report format, expecting a Clair, Trivy or Grype report in JSON format")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(r.Vulnerabilities, func(i, j int) bool {
+		a, b := r.Vulnerabilities[i], r.Vulnerabilities[j]
+		if a.ID != b.ID {
+			return a.ID < b.ID
+		}
+		if a.Package != b.Package {
+			return a.Package < b.Package
+		}
+		return a.Version < b.Version
+	})
+
+	return r, nil
+}
+
+// severity returns the severity in lower case, or unknown if not given
+func severity(s string) string {
+	if s == "" {
+		return "unknown"
+	}
+
+	return strings.ToLower(s)
+}
+
+// utcTime returns the time in RFC3339 format, in UTC, or an empty string if
+// the time can't be parsed
+func utcTime(t string) string {
+	p, err := time.Parse(time.RFC3339, t)
+	if err != nil {
+		return ""
+	}
+
+	return p.UTC().Format(time.RFC3339)
+}
+
+func parseTrivy(data []byte) (*Report, error) {
+	var doc struct {
+		Results []struct {
+			Vulnerabilities []struct {
+				VulnerabilityID  string `json:"VulnerabilityID"`
+				PkgName          string `json:"PkgName"`
+				InstalledVersion string `json:"InstalledVersion"`
+				FixedVersion     string `json:"FixedVersion"`
+				Severity         string `json:"Severity"`
+				PublishedDate    string `json:"PublishedDate"`
+			} `json:"Vulnerabilities"`
+		} `json:"Results"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("unable to parse the Trivy report: %w", err)
+	}
+
+	r := Report{Scanner: Trivy, Vulnerabilities: []Vulnerability{}}
+	for _, result := range doc.Results {
+		for _, v := range result.Vulnerabilities {
+			r.Vulnerabilities = append(r.Vulnerabilities, Vulnerability{
+				ID:           v.VulnerabilityID,
+				Severity:     severity(v.Severity),
+				Package:      v.PkgName,
+				Version:      v.InstalledVersion,
+				FixedVersion: v.FixedVersion,
+				Published:    utcTime(v.PublishedDate),
+			})
+		}
+	}
+
+	return &r, nil
+}
+
+func parseGrype(data []byte) (*Report, error) {
+	var doc struct {
+		Matches []struct {
+			Vulnerability struct {
+				ID       string `json:"id"`
+				Severity string `json:"severity"`
+				Fix      struct {
+					Versions []string `json:"versions"`
+				} `json:"fix"`
+			} `json:"vulnerability"`
+			Artifact struct {
+				Name    string `json:"name"`
+				Version string `json:"version"`
+			} `json:"artifact"`
+		} `json:"matches"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("unable to parse the Grype report: %w", err)
+	}
+
+	// Grype does not report when the vulnerability was published
+	r := Report{Scanner: Grype, Vulnerabilities: []Vulnerability{}}
+	for _, m := range doc.Matches {
+		r.Vulnerabilities = append(r.Vulnerabilities, Vulnerability{
+			ID:           m.Vulnerability.ID,
+			Severity:     severity(m.Vulnerability.Severity),
+			Package:      m.Artifact.Name,
+			Version:      m.Artifact.Version,
+			FixedVersion: strings.Join(m.Vulnerability.Fix.Versions, ", "),
+		})
+	}
+
+	return &r, nil
+}
+
+func parseClair(data []byte) (*Report, error) {
+	var doc struct {
+		Packages map[string]struct {
+			Name    string `json:"name"`
+			Version string `json:"version"`
+		} `json:"packages"`
+		Vulnerabilities map[string]struct {
+			Name               string `json:"name"`
+			Issued             string `json:"issued"`
+			NormalizedSeverity string `json:"normalized_severity"`
+			FixedInVersion     string `json:"fixed_in_version"`
+		} `json:"vulnerabilities"`
+		PackageVulnerabilities map[string][]string `json:"package_vulnerabilities"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("unable to parse the Clair report: %w", err)
+	}
+
+	// the vulnerabilities are matched to the installed packages via the
+	// package_vulnerabilities, the package of a vulnerability describes the
+	// affected package, not the one installed
+	r := Report{Scanner: Clair, Vulnerabilities: []Vulnerability{}}
+	for pkgID, vulnIDs := range doc.PackageVulnerabilities {
+		pkg := doc.Packages[pkgID]
+		for _, id := range vulnIDs {
+			v, ok := doc.Vulnerabilities[id]
+			if !ok {
+				continue
+			}
+			r.Vulnerabilities = append(r.Vulnerabilities, Vulnerability{
+				ID:           v.Name,
+				Severity:     severity(v.NormalizedSeverity),
+				Package:      pkg.Name,
+				Version:      pkg.Version,
+				FixedVersion: v.FixedInVersion,
+				Published:    utcTime(v.Issued),
+			})
+		}
+	}
+
+	return &r, nil
+}
@@ -0,0 +1,90 @@
+// Copyright The Enterprise Contract Contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build unit
+
+package vulnerability
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var expectedVulnerabilities = []Vulnerability{
+	{ID: "CVE-2022-3715", Severity: "low", Package: "bash", Version: "5.1.8-6.el9_1", Published: "2023-01-05T15:15:10Z"},
+	{ID: "CVE-2023-0286", Severity: "critical", Package: "openssl-libs", Version: "1:3.0.7-6.el9_2", FixedVersion: "1:3.0.7-16.el9_2", Published: "2023-02-08T20:15:23Z"},
+}
+
+func TestParse(t *testing.T) {
+	cases := []struct {
+		file      string
+		scanner   string
+		published bool
+	}{
+		{file: "testdata/trivy.json", scanner: Trivy, published: true},
+		{file: "testdata/grype.json", scanner: Grype},
+		{file: "testdata/clair.json", scanner: Clair, published: true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.scanner, func(t *testing.T) {
+			data, err := os.ReadFile(c.file)
+			require.NoError(t, err)
+
+			r, err := Parse(data)
+			require.NoError(t, err)
+
+			assert.Equal(t, c.scanner, r.Scanner)
+
+			expected := make([]Vulnerability, 0, len(expectedVulnerabilities))
+			for _, v := range expectedVulnerabilities {
+				if !c.published {
+					v.Published = ""
+				}
+				expected = append(expected, v)
+			}
+			assert.Equal(t, expected, r.Vulnerabilities)
+		})
+	}
+}
+
+func TestParseNoVulnerabilities(t *testing.T) {
+	r, err := Parse([]byte(`{"SchemaVersion": 2, "ArtifactName": "image"}`))
+	require.NoError(t, err)
+	assert.Equal(t, &Report{Scanner: Trivy, Vulnerabilities: []Vulnerability{}}, r)
+}
+
+func TestParseErrors(t *testing.T) {
+	cases := []struct {
+		name string
+		data string
+		err  string
+	}{
+		{name: "not JSON", data: `<report/>`, err: "unable to parse the vulnerability report, only JSON reports are supported"},
+		{name: "unknown format", data: `{"vulnerabilities": []}`, err: "unknown vulnerability report format, expecting a Clair, Trivy or Grype report in JSON format"},
+		{name: "malformed Grype report", data: `{"matches": {}}`, err: "unable to parse the Grype report"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := Parse([]byte(c.data))
+			assert.ErrorContains(t, err, c.err)
+		})
+	}
+}